@@ -1,22 +1,36 @@
 package agent
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"jas-agent/agent/core"
-	"strings"
+	"jas-agent/agent/llm"
+	"jas-agent/agent/memory"
+	"time"
 )
 
 type Agent interface {
 	Type() AgentType
-	Step() string
+	Step(ctx context.Context) string
 }
 
 type AgentExecutor struct {
-	context      *Context
-	maxSteps     int
-	currentStep  int
-	state        State
-	agent        Agent
-	summaryAgent Agent
+	context       *Context
+	maxSteps      int
+	currentStep   int
+	state         State
+	agent         Agent
+	summaryAgent  Agent
+	toolCalls     []string
+	runTimeout    time.Duration
+	finalResult   *FinalResult
+	summaryPolicy SummaryPolicy
+	checkpointer  Checkpointer
+	closers       []io.Closer
+	// runStart 本次运行的消息在记忆非系统消息中的起始位置
+	runStart int
 }
 
 func NewAgentExecutor(context *Context) *AgentExecutor {
@@ -49,6 +63,23 @@ func (agent *AgentExecutor) UpdateState(state State) {
 	agent.state = state
 }
 
+// AddCloser 登记执行器结束后需要释放的资源，如按子 Agent 配置连接的 MCP 客户端
+func (agent *AgentExecutor) AddCloser(closer io.Closer) {
+	agent.closers = append(agent.closers, closer)
+}
+
+// Close 释放 AddCloser 登记的资源
+func (agent *AgentExecutor) Close() error {
+	var errs []error
+	for _, closer := range agent.closers {
+		if err := closer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	agent.closers = nil
+	return errors.Join(errs...)
+}
+
 // GetCurrentStep 获取当前步骤数
 func (agent *AgentExecutor) GetCurrentStep() int {
 	return agent.currentStep
@@ -69,37 +100,114 @@ func (agent *AgentExecutor) SetMaxSteps(steps int) {
 	agent.maxSteps = steps
 }
 
-func (agent *AgentExecutor) Run(query string) string {
+// SetRunTimeout 设置单次运行的超时时间，0 表示不限制
+func (agent *AgentExecutor) SetRunTimeout(timeout time.Duration) {
+	agent.runTimeout = timeout
+}
+
+// SetSummaryPolicy 设置完成后的总结策略
+func (agent *AgentExecutor) SetSummaryPolicy(policy SummaryPolicy) {
+	agent.summaryPolicy = policy
+}
+
+// GetAgentType 获取主 Agent 类型
+func (agent *AgentExecutor) GetAgentType() AgentType {
+	if agent.agent == nil {
+		return ""
+	}
+	return agent.agent.Type()
+}
+
+// RecordToolCall 记录一次工具调用
+func (agent *AgentExecutor) RecordToolCall(name string) {
+	agent.toolCalls = append(agent.toolCalls, name)
+}
+
+// GetToolCalls 获取本次执行中按顺序调用过的工具名称（可能重复）
+func (agent *AgentExecutor) GetToolCalls() []string {
+	return append([]string(nil), agent.toolCalls...)
+}
+
+// GetUsage 获取本次执行累计的 token 用量
+func (agent *AgentExecutor) GetUsage() llm.Usage {
+	return agent.context.GetUsage()
+}
+
+// SetFinalResult 记录最终结果并结束运行
+func (agent *AgentExecutor) SetFinalResult(result *FinalResult) {
+	agent.finalResult = result
+	agent.state = FinishState
+}
+
+// GetFinalResult 获取最终结果，未结束时返回 nil
+func (agent *AgentExecutor) GetFinalResult() *FinalResult {
+	return agent.finalResult
+}
+
+func (agent *AgentExecutor) Run(ctx context.Context, query string) string {
+	agent.runStart = len(nonSystemMessages(memory.Messages(agent.context.memory)))
 	if len(query) > 0 {
 		agent.context.memory.AddMessage(core.Message{
 			Role:    core.MessageRoleUser,
 			Content: query,
 		})
 	}
-
 	agent.state = RunningState
+	agent.finalResult = nil
+	agent.checkpoint(ctx)
+	return agent.run(ctx)
+}
+
+// run 执行主循环直到结束，每一步结束后以及结束时保存检查点
+func (agent *AgentExecutor) run(ctx context.Context) string {
+	if agent.runTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, agent.runTimeout)
+		defer cancel()
+	}
+	defer agent.checkpoint(ctx)
+
 	var results []string
 
 	// 执行主要的 ReAct 循环
 	for agent.currentStep < agent.maxSteps && agent.state != FinishState {
+		if err := ctx.Err(); err != nil {
+			return agent.interrupt(err)
+		}
+		if agent.context.budgetExceeded() {
+			return agent.stopOnBudget()
+		}
 		agent.currentStep++
-		result := agent.agent.Step()
-		results = append(results, result)
+		results = append(results, agent.agent.Step(ctx))
+		agent.checkpoint(ctx)
+	}
 
-		// 检查是否完成
-		if strings.Contains(strings.ToLower(result), "final answer") {
-			agent.state = FinishState
-			break
-		}
+	// 最后一步中途被取消时，结果不可信
+	if err := ctx.Err(); err != nil {
+		return agent.interrupt(err)
+	}
+
+	// 超出预算时最后一步的模型调用已被拒绝，结果不可信，也不再进行总结
+	if agent.context.budgetExceeded() && agent.finalResult == nil {
+		return agent.stopOnBudget()
 	}
 
-	if agent.currentStep >= agent.maxSteps {
+	// 在最后一步完成的运行不算超出步数
+	if agent.currentStep >= agent.maxSteps && agent.state != FinishState {
 		agent.state = ErrorState
 	}
 
-	// 如果启用了总结功能且执行完成，使用 SummarAgent 进行总结
+	// 执行完成后按总结策略决定是否使用 SummaryAgent 进行总结
 	if agent.state == FinishState {
-		summary := agent.summaryAgent.Step()
+		// Plan、Chain 等未显式提交结果的 Agent，以最后一步输出作为最终结果
+		if agent.finalResult == nil && len(results) > 0 {
+			agent.finalResult = &FinalResult{Answer: results[len(results)-1]}
+		}
+		// 已得到答案但预算耗尽时跳过总结
+		if agent.finalResult != nil && (!agent.summaryPolicy.enabled(agent.currentStep) || agent.context.budgetExceeded()) {
+			return agent.finalResult.Answer
+		}
+		summary := agent.summaryAgent.Step(ctx)
 		return summary
 	}
 
@@ -109,6 +217,24 @@ func (agent *AgentExecutor) Run(query string) string {
 	return results[len(results)-1]
 }
 
+// interrupt 根据上下文错误更新状态并返回说明
+func (agent *AgentExecutor) interrupt(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		agent.state = TimeoutState
+		return fmt.Sprintf("Execution timed out after %d steps", agent.currentStep)
+	}
+	agent.state = CanceledState
+	return fmt.Sprintf("Execution canceled after %d steps", agent.currentStep)
+}
+
+// stopOnBudget 超出用量预算时结束运行
+func (agent *AgentExecutor) stopOnBudget() string {
+	agent.state = BudgetExceededState
+	usage := agent.context.GetUsage()
+	return fmt.Sprintf("Execution stopped after %d steps: token budget exceeded (%d tokens, cost %.4f)",
+		agent.currentStep, usage.TotalTokens(), usage.Cost)
+}
+
 type State string
 
 const (
@@ -116,16 +242,23 @@ const (
 	RunningState State = "Running"
 	FinishState  State = "Finish"
 	ErrorState   State = "Error"
+	// TimeoutState 运行超过 run timeout
+	TimeoutState State = "Timeout"
+	// CanceledState 调用方取消（如客户端断开连接）
+	CanceledState State = "Canceled"
+	// BudgetExceededState 累计 token 用量或费用超出预算
+	BudgetExceededState State = "BudgetExceeded"
 )
 
 type AgentType string
 
 const (
-	ReactAgentType     AgentType = "ReactAgent"
-	PlanAgentType      AgentType = "PlanAgent"
-	ChainAgentType     AgentType = "ChainAgent"
-	SummaryAgentType   AgentType = "SummaryAgent"
-	SQLAgentType       AgentType = "SQLAgent"
-	ESAgentType        AgentType = "ESAgent"
-	RootCauseAgentType AgentType = "RootCauseAgent"
+	ReactAgentType      AgentType = "ReactAgent"
+	PlanAgentType       AgentType = "PlanAgent"
+	ChainAgentType      AgentType = "ChainAgent"
+	SummaryAgentType    AgentType = "SummaryAgent"
+	SQLAgentType        AgentType = "SQLAgent"
+	ESAgentType         AgentType = "ESAgent"
+	RootCauseAgentType  AgentType = "RootCauseAgent"
+	SupervisorAgentType AgentType = "SupervisorAgent"
 )
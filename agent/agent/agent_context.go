@@ -2,10 +2,14 @@ package agent
 
 import (
 	"context"
+	"fmt"
 	"jas-agent/agent/core"
 	"jas-agent/agent/llm"
 	"jas-agent/agent/memory"
 	"jas-agent/agent/tools"
+	"jas-agent/agent/window"
+	"sync"
+	"time"
 )
 
 type Context struct {
@@ -16,22 +20,98 @@ type Context struct {
 	memory             core.Memory
 	send               func(ctx context.Context, msg core.Message) error
 	allowedMCPServices []string
+	toolTimeout        time.Duration
+	toolCallMode       ToolCallMode
+	maxParallelTools   int
+	maxParallelSteps   int
+	streamTokens       bool
+	phaseModels        map[ModelPhase]string
+	usage              *llm.UsageMeter
+	window             *window.Manager
+	toolPolicies       ToolPolicies
+	approver           Approver
+	subAgentFactory    SubAgentFactory
+	subAgents          []SubAgent
+	maxAgentDepth      int
+	planEvaluation     bool
+	maxPlanRevisions   int
+	planRepairAttempts int
+	depth              int      // 子 Agent 所在的层数，根上下文为 0
+	source             string   // 子 Agent 运行的来源路径，根上下文为空
+	parent             *Context // Derive 创建的子上下文的父上下文
 }
 
+// ModelPhase 执行阶段，不同阶段可以使用不同的模型
+type ModelPhase string
+
+const (
+	// ModelPhasePlan 计划生成与重新规划
+	ModelPhasePlan ModelPhase = "plan"
+	// ModelPhaseReact ReAct 思考步骤
+	ModelPhaseReact ModelPhase = "react"
+	// ModelPhaseSummary 总结
+	ModelPhaseSummary ModelPhase = "summary"
+)
+
+// ToolCallMode 工具调用协议
+type ToolCallMode string
+
+const (
+	// ToolCallModeText 文本 ReAct 模式，从 "Action: tool[input]" 中解析工具调用
+	ToolCallModeText ToolCallMode = "text"
+	// ToolCallModeNative 原生 function calling 模式，工具调用与结果通过 tool_calls / tool 消息传递
+	ToolCallModeNative ToolCallMode = "native"
+)
+
 type Option func(*Context)
 
 func NewContext(opts ...Option) *Context {
 	ctx := &Context{
-		agentType:   ReactAgentType,
-		toolManager: tools.GetToolManager(),
-		memory:      memory.NewMemory(),
+		agentType:          ReactAgentType,
+		toolManager:        tools.GetToolManager(),
+		memory:             memory.NewMemory(),
+		toolCallMode:       ToolCallModeText,
+		maxParallelTools:   1,
+		maxParallelSteps:   defaultMaxParallelSteps,
+		maxAgentDepth:      defaultMaxAgentDepth,
+		maxPlanRevisions:   defaultMaxPlanRevisions,
+		planRepairAttempts: defaultPlanRepairAttempts,
 	}
 	for _, opt := range opts {
 		opt(ctx)
 	}
+	ctx.registerReadObservation()
 	return ctx
 }
 
+// Derive 创建子上下文，与父上下文共享记忆、模型调用、推送通道、用量计量与审批方式，
+// 可通过 opts 覆盖模型、工具等设置，用于链节点等需要独立配置的场景
+func (ctx *Context) Derive(opts ...Option) *Context {
+	child := *ctx
+	child.parent = ctx
+	for _, opt := range opts {
+		opt(&child)
+	}
+	if child.toolManager != ctx.toolManager {
+		child.registerReadObservation()
+	}
+	return &child
+}
+
+// registerReadObservation 设置了上下文窗口管理器时注册 read_observation 工具
+func (ctx *Context) registerReadObservation() {
+	if ctx.window == nil {
+		return
+	}
+	// read_observation 只对本次运行有效，不能注册到全局工具管理器
+	if ctx.toolManager == tools.GetToolManager() {
+		tm := tools.NewToolManager()
+		tm.Inherit(ctx.toolManager)
+		ctx.toolManager = tm
+	}
+	ctx.toolManager.RegisterTool(window.NewReadObservationTool(ctx.window.Store()))
+}
+
 func WithModel(model string) Option {
 	return func(context *Context) {
 		context.model = model
@@ -67,6 +147,135 @@ func WithAllowedMCPServices(services []string) Option {
 	}
 }
 
+// WithToolTimeout 设置单次工具调用的超时时间，0 表示不限制
+func WithToolTimeout(timeout time.Duration) Option {
+	return func(context *Context) {
+		context.toolTimeout = timeout
+	}
+}
+
+// WithToolCallMode 设置工具调用协议，未知取值按文本模式处理
+func WithToolCallMode(mode ToolCallMode) Option {
+	return func(context *Context) {
+		if mode == ToolCallModeNative {
+			context.toolCallMode = ToolCallModeNative
+			return
+		}
+		context.toolCallMode = ToolCallModeText
+	}
+}
+
+// WithMaxParallelTools 设置同一步骤内并发执行的工具调用数上限，小于 1 时按 1（串行）处理
+func WithMaxParallelTools(n int) Option {
+	return func(context *Context) {
+		if n < 1 {
+			n = 1
+		}
+		context.maxParallelTools = n
+	}
+}
+
+// WithMaxParallelSteps 设置计划中可同时执行的步骤数上限，小于 1 时使用默认值
+func WithMaxParallelSteps(n int) Option {
+	return func(context *Context) {
+		if n < 1 {
+			n = defaultMaxParallelSteps
+		}
+		context.maxParallelSteps = n
+	}
+}
+
+// WithStreamTokens 开启 token 级流式输出，模型输出的增量片段通过 Send 推送
+func WithStreamTokens(enabled bool) Option {
+	return func(context *Context) {
+		context.streamTokens = enabled
+	}
+}
+
+// WithPhaseModel 为指定阶段设置模型，为空时使用默认模型。
+// 复制后再修改，Derive 创建的子上下文不会影响父上下文
+func WithPhaseModel(phase ModelPhase, model string) Option {
+	return func(context *Context) {
+		if model == "" {
+			return
+		}
+		phaseModels := make(map[ModelPhase]string, len(context.phaseModels)+1)
+		for p, m := range context.phaseModels {
+			phaseModels[p] = m
+		}
+		phaseModels[phase] = model
+		context.phaseModels = phaseModels
+	}
+}
+
+// WithoutPhaseModels 清除各阶段的模型，使所有阶段都使用上下文的模型
+func WithoutPhaseModels() Option {
+	return func(context *Context) {
+		context.phaseModels = nil
+	}
+}
+
+// WithUsageMeter 设置用量计量器，用于统计 token 用量和检查预算，
+// 计量器需同时以 llm.ObserveUsage / llm.EnforceBudget 挂载到 Chat 上
+func WithUsageMeter(meter *llm.UsageMeter) Option {
+	return func(context *Context) {
+		context.usage = meter
+	}
+}
+
+// WithRunBudget 为 Derive 创建的子上下文设置独立的运行预算：子上下文的模型调用按 pricing 计入新的计量器，
+// 达到该预算或父上下文的预算时拒绝后续调用，用量同时计入父上下文。budget 为零值时不做修改
+func WithRunBudget(budget llm.Budget, pricing llm.Pricing) Option {
+	return func(context *Context) {
+		if budget.MaxTokens <= 0 && budget.MaxCost <= 0 {
+			return
+		}
+		var limits []llm.Limit
+		if context.usage != nil {
+			limits = append(limits, context.usage)
+		}
+		meter := llm.NewUsageMeter(budget, limits...)
+		context.usage = meter
+		if context.chat != nil {
+			context.chat = llm.Chain(context.chat, llm.ObserveUsage(pricing, meter.Observe), llm.EnforceBudget(meter))
+		}
+	}
+}
+
+// WithContextWindow 设置上下文窗口管理器：发送给模型的历史消息按模型的上下文窗口压缩，
+// 超长工具输出转存后由模型通过 read_observation 工具读取
+func WithContextWindow(manager *window.Manager) Option {
+	return func(context *Context) {
+		context.window = manager
+	}
+}
+
+// ModelFor 获取指定阶段使用的模型
+func (ctx *Context) ModelFor(phase ModelPhase) string {
+	if model, ok := ctx.phaseModels[phase]; ok {
+		return model
+	}
+	return ctx.model
+}
+
+// GetToolCallMode 获取工具调用协议
+func (ctx *Context) GetToolCallMode() ToolCallMode {
+	return ctx.toolCallMode
+}
+
+// GetUsage 获取累计的 token 用量，未设置计量器时返回零值
+func (ctx *Context) GetUsage() llm.Usage {
+	if ctx.usage == nil {
+		return llm.Usage{}
+	}
+	return ctx.usage.Total()
+}
+
+// budgetExceeded 累计用量是否已达到预算
+func (ctx *Context) budgetExceeded() bool {
+	return ctx.usage != nil && ctx.usage.Exceeded()
+}
+
 // GetToolManager 获取工具管理器
 func (ctx *Context) GetToolManager() *tools.ToolManager {
 	return ctx.toolManager
@@ -77,6 +286,95 @@ func (ctx *Context) GetMemory() core.Memory {
 	return ctx.memory
 }
 
+// fitMessages 将消息压缩到模型的上下文窗口内，未设置上下文窗口管理器时原样返回
+func (ctx *Context) fitMessages(c context.Context, model string, messages []core.Message) []core.Message {
+	if ctx.window == nil {
+		return messages
+	}
+	return ctx.window.Fit(c, model, messages)
+}
+
+// ExecTool 按工具策略审批后在工具超时限制下执行工具调用，超长输出按上下文窗口配置转存。
+// 等待审批的时间不计入工具超时
+func (ctx *Context) ExecTool(c context.Context, toolCall *tools.ToolCall) (string, error) {
+	toolCall, edited, err := ctx.approve(c, toolCall)
+	if err != nil {
+		return "", err
+	}
+	result, err := ctx.execTool(c, toolCall)
+	if err == nil && edited {
+		// 让模型知道实际执行时使用的参数
+		result = fmt.Sprintf("Note: arguments were edited by the user to %s\n%s", toolCall.Input, result)
+	}
+	return result, err
+}
+
+func (ctx *Context) execTool(c context.Context, toolCall *tools.ToolCall) (string, error) {
+	if ctx.toolTimeout > 0 {
+		var cancel context.CancelFunc
+		c, cancel = context.WithTimeout(c, ctx.toolTimeout)
+		defer cancel()
+	}
+	result, err := ctx.toolManager.ExecTool(c, toolCall)
+	if err != nil || ctx.window == nil {
+		return result, err
+	}
+	return ctx.window.Offload(ctx.model, toolCall.Name, result), nil
+}
+
+// Completions 调用模型；开启 token 级流式输出且存在推送通道时，
+// 以 MessageKindDelta 消息逐段推送增量内容（包括工具调用参数片段）
+func (ctx *Context) Completions(c context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	if !ctx.streamTokens || ctx.send == nil {
+		return ctx.chat.Completions(c, req)
+	}
+	return ctx.chat.CompletionsStream(c, req, func(delta llm.ChatDelta) error {
+		msg := core.Message{
+			Role:    core.MessageRoleAssistant,
+			Content: delta.Content,
+			Kind:    core.MessageKindDelta,
+		}
+		for _, toolCall := range delta.ToolCalls {
+			msg.ToolCalls = append(msg.ToolCalls, core.ToolCall{
+				ID:        toolCall.ID,
+				Name:      toolCall.Name,
+				Arguments: toolCall.Arguments,
+			})
+		}
+		return ctx.send(c, msg)
+	})
+}
+
+// ToolResult 工具调用结果
+type ToolResult struct {
+	Result string
+	Err    error
+}
+
+// ExecTools 在并发上限内执行一组工具调用，结果按调用顺序返回，单个调用失败不影响其它调用
+func (ctx *Context) ExecTools(c context.Context, toolCalls []*tools.ToolCall) []ToolResult {
+	results := make([]ToolResult, len(toolCalls))
+	if ctx.maxParallelTools <= 1 || len(toolCalls) <= 1 {
+		for i, toolCall := range toolCalls {
+			results[i].Result, results[i].Err = ctx.ExecTool(c, toolCall)
+		}
+		return results
+	}
+	sem := make(chan struct{}, ctx.maxParallelTools)
+	var wg sync.WaitGroup
+	for i, toolCall := range toolCalls {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, toolCall *tools.ToolCall) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i].Result, results[i].Err = ctx.ExecTool(c, toolCall)
+		}(i, toolCall)
+	}
+	wg.Wait()
+	return results
+}
+
 // Send 发送消息
 func (ctx *Context) Send(c context.Context, message core.Message) {
 	if ctx.send == nil {
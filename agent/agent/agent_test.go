@@ -0,0 +1,147 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"jas-agent/agent/llm"
+	"jas-agent/agent/tools"
+)
+
+func TestRunFinishesOnLastStep(t *testing.T) {
+	tests := []struct {
+		name      string
+		replies   []string
+		wantState State
+		wantFinal bool
+	}{
+		{"finish on last step", []string{"Action: calculator[1+1]", "Action: Finish[2]"}, FinishState, true},
+		{"max steps exceeded", []string{"Action: calculator[1+1]", "Action: calculator[2+2]"}, ErrorState, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chat := &fakeChat{}
+			for _, reply := range tt.replies {
+				chat.replies = append(chat.replies, textReply(reply))
+			}
+			tm := tools.NewToolManager()
+			tm.RegisterTool(&fakeTool{name: "calculator"})
+			executor := NewAgentExecutor(NewContext(WithChat(chat), WithToolManager(tm)))
+			executor.SetMaxSteps(2)
+			executor.SetSummaryPolicy(SummaryPolicy{Mode: SummaryNever})
+
+			result := executor.Run(context.Background(), "1+1")
+			if executor.GetState() != tt.wantState {
+				t.Fatalf("state = %s, want %s", executor.GetState(), tt.wantState)
+			}
+			if got := executor.GetFinalResult() != nil; got != tt.wantFinal {
+				t.Fatalf("final result set = %v, want %v", got, tt.wantFinal)
+			}
+			if tt.wantFinal && result != "2" {
+				t.Fatalf("result = %q, want 2", result)
+			}
+		})
+	}
+}
+
+// blockingTool 阻塞到 ctx 结束的工具，started 在调用开始时关闭
+func blockingTool(started chan struct{}) *fakeTool {
+	return &fakeTool{name: "wait", handler: func(ctx context.Context, _ string) (string, error) {
+		close(started)
+		<-ctx.Done()
+		return "", ctx.Err()
+	}}
+}
+
+func TestRunInterrupted(t *testing.T) {
+	tests := []struct {
+		name       string
+		runTimeout time.Duration
+		// cancel 工具开始执行后取消运行
+		cancel     bool
+		wantState  State
+		wantResult string
+	}{
+		{name: "run timeout", runTimeout: 50 * time.Millisecond, wantState: TimeoutState, wantResult: "Execution timed out after 1 steps"},
+		{name: "canceled", cancel: true, wantState: CanceledState, wantResult: "Execution canceled after 1 steps"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := make(chan struct{})
+			chat := &fakeChat{replies: []*llm.ChatResponse{textReply("Action: wait[]"), textReply("Action: Finish[done]")}}
+			tm := tools.NewToolManager()
+			tm.RegisterTool(blockingTool(started))
+			executor := NewAgentExecutor(NewContext(WithChat(chat), WithToolManager(tm)))
+			executor.SetSummaryPolicy(SummaryPolicy{Mode: SummaryNever})
+			executor.SetRunTimeout(tt.runTimeout)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				go func() {
+					<-started
+					cancel()
+				}()
+			}
+
+			// 中断后不再调用模型，也不执行总结
+			result := executor.Run(ctx, "wait")
+			if executor.GetState() != tt.wantState || result != tt.wantResult {
+				t.Fatalf("state = %s, result = %q, want %s, %q", executor.GetState(), result, tt.wantState, tt.wantResult)
+			}
+			if len(chat.requests) != 1 || executor.GetFinalResult() != nil {
+				t.Fatalf("model called %d times, final result %+v", len(chat.requests), executor.GetFinalResult())
+			}
+		})
+	}
+}
+
+func TestToolTimeout(t *testing.T) {
+	tests := []struct {
+		name string
+		// approvalDelay 大于 0 时工具需要审批，审批在 approvalDelay 后通过
+		approvalDelay   time.Duration
+		toolDuration    time.Duration
+		wantObservation string
+	}{
+		{name: "tool times out", toolDuration: time.Second, wantObservation: "context deadline exceeded"},
+		{name: "tool finishes in time", toolDuration: 0, wantObservation: "Observation: finished"},
+		// 等待审批的时间不计入工具超时
+		{name: "timeout starts after approval", approvalDelay: 150 * time.Millisecond, toolDuration: 10 * time.Millisecond, wantObservation: "Observation: finished"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chat := &fakeChat{replies: []*llm.ChatResponse{textReply("Action: work[]"), textReply("Action: Finish[done]")}}
+			tm := tools.NewToolManager()
+			tm.RegisterTool(&fakeTool{name: "work", handler: func(ctx context.Context, _ string) (string, error) {
+				select {
+				case <-time.After(tt.toolDuration):
+					return "finished", nil
+				case <-ctx.Done():
+					return "", ctx.Err()
+				}
+			}})
+			opts := []Option{WithChat(chat), WithToolManager(tm), WithToolTimeout(50 * time.Millisecond)}
+			if tt.approvalDelay > 0 {
+				opts = append(opts,
+					WithToolPolicies(ToolPolicies{Default: ToolPolicyConfirm}),
+					WithApprover(ApproverFunc(func(context.Context, ApprovalRequest) (<-chan ApprovalDecision, error) {
+						decisions := make(chan ApprovalDecision, 1)
+						time.AfterFunc(tt.approvalDelay, func() { decisions <- ApprovalDecision{Action: ApprovalApprove} })
+						return decisions, nil
+					})))
+			}
+			executor := NewAgentExecutor(NewContext(opts...))
+			executor.SetSummaryPolicy(SummaryPolicy{Mode: SummaryNever})
+
+			// 工具超时只作为该调用的错误观察，运行继续
+			if result := executor.Run(context.Background(), "work"); result != "done" || executor.GetState() != FinishState {
+				t.Fatalf("result = %q, state = %s", result, executor.GetState())
+			}
+			if observation := lastUserContent(chat.requests[1]); !strings.Contains(observation, tt.wantObservation) {
+				t.Fatalf("observation = %q, want it to contain %q", observation, tt.wantObservation)
+			}
+		})
+	}
+}
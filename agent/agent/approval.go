@@ -0,0 +1,180 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+
+	"jas-agent/agent/core"
+	"jas-agent/agent/tools"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrToolDenied 工具调用被策略禁止
+	ErrToolDenied = errors.New("tool call denied by policy")
+	// ErrToolRejected 工具调用被审批人拒绝
+	ErrToolRejected = errors.New("tool call rejected")
+)
+
+// ToolPolicy 工具调用策略
+type ToolPolicy string
+
+const (
+	// ToolPolicyAuto 直接执行
+	ToolPolicyAuto ToolPolicy = "auto"
+	// ToolPolicyConfirm 执行前暂停运行，等待人工审批
+	ToolPolicyConfirm ToolPolicy = "confirm"
+	// ToolPolicyDeny 禁止调用
+	ToolPolicyDeny ToolPolicy = "deny"
+)
+
+// ToolPolicyRule 按工具名匹配的策略，Pattern 使用 path.Match 语法，如 "k8s@restart_*"
+type ToolPolicyRule struct {
+	Pattern string
+	Policy  ToolPolicy
+}
+
+// ToolPolicies 工具策略，按顺序使用第一条匹配的规则，都不匹配时使用 Default（为空时为 auto）
+type ToolPolicies struct {
+	Default ToolPolicy
+	Rules   []ToolPolicyRule
+}
+
+// PolicyFor 获取工具的调用策略
+func (p ToolPolicies) PolicyFor(toolName string) ToolPolicy {
+	for _, rule := range p.Rules {
+		if ok, _ := path.Match(rule.Pattern, toolName); ok {
+			return rule.Policy
+		}
+	}
+	if p.Default == "" {
+		return ToolPolicyAuto
+	}
+	return p.Default
+}
+
+// ApprovalAction 审批决定
+type ApprovalAction string
+
+const (
+	// ApprovalApprove 按原参数执行
+	ApprovalApprove ApprovalAction = "approve"
+	// ApprovalEdit 按修改后的参数执行
+	ApprovalEdit ApprovalAction = "edit"
+	// ApprovalReject 不执行，拒绝原因作为观察结果返回给模型
+	ApprovalReject ApprovalAction = "reject"
+)
+
+// ApprovalRequest 待审批的工具调用，以 MessageKindApproval 消息（内容为 JSON）推送给调用方
+type ApprovalRequest struct {
+	ID         string `json:"id"`
+	ToolCallID string `json:"tool_call_id,omitempty"`
+	ToolName   string `json:"tool_name"`
+	Arguments  string `json:"arguments"`
+}
+
+// ApprovalDecision 审批结果
+type ApprovalDecision struct {
+	Action    ApprovalAction `json:"action"`
+	Arguments string         `json:"arguments,omitempty"` // edit 时的新参数
+	Reason    string         `json:"reason,omitempty"`
+}
+
+// Approver 登记审批请求，作出决定后通过返回的通道送达。
+// 登记先于审批请求的推送，调用方收到推送后即可作出决定；ctx 结束后登记失效
+type Approver interface {
+	RequestApproval(ctx context.Context, req ApprovalRequest) (<-chan ApprovalDecision, error)
+}
+
+// ApproverFunc 函数形式的 Approver
+type ApproverFunc func(ctx context.Context, req ApprovalRequest) (<-chan ApprovalDecision, error)
+
+func (f ApproverFunc) RequestApproval(ctx context.Context, req ApprovalRequest) (<-chan ApprovalDecision, error) {
+	return f(ctx, req)
+}
+
+// WithToolPolicies 设置工具调用策略
+func WithToolPolicies(policies ToolPolicies) Option {
+	return func(context *Context) {
+		context.toolPolicies = policies
+	}
+}
+
+// WithApprover 设置 confirm 策略下的审批方式，未设置时 confirm 的工具调用一律拒绝
+func WithApprover(approver Approver) Option {
+	return func(context *Context) {
+		context.approver = approver
+	}
+}
+
+// approve 按工具策略检查调用，需要审批时推送审批请求并等待决定，返回实际执行的调用。
+// 参数被修改时 edited 为 true
+func (ctx *Context) approve(c context.Context, toolCall *tools.ToolCall) (call *tools.ToolCall, edited bool, err error) {
+	switch ctx.toolPolicies.PolicyFor(toolCall.Name) {
+	case ToolPolicyDeny:
+		return nil, false, fmt.Errorf("%w: %s", ErrToolDenied, toolCall.Name)
+	case ToolPolicyConfirm:
+	default:
+		return toolCall, false, nil
+	}
+	approver := ctx.getApprover()
+	if approver == nil {
+		return nil, false, fmt.Errorf("%w: %s requires approval but no approver is available", ErrToolRejected, toolCall.Name)
+	}
+	req := ApprovalRequest{
+		ID:         uuid.NewString(),
+		ToolCallID: toolCall.ID,
+		ToolName:   toolCall.Name,
+		Arguments:  toolCall.Input,
+	}
+	decisions, err := approver.RequestApproval(c, req)
+	if err != nil {
+		return nil, false, fmt.Errorf("request approval of %s: %w", toolCall.Name, err)
+	}
+	if data, err := json.Marshal(req); err == nil {
+		ctx.Send(c, core.Message{
+			Role:    core.MessageRoleAssistant,
+			Content: string(data),
+			Kind:    core.MessageKindApproval,
+		})
+	}
+	// 等待期间运行暂停，取消或超时即结束等待
+	var decision ApprovalDecision
+	select {
+	case decision = <-decisions:
+	case <-c.Done():
+		return nil, false, fmt.Errorf("wait for approval of %s: %w", toolCall.Name, c.Err())
+	}
+	switch decision.Action {
+	case ApprovalApprove:
+		return toolCall, false, nil
+	case ApprovalEdit:
+		edited := *toolCall
+		edited.Input = decision.Arguments
+		return &edited, true, nil
+	default:
+		if decision.Reason != "" {
+			return nil, false, fmt.Errorf("%w by user: %s", ErrToolRejected, decision.Reason)
+		}
+		return nil, false, fmt.Errorf("%w by user", ErrToolRejected)
+	}
+}
+
+// getApprover 获取审批方式，子上下文未单独设置时使用父上下文的审批方式（可能在创建子上下文之后设置）
+func (ctx *Context) getApprover() Approver {
+	for c := ctx; c != nil; c = c.parent {
+		if c.approver != nil {
+			return c.approver
+		}
+	}
+	return nil
+}
+
+// SetApprover 设置审批方式，用于创建执行器后才能确定审批对象（如运行ID）的场景
+func (agent *AgentExecutor) SetApprover(approver Approver) {
+	agent.context.approver = approver
+}
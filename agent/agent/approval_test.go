@@ -0,0 +1,130 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"jas-agent/agent/llm"
+	"jas-agent/agent/tools"
+)
+
+func TestToolPoliciesPolicyFor(t *testing.T) {
+	policies := ToolPolicies{
+		Default: ToolPolicyDeny,
+		Rules: []ToolPolicyRule{
+			{Pattern: "k8s@restart_*", Policy: ToolPolicyConfirm},
+			{Pattern: "k8s@*", Policy: ToolPolicyAuto},
+			{Pattern: "calc?lator", Policy: ToolPolicyAuto},
+		},
+	}
+	tests := []struct {
+		tool string
+		want ToolPolicy
+	}{
+		{"k8s@restart_pod", ToolPolicyConfirm},
+		// 按顺序使用第一条匹配的规则
+		{"k8s@list_pods", ToolPolicyAuto},
+		{"calculator", ToolPolicyAuto},
+		{"calculators", ToolPolicyDeny},
+		{"shell", ToolPolicyDeny},
+	}
+	for _, tt := range tests {
+		if got := policies.PolicyFor(tt.tool); got != tt.want {
+			t.Errorf("PolicyFor(%q) = %s, want %s", tt.tool, got, tt.want)
+		}
+	}
+	if got := (ToolPolicies{}).PolicyFor("shell"); got != ToolPolicyAuto {
+		t.Errorf("PolicyFor() without rules = %s, want auto", got)
+	}
+}
+
+func TestToolApproval(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   ToolPolicy
+		approver Approver
+		// wantInput 工具实际收到的参数，为空表示工具未执行
+		wantInput       string
+		wantObservation string
+	}{
+		{
+			name:            "auto",
+			policy:          ToolPolicyAuto,
+			wantInput:       "1+1",
+			wantObservation: "calculator done",
+		},
+		{
+			name:            "deny",
+			policy:          ToolPolicyDeny,
+			wantObservation: ErrToolDenied.Error(),
+		},
+		{
+			name:            "confirm without approver",
+			policy:          ToolPolicyConfirm,
+			wantObservation: "no approver is available",
+		},
+		{
+			name:            "approve",
+			policy:          ToolPolicyConfirm,
+			approver:        decide(ApprovalDecision{Action: ApprovalApprove}),
+			wantInput:       "1+1",
+			wantObservation: "calculator done",
+		},
+		{
+			name:            "edit",
+			policy:          ToolPolicyConfirm,
+			approver:        decide(ApprovalDecision{Action: ApprovalEdit, Arguments: "2+2"}),
+			wantInput:       "2+2",
+			wantObservation: "calculator done",
+		},
+		{
+			name:            "reject",
+			policy:          ToolPolicyConfirm,
+			approver:        decide(ApprovalDecision{Action: ApprovalReject, Reason: "not now"}),
+			wantObservation: "not now",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chat := &fakeChat{replies: []*llm.ChatResponse{textReply("Action: calculator[1+1]"), textReply("Action: Finish[done]")}}
+			var input string
+			tm := tools.NewToolManager()
+			tm.RegisterTool(&fakeTool{name: "calculator", handler: func(_ context.Context, in string) (string, error) {
+				input = in
+				return "calculator done", nil
+			}})
+			opts := []Option{WithChat(chat), WithToolManager(tm),
+				WithToolPolicies(ToolPolicies{Rules: []ToolPolicyRule{{Pattern: "calc*", Policy: tt.policy}}})}
+			if tt.approver != nil {
+				opts = append(opts, WithApprover(tt.approver))
+			}
+			executor := NewAgentExecutor(NewContext(opts...))
+			executor.SetSummaryPolicy(SummaryPolicy{Mode: SummaryNever})
+
+			if result := executor.Run(context.Background(), "1+1"); result != "done" {
+				t.Fatalf("result = %q", result)
+			}
+			if input != tt.wantInput {
+				t.Fatalf("tool input = %q, want %q", input, tt.wantInput)
+			}
+			if len(chat.requests) != 2 {
+				t.Fatalf("model called %d times, want 2", len(chat.requests))
+			}
+			if observation := lastUserContent(chat.requests[1]); !strings.Contains(observation, tt.wantObservation) {
+				t.Fatalf("observation = %q, want it to contain %q", observation, tt.wantObservation)
+			}
+		})
+	}
+}
+
+// decide 立即作出 decision 的审批方式，同时校验审批请求
+func decide(decision ApprovalDecision) Approver {
+	return ApproverFunc(func(_ context.Context, req ApprovalRequest) (<-chan ApprovalDecision, error) {
+		decisions := make(chan ApprovalDecision, 1)
+		if req.ID != "" && req.ToolName == "calculator" && req.Arguments == "1+1" {
+			decisions <- decision
+		}
+		return decisions, nil
+	})
+}
@@ -6,8 +6,6 @@ import (
 	"jas-agent/agent/core"
 	"jas-agent/agent/llm"
 	"jas-agent/agent/tools"
-	"regexp"
-	"strings"
 )
 
 type BaseReact struct {
@@ -27,21 +25,23 @@ func NewBaseReact(context *Context, executor *AgentExecutor) *BaseReact {
 	}
 }
 
-func (agent *BaseReact) Step() string {
-	shouldAct := agent.Thought()
+func (agent *BaseReact) Step(ctx context.Context) string {
+	shouldAct := agent.Thought(ctx)
 	if !shouldAct {
 		return "Thinking complete - no action needed"
 	}
-	return agent.Action()
+	return agent.Action(ctx)
 }
-func (agent *BaseReact) Thought() bool {
+func (agent *BaseReact) Thought(ctx context.Context) bool {
+	if agent.context.toolCallMode == ToolCallModeNative {
+		return agent.nativeThought(ctx)
+	}
 	// 检查是否需要继续思考
 	lastMessage := agent.context.memory.GetLastMessage()
 	if lastMessage.Role == core.MessageRoleAssistant {
 		// 检查是否包含完成标记
-		if strings.Contains(strings.ToLower(lastMessage.Content), "action: finish") ||
-			strings.Contains(strings.ToLower(lastMessage.Content), "final answer") {
-			agent.executor.UpdateState(FinishState)
+		if answer, ok := llm.ParseFinish(lastMessage.Content); ok {
+			agent.executor.SetFinalResult(ParseFinalResult(answer))
 			return false // 思考完成
 		}
 	}
@@ -53,7 +53,10 @@ func (agent *BaseReact) Thought() bool {
 		}
 	}
 	// 调用LLM进行思考
-	resp, err := agent.context.chat.Completions(context.TODO(), llm.NewChatRequest(agent.context.model, agent.context.memory.GetMessages(), ts...))
+	model := agent.context.ModelFor(ModelPhaseReact)
+	messages := agent.context.fitMessages(ctx, model, agent.context.memory.GetMessages())
+	req := llm.NewChatRequest(model, messages, ts...)
+	resp, err := agent.context.Completions(ctx, req)
 	if err != nil {
 		// 添加错误消息
 		agent.context.memory.AddMessage(core.Message{
@@ -66,62 +69,176 @@ func (agent *BaseReact) Thought() bool {
 		Role:    core.MessageRoleAssistant,
 		Content: resp.Content(),
 	}
-	agent.context.Send(context.TODO(), msg)
+	agent.context.Send(ctx, msg)
 	// 添加助手的思考结果
 	agent.context.memory.AddMessage(msg)
 
-	// 检查是否是Finish命令
-	if strings.Contains(strings.ToLower(resp.Content()), "action: finish") ||
-		strings.Contains(strings.ToLower(resp.Content()), "final answer") {
-		agent.executor.UpdateState(FinishState)
+	// 检查是否是Finish命令，只识别助手输出中的 Action: Finish[...]
+	if answer, ok := llm.ParseFinish(resp.Content()); ok {
+		agent.executor.SetFinalResult(ParseFinalResult(answer))
 		return true // Finish也需要执行Action
 	}
 	agent.tools = resp.GetToolCalls()
+	// 提示词中的 MCP 工具以函数名（svc__tool）声明，还原为 svc@tool
+	for _, toolCall := range agent.tools {
+		toolCall.Name = req.ResolveToolName(toolCall.Name)
+	}
 	return agent.tools != nil
 }
 
-func (agent *BaseReact) Action() string {
+// nativeThought function calling 模式下的思考：所有工具以 JSON Schema 声明，
+// 工具调用由模型以 tool_calls 返回，不再解析文本；调用 finish 工具或不再调用工具即结束
+func (agent *BaseReact) nativeThought(ctx context.Context) bool {
+	model := agent.context.ModelFor(ModelPhaseReact)
+	messages := agent.context.fitMessages(ctx, model, withNativeToolCallPrompt(agent.context.memory.GetMessages()))
+	req := llm.NewChatRequest(model, messages, append(agent.context.toolManager.AvailableTools(), finishTool{})...)
+	resp, err := agent.context.Completions(ctx, req)
+	if err != nil {
+		agent.context.memory.AddMessage(core.Message{
+			Role:    core.MessageRoleAssistant,
+			Content: fmt.Sprintf("Error during thinking: %s", err.Error()),
+		})
+		return false
+	}
+	msg := core.Message{
+		Role:    core.MessageRoleAssistant,
+		Content: resp.Content(),
+	}
+	agent.tools = nil
+	for _, callTool := range resp.CallTools() {
+		name := req.ResolveToolName(callTool.Function.Name)
+		msg.ToolCalls = append(msg.ToolCalls, core.ToolCall{
+			ID:        callTool.ID,
+			Name:      name,
+			Arguments: callTool.Function.Arguments,
+		})
+		agent.tools = append(agent.tools, &tools.ToolCall{
+			ID:    callTool.ID,
+			Name:  name,
+			Input: callTool.Function.Arguments,
+		})
+	}
+	agent.context.Send(ctx, msg)
+	agent.context.memory.AddMessage(msg)
+
+	for _, toolCall := range agent.tools {
+		if toolCall.Name == FinishToolName {
+			agent.executor.SetFinalResult(ParseFinalResult(toolCall.Input))
+			return true
+		}
+	}
+	// 没有工具调用即为最终答案
+	if len(agent.tools) == 0 {
+		agent.executor.SetFinalResult(&FinalResult{Answer: resp.Content()})
+	}
+	return true
+}
+
+// withNativeToolCallPrompt 在开头的系统消息之后插入 function calling 模式说明，
+// 说明只随请求发送，不写入记忆
+func withNativeToolCallPrompt(messages []core.Message) []core.Message {
+	i := 0
+	for i < len(messages) && messages[i].Role == core.MessageRoleSystem {
+		i++
+	}
+	result := make([]core.Message, 0, len(messages)+1)
+	result = append(result, messages[:i]...)
+	result = append(result, core.Message{
+		Role:    core.MessageRoleSystem,
+		Content: core.NativeToolCallPrompt,
+	})
+	return append(result, messages[i:]...)
+}
+
+func (agent *BaseReact) Action(ctx context.Context) string {
 	lastMessage := agent.context.memory.GetLastMessage()
 	if lastMessage.Role != core.MessageRoleAssistant {
 		return "No action needed - waiting for assistant response"
 	}
+	if agent.context.toolCallMode == ToolCallModeNative {
+		return agent.nativeAction(ctx, lastMessage)
+	}
 	// 检查是否是Finish命令
-	if strings.Contains(strings.ToLower(lastMessage.Content), "action: finish") {
-		// 提取最终答案
-		re := regexp.MustCompile(`Action:\s*Finish\[([^\]]+)\]`)
-		matches := re.FindStringSubmatch(lastMessage.Content)
-		if len(matches) >= 2 {
-			finalAnswer := strings.TrimSpace(matches[1])
-			return fmt.Sprintf("Final answer: %s", finalAnswer)
-		}
-		return "Task completed"
+	if result := agent.executor.GetFinalResult(); result != nil {
+		return fmt.Sprintf("Final answer: %s", result.Answer)
 	}
 
 	toolCalls := agent.tools
 	if len(toolCalls) == 0 {
 		return "No tool call found in assistant response"
 	}
-	exeResult := ""
 	for _, toolCall := range toolCalls {
-		// 执行工具
-		result, err := agent.context.toolManager.ExecTool(context.Background(), toolCall)
-		if err != nil {
-			// 添加错误观察
-			agent.context.memory.AddMessage(core.Message{
-				Role:    core.MessageRoleUser,
-				Content: fmt.Sprintf("Tool execution error: %s", err.Error()),
-			})
-			return fmt.Sprintf("Tool execution failed: %s", err.Error())
+		agent.executor.RecordToolCall(toolCall.Name)
+	}
+	// 执行工具：同一步骤内的调用并发执行，结果按调用顺序写入观察
+	exeResult := ""
+	for i, res := range agent.context.ExecTools(ctx, toolCalls) {
+		content := fmt.Sprintf("Observation: %s", res.Result)
+		if res.Err != nil {
+			// 单个工具失败只作为该调用的错误观察，不中断其它调用
+			content = fmt.Sprintf("Observation: Tool execution error: %s", res.Err.Error())
 		}
 		msg := core.Message{
 			Role:    core.MessageRoleUser,
-			Content: fmt.Sprintf("Observation: %s", result),
+			Content: content,
 		}
-		agent.context.Send(context.TODO(), msg)
+		agent.context.Send(ctx, msg)
 		// 添加观察结果
 		agent.context.memory.AddMessage(msg)
-		exeResult = fmt.Sprintf("Executed %s with result: %s", toolCall.Name, result)
+		if res.Err != nil {
+			exeResult = fmt.Sprintf("Tool %s execution failed: %s", toolCalls[i].Name, res.Err.Error())
+			continue
+		}
+		exeResult = fmt.Sprintf("Executed %s with result: %s", toolCalls[i].Name, res.Result)
 	}
 
 	return exeResult
 }
+
+// nativeAction 执行 tool_calls，每个调用结果（包括错误）都以带 tool_call_id 的 tool 消息返回，
+// 保证每个 tool_call 都有对应的响应
+func (agent *BaseReact) nativeAction(ctx context.Context, lastMessage core.Message) string {
+	if result := agent.executor.GetFinalResult(); result != nil {
+		// 已结束时同一响应中的其它调用不再执行，但仍需逐个回复 tool 消息
+		for _, toolCall := range agent.tools {
+			content := "Skipped: run finished"
+			if toolCall.Name == FinishToolName {
+				content, _ = finishTool{}.Handler(ctx, toolCall.Input)
+			}
+			agent.context.memory.AddMessage(core.Message{
+				Role:       core.MessageRoleTool,
+				Content:    content,
+				Name:       toolCall.Name,
+				ToolCallID: toolCall.ID,
+			})
+		}
+		return fmt.Sprintf("Final answer: %s", result.Answer)
+	}
+	calls := make([]*tools.ToolCall, 0, len(agent.tools))
+	for _, toolCall := range agent.tools {
+		agent.executor.RecordToolCall(toolCall.Name)
+		call := *toolCall
+		if tool, ok := agent.context.toolManager.GetTool(call.Name); ok {
+			call.Input = tools.ResolveInput(tool, call.Input)
+		}
+		calls = append(calls, &call)
+	}
+	exeResult := ""
+	for i, res := range agent.context.ExecTools(ctx, calls) {
+		toolCall := agent.tools[i]
+		result := res.Result
+		if res.Err != nil {
+			result = fmt.Sprintf("Tool execution error: %s", res.Err.Error())
+		}
+		msg := core.Message{
+			Role:       core.MessageRoleTool,
+			Content:    result,
+			Name:       toolCall.Name,
+			ToolCallID: toolCall.ID,
+		}
+		agent.context.Send(ctx, msg)
+		agent.context.memory.AddMessage(msg)
+		exeResult = fmt.Sprintf("Executed %s with result: %s", toolCall.Name, result)
+	}
+	return exeResult
+}
@@ -0,0 +1,172 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"jas-agent/agent/core"
+	"jas-agent/agent/llm"
+	"jas-agent/agent/tools"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+func TestTextModeResolvesMCPToolName(t *testing.T) {
+	var input string
+	tm := tools.NewToolManager()
+	tm.RegisterTool(&fakeTool{name: "svc@search", toolType: core.Mcp, handler: func(ctx context.Context, in string) (string, error) {
+		input = in
+		return "found", nil
+	}})
+	chat := &fakeChat{replies: []*llm.ChatResponse{
+		toolCallReply("call_1", "svc__search", `{"q":"go"}`),
+		textReply("Action: Finish[done]"),
+	}}
+	executor := NewAgentExecutor(NewContext(WithChat(chat), WithToolManager(tm)))
+	executor.SetSummaryPolicy(SummaryPolicy{Mode: SummaryNever})
+
+	if result := executor.Run(context.Background(), "search go"); result != "done" {
+		t.Fatalf("result = %q, want done", result)
+	}
+	if input != `{"q":"go"}` {
+		t.Fatalf("svc@search input = %q", input)
+	}
+	if calls := executor.GetToolCalls(); len(calls) != 1 || calls[0] != "svc@search" {
+		t.Fatalf("tool calls = %v", calls)
+	}
+}
+
+// outOfOrderTools slow 等到 fast 结束后才返回，被取消时返回错误；fail 返回错误
+func outOfOrderTools() *tools.ToolManager {
+	fastDone := make(chan struct{})
+	tm := tools.NewToolManager()
+	tm.RegisterTool(&fakeTool{name: "slow", handler: func(ctx context.Context, input string) (string, error) {
+		select {
+		case <-fastDone:
+			return "slow " + input, nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(5 * time.Second):
+			return "", errors.New("fast did not finish before slow")
+		}
+	}})
+	tm.RegisterTool(&fakeTool{name: "fast", handler: func(_ context.Context, input string) (string, error) {
+		defer close(fastDone)
+		return "fast " + input, nil
+	}})
+	tm.RegisterTool(&fakeTool{name: "fail", handler: func(_ context.Context, input string) (string, error) {
+		return "", fmt.Errorf("cannot handle %s", input)
+	}})
+	return tm
+}
+
+func TestExecTools(t *testing.T) {
+	tm := outOfOrderTools()
+	agentCtx := NewContext(WithToolManager(tm), WithMaxParallelTools(3))
+	calls := []*tools.ToolCall{{Name: "slow", Input: "a"}, {Name: "fail", Input: "b"}, {Name: "fast", Input: "c"}}
+
+	// 结果按调用顺序返回，失败的调用不影响其它调用
+	results := agentCtx.ExecTools(context.Background(), calls)
+	if len(results) != 3 {
+		t.Fatalf("results = %+v", results)
+	}
+	if results[0].Result != "slow a" || results[0].Err != nil {
+		t.Errorf("result 0 = %+v", results[0])
+	}
+	if results[1].Err == nil || results[1].Err.Error() != "cannot handle b" {
+		t.Errorf("result 1 = %+v", results[1])
+	}
+	if results[2].Result != "fast c" || results[2].Err != nil {
+		t.Errorf("result 2 = %+v", results[2])
+	}
+}
+
+func TestExecToolsConcurrencyLimit(t *testing.T) {
+	for _, limit := range []int{1, 2, 4} {
+		t.Run(fmt.Sprintf("limit %d", limit), func(t *testing.T) {
+			var active, peak atomic.Int32
+			tm := tools.NewToolManager()
+			tm.RegisterTool(&fakeTool{name: "work", handler: func(_ context.Context, input string) (string, error) {
+				n := active.Add(1)
+				defer active.Add(-1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				time.Sleep(20 * time.Millisecond)
+				return input, nil
+			}})
+			agentCtx := NewContext(WithToolManager(tm), WithMaxParallelTools(limit))
+			calls := make([]*tools.ToolCall, 6)
+			for i := range calls {
+				calls[i] = &tools.ToolCall{Name: "work", Input: fmt.Sprint(i)}
+			}
+
+			results := agentCtx.ExecTools(context.Background(), calls)
+			for i, res := range results {
+				if res.Result != fmt.Sprint(i) {
+					t.Fatalf("result %d = %+v", i, res)
+				}
+			}
+			if got := peak.Load(); got != int32(limit) {
+				t.Fatalf("peak concurrent calls = %d, want %d", got, limit)
+			}
+		})
+	}
+}
+
+func TestNativeActionToolMessages(t *testing.T) {
+	tm := outOfOrderTools()
+	reply := toolCallReply("call_slow", "slow", `{"input": "a"}`)
+	reply.Choices[0].Message.ToolCalls = append(reply.Choices[0].Message.ToolCalls,
+		openai.ToolCall{ID: "call_fail", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "fail", Arguments: `{"input": "b"}`}},
+		openai.ToolCall{ID: "call_fast", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "fast", Arguments: `{"input": "c"}`}},
+	)
+	chat := &fakeChat{replies: []*llm.ChatResponse{reply, textReply("done")}}
+	executor := NewAgentExecutor(NewContext(WithChat(chat), WithToolManager(tm),
+		WithToolCallMode(ToolCallModeNative), WithMaxParallelTools(3)))
+	executor.SetSummaryPolicy(SummaryPolicy{Mode: SummaryNever})
+
+	if result := executor.Run(context.Background(), "run all"); result != "done" {
+		t.Fatalf("result = %q, want done", result)
+	}
+	// 每个 tool_call 按调用顺序各有一条带对应 tool_call_id 的 tool 消息，错误也作为结果返回
+	want := []core.Message{
+		{Role: core.MessageRoleTool, Name: "slow", ToolCallID: "call_slow", Content: "slow a"},
+		{Role: core.MessageRoleTool, Name: "fail", ToolCallID: "call_fail", Content: "Tool execution error: cannot handle b"},
+		{Role: core.MessageRoleTool, Name: "fast", ToolCallID: "call_fast", Content: "fast c"},
+	}
+	var got []core.Message
+	for _, msg := range executor.context.memory.GetMessages() {
+		if msg.Role == core.MessageRoleTool {
+			got = append(got, msg)
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("tool messages = %+v", got)
+	}
+	for i := range want {
+		if got[i].Name != want[i].Name || got[i].ToolCallID != want[i].ToolCallID || got[i].Content != want[i].Content {
+			t.Errorf("tool message %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	// 模型在下一次请求中看到全部工具结果
+	if len(chat.requests) != 2 {
+		t.Fatalf("model calls = %d", len(chat.requests))
+	}
+	var toolIDs []string
+	for _, msg := range chat.requests[1].Request().Messages {
+		if msg.Role == openai.ChatMessageRoleTool {
+			toolIDs = append(toolIDs, msg.ToolCallID)
+		}
+	}
+	if fmt.Sprint(toolIDs) != "[call_slow call_fail call_fast]" {
+		t.Fatalf("tool_call_ids sent to model = %v", toolIDs)
+	}
+}
@@ -2,9 +2,14 @@ package agent
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"jas-agent/agent/core"
@@ -18,12 +23,60 @@ var chainLogger = log.NewHelper(log.With(log.NewStdLogger(os.Stdout), "module",
 // ChainNode 链式节点
 type ChainNode struct {
 	Name        string              // 节点名称
-	Agent       Agent               // 执行的Agent
+	Agent       Agent               // 执行的Agent，为 nil 时节点只合并分支结果
 	Transform   func(string) string // 输出转换函数
 	Condition   func(string) bool   // 执行条件
 	MaxSteps    int                 // 最大步数
 	NextNodes   []*ChainNode        // 下一个节点（支持分支）
 	Description string              // 节点描述
+	Prompt      string              // 节点指令，节点开始执行时与节点输入一起作为用户消息写入
+	// Input 节点输入，可引用 {{nodes.<节点名>.output}} 与 {{query}}，为空时使用上一个节点的结果
+	Input string
+	// EdgeConditions 按下一个节点名称的连线条件，以本节点的输出判断；
+	// 存在连线条件且所有下一个节点都不满足时该分支结束
+	EdgeConditions map[string]func(string) bool
+	// FanOut 为 true 时沿所有满足条件的出边并行执行，否则只沿第一条满足条件的出边继续
+	FanOut bool
+	// Merge 汇聚节点的合并方式，设置后节点等待所有可能到达本节点的分支结束，
+	// 合并结果作为节点输入（Agent 为 nil 时作为节点输出）
+	Merge    *ChainMerge
+	executor *AgentExecutor // 节点 Agent 绑定的执行器，用于感知节点结束状态
+	// seed 节点独立记忆的初始消息（节点自身的系统提示词），为 nil 时节点与链共享记忆
+	seed []core.Message
+}
+
+// ChainNodeTrace 链中一个节点的执行记录
+type ChainNodeTrace struct {
+	Node       string `json:"node"`
+	AgentType  string `json:"agent_type,omitempty"`
+	Status     string `json:"status"` // completed、failed 或 skipped
+	Input      string `json:"input,omitempty"`
+	Output     string `json:"output,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Steps      int    `json:"steps,omitempty"`
+	State      string `json:"state,omitempty"` // 节点执行器的结束状态
+	DurationMs int64  `json:"duration_ms,omitempty"`
+}
+
+const (
+	ChainNodeCompleted = "completed"
+	ChainNodeFailed    = "failed"
+	ChainNodeSkipped   = "skipped"
+)
+
+// chainBranch 到达节点的一条分支：上游节点的输出或失败原因
+type chainBranch struct {
+	From   string `json:"from"`
+	Output string `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// chainNodeRun 一个节点的执行结果
+type chainNodeRun struct {
+	node        *ChainNode
+	trace       ChainNodeTrace
+	finalResult *FinalResult
+	order       int64 // 完成顺序
 }
 
 // ChainAgent 链式Agent
@@ -31,93 +84,488 @@ type ChainAgent struct {
 	context      *Context
 	executor     *AgentExecutor
 	rootNode     *ChainNode
-	currentNode  *ChainNode
-	chainResult  map[string]string // 存储每个节点的结果
+	nodes        map[string]*ChainNode    // 按名称索引的全部节点
+	pending      []*ChainNode             // 已被激活、等待执行的节点
+	arrivals     map[string][]chainBranch // 按节点名称记录到达的分支，按完成顺序排列
+	chainResult  map[string]string        // 存储每个节点的结果
+	lastOutput   string                   // 最近完成的节点的结果
+	query        string                   // 链开始时的用户输入
+	trace        []ChainNodeTrace         // 按执行顺序的节点记录
 	systemPrompt string
 }
 
+func newChainAgent(context *Context, root *ChainNode) *ChainAgent {
+	a := &ChainAgent{
+		context:      context,
+		rootNode:     root,
+		nodes:        make(map[string]*ChainNode),
+		arrivals:     make(map[string][]chainBranch),
+		chainResult:  make(map[string]string),
+		systemPrompt: `你是一个链式执行代理，将按照预定义的流程逐步处理任务。`,
+	}
+	collectChainNodes(root, a.nodes)
+	if root != nil {
+		a.pending = []*ChainNode{root}
+	}
+	return a
+}
+
 func (a *ChainAgent) Type() AgentType {
 	return ChainAgentType
 }
 
-func (a *ChainAgent) Step() string {
-	if a.currentNode == nil {
+// Step 执行一轮：同时执行所有就绪的节点（都使用独立记忆时并发执行），再沿连线激活下一批节点，
+// 没有待执行的节点时链结束
+func (a *ChainAgent) Step(ctx context.Context) string {
+	if len(a.pending) == 0 {
 		return "Chain execution completed"
 	}
+	if len(a.chainResult) == 0 && a.query == "" {
+		a.query = latestUserInput(a.context.memory.GetMessages())
+	}
 
-	// 执行当前节点
-	nodeName := a.currentNode.Name
-	chainLogger.Infof("🔗 Executing chain node: %s", nodeName)
+	wave := a.takeReady()
+	runs := make([]*chainNodeRun, 0, len(wave))
+	for _, node := range wave {
+		if node.Condition != nil && !node.Condition(a.activation(node)) {
+			chainLogger.Infof("⏭️  Skipping node %s (condition not met)", node.Name)
+			a.trace = append(a.trace, ChainNodeTrace{Node: node.Name, AgentType: agentTypeOf(node.Agent), Status: ChainNodeSkipped})
+			// 跳过的节点将输入原样传给第一个下一个节点
+			if len(node.NextNodes) > 0 {
+				a.arrive(node.NextNodes[0], chainBranch{From: node.Name, Output: a.activation(node)})
+			}
+			continue
+		}
+		runs = append(runs, &chainNodeRun{node: node})
+	}
 
-	// 获取上一个节点的输出作为输入
-	var input string
-	if len(a.chainResult) > 0 {
-		// 找到最后一个执行的结果
-		for _, result := range a.chainResult {
-			input = result
+	var completed atomic.Int64
+	if len(runs) > 1 && allIsolated(runs) {
+		chainLogger.Infof("🔀 Executing %d chain nodes in parallel", len(runs))
+		var wg sync.WaitGroup
+		for _, run := range runs {
+			wg.Add(1)
+			go func(run *chainNodeRun) {
+				defer wg.Done()
+				a.runNode(ctx, run)
+				run.order = completed.Add(1)
+			}(run)
+		}
+		wg.Wait()
+	} else {
+		for _, run := range runs {
+			a.runNode(ctx, run)
+			run.order = completed.Add(1)
+		}
+	}
+
+	// 按完成顺序记录结果并激活下一批节点，失败的分支只通知其后的汇聚节点
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].order < runs[j].order
+	})
+	var last *chainNodeRun
+	for _, run := range runs {
+		node, trace := run.node, run.trace
+		a.trace = append(a.trace, trace)
+		a.chainResult[node.Name] = trace.Output
+		if trace.Status == ChainNodeFailed {
+			chainLogger.Warnf("❌ Node %s failed: %s", node.Name, trace.Error)
+			for _, join := range a.downstreamJoins(node) {
+				a.arrive(join, chainBranch{From: node.Name, Error: trace.Error})
+			}
+			continue
+		}
+		last = run
+		a.lastOutput = trace.Output
+		// 节点独立执行时，链的记忆中只记录各节点的结果
+		if node.seed != nil {
+			a.context.memory.AddMessage(core.Message{
+				Role:    core.MessageRoleAssistant,
+				Content: fmt.Sprintf("[%s] %s", node.Name, trace.Output),
+			})
+		}
+		chainLogger.Infof("✅ Node %s completed with result: %s", node.Name, truncateString(trace.Output, 100))
+		for _, next := range a.route(node, trace.Output) {
+			a.arrive(next, chainBranch{From: node.Name, Output: trace.Output})
 		}
 	}
 
-	// 检查执行条件
-	if a.currentNode.Condition != nil && !a.currentNode.Condition(input) {
-		chainLogger.Infof("⏭️  Skipping node %s (condition not met)", nodeName)
-		// 跳过当前节点，移到下一个
-		if len(a.currentNode.NextNodes) > 0 {
-			a.currentNode = a.currentNode.NextNodes[0]
-			return a.Step()
+	if len(a.pending) > 0 {
+		names := make([]string, 0, len(a.pending))
+		for _, node := range a.pending {
+			names = append(names, node.Name)
+		}
+		return "Moving to next nodes: " + strings.Join(names, ", ")
+	}
+	var nodeResult *FinalResult
+	if last != nil {
+		nodeResult = last.finalResult
+	}
+	return a.finish(a.lastOutput, nodeResult)
+}
+
+// runNode 执行单个节点，节点异常或未正常结束时记为失败，不影响其它分支
+func (a *ChainAgent) runNode(ctx context.Context, run *chainNodeRun) {
+	node := run.node
+	start := time.Now()
+	run.trace = ChainNodeTrace{Node: node.Name, AgentType: agentTypeOf(node.Agent)}
+	defer func() {
+		if r := recover(); r != nil {
+			run.trace.Status = ChainNodeFailed
+			run.trace.Error = fmt.Sprintf("panic: %v", r)
+		}
+		run.trace.DurationMs = time.Since(start).Milliseconds()
+	}()
+	chainLogger.Infof("🔗 Executing chain node: %s", node.Name)
+
+	var input string
+	if node.Merge != nil {
+		merged, err := a.merge(ctx, node)
+		if err != nil {
+			run.trace.Status = ChainNodeFailed
+			run.trace.Error = err.Error()
+			return
 		}
-		a.currentNode = nil
-		return "Chain execution completed (skipped)"
+		input = merged
+		if node.Input != "" {
+			input = a.renderReferences(node.Input)
+		}
+	} else {
+		input = a.nodeInput(node)
 	}
+	run.trace.Input = input
 
-	// 创建节点专用的执行器
-	nodeExecutor := &AgentExecutor{
-		context:      a.context,
-		maxSteps:     a.currentNode.MaxSteps,
-		currentStep:  0,
-		state:        IdleState,
-		agent:        a.currentNode.Agent,
-		summaryAgent: NewSummaryAgent(a.context, a.executor),
+	// 只合并分支的节点以合并结果作为输出
+	if node.Agent == nil {
+		run.trace.Status = ChainNodeCompleted
+		run.trace.Output = a.applyTransform(node, input)
+		return
 	}
 
-	// 如果有输入，添加到内存
-	if input != "" {
-		a.context.memory.AddMessage(core.Message{
+	// 使用节点 Agent 绑定的执行器运行节点，节点 Agent 提交结果后该执行器即结束
+	nodeExecutor := node.executor
+	if nodeExecutor == nil {
+		nodeExecutor = &AgentExecutor{context: a.context}
+	}
+	nodeExecutor.maxSteps = node.MaxSteps
+	nodeExecutor.currentStep = 0
+	nodeExecutor.state = IdleState
+	nodeExecutor.agent = node.Agent
+	nodeExecutor.summaryAgent = NewSummaryAgent(nodeExecutor.context, nodeExecutor)
+
+	// 独立记忆的节点每次执行都只以自身的系统提示词和节点输入开始
+	nodeMemory := nodeExecutor.context.memory
+	if node.seed != nil {
+		nodeMemory.Clear()
+		nodeMemory.AddMessages(node.seed)
+	}
+	if instruction := node.instruction(a.renderReferences(node.Prompt), input, node.seed != nil); instruction != "" {
+		nodeMemory.AddMessage(core.Message{
 			Role:    core.MessageRoleUser,
-			Content: fmt.Sprintf("基于上一步的结果继续处理: %s", input),
+			Content: instruction,
 		})
 	}
 
-	// 执行节点
-	result := nodeExecutor.Run("")
+	result := nodeExecutor.Run(ctx, "")
+	run.trace.Output = a.applyTransform(node, result)
+	run.trace.Steps = nodeExecutor.currentStep
+	run.trace.State = string(nodeExecutor.state)
+	run.finalResult = nodeExecutor.GetFinalResult()
+	if nodeExecutor.state != FinishState {
+		run.trace.Status = ChainNodeFailed
+		run.trace.Error = fmt.Sprintf("node ended in state %s: %s", nodeExecutor.state, truncateString(result, 200))
+		return
+	}
+	run.trace.Status = ChainNodeCompleted
+}
 
-	// 应用转换函数
-	if a.currentNode.Transform != nil {
-		result = a.currentNode.Transform(result)
+func (a *ChainAgent) applyTransform(node *ChainNode, output string) string {
+	if node.Transform == nil {
+		return output
 	}
+	return node.Transform(output)
+}
 
-	// 保存结果
-	a.chainResult[nodeName] = result
-	chainLogger.Infof("✅ Node %s completed with result: %s", nodeName, truncateString(result, 100))
+// takeReady 取出所有就绪的节点。汇聚节点在其它待执行节点都无法再到达它时才就绪
+func (a *ChainAgent) takeReady() []*ChainNode {
+	var ready, waiting []*ChainNode
+	for _, node := range a.pending {
+		if node.Merge != nil && a.awaitingBranches(node) {
+			waiting = append(waiting, node)
+			continue
+		}
+		ready = append(ready, node)
+	}
+	// 汇聚节点互相等待（如位于环路中）时按激活顺序执行第一个
+	if len(ready) == 0 {
+		ready, waiting = waiting[:1], waiting[1:]
+	}
+	a.pending = waiting
+	return ready
+}
 
-	// 选择下一个节点
-	if len(a.currentNode.NextNodes) == 0 {
-		a.currentNode = nil
-		a.executor.UpdateState(FinishState)
-		return result
+// awaitingBranches 是否还有其它待执行的节点可能到达 join
+func (a *ChainAgent) awaitingBranches(join *ChainNode) bool {
+	for _, node := range a.pending {
+		if node != join && chainReachable(node, join) {
+			return true
+		}
 	}
+	return false
+}
 
-	// 如果有多个下一个节点，根据结果选择（简单实现：选择第一个条件满足的）
-	for _, nextNode := range a.currentNode.NextNodes {
-		if nextNode.Condition == nil || nextNode.Condition(result) {
-			a.currentNode = nextNode
-			return "Moving to next node: " + nextNode.Name
+// route 按本节点的输出选择下一个节点：FanOut 时选择所有满足条件的节点，否则选择第一个；
+// 都不满足且未声明连线条件时使用第一个下一个节点
+func (a *ChainAgent) route(node *ChainNode, output string) []*ChainNode {
+	var targets []*ChainNode
+	for _, next := range node.NextNodes {
+		condition := node.EdgeConditions[next.Name]
+		if condition == nil {
+			condition = next.Condition
+		}
+		if condition == nil || condition(output) {
+			targets = append(targets, next)
+			if !node.FanOut {
+				break
+			}
 		}
 	}
+	if len(targets) == 0 && len(node.EdgeConditions) == 0 && len(node.NextNodes) > 0 {
+		targets = node.NextNodes[:1]
+	}
+	return targets
+}
 
-	// 如果没有满足条件的下一个节点，使用第一个
-	a.currentNode = a.currentNode.NextNodes[0]
-	return "Moving to next node: " + a.currentNode.Name
+// arrive 记录到达节点的分支，并激活该节点（已在等待中的节点不重复激活）
+func (a *ChainAgent) arrive(node *ChainNode, branch chainBranch) {
+	a.arrivals[node.Name] = append(a.arrivals[node.Name], branch)
+	for _, pending := range a.pending {
+		if pending == node {
+			return
+		}
+	}
+	a.pending = append(a.pending, node)
+}
+
+// downstreamJoins 沿连线最先遇到的汇聚节点，用于向其报告分支失败
+func (a *ChainAgent) downstreamJoins(node *ChainNode) []*ChainNode {
+	var joins []*ChainNode
+	visited := map[*ChainNode]bool{node: true}
+	queue := append([]*ChainNode(nil), node.NextNodes...)
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		if visited[next] {
+			continue
+		}
+		visited[next] = true
+		if next.Merge != nil {
+			joins = append(joins, next)
+			continue
+		}
+		queue = append(queue, next.NextNodes...)
+	}
+	return joins
+}
+
+// activation 激活节点的分支输出，用于判断节点的执行条件
+func (a *ChainAgent) activation(node *ChainNode) string {
+	if branches := a.arrivals[node.Name]; len(branches) > 0 {
+		return branches[len(branches)-1].Output
+	}
+	return a.nodeInput(node)
+}
+
+// finish 以最后完成的节点的结果结束链，最终结果附带各节点的执行记录
+func (a *ChainAgent) finish(result string, nodeResult *FinalResult) string {
+	a.pending = nil
+	finalResult := &FinalResult{Answer: result, ChainTrace: append([]ChainNodeTrace(nil), a.trace...)}
+	if nodeResult != nil {
+		finalResult.Citations = nodeResult.Citations
+		finalResult.Confidence = nodeResult.Confidence
+	}
+	a.executor.SetFinalResult(finalResult)
+	return result
+}
+
+// nodeInput 节点的输入：声明了 Input 时按引用渲染，否则为激活该节点的分支输出；
+// 独立记忆的节点作为第一个节点执行时以用户输入作为输入
+func (a *ChainAgent) nodeInput(node *ChainNode) string {
+	if node.Input != "" {
+		return a.renderReferences(node.Input)
+	}
+	if branches := a.arrivals[node.Name]; len(branches) > 0 {
+		return branches[len(branches)-1].Output
+	}
+	if len(a.chainResult) == 0 && node.seed != nil {
+		return a.query
+	}
+	return a.lastOutput
+}
+
+// chainReferencePattern 匹配 {{nodes.<节点名>.output}} 与 {{query}} 形式的引用
+var chainReferencePattern = regexp.MustCompile(`\{\{\s*(?:nodes\.([A-Za-z_][A-Za-z0-9_]*)\.output|(query))\s*\}\}`)
+
+// renderReferences 替换文本中的引用，未执行的节点替换为空字符串
+func (a *ChainAgent) renderReferences(text string) string {
+	if !strings.Contains(text, "{{") {
+		return text
+	}
+	return chainReferencePattern.ReplaceAllStringFunc(text, func(ref string) string {
+		match := chainReferencePattern.FindStringSubmatch(ref)
+		if match[2] != "" {
+			return a.query
+		}
+		return a.chainResult[match[1]]
+	})
+}
+
+// chainReferences 文本中引用的节点名称
+func chainReferences(text string) []string {
+	var names []string
+	for _, match := range chainReferencePattern.FindAllStringSubmatch(text, -1) {
+		if match[1] != "" {
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
+
+func agentTypeOf(agent Agent) string {
+	if agent == nil {
+		return ""
+	}
+	return string(agent.Type())
+}
+
+// allIsolated 节点是否都使用独立记忆，共享记忆的节点不能并发执行
+func allIsolated(runs []*chainNodeRun) bool {
+	for _, run := range runs {
+		if run.node.Agent != nil && run.node.seed == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// chainReachable 沿连线从 from 能否到达 to
+func chainReachable(from, to *ChainNode) bool {
+	visited := map[*ChainNode]bool{}
+	queue := []*ChainNode{from}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, next := range node.NextNodes {
+			if next == to {
+				return true
+			}
+			if !visited[next] {
+				visited[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return false
+}
+
+// collectChainNodes 从 node 开始收集所有可到达的节点
+func collectChainNodes(node *ChainNode, nodes map[string]*ChainNode) {
+	if node == nil || nodes[node.Name] != nil {
+		return
+	}
+	nodes[node.Name] = node
+	for _, next := range node.NextNodes {
+		collectChainNodes(next, nodes)
+	}
+}
+
+// instruction 节点开始执行时写入的用户消息，isolated 表示节点使用独立记忆，
+// 此时输入即节点看到的全部上下文
+func (node *ChainNode) instruction(prompt, input string, isolated bool) string {
+	if prompt == "" {
+		if input == "" || isolated {
+			return input
+		}
+		return fmt.Sprintf("基于上一步的结果继续处理: %s", input)
+	}
+	if input == "" {
+		return prompt
+	}
+	if isolated || node.Input != "" || node.Merge != nil {
+		return fmt.Sprintf("%s\n\n输入:\n%s", prompt, input)
+	}
+	return fmt.Sprintf("%s\n\n上一步的结果: %s", prompt, input)
+}
+
+// latestUserInput 最近一条用户消息
+func latestUserInput(messages []core.Message) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == core.MessageRoleUser {
+			return messages[i].Content
+		}
+	}
+	return ""
+}
+
+// chainState ChainAgent 的检查点状态，Pending 为空表示链已执行完毕。
+// CurrentNode 用于读取只有单个待执行节点的旧检查点
+type chainState struct {
+	CurrentNode string                   `json:"current_node,omitempty"`
+	Pending     []string                 `json:"pending"`
+	Arrivals    map[string][]chainBranch `json:"arrivals,omitempty"`
+	Results     map[string]string        `json:"results"`
+	LastOutput  string                   `json:"last_output,omitempty"`
+	Query       string                   `json:"query,omitempty"`
+	Trace       []ChainNodeTrace         `json:"trace,omitempty"`
+}
+
+// Snapshot 保存待执行的节点、到达各节点的分支与已完成节点的结果
+func (a *ChainAgent) Snapshot() (json.RawMessage, error) {
+	state := chainState{
+		Pending:    []string{},
+		Arrivals:   a.arrivals,
+		Results:    a.chainResult,
+		LastOutput: a.lastOutput,
+		Query:      a.query,
+		Trace:      a.trace,
+	}
+	for _, node := range a.pending {
+		state.Pending = append(state.Pending, node.Name)
+	}
+	return json.Marshal(state)
+}
+
+// Restore 恢复链的执行位置，中断时正在执行的节点重新执行
+func (a *ChainAgent) Restore(state json.RawMessage) error {
+	var saved chainState
+	if err := json.Unmarshal(state, &saved); err != nil {
+		return err
+	}
+	names := saved.Pending
+	if len(names) == 0 && saved.CurrentNode != "" {
+		names = []string{saved.CurrentNode}
+	}
+	pending := make([]*ChainNode, 0, len(names))
+	for _, name := range names {
+		node := a.nodes[name]
+		if node == nil {
+			return fmt.Errorf("chain node %s not found", name)
+		}
+		pending = append(pending, node)
+	}
+	a.pending = pending
+	a.arrivals = saved.Arrivals
+	a.chainResult = saved.Results
+	a.lastOutput = saved.LastOutput
+	a.query = saved.Query
+	a.trace = saved.Trace
+	if a.arrivals == nil {
+		a.arrivals = make(map[string][]chainBranch)
+	}
+	if a.chainResult == nil {
+		a.chainResult = make(map[string]string)
+	}
+	return nil
 }
 
 // ChainBuilder 链式构建器
@@ -158,6 +606,7 @@ func (b *ChainBuilder) AddNode(name string, agentType AgentType, maxSteps int) *
 		Agent:     agent,
 		MaxSteps:  maxSteps,
 		NextNodes: []*ChainNode{},
+		executor:  executor,
 	}
 
 	b.nodes[name] = node
@@ -186,6 +635,23 @@ func (b *ChainBuilder) SetCondition(nodeName string, condition func(string) bool
 	return b
 }
 
+// SetFanOut 设置节点沿所有满足条件的出边执行各分支，构建器的节点共享记忆，分支依次执行
+func (b *ChainBuilder) SetFanOut(nodeName string) *ChainBuilder {
+	if node, ok := b.nodes[nodeName]; ok {
+		node.FanOut = true
+	}
+	return b
+}
+
+// AddMergeNode 添加只合并分支结果的汇聚节点
+func (b *ChainBuilder) AddMergeNode(name string, merge ChainMerge) *ChainBuilder {
+	b.nodes[name] = &ChainNode{Name: name, Merge: &merge, NextNodes: []*ChainNode{}}
+	if b.root == nil {
+		b.root = b.nodes[name]
+	}
+	return b
+}
+
 // Link 连接两个节点
 func (b *ChainBuilder) Link(fromNode, toNode string) *ChainBuilder {
 	if from, ok := b.nodes[fromNode]; ok {
@@ -198,15 +664,7 @@ func (b *ChainBuilder) Link(fromNode, toNode string) *ChainBuilder {
 
 // Build 构建链式Agent
 func (b *ChainBuilder) Build() Agent {
-	systemPrompt := `你是一个链式执行代理，将按照预定义的流程逐步处理任务。`
-
-	return &ChainAgent{
-		context:      b.context,
-		rootNode:     b.root,
-		currentNode:  b.root,
-		chainResult:  make(map[string]string),
-		systemPrompt: systemPrompt,
-	}
+	return newChainAgent(b.context, b.root)
 }
 
 // NewChainAgentExecutor 创建链式Agent执行器
@@ -251,7 +709,7 @@ func (a *RouteAgent) Type() AgentType {
 	return "RouteAgent"
 }
 
-func (a *RouteAgent) Step() string {
+func (a *RouteAgent) Step(ctx context.Context) string {
 	// 获取用户输入
 	messages := a.context.memory.GetMessages()
 	var userInput string
@@ -273,7 +731,7 @@ func (a *RouteAgent) Step() string {
 	}
 
 	// 执行目标Agent
-	return targetAgent.Step()
+	return targetAgent.Step(ctx)
 }
 
 // NewRouteAgent 创建路由Agent
@@ -310,7 +768,7 @@ func (a *AIRouteAgent) Type() AgentType {
 	return "AIRouteAgent"
 }
 
-func (a *AIRouteAgent) Step() string {
+func (a *AIRouteAgent) Step(ctx context.Context) string {
 	// 获取用户输入
 	messages := a.context.memory.GetMessages()
 	var userInput string
@@ -342,7 +800,7 @@ func (a *AIRouteAgent) Step() string {
 		},
 	}
 
-	resp, err := a.context.chat.Completions(context.TODO(), llm.NewChatRequest(a.context.model, routeMessages))
+	resp, err := a.context.chat.Completions(ctx, llm.NewChatRequest(a.context.model, routeMessages))
 	if err != nil {
 		return fmt.Sprintf("Route selection failed: %s", err.Error())
 	}
@@ -357,7 +815,7 @@ func (a *AIRouteAgent) Step() string {
 	}
 
 	// 执行目标Agent
-	return targetAgent.Step()
+	return targetAgent.Step(ctx)
 }
 
 // NewAIRouteAgent 创建AI路由Agent
@@ -0,0 +1,157 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"jas-agent/agent/llm"
+	"jas-agent/agent/tools"
+)
+
+// buildTestChain 按 JSON 链定义创建链执行器，节点均为不做总结的 ReAct 执行器
+func buildTestChain(t *testing.T, chat *fakeChat, definition string) *AgentExecutor {
+	t.Helper()
+	spec, err := ParseChainSpec([]byte(definition))
+	if err != nil {
+		t.Fatal(err)
+	}
+	executor, err := BuildChain(NewContext(WithChat(chat), WithToolManager(tools.NewToolManager())), spec,
+		func(node ChainNodeSpec, nodeCtx *Context) (*AgentExecutor, error) {
+			nodeExecutor := NewAgentExecutor(nodeCtx)
+			nodeExecutor.SetSummaryPolicy(SummaryPolicy{Mode: SummaryNever})
+			return nodeExecutor, nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	executor.SetSummaryPolicy(SummaryPolicy{Mode: SummaryNever})
+	return executor
+}
+
+// chainTraceStatuses 按执行顺序列出节点及其状态
+func chainTraceStatuses(trace []ChainNodeTrace) []string {
+	statuses := make([]string, 0, len(trace))
+	for _, node := range trace {
+		statuses = append(statuses, node.Node+":"+node.Status)
+	}
+	return statuses
+}
+
+func TestChainRouting(t *testing.T) {
+	var mu sync.Mutex
+	inputs := map[string]string{}
+	chat := &fakeChat{respond: func(req llm.ChatRequest) (*llm.ChatResponse, error) {
+		content := lastUserContent(req)
+		node, _, _ := strings.Cut(content, "\n")
+		mu.Lock()
+		inputs[node] = content
+		mu.Unlock()
+		switch node {
+		case "classify":
+			return textReply("Action: Finish[  LOGS ]"), nil
+		case "logs":
+			return textReply("Action: Finish[error rate 5%]"), nil
+		default:
+			return textReply("Action: Finish[" + node + " done]"), nil
+		}
+	}}
+	executor := buildTestChain(t, chat, `{
+		"nodes": [
+			{"name": "classify", "type": "react", "prompt": "classify", "transform": "{{.output | trim | lower}}"},
+			{"name": "metrics", "type": "react", "prompt": "metrics"},
+			{"name": "logs", "type": "react", "prompt": "logs", "input": "{{query}} ({{nodes.classify.output}})"},
+			{"name": "report", "type": "react", "prompt": "report", "transform": "{{.nodes.classify.output}}: {{.output}}"}
+		],
+		"edges": [
+			{"from": "classify", "to": "metrics", "condition": "output == \"metrics\""},
+			{"from": "classify", "to": "logs", "condition": "contains(output, \"logs\")"},
+			{"from": "logs", "to": "report", "condition": "has_suffix(output, \"%\") && classify == \"logs\""},
+			{"from": "metrics", "to": "report"}
+		]
+	}`)
+
+	if result := executor.Run(context.Background(), "why is checkout slow?"); result != "logs: report done" {
+		t.Fatalf("result = %q", result)
+	}
+	if want := "logs\n\n输入:\nwhy is checkout slow? (logs)"; inputs["logs"] != want {
+		t.Fatalf("logs input = %q, want %q", inputs["logs"], want)
+	}
+	if want := "report\n\n输入:\nerror rate 5%"; inputs["report"] != want {
+		t.Fatalf("report input = %q, want %q", inputs["report"], want)
+	}
+	final := executor.GetFinalResult()
+	got := strings.Join(chainTraceStatuses(final.ChainTrace), " ")
+	if want := "classify:completed logs:completed report:completed"; got != want {
+		t.Fatalf("trace = %s, want %s", got, want)
+	}
+}
+
+func TestChainParallelMerge(t *testing.T) {
+	// 分支按 fast、broken、slow 的顺序完成，与定义中的顺序相反
+	fastDone, brokenDone := make(chan struct{}), make(chan struct{})
+	var fastOnce, brokenOnce sync.Once
+	chat := &fakeChat{respond: func(req llm.ChatRequest) (*llm.ChatResponse, error) {
+		node, _, _ := strings.Cut(lastUserContent(req), "\n")
+		switch node {
+		case "classify":
+			return textReply("Action: Finish[latency]"), nil
+		case "fast":
+			defer fastOnce.Do(func() { close(fastDone) })
+			return textReply("Action: Finish[fast result]"), nil
+		case "broken":
+			<-fastDone
+			time.Sleep(50 * time.Millisecond)
+			defer brokenOnce.Do(func() { close(brokenDone) })
+			// 调用不存在的工具后超出步数，节点失败
+			return textReply("Action: lookup[x]"), nil
+		case "slow":
+			<-brokenDone
+			time.Sleep(50 * time.Millisecond)
+			return textReply("Action: Finish[slow result]"), nil
+		}
+		return textReply("Action: Finish[unexpected " + node + "]"), nil
+	}}
+	executor := buildTestChain(t, chat, `{
+		"nodes": [
+			{"name": "classify", "type": "react", "prompt": "classify", "fan_out": true},
+			{"name": "slow", "type": "react", "prompt": "slow"},
+			{"name": "broken", "type": "react", "prompt": "broken", "max_steps": 1},
+			{"name": "fast", "type": "react", "prompt": "fast"},
+			{"name": "skipped", "type": "react", "prompt": "skipped"},
+			{"name": "join", "type": "merge", "transform": "{{.query}}\n{{.output}}"}
+		],
+		"edges": [
+			{"from": "classify", "to": "slow"},
+			{"from": "classify", "to": "broken"},
+			{"from": "classify", "to": "fast", "condition": "output == classify"},
+			{"from": "classify", "to": "skipped", "condition": "output == \"errors\""},
+			{"from": "slow", "to": "join"},
+			{"from": "broken", "to": "join"},
+			{"from": "fast", "to": "join"},
+			{"from": "skipped", "to": "join"}
+		]
+	}`)
+
+	result := executor.Run(context.Background(), "why is checkout slow?")
+	if executor.GetState() != FinishState {
+		t.Fatalf("state = %s, result = %q", executor.GetState(), result)
+	}
+	// concat 按分支完成顺序合并，失败的分支附带失败原因
+	fast := strings.Index(result, "[fast]\nfast result")
+	broken := strings.Index(result, "[broken] 失败: node ended in state Error")
+	slow := strings.Index(result, "[slow]\nslow result")
+	if !strings.HasPrefix(result, "why is checkout slow?\n") || fast < 0 || broken < fast || slow < broken {
+		t.Fatalf("result = %q", result)
+	}
+	if strings.Contains(result, "skipped") {
+		t.Fatalf("unselected branch was merged: %q", result)
+	}
+	final := executor.GetFinalResult()
+	got := strings.Join(chainTraceStatuses(final.ChainTrace), " ")
+	if want := "classify:completed fast:completed broken:failed slow:completed join:completed"; got != want {
+		t.Fatalf("trace = %s, want %s", got, want)
+	}
+}
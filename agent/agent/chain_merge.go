@@ -0,0 +1,148 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"jas-agent/agent/core"
+	"jas-agent/agent/llm"
+)
+
+// MergeStrategy 汇聚节点合并分支结果的方式
+type MergeStrategy string
+
+const (
+	// MergeConcat 按完成顺序拼接各分支的结果，失败的分支附带失败原因
+	MergeConcat MergeStrategy = "concat"
+	// MergeLLM 由模型综合各分支的结果
+	MergeLLM MergeStrategy = "llm"
+	// MergeFirstSuccess 使用最先成功完成的分支的结果
+	MergeFirstSuccess MergeStrategy = "first_success"
+	// MergeVote 使用多数分支给出的结果（忽略首尾空白与大小写），票数相同时取先完成的
+	MergeVote MergeStrategy = "vote"
+)
+
+// ErrAllBranchesFailed 汇聚节点的所有分支都失败
+var ErrAllBranchesFailed = errors.New("all branches failed")
+
+// ChainMerge 汇聚节点的合并配置
+type ChainMerge struct {
+	Strategy MergeStrategy
+	Prompt   string // llm 合并的指令，为空时使用默认指令
+	Model    string // llm 合并使用的模型，为空时使用链的模型
+}
+
+// ValidMergeStrategy 是否为支持的合并方式，空值表示 concat
+func ValidMergeStrategy(strategy MergeStrategy) bool {
+	switch strategy {
+	case "", MergeConcat, MergeLLM, MergeFirstSuccess, MergeVote:
+		return true
+	}
+	return false
+}
+
+const defaultMergePrompt = `以下是针对同一问题并行执行的多个分支的结果，请综合这些结果给出完整、一致的回答。
+结果之间存在冲突时指出冲突并说明更可信的结论；失败的分支只需说明缺少了哪部分信息。`
+
+// merge 按节点的合并方式合并到达该节点的分支结果
+func (a *ChainAgent) merge(ctx context.Context, node *ChainNode) (string, error) {
+	branches := a.arrivals[node.Name]
+	var succeeded []chainBranch
+	for _, branch := range branches {
+		if branch.Error == "" {
+			succeeded = append(succeeded, branch)
+		}
+	}
+	switch node.Merge.Strategy {
+	case MergeFirstSuccess:
+		if len(succeeded) == 0 {
+			return "", branchesError(branches)
+		}
+		return succeeded[0].Output, nil
+	case MergeVote:
+		if len(succeeded) == 0 {
+			return "", branchesError(branches)
+		}
+		return vote(succeeded), nil
+	case MergeLLM:
+		if len(succeeded) == 0 {
+			return "", branchesError(branches)
+		}
+		return a.mergeWithLLM(ctx, node.Merge, branches)
+	default:
+		return formatBranches(branches), nil
+	}
+}
+
+// mergeWithLLM 由模型综合各分支的结果
+func (a *ChainAgent) mergeWithLLM(ctx context.Context, merge *ChainMerge, branches []chainBranch) (string, error) {
+	prompt := merge.Prompt
+	if prompt == "" {
+		prompt = defaultMergePrompt
+	}
+	model := merge.Model
+	if model == "" {
+		model = a.context.model
+	}
+	var content strings.Builder
+	if a.query != "" {
+		content.WriteString(fmt.Sprintf("用户问题: %s\n\n", a.query))
+	}
+	content.WriteString(formatBranches(branches))
+	messages := []core.Message{
+		{Role: core.MessageRoleSystem, Content: a.renderReferences(prompt)},
+		{Role: core.MessageRoleUser, Content: content.String()},
+	}
+	resp, err := a.context.Completions(ctx, llm.NewChatRequest(model, messages))
+	if err != nil {
+		return "", fmt.Errorf("merge branches: %w", err)
+	}
+	return resp.Content(), nil
+}
+
+// formatBranches 按完成顺序列出各分支的结果
+func formatBranches(branches []chainBranch) string {
+	parts := make([]string, 0, len(branches))
+	for _, branch := range branches {
+		if branch.Error != "" {
+			parts = append(parts, fmt.Sprintf("[%s] 失败: %s", branch.From, branch.Error))
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("[%s]\n%s", branch.From, branch.Output))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// vote 多数分支给出的结果，票数相同时取先完成的
+func vote(branches []chainBranch) string {
+	counts := make(map[string]int, len(branches))
+	for _, branch := range branches {
+		counts[voteKey(branch.Output)]++
+	}
+	// 按完成顺序查找票数最多的结果，票数相同时保留先出现的
+	best, bestCount := "", 0
+	for _, branch := range branches {
+		if count := counts[voteKey(branch.Output)]; count > bestCount {
+			best, bestCount = branch.Output, count
+		}
+	}
+	return best
+}
+
+// voteKey 投票时比较的结果，忽略首尾空白与大小写
+func voteKey(output string) string {
+	return strings.ToLower(strings.TrimSpace(output))
+}
+
+func branchesError(branches []chainBranch) error {
+	if len(branches) == 0 {
+		return fmt.Errorf("%w: no branch reached the node", ErrAllBranchesFailed)
+	}
+	reasons := make([]string, 0, len(branches))
+	for _, branch := range branches {
+		reasons = append(reasons, fmt.Sprintf("%s: %s", branch.From, branch.Error))
+	}
+	return fmt.Errorf("%w: %s", ErrAllBranchesFailed, strings.Join(reasons, "; "))
+}
@@ -0,0 +1,104 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"jas-agent/agent/llm"
+)
+
+func TestChainMerge(t *testing.T) {
+	// 分支按完成顺序排列
+	branches := []chainBranch{
+		{From: "metrics", Output: "cpu saturated"},
+		{From: "logs", Error: "node ended in state Error"},
+		{From: "traces", Output: "slow db query"},
+	}
+	failed := []chainBranch{{From: "logs", Error: "timeout"}, {From: "traces", Error: "canceled"}}
+	tests := []struct {
+		name     string
+		strategy MergeStrategy
+		branches []chainBranch
+		want     string
+		wantErr  error
+	}{
+		{
+			name:     "concat",
+			strategy: MergeConcat,
+			branches: branches,
+			want:     "[metrics]\ncpu saturated\n\n[logs] 失败: node ended in state Error\n\n[traces]\nslow db query",
+		},
+		{name: "concat all failed", strategy: MergeConcat, branches: failed, want: "[logs] 失败: timeout\n\n[traces] 失败: canceled"},
+		{
+			name:     "first success skips failed branches",
+			strategy: MergeFirstSuccess,
+			branches: []chainBranch{{From: "logs", Error: "timeout"}, {From: "traces", Output: "slow db query"}, {From: "metrics", Output: "cpu"}},
+			want:     "slow db query",
+		},
+		{name: "first success all failed", strategy: MergeFirstSuccess, branches: failed, wantErr: ErrAllBranchesFailed},
+		{
+			name:     "vote",
+			strategy: MergeVote,
+			branches: []chainBranch{{From: "a", Output: "no"}, {From: "b", Output: "Yes"}, {From: "c", Error: "timeout"}, {From: "d", Output: " yes\n"}},
+			want:     "Yes",
+		},
+		{
+			// 票数相同时取先完成的分支给出的结果
+			name:     "vote tie",
+			strategy: MergeVote,
+			branches: []chainBranch{{From: "a", Output: "Yes"}, {From: "b", Output: "no"}, {From: "c", Output: "NO"}, {From: "d", Output: "yes"}},
+			want:     "Yes",
+		},
+		{name: "vote all failed", strategy: MergeVote, branches: failed, wantErr: ErrAllBranchesFailed},
+		{name: "llm all failed", strategy: MergeLLM, branches: failed, wantErr: ErrAllBranchesFailed},
+		{name: "no branch", strategy: MergeFirstSuccess, wantErr: ErrAllBranchesFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chat := &fakeChat{}
+			a := newChainAgent(NewContext(WithChat(chat)), nil)
+			node := &ChainNode{Name: "join", Merge: &ChainMerge{Strategy: tt.strategy}}
+			a.arrivals["join"] = tt.branches
+
+			got, err := a.merge(context.Background(), node)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("merge() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("merge() = %q, want %q", got, tt.want)
+			}
+			if len(chat.requests) != 0 {
+				t.Fatal("model was called for a failed merge")
+			}
+		})
+	}
+}
+
+func TestChainMergeWithLLM(t *testing.T) {
+	chat := &fakeChat{replies: []*llm.ChatResponse{textReply("the database is the bottleneck")}}
+	a := newChainAgent(NewContext(WithChat(chat), WithModel("chain-model")), nil)
+	a.query = "why is checkout slow?"
+	a.chainResult["classify"] = "latency"
+	a.arrivals["join"] = []chainBranch{{From: "metrics", Output: "cpu saturated"}, {From: "logs", Error: "timeout"}}
+	node := &ChainNode{Name: "join", Merge: &ChainMerge{Strategy: MergeLLM, Prompt: "combine the {{nodes.classify.output}} findings", Model: "merge-model"}}
+
+	got, err := a.merge(context.Background(), node)
+	if err != nil || got != "the database is the bottleneck" {
+		t.Fatalf("merge() = %q, %v", got, err)
+	}
+	req := chat.requests[0].Request()
+	if req.Model != "merge-model" {
+		t.Fatalf("merge model = %q", req.Model)
+	}
+	if req.Messages[0].Content != "combine the latency findings" {
+		t.Fatalf("merge prompt = %q", req.Messages[0].Content)
+	}
+	content := lastUserContent(chat.requests[0])
+	for _, want := range []string{"why is checkout slow?", "[metrics]\ncpu saturated", "[logs] 失败: timeout"} {
+		if !strings.Contains(content, want) {
+			t.Fatalf("merge input %q does not contain %q", content, want)
+		}
+	}
+}
@@ -0,0 +1,426 @@
+package agent
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"jas-agent/agent/core"
+	"jas-agent/agent/memory"
+
+	"github.com/Knetic/govaluate"
+	"gopkg.in/yaml.v3"
+)
+
+// ChainSpec 声明式的链定义，可用 JSON 或 YAML 描述。
+// 节点按 Start（为空时为第一个节点）开始执行，执行完一个节点后沿第一条满足条件的连线继续
+type ChainSpec struct {
+	Start string          `json:"start,omitempty" yaml:"start,omitempty"`
+	Nodes []ChainNodeSpec `json:"nodes" yaml:"nodes"`
+	Edges []ChainEdgeSpec `json:"edges,omitempty" yaml:"edges,omitempty"`
+}
+
+// ChainNodeSpec 链节点定义
+type ChainNodeSpec struct {
+	Name        string `json:"name" yaml:"name"`                                   // 节点名称，字母、数字或下划线
+	Type        string `json:"type" yaml:"type"`                                   // 节点的 Agent 类型，如 react、plan、sql
+	Description string `json:"description,omitempty" yaml:"description,omitempty"` // 节点描述
+	Prompt      string `json:"prompt,omitempty" yaml:"prompt,omitempty"`           // 节点指令，可包含引用
+	Model       string `json:"model,omitempty" yaml:"model,omitempty"`             // 节点使用的模型，为空时使用 Agent 的模型
+	// Input 节点输入，可引用之前节点的输出 {{nodes.<节点名>.output}} 与用户输入 {{query}}，
+	// 为空时使用上一个节点的结果（第一个节点为用户输入）
+	Input string `json:"input,omitempty" yaml:"input,omitempty"`
+	// Tools 节点可用的工具，支持 * 与 ? 通配（MCP 工具名为 service@tool），为空时可用全部工具
+	Tools    []string `json:"tools,omitempty" yaml:"tools,omitempty"`
+	MaxSteps int      `json:"max_steps,omitempty" yaml:"max_steps,omitempty"` // 节点最大步数，为空时使用该类型的默认值
+	// Condition 执行条件表达式，以上一个节点的输出判断，不满足时跳过该节点
+	Condition string `json:"condition,omitempty" yaml:"condition,omitempty"`
+	// Transform 输出转换模版（text/template），结果作为节点的输出
+	Transform string `json:"transform,omitempty" yaml:"transform,omitempty"`
+	// ConnectionConfig 节点的连接配置（如 sql、elasticsearch 节点），为空时使用 Agent 的连接配置
+	ConnectionConfig map[string]any `json:"connection_config,omitempty" yaml:"connection_config,omitempty"`
+	// FanOut 为 true 时沿所有满足条件的出边并行执行
+	FanOut bool `json:"fan_out,omitempty" yaml:"fan_out,omitempty"`
+	// Merge 设置后节点作为汇聚节点，等待所有分支结束后合并结果；merge 类型的节点只合并不执行 Agent
+	Merge *ChainMergeSpec `json:"merge,omitempty" yaml:"merge,omitempty"`
+}
+
+// ChainMergeSpec 汇聚节点的合并配置
+type ChainMergeSpec struct {
+	Strategy string `json:"strategy,omitempty" yaml:"strategy,omitempty"` // concat（默认）、llm、first_success 或 vote
+	Prompt   string `json:"prompt,omitempty" yaml:"prompt,omitempty"`     // llm 合并的指令
+	Model    string `json:"model,omitempty" yaml:"model,omitempty"`       // llm 合并使用的模型
+}
+
+// ChainNodeTypeMerge 只合并分支结果、不执行 Agent 的节点类型
+const ChainNodeTypeMerge = "merge"
+
+// ChainEdgeSpec 节点间的连线，Condition 以 From 节点的输出判断，为空表示无条件
+type ChainEdgeSpec struct {
+	From      string `json:"from" yaml:"from"`
+	To        string `json:"to" yaml:"to"`
+	Condition string `json:"condition,omitempty" yaml:"condition,omitempty"`
+}
+
+// ChainNodeFactory 按节点定义在节点上下文中创建执行器
+type ChainNodeFactory func(spec ChainNodeSpec, nodeCtx *Context) (*AgentExecutor, error)
+
+var chainNodeNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// 表达式中的保留变量名，节点不能使用
+var chainReservedNames = map[string]bool{"output": true, "query": true, "nodes": true}
+
+// chainExpressionFunctions 条件表达式可用的函数
+var chainExpressionFunctions = map[string]govaluate.ExpressionFunction{
+	"contains": func(args ...any) (any, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("contains(s, substr) expects 2 arguments")
+		}
+		return strings.Contains(fmt.Sprint(args[0]), fmt.Sprint(args[1])), nil
+	},
+	"has_prefix": func(args ...any) (any, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("has_prefix(s, prefix) expects 2 arguments")
+		}
+		return strings.HasPrefix(fmt.Sprint(args[0]), fmt.Sprint(args[1])), nil
+	},
+	"has_suffix": func(args ...any) (any, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("has_suffix(s, suffix) expects 2 arguments")
+		}
+		return strings.HasSuffix(fmt.Sprint(args[0]), fmt.Sprint(args[1])), nil
+	},
+	"lower": func(args ...any) (any, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("lower(s) expects 1 argument")
+		}
+		return strings.ToLower(fmt.Sprint(args[0])), nil
+	},
+	"len": func(args ...any) (any, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("len(s) expects 1 argument")
+		}
+		return float64(len([]rune(fmt.Sprint(args[0])))), nil
+	},
+}
+
+// chainTemplateFunctions 转换模版可用的函数
+var chainTemplateFunctions = template.FuncMap{
+	"trim":     strings.TrimSpace,
+	"lower":    strings.ToLower,
+	"upper":    strings.ToUpper,
+	"replace":  strings.ReplaceAll,
+	"contains": strings.Contains,
+	"truncate": func(n int, s string) string { return truncateString(s, n) },
+}
+
+// ParseChainSpec 解析 JSON 或 YAML 格式的链定义
+func ParseChainSpec(data []byte) (*ChainSpec, error) {
+	spec := &ChainSpec{}
+	// JSON 是 YAML 的子集，统一按 YAML 解析
+	if err := yaml.Unmarshal(data, spec); err != nil {
+		return nil, fmt.Errorf("parse chain spec: %w", err)
+	}
+	return spec, nil
+}
+
+// Validate 校验链定义：节点名称唯一，连线与起始节点存在且不成环，条件表达式与转换模版可以编译，
+// validType 校验节点类型，为 nil 时不校验
+func (spec *ChainSpec) Validate(validType func(string) bool) error {
+	if len(spec.Nodes) == 0 {
+		return fmt.Errorf("chain has no nodes")
+	}
+	names := make(map[string]bool, len(spec.Nodes))
+	for _, node := range spec.Nodes {
+		if !chainNodeNamePattern.MatchString(node.Name) || chainReservedNames[node.Name] {
+			return fmt.Errorf("invalid chain node name %q", node.Name)
+		}
+		if names[node.Name] {
+			return fmt.Errorf("duplicate chain node %q", node.Name)
+		}
+		names[node.Name] = true
+		if node.Type != ChainNodeTypeMerge && validType != nil && !validType(node.Type) {
+			return fmt.Errorf("chain node %s: unsupported type %q", node.Name, node.Type)
+		}
+		if node.Merge != nil && !ValidMergeStrategy(MergeStrategy(node.Merge.Strategy)) {
+			return fmt.Errorf("chain node %s: unknown merge strategy %q", node.Name, node.Merge.Strategy)
+		}
+		if node.MaxSteps < 0 {
+			return fmt.Errorf("chain node %s: max_steps must not be negative", node.Name)
+		}
+		for _, pattern := range node.Tools {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("chain node %s: invalid tool pattern %q", node.Name, pattern)
+			}
+		}
+		if _, err := compileChainCondition(node.Condition); err != nil {
+			return fmt.Errorf("chain node %s: %w", node.Name, err)
+		}
+		if _, err := compileChainTransform(node.Name, node.Transform); err != nil {
+			return fmt.Errorf("chain node %s: %w", node.Name, err)
+		}
+	}
+	if spec.Start != "" && !names[spec.Start] {
+		return fmt.Errorf("chain start node %q not found", spec.Start)
+	}
+	for _, edge := range spec.Edges {
+		if !names[edge.From] || !names[edge.To] {
+			return fmt.Errorf("chain edge %s -> %s refers to an unknown node", edge.From, edge.To)
+		}
+		if _, err := compileChainCondition(edge.Condition); err != nil {
+			return fmt.Errorf("chain edge %s -> %s: %w", edge.From, edge.To, err)
+		}
+	}
+	// 连线成环时链可能无限执行
+	if cycle := spec.cycle(); cycle != nil {
+		return fmt.Errorf("chain has a cycle: %s", strings.Join(cycle, " -> "))
+	}
+	// 引用的节点必须在本节点之前执行
+	for _, node := range spec.Nodes {
+		upstream := spec.upstream(node.Name)
+		for _, ref := range append(chainReferences(node.Prompt), chainReferences(node.Input)...) {
+			if !names[ref] {
+				return fmt.Errorf("chain node %s references unknown node %q", node.Name, ref)
+			}
+			if !upstream[ref] {
+				return fmt.Errorf("chain node %s references node %q that does not run before it", node.Name, ref)
+			}
+		}
+	}
+	return nil
+}
+
+// cycle 连线构成的环，返回环上的节点名称（首尾相同），无环时返回 nil
+func (spec *ChainSpec) cycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	next := make(map[string][]string, len(spec.Nodes))
+	for _, edge := range spec.Edges {
+		next[edge.From] = append(next[edge.From], edge.To)
+	}
+	state := make(map[string]int, len(spec.Nodes))
+	var path []string
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		state[name] = visiting
+		path = append(path, name)
+		for _, to := range next[name] {
+			switch state[to] {
+			case visiting:
+				for i, node := range path {
+					if node == to {
+						return append(append([]string(nil), path[i:]...), to)
+					}
+				}
+			case unvisited:
+				if cycle := visit(to); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+	for _, node := range spec.Nodes {
+		if state[node.Name] != unvisited {
+			continue
+		}
+		if cycle := visit(node.Name); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// upstream 沿连线能够到达 name 的节点
+func (spec *ChainSpec) upstream(name string) map[string]bool {
+	result := map[string]bool{}
+	queue := []string{name}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, edge := range spec.Edges {
+			if edge.To == current && !result[edge.From] {
+				result[edge.From] = true
+				queue = append(queue, edge.From)
+			}
+		}
+	}
+	return result
+}
+
+// BuildChain 按链定义创建 ChainAgent 执行器。每个节点在独立记忆的子上下文中创建，
+// 可使用独立的模型与工具；节点记忆以链的系统提示词开始，执行时只写入节点输入
+func BuildChain(context *Context, spec *ChainSpec, factory ChainNodeFactory) (*AgentExecutor, error) {
+	if err := spec.Validate(nil); err != nil {
+		return nil, err
+	}
+	// 条件与转换在执行时读取链的结果，先创建 ChainAgent 供其引用
+	ca := newChainAgent(context, nil)
+	nodes := ca.nodes
+	for _, nodeSpec := range spec.Nodes {
+		if nodeSpec.Type == ChainNodeTypeMerge {
+			node := &ChainNode{
+				Name:           nodeSpec.Name,
+				Description:    nodeSpec.Description,
+				Input:          nodeSpec.Input,
+				EdgeConditions: map[string]func(string) bool{},
+				FanOut:         nodeSpec.FanOut,
+				Merge:          nodeSpec.merge(),
+			}
+			ca.bindExpressions(node, nodeSpec)
+			nodes[node.Name] = node
+			continue
+		}
+		// 每个节点使用独立的工具管理器，节点创建时注册的工具（如 sql 节点的数据库工具）不影响其他节点
+		patterns := nodeSpec.Tools
+		if len(patterns) == 0 {
+			patterns = []string{"*"}
+		}
+		nodeMemory := memory.NewMemory()
+		nodeMemory.AddMessages(leadingSystemMessages(context.memory.GetMessages()))
+		opts := []Option{WithToolManager(context.toolManager.Subset(patterns)), WithMemory(nodeMemory)}
+		if nodeSpec.Model != "" {
+			opts = append(opts, WithModel(nodeSpec.Model), WithoutPhaseModels())
+		}
+		executor, err := factory(nodeSpec, context.Derive(opts...))
+		if err != nil {
+			return nil, fmt.Errorf("create chain node %s: %w", nodeSpec.Name, err)
+		}
+		node := &ChainNode{
+			Name:           nodeSpec.Name,
+			Agent:          executor.agent,
+			MaxSteps:       executor.maxSteps,
+			Description:    nodeSpec.Description,
+			Prompt:         nodeSpec.Prompt,
+			Input:          nodeSpec.Input,
+			EdgeConditions: map[string]func(string) bool{},
+			FanOut:         nodeSpec.FanOut,
+			executor:       executor,
+			seed:           executor.context.memory.GetMessages(),
+		}
+		if nodeSpec.Merge != nil {
+			node.Merge = nodeSpec.merge()
+		}
+		if nodeSpec.MaxSteps > 0 {
+			node.MaxSteps = nodeSpec.MaxSteps
+		}
+		ca.bindExpressions(node, nodeSpec)
+		nodes[node.Name] = node
+	}
+	for _, edge := range spec.Edges {
+		from, to := nodes[edge.From], nodes[edge.To]
+		from.NextNodes = append(from.NextNodes, to)
+		condition, _ := compileChainCondition(edge.Condition)
+		if condition != nil {
+			from.EdgeConditions[to.Name] = ca.condition(edge.From+"->"+edge.To, condition)
+		}
+	}
+	start := spec.Start
+	if start == "" {
+		start = spec.Nodes[0].Name
+	}
+	ca.rootNode = nodes[start]
+	ca.pending = []*ChainNode{ca.rootNode}
+	return NewChainAgentExecutor(context, ca), nil
+}
+
+// bindExpressions 设置节点的执行条件与输出转换
+func (a *ChainAgent) bindExpressions(node *ChainNode, spec ChainNodeSpec) {
+	condition, _ := compileChainCondition(spec.Condition)
+	node.Condition = a.condition(spec.Name, condition)
+	transform, _ := compileChainTransform(spec.Name, spec.Transform)
+	node.Transform = a.transform(spec.Name, transform)
+}
+
+// merge 汇聚节点的合并配置，merge 类型的节点未配置时按 concat 合并
+func (spec ChainNodeSpec) merge() *ChainMerge {
+	if spec.Merge == nil {
+		return &ChainMerge{Strategy: MergeConcat}
+	}
+	strategy := MergeStrategy(spec.Merge.Strategy)
+	if strategy == "" {
+		strategy = MergeConcat
+	}
+	return &ChainMerge{Strategy: strategy, Prompt: spec.Merge.Prompt, Model: spec.Merge.Model}
+}
+
+// leadingSystemMessages 开头的系统消息
+func leadingSystemMessages(messages []core.Message) []core.Message {
+	i := 0
+	for i < len(messages) && messages[i].Role == core.MessageRoleSystem {
+		i++
+	}
+	return append([]core.Message(nil), messages[:i]...)
+}
+
+func compileChainCondition(expression string) (*govaluate.EvaluableExpression, error) {
+	if strings.TrimSpace(expression) == "" {
+		return nil, nil
+	}
+	expr, err := govaluate.NewEvaluableExpressionWithFunctions(expression, chainExpressionFunctions)
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition %q: %w", expression, err)
+	}
+	return expr, nil
+}
+
+func compileChainTransform(name, text string) (*template.Template, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New(name).Funcs(chainTemplateFunctions).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transform: %w", err)
+	}
+	return tmpl, nil
+}
+
+// condition 将条件表达式包装为节点条件，表达式中 output 为被判断的输出，query 为链开始时的用户输入，
+// 已完成的节点以节点名称引用其结果。求值失败或结果不是布尔值时视为不满足
+func (a *ChainAgent) condition(name string, expr *govaluate.EvaluableExpression) func(string) bool {
+	if expr == nil {
+		return nil
+	}
+	return func(output string) bool {
+		params := map[string]any{"output": output, "query": a.query}
+		for node, result := range a.chainResult {
+			params[node] = result
+		}
+		value, err := expr.Evaluate(params)
+		if err != nil {
+			chainLogger.Warnf("evaluate condition of %s: %v", name, err)
+			return false
+		}
+		matched, ok := value.(bool)
+		return ok && matched
+	}
+}
+
+// transform 将转换模版包装为节点转换函数，模版中 .output 为节点输出，.query 为链开始时的用户输入，
+// .nodes.<节点名>.output 为已完成节点的结果。渲染失败时保留原输出
+func (a *ChainAgent) transform(name string, tmpl *template.Template) func(string) string {
+	if tmpl == nil {
+		return nil
+	}
+	return func(output string) string {
+		var buf bytes.Buffer
+		nodes := make(map[string]map[string]string, len(a.chainResult))
+		for node, result := range a.chainResult {
+			nodes[node] = map[string]string{"output": result}
+		}
+		data := map[string]any{"output": output, "query": a.query, "nodes": nodes}
+		if err := tmpl.Execute(&buf, data); err != nil {
+			chainLogger.Warnf("render transform of %s: %v", name, err)
+			return output
+		}
+		return buf.String()
+	}
+}
@@ -0,0 +1,233 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChainSpecValidate(t *testing.T) {
+	validType := func(agentType string) bool {
+		return agentType == "react" || agentType == "plan"
+	}
+	tests := []struct {
+		name string
+		spec string
+		// wantErr 错误信息应包含的内容，为空表示校验通过
+		wantErr string
+	}{
+		{
+			name: "valid",
+			spec: `{
+				"start": "classify",
+				"nodes": [
+					{"name": "classify", "type": "react", "transform": "{{.output | trim | lower}}"},
+					{"name": "logs", "type": "react", "tools": ["es@*"], "input": "{{query}} {{nodes.classify.output}}"},
+					{"name": "metrics", "type": "plan", "max_steps": 3, "condition": "contains(output, \"metrics\")"},
+					{"name": "join", "type": "merge", "merge": {"strategy": "vote"}},
+					{"name": "report", "type": "react", "prompt": "summarize {{nodes.logs.output}}"}
+				],
+				"edges": [
+					{"from": "classify", "to": "logs", "condition": "classify == \"logs\""},
+					{"from": "classify", "to": "metrics"},
+					{"from": "logs", "to": "join"},
+					{"from": "metrics", "to": "join"},
+					{"from": "join", "to": "report"}
+				]
+			}`,
+		},
+		{
+			name: "valid yaml",
+			spec: `
+nodes:
+  - name: a
+    type: react
+  - name: b
+    type: react
+    input: "{{nodes.a.output}}"
+edges:
+  - from: a
+    to: b
+`,
+		},
+		{name: "no nodes", spec: `{"nodes": []}`, wantErr: "chain has no nodes"},
+		{name: "invalid name", spec: `{"nodes": [{"name": "1st", "type": "react"}]}`, wantErr: `invalid chain node name "1st"`},
+		{name: "reserved name", spec: `{"nodes": [{"name": "output", "type": "react"}]}`, wantErr: `invalid chain node name "output"`},
+		{
+			name:    "duplicate node",
+			spec:    `{"nodes": [{"name": "a", "type": "react"}, {"name": "a", "type": "plan"}]}`,
+			wantErr: `duplicate chain node "a"`,
+		},
+		{name: "unsupported type", spec: `{"nodes": [{"name": "a", "type": "chain"}]}`, wantErr: `unsupported type "chain"`},
+		{
+			name:    "unknown merge strategy",
+			spec:    `{"nodes": [{"name": "a", "type": "merge", "merge": {"strategy": "average"}}]}`,
+			wantErr: `unknown merge strategy "average"`,
+		},
+		{name: "negative max steps", spec: `{"nodes": [{"name": "a", "type": "react", "max_steps": -1}]}`, wantErr: "max_steps must not be negative"},
+		{name: "invalid tool pattern", spec: `{"nodes": [{"name": "a", "type": "react", "tools": ["["]}]}`, wantErr: `invalid tool pattern "["`},
+		{name: "invalid condition", spec: `{"nodes": [{"name": "a", "type": "react", "condition": "output =="}]}`, wantErr: "invalid condition"},
+		{name: "invalid transform", spec: `{"nodes": [{"name": "a", "type": "react", "transform": "{{.output"}]}`, wantErr: "invalid transform"},
+		{name: "unknown start", spec: `{"start": "b", "nodes": [{"name": "a", "type": "react"}]}`, wantErr: `chain start node "b" not found`},
+		{
+			name:    "unknown edge node",
+			spec:    `{"nodes": [{"name": "a", "type": "react"}], "edges": [{"from": "a", "to": "b"}]}`,
+			wantErr: "chain edge a -> b refers to an unknown node",
+		},
+		{
+			name:    "invalid edge condition",
+			spec:    `{"nodes": [{"name": "a", "type": "react"}, {"name": "b", "type": "react"}], "edges": [{"from": "a", "to": "b", "condition": "(output"}]}`,
+			wantErr: "chain edge a -> b: invalid condition",
+		},
+		{
+			name:    "unknown reference",
+			spec:    `{"nodes": [{"name": "a", "type": "react", "input": "{{nodes.missing.output}}"}]}`,
+			wantErr: `chain node a references unknown node "missing"`,
+		},
+		{
+			name: "reference to a later node",
+			spec: `{
+				"nodes": [{"name": "a", "type": "react"}, {"name": "b", "type": "react", "prompt": "use {{nodes.c.output}}"}, {"name": "c", "type": "react"}],
+				"edges": [{"from": "a", "to": "b"}, {"from": "b", "to": "c"}]
+			}`,
+			wantErr: `chain node b references node "c" that does not run before it`,
+		},
+		{
+			name: "reference to a parallel branch",
+			spec: `{
+				"nodes": [{"name": "a", "type": "react", "fan_out": true}, {"name": "b", "type": "react"}, {"name": "c", "type": "react", "input": "{{nodes.b.output}}"}],
+				"edges": [{"from": "a", "to": "b"}, {"from": "a", "to": "c"}]
+			}`,
+			wantErr: `chain node c references node "b" that does not run before it`,
+		},
+		{
+			name: "cycle",
+			spec: `{
+				"nodes": [{"name": "a", "type": "react"}, {"name": "b", "type": "react"}, {"name": "c", "type": "react"}],
+				"edges": [{"from": "a", "to": "b"}, {"from": "b", "to": "c"}, {"from": "c", "to": "b", "condition": "output != \"done\""}]
+			}`,
+			wantErr: "chain has a cycle: b -> c -> b",
+		},
+		{
+			name:    "self loop",
+			spec:    `{"nodes": [{"name": "a", "type": "react"}], "edges": [{"from": "a", "to": "a"}]}`,
+			wantErr: "chain has a cycle: a -> a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := ParseChainSpec([]byte(tt.spec))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = spec.Validate(validType)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseChainSpec(t *testing.T) {
+	spec, err := ParseChainSpec([]byte(`
+start: classify
+nodes:
+  - name: classify
+    type: react
+    fan_out: true
+  - name: join
+    type: merge
+    merge:
+      strategy: llm
+      prompt: combine
+edges:
+  - from: classify
+    to: join
+    condition: len(output) > 0
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.Start != "classify" || len(spec.Nodes) != 2 || !spec.Nodes[0].FanOut {
+		t.Fatalf("spec = %+v", spec)
+	}
+	if merge := spec.Nodes[1].Merge; merge == nil || merge.Strategy != "llm" || merge.Prompt != "combine" {
+		t.Fatalf("merge = %+v", merge)
+	}
+	if len(spec.Edges) != 1 || spec.Edges[0].Condition != "len(output) > 0" {
+		t.Fatalf("edges = %+v", spec.Edges)
+	}
+	if _, err := ParseChainSpec([]byte(`{"nodes": "a"}`)); err == nil {
+		t.Fatal("ParseChainSpec() accepted nodes that are not a list")
+	}
+}
+
+func TestChainCondition(t *testing.T) {
+	tests := []struct {
+		expression string
+		output     string
+		want       bool
+	}{
+		{`output == "logs"`, "logs", true},
+		{`output == "logs"`, "metrics", false},
+		{`contains(lower(output), "error")`, "Found ERROR in service", true},
+		{`has_prefix(output, "SELECT") && len(output) > 10`, "SELECT * FROM t", true},
+		{`has_prefix(output, "SELECT") && len(output) > 10`, "SELECT 1", false},
+		{`has_suffix(query, "?")`, "", true},
+		// 已完成的节点以名称引用其结果
+		{`classify == "metrics" && output != ""`, "cpu", true},
+		// 求值失败或结果不是布尔值时视为不满足
+		{`unknown == "x"`, "x", false},
+		{`len(output)`, "abc", false},
+		{`contains(output)`, "abc", false},
+	}
+	a := newChainAgent(NewContext(), nil)
+	a.query = "why is checkout slow?"
+	a.chainResult["classify"] = "metrics"
+	for _, tt := range tests {
+		expr, err := compileChainCondition(tt.expression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := a.condition("test", expr)(tt.output); got != tt.want {
+			t.Errorf("condition %q on %q = %v, want %v", tt.expression, tt.output, got, tt.want)
+		}
+	}
+	if condition := a.condition("empty", nil); condition != nil {
+		t.Error("empty condition should not be bound")
+	}
+}
+
+func TestChainTransform(t *testing.T) {
+	tests := []struct {
+		transform string
+		output    string
+		want      string
+	}{
+		{`{{.output | trim | upper}}`, "  ok \n", "OK"},
+		{`{{.query}}: {{.output}}`, "slow queries", "why is checkout slow?: slow queries"},
+		{`{{.nodes.classify.output}}/{{.output}}`, "p99", "metrics/p99"},
+		{`[{{.nodes.missing.output}}]`, "x", "[]"},
+		{`{{truncate 3 .output}}`, "abcdef", "abc..."},
+		{`{{if contains .output "error"}}failed{{else}}{{replace .output "-" " "}}{{end}}`, "all-good", "all good"},
+		// 渲染失败时保留原输出
+		{`{{.output.Field}}`, "unchanged", "unchanged"},
+	}
+	a := newChainAgent(NewContext(), nil)
+	a.query = "why is checkout slow?"
+	a.chainResult["classify"] = "metrics"
+	for _, tt := range tests {
+		tmpl, err := compileChainTransform("test", tt.transform)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := a.transform("test", tmpl)(tt.output); got != tt.want {
+			t.Errorf("transform %q on %q = %q, want %q", tt.transform, tt.output, got, tt.want)
+		}
+	}
+}
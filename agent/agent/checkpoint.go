@@ -0,0 +1,181 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+
+	"jas-agent/agent/core"
+	"jas-agent/agent/llm"
+	"jas-agent/agent/memory"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+var checkpointLogger = log.NewHelper(log.With(log.NewStdLogger(os.Stdout), "module", "agent/checkpoint"))
+
+// Checkpoint 执行器在某一步结束后的可恢复状态
+type Checkpoint struct {
+	AgentType   AgentType      `json:"agent_type"`
+	State       State          `json:"state"`
+	CurrentStep int            `json:"current_step"`
+	MaxSteps    int            `json:"max_steps"`
+	Messages    []core.Message `json:"messages"`
+	ToolCalls   []string       `json:"tool_calls,omitempty"`
+	Usage       llm.Usage      `json:"usage"`
+	FinalResult *FinalResult   `json:"final_result,omitempty"`
+	// AgentState Agent 自身的执行状态，如 PlanAgent 的计划、ChainAgent 的节点结果
+	AgentState json.RawMessage `json:"agent_state,omitempty"`
+	// RunStart 本次运行写入的第一条消息（用户输入）在 Messages 非系统消息中的位置，之前的为会话历史
+	RunStart int `json:"run_start"`
+}
+
+// Stateful 在记忆之外还持有执行状态的 Agent 实现该接口，以便检查点保存与恢复
+type Stateful interface {
+	Snapshot() (json.RawMessage, error)
+	Restore(state json.RawMessage) error
+}
+
+// Checkpointer 保存检查点，执行器在用户输入写入记忆后以及每一步结束后调用
+type Checkpointer interface {
+	Save(ctx context.Context, checkpoint *Checkpoint) error
+}
+
+// CheckpointerFunc 函数形式的 Checkpointer
+type CheckpointerFunc func(ctx context.Context, checkpoint *Checkpoint) error
+
+func (f CheckpointerFunc) Save(ctx context.Context, checkpoint *Checkpoint) error {
+	return f(ctx, checkpoint)
+}
+
+// SetCheckpointer 设置检查点保存方式，nil 表示不保存
+func (agent *AgentExecutor) SetCheckpointer(checkpointer Checkpointer) {
+	agent.checkpointer = checkpointer
+}
+
+// Snapshot 生成当前状态的检查点
+func (agent *AgentExecutor) Snapshot() (*Checkpoint, error) {
+	checkpoint := &Checkpoint{
+		AgentType:   agent.GetAgentType(),
+		State:       agent.state,
+		CurrentStep: agent.currentStep,
+		MaxSteps:    agent.maxSteps,
+		Messages:    memory.Messages(agent.context.memory),
+		RunStart:    agent.runStart,
+		ToolCalls:   agent.GetToolCalls(),
+		Usage:       agent.context.GetUsage(),
+		FinalResult: agent.finalResult,
+	}
+	if stateful, ok := agent.agent.(Stateful); ok {
+		state, err := stateful.Snapshot()
+		if err != nil {
+			return nil, fmt.Errorf("snapshot %s: %w", checkpoint.AgentType, err)
+		}
+		checkpoint.AgentState = state
+	}
+	return checkpoint, nil
+}
+
+// Restore 从检查点恢复状态，执行器需由同一 Agent 配置重新创建。
+// 系统提示词在创建执行器时重新生成，不从检查点恢复；
+// 只恢复本次运行写入的消息，并以重新加载的会话历史为准：运行中断后会话新增的轮次保留，
+// 已写入会话的本次运行消息（persist_all）不会重复写入
+func (agent *AgentExecutor) Restore(checkpoint *Checkpoint) error {
+	if agentType := agent.GetAgentType(); checkpoint.AgentType != "" && checkpoint.AgentType != agentType {
+		return fmt.Errorf("restore checkpoint: agent type mismatch: %s != %s", checkpoint.AgentType, agentType)
+	}
+	if stateful, ok := agent.agent.(Stateful); ok && len(checkpoint.AgentState) > 0 {
+		if err := stateful.Restore(checkpoint.AgentState); err != nil {
+			return fmt.Errorf("restore %s: %w", checkpoint.AgentType, err)
+		}
+	}
+	saved := nonSystemMessages(checkpoint.Messages)
+	if checkpoint.RunStart < 0 || checkpoint.RunStart > len(saved) {
+		return fmt.Errorf("restore checkpoint: run start %d out of range [0, %d]", checkpoint.RunStart, len(saved))
+	}
+	runMessages := saved[checkpoint.RunStart:]
+	existing := nonSystemMessages(memory.Messages(agent.context.memory))
+	start, restored := locateRun(existing, runMessages)
+	agent.context.memory.AddMessages(runMessages[restored:])
+	agent.runStart = start
+	agent.state = checkpoint.State
+	agent.currentStep = checkpoint.CurrentStep
+	if checkpoint.MaxSteps > 0 {
+		agent.maxSteps = checkpoint.MaxSteps
+	}
+	agent.toolCalls = append([]string(nil), checkpoint.ToolCalls...)
+	agent.finalResult = checkpoint.FinalResult
+	if agent.context.usage != nil {
+		agent.context.usage.Observe(context.Background(), "", checkpoint.Usage)
+	}
+	return nil
+}
+
+// Resume 从恢复的检查点继续运行，不再写入用户输入。
+// 检查点已处于完成状态（如在总结阶段中断）时只重新执行总结
+func (agent *AgentExecutor) Resume(ctx context.Context) string {
+	if agent.state != FinishState {
+		agent.state = RunningState
+	}
+	return agent.run(ctx)
+}
+
+// checkpoint 保存检查点，失败时只记录日志，不影响运行。
+// 运行被取消或超时时当前步骤可能只执行了一半，不保存检查点，恢复时从上一个检查点重新执行该步
+func (agent *AgentExecutor) checkpoint(ctx context.Context) {
+	if agent.checkpointer == nil || ctx.Err() != nil {
+		return
+	}
+	checkpoint, err := agent.Snapshot()
+	if err == nil {
+		err = agent.checkpointer.Save(ctx, checkpoint)
+	}
+	if err != nil {
+		checkpointLogger.Warnf("save checkpoint at step %d: %v", agent.currentStep, err)
+	}
+}
+
+func nonSystemMessages(messages []core.Message) []core.Message {
+	result := make([]core.Message, 0, len(messages))
+	for _, msg := range messages {
+		if msg.Role != core.MessageRoleSystem {
+			result = append(result, msg)
+		}
+	}
+	return result
+}
+
+// locateRun 在记忆的非系统消息中查找已写入的本次运行消息，返回运行开始的位置与已写入的消息数。
+// 已写入的部分须与 runMessages 开头一致，且一直延续到记忆末尾或覆盖全部 runMessages；
+// 有多处时取写入最多、位置最靠后的一处，没有时运行从记忆末尾开始
+func locateRun(existing, runMessages []core.Message) (int, int) {
+	start, restored := len(existing), 0
+	for i := range existing {
+		n := 0
+		for i+n < len(existing) && n < len(runMessages) && sameMessage(existing[i+n], runMessages[n]) {
+			n++
+		}
+		if n == 0 || (i+n < len(existing) && n < len(runMessages)) {
+			continue
+		}
+		if n >= restored {
+			start, restored = i, n
+		}
+	}
+	return start, restored
+}
+
+// sameMessage 是否为同一条发送给模型的消息，忽略只用于推送的字段
+func sameMessage(a, b core.Message) bool {
+	if a.Role != b.Role || a.Content != b.Content || a.Name != b.Name || a.ToolCallID != b.ToolCallID || len(a.ToolCalls) != len(b.ToolCalls) {
+		return false
+	}
+	for i := range a.ToolCalls {
+		if !reflect.DeepEqual(a.ToolCalls[i], b.ToolCalls[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,173 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"jas-agent/agent/core"
+	"jas-agent/agent/llm"
+	"jas-agent/agent/memory"
+	"jas-agent/agent/tools"
+)
+
+// lookupThenFinish 先调用 lookup 工具，看到工具结果后给出最终回答
+func lookupThenFinish(req llm.ChatRequest) (*llm.ChatResponse, error) {
+	for _, msg := range req.Request().Messages {
+		if strings.Contains(msg.Content, "lookup done") {
+			return textReply("Action: Finish[42]"), nil
+		}
+	}
+	return textReply("Action: lookup[orders]"), nil
+}
+
+func newCheckpointTestExecutor(chat llm.Chat, mem core.Memory) *AgentExecutor {
+	tm := tools.NewToolManager()
+	tm.RegisterTool(&fakeTool{name: "lookup"})
+	executor := NewAgentExecutor(NewContext(WithChat(chat), WithToolManager(tm), WithMemory(mem)))
+	executor.SetSummaryPolicy(SummaryPolicy{Mode: SummaryNever})
+	return executor
+}
+
+// memoryWith 依次写入 turns 中消息的内存
+func memoryWith(turns ...[]core.Message) core.Memory {
+	mem := memory.NewMemory()
+	for _, turn := range turns {
+		mem.AddMessages(turn)
+	}
+	return mem
+}
+
+func TestRestoreCheckpoint(t *testing.T) {
+	history := []core.Message{
+		{Role: core.MessageRoleUser, Content: "earlier question"},
+		{Role: core.MessageRoleAssistant, Content: "earlier answer"},
+	}
+	// 中断后会话中新增的一轮对话
+	newTurn := []core.Message{
+		{Role: core.MessageRoleUser, Content: "another question"},
+		{Role: core.MessageRoleAssistant, Content: "another answer"},
+	}
+
+	// 带会话历史运行，取第一步（工具调用）结束后的检查点
+	var checkpoint *Checkpoint
+	first := newCheckpointTestExecutor(&fakeChat{respond: lookupThenFinish}, memoryWith(history))
+	first.SetCheckpointer(CheckpointerFunc(func(_ context.Context, saved *Checkpoint) error {
+		if saved.CurrentStep == 1 && checkpoint == nil {
+			// 检查点以 JSON 保存
+			data, err := json.Marshal(saved)
+			if err != nil {
+				return err
+			}
+			checkpoint = &Checkpoint{}
+			return json.Unmarshal(data, checkpoint)
+		}
+		return nil
+	}))
+	first.Run(context.Background(), "what is the answer")
+	if checkpoint == nil || checkpoint.RunStart != len(history) {
+		t.Fatalf("checkpoint = %+v, want run start %d", checkpoint, len(history))
+	}
+	runMessages := nonSystemMessages(checkpoint.Messages)[checkpoint.RunStart:]
+	if len(runMessages) != 3 || runMessages[0].Content != "what is the answer" {
+		t.Fatalf("run messages = %+v", runMessages)
+	}
+
+	tests := []struct {
+		name string
+		// memory 恢复时重新加载的会话
+		memory     core.Memory
+		checkpoint func(Checkpoint) Checkpoint
+		want       [][]core.Message
+	}{
+		{
+			name:   "session unchanged",
+			memory: memoryWith(history),
+			want:   [][]core.Message{history, runMessages},
+		},
+		{
+			// 按数量对齐会丢掉本次运行的前两条消息
+			name:   "session got new turns",
+			memory: memoryWith(history, newTurn),
+			want:   [][]core.Message{history, newTurn, runMessages},
+		},
+		{
+			name:   "window memory with new turns",
+			memory: memory.NewWindowMemory(memoryWith(history, newTurn), 2),
+			want:   [][]core.Message{history, newTurn, runMessages},
+		},
+		{
+			// persist_all 时本次运行的消息已写入会话，不重复写入
+			name:   "run messages persisted",
+			memory: memoryWith(history, runMessages),
+			want:   [][]core.Message{history, runMessages},
+		},
+		{
+			name:   "run messages partly persisted",
+			memory: memoryWith(history, runMessages[:1]),
+			want:   [][]core.Message{history, runMessages},
+		},
+		{
+			name:   "run messages persisted before new turns",
+			memory: memoryWith(history, runMessages, newTurn),
+			want:   [][]core.Message{history, runMessages, newTurn},
+		},
+		{
+			// 没有 run_start 的检查点把会话历史视为本次运行的消息，已加载的历史不重复写入
+			name:   "checkpoint without run start",
+			memory: memoryWith(history),
+			checkpoint: func(c Checkpoint) Checkpoint {
+				c.RunStart = 0
+				return c
+			},
+			want: [][]core.Message{history, runMessages},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restored := *checkpoint
+			if tt.checkpoint != nil {
+				restored = tt.checkpoint(restored)
+			}
+			chat := &fakeChat{respond: lookupThenFinish}
+			executor := newCheckpointTestExecutor(chat, tt.memory)
+			if err := executor.Restore(&restored); err != nil {
+				t.Fatal(err)
+			}
+			var want []core.Message
+			for _, messages := range tt.want {
+				want = append(want, messages...)
+			}
+			got := nonSystemMessages(memory.Messages(tt.memory))
+			if len(got) != len(want) {
+				t.Fatalf("restored messages = %+v, want %+v", got, want)
+			}
+			for i := range want {
+				if !sameMessage(got[i], want[i]) {
+					t.Fatalf("restored message %d = %+v, want %+v", i, got[i], want[i])
+				}
+			}
+
+			// 继续执行时不再调用工具，下一个检查点的运行起点仍指向本次运行的用户输入
+			var last *Checkpoint
+			executor.SetCheckpointer(CheckpointerFunc(func(_ context.Context, saved *Checkpoint) error {
+				last = saved
+				return nil
+			}))
+			if result := executor.Resume(context.Background()); result != "42" || len(chat.requests) != 1 {
+				t.Fatalf("resume = %q after %d model calls", result, len(chat.requests))
+			}
+			if saved := nonSystemMessages(last.Messages); tt.checkpoint == nil && saved[last.RunStart].Content != "what is the answer" {
+				t.Fatalf("run start %d points at %+v", last.RunStart, saved[last.RunStart])
+			}
+		})
+	}
+
+	// 运行起点超出检查点的消息时拒绝恢复
+	invalid := *checkpoint
+	invalid.RunStart = len(checkpoint.Messages) + 1
+	if err := newCheckpointTestExecutor(&fakeChat{}, memoryWith(history)).Restore(&invalid); err == nil {
+		t.Fatal("Restore accepted a run start beyond the saved messages")
+	}
+}
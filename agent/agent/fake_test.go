@@ -0,0 +1,93 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"jas-agent/agent/core"
+	"jas-agent/agent/llm"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// fakeChat 测试用模型：respond 不为空时按请求生成响应，否则按顺序返回 replies
+type fakeChat struct {
+	mu       sync.Mutex
+	replies  []*llm.ChatResponse
+	requests []llm.ChatRequest
+	respond  func(req llm.ChatRequest) (*llm.ChatResponse, error)
+}
+
+var _ llm.Chat = (*fakeChat)(nil)
+
+func (c *fakeChat) Completions(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	c.mu.Lock()
+	c.requests = append(c.requests, req)
+	respond := c.respond
+	var reply *llm.ChatResponse
+	if respond == nil && len(c.replies) > 0 {
+		reply, c.replies = c.replies[0], c.replies[1:]
+	}
+	c.mu.Unlock()
+	if respond != nil {
+		return respond(req)
+	}
+	if reply == nil {
+		return nil, fmt.Errorf("fake chat: no reply left")
+	}
+	return reply, nil
+}
+
+func (c *fakeChat) CompletionsStream(ctx context.Context, req llm.ChatRequest, onDelta func(llm.ChatDelta) error) (*llm.ChatResponse, error) {
+	return c.Completions(ctx, req)
+}
+
+// textReply 内容为 content 的助手响应
+func textReply(content string) *llm.ChatResponse {
+	return &llm.ChatResponse{ChatCompletionResponse: openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: content}}},
+	}}
+}
+
+// toolCallReply 以 tool_calls 调用 name 函数的助手响应
+func toolCallReply(id, name, arguments string) *llm.ChatResponse {
+	resp := textReply("")
+	resp.Choices[0].Message.ToolCalls = []openai.ToolCall{{
+		ID:       id,
+		Type:     openai.ToolTypeFunction,
+		Function: openai.FunctionCall{Name: name, Arguments: arguments},
+	}}
+	return resp
+}
+
+// lastUserContent 请求中最后一条用户消息的内容
+func lastUserContent(req llm.ChatRequest) string {
+	messages := req.Request().Messages
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == openai.ChatMessageRoleUser {
+			return messages[i].Content
+		}
+	}
+	return ""
+}
+
+// fakeTool 测试用工具
+type fakeTool struct {
+	name     string
+	toolType core.ToolType
+	input    any
+	handler  func(ctx context.Context, input string) (string, error)
+}
+
+func (t *fakeTool) Name() string        { return t.name }
+func (t *fakeTool) Description() string { return "fake tool " + t.name }
+func (t *fakeTool) Input() any          { return t.input }
+func (t *fakeTool) Type() core.ToolType { return t.toolType }
+
+func (t *fakeTool) Handler(ctx context.Context, input string) (string, error) {
+	if t.handler == nil {
+		return t.name + " done", nil
+	}
+	return t.handler(ctx, input)
+}
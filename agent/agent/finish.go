@@ -0,0 +1,77 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"jas-agent/agent/core"
+	"strings"
+)
+
+// FinishToolName function calling 模式下用于结束运行的工具名称
+const FinishToolName = "finish"
+
+// FinalResult 结构化的最终结果
+type FinalResult struct {
+	Answer     string   `json:"answer"`
+	Citations  []string `json:"citations,omitempty"`  // 答案引用的来源，如工具名称、文档或链接
+	Confidence float64  `json:"confidence,omitempty"` // 置信度 0~1，0 表示未给出
+	// ChainTrace 链式执行时各节点的执行记录
+	ChainTrace []ChainNodeTrace `json:"chain_trace,omitempty"`
+	// SubRuns 计划步骤委派的子 Agent 运行记录
+	SubRuns []SubRunTrace `json:"sub_runs,omitempty"`
+	// PlanRevisions 计划执行过程中的修订记录
+	PlanRevisions []PlanRevision `json:"plan_revisions,omitempty"`
+}
+
+// ParseFinalResult 解析 finish 的参数：JSON 对象按字段解析，否则整体作为答案文本
+func ParseFinalResult(input string) *FinalResult {
+	input = strings.TrimSpace(input)
+	if strings.HasPrefix(input, "{") {
+		var result FinalResult
+		if err := json.Unmarshal([]byte(input), &result); err == nil && result.Answer != "" {
+			return &result
+		}
+	}
+	return &FinalResult{Answer: input}
+}
+
+// finishTool 结束工具，仅用于向模型声明结束协议，不注册到 ToolManager
+type finishTool struct{}
+
+func (finishTool) Name() string {
+	return FinishToolName
+}
+
+func (finishTool) Description() string {
+	return "任务完成时调用，提交最终答案并结束运行。调用后不会再执行其它工具"
+}
+
+func (finishTool) Input() any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"answer": map[string]any{
+				"type":        "string",
+				"description": "最终答案",
+			},
+			"citations": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "答案引用的来源，如工具名称、文档或链接",
+			},
+			"confidence": map[string]any{
+				"type":        "number",
+				"description": "对答案的置信度，取值 0~1",
+			},
+		},
+		"required": []string{"answer"},
+	}
+}
+
+func (finishTool) Type() core.ToolType {
+	return core.Normal
+}
+
+func (finishTool) Handler(ctx context.Context, input string) (string, error) {
+	return "Final answer recorded", nil
+}
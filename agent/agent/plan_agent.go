@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"jas-agent/agent/core"
@@ -17,6 +18,18 @@ import (
 
 var planLogger = log.NewHelper(log.With(log.NewStdLogger(os.Stdout), "module", "agent/plan_agent"))
 
+// 计划步骤状态
+const (
+	PlanStepPending   = "pending"
+	PlanStepExecuting = "executing"
+	PlanStepCompleted = "completed"
+	PlanStepFailed    = "failed"
+	PlanStepSkipped   = "skipped"
+)
+
+// defaultMaxParallelSteps 默认可同时执行的计划步骤数
+const defaultMaxParallelSteps = 4
+
 // PlanStep 计划步骤
 type PlanStep struct {
 	ID           int    `json:"id"`
@@ -26,6 +39,13 @@ type PlanStep struct {
 	Status       string `json:"status"` // pending, executing, completed, failed, skipped
 	Result       string `json:"result"`
 	Dependencies []int  `json:"dependencies"` // 依赖的步骤ID
+	// Agent 委派执行该步骤的子 Agent（Agent 类型或 Agent ID），设置后不执行 Tool
+	Agent    string   `json:"agent,omitempty"`
+	MaxSteps int      `json:"max_steps,omitempty"` // 子 Agent 的最大步数，0 表示使用其默认值
+	Tools    []string `json:"tools,omitempty"`     // 子 Agent 可用的工具，支持通配符，为空时可用全部工具
+	// SubRun 子 Agent 的运行记录
+	SubRun  *SubRunTrace `json:"sub_run,omitempty"`
+	Retries int          `json:"retries,omitempty"` // 评估后重试的次数
 }
 
 // Plan 执行计划
@@ -35,6 +55,21 @@ type Plan struct {
 	Created time.Time   `json:"created"`
 	Updated time.Time   `json:"updated"`
 	Status  string      `json:"status"` // planning, executing, completed, failed
+	// Revisions 执行过程中的计划修订记录
+	Revisions []PlanRevision `json:"revisions,omitempty"`
+}
+
+// PlanStepEvent 计划步骤的状态变化，以 MessageKindPlanStep 消息（内容为 JSON）推送
+type PlanStepEvent struct {
+	StepID       int    `json:"step_id"`
+	Description  string `json:"description"`
+	Tool         string `json:"tool,omitempty"`
+	Agent        string `json:"agent,omitempty"`
+	Input        string `json:"input,omitempty"`
+	Dependencies []int  `json:"dependencies,omitempty"`
+	Status       string `json:"status"`
+	Result       string `json:"result,omitempty"` // completed 时的结果
+	Error        string `json:"error,omitempty"`  // failed 时的错误，skipped 时的跳过原因
 }
 
 // PlanAgent 计划Agent
@@ -52,23 +87,49 @@ func (a *PlanAgent) Type() AgentType {
 	return PlanAgentType
 }
 
-func (a *PlanAgent) Step() string {
+func (a *PlanAgent) Step(ctx context.Context) string {
 	if a.plan == nil {
 		// 第一步：生成计划
-		return a.generatePlan()
+		return a.generatePlan(ctx)
 	}
 
 	if a.plan.Status == "completed" || a.plan.Status == "failed" {
-		a.executor.UpdateState(FinishState)
-		return fmt.Sprintf("Plan execution %s", a.plan.Status)
+		return a.finish(fmt.Sprintf("Plan execution %s", a.plan.Status))
 	}
 
 	// 执行计划中的下一步
-	return a.executeNextStep()
+	return a.executeNextStep(ctx)
+}
+
+// planState PlanAgent 的检查点状态
+type planState struct {
+	Plan *Plan `json:"plan"`
+}
+
+// Snapshot 保存当前计划及各步骤的执行结果
+func (a *PlanAgent) Snapshot() (json.RawMessage, error) {
+	return json.Marshal(planState{Plan: a.plan})
+}
+
+// Restore 恢复计划，中断时正在执行的步骤重新执行
+func (a *PlanAgent) Restore(state json.RawMessage) error {
+	var saved planState
+	if err := json.Unmarshal(state, &saved); err != nil {
+		return err
+	}
+	if saved.Plan != nil {
+		for _, step := range saved.Plan.Steps {
+			if step.Status == PlanStepExecuting {
+				step.Status = PlanStepPending
+			}
+		}
+	}
+	a.plan = saved.Plan
+	return nil
 }
 
 // generatePlan 生成执行计划
-func (a *PlanAgent) generatePlan() string {
+func (a *PlanAgent) generatePlan(ctx context.Context) string {
 	planLogger.Info("📋 Generating execution plan...")
 
 	// 获取用户查询
@@ -81,12 +142,12 @@ func (a *PlanAgent) generatePlan() string {
 		}
 	}
 
-	// 构建计划生成提示
-	tools := a.context.toolManager.AvailableTools()
-	var toolsDesc strings.Builder
-	toolsDesc.WriteString("可用工具:\n")
-	for _, tool := range tools {
-		toolsDesc.WriteString(fmt.Sprintf("- %s: %s\n", tool.Name(), tool.Description()))
+	// 可委派子 Agent 时说明 agent、max_steps、tools 字段
+	subAgentsDesc, subAgentsNote := a.subAgentsDesc(), ""
+	if subAgentsDesc != "" {
+		subAgentsNote = `
+6. 需要多步推理或专业能力的步骤可以委派给子Agent：设置 "agent" 为子Agent名称（此时不需要 tool），
+   可选设置 "max_steps"（子Agent最大步数）和 "tools"（子Agent可用的工具名称列表）`
 	}
 
 	planPrompt := fmt.Sprintf(`请为以下任务生成详细的执行计划。
@@ -95,7 +156,7 @@ func (a *PlanAgent) generatePlan() string {
 
 %s
 
-请生成一个JSON格式的执行计划，包含以下结构:
+%s请生成一个JSON格式的执行计划，包含以下结构:
 {
   "goal": "任务目标",
   "steps": [
@@ -112,11 +173,11 @@ func (a *PlanAgent) generatePlan() string {
 注意事项:
 1. 步骤要有逻辑顺序
 2. 如果某步骤依赖其他步骤的结果，在dependencies中标注
-3. 工具名称必须从可用工具列表中选择
-4. 每个步骤要具体、可执行
-5. 最后一步应该是总结或返回答案
+3. 工具名称必须从可用工具列表中选择，定义了参数的工具，input 为符合其参数 JSON Schema 的 JSON 字符串
+4. 每个步骤要具体、可执行，都必须指定工具，所有步骤完成后会自动总结答案
+5. input 中可以用 ${step.N} 引用步骤 N 的结果，被引用的步骤必须列在 dependencies 中，依赖关系不能成环%s
 
-请只返回JSON格式的计划，不要包含其他内容。`, userQuery, toolsDesc.String())
+请只返回JSON格式的计划，不要包含其他内容。`, userQuery, a.toolsDesc(), subAgentsDesc, subAgentsNote)
 
 	// 调用LLM生成计划
 	planMessages := []core.Message{
@@ -130,23 +191,24 @@ func (a *PlanAgent) generatePlan() string {
 		},
 	}
 
-	resp, err := a.context.chat.Completions(context.TODO(), llm.NewChatRequest(a.context.model, planMessages))
-	if err != nil {
-		return fmt.Sprintf("Plan generation failed: %s", err.Error())
-	}
-
-	// 解析计划
-	planJSON := extractJSON(resp.Content())
+	// 解析并校验计划，未通过校验时交由模型修正
 	var planData struct {
 		Goal  string      `json:"goal"`
 		Steps []*PlanStep `json:"steps"`
 	}
-
-	err = json.Unmarshal([]byte(planJSON), &planData)
+	err := a.requestJSON(ctx, planMessages, "plan", planSchema, func(content string) error {
+		planData.Goal, planData.Steps = "", nil
+		if err := json.Unmarshal([]byte(extractJSON(content)), &planData); err != nil {
+			planLogger.Infof("Response: %s", content)
+			return fmt.Errorf("parse plan JSON: %w", err)
+		}
+		return a.validateSteps(planData.Steps, nil)
+	})
 	if err != nil {
-		planLogger.Errorf("Failed to parse plan JSON: %s", err.Error())
-		planLogger.Infof("Response: %s", resp.Content())
-		return fmt.Sprintf("Failed to parse plan: %s", err.Error())
+		// 修正次数用尽后计划失败，不再重复生成
+		planLogger.Errorf("Plan generation failed: %s", err.Error())
+		a.plan = &Plan{Goal: userQuery, Created: time.Now(), Updated: time.Now(), Status: "failed"}
+		return a.finish(fmt.Sprintf("Plan generation failed: %s", err.Error()))
 	}
 
 	// 初始化计划
@@ -160,7 +222,7 @@ func (a *PlanAgent) generatePlan() string {
 
 	// 设置所有步骤状态为pending
 	for _, step := range a.plan.Steps {
-		step.Status = "pending"
+		a.setStepStatus(ctx, step, PlanStepPending, "")
 	}
 
 	// 显示计划
@@ -179,87 +241,154 @@ func (a *PlanAgent) generatePlan() string {
 	return "Plan generated successfully"
 }
 
-// executeNextStep 执行下一步
-func (a *PlanAgent) executeNextStep() string {
-	// 查找下一个待执行的步骤
-	var nextStep *PlanStep
-	for _, step := range a.plan.Steps {
-		if step.Status == "pending" {
-			// 检查依赖是否已完成
-			canExecute := true
-			for _, depID := range step.Dependencies {
-				depStep := a.findStepByID(depID)
-				if depStep == nil || depStep.Status != "completed" {
-					canExecute = false
-					break
-				}
+// executeNextStep 按依赖关系执行一批步骤：依赖都已完成的步骤在并发上限内同时执行，
+// 失败步骤的后续步骤标记为跳过
+func (a *PlanAgent) executeNextStep(ctx context.Context) string {
+	ready := a.readySteps(ctx)
+	if len(ready) == 0 {
+		var failed, blocked []*PlanStep
+		for _, step := range a.plan.Steps {
+			switch step.Status {
+			case PlanStepFailed:
+				failed = append(failed, step)
+			case PlanStepPending:
+				blocked = append(blocked, step)
 			}
+		}
+		if len(failed) == 0 && len(blocked) == 0 {
+			a.plan.Status = "completed"
+			return a.finish(a.generateSummary(ctx))
+		}
 
-			if canExecute {
-				nextStep = step
-				break
-			}
+		// 如果有失败的步骤且还能修订计划，重新规划剩余步骤
+		if a.canRevise() {
+			return a.replan(ctx, failed, blocked)
+		}
+
+		a.plan.Status = "failed"
+		if len(failed) > 0 {
+			return fmt.Sprintf("Plan execution failed: steps %s failed", stepIDs(failed))
 		}
+		return fmt.Sprintf("Plan execution blocked: dependencies of steps %s not met", stepIDs(blocked))
 	}
 
-	if nextStep == nil {
-		// 检查是否所有步骤都完成
-		allCompleted := true
-		for _, step := range a.plan.Steps {
-			if step.Status != "completed" && step.Status != "skipped" {
-				allCompleted = false
+	// 执行步骤，结束后评估结果
+	var report string
+	if len(ready) == 1 {
+		report = a.executeStep(ctx, ready[0])
+	} else {
+		report = a.executeSteps(ctx, ready)
+	}
+	if revised := a.evaluate(ctx, ready); revised != "" {
+		report += "\n" + revised
+	}
+	return report
+}
+
+// readySteps 依赖都已完成的待执行步骤，最多返回并发上限个；依赖失败、被跳过或不存在的步骤标记为跳过
+func (a *PlanAgent) readySteps(ctx context.Context) []*PlanStep {
+	limit := a.context.maxParallelSteps
+	if limit < 1 {
+		limit = 1
+	}
+	var ready []*PlanStep
+	for _, step := range a.plan.Steps {
+		if step.Status != PlanStepPending {
+			continue
+		}
+		canExecute := true
+		for _, depID := range step.Dependencies {
+			depStep := a.findStepByID(depID)
+			if depStep == nil {
+				a.skipStep(ctx, step, fmt.Sprintf("dependency step %d does not exist", depID))
+				canExecute = false
+				break
+			}
+			if depStep.Status == PlanStepFailed || depStep.Status == PlanStepSkipped {
+				a.skipStep(ctx, step, fmt.Sprintf("dependency step %d %s", depID, depStep.Status))
+				canExecute = false
+				break
+			}
+			if depStep.Status != PlanStepCompleted {
+				canExecute = false
 				break
 			}
 		}
-
-		if allCompleted {
-			a.plan.Status = "completed"
-			a.executor.UpdateState(FinishState)
-			return a.generateSummary()
-		}
-
-		// 如果有失败的步骤且启用重新规划
-		if a.enableReplan {
-			return a.replan()
+		if canExecute && len(ready) < limit {
+			ready = append(ready, step)
 		}
+	}
+	return ready
+}
 
-		a.plan.Status = "failed"
-		return "Plan execution blocked: dependencies not met"
+// executeSteps 并发执行一批互不依赖的步骤，结果按计划顺序写入记忆
+func (a *PlanAgent) executeSteps(ctx context.Context, steps []*PlanStep) string {
+	inputs := make([]string, len(steps))
+	for i, step := range steps {
+		inputs[i] = a.startStep(ctx, step)
 	}
+	results := make([]ToolResult, len(steps))
+	var wg sync.WaitGroup
+	for i := range steps {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i].Result, results[i].Err = a.runStep(ctx, steps[i], inputs[i])
+		}(i)
+	}
+	wg.Wait()
 
-	// 执行步骤
-	return a.executeStep(nextStep)
+	reports := make([]string, 0, len(steps))
+	for i, step := range steps {
+		reports = append(reports, a.finishStep(ctx, step, results[i].Result, results[i].Err))
+	}
+	return strings.Join(reports, "\n")
 }
 
 // executeStep 执行具体步骤
-func (a *PlanAgent) executeStep(step *PlanStep) string {
-	step.Status = "executing"
+func (a *PlanAgent) executeStep(ctx context.Context, step *PlanStep) string {
+	input := a.startStep(ctx, step)
+	result, err := a.runStep(ctx, step, input)
+	return a.finishStep(ctx, step, result, err)
+}
+
+// startStep 将步骤标记为执行中，返回替换依赖引用后的输入
+func (a *PlanAgent) startStep(ctx context.Context, step *PlanStep) string {
+	a.setStepStatus(ctx, step, PlanStepExecuting, "")
 	planLogger.Infof("⚙️  Executing step %d: %s", step.ID, step.Description)
 
 	// 替换输入中的依赖引用 ${step.X}
 	input := a.resolveDependencies(step.Input, step.Dependencies)
+	if step.Agent == "" {
+		a.executor.RecordToolCall(step.Tool)
+	}
+	return input
+}
 
-	// 创建工具调用
-	ctx := context.Background()
-	toolCall := &tools.ToolCall{
+// runStep 调用步骤的工具，或委派子 Agent 完成步骤
+func (a *PlanAgent) runStep(ctx context.Context, step *PlanStep, input string) (string, error) {
+	if step.Agent != "" {
+		return a.runSubAgent(ctx, step, input)
+	}
+	return a.context.ExecTool(ctx, &tools.ToolCall{
 		Name:  step.Tool,
 		Input: input,
-	}
-
-	// 执行工具调用
-	result, err := a.context.toolManager.ExecTool(ctx, toolCall)
+	})
+}
 
+// finishStep 记录步骤的执行结果，失败时跳过依赖该步骤的后续步骤
+func (a *PlanAgent) finishStep(ctx context.Context, step *PlanStep, result string, err error) string {
+	a.plan.Updated = time.Now()
 	if err != nil {
-		step.Status = "failed"
 		step.Result = fmt.Sprintf("Error: %s", err.Error())
+		a.setStepStatus(ctx, step, PlanStepFailed, err.Error())
 		planLogger.Errorf("❌ Step %d failed: %s", step.ID, err.Error())
+		a.skipDependents(ctx, step)
 		return fmt.Sprintf("Step %d execution failed: %s", step.ID, err.Error())
 	}
 
-	step.Status = "completed"
 	step.Result = result
-	a.plan.Updated = time.Now()
-
+	a.setStepStatus(ctx, step, PlanStepCompleted, "")
 	planLogger.Infof("✅ Step %d completed: %s", step.ID, truncateString(result, 100))
 
 	// 添加到内存
@@ -275,6 +404,76 @@ func (a *PlanAgent) executeStep(step *PlanStep) string {
 	return fmt.Sprintf("Step %d completed", step.ID)
 }
 
+// skipDependents 跳过直接或间接依赖 failed 的待执行步骤
+func (a *PlanAgent) skipDependents(ctx context.Context, failed *PlanStep) {
+	for _, step := range a.plan.Steps {
+		if step.Status != PlanStepPending {
+			continue
+		}
+		for _, depID := range step.Dependencies {
+			if depID == failed.ID {
+				a.skipStep(ctx, step, fmt.Sprintf("dependency step %d %s", failed.ID, failed.Status))
+				a.skipDependents(ctx, step)
+				break
+			}
+		}
+	}
+}
+
+func (a *PlanAgent) skipStep(ctx context.Context, step *PlanStep, reason string) {
+	step.Result = "Skipped: " + reason
+	a.setStepStatus(ctx, step, PlanStepSkipped, reason)
+	planLogger.Warnf("⏭️  Step %d skipped: %s", step.ID, reason)
+}
+
+// setStepStatus 更新步骤状态并推送步骤事件，detail 为失败的错误或跳过原因
+func (a *PlanAgent) setStepStatus(ctx context.Context, step *PlanStep, status, detail string) {
+	step.Status = status
+	event := PlanStepEvent{
+		StepID:       step.ID,
+		Description:  step.Description,
+		Tool:         step.Tool,
+		Agent:        step.Agent,
+		Input:        step.Input,
+		Dependencies: step.Dependencies,
+		Status:       status,
+		Error:        detail,
+	}
+	if status == PlanStepCompleted {
+		event.Result = step.Result
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	a.context.Send(ctx, core.Message{
+		Role:    core.MessageRoleAssistant,
+		Content: string(data),
+		Kind:    core.MessageKindPlanStep,
+	})
+}
+
+// finish 结束计划，最终结果附带子 Agent 的运行记录与计划修订记录
+func (a *PlanAgent) finish(answer string) string {
+	a.executor.SetFinalResult(&FinalResult{Answer: answer, SubRuns: a.subRuns(), PlanRevisions: a.plan.Revisions})
+	return answer
+}
+
+// toolsDesc 可用工具说明
+func (a *PlanAgent) toolsDesc() string {
+	var desc strings.Builder
+	desc.WriteString("可用工具:\n")
+	for _, tool := range a.context.toolManager.AvailableTools() {
+		desc.WriteString(fmt.Sprintf("- %s: %s\n", tool.Name(), tool.Description()))
+		if tool.Input() != nil {
+			if schema, err := json.Marshal(tool.Input()); err == nil {
+				desc.WriteString(fmt.Sprintf("  参数: %s\n", schema))
+			}
+		}
+	}
+	return desc.String()
+}
+
 // findStepByID 根据ID查找步骤
 func (a *PlanAgent) findStepByID(id int) *PlanStep {
 	for _, step := range a.plan.Steps {
@@ -290,7 +489,7 @@ func (a *PlanAgent) resolveDependencies(input string, dependencies []int) string
 	result := input
 	for _, depID := range dependencies {
 		step := a.findStepByID(depID)
-		if step != nil && step.Status == "completed" {
+		if step != nil && step.Status == PlanStepCompleted {
 			placeholder := fmt.Sprintf("${step.%d}", depID)
 			result = strings.ReplaceAll(result, placeholder, step.Result)
 		}
@@ -298,70 +497,8 @@ func (a *PlanAgent) resolveDependencies(input string, dependencies []int) string
 	return result
 }
 
-// replan 重新规划
-func (a *PlanAgent) replan() string {
-	planLogger.Info("🔄 Replanning...")
-
-	// 收集当前执行状态
-	var statusReport strings.Builder
-	statusReport.WriteString("当前执行状态:\n")
-	for _, step := range a.plan.Steps {
-		statusReport.WriteString(fmt.Sprintf("Step %d (%s): %s\n", step.ID, step.Status, step.Description))
-		if step.Status == "failed" {
-			statusReport.WriteString(fmt.Sprintf("  Error: %s\n", step.Result))
-		}
-	}
-
-	// 请求重新规划
-	replanPrompt := fmt.Sprintf(`任务执行遇到问题，需要重新规划。
-
-原始目标: %s
-
-%s
-
-请生成一个新的执行计划，避免之前失败的问题。返回JSON格式。`, a.plan.Goal, statusReport.String())
-
-	replanMessages := []core.Message{
-		{
-			Role:    core.MessageRoleSystem,
-			Content: a.systemPrompt,
-		},
-		{
-			Role:    core.MessageRoleUser,
-			Content: replanPrompt,
-		},
-	}
-
-	resp, err := a.context.chat.Completions(context.TODO(), llm.NewChatRequest(a.context.model, replanMessages))
-	if err != nil {
-		return fmt.Sprintf("Replan failed: %s", err.Error())
-	}
-
-	// 解析新计划
-	planJSON := extractJSON(resp.Content())
-	var planData struct {
-		Goal  string      `json:"goal"`
-		Steps []*PlanStep `json:"steps"`
-	}
-
-	err = json.Unmarshal([]byte(planJSON), &planData)
-	if err != nil {
-		return fmt.Sprintf("Failed to parse new plan: %s", err.Error())
-	}
-
-	// 更新计划
-	a.plan.Steps = planData.Steps
-	a.plan.Updated = time.Now()
-	for _, step := range a.plan.Steps {
-		step.Status = "pending"
-	}
-
-	planLogger.Info("✨ Plan updated successfully")
-	return "Replanned successfully"
-}
-
 // generateSummary 生成总结
-func (a *PlanAgent) generateSummary() string {
+func (a *PlanAgent) generateSummary(ctx context.Context) string {
 	planLogger.Info("📊 Generating summary...")
 
 	var summary strings.Builder
@@ -369,7 +506,7 @@ func (a *PlanAgent) generateSummary() string {
 	summary.WriteString("执行结果:\n")
 
 	for _, step := range a.plan.Steps {
-		if step.Status == "completed" {
+		if step.Status == PlanStepCompleted {
 			summary.WriteString(fmt.Sprintf("✓ %s\n", step.Description))
 			if step.Result != "" {
 				summary.WriteString(fmt.Sprintf("  结果: %s\n", truncateString(step.Result, 200)))
@@ -389,7 +526,7 @@ func (a *PlanAgent) generateSummary() string {
 		},
 	}
 
-	resp, err := a.context.chat.Completions(context.TODO(), llm.NewChatRequest(a.context.model, summaryMessages))
+	resp, err := a.context.chat.Completions(ctx, llm.NewChatRequest(a.context.ModelFor(ModelPhaseSummary), summaryMessages))
 	if err != nil {
 		return summary.String()
 	}
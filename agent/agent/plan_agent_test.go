@@ -0,0 +1,371 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"jas-agent/agent/core"
+	"jas-agent/agent/llm"
+	"jas-agent/agent/tools"
+)
+
+// planResponder 按请求的阶段生成计划 Agent 的模型响应：plan 为生成的计划，evaluate 与 replan 按调用次数
+// （从 1 开始）返回评估结果与重新规划的步骤，其余请求（子 Agent）由 other 处理
+type planResponder struct {
+	plan        string
+	evaluate    func(call int) string
+	replan      func(call int) string
+	other       func(req llm.ChatRequest) string
+	mu          sync.Mutex
+	evaluations int
+	replans     int
+}
+
+func (r *planResponder) respond(req llm.ChatRequest) (*llm.ChatResponse, error) {
+	system := req.Request().Messages[0].Content
+	user := lastUserContent(req)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch {
+	case system == stepEvaluatorPrompt:
+		r.evaluations++
+		return textReply(r.evaluate(r.evaluations)), nil
+	case strings.Contains(user, "请为以下任务生成详细的执行计划"):
+		return textReply(r.plan), nil
+	case strings.Contains(user, "任务执行遇到问题"):
+		r.replans++
+		return textReply(r.replan(r.replans)), nil
+	case strings.Contains(system, "总结助手"):
+		return textReply("summary"), nil
+	case r.other != nil:
+		return textReply(r.other(req)), nil
+	}
+	return nil, fmt.Errorf("unexpected request: %s", user)
+}
+
+// recordingTool 记录每次调用输入的工具，输入以 bad 开头时调用失败
+type recordingTool struct {
+	mu     sync.Mutex
+	inputs []string
+}
+
+func (r *recordingTool) tool(name string) *fakeTool {
+	return &fakeTool{name: name, handler: func(_ context.Context, input string) (string, error) {
+		r.mu.Lock()
+		r.inputs = append(r.inputs, input)
+		r.mu.Unlock()
+		if strings.HasPrefix(input, "bad") {
+			return "", fmt.Errorf("cannot fetch %s", input)
+		}
+		return "result of " + input, nil
+	}}
+}
+
+// planSteps 以 JSON 描述的计划
+func planSteps(steps ...string) string {
+	return `{"goal": "investigate", "steps": [` + strings.Join(steps, ",") + `]}`
+}
+
+func newTestPlanExecutor(chat llm.Chat, tm *tools.ToolManager, enableReplan bool, opts ...Option) *AgentExecutor {
+	opts = append([]Option{WithChat(chat), WithToolManager(tm)}, opts...)
+	executor := NewPlanAgentExecutor(NewContext(opts...), enableReplan)
+	executor.SetSummaryPolicy(SummaryPolicy{Mode: SummaryNever})
+	return executor
+}
+
+func TestPlanDependencyFailure(t *testing.T) {
+	// 步骤 1 与 2 互不依赖，同时开始后才返回
+	started := make(chan string, 2)
+	barrier := func(name string) func(context.Context, string) (string, error) {
+		return func(_ context.Context, input string) (string, error) {
+			started <- name
+			deadline := time.After(5 * time.Second)
+			for len(started) < 2 {
+				select {
+				case <-deadline:
+					return "", errors.New("steps were not executed concurrently")
+				default:
+					time.Sleep(time.Millisecond)
+				}
+			}
+			if name == "fail" {
+				return "", errors.New("service unavailable")
+			}
+			return "fetched " + input, nil
+		}
+	}
+	recorder := &recordingTool{}
+	tm := tools.NewToolManager()
+	tm.RegisterTool(&fakeTool{name: "fetch", handler: barrier("fetch")})
+	tm.RegisterTool(&fakeTool{name: "fail", handler: barrier("fail")})
+	tm.RegisterTool(recorder.tool("use"))
+
+	responder := &planResponder{plan: planSteps(
+		`{"id": 1, "description": "fetch", "tool": "fetch", "input": "orders"}`,
+		`{"id": 2, "description": "fail", "tool": "fail", "input": "metrics"}`,
+		`{"id": 3, "description": "use 1", "tool": "use", "input": "${step.1}", "dependencies": [1]}`,
+		`{"id": 4, "description": "use 2", "tool": "use", "input": "${step.2}", "dependencies": [2]}`,
+		`{"id": 5, "description": "use 4", "tool": "use", "input": "${step.4}", "dependencies": [4]}`,
+		`{"id": 6, "description": "use 1 and 3", "tool": "use", "input": "${step.1} ${step.3}", "dependencies": [1, 3]}`,
+	)}
+	var mu sync.Mutex
+	skipped := map[int]string{}
+	send := func(_ context.Context, msg core.Message) error {
+		var event PlanStepEvent
+		if msg.Kind == core.MessageKindPlanStep && json.Unmarshal([]byte(msg.Content), &event) == nil && event.Status == PlanStepSkipped {
+			mu.Lock()
+			skipped[event.StepID] = event.Error
+			mu.Unlock()
+		}
+		return nil
+	}
+	executor := newTestPlanExecutor(&fakeChat{respond: responder.respond}, tm, false, WithSend(send))
+
+	if result := executor.Run(context.Background(), "investigate"); result != "Plan execution failed" {
+		t.Fatalf("result = %q", result)
+	}
+	plan := executor.agent.(*PlanAgent).plan
+	want := []string{PlanStepCompleted, PlanStepFailed, PlanStepCompleted, PlanStepSkipped, PlanStepSkipped, PlanStepCompleted}
+	for i, step := range plan.Steps {
+		if step.Status != want[i] {
+			t.Errorf("step %d = %s, want %s", step.ID, step.Status, want[i])
+		}
+	}
+	if plan.Status != "failed" {
+		t.Fatalf("plan status = %s", plan.Status)
+	}
+	// 失败步骤的直接与间接后续步骤都被跳过，其余步骤使用依赖的结果继续执行
+	if skipped[4] != "dependency step 2 failed" || skipped[5] != "dependency step 4 skipped" {
+		t.Fatalf("skip reasons = %v", skipped)
+	}
+	wantInputs := []string{"fetched orders", "fetched orders result of fetched orders"}
+	if strings.Join(recorder.inputs, "|") != strings.Join(wantInputs, "|") {
+		t.Fatalf("use inputs = %q, want %q", recorder.inputs, wantInputs)
+	}
+}
+
+func TestPlanRevisions(t *testing.T) {
+	insert := `{"score": 0.4, "decision": "insert", "reason": "need more", "steps": [{"id": 1, "tool": "fetch", "input": "more"}]}`
+	retry := `{"score": 0.2, "decision": "retry", "reason": "wrong input", "step_id": 1, "input": "b"}`
+	tests := []struct {
+		name         string
+		plan         string
+		evaluate     string // 每次评估都返回的结果，为空时不开启评估
+		replan       string // 每次重新规划都返回的步骤
+		maxRevisions int
+		wantInputs   []string
+		wantRevision []PlanDecision
+		wantStatus   string
+	}{
+		{
+			// 达到修订上限后不再评估
+			name:         "insert",
+			plan:         planSteps(`{"id": 1, "tool": "fetch", "input": "a"}`),
+			evaluate:     insert,
+			maxRevisions: 2,
+			wantInputs:   []string{"a", "more", "more"},
+			wantRevision: []PlanDecision{PlanInsert, PlanInsert},
+			wantStatus:   "completed",
+		},
+		{
+			// 单个步骤最多重试 maxStepRetries 次，超出后的重试决定不执行，记录为按原计划继续
+			name:         "retry",
+			plan:         planSteps(`{"id": 1, "tool": "fetch", "input": "a"}`, `{"id": 2, "tool": "fetch", "input": "${step.1}", "dependencies": [1]}`),
+			evaluate:     retry,
+			maxRevisions: 5,
+			wantInputs:   []string{"a", "b", "b", "result of b"},
+			wantRevision: []PlanDecision{PlanRetry, PlanRetry, PlanContinue, PlanContinue},
+			wantStatus:   "completed",
+		},
+		{
+			// 步骤失败后重新规划，达到修订上限后计划失败
+			name:         "replan",
+			plan:         planSteps(`{"id": 1, "tool": "fetch", "input": "bad1"}`, `{"id": 2, "tool": "fetch", "input": "${step.1}", "dependencies": [1]}`),
+			replan:       `{"steps": [{"id": 3, "tool": "fetch", "input": "bad2"}]}`,
+			maxRevisions: 2,
+			wantInputs:   []string{"bad1", "bad2", "bad2"},
+			wantRevision: []PlanDecision{PlanReplan, PlanReplan},
+			wantStatus:   "failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &recordingTool{}
+			tm := tools.NewToolManager()
+			tm.RegisterTool(recorder.tool("fetch"))
+			responder := &planResponder{
+				plan:     tt.plan,
+				evaluate: func(int) string { return tt.evaluate },
+				replan:   func(int) string { return tt.replan },
+			}
+			executor := newTestPlanExecutor(&fakeChat{respond: responder.respond}, tm, true,
+				WithPlanEvaluation(tt.evaluate != ""), WithMaxPlanRevisions(tt.maxRevisions))
+
+			executor.Run(context.Background(), "investigate")
+			plan := executor.agent.(*PlanAgent).plan
+			if plan.Status != tt.wantStatus {
+				t.Fatalf("plan status = %s, want %s", plan.Status, tt.wantStatus)
+			}
+			if strings.Join(recorder.inputs, "|") != strings.Join(tt.wantInputs, "|") {
+				t.Fatalf("tool inputs = %q, want %q", recorder.inputs, tt.wantInputs)
+			}
+			revisions := executor.GetFinalResult().PlanRevisions
+			if len(revisions) != len(tt.wantRevision) {
+				t.Fatalf("revisions = %+v, want %v", revisions, tt.wantRevision)
+			}
+			for i, revision := range revisions {
+				if revision.Decision != tt.wantRevision[i] || revision.Revision != i+1 {
+					t.Fatalf("revision %d = %+v", i, revision)
+				}
+			}
+			if tt.name == "retry" {
+				for _, revision := range revisions[2:] {
+					if revision.RetriedStep != 0 || revision.Reason != "retry limit reached: step 1 has been retried 2 times; wrong input" {
+						t.Fatalf("revision at retry limit = %+v", revision)
+					}
+				}
+			}
+			if tt.name == "replan" {
+				if removed := revisions[0].RemovedSteps; len(removed) != 2 || removed[0].Status != PlanStepFailed || removed[1].Status != PlanStepSkipped {
+					t.Fatalf("removed steps = %+v", removed)
+				}
+			}
+		})
+	}
+}
+
+func TestPlanSubAgent(t *testing.T) {
+	responder := &planResponder{
+		plan: planSteps(
+			`{"id": 1, "description": "fetch", "tool": "fetch", "input": "orders"}`,
+			`{"id": 2, "description": "analyze", "agent": "analyst", "input": "analyze ${step.1}", "dependencies": [1], "max_steps": 2}`,
+		),
+		other: func(req llm.ChatRequest) string {
+			return "Action: Finish[analysis of " + lastUserContent(req) + "]"
+		},
+	}
+	recorder := &recordingTool{}
+	tm := tools.NewToolManager()
+	tm.RegisterTool(recorder.tool("fetch"))
+	var created []int
+	factory := func(_ context.Context, name string, subCtx *Context) (*AgentExecutor, error) {
+		created = append(created, subCtx.depth)
+		return NewAgentExecutor(subCtx), nil
+	}
+	executor := newTestPlanExecutor(&fakeChat{respond: responder.respond}, tm, false,
+		WithSubAgents(factory, []SubAgent{{Name: "analyst", Description: "analyzes data"}}))
+
+	executor.Run(context.Background(), "investigate")
+	subRuns := executor.GetFinalResult().SubRuns
+	if len(subRuns) != 1 || len(created) != 1 || created[0] != 1 {
+		t.Fatalf("sub runs = %+v, created at depths %v", subRuns, created)
+	}
+	run := subRuns[0]
+	if run.StepID != 2 || run.Depth != 1 || run.Source != "step2:analyst" || run.Status != PlanStepCompleted ||
+		run.Input != "analyze result of orders" || run.Output != "analysis of analyze result of orders" {
+		t.Fatalf("sub run = %+v", run)
+	}
+	if step := executor.agent.(*PlanAgent).plan.Steps[1]; step.Result != run.Output {
+		t.Fatalf("step result = %q", step.Result)
+	}
+}
+
+func TestRunAgent(t *testing.T) {
+	finish := &fakeChat{respond: func(llm.ChatRequest) (*llm.ChatResponse, error) {
+		return textReply("Action: Finish[done]"), nil
+	}}
+	loop := &fakeChat{respond: func(llm.ChatRequest) (*llm.ChatResponse, error) {
+		return textReply("Action: missing[x]"), nil
+	}}
+	react := func(_ context.Context, _ string, subCtx *Context) (*AgentExecutor, error) {
+		return NewAgentExecutor(subCtx), nil
+	}
+	tests := []struct {
+		name    string
+		chat    llm.Chat
+		factory SubAgentFactory
+		depth   int // 父上下文所在的层数
+		want    string
+		wantErr error
+		// wantErrText 错误信息应包含的内容
+		wantErrText string
+	}{
+		{name: "completed", chat: finish, factory: react, depth: 1, want: "done"},
+		{name: "max depth", chat: finish, factory: react, depth: 2, wantErr: ErrMaxAgentDepth, wantErrText: "at depth 3, limit 2"},
+		{name: "delegation disabled", chat: finish, wantErrText: "delegation is not enabled"},
+		{
+			name: "factory error",
+			chat: finish,
+			factory: func(context.Context, string, *Context) (*AgentExecutor, error) {
+				return nil, errors.New("unknown agent")
+			},
+			wantErrText: "create sub agent worker: unknown agent",
+		},
+		{name: "not finished", chat: loop, factory: react, wantErrText: "ended with state Error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []Option{WithChat(tt.chat), WithToolManager(tools.NewToolManager()), WithMaxAgentDepth(2)}
+			if tt.factory != nil {
+				opts = append(opts, WithSubAgents(tt.factory, nil))
+			}
+			parent := NewContext(opts...)
+			parent.depth = tt.depth
+			trace := &SubRunTrace{Agent: "worker", Input: "task"}
+
+			got, err := runAgent(context.Background(), parent, trace, "worker", tools.NewToolManager(), 2)
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("runAgent() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErrText != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrText) {
+					t.Fatalf("runAgent() error = %v, want %q", err, tt.wantErrText)
+				}
+				if trace.Status != PlanStepFailed || trace.Error != err.Error() {
+					t.Fatalf("trace = %+v", trace)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("runAgent() = %q, %v", got, err)
+			}
+			if trace.Status != PlanStepCompleted || trace.Depth != tt.depth+1 || trace.Output != tt.want {
+				t.Fatalf("trace = %+v", trace)
+			}
+		})
+	}
+}
+
+// closerFunc 以函数实现 io.Closer
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }
+
+func TestRunAgentClosesExecutor(t *testing.T) {
+	for _, reply := range []string{"Action: Finish[done]", "Action: missing[x]"} {
+		var closed int
+		chat := &fakeChat{respond: func(llm.ChatRequest) (*llm.ChatResponse, error) {
+			return textReply(reply), nil
+		}}
+		factory := func(_ context.Context, _ string, subCtx *Context) (*AgentExecutor, error) {
+			executor := NewAgentExecutor(subCtx)
+			executor.AddCloser(closerFunc(func() error {
+				closed++
+				return nil
+			}))
+			return executor, nil
+		}
+		parent := NewContext(WithChat(chat), WithToolManager(tools.NewToolManager()), WithSubAgents(factory, nil))
+		// 子 Agent 无论成功与否，登记的资源都在运行结束后关闭
+		_, _ = runAgent(context.Background(), parent, &SubRunTrace{Agent: "worker", Input: "task"}, "worker", tools.NewToolManager(), 2)
+		if closed != 1 {
+			t.Fatalf("reply %q: closed %d times, want 1", reply, closed)
+		}
+	}
+}
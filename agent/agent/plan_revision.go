@@ -0,0 +1,413 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"jas-agent/agent/core"
+	"jas-agent/agent/tools"
+)
+
+// PlanDecision 步骤评估后计划的走向
+type PlanDecision string
+
+const (
+	// PlanContinue 按原计划继续；修订记录中表示要求的重试已达上限，未再重试
+	PlanContinue PlanDecision = "continue"
+	// PlanRetry 修改输入后重新执行某个步骤
+	PlanRetry PlanDecision = "retry"
+	// PlanInsert 插入新的步骤，原有步骤不变
+	PlanInsert PlanDecision = "insert"
+	// PlanReplan 以新的步骤替换所有未完成的步骤，已完成的步骤及结果保留
+	PlanReplan PlanDecision = "replan"
+)
+
+const (
+	// defaultMaxPlanRevisions 默认允许的计划修订次数
+	defaultMaxPlanRevisions = 5
+	// maxStepRetries 单个步骤最多重试的次数
+	maxStepRetries = 2
+)
+
+// StepEvaluation 评估器对刚结束的步骤的评估结果
+type StepEvaluation struct {
+	Score    float64      `json:"score"` // 0~1，结果对目标的推进程度
+	Decision PlanDecision `json:"decision"`
+	Reason   string       `json:"reason"`
+	StepID   int          `json:"step_id,omitempty"` // retry 的步骤
+	Input    string       `json:"input,omitempty"`   // retry 的新输入，为空时沿用原输入
+	Steps    []*PlanStep  `json:"steps,omitempty"`   // insert 新增的步骤或 replan 替换剩余步骤的新步骤
+}
+
+// PlanRevision 计划的一次修订记录
+type PlanRevision struct {
+	Revision       int          `json:"revision"` // 从 1 开始
+	Decision       PlanDecision `json:"decision"`
+	Reason         string       `json:"reason,omitempty"`
+	Score          float64      `json:"score"`
+	EvaluatedSteps []int        `json:"evaluated_steps,omitempty"` // 触发修订的步骤
+	RetriedStep    int          `json:"retried_step,omitempty"`
+	RetryInput     string       `json:"retry_input,omitempty"`
+	AddedSteps     []PlanStep   `json:"added_steps,omitempty"`
+	RemovedSteps   []PlanStep   `json:"removed_steps,omitempty"` // replan 移除的未完成步骤，保留其执行结果
+	Created        time.Time    `json:"created"`
+}
+
+// WithPlanEvaluation 开启计划步骤评估：每批步骤结束后由模型评估结果并决定继续、重试、插入步骤或重新规划
+func WithPlanEvaluation(enabled bool) Option {
+	return func(context *Context) {
+		context.planEvaluation = enabled
+	}
+}
+
+// WithMaxPlanRevisions 设置计划的修订次数上限，小于 1 时使用默认值
+func WithMaxPlanRevisions(n int) Option {
+	return func(context *Context) {
+		if n < 1 {
+			n = defaultMaxPlanRevisions
+		}
+		context.maxPlanRevisions = n
+	}
+}
+
+const stepEvaluatorPrompt = `你是计划执行的评估助手。根据任务目标、当前计划和刚结束的步骤的结果，评估结果是否推进了目标，并决定计划如何继续。
+
+只返回如下 JSON，不要包含其他内容:
+{
+  "score": 0.8,
+  "decision": "continue",
+  "reason": "决定的理由",
+  "step_id": 0,
+  "input": "",
+  "steps": []
+}
+
+score 取值 0~1，表示结果对目标的推进程度。decision 取值:
+- continue: 结果可用，按原计划继续
+- retry: 某个步骤的结果不可用，修改输入后重新执行该步骤。step_id 为要重试的步骤，input 为新的输入
+- insert: 需要补充步骤。steps 为新增的步骤，格式与计划中的步骤相同，可以依赖已有步骤
+- replan: 剩余的计划不再适用。steps 为替换所有未完成步骤的新步骤，已完成的步骤及结果保留，可以在 dependencies 中引用
+
+新步骤的 id 不能与已有步骤重复，工具名称必须从可用工具列表中选择，input 需符合工具的参数 JSON Schema，
+input 中 ${step.N} 引用的步骤必须列在 dependencies 中，依赖关系不能成环。`
+
+// stepEvaluationSchema 步骤评估返回的 JSON Schema
+var stepEvaluationSchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "score": {"type": "number"},
+    "decision": {"type": "string", "enum": ["continue", "retry", "insert", "replan"]},
+    "reason": {"type": "string"},
+    "step_id": {"type": "integer"},
+    "input": {"type": "string"},
+    "steps": {"type": "array", "items": ` + planStepSchema + `}
+  },
+  "required": ["score", "decision", "reason"]
+}`)
+
+// canRevise 是否还能修订计划
+func (a *PlanAgent) canRevise() bool {
+	return a.enableReplan && len(a.plan.Revisions) < a.context.maxPlanRevisions
+}
+
+// evaluate 评估刚结束的一批步骤，按评估结果修订计划
+func (a *PlanAgent) evaluate(ctx context.Context, steps []*PlanStep) string {
+	if !a.context.planEvaluation || !a.canRevise() {
+		return ""
+	}
+	evaluation, err := a.evaluateSteps(ctx, steps)
+	if err != nil {
+		planLogger.Warnf("Step evaluation failed, continue with current plan: %s", err.Error())
+		return ""
+	}
+	planLogger.Infof("🧭 Evaluation of steps %s: %s (score %.2f) %s", stepIDs(steps), evaluation.Decision, evaluation.Score, evaluation.Reason)
+	if evaluation.Decision == PlanContinue {
+		return ""
+	}
+	return a.revise(ctx, evaluation, steps)
+}
+
+// evaluateSteps 请求模型评估步骤结果
+func (a *PlanAgent) evaluateSteps(ctx context.Context, steps []*PlanStep) (*StepEvaluation, error) {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("任务目标: %s\n\n", a.plan.Goal))
+	content.WriteString(a.planStatus())
+	content.WriteString(fmt.Sprintf("\n刚结束的步骤: %s\n\n", stepIDs(steps)))
+	content.WriteString(a.toolsDesc())
+	content.WriteString(a.subAgentsDesc())
+
+	messages := []core.Message{
+		{Role: core.MessageRoleSystem, Content: stepEvaluatorPrompt},
+		{Role: core.MessageRoleUser, Content: content.String()},
+	}
+	var evaluation StepEvaluation
+	err := a.requestJSON(ctx, messages, "step_evaluation", stepEvaluationSchema, func(content string) error {
+		evaluation = StepEvaluation{}
+		if err := json.Unmarshal([]byte(extractJSON(content)), &evaluation); err != nil {
+			return fmt.Errorf("parse evaluation: %w", err)
+		}
+		return a.validateEvaluation(&evaluation)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &evaluation, nil
+}
+
+// validateEvaluation 校验评估结果：重试的步骤已结束且新输入符合工具的参数 schema，新增步骤通过计划校验
+func (a *PlanAgent) validateEvaluation(evaluation *StepEvaluation) error {
+	switch evaluation.Decision {
+	case PlanContinue:
+	case PlanRetry:
+		step := a.findStepByID(evaluation.StepID)
+		if step == nil || (step.Status != PlanStepCompleted && step.Status != PlanStepFailed) {
+			return fmt.Errorf("retry: step %d has not finished", evaluation.StepID)
+		}
+		if evaluation.Input != "" && step.Agent == "" {
+			if tool, ok := a.context.toolManager.GetTool(step.Tool); ok {
+				if err := tools.ValidateInput(tool, stepRefsAsNull(evaluation.Input)); err != nil {
+					return fmt.Errorf("retry: input of tool %s does not match its schema: %w", step.Tool, err)
+				}
+			}
+		}
+	case PlanInsert:
+		return a.validateSteps(evaluation.Steps, a.plan.Steps)
+	case PlanReplan:
+		return a.validateSteps(evaluation.Steps, a.completedSteps())
+	default:
+		return fmt.Errorf("unknown decision %q", evaluation.Decision)
+	}
+	return nil
+}
+
+// revise 按评估结果修订计划并记录修订
+func (a *PlanAgent) revise(ctx context.Context, evaluation *StepEvaluation, steps []*PlanStep) string {
+	revision := PlanRevision{
+		Revision:       len(a.plan.Revisions) + 1,
+		Decision:       evaluation.Decision,
+		Reason:         evaluation.Reason,
+		Score:          evaluation.Score,
+		EvaluatedSteps: stepIDList(steps),
+		Created:        time.Now(),
+	}
+	switch evaluation.Decision {
+	case PlanRetry:
+		step := a.findStepByID(evaluation.StepID)
+		if step.Retries >= maxStepRetries {
+			// 不再重试，按原计划继续，同样记录为一次修订
+			planLogger.Warnf("Step %d reached the retry limit, continue with current plan", step.ID)
+			revision.Decision = PlanContinue
+			revision.Reason = fmt.Sprintf("retry limit reached: step %d has been retried %d times; %s", step.ID, step.Retries, evaluation.Reason)
+			break
+		}
+		step.Retries++
+		if evaluation.Input != "" {
+			step.Input = evaluation.Input
+		}
+		step.Result = ""
+		step.SubRun = nil
+		a.setStepStatus(ctx, step, PlanStepPending, "")
+		a.resetDependents(ctx, step)
+		revision.RetriedStep = step.ID
+		revision.RetryInput = step.Input
+	case PlanInsert:
+		revision.AddedSteps = a.addSteps(ctx, evaluation.Steps)
+	case PlanReplan:
+		revision.RemovedSteps, revision.AddedSteps = a.replaceRemaining(ctx, evaluation.Steps)
+	}
+	a.plan.Revisions = append(a.plan.Revisions, revision)
+	a.plan.Updated = time.Now()
+	planLogger.Infof("✨ Plan revision %d: %s", revision.Revision, revision.Decision)
+	return fmt.Sprintf("Plan revised (%s): %s", revision.Decision, revision.Reason)
+}
+
+// replan 步骤失败或依赖无法满足时，以新的步骤替换所有未完成的步骤
+func (a *PlanAgent) replan(ctx context.Context, failed, blocked []*PlanStep) string {
+	planLogger.Info("🔄 Replanning...")
+
+	replanPrompt := fmt.Sprintf(`任务执行遇到问题，需要重新规划剩余的步骤。
+
+原始目标: %s
+
+%s
+%s%s
+已完成的步骤及结果保留，可以在 dependencies 中引用。请只返回替换所有未完成步骤的新步骤，避免之前失败的问题。
+新步骤的 id 不能与已有步骤重复，input 需符合工具的参数 JSON Schema，${step.N} 引用的步骤必须列在 dependencies 中。
+返回JSON格式: {"steps": [...]}`, a.plan.Goal, a.planStatus(), a.toolsDesc(), a.subAgentsDesc())
+
+	replanMessages := []core.Message{
+		{
+			Role:    core.MessageRoleSystem,
+			Content: a.systemPrompt,
+		},
+		{
+			Role:    core.MessageRoleUser,
+			Content: replanPrompt,
+		},
+	}
+
+	// 解析并校验新步骤，新步骤只能依赖已完成的步骤或其它新步骤
+	var planData struct {
+		Steps []*PlanStep `json:"steps"`
+	}
+	err := a.requestJSON(ctx, replanMessages, "replan", planStepsSchema, func(content string) error {
+		planData.Steps = nil
+		if err := json.Unmarshal([]byte(extractJSON(content)), &planData); err != nil {
+			return fmt.Errorf("parse new plan JSON: %w", err)
+		}
+		return a.validateSteps(planData.Steps, a.completedSteps())
+	})
+	if err != nil {
+		a.plan.Status = "failed"
+		return fmt.Sprintf("Replan failed: %s", err.Error())
+	}
+
+	var reasons []string
+	if len(failed) > 0 {
+		reasons = append(reasons, fmt.Sprintf("steps %s failed", stepIDs(failed)))
+	}
+	if len(blocked) > 0 {
+		reasons = append(reasons, fmt.Sprintf("dependencies of steps %s not met", stepIDs(blocked)))
+	}
+	return a.revise(ctx, &StepEvaluation{
+		Decision: PlanReplan,
+		Reason:   strings.Join(reasons, "; "),
+		Steps:    planData.Steps,
+	}, append(failed, blocked...))
+}
+
+// addSteps 以不与已有步骤冲突的 ID 加入新步骤
+func (a *PlanAgent) addSteps(ctx context.Context, steps []*PlanStep) []PlanStep {
+	a.renumber(steps)
+	added := make([]PlanStep, 0, len(steps))
+	for _, step := range steps {
+		step.Result = ""
+		step.Retries = 0
+		step.SubRun = nil
+		a.plan.Steps = append(a.plan.Steps, step)
+		a.setStepStatus(ctx, step, PlanStepPending, "")
+		added = append(added, *step)
+	}
+	return added
+}
+
+// replaceRemaining 移除所有未完成的步骤并加入新步骤，已完成的步骤保持不变
+func (a *PlanAgent) replaceRemaining(ctx context.Context, steps []*PlanStep) (removed, added []PlanStep) {
+	kept := a.plan.Steps[:0:0]
+	for _, step := range a.plan.Steps {
+		if step.Status == PlanStepCompleted {
+			kept = append(kept, step)
+			continue
+		}
+		removed = append(removed, *step)
+	}
+	a.plan.Steps = kept
+	return removed, a.addSteps(ctx, steps)
+}
+
+// renumber 为与已有步骤 ID 冲突的新步骤分配新 ID。新步骤对其它新步骤原 ID 的依赖随之更新，
+// 对自身原 ID 的依赖视为引用同 ID 的已有步骤
+func (a *PlanAgent) renumber(steps []*PlanStep) {
+	used := make(map[int]bool, len(a.plan.Steps)+len(steps))
+	next := 1
+	for _, step := range a.plan.Steps {
+		used[step.ID] = true
+		if step.ID >= next {
+			next = step.ID + 1
+		}
+	}
+	original := make([]int, len(steps))
+	mapping := make(map[int]int, len(steps))
+	for i, step := range steps {
+		original[i] = step.ID
+		if step.ID <= 0 || used[step.ID] {
+			for used[next] {
+				next++
+			}
+			mapping[step.ID] = next
+			step.ID = next
+		}
+		used[step.ID] = true
+	}
+	if len(mapping) == 0 {
+		return
+	}
+	for i, step := range steps {
+		for j, depID := range step.Dependencies {
+			newID, ok := mapping[depID]
+			if !ok || depID == original[i] {
+				continue
+			}
+			step.Dependencies[j] = newID
+			step.Input = strings.ReplaceAll(step.Input, fmt.Sprintf("${step.%d}", depID), fmt.Sprintf("${step.%d}", newID))
+		}
+	}
+}
+
+// resetDependents 重试步骤时，因其失败而被跳过的后续步骤恢复为待执行
+func (a *PlanAgent) resetDependents(ctx context.Context, retried *PlanStep) {
+	for _, step := range a.plan.Steps {
+		if step.Status != PlanStepSkipped {
+			continue
+		}
+		for _, depID := range step.Dependencies {
+			if depID == retried.ID {
+				step.Result = ""
+				a.setStepStatus(ctx, step, PlanStepPending, "")
+				a.resetDependents(ctx, step)
+				break
+			}
+		}
+	}
+}
+
+// completedSteps 已完成的步骤，重新规划时保留
+func (a *PlanAgent) completedSteps() []*PlanStep {
+	var steps []*PlanStep
+	for _, step := range a.plan.Steps {
+		if step.Status == PlanStepCompleted {
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
+
+// planStatus 当前计划各步骤的状态与结果
+func (a *PlanAgent) planStatus() string {
+	var status strings.Builder
+	status.WriteString("当前执行状态:\n")
+	for _, step := range a.plan.Steps {
+		action := step.Tool
+		if step.Agent != "" {
+			action = "agent " + step.Agent
+		}
+		status.WriteString(fmt.Sprintf("Step %d (%s) [%s: %s] %s", step.ID, step.Status, action, step.Input, step.Description))
+		if len(step.Dependencies) > 0 {
+			status.WriteString(fmt.Sprintf(" (依赖: %v)", step.Dependencies))
+		}
+		status.WriteString("\n")
+		if step.Result != "" {
+			status.WriteString(fmt.Sprintf("  结果: %s\n", truncateString(step.Result, 500)))
+		}
+	}
+	return status.String()
+}
+
+func stepIDList(steps []*PlanStep) []int {
+	ids := make([]int, 0, len(steps))
+	for _, step := range steps {
+		ids = append(ids, step.ID)
+	}
+	return ids
+}
+
+func stepIDs(steps []*PlanStep) string {
+	ids := make([]string, 0, len(steps))
+	for _, step := range steps {
+		ids = append(ids, fmt.Sprint(step.ID))
+	}
+	return strings.Join(ids, ", ")
+}
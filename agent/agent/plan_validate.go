@@ -0,0 +1,286 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"jas-agent/agent/core"
+	"jas-agent/agent/llm"
+	"jas-agent/agent/tools"
+)
+
+// defaultPlanRepairAttempts 默认允许模型修正无效计划的次数
+const defaultPlanRepairAttempts = 2
+
+// stepRefPattern 步骤输入中对其它步骤结果的引用 ${step.N}
+var stepRefPattern = regexp.MustCompile(`\$\{step\.(\d+)\}`)
+
+// planStepSchema 计划步骤的 JSON Schema，服务商支持结构化输出时约束模型的返回
+const planStepSchema = `{
+  "type": "object",
+  "properties": {
+    "id": {"type": "integer"},
+    "description": {"type": "string"},
+    "tool": {"type": "string"},
+    "agent": {"type": "string"},
+    "input": {"type": "string"},
+    "max_steps": {"type": "integer"},
+    "tools": {"type": "array", "items": {"type": "string"}},
+    "dependencies": {"type": "array", "items": {"type": "integer"}}
+  },
+  "required": ["id", "description", "input", "dependencies"]
+}`
+
+var (
+	// planSchema 生成计划返回的 JSON Schema
+	planSchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "goal": {"type": "string"},
+    "steps": {"type": "array", "items": ` + planStepSchema + `}
+  },
+  "required": ["goal", "steps"]
+}`)
+	// planStepsSchema 重新规划返回的 JSON Schema
+	planStepsSchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "steps": {"type": "array", "items": ` + planStepSchema + `}
+  },
+  "required": ["steps"]
+}`)
+)
+
+// PlanValidationError 模型返回的计划未通过校验
+type PlanValidationError struct {
+	Problems []string
+}
+
+func (e *PlanValidationError) Error() string {
+	return "invalid plan: " + strings.Join(e.Problems, "; ")
+}
+
+// WithPlanRepairAttempts 设置计划未通过校验时交由模型修正的次数，0 表示不修正，小于 0 时使用默认值
+func WithPlanRepairAttempts(n int) Option {
+	return func(context *Context) {
+		if n < 0 {
+			n = defaultPlanRepairAttempts
+		}
+		context.planRepairAttempts = n
+	}
+}
+
+// requestJSON 请求模型按 schema 返回 JSON 并交由 parse 解析校验，失败时把错误反馈给模型修正，
+// 最多修正 planRepairAttempts 次
+func (a *PlanAgent) requestJSON(ctx context.Context, messages []core.Message, name string, schema json.RawMessage, parse func(content string) error) error {
+	messages = append([]core.Message(nil), messages...)
+	for attempt := 0; ; attempt++ {
+		req := llm.NewChatRequest(a.context.ModelFor(ModelPhasePlan), messages).WithResponseFormat(name, schema)
+		resp, err := a.context.Completions(ctx, req)
+		if err != nil {
+			return err
+		}
+		content := resp.Content()
+		err = parse(content)
+		if err == nil {
+			return nil
+		}
+		if attempt >= a.context.planRepairAttempts {
+			return err
+		}
+		planLogger.Warnf("Invalid %s, asking model to repair (%d/%d): %s", name, attempt+1, a.context.planRepairAttempts, err.Error())
+		messages = append(messages,
+			core.Message{Role: core.MessageRoleAssistant, Content: content},
+			core.Message{Role: core.MessageRoleUser, Content: fmt.Sprintf(`上面返回的内容无法使用:
+%s
+
+请修正以上问题，按要求的格式重新返回完整的JSON，不要包含其他内容。`, err.Error())},
+		)
+	}
+}
+
+// validateSteps 校验模型返回的新步骤：工具存在且输入符合工具的参数 schema，委派的子 Agent 可用，
+// 依赖的步骤存在且依赖关系无环。existing 为新步骤可以依赖的已有步骤
+func (a *PlanAgent) validateSteps(steps []*PlanStep, existing []*PlanStep) error {
+	var problems []string
+	if len(steps) == 0 {
+		problems = append(problems, "no steps given")
+	}
+	existingIDs := make(map[int]bool, len(existing))
+	for _, step := range existing {
+		existingIDs[step.ID] = true
+	}
+	newSteps := make(map[int]*PlanStep, len(steps))
+	for _, step := range steps {
+		if step == nil {
+			problems = append(problems, "step must be an object")
+			continue
+		}
+		if step.ID <= 0 {
+			problems = append(problems, fmt.Sprintf("step %q: id must be a positive integer", step.Description))
+			continue
+		}
+		if newSteps[step.ID] != nil {
+			problems = append(problems, fmt.Sprintf("step %d: duplicate id", step.ID))
+			continue
+		}
+		newSteps[step.ID] = step
+	}
+	availableTools := make(map[string]core.Tool)
+	for _, tool := range a.context.toolManager.AvailableTools() {
+		availableTools[tool.Name()] = tool
+	}
+	subAgents := make(map[string]bool, len(a.context.subAgents))
+	if a.subAgentsDesc() != "" {
+		for _, subAgent := range a.context.subAgents {
+			subAgents[subAgent.Name] = true
+		}
+	}
+
+	for _, step := range steps {
+		if step == nil || step.ID <= 0 {
+			continue
+		}
+		switch {
+		case step.Agent != "" && step.Tool != "":
+			problems = append(problems, fmt.Sprintf("step %d: set either tool or agent, not both", step.ID))
+		case step.Agent != "":
+			if !subAgents[step.Agent] {
+				problems = append(problems, fmt.Sprintf("step %d: agent %q is not available", step.ID, step.Agent))
+			}
+		case step.Tool == "":
+			problems = append(problems, fmt.Sprintf("step %d: tool is required", step.ID))
+		default:
+			tool, ok := availableTools[step.Tool]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("step %d: tool %q does not exist", step.ID, step.Tool))
+				break
+			}
+			if err := tools.ValidateInput(tool, stepRefsAsNull(step.Input)); err != nil {
+				problems = append(problems, fmt.Sprintf("step %d: input of tool %s does not match its schema: %s", step.ID, step.Tool, err.Error()))
+			}
+		}
+		for _, depID := range step.Dependencies {
+			if depID == step.ID && !existingIDs[depID] {
+				problems = append(problems, fmt.Sprintf("step %d: depends on itself", step.ID))
+			} else if newSteps[depID] == nil && !existingIDs[depID] {
+				problems = append(problems, fmt.Sprintf("step %d: dependency step %d does not exist", step.ID, depID))
+			}
+		}
+		reported := make(map[int]bool)
+		for _, match := range stepRefPattern.FindAllStringSubmatch(step.Input, -1) {
+			refID, _ := strconv.Atoi(match[1])
+			if !containsInt(step.Dependencies, refID) && !reported[refID] {
+				reported[refID] = true
+				problems = append(problems, fmt.Sprintf("step %d: input references %s but dependencies do not include %d", step.ID, match[0], refID))
+			}
+		}
+	}
+	if cycle := dependencyCycle(steps, newSteps); len(cycle) > 0 {
+		problems = append(problems, fmt.Sprintf("dependency cycle: %s", joinInts(cycle, " -> ")))
+	}
+	if len(problems) > 0 {
+		return &PlanValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// dependencyCycle 新步骤之间的依赖环，无环时返回 nil。
+// 与 renumber 一致，步骤对自身 ID 的依赖指向同 ID 的已有步骤，不计入环
+func dependencyCycle(steps []*PlanStep, newSteps map[int]*PlanStep) []int {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[int]int, len(newSteps))
+	var path []int
+	var visit func(step *PlanStep) []int
+	visit = func(step *PlanStep) []int {
+		state[step.ID] = visiting
+		path = append(path, step.ID)
+		for _, depID := range step.Dependencies {
+			dep := newSteps[depID]
+			if dep == nil || depID == step.ID {
+				continue
+			}
+			switch state[depID] {
+			case visiting:
+				for i, id := range path {
+					if id == depID {
+						return append(append([]int(nil), path[i:]...), depID)
+					}
+				}
+			case unvisited:
+				if cycle := visit(dep); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[step.ID] = visited
+		return nil
+	}
+	for _, step := range steps {
+		if step == nil || newSteps[step.ID] != step || state[step.ID] != unvisited {
+			continue
+		}
+		if cycle := visit(step); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// stepRefsAsNull 将 JSON 字符串之外的 ${step.N} 替换为 null，使引用其它步骤结果的输入可以按 schema 校验
+func stepRefsAsNull(input string) string {
+	var out strings.Builder
+	inString, escaped := false, false
+	for i := 0; i < len(input); i++ {
+		c := input[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			out.WriteByte(c)
+			continue
+		}
+		if c == '"' {
+			inString = true
+		} else if c == '$' {
+			if loc := stepRefPattern.FindStringIndex(input[i:]); loc != nil && loc[0] == 0 {
+				out.WriteString("null")
+				i += loc[1] - 1
+				continue
+			}
+		}
+		out.WriteByte(c)
+	}
+	return out.String()
+}
+
+func containsInt(values []int, target int) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
+func joinInts(values []int, sep string) string {
+	parts := make([]string, 0, len(values))
+	for _, value := range values {
+		parts = append(parts, strconv.Itoa(value))
+	}
+	return strings.Join(parts, sep)
+}
@@ -0,0 +1,274 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"jas-agent/agent/llm"
+	"jas-agent/agent/tools"
+)
+
+// newValidatePlanAgent 可用工具为 fetch（字符串输入）与 query（limit 为必填整数），可委派的子 Agent 为 analyst
+func newValidatePlanAgent() *PlanAgent {
+	tm := tools.NewToolManager()
+	tm.RegisterTool(&fakeTool{name: "fetch"})
+	tm.RegisterTool(&fakeTool{name: "query", input: map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"limit": map[string]any{"type": "integer"}},
+		"required":   []string{"limit"},
+	}})
+	factory := func(_ context.Context, _ string, subCtx *Context) (*AgentExecutor, error) {
+		return NewAgentExecutor(subCtx), nil
+	}
+	executor := newTestPlanExecutor(&fakeChat{}, tm, false,
+		WithSubAgents(factory, []SubAgent{{Name: "analyst", Description: "analyzes data"}}))
+	return executor.agent.(*PlanAgent)
+}
+
+func TestValidateSteps(t *testing.T) {
+	tests := []struct {
+		name     string
+		steps    string
+		existing []*PlanStep
+		// wantProblems 为空时校验通过
+		wantProblems []string
+	}{
+		{
+			name: "valid",
+			steps: `[{"id": 1, "tool": "fetch", "input": "orders"},
+				{"id": 2, "tool": "query", "input": "{\"limit\": 10}", "dependencies": [1]},
+				{"id": 3, "agent": "analyst", "input": "analyze ${step.1}", "dependencies": [1]}]`,
+		},
+		{
+			// 引用其它步骤结果的位置按 null 校验
+			name:  "step reference in tool input",
+			steps: `[{"id": 1, "tool": "fetch", "input": "count"}, {"id": 2, "tool": "query", "input": "{\"limit\": ${step.1}}", "dependencies": [1]}]`,
+		},
+		{
+			// 重新规划的步骤可以依赖已有步骤，对自身 ID 的依赖指向同 ID 的已有步骤
+			name:     "dependency on existing step",
+			steps:    `[{"id": 2, "tool": "fetch", "input": "${step.2}", "dependencies": [2]}]`,
+			existing: []*PlanStep{{ID: 2, Tool: "fetch", Status: PlanStepFailed}},
+		},
+		{
+			name:         "no steps",
+			steps:        `[]`,
+			wantProblems: []string{"no steps given"},
+		},
+		{
+			name:         "unknown tool",
+			steps:        `[{"id": 1, "tool": "drop_table", "input": "orders"}]`,
+			wantProblems: []string{`step 1: tool "drop_table" does not exist`},
+		},
+		{
+			name:         "unknown agent",
+			steps:        `[{"id": 1, "agent": "sql", "input": "count orders"}]`,
+			wantProblems: []string{`step 1: agent "sql" is not available`},
+		},
+		{
+			name:         "tool and agent",
+			steps:        `[{"id": 1, "tool": "fetch", "agent": "analyst", "input": "orders"}]`,
+			wantProblems: []string{"step 1: set either tool or agent, not both"},
+		},
+		{
+			name:         "missing tool",
+			steps:        `[{"id": 1, "input": "orders"}]`,
+			wantProblems: []string{"step 1: tool is required"},
+		},
+		{
+			name: "schema mismatch",
+			steps: `[{"id": 1, "tool": "query", "input": "{\"limit\": \"ten\"}"},
+				{"id": 2, "tool": "query", "input": "{}"},
+				{"id": 3, "tool": "query", "input": "limit 10"}]`,
+			wantProblems: []string{
+				"step 1: input of tool query does not match its schema: input.limit: expected integer, got string",
+				`step 2: input of tool query does not match its schema: input: missing required property "limit"`,
+				"step 3: input of tool query does not match its schema: input is not valid JSON",
+			},
+		},
+		{
+			name:         "invalid and duplicate ids",
+			steps:        `[{"id": 0, "description": "zero", "tool": "fetch", "input": "a"}, {"id": 1, "tool": "fetch", "input": "b"}, {"id": 1, "tool": "fetch", "input": "c"}]`,
+			wantProblems: []string{`step "zero": id must be a positive integer`, "step 1: duplicate id"},
+		},
+		{
+			name:         "missing dependency",
+			steps:        `[{"id": 1, "tool": "fetch", "input": "orders", "dependencies": [9]}]`,
+			wantProblems: []string{"step 1: dependency step 9 does not exist"},
+		},
+		{
+			name:         "depends on itself",
+			steps:        `[{"id": 1, "tool": "fetch", "input": "orders", "dependencies": [1]}]`,
+			wantProblems: []string{"step 1: depends on itself"},
+		},
+		{
+			name:         "reference without dependency",
+			steps:        `[{"id": 1, "tool": "fetch", "input": "orders"}, {"id": 2, "tool": "fetch", "input": "${step.1} ${step.1}"}]`,
+			wantProblems: []string{"step 2: input references ${step.1} but dependencies do not include 1"},
+		},
+		{
+			// 报告环上的完整路径
+			name: "cycle",
+			steps: `[{"id": 1, "tool": "fetch", "input": "a", "dependencies": [2]},
+				{"id": 2, "tool": "fetch", "input": "b", "dependencies": [3]},
+				{"id": 3, "tool": "fetch", "input": "c", "dependencies": [1]},
+				{"id": 4, "tool": "fetch", "input": "d", "dependencies": [3]}]`,
+			wantProblems: []string{"dependency cycle: 1 -> 2 -> 3 -> 1"},
+		},
+		{
+			name: "cycle after acyclic prefix",
+			steps: `[{"id": 1, "tool": "fetch", "input": "a", "dependencies": [2]},
+				{"id": 2, "tool": "fetch", "input": "b", "dependencies": [3]},
+				{"id": 3, "tool": "fetch", "input": "c", "dependencies": [2]}]`,
+			wantProblems: []string{"dependency cycle: 2 -> 3 -> 2"},
+		},
+	}
+	agent := newValidatePlanAgent()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var steps []*PlanStep
+			if err := json.Unmarshal([]byte(tt.steps), &steps); err != nil {
+				t.Fatal(err)
+			}
+			err := agent.validateSteps(steps, tt.existing)
+			if len(tt.wantProblems) == 0 {
+				if err != nil {
+					t.Fatalf("validateSteps() error = %v", err)
+				}
+				return
+			}
+			var validationErr *PlanValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("validateSteps() error = %v, want PlanValidationError", err)
+			}
+			if len(validationErr.Problems) != len(tt.wantProblems) {
+				t.Fatalf("problems = %q, want %q", validationErr.Problems, tt.wantProblems)
+			}
+			for i, want := range tt.wantProblems {
+				if !strings.HasPrefix(validationErr.Problems[i], want) {
+					t.Errorf("problem %d = %q, want %q", i, validationErr.Problems[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestStepRefsAsNull(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: `{"limit": ${step.1}}`, want: `{"limit": null}`},
+		{input: `[${step.1}, ${step.12}]`, want: `[null, null]`},
+		// 字符串中的引用保留原样，转义的引号不结束字符串
+		{input: `{"q": "${step.1}", "r": "say \"${step.2}\"", "n": ${step.3}}`, want: `{"q": "${step.1}", "r": "say \"${step.2}\"", "n": null}`},
+		{input: `{"cost": $5, "ref": ${step.x}}`, want: `{"cost": $5, "ref": ${step.x}}`},
+	}
+	for _, tt := range tests {
+		if got := stepRefsAsNull(tt.input); got != tt.want {
+			t.Errorf("stepRefsAsNull(%s) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestPlanRepair(t *testing.T) {
+	valid := planSteps(`{"id": 1, "tool": "fetch", "input": "orders"}`)
+	unknownTool := planSteps(`{"id": 1, "tool": "drop_table", "input": "orders"}`)
+	tests := []struct {
+		name     string
+		replies  []string // 依次返回的计划
+		attempts int
+		// wantRequests 生成计划的请求次数
+		wantRequests int
+		wantStatus   string
+		// wantFeedback 每次修正请求反馈给模型的问题
+		wantFeedback []string
+	}{
+		{
+			name:         "valid first reply",
+			replies:      []string{valid},
+			attempts:     2,
+			wantRequests: 1,
+			wantStatus:   "completed",
+		},
+		{
+			name:         "repaired after invalid reply",
+			replies:      []string{unknownTool, valid},
+			attempts:     2,
+			wantRequests: 2,
+			wantStatus:   "completed",
+			wantFeedback: []string{`step 1: tool "drop_table" does not exist`},
+		},
+		{
+			name:         "repaired after unparsable reply",
+			replies:      []string{"the plan is to fetch orders", valid},
+			attempts:     2,
+			wantRequests: 2,
+			wantStatus:   "completed",
+			wantFeedback: []string{"parse plan JSON"},
+		},
+		{
+			// 修正次数用尽后计划失败，不执行任何步骤
+			name:         "attempts exhausted",
+			replies:      []string{unknownTool, "not json", unknownTool, valid},
+			attempts:     2,
+			wantRequests: 3,
+			wantStatus:   "failed",
+			wantFeedback: []string{`tool "drop_table" does not exist`, "parse plan JSON"},
+		},
+		{
+			name:         "repair disabled",
+			replies:      []string{unknownTool, valid},
+			attempts:     0,
+			wantRequests: 1,
+			wantStatus:   "failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &recordingTool{}
+			tm := tools.NewToolManager()
+			tm.RegisterTool(recorder.tool("fetch"))
+			var requests []llm.ChatRequest
+			chat := &fakeChat{respond: func(req llm.ChatRequest) (*llm.ChatResponse, error) {
+				// 修正请求沿用生成计划的消息，其余为计划完成后的总结请求
+				if messages := req.Request().Messages; len(messages) < 2 || !strings.Contains(messages[1].Content, "请为以下任务生成详细的执行计划") {
+					return textReply("summary"), nil
+				}
+				requests = append(requests, req)
+				reply := tt.replies[len(requests)-1]
+				return textReply(reply), nil
+			}}
+			executor := newTestPlanExecutor(chat, tm, false, WithPlanRepairAttempts(tt.attempts))
+
+			result := executor.Run(context.Background(), "investigate")
+			plan := executor.agent.(*PlanAgent).plan
+			if len(requests) != tt.wantRequests || plan.Status != tt.wantStatus {
+				t.Fatalf("requests = %d, plan status = %s, want %d, %s", len(requests), plan.Status, tt.wantRequests, tt.wantStatus)
+			}
+			if tt.wantStatus == "failed" {
+				if !strings.HasPrefix(result, "Plan generation failed: ") || len(recorder.inputs) != 0 {
+					t.Fatalf("result = %q, tool inputs = %q", result, recorder.inputs)
+				}
+			} else if strings.Join(recorder.inputs, "|") != "orders" {
+				t.Fatalf("tool inputs = %q", recorder.inputs)
+			}
+			// 修正请求带上此前的回答与校验问题
+			for i, want := range tt.wantFeedback {
+				messages := requests[i+1].Request().Messages
+				if len(messages) != 2+2*(i+1) {
+					t.Fatalf("repair request %d has %d messages", i+1, len(messages))
+				}
+				if answer := messages[len(messages)-2].Content; answer != tt.replies[i] {
+					t.Fatalf("repair request %d echoes %q, want %q", i+1, answer, tt.replies[i])
+				}
+				if feedback := lastUserContent(requests[i+1]); !strings.Contains(feedback, want) {
+					t.Fatalf("repair request %d feedback = %q, want %q", i+1, feedback, want)
+				}
+			}
+		})
+	}
+}
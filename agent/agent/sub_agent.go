@@ -0,0 +1,204 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"jas-agent/agent/core"
+	"jas-agent/agent/memory"
+	"jas-agent/agent/tools"
+)
+
+// ErrMaxAgentDepth 子 Agent 的嵌套层数超出上限
+var ErrMaxAgentDepth = errors.New("max agent depth exceeded")
+
+// defaultMaxAgentDepth 默认允许的子 Agent 嵌套层数
+const defaultMaxAgentDepth = 2
+
+// SubAgent 可被计划步骤委派的子 Agent，用于生成计划时告知模型
+type SubAgent struct {
+	Name        string // 步骤中 agent 字段的取值，如 Agent 类型 "sql" 或 Agent ID
+	Description string
+}
+
+// SubAgentFactory 按步骤的 agent 字段在 subCtx 上创建子 Agent 的执行器。
+// subCtx 已按步骤的工具集与独立记忆派生，创建时可以在其上覆盖模型、注册工具或写入系统提示词，
+// 为子 Agent 打开的资源通过 AddCloser 登记，子 Agent 运行结束后关闭
+type SubAgentFactory func(ctx context.Context, name string, subCtx *Context) (*AgentExecutor, error)
+
+// SubRunTrace 子 Agent 的一次运行记录
+type SubRunTrace struct {
+	StepID      int      `json:"step_id"`
+	Agent       string   `json:"agent"`
+	AgentType   string   `json:"agent_type,omitempty"`
+	Depth       int      `json:"depth"`  // 子 Agent 所在的层数，从 1 开始
+	Source      string   `json:"source"` // 来源路径，与子 Agent 推送消息的 Source 一致
+	Status      string   `json:"status"` // completed 或 failed
+	Input       string   `json:"input,omitempty"`
+	Output      string   `json:"output,omitempty"`
+	Error       string   `json:"error,omitempty"`
+	Steps       int      `json:"steps,omitempty"`
+	ToolsCalled []string `json:"tools_called,omitempty"`
+	State       string   `json:"state,omitempty"` // 子 Agent 执行器的结束状态
+	DurationMs  int64    `json:"duration_ms,omitempty"`
+	// SubRuns 子 Agent 自身委派的子运行
+	SubRuns []SubRunTrace `json:"sub_runs,omitempty"`
+}
+
+// WithSubAgents 允许计划步骤委派子 Agent，agents 为生成计划时提供给模型的可选子 Agent
+func WithSubAgents(factory SubAgentFactory, agents []SubAgent) Option {
+	return func(context *Context) {
+		context.subAgentFactory = factory
+		context.subAgents = agents
+	}
+}
+
+// WithMaxAgentDepth 设置子 Agent 的嵌套层数上限，小于 1 时使用默认值
+func WithMaxAgentDepth(n int) Option {
+	return func(context *Context) {
+		if n < 1 {
+			n = defaultMaxAgentDepth
+		}
+		context.maxAgentDepth = n
+	}
+}
+
+// runSubAgent 在独立记忆、步骤工具集的子上下文中运行子 Agent，以其最终答案作为步骤结果
+func (a *PlanAgent) runSubAgent(ctx context.Context, step *PlanStep, input string) (string, error) {
+	if input == "" {
+		input = step.Description
+	}
+	trace := &SubRunTrace{StepID: step.ID, Agent: step.Agent, Input: input}
+	step.SubRun = trace
+	patterns := step.Tools
+	if len(patterns) == 0 {
+		patterns = []string{"*"}
+	}
+	return runAgent(ctx, a.context, trace, fmt.Sprintf("step%d:%s", step.ID, step.Agent), a.context.toolManager.Subset(patterns), step.MaxSteps)
+}
+
+// runAgent 在 parent 派生的独立记忆子上下文中运行 trace.Agent 指定的子 Agent，以其最终答案作为结果并记录到 trace。
+// source 为本次运行在来源路径中的名称，子 Agent 推送的消息带有完整的来源路径与层数
+func runAgent(ctx context.Context, parent *Context, trace *SubRunTrace, source string, toolManager *tools.ToolManager, maxSteps int) (string, error) {
+	trace.Depth = parent.depth + 1
+	if parent.source != "" {
+		source = parent.source + "/" + source
+	}
+	trace.Source = source
+	start := time.Now()
+	result, err := func() (result string, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("sub agent %s panic: %v", trace.Agent, r)
+			}
+		}()
+		if parent.subAgentFactory == nil {
+			return "", fmt.Errorf("sub agent %s: delegation is not enabled", trace.Agent)
+		}
+		if trace.Depth > parent.maxAgentDepth {
+			return "", fmt.Errorf("%w: sub agent %s at depth %d, limit %d", ErrMaxAgentDepth, trace.Agent, trace.Depth, parent.maxAgentDepth)
+		}
+		subCtx := parent.Derive(
+			WithToolManager(toolManager),
+			WithMemory(memory.NewMemory()),
+			withDepth(trace.Depth),
+			withSource(source),
+		)
+		executor, err := parent.subAgentFactory(ctx, trace.Agent, subCtx)
+		if err != nil {
+			return "", fmt.Errorf("create sub agent %s: %w", trace.Agent, err)
+		}
+		defer executor.Close()
+		if maxSteps > 0 {
+			executor.SetMaxSteps(maxSteps)
+		}
+		// 子 Agent 的最终答案直接作为结果，不再单独总结
+		executor.SetSummaryPolicy(SummaryPolicy{Mode: SummaryNever})
+		trace.AgentType = string(executor.GetAgentType())
+
+		output := executor.Run(ctx, trace.Input)
+		trace.Steps = executor.GetCurrentStep()
+		trace.ToolsCalled = executor.GetToolCalls()
+		trace.State = string(executor.GetState())
+		if finalResult := executor.GetFinalResult(); finalResult != nil {
+			trace.SubRuns = finalResult.SubRuns
+			if finalResult.Answer != "" {
+				output = finalResult.Answer
+			}
+		}
+		if executor.GetState() != FinishState {
+			return "", fmt.Errorf("sub agent %s ended with state %s: %s", trace.Agent, executor.GetState(), output)
+		}
+		// 计划执行失败时执行器同样以 Finish 结束
+		if subPlan, ok := executor.agent.(*PlanAgent); ok && subPlan.plan != nil && subPlan.plan.Status == "failed" {
+			return "", fmt.Errorf("sub agent %s: plan execution failed", trace.Agent)
+		}
+		return output, nil
+	}()
+	trace.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		trace.Status = PlanStepFailed
+		trace.Error = err.Error()
+		return "", err
+	}
+	trace.Status = PlanStepCompleted
+	trace.Output = result
+	return result, nil
+}
+
+// subAgentsDesc 生成计划时的可委派子 Agent 说明，未启用委派或已达到层数上限时为空
+func (a *PlanAgent) subAgentsDesc() string {
+	if a.context.subAgentFactory == nil || len(a.context.subAgents) == 0 || a.context.depth >= a.context.maxAgentDepth {
+		return ""
+	}
+	var desc strings.Builder
+	desc.WriteString("可委派的子Agent（步骤设置 agent 字段时，由子Agent完成该步骤，input 作为子Agent的任务，其最终答案作为步骤结果）:\n")
+	for _, subAgent := range a.context.subAgents {
+		desc.WriteString(fmt.Sprintf("- %s: %s\n", subAgent.Name, subAgent.Description))
+	}
+	desc.WriteString("\n")
+	return desc.String()
+}
+
+// subRuns 按步骤顺序收集各步骤的子运行记录
+func (a *PlanAgent) subRuns() []SubRunTrace {
+	if a.plan == nil {
+		return nil
+	}
+	var traces []SubRunTrace
+	for _, step := range a.plan.Steps {
+		if step.SubRun != nil {
+			traces = append(traces, *step.SubRun)
+		}
+	}
+	return traces
+}
+
+// withSource 设置子上下文的来源路径，推送的消息未标记来源时标记为该路径与所在层数，
+// 调用方据此区分嵌套运行的消息并单独编号步骤
+func withSource(source string) Option {
+	return func(subCtx *Context) {
+		subCtx.source = source
+		send := subCtx.send
+		if send == nil {
+			return
+		}
+		depth := subCtx.depth
+		subCtx.send = func(c context.Context, msg core.Message) error {
+			if msg.Source == "" {
+				msg.Source, msg.Depth = source, depth
+			}
+			return send(c, msg)
+		}
+	}
+}
+
+// withDepth 设置子上下文所在的层数
+func withDepth(depth int) Option {
+	return func(context *Context) {
+		context.depth = depth
+	}
+}
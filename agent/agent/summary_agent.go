@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"jas-agent/agent/core"
 	"jas-agent/agent/llm"
+	"jas-agent/agent/window"
 	"strings"
 )
 
@@ -18,9 +19,14 @@ func (agent *SummaryAgent) Type() AgentType {
 	return SummaryAgentType
 }
 
-func (agent *SummaryAgent) Step() string {
-	// 获取所有执行历史
-	messages := agent.context.memory.GetMessages()
+func (agent *SummaryAgent) Step(ctx context.Context) string {
+	policy := agent.executor.summaryPolicy
+	model := agent.context.ModelFor(ModelPhaseSummary)
+	if policy.Model != "" {
+		model = policy.Model
+	}
+	// 获取所有执行历史，超出上下文窗口时先压缩
+	messages := agent.context.fitMessages(ctx, model, agent.context.memory.GetMessages())
 
 	// 构建总结上下文
 	var executionLog strings.Builder
@@ -29,8 +35,11 @@ func (agent *SummaryAgent) Step() string {
 	for i, msg := range messages {
 		switch msg.Role {
 		case core.MessageRoleSystem:
-			// 跳过系统提示词
-			continue
+			// 跳过系统提示词，保留上下文压缩产生的省略说明或摘要
+			if !window.IsCompactionNote(msg) {
+				continue
+			}
+			executionLog.WriteString(fmt.Sprintf("%s\n", msg.Content))
 		case core.MessageRoleUser:
 			if strings.Contains(msg.Content, "Observation:") {
 				executionLog.WriteString(fmt.Sprintf("观察 %d: %s\n", i, msg.Content))
@@ -39,9 +48,18 @@ func (agent *SummaryAgent) Step() string {
 			}
 		case core.MessageRoleAssistant:
 			executionLog.WriteString(fmt.Sprintf("思考 %d: %s\n", i, msg.Content))
+			for _, toolCall := range msg.ToolCalls {
+				executionLog.WriteString(fmt.Sprintf("行动 %d: %s[%s]\n", i, toolCall.Name, toolCall.Arguments))
+			}
+		case core.MessageRoleTool:
+			executionLog.WriteString(fmt.Sprintf("观察 %d: %s\n", i, msg.Content))
 		}
 	}
 
+	if result := agent.executor.GetFinalResult(); result != nil && result.Answer != "" {
+		executionLog.WriteString(fmt.Sprintf("最终答案: %s\n", result.Answer))
+	}
+
 	// 添加总结提示
 	executionLog.WriteString("\n请基于以上执行过程，提供简洁明了的最终答案。")
 
@@ -49,7 +67,7 @@ func (agent *SummaryAgent) Step() string {
 	summaryMessages := []core.Message{
 		{
 			Role:    core.MessageRoleSystem,
-			Content: agent.systemPrompt,
+			Content: agent.prompt(),
 		},
 		{
 			Role:    core.MessageRoleUser,
@@ -57,8 +75,22 @@ func (agent *SummaryAgent) Step() string {
 		},
 	}
 
-	// 调用LLM进行总结
-	resp, err := agent.context.chat.Completions(context.TODO(), llm.NewChatRequest(agent.context.model, summaryMessages))
+	// 调用LLM进行总结，有推送通道时逐段推送总结
+	req := llm.NewChatRequest(model, summaryMessages)
+	var resp *llm.ChatResponse
+	var err error
+	if agent.context.send != nil {
+		resp, err = agent.context.chat.CompletionsStream(ctx, req, func(delta llm.ChatDelta) error {
+			agent.context.Send(ctx, core.Message{
+				Role:    core.MessageRoleAssistant,
+				Content: delta.Content,
+				Kind:    core.MessageKindSummary,
+			})
+			return nil
+		})
+	} else {
+		resp, err = agent.context.chat.Completions(ctx, req)
+	}
 	if err != nil {
 		return fmt.Sprintf("总结生成失败: %s", err.Error())
 	}
@@ -78,3 +110,48 @@ func NewSummaryAgent(context *Context, executor *AgentExecutor) Agent {
 		executor:     executor,
 	}
 }
+
+// prompt 返回总结使用的系统提示词，策略中配置了模版时优先使用
+func (agent *SummaryAgent) prompt() string {
+	template := agent.executor.summaryPolicy.PromptTemplate
+	if template == "" {
+		return agent.systemPrompt
+	}
+	// 已注册的模版名称按模版构建，否则视为提示词文本
+	if prompt, err := core.BuildGlobalPrompt(template, map[string]interface{}{}); err == nil {
+		return prompt
+	}
+	return template
+}
+
+// SummaryMode 总结阶段的执行策略
+type SummaryMode string
+
+const (
+	// SummaryAlways 每次完成后都进行总结（默认）
+	SummaryAlways SummaryMode = "always"
+	// SummaryNever 不进行总结，直接返回最终答案
+	SummaryNever SummaryMode = "never"
+	// SummaryMinSteps 执行步数超过 MinSteps 时才进行总结
+	SummaryMinSteps SummaryMode = "min_steps"
+)
+
+// SummaryPolicy 总结阶段配置
+type SummaryPolicy struct {
+	Mode           SummaryMode
+	MinSteps       int
+	Model          string // 总结使用的模型，为空时使用 Agent 的模型
+	PromptTemplate string // 已注册的模版名称或提示词文本，为空时使用内置的 summary_system
+}
+
+// enabled 判断执行了 steps 步后是否需要总结
+func (p SummaryPolicy) enabled(steps int) bool {
+	switch p.Mode {
+	case SummaryNever:
+		return false
+	case SummaryMinSteps:
+		return steps > p.MinSteps
+	default:
+		return true
+	}
+}
@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"jas-agent/agent/core"
+	"jas-agent/agent/llm"
+	"jas-agent/agent/tools"
+)
+
+func TestSummaryPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      SummaryPolicy
+		wantSummary bool
+		// wantModel、wantPrompt 总结请求使用的模型与系统提示词，为空时不检查
+		wantModel  string
+		wantPrompt string
+	}{
+		{name: "always", policy: SummaryPolicy{Mode: SummaryAlways}, wantSummary: true, wantModel: "agent-model", wantPrompt: core.GetSummarySystemPrompt()},
+		// 未设置时与 always 相同
+		{name: "default", policy: SummaryPolicy{}, wantSummary: true},
+		{name: "never", policy: SummaryPolicy{Mode: SummaryNever}},
+		// 运行了 2 步，超过 MinSteps 才总结
+		{name: "min steps exceeded", policy: SummaryPolicy{Mode: SummaryMinSteps, MinSteps: 1}, wantSummary: true},
+		{name: "min steps not exceeded", policy: SummaryPolicy{Mode: SummaryMinSteps, MinSteps: 2}},
+		{name: "summary model", policy: SummaryPolicy{Model: "summary-model"}, wantSummary: true, wantModel: "summary-model"},
+		{name: "prompt text", policy: SummaryPolicy{PromptTemplate: "用一句话总结"}, wantSummary: true, wantPrompt: "用一句话总结"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chat := &fakeChat{replies: []*llm.ChatResponse{
+				textReply("Action: calculator[1+1]"),
+				textReply("Action: Finish[2]"),
+				textReply("the summarized answer"),
+			}}
+			tm := tools.NewToolManager()
+			tm.RegisterTool(&fakeTool{name: "calculator"})
+			executor := NewAgentExecutor(NewContext(WithChat(chat), WithToolManager(tm), WithModel("agent-model")))
+			executor.SetSummaryPolicy(tt.policy)
+
+			result := executor.Run(context.Background(), "1+1")
+			if executor.GetState() != FinishState || executor.GetFinalResult().Answer != "2" {
+				t.Fatalf("state = %s, final result = %+v", executor.GetState(), executor.GetFinalResult())
+			}
+			if !tt.wantSummary {
+				if result != "2" || len(chat.requests) != 2 {
+					t.Fatalf("result = %q after %d model calls, want the final answer without summary", result, len(chat.requests))
+				}
+				return
+			}
+			if result != "the summarized answer" || len(chat.requests) != 3 {
+				t.Fatalf("result = %q after %d model calls, want the summary", result, len(chat.requests))
+			}
+			// 总结请求包含执行过程与最终答案
+			req := chat.requests[2].Request()
+			if !strings.Contains(lastUserContent(chat.requests[2]), "最终答案: 2") || len(req.Tools) != 0 {
+				t.Fatalf("summary request = %+v", req)
+			}
+			if tt.wantModel != "" && req.Model != tt.wantModel {
+				t.Fatalf("summary model = %q, want %q", req.Model, tt.wantModel)
+			}
+			if tt.wantPrompt != "" && req.Messages[0].Content != tt.wantPrompt {
+				t.Fatalf("summary prompt = %q, want %q", req.Messages[0].Content, tt.wantPrompt)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"jas-agent/agent/core"
+	"jas-agent/agent/tools"
+)
+
+// WorkerToolPrefix 工作 Agent 对应工具名的前缀，工具名为前缀加工作 Agent 的名称
+const WorkerToolPrefix = "agent_"
+
+// SupervisorAgent 调度者 Agent：每个工作 Agent 以工具形式提供给模型，
+// 由模型按 ReAct 循环把子任务分派给工作 Agent，收集回答后综合为最终答案
+type SupervisorAgent struct {
+	*BaseReact
+	systemPrompt string
+	mu           sync.Mutex
+	runs         []*SubRunTrace
+}
+
+func (agent *SupervisorAgent) Type() AgentType {
+	return SupervisorAgentType
+}
+
+// Step 执行一轮思考与调度，结束时最终结果附带各工作 Agent 的运行记录
+func (agent *SupervisorAgent) Step(ctx context.Context) string {
+	result := agent.BaseReact.Step(ctx)
+	if finalResult := agent.executor.GetFinalResult(); finalResult != nil {
+		finalResult.SubRuns = agent.subRuns()
+	}
+	return result
+}
+
+// subRuns 按调度顺序收集工作 Agent 的运行记录
+func (agent *SupervisorAgent) subRuns() []SubRunTrace {
+	agent.mu.Lock()
+	defer agent.mu.Unlock()
+	traces := make([]SubRunTrace, 0, len(agent.runs))
+	for _, trace := range agent.runs {
+		traces = append(traces, *trace)
+	}
+	return traces
+}
+
+// delegate 在独立的子上下文中运行工作 Agent 完成子任务，工作 Agent 使用全局工具与自身配置的工具，
+// 不继承调度者的工具
+func (agent *SupervisorAgent) delegate(ctx context.Context, worker SubAgent, task string) (string, error) {
+	trace := &SubRunTrace{StepID: agent.executor.GetCurrentStep(), Agent: worker.Name, Input: task}
+	agent.mu.Lock()
+	agent.runs = append(agent.runs, trace)
+	source := fmt.Sprintf("%s#%d", WorkerToolPrefix+worker.Name, len(agent.runs))
+	agent.mu.Unlock()
+	toolManager := tools.NewToolManager()
+	toolManager.Inherit(tools.GetToolManager())
+	return runAgent(ctx, agent.context, trace, source, toolManager, 0)
+}
+
+// workerTool 以工具形式提供给模型的工作 Agent
+type workerTool struct {
+	supervisor *SupervisorAgent
+	worker     SubAgent
+}
+
+func (t *workerTool) Name() string {
+	return WorkerToolPrefix + t.worker.Name
+}
+
+func (t *workerTool) Description() string {
+	return t.worker.Description
+}
+
+func (t *workerTool) Input() any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"task": map[string]any{
+				"type":        "string",
+				"description": "交给该Agent的子任务，需包含完成任务所需的全部信息",
+			},
+		},
+		"required": []string{"task"},
+	}
+}
+
+func (t *workerTool) Type() core.ToolType {
+	return core.Normal
+}
+
+// Handler 运行工作 Agent，输入不是 {"task": "..."} 形式时整体作为子任务
+func (t *workerTool) Handler(ctx context.Context, input string) (string, error) {
+	task := strings.TrimSpace(input)
+	var args struct {
+		Task string `json:"task"`
+	}
+	if err := json.Unmarshal([]byte(task), &args); err == nil && args.Task != "" {
+		task = args.Task
+	}
+	if task == "" {
+		return "", fmt.Errorf("agent %s: task is empty", t.worker.Name)
+	}
+	return t.supervisor.delegate(ctx, t.worker, task)
+}
+
+// NewSupervisorAgent 创建调度者 Agent，工作 Agent 来自 WithSubAgents 提供的子 Agent 列表，
+// 以工具形式注册到上下文的工具管理器
+func NewSupervisorAgent(context *Context, executor *AgentExecutor) Agent {
+	agent := &SupervisorAgent{
+		BaseReact: NewBaseReact(context, executor),
+	}
+	workers := make([]core.ToolData, 0, len(context.subAgents))
+	for _, worker := range context.subAgents {
+		tool := &workerTool{supervisor: agent, worker: worker}
+		context.toolManager.RegisterTool(tool)
+		workers = append(workers, core.ToolData{
+			Name:        tool.Name(),
+			Description: tool.Description(),
+		})
+	}
+	agent.systemPrompt = core.GetSupervisorSystemPrompt(core.SupervisorSystemPrompt{
+		Date:    time.Now().Format("2006-01-02 15:04:05"),
+		Workers: workers,
+	})
+	context.memory.AddMessage(core.Message{
+		Role:    core.MessageRoleSystem,
+		Content: agent.systemPrompt,
+	})
+	return agent
+}
+
+// NewSupervisorAgentExecutor 创建调度者 Agent 执行器
+func NewSupervisorAgentExecutor(context *Context) *AgentExecutor {
+	executor := &AgentExecutor{
+		context:     context,
+		maxSteps:    15,
+		currentStep: 0,
+		state:       IdleState,
+	}
+	executor.agent = NewSupervisorAgent(context, executor)
+	executor.summaryAgent = NewSummaryAgent(context, executor)
+	return executor
+}
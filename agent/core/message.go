@@ -1,11 +1,38 @@
 package core
 
 type Message struct {
-	Role       RoleType `json:"role"`
-	Content    string   `json:"content,omitempty"`
-	Name       string   `json:"name,omitempty"`
-	ToolCallID string   `json:"tool_call_id,omitempty"`
-	ToolCall   []Tool   `json:"tool_call"`
+	Role       RoleType   `json:"role"`
+	Content    string     `json:"content,omitempty"`
+	Name       string     `json:"name,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	// Kind 标记仅用于推送给调用方的特殊消息（如流式总结），不发送给模型
+	Kind MessageKind `json:"-"`
+	// Source 推送消息的嵌套运行来源（如子 Agent 的运行路径），主运行为空，不发送给模型
+	Source string `json:"-"`
+	// Depth 推送消息所在运行的嵌套层数，主运行为 0
+	Depth int `json:"-"`
+}
+
+// MessageKind 推送消息的类别，空值表示普通的执行过程消息
+type MessageKind string
+
+const (
+	// MessageKindSummary 流式输出的总结片段
+	MessageKindSummary MessageKind = "summary"
+	// MessageKindDelta 模型输出的增量片段（token 级流式输出）
+	MessageKindDelta MessageKind = "delta"
+	// MessageKindApproval 工具调用等待人工审批，内容为审批请求的 JSON
+	MessageKindApproval MessageKind = "approval"
+	// MessageKindPlanStep 计划步骤的状态变化，内容为步骤事件的 JSON
+	MessageKindPlanStep MessageKind = "plan_step"
+)
+
+// ToolCall 助手消息中的结构化工具调用（function calling）
+type ToolCall struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
 }
 
 type RoleType string
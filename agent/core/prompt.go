@@ -13,6 +13,7 @@ func init() {
 	initPlanTemplate()
 	initESTemplate()
 	initRootCauseTemplate()
+	initSupervisorTemplate()
 }
 
 type ToolData struct {
@@ -623,3 +624,79 @@ Trace配置: %s
 
 	return result
 }
+
+type SupervisorSystemPrompt struct {
+	Date    string     `json:"date"`
+	Workers []ToolData `json:"workers"`
+}
+
+// initSupervisorTemplate 初始化 Supervisor 模版
+func initSupervisorTemplate() {
+	supervisorTemplate := NewPromptTemplate(
+		"supervisor_system",
+		"Supervisor Agent 系统提示词模版",
+		`你是多个专业Agent的调度者。你不直接查询数据，而是把用户问题拆分为子任务，交给最合适的Agent完成，再综合它们的回答。
+
+当前时间: {{.Date}}
+
+可调度的Agent（以工具形式调用，输入为 {"task": "子任务"}）:
+{{.Workers}}
+
+工作方式:
+	1. 先判断问题需要哪些Agent，每次只调度一个Agent，等待其回答后再决定下一步
+	2. 子任务要完整、独立，包含Agent完成任务所需的全部信息（Agent看不到用户的原始问题和其它Agent的回答）
+	3. 某个Agent的回答不足时，可以补充信息后再次调度它，或改用其它Agent
+	4. 思考格式: Thought: [你的思考过程]
+	5. 行动格式: Action: agent_<ID>[{"task": "子任务"}] 或 Action: Finish[final answer]，工具名使用上面列表中的名称
+	6. 最终答案要综合各Agent的回答，回答之间存在冲突时指出冲突并说明更可信的结论
+
+{{.Examples}}
+
+请开始第一步思考。`,
+	).AddVariable("Date", "当前时间").
+		AddVariable("Workers", "可调度的Agent列表").
+		AddVariable("Examples", "Few-shot 示例").
+		AddExample(
+			"昨天订单服务报错的原因是什么，影响了多少订单？",
+			`Thought: 需要先定位报错原因，再统计受影响的订单，先交给根因分析Agent（agent_3）
+Action: agent_3[{"task": "分析订单服务昨天的报错，给出根本原因和报错时间段"}]`,
+			"多Agent协作任务",
+		)
+
+	RegisterGlobalTemplate(supervisorTemplate)
+}
+
+// GetSupervisorSystemPrompt 生成Supervisor系统提示词
+func GetSupervisorSystemPrompt(prompt SupervisorSystemPrompt) string {
+	var workersDesc strings.Builder
+	for _, worker := range prompt.Workers {
+		workersDesc.WriteString(fmt.Sprintf("- %s: %s\n", worker.Name, worker.Description))
+	}
+
+	data := map[string]interface{}{
+		"Date":    prompt.Date,
+		"Workers": workersDesc.String(),
+	}
+
+	result, err := BuildGlobalPrompt("supervisor_system", data)
+	if err != nil {
+		// 如果模版构建失败，回退到原始实现
+		return fmt.Sprintf(`你是多个专业Agent的调度者。把用户问题拆分为子任务，交给最合适的Agent完成，再综合它们的回答。
+
+当前时间: %s
+
+可调度的Agent（以工具形式调用，输入为 {"task": "子任务"}）:
+%s
+每次只调度一个Agent。思考格式: Thought: [你的思考过程]，行动格式: Action: agent_<ID>[{"task": "子任务"}] 或 Action: Finish[final answer]，工具名使用上面列表中的名称
+
+请开始第一步思考。`, prompt.Date, workersDesc.String())
+	}
+
+	return result
+}
+
+// NativeToolCallPrompt 原生 function calling 模式下追加的系统提示，覆盖文本 ReAct 的行动格式约束
+const NativeToolCallPrompt = `当前使用原生函数调用(function calling)模式：
+	1. 需要使用工具时，直接发起函数调用，不要输出 "Action: toolName[input]" 形式的文本
+	2. 工具结果会以 tool 消息返回，请基于实际结果继续推理
+	3. 不再需要调用工具时，直接回复最终答案，不要再发起函数调用`
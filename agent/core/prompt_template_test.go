@@ -1,6 +1,7 @@
 package core
 
 import (
+	"regexp"
 	"testing"
 )
 
@@ -265,6 +266,23 @@ func TestBuiltinTemplates(t *testing.T) {
 	}
 }
 
+// TestSupervisorTemplate 示例中的工具名与工作 Agent 的工具名一样为 agent_<ID>
+func TestSupervisorTemplate(t *testing.T) {
+	supervisorPrompt := GetSupervisorSystemPrompt(SupervisorSystemPrompt{
+		Date:    "2024-01-01 12:00:00",
+		Workers: []ToolData{{Name: "agent_3", Description: "rca（react）根因分析"}},
+	})
+	actions := regexp.MustCompile(`Action: (\w+)\[\{`).FindAllStringSubmatch(supervisorPrompt, -1)
+	if len(actions) == 0 {
+		t.Fatal("Supervisor 模版缺少行动示例")
+	}
+	for _, action := range actions {
+		if !regexp.MustCompile(`^agent_\d+$`).MatchString(action[1]) {
+			t.Errorf("Supervisor 模版示例的工具名 %s 不是 agent_<ID>", action[1])
+		}
+	}
+}
+
 // 辅助函数
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) &&
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
@@ -33,22 +34,22 @@ func main() {
 	})
 
 	// 创建上下文
-	context := agent.NewContext(
+	agentCtx := agent.NewContext(
 		agent.WithModel(openai.GPT3Dot5Turbo),
 		agent.WithChat(chat),
 	)
 
 	// 示例1: 简单线性链
 	logger.Info("\n=== 示例1: 简单线性链 ===")
-	simpleChainExample(context)
+	simpleChainExample(agentCtx)
 
 	// 示例2: 带转换的链
 	logger.Info("\n=== 示例2: 带转换的链 ===")
-	transformChainExample(context)
+	transformChainExample(agentCtx)
 
 	// 示例3: 条件分支链
 	logger.Info("\n=== 示例3: 条件分支链 ===")
-	conditionalChainExample(context)
+	conditionalChainExample(agentCtx)
 }
 
 // 示例1: 简单线性链 - 查询狗狗体重然后计算总和
@@ -65,7 +66,7 @@ func simpleChainExample(ctx *agent.Context) {
 	chainAgent := builder.Build()
 	executor := agent.NewChainAgentExecutor(ctx, chainAgent)
 
-	result := executor.Run("我有一只边境牧羊犬和一只苏格兰梗，它们的总体重是多少？")
+	result := executor.Run(context.Background(), "我有一只边境牧羊犬和一只苏格兰梗，它们的总体重是多少？")
 	logger.Infof("📊 最终结果: %s", result)
 }
 
@@ -92,7 +93,7 @@ func transformChainExample(ctx *agent.Context) {
 	chainAgent := builder.Build()
 	executor := agent.NewChainAgentExecutor(ctx, chainAgent)
 
-	result := executor.Run("玩具贵宾犬的平均体重是多少？")
+	result := executor.Run(context.Background(), "玩具贵宾犬的平均体重是多少？")
 	logger.Infof("📊 最终结果: %s", result)
 }
 
@@ -128,7 +129,7 @@ func conditionalChainExample(ctx *agent.Context) {
 	chainAgent := builder.Build()
 	executor := agent.NewChainAgentExecutor(ctx, chainAgent)
 
-	result := executor.Run("玩具贵宾犬的平均体重是多少？")
+	result := executor.Run(context.Background(), "玩具贵宾犬的平均体重是多少？")
 	logger.Infof("📊 最终结果: %s", result)
 }
 
@@ -163,6 +164,6 @@ func dataProcessingChainExample(ctx *agent.Context) {
 	chainAgent := builder.Build()
 	executor := agent.NewChainAgentExecutor(ctx, chainAgent)
 
-	result := executor.Run("收集边境牧羊犬、苏格兰梗、玩具贵宾犬的体重数据并进行分析")
+	result := executor.Run(context.Background(), "收集边境牧羊犬、苏格兰梗、玩具贵宾犬的体重数据并进行分析")
 	logger.Infof("📊 最终结果: %s", result)
 }
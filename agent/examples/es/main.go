@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings
@@ -48,13 +49,13 @@ func main() {
 	})
 
 	// 创建Agent上下文
-	context := agent.NewContext(
+	agentCtx := agent.NewContext(
 		agent.WithChat(chat),
 	)
 
 	// 创建ES Agent执行器
 	clusterInfo := fmt.Sprintf("Elasticsearch cluster at %s", esHost)
-	executor := agent.NewESAgentExecutor(context, clusterInfo)
+	executor := agent.NewESAgentExecutor(agentCtx, clusterInfo)
 
 	// 示例查询
 	queries := []string{
@@ -68,7 +69,7 @@ func main() {
 		logger.Infof("\n\n🔍 查询 %d: %s", i+1, query)
 		logger.Info(strings.Repeat("-", 60))
 
-		result := executor.Run(query)
+		result := executor.Run(context.Background(), query)
 		logger.Infof("\n✅ 结果:\n%s", result)
 	}
 
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
 
@@ -30,22 +31,22 @@ func main() {
 	})
 
 	// 创建上下文
-	context := agent.NewContext(
+	agentCtx := agent.NewContext(
 		agent.WithModel(openai.GPT3Dot5Turbo),
 		agent.WithChat(chat),
 	)
 
 	// 示例1: 基本计划执行
 	logger.Info("\n=== 示例1: 基本计划执行 ===")
-	basicPlanExample(context)
+	basicPlanExample(agentCtx)
 
 	// 示例2: 带依赖的复杂计划
 	logger.Info("\n=== 示例2: 带依赖的复杂计划 ===")
-	complexPlanExample(context)
+	complexPlanExample(agentCtx)
 
 	// 示例3: 启用重新规划
 	logger.Info("\n=== 示例3: 启用重新规划 ===")
-	replanExample(context)
+	replanExample(agentCtx)
 }
 
 // 示例1: 基本计划执行 - 简单的多步骤任务
@@ -53,7 +54,7 @@ func basicPlanExample(ctx *agent.Context) {
 	// 创建Plan Agent执行器（不启用重新规划）
 	executor := agent.NewPlanAgentExecutor(ctx, false)
 
-	result := executor.Run("计算15 + 27的结果，然后乘以3")
+	result := executor.Run(context.Background(), "计算15 + 27的结果，然后乘以3")
 	logger.Infof("📊 最终结果:\n%s", result)
 }
 
@@ -62,7 +63,7 @@ func complexPlanExample(ctx *agent.Context) {
 	// 创建Plan Agent执行器（不启用重新规划）
 	executor := agent.NewPlanAgentExecutor(ctx, false)
 
-	result := executor.Run("我有3只狗，分别是border collie、scottish terrier和toy poodle。请查询它们的平均体重，然后计算总重量")
+	result := executor.Run(context.Background(), "我有3只狗，分别是border collie、scottish terrier和toy poodle。请查询它们的平均体重，然后计算总重量")
 	logger.Infof("📊 最终结果:\n%s", result)
 }
 
@@ -71,7 +72,7 @@ func replanExample(ctx *agent.Context) {
 	// 创建Plan Agent执行器（启用重新规划）
 	executor := agent.NewPlanAgentExecutor(ctx, true)
 
-	result := executor.Run("查询拉布拉多和金毛的体重差异，并计算平均值")
+	result := executor.Run(context.Background(), "查询拉布拉多和金毛的体重差异，并计算平均值")
 	logger.Infof("📊 最终结果:\n%s", result)
 }
 
@@ -79,7 +80,7 @@ func replanExample(ctx *agent.Context) {
 func mathPlanExample(ctx *agent.Context) {
 	executor := agent.NewPlanAgentExecutor(ctx, false)
 
-	result := executor.Run("计算(15 + 27) * 3 - 10，并说明计算过程")
+	result := executor.Run(context.Background(), "计算(15 + 27) * 3 - 10，并说明计算过程")
 	logger.Infof("📊 最终结果:\n%s", result)
 }
 
@@ -87,6 +88,6 @@ func mathPlanExample(ctx *agent.Context) {
 func analysisPlanExample(ctx *agent.Context) {
 	executor := agent.NewPlanAgentExecutor(ctx, false)
 
-	result := executor.Run("收集边境牧羊犬、德国牧羊犬、澳大利亚牧羊犬的体重信息，找出最重的品种")
+	result := executor.Run(context.Background(), "收集边境牧羊犬、德国牧羊犬、澳大利亚牧羊犬的体重信息，找出最重的品种")
 	logger.Infof("📊 最终结果:\n%s", result)
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
 
@@ -25,10 +26,10 @@ func main() {
 		ApiKey:  apiKey,
 		BaseURL: baseUrl,
 	})
-	context := agent.NewContext(agent.WithModel(openai.GPT3Dot5Turbo), agent.WithChat(chat))
-	executor := agent.NewAgentExecutor(context)
+	agentCtx := agent.NewContext(agent.WithModel(openai.GPT3Dot5Turbo), agent.WithChat(chat))
+	executor := agent.NewAgentExecutor(agentCtx)
 	logger.Info("Running agent with query...")
-	result := executor.Run("我有3只狗,分别是border collie ,scottish terrier和toy poodle.请问这些的体重总和")
+	result := executor.Run(context.Background(), "我有3只狗,分别是border collie ,scottish terrier和toy poodle.请问这些的体重总和")
 
 	logger.Infof("Final result: %s", result)
 }
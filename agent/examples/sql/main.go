@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"flag"
 	"fmt"
@@ -56,28 +57,28 @@ func main() {
 	})
 
 	// 创建上下文
-	context := agent.NewContext(
+	agentCtx := agent.NewContext(
 		agent.WithModel(openai.GPT3Dot5Turbo),
 		agent.WithChat(chat),
 	)
 
 	// 创建执行器（使用 SQL Agent）
-	executor := agent.NewSQLAgentExecutor(context, fmt.Sprintf("MySQL Database: %s", dsn))
+	executor := agent.NewSQLAgentExecutor(agentCtx, fmt.Sprintf("MySQL Database: %s", dsn))
 
 	// 示例查询
 	logger.Info("\n=== Example 1: List all tables ===")
-	result := executor.Run("查询：列出数据库中的所有表")
+	result := executor.Run(context.Background(), "查询：列出数据库中的所有表")
 	logger.Infof("Result: %s", result)
 	logger.Info("")
 
 	// 示例查询 2
 	logger.Info("=== Example 2: Query user count ===")
-	result = executor.Run("查询：查询用户表有多少条记录")
+	result = executor.Run(context.Background(), "查询：查询用户表有多少条记录")
 	logger.Infof("Result: %s", result)
 	logger.Info("")
 
 	// 示例查询 3
 	logger.Info("=== Example 3: Complex query ===")
-	result = executor.Run("查询：查询每个用户的订单总金额，按金额降序排列,请以表格的形式展示")
+	result = executor.Run(context.Background(), "查询：查询每个用户的订单总金额，按金额降序排列,请以表格的形式展示")
 	logger.Infof("Result: %s", result)
 }
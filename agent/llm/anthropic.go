@@ -0,0 +1,350 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"jas-agent/agent/core"
+	"jas-agent/agent/tools"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+const (
+	anthropicDefaultBaseURL   = "https://api.anthropic.com"
+	anthropicVersion          = "2023-06-01"
+	anthropicDefaultMaxTokens = 4096
+)
+
+// APIError 非 OpenAI SDK 实现的服务商返回的 HTTP 错误
+type APIError struct {
+	StatusCode int
+	Header     http.Header
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("llm api error, status code: %d, message: %s", e.StatusCode, e.Message)
+}
+
+// anthropicChat Anthropic Messages API 协议的 Chat 实现，
+// 请求与响应在内部与 OpenAI 结构互相转换，对上层保持一致
+type anthropicChat struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// NewAnthropicChat 创建 Anthropic 协议的 Chat
+func NewAnthropicChat(chatConfig *Config) Chat {
+	baseURL := strings.TrimSuffix(chatConfig.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = anthropicDefaultBaseURL
+	}
+	return &anthropicChat{
+		apiKey:  chatConfig.ApiKey,
+		baseURL: baseURL,
+		client:  newHTTPClient(10 * time.Minute),
+	}
+}
+
+type anthropicRequest struct {
+	Model     string             `json:"model"`
+	MaxTokens int                `json:"max_tokens"`
+	System    string             `json:"system,omitempty"`
+	Messages  []anthropicMessage `json:"messages"`
+	Tools     []anthropicTool    `json:"tools,omitempty"`
+	Stream    bool               `json:"stream,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string           `json:"role"`
+	Content []anthropicBlock `json:"content"`
+}
+
+type anthropicBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   string          `json:"content,omitempty"`
+}
+
+type anthropicTool struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	InputSchema any    `json:"input_schema"`
+}
+
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+type anthropicResponse struct {
+	ID         string           `json:"id"`
+	Model      string           `json:"model"`
+	Content    []anthropicBlock `json:"content"`
+	StopReason string           `json:"stop_reason"`
+	Usage      anthropicUsage   `json:"usage"`
+}
+
+type anthropicStreamEvent struct {
+	Type         string             `json:"type"`
+	Index        int                `json:"index"`
+	Message      *anthropicResponse `json:"message,omitempty"`
+	ContentBlock *anthropicBlock    `json:"content_block,omitempty"`
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage *anthropicUsage `json:"usage,omitempty"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+func (chat *anthropicChat) Completions(ctx context.Context, chatReq ChatRequest) (*ChatResponse, error) {
+	resp, err := chat.do(ctx, chatReq, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result anthropicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode anthropic response: %w", err)
+	}
+	acc := newStreamAccumulator()
+	acc.resp.ID = result.ID
+	acc.resp.Model = result.Model
+	toolIndex := 0
+	for _, block := range result.Content {
+		switch block.Type {
+		case "text":
+			acc.content.WriteString(block.Text)
+		case "tool_use":
+			acc.add(anthropicToolCallChunk(toolIndex, block.ID, block.Name, string(block.Input)))
+			toolIndex++
+		}
+	}
+	acc.finishReason = anthropicFinishReason(result.StopReason)
+	acc.resp.Usage = result.Usage.toOpenAI()
+	return acc.response(), nil
+}
+
+func (chat *anthropicChat) CompletionsStream(ctx context.Context, chatReq ChatRequest, onDelta func(ChatDelta) error) (*ChatResponse, error) {
+	resp, err := chat.do(ctx, chatReq, true)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	acc := newStreamAccumulator()
+	var usage anthropicUsage
+	// 内容块序号包含文本块，需映射为工具调用序号
+	toolIndexes := map[int]int{}
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		var event anthropicStreamEvent
+		if err := json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(line, "data:"))), &event); err != nil {
+			return nil, fmt.Errorf("decode anthropic stream event: %w", err)
+		}
+		var chunk *openai.ChatCompletionStreamResponse
+		switch event.Type {
+		case "message_start":
+			if event.Message != nil {
+				acc.resp.ID = event.Message.ID
+				acc.resp.Model = event.Message.Model
+				usage.InputTokens = event.Message.Usage.InputTokens
+			}
+		case "content_block_start":
+			if event.ContentBlock != nil && event.ContentBlock.Type == "tool_use" {
+				toolIndexes[event.Index] = len(toolIndexes)
+				c := anthropicToolCallChunk(toolIndexes[event.Index], event.ContentBlock.ID, event.ContentBlock.Name, "")
+				chunk = &c
+			}
+		case "content_block_delta":
+			switch event.Delta.Type {
+			case "text_delta":
+				c := openai.ChatCompletionStreamResponse{Choices: []openai.ChatCompletionStreamChoice{{
+					Delta: openai.ChatCompletionStreamChoiceDelta{Content: event.Delta.Text},
+				}}}
+				chunk = &c
+			case "input_json_delta":
+				c := anthropicToolCallChunk(toolIndexes[event.Index], "", "", event.Delta.PartialJSON)
+				chunk = &c
+			}
+		case "message_delta":
+			acc.finishReason = anthropicFinishReason(event.Delta.StopReason)
+			if event.Usage != nil {
+				usage.OutputTokens = event.Usage.OutputTokens
+			}
+		case "error":
+			if event.Error != nil {
+				return nil, fmt.Errorf("anthropic stream error: %s: %s", event.Error.Type, event.Error.Message)
+			}
+		}
+		if chunk == nil {
+			continue
+		}
+		// 流式片段中不携带 ID 等元数据，避免覆盖 message_start 中的值
+		chunk.ID, chunk.Model = acc.resp.ID, acc.resp.Model
+		delta, ok := acc.add(*chunk)
+		if !ok {
+			continue
+		}
+		if err := onDelta(delta); err != nil {
+			return nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	acc.resp.Usage = usage.toOpenAI()
+	return acc.response(), nil
+}
+
+// do 发送请求，非 2xx 响应转换为 APIError
+func (chat *anthropicChat) do(ctx context.Context, chatReq ChatRequest, stream bool) (*http.Response, error) {
+	body, err := json.Marshal(anthropicRequestFrom(chatReq, stream))
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, chat.baseURL+"/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", chat.apiKey)
+	req.Header.Set("anthropic-version", anthropicVersion)
+	resp, err := chat.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, &APIError{StatusCode: resp.StatusCode, Header: resp.Header, Message: string(data)}
+	}
+	return resp, nil
+}
+
+// anthropicRequestFrom 将请求转换为 Messages API 格式：
+// 系统消息合并到 system，tool 消息转换为 tool_result，相邻同角色消息合并
+func anthropicRequestFrom(chatReq ChatRequest, stream bool) anthropicRequest {
+	req := anthropicRequest{
+		Model:     chatReq.model,
+		MaxTokens: anthropicDefaultMaxTokens,
+		Stream:    stream,
+	}
+	var system []string
+	for _, message := range chatReq.messages {
+		role := "user"
+		var blocks []anthropicBlock
+		switch message.Role {
+		case core.MessageRoleSystem, core.MessageRoleDeveloper:
+			system = append(system, message.Content)
+			continue
+		case core.MessageRoleTool, core.MessageRoleFunction:
+			blocks = append(blocks, anthropicBlock{
+				Type:      "tool_result",
+				ToolUseID: message.ToolCallID,
+				Content:   message.Content,
+			})
+		case core.MessageRoleAssistant:
+			role = "assistant"
+			if message.Content != "" {
+				blocks = append(blocks, anthropicBlock{Type: "text", Text: message.Content})
+			}
+			for _, toolCall := range message.ToolCalls {
+				blocks = append(blocks, anthropicBlock{
+					Type:  "tool_use",
+					ID:    toolCall.ID,
+					Name:  FunctionName(toolCall.Name),
+					Input: anthropicToolInput(toolCall.Arguments),
+				})
+			}
+		default:
+			if message.Content != "" {
+				blocks = append(blocks, anthropicBlock{Type: "text", Text: message.Content})
+			}
+		}
+		if len(blocks) == 0 {
+			continue
+		}
+		if n := len(req.Messages); n > 0 && req.Messages[n-1].Role == role {
+			req.Messages[n-1].Content = append(req.Messages[n-1].Content, blocks...)
+			continue
+		}
+		req.Messages = append(req.Messages, anthropicMessage{Role: role, Content: blocks})
+	}
+	req.System = strings.Join(system, "\n\n")
+	for _, tool := range chatReq.tools {
+		req.Tools = append(req.Tools, anthropicTool{
+			Name:        FunctionName(tool.Name()),
+			Description: tool.Description(),
+			InputSchema: tools.ParametersSchema(tool),
+		})
+	}
+	return req
+}
+
+// anthropicToolInput tool_use 的 input 必须是 JSON 对象，非对象参数包装为 input 字段
+func anthropicToolInput(arguments string) json.RawMessage {
+	var object map[string]any
+	if err := json.Unmarshal([]byte(arguments), &object); err == nil && object != nil {
+		return json.RawMessage(arguments)
+	}
+	data, _ := json.Marshal(map[string]string{tools.InputArgument: arguments})
+	return data
+}
+
+// anthropicToolCallChunk 将 tool_use 片段转换为 OpenAI 流式片段，以复用累积逻辑
+func anthropicToolCallChunk(index int, id, name, arguments string) openai.ChatCompletionStreamResponse {
+	return openai.ChatCompletionStreamResponse{Choices: []openai.ChatCompletionStreamChoice{{
+		Delta: openai.ChatCompletionStreamChoiceDelta{ToolCalls: []openai.ToolCall{{
+			Index:    &index,
+			ID:       id,
+			Type:     openai.ToolTypeFunction,
+			Function: openai.FunctionCall{Name: name, Arguments: arguments},
+		}}},
+	}}}
+}
+
+func anthropicFinishReason(stopReason string) openai.FinishReason {
+	switch stopReason {
+	case "tool_use":
+		return openai.FinishReasonToolCalls
+	case "max_tokens":
+		return openai.FinishReasonLength
+	case "":
+		return ""
+	default:
+		return openai.FinishReasonStop
+	}
+}
+
+func (u anthropicUsage) toOpenAI() openai.Usage {
+	return openai.Usage{
+		PromptTokens:     u.InputTokens,
+		CompletionTokens: u.OutputTokens,
+		TotalTokens:      u.InputTokens + u.OutputTokens,
+	}
+}
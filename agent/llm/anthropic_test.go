@@ -0,0 +1,33 @@
+package llm
+
+import (
+	"jas-agent/agent/core"
+	"testing"
+)
+
+func TestAnthropicRequestFrom(t *testing.T) {
+	messages := []core.Message{
+		{Role: core.MessageRoleSystem, Content: "system"},
+		{Role: core.MessageRoleUser, Content: "查询两个指标"},
+		{Role: core.MessageRoleAssistant, ToolCalls: []core.ToolCall{
+			{ID: "a", Name: "svc@metric", Arguments: `{"name":"cpu"}`},
+			{ID: "b", Name: "calculator", Arguments: `1+1`},
+		}},
+		{Role: core.MessageRoleTool, Content: "80%", ToolCallID: "a"},
+		{Role: core.MessageRoleTool, Content: "2", ToolCallID: "b"},
+	}
+	req := anthropicRequestFrom(NewChatRequest("claude-sonnet-4-5", messages), false)
+
+	if req.System != "system" || len(req.Messages) != 3 {
+		t.Fatalf("unexpected request: %+v", req)
+	}
+	toolUse := req.Messages[1].Content
+	if toolUse[0].Name != "svc__metric" || string(toolUse[1].Input) != `{"input":"1+1"}` {
+		t.Fatalf("unexpected tool_use blocks: %+v", toolUse)
+	}
+	// 相邻的 tool 结果合并到同一条 user 消息
+	results := req.Messages[2]
+	if results.Role != "user" || len(results.Content) != 2 || results.Content[1].ToolUseID != "b" {
+		t.Fatalf("unexpected tool_result message: %+v", results)
+	}
+}
@@ -2,21 +2,55 @@ package llm
 
 import (
 	"context"
+	"errors"
+	"io"
+	"strings"
 
 	"github.com/sashabaranov/go-openai"
 )
 
 type Chat interface {
 	Completions(ctx context.Context, chatReq ChatRequest) (*ChatResponse, error)
+	// CompletionsStream 以流式方式调用模型，逐段回调增量内容（onDelta 返回错误时中止读取），
+	// 结束后返回与 Completions 相同结构的完整响应
+	CompletionsStream(ctx context.Context, chatReq ChatRequest, onDelta func(ChatDelta) error) (*ChatResponse, error)
 }
 
+// ChatDelta 流式响应中的一段增量内容
+type ChatDelta struct {
+	Content   string
+	ToolCalls []ToolCallDelta
+}
+
+// ToolCallDelta 工具调用的增量，同一调用的多个片段 Index 相同，
+// ID 与 Name 通常只在第一个片段中出现，Arguments 需按顺序拼接
+type ToolCallDelta struct {
+	Index     int
+	ID        string
+	Name      string
+	Arguments string
+}
+
+// 结构化输出方式，即请求要求按 JSON Schema 输出时使用的 response_format
+const (
+	// StructuredOutputJSONSchema 使用 json_schema，模型按 schema 输出
+	StructuredOutputJSONSchema = "json_schema"
+	// StructuredOutputJSONObject 使用 json_object，只保证输出合法 JSON
+	StructuredOutputJSONObject = "json_object"
+	// StructuredOutputNone 不设置 response_format，只由提示词约束
+	StructuredOutputNone = "none"
+)
+
 type openaiChat struct {
-	client *openai.Client
+	client           *openai.Client
+	structuredOutput string
 }
 
 type Config struct {
 	ApiKey  string
 	BaseURL string
+	// StructuredOutput 结构化输出方式，为空时 OpenAI 官方接口使用 json_schema，其它兼容接口不设置
+	StructuredOutput string
 }
 
 func (config *Config) Enable() bool {
@@ -27,17 +61,148 @@ func NewChat(chatConfig *Config) Chat {
 	if chatConfig.BaseURL != "" {
 		config.BaseURL = chatConfig.BaseURL
 	}
-	return &openaiChat{client: openai.NewClientWithConfig(config)}
+	config.HTTPClient = newHTTPClient(0)
+	structuredOutput := chatConfig.StructuredOutput
+	if structuredOutput == "" {
+		structuredOutput = StructuredOutputNone
+		if strings.HasPrefix(config.BaseURL, "https://api.openai.com/") {
+			structuredOutput = StructuredOutputJSONSchema
+		}
+	}
+	return &openaiChat{client: openai.NewClientWithConfig(config), structuredOutput: structuredOutput}
+}
+
+// ValidStructuredOutput 是否为支持的结构化输出方式，空值表示按接口选择默认方式
+func ValidStructuredOutput(mode string) bool {
+	switch mode {
+	case "", StructuredOutputJSONSchema, StructuredOutputJSONObject, StructuredOutputNone:
+		return true
+	}
+	return false
+}
+
+// request 转换请求，请求要求结构化输出时按服务商支持的方式设置 response_format
+func (chat *openaiChat) request(chatReq ChatRequest) openai.ChatCompletionRequest {
+	request := chatReq.Request()
+	format := chatReq.ResponseFormat()
+	if format == nil {
+		return request
+	}
+	switch chat.structuredOutput {
+	case StructuredOutputJSONSchema:
+		request.ResponseFormat = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+			JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+				Name:   format.Name,
+				Schema: format.Schema,
+			},
+		}
+	case StructuredOutputJSONObject:
+		request.ResponseFormat = &openai.ChatCompletionResponseFormat{Type: openai.ChatCompletionResponseFormatTypeJSONObject}
+	}
+	return request
 }
 
 func (chat *openaiChat) Completions(ctx context.Context, chatReq ChatRequest) (*ChatResponse, error) {
 	// 调用API
-	resp, err := chat.client.CreateChatCompletion(
-		context.WithoutCancel(ctx),
-		chatReq.Request(),
-	)
+	resp, err := chat.client.CreateChatCompletion(ctx, chat.request(chatReq))
 	if err != nil {
 		return nil, err
 	}
 	return &ChatResponse{resp}, nil
 }
+
+func (chat *openaiChat) CompletionsStream(ctx context.Context, chatReq ChatRequest, onDelta func(ChatDelta) error) (*ChatResponse, error) {
+	chatReq.stream = true
+	stream, err := chat.client.CreateChatCompletionStream(ctx, chat.request(chatReq))
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	acc := newStreamAccumulator()
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		delta, ok := acc.add(chunk)
+		if !ok {
+			continue
+		}
+		if err := onDelta(delta); err != nil {
+			return nil, err
+		}
+	}
+	return acc.response(), nil
+}
+
+// streamAccumulator 将流式片段累积为完整响应
+type streamAccumulator struct {
+	resp         openai.ChatCompletionResponse
+	content      strings.Builder
+	toolCalls    []openai.ToolCall
+	finishReason openai.FinishReason
+}
+
+func newStreamAccumulator() *streamAccumulator {
+	return &streamAccumulator{}
+}
+
+// add 累积一个片段，返回其中的增量内容；片段不含内容时返回 false
+func (acc *streamAccumulator) add(chunk openai.ChatCompletionStreamResponse) (ChatDelta, bool) {
+	acc.resp.ID = chunk.ID
+	acc.resp.Model = chunk.Model
+	acc.resp.Created = chunk.Created
+	if chunk.Usage != nil {
+		acc.resp.Usage = *chunk.Usage
+	}
+	if len(chunk.Choices) == 0 {
+		return ChatDelta{}, false
+	}
+	choice := chunk.Choices[0]
+	if choice.FinishReason != "" {
+		acc.finishReason = choice.FinishReason
+	}
+	delta := ChatDelta{Content: choice.Delta.Content}
+	acc.content.WriteString(choice.Delta.Content)
+	for i, toolCall := range choice.Delta.ToolCalls {
+		index := i
+		if toolCall.Index != nil {
+			index = *toolCall.Index
+		}
+		for len(acc.toolCalls) <= index {
+			acc.toolCalls = append(acc.toolCalls, openai.ToolCall{Type: openai.ToolTypeFunction})
+		}
+		current := &acc.toolCalls[index]
+		if toolCall.ID != "" {
+			current.ID = toolCall.ID
+		}
+		current.Function.Name += toolCall.Function.Name
+		current.Function.Arguments += toolCall.Function.Arguments
+		delta.ToolCalls = append(delta.ToolCalls, ToolCallDelta{
+			Index:     index,
+			ID:        toolCall.ID,
+			Name:      toolCall.Function.Name,
+			Arguments: toolCall.Function.Arguments,
+		})
+	}
+	return delta, delta.Content != "" || len(delta.ToolCalls) > 0
+}
+
+// response 返回累积后的完整响应
+func (acc *streamAccumulator) response() *ChatResponse {
+	resp := acc.resp
+	resp.Choices = []openai.ChatCompletionChoice{{
+		Message: openai.ChatCompletionMessage{
+			Role:      openai.ChatMessageRoleAssistant,
+			Content:   acc.content.String(),
+			ToolCalls: acc.toolCalls,
+		},
+		FinishReason: acc.finishReason,
+	}}
+	return &ChatResponse{resp}
+}
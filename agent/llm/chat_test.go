@@ -0,0 +1,69 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+func TestStreamAccumulator(t *testing.T) {
+	index := 0
+	chunks := []openai.ChatCompletionStreamResponse{
+		{Choices: []openai.ChatCompletionStreamChoice{{Delta: openai.ChatCompletionStreamChoiceDelta{Content: "正在"}}}},
+		{Choices: []openai.ChatCompletionStreamChoice{{Delta: openai.ChatCompletionStreamChoiceDelta{Content: "查询"}}}},
+		{Choices: []openai.ChatCompletionStreamChoice{{Delta: openai.ChatCompletionStreamChoiceDelta{
+			ToolCalls: []openai.ToolCall{{Index: &index, ID: "call_1", Function: openai.FunctionCall{Name: "search", Arguments: `{"q":`}}},
+		}}}},
+		{Choices: []openai.ChatCompletionStreamChoice{{Delta: openai.ChatCompletionStreamChoiceDelta{
+			ToolCalls: []openai.ToolCall{{Index: &index, Function: openai.FunctionCall{Arguments: `"go"}`}}},
+		}}}},
+		{Choices: []openai.ChatCompletionStreamChoice{{FinishReason: openai.FinishReasonToolCalls}}},
+	}
+	acc := newStreamAccumulator()
+	var deltas int
+	for _, chunk := range chunks {
+		if _, ok := acc.add(chunk); ok {
+			deltas++
+		}
+	}
+	if deltas != 4 {
+		t.Fatalf("deltas = %d, want 4", deltas)
+	}
+	resp := acc.response()
+	if resp.Content() != "正在查询" {
+		t.Fatalf("content = %q", resp.Content())
+	}
+	calls := resp.GetToolCalls()
+	if len(calls) != 1 || calls[0].ID != "call_1" || calls[0].Name != "search" || calls[0].Input != `{"q":"go"}` {
+		t.Fatalf("unexpected tool calls: %+v", calls[0])
+	}
+	if resp.Choices[0].FinishReason != openai.FinishReasonToolCalls {
+		t.Fatalf("finish reason = %q", resp.Choices[0].FinishReason)
+	}
+}
+
+func TestStructuredOutputRequest(t *testing.T) {
+	req := NewChatRequest("gpt-4o", nil).WithResponseFormat("plan", []byte(`{"type":"object"}`))
+	official := NewChat(&Config{ApiKey: "sk"}).(*openaiChat)
+	format := official.request(req).ResponseFormat
+	if format == nil || format.Type != openai.ChatCompletionResponseFormatTypeJSONSchema || format.JSONSchema.Name != "plan" {
+		t.Fatalf("official api format = %+v", format)
+	}
+	compatible := NewChat(&Config{ApiKey: "sk", BaseURL: "http://localhost:8000/v1"}).(*openaiChat)
+	if format := compatible.request(req).ResponseFormat; format != nil {
+		t.Fatalf("compatible api format = %+v, want none", format)
+	}
+	ollama, err := NewProviderChat(ProviderConfig{Name: "local", Protocol: ProtocolOllama})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format := ollama.(*openaiChat).request(req).ResponseFormat; format == nil || format.Type != openai.ChatCompletionResponseFormatTypeJSONObject {
+		t.Fatalf("ollama format = %+v", format)
+	}
+	if format := official.request(NewChatRequest("gpt-4o", nil)).ResponseFormat; format != nil {
+		t.Fatalf("plain request format = %+v", format)
+	}
+	if _, err := NewProviderChat(ProviderConfig{Name: "x", StructuredOutput: "xml"}); err == nil {
+		t.Fatal("expected unsupported structured output error")
+	}
+}
@@ -0,0 +1,397 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/aegis/circuitbreaker"
+	"github.com/go-kratos/aegis/circuitbreaker/sre"
+	"github.com/sashabaranov/go-openai"
+)
+
+// Middleware Chat 中间件
+type Middleware func(Chat) Chat
+
+// Chain 按顺序组合中间件，第一个中间件位于最外层
+func Chain(chat Chat, middlewares ...Middleware) Chat {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		chat = middlewares[i](chat)
+	}
+	return chat
+}
+
+// ProviderResolver 可以根据模型名解析服务商名称的 Chat（如 Registry）
+type ProviderResolver interface {
+	ProviderOf(model string) string
+}
+
+// chatFunc 由函数构成的 Chat，便于实现中间件
+type chatFunc struct {
+	completions func(ctx context.Context, chatReq ChatRequest) (*ChatResponse, error)
+	stream      func(ctx context.Context, chatReq ChatRequest, onDelta func(ChatDelta) error) (*ChatResponse, error)
+}
+
+func (c chatFunc) Completions(ctx context.Context, chatReq ChatRequest) (*ChatResponse, error) {
+	return c.completions(ctx, chatReq)
+}
+
+func (c chatFunc) CompletionsStream(ctx context.Context, chatReq ChatRequest, onDelta func(ChatDelta) error) (*ChatResponse, error) {
+	return c.stream(ctx, chatReq, onDelta)
+}
+
+// IsRetryable 判断错误是否可以重试：限流、超时、服务端错误和网络错误
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if code := statusCode(err); code != 0 {
+		return code == http.StatusTooManyRequests || code == http.StatusRequestTimeout || code >= 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+func statusCode(err error) int {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.HTTPStatusCode
+	}
+	var httpErr *APIError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode
+	}
+	return 0
+}
+
+// RetryConfig 重试配置
+type RetryConfig struct {
+	MaxAttempts int           // 最大尝试次数（含首次），小于 2 时不重试
+	BaseDelay   time.Duration // 首次重试的基础等待时间，默认 500ms
+	MaxDelay    time.Duration // 单次等待上限，默认 20s
+}
+
+// Retry 对可重试错误进行指数退避重试（full jitter），服务端返回 Retry-After 时至少等待该时长。
+// 流式调用只在尚未输出任何片段时重试，避免重复推送
+func Retry(config RetryConfig) Middleware {
+	if config.BaseDelay <= 0 {
+		config.BaseDelay = 500 * time.Millisecond
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = 20 * time.Second
+	}
+	return func(next Chat) Chat {
+		return chatFunc{
+			completions: func(ctx context.Context, chatReq ChatRequest) (*ChatResponse, error) {
+				var resp *ChatResponse
+				err := retry(ctx, config, func(ctx context.Context) (bool, error) {
+					var err error
+					resp, err = next.Completions(ctx, chatReq)
+					return IsRetryable(err), err
+				})
+				return resp, err
+			},
+			stream: func(ctx context.Context, chatReq ChatRequest, onDelta func(ChatDelta) error) (*ChatResponse, error) {
+				var resp *ChatResponse
+				err := retry(ctx, config, func(ctx context.Context) (bool, error) {
+					started := false
+					var err error
+					resp, err = next.CompletionsStream(ctx, chatReq, func(delta ChatDelta) error {
+						started = true
+						return onDelta(delta)
+					})
+					return !started && IsRetryable(err), err
+				})
+				return resp, err
+			},
+		}
+	}
+}
+
+func retry(ctx context.Context, config RetryConfig, call func(ctx context.Context) (bool, error)) error {
+	for attempt := 1; ; attempt++ {
+		holder := &retryAfterHolder{}
+		retryable, err := call(context.WithValue(ctx, retryAfterKey{}, holder))
+		if err == nil || !retryable || attempt >= config.MaxAttempts {
+			return err
+		}
+		delay := backoff(config, attempt)
+		if retryAfter := holder.get(); retryAfter > delay {
+			delay = retryAfter
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff 第 attempt 次失败后的等待时间：[0, min(MaxDelay, BaseDelay*2^(attempt-1))) 内随机
+func backoff(config RetryConfig, attempt int) time.Duration {
+	ceiling := config.BaseDelay << (attempt - 1)
+	if ceiling <= 0 || ceiling > config.MaxDelay {
+		ceiling = config.MaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(ceiling))) + time.Millisecond
+}
+
+type retryAfterKey struct{}
+
+// retryAfterHolder 记录单次请求响应中的 Retry-After，由 retryAfterTransport 写入
+type retryAfterHolder struct {
+	mu    sync.Mutex
+	delay time.Duration
+}
+
+func (h *retryAfterHolder) set(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.delay = d
+}
+
+func (h *retryAfterHolder) get() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.delay
+}
+
+// retryAfterTransport 从 429/503 响应中读取 Retry-After，供 Retry 中间件使用。
+// OpenAI SDK 返回的错误不包含响应头，因此在传输层获取
+type retryAfterTransport struct {
+	base http.RoundTripper
+}
+
+func newHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: retryAfterTransport{base: http.DefaultTransport},
+	}
+}
+
+func (t retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return resp, nil
+	}
+	if holder, ok := req.Context().Value(retryAfterKey{}).(*retryAfterHolder); ok {
+		if d := parseRetryAfter(resp.Header.Get("Retry-After")); d > 0 {
+			holder.set(d)
+		}
+	}
+	return resp, nil
+}
+
+// parseRetryAfter 解析 Retry-After（秒数或 HTTP 日期）
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// BreakerGroup 按服务商维护的熔断器，在多个 Agent 间共享
+type BreakerGroup struct {
+	mu       sync.Mutex
+	breakers map[string]circuitbreaker.CircuitBreaker
+	opts     []sre.Option
+}
+
+// NewBreakerGroup 创建熔断器组
+func NewBreakerGroup(opts ...sre.Option) *BreakerGroup {
+	return &BreakerGroup{
+		breakers: map[string]circuitbreaker.CircuitBreaker{},
+		opts:     opts,
+	}
+}
+
+// Get 获取服务商对应的熔断器
+func (g *BreakerGroup) Get(provider string) circuitbreaker.CircuitBreaker {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	breaker, ok := g.breakers[provider]
+	if !ok {
+		breaker = sre.NewBreaker(g.opts...)
+		g.breakers[provider] = breaker
+	}
+	return breaker
+}
+
+// CircuitBreaker 按服务商熔断：服务商持续出现可重试错误时快速失败，
+// 返回 circuitbreaker.ErrNotAllowed。next 实现 ProviderResolver 时按服务商区分，否则共用一个熔断器
+func CircuitBreaker(group *BreakerGroup) Middleware {
+	return func(next Chat) Chat {
+		providerOf := func(model string) string { return "" }
+		if resolver, ok := next.(ProviderResolver); ok {
+			providerOf = resolver.ProviderOf
+		}
+		guard := func(chatReq ChatRequest, call func() error) error {
+			breaker := group.Get(providerOf(chatReq.model))
+			if err := breaker.Allow(); err != nil {
+				return err
+			}
+			err := call()
+			if errors.Is(err, context.Canceled) {
+				// 调用方取消不代表服务商异常
+				return err
+			}
+			if IsRetryable(err) {
+				breaker.MarkFailed()
+			} else {
+				breaker.MarkSuccess()
+			}
+			return err
+		}
+		return chatFunc{
+			completions: func(ctx context.Context, chatReq ChatRequest) (*ChatResponse, error) {
+				var resp *ChatResponse
+				err := guard(chatReq, func() error {
+					var err error
+					resp, err = next.Completions(ctx, chatReq)
+					return err
+				})
+				return resp, err
+			},
+			stream: func(ctx context.Context, chatReq ChatRequest, onDelta func(ChatDelta) error) (*ChatResponse, error) {
+				var resp *ChatResponse
+				err := guard(chatReq, func() error {
+					var err error
+					resp, err = next.CompletionsStream(ctx, chatReq, onDelta)
+					return err
+				})
+				return resp, err
+			},
+		}
+	}
+}
+
+// Fallback 主模型调用失败（可重试错误或熔断）时改用备用模型
+func Fallback(model string) Middleware {
+	shouldFallback := func(err error) bool {
+		return IsRetryable(err) || errors.Is(err, circuitbreaker.ErrNotAllowed)
+	}
+	return func(next Chat) Chat {
+		return chatFunc{
+			completions: func(ctx context.Context, chatReq ChatRequest) (*ChatResponse, error) {
+				resp, err := next.Completions(ctx, chatReq)
+				if err == nil || !shouldFallback(err) || chatReq.model == model {
+					return resp, err
+				}
+				chatReq.model = model
+				return next.Completions(ctx, chatReq)
+			},
+			stream: func(ctx context.Context, chatReq ChatRequest, onDelta func(ChatDelta) error) (*ChatResponse, error) {
+				started := false
+				resp, err := next.CompletionsStream(ctx, chatReq, func(delta ChatDelta) error {
+					started = true
+					return onDelta(delta)
+				})
+				if err == nil || started || !shouldFallback(err) || chatReq.model == model {
+					return resp, err
+				}
+				chatReq.model = model
+				return next.CompletionsStream(ctx, chatReq, onDelta)
+			},
+		}
+	}
+}
+
+// TokenBucket 客户端令牌桶限流器
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // 每秒补充的令牌数
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket 创建令牌桶，rate 不大于 0 时不限流，burst 小于 1 时按 1 处理
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait 阻塞直到获取一个令牌或 ctx 结束
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	for {
+		delay := b.reserve()
+		if delay == 0 {
+			return nil
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve 尝试取出一个令牌，失败时返回需要等待的时间
+func (b *TokenBucket) reserve() time.Duration {
+	if b.rate <= 0 {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// RateLimit 每次调用模型前从令牌桶获取令牌
+func RateLimit(bucket *TokenBucket) Middleware {
+	return func(next Chat) Chat {
+		return chatFunc{
+			completions: func(ctx context.Context, chatReq ChatRequest) (*ChatResponse, error) {
+				if err := bucket.Wait(ctx); err != nil {
+					return nil, err
+				}
+				return next.Completions(ctx, chatReq)
+			},
+			stream: func(ctx context.Context, chatReq ChatRequest, onDelta func(ChatDelta) error) (*ChatResponse, error) {
+				if err := bucket.Wait(ctx); err != nil {
+					return nil, err
+				}
+				return next.CompletionsStream(ctx, chatReq, onDelta)
+			},
+		}
+	}
+}
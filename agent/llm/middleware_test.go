@@ -0,0 +1,56 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type flakyChat struct {
+	failures int
+	models   []string
+}
+
+func (c *flakyChat) Completions(ctx context.Context, chatReq ChatRequest) (*ChatResponse, error) {
+	c.models = append(c.models, chatReq.model)
+	if c.failures > 0 {
+		c.failures--
+		return nil, &APIError{StatusCode: http.StatusTooManyRequests}
+	}
+	return &ChatResponse{}, nil
+}
+
+func (c *flakyChat) CompletionsStream(ctx context.Context, chatReq ChatRequest, onDelta func(ChatDelta) error) (*ChatResponse, error) {
+	return c.Completions(ctx, chatReq)
+}
+
+func TestRetryAndFallback(t *testing.T) {
+	retry := Retry(RetryConfig{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+
+	inner := &flakyChat{failures: 1}
+	if _, err := Chain(inner, retry).Completions(context.Background(), NewChatRequest("m", nil)); err != nil {
+		t.Fatalf("retry should recover: %v", err)
+	}
+
+	// 主模型重试耗尽后改用备用模型
+	inner = &flakyChat{failures: 2}
+	if _, err := Chain(inner, Fallback("backup"), retry).Completions(context.Background(), NewChatRequest("m", nil)); err != nil {
+		t.Fatalf("fallback should recover: %v", err)
+	}
+	if want := []string{"m", "m", "backup"}; len(inner.models) != 3 || inner.models[2] != want[2] {
+		t.Fatalf("models = %v, want %v", inner.models, want)
+	}
+
+	// 客户端错误不重试
+	if IsRetryable(&APIError{StatusCode: http.StatusBadRequest}) || !IsRetryable(errors.Join(&APIError{StatusCode: 502})) {
+		t.Fatal("unexpected retryable classification")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if d := parseRetryAfter("3"); d != 3*time.Second {
+		t.Fatalf("parseRetryAfter = %v", d)
+	}
+}
@@ -0,0 +1,143 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// 服务商协议
+const (
+	ProtocolOpenAI    = "openai"
+	ProtocolAnthropic = "anthropic"
+	ProtocolOllama    = "ollama"
+)
+
+const ollamaDefaultBaseURL = "http://localhost:11434/v1"
+
+// ProviderConfig 模型服务商配置
+type ProviderConfig struct {
+	Name     string
+	Protocol string // openai（默认）、anthropic、ollama
+	ApiKey   string
+	BaseURL  string
+	// StructuredOutput 结构化输出方式：json_schema、json_object 或 none，
+	// 为空时 OpenAI 官方接口为 json_schema，ollama 为 json_object，其它为 none（anthropic 协议不支持，始终由提示词约束）
+	StructuredOutput string
+}
+
+// NewProviderChat 按协议创建服务商的 Chat 实现
+func NewProviderChat(provider ProviderConfig) (Chat, error) {
+	if !ValidStructuredOutput(provider.StructuredOutput) {
+		return nil, fmt.Errorf("provider %s: unsupported structured output %q", provider.Name, provider.StructuredOutput)
+	}
+	config := &Config{ApiKey: provider.ApiKey, BaseURL: provider.BaseURL, StructuredOutput: provider.StructuredOutput}
+	switch strings.ToLower(provider.Protocol) {
+	case "", ProtocolOpenAI:
+		return NewChat(config), nil
+	case ProtocolAnthropic:
+		return NewAnthropicChat(config), nil
+	case ProtocolOllama:
+		// Ollama 提供 OpenAI 兼容接口，API Key 不做校验
+		if config.BaseURL == "" {
+			config.BaseURL = ollamaDefaultBaseURL
+		}
+		if config.ApiKey == "" {
+			config.ApiKey = ProtocolOllama
+		}
+		if config.StructuredOutput == "" {
+			config.StructuredOutput = StructuredOutputJSONObject
+		}
+		return NewChat(config), nil
+	default:
+		return nil, fmt.Errorf("provider %s: unsupported protocol %q", provider.Name, provider.Protocol)
+	}
+}
+
+// Registry 多服务商路由，本身也是 Chat：
+// 模型名形如 "provider/model" 时路由到对应服务商并去掉前缀，否则使用默认服务商；
+// 模型名为空时使用默认模型
+type Registry struct {
+	mu              sync.RWMutex
+	providers       map[string]Chat
+	defaultProvider string
+	defaultModel    string
+}
+
+// NewRegistry 创建服务商注册表
+func NewRegistry(defaultProvider, defaultModel string) *Registry {
+	return &Registry{
+		providers:       map[string]Chat{},
+		defaultProvider: defaultProvider,
+		defaultModel:    defaultModel,
+	}
+}
+
+// Register 注册服务商，第一个注册的服务商在未指定默认服务商时作为默认服务商
+func (r *Registry) Register(name string, chat Chat) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[name] = chat
+	if r.defaultProvider == "" {
+		r.defaultProvider = name
+	}
+}
+
+// Providers 返回已注册的服务商名称
+func (r *Registry) Providers() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Resolve 解析模型名，返回服务商名称、服务商的 Chat 与去掉前缀后的模型名。
+// 只有前缀是已注册的服务商时才视为 "provider/model"，以兼容 "meta-llama/Llama-3" 这类模型名
+func (r *Registry) Resolve(model string) (string, Chat, string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if model == "" {
+		model = r.defaultModel
+	}
+	if name, rest, ok := strings.Cut(model, "/"); ok {
+		if chat, ok := r.providers[name]; ok {
+			return name, chat, rest, nil
+		}
+	}
+	chat, ok := r.providers[r.defaultProvider]
+	if !ok {
+		return "", nil, "", fmt.Errorf("no llm provider for model %q", model)
+	}
+	return r.defaultProvider, chat, model, nil
+}
+
+// ProviderOf 返回模型名对应的服务商名称
+func (r *Registry) ProviderOf(model string) string {
+	name, _, _, err := r.Resolve(model)
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
+func (r *Registry) Completions(ctx context.Context, chatReq ChatRequest) (*ChatResponse, error) {
+	_, chat, model, err := r.Resolve(chatReq.model)
+	if err != nil {
+		return nil, err
+	}
+	chatReq.model = model
+	return chat.Completions(ctx, chatReq)
+}
+
+func (r *Registry) CompletionsStream(ctx context.Context, chatReq ChatRequest, onDelta func(ChatDelta) error) (*ChatResponse, error) {
+	_, chat, model, err := r.Resolve(chatReq.model)
+	if err != nil {
+		return nil, err
+	}
+	chatReq.model = model
+	return chat.CompletionsStream(ctx, chatReq, onDelta)
+}
@@ -0,0 +1,38 @@
+package llm
+
+import (
+	"context"
+	"testing"
+)
+
+type recordChat struct {
+	models []string
+}
+
+func (c *recordChat) Completions(ctx context.Context, chatReq ChatRequest) (*ChatResponse, error) {
+	c.models = append(c.models, chatReq.model)
+	return &ChatResponse{}, nil
+}
+
+func (c *recordChat) CompletionsStream(ctx context.Context, chatReq ChatRequest, onDelta func(ChatDelta) error) (*ChatResponse, error) {
+	return c.Completions(ctx, chatReq)
+}
+
+func TestRegistryRouting(t *testing.T) {
+	defaultChat, claude := &recordChat{}, &recordChat{}
+	registry := NewRegistry("", "gpt-4o-mini")
+	registry.Register("default", defaultChat)
+	registry.Register("claude", claude)
+
+	for _, model := range []string{"claude/claude-sonnet-4-5", "meta-llama/Llama-3", ""} {
+		if _, err := registry.Completions(context.Background(), NewChatRequest(model, nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(claude.models) != 1 || claude.models[0] != "claude-sonnet-4-5" {
+		t.Fatalf("claude models = %v", claude.models)
+	}
+	if len(defaultChat.models) != 2 || defaultChat.models[0] != "meta-llama/Llama-3" || defaultChat.models[1] != "gpt-4o-mini" {
+		t.Fatalf("default models = %v", defaultChat.models)
+	}
+}
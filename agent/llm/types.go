@@ -1,6 +1,7 @@
 package llm
 
 import (
+	"encoding/json"
 	"jas-agent/agent/core"
 	"jas-agent/agent/tools"
 	"regexp"
@@ -10,10 +11,17 @@ import (
 )
 
 type ChatRequest struct {
-	model    string
-	messages []core.Message
-	stream   bool
-	tools    []core.Tool
+	model          string
+	messages       []core.Message
+	stream         bool
+	tools          []core.Tool
+	responseFormat *ResponseFormat
+}
+
+// ResponseFormat 要求模型按 JSON Schema 输出，服务商不支持结构化输出时由提示词约束
+type ResponseFormat struct {
+	Name   string
+	Schema json.RawMessage
 }
 
 func NewChatRequest(model string, messages []core.Message, tools ...core.Tool) ChatRequest {
@@ -24,33 +32,84 @@ func NewChatRequest(model string, messages []core.Message, tools ...core.Tool) C
 		tools:    tools,
 	}
 }
+
+// WithResponseFormat 返回要求按 schema 输出 JSON 的请求
+func (req ChatRequest) WithResponseFormat(name string, schema json.RawMessage) ChatRequest {
+	req.responseFormat = &ResponseFormat{Name: name, Schema: schema}
+	return req
+}
+
+// ResponseFormat 请求要求的输出格式，未要求时返回 nil
+func (req ChatRequest) ResponseFormat() *ResponseFormat {
+	return req.responseFormat
+}
+
 func (req ChatRequest) Request() openai.ChatCompletionRequest {
 	var messages []openai.ChatCompletionMessage
 	for _, message := range req.messages {
-		messages = append(messages, openai.ChatCompletionMessage{
-			Role:    string(message.Role),
-			Content: message.Content,
-			Name:    message.Name,
-		})
+		msg := openai.ChatCompletionMessage{
+			Role:       string(message.Role),
+			Content:    message.Content,
+			ToolCallID: message.ToolCallID,
+		}
+		// tool 消息不支持 name 字段，工具名称只在本地记录
+		if message.Role != core.MessageRoleTool {
+			msg.Name = message.Name
+		}
+		for _, toolCall := range message.ToolCalls {
+			msg.ToolCalls = append(msg.ToolCalls, openai.ToolCall{
+				ID:   toolCall.ID,
+				Type: openai.ToolTypeFunction,
+				Function: openai.FunctionCall{
+					Name:      FunctionName(toolCall.Name),
+					Arguments: toolCall.Arguments,
+				},
+			})
+		}
+		messages = append(messages, msg)
 	}
-	var tools []openai.Tool
+	var ts []openai.Tool
 	for _, tool := range req.tools {
-		tools = append(tools, openai.Tool{
+		ts = append(ts, openai.Tool{
 			Type: openai.ToolTypeFunction,
 			Function: &openai.FunctionDefinition{
-				Name:        tool.Name(),
+				Name:        FunctionName(tool.Name()),
 				Description: tool.Description(),
 				Strict:      false,
-				Parameters:  tool.Input(),
+				Parameters:  tools.ParametersSchema(tool),
 			},
 		})
 	}
-	return openai.ChatCompletionRequest{
+	request := openai.ChatCompletionRequest{
 		Model:    req.model,
 		Messages: messages,
-		Tools:    tools,
+		Tools:    ts,
 		Stream:   req.stream,
 	}
+	// 流式响应默认不返回用量，需显式要求在最后一个片段中携带
+	if req.stream {
+		request.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+	}
+	return request
+}
+
+// ResolveToolName 将模型返回的函数名还原为请求中声明的工具名称
+func (req ChatRequest) ResolveToolName(functionName string) string {
+	for _, tool := range req.tools {
+		if FunctionName(tool.Name()) == functionName {
+			return tool.Name()
+		}
+	}
+	return functionName
+}
+
+var invalidFunctionNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// FunctionName 将工具名称转换为合法的函数名（仅允许字母、数字、下划线和中划线），
+// MCP 工具的 service@tool 会被转换为 service__tool
+func FunctionName(toolName string) string {
+	name := strings.ReplaceAll(toolName, tools.MCP_SEP, "__")
+	return invalidFunctionNameChars.ReplaceAllString(name, "_")
 }
 
 type ChatResponse struct {
@@ -75,6 +134,7 @@ func (resp ChatResponse) GetToolCalls() []*tools.ToolCall {
 	var callsTools []*tools.ToolCall
 	for _, callTool := range resp.CallTools() {
 		callsTools = append(callsTools, &tools.ToolCall{
+			ID:    callTool.ID,
 			Name:  callTool.Function.Name,
 			Input: callTool.Function.Arguments,
 		})
@@ -135,6 +195,38 @@ func parseToolCall(content string) []*tools.ToolCall {
 	return toolCalls
 }
 
+var (
+	finishPattern      = regexp.MustCompile(`(?i)Action:\s*Finish\b`)
+	finalAnswerPattern = regexp.MustCompile(`(?im)^\s*Final Answer:`)
+)
+
+// ParseFinish 解析文本 ReAct 模式下的结束指令 "Action: Finish[answer]"。
+// 答案按括号嵌套匹配提取，允许答案中包含 "]"；括号不完整时取到最后一个 "]" 为止。
+// 兼容模型以单独一行 "Final Answer: ..." 给出答案的写法。
+func ParseFinish(content string) (string, bool) {
+	loc := finishPattern.FindStringIndex(content)
+	if loc == nil {
+		if loc = finalAnswerPattern.FindStringIndex(content); loc != nil {
+			return strings.TrimSpace(content[loc[1]:]), true
+		}
+		return "", false
+	}
+	remaining := content[loc[1]:]
+	trimmed := strings.TrimLeft(remaining, " \t\n\r")
+	if !strings.HasPrefix(trimmed, "[") {
+		return strings.TrimSpace(trimmed), true
+	}
+	bracketStart := loc[1] + (len(remaining) - len(trimmed))
+	if answer, found := extractBracketContent(content, bracketStart); found {
+		return answer, true
+	}
+	answer := content[bracketStart+1:]
+	if end := strings.LastIndex(answer, "]"); end >= 0 {
+		answer = answer[:end]
+	}
+	return strings.TrimSpace(answer), true
+}
+
 // extractBracketContent 提取括号内的内容（处理嵌套，支持复杂JSON）
 func extractBracketContent(content string, startPos int) (string, bool) {
 	if startPos >= len(content) || content[startPos] != '[' {
@@ -3,6 +3,7 @@ package llm
 import (
 	"context"
 	"encoding/json"
+	"jas-agent/agent/core"
 	"regexp"
 	"testing"
 
@@ -132,3 +133,44 @@ func TestCallTools(tb *testing.T) {
 	tb.Logf("OpenAI answered the original request with: %v",
 		msg.Content)
 }
+
+func TestChatRequestToolMessages(t *testing.T) {
+	messages := []core.Message{
+		{Role: core.MessageRoleUser, Content: "1+1"},
+		{Role: core.MessageRoleAssistant, ToolCalls: []core.ToolCall{
+			{ID: "call_1", Name: "svc@calc", Arguments: `{"expression":"1+1"}`},
+		}},
+		{Role: core.MessageRoleTool, Content: "2", Name: "svc@calc", ToolCallID: "call_1"},
+	}
+	req := NewChatRequest("gpt-4o", messages)
+	got := req.Request().Messages
+
+	if len(got[1].ToolCalls) != 1 || got[1].ToolCalls[0].ID != "call_1" ||
+		got[1].ToolCalls[0].Function.Name != "svc__calc" {
+		t.Fatalf("unexpected assistant tool calls: %+v", got[1].ToolCalls)
+	}
+	if got[2].ToolCallID != "call_1" || got[2].Name != "" {
+		t.Fatalf("unexpected tool message: %+v", got[2])
+	}
+}
+
+func TestParseFinish(t *testing.T) {
+	cases := []struct {
+		content string
+		answer  string
+		ok      bool
+	}{
+		{`Thought: 完成
+Action: Finish[数组为 [1, 2, 3]]`, "数组为 [1, 2, 3]", true},
+		{`Action: finish[{"answer": "42", "confidence": 0.9}]`, `{"answer": "42", "confidence": 0.9}`, true},
+		{"Thought: 已得到结果\nFinal Answer: 126", "126", true},
+		{"Action: calculator[1+1]", "", false},
+		{"Thought: 观察中提到 final answer 但任务未完成\nAction: search[x]", "", false},
+	}
+	for _, c := range cases {
+		answer, ok := ParseFinish(c.content)
+		if ok != c.ok || answer != c.answer {
+			t.Errorf("ParseFinish(%q) = %q, %v; want %q, %v", c.content, answer, ok, c.answer, c.ok)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// ErrBudgetExceeded 累计用量已超出预算，后续模型调用被拒绝
+var ErrBudgetExceeded = errors.New("llm budget exceeded")
+
+// Usage 模型调用的 token 用量与费用
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+	Cost             float64
+}
+
+// TotalTokens 总 token 数
+func (u Usage) TotalTokens() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// Add 累加用量
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		PromptTokens:     u.PromptTokens + other.PromptTokens,
+		CompletionTokens: u.CompletionTokens + other.CompletionTokens,
+		Cost:             u.Cost + other.Cost,
+	}
+}
+
+// ModelPrice 模型单价，单位为每百万 token 的费用
+type ModelPrice struct {
+	Prompt     float64
+	Completion float64
+}
+
+// Pricing 模型名到单价的映射，先按完整模型名（如 "claude/claude-sonnet-4-5"）匹配，
+// 找不到时去掉服务商前缀再匹配；未配置单价的模型费用为 0
+type Pricing map[string]ModelPrice
+
+// Cost 计算一次调用的费用
+func (p Pricing) Cost(model string, promptTokens, completionTokens int) float64 {
+	price, ok := p[model]
+	if !ok {
+		if _, name, cut := strings.Cut(model, "/"); cut {
+			price, ok = p[name]
+		}
+	}
+	if !ok {
+		return 0
+	}
+	return (float64(promptTokens)*price.Prompt + float64(completionTokens)*price.Completion) / 1e6
+}
+
+// UsageObserver 接收每次模型调用的用量，model 为请求中的模型名（请求未指定时为响应中的模型名）
+type UsageObserver func(ctx context.Context, model string, usage Usage)
+
+// ObserveUsage 在每次调用成功后按单价计算费用并通知观察者，
+// 应位于重试与降级之内，使每次实际发出的调用（包括备用模型）都被统计
+func ObserveUsage(pricing Pricing, observers ...UsageObserver) Middleware {
+	return func(next Chat) Chat {
+		observe := func(ctx context.Context, model string, resp *ChatResponse) {
+			usage := Usage{
+				PromptTokens:     resp.Usage.PromptTokens,
+				CompletionTokens: resp.Usage.CompletionTokens,
+			}
+			if usage.TotalTokens() == 0 {
+				return
+			}
+			if model == "" {
+				model = resp.Model
+			}
+			usage.Cost = pricing.Cost(model, usage.PromptTokens, usage.CompletionTokens)
+			for _, observer := range observers {
+				observer(ctx, model, usage)
+			}
+		}
+		return chatFunc{
+			completions: func(ctx context.Context, chatReq ChatRequest) (*ChatResponse, error) {
+				resp, err := next.Completions(ctx, chatReq)
+				if err == nil {
+					observe(ctx, chatReq.model, resp)
+				}
+				return resp, err
+			},
+			stream: func(ctx context.Context, chatReq ChatRequest, onDelta func(ChatDelta) error) (*ChatResponse, error) {
+				resp, err := next.CompletionsStream(ctx, chatReq, onDelta)
+				if err == nil {
+					observe(ctx, chatReq.model, resp)
+				}
+				return resp, err
+			},
+		}
+	}
+}
+
+// Budget 用量预算，0 表示不限制
+type Budget struct {
+	MaxTokens int
+	MaxCost   float64
+}
+
+// Limit 运行之外的用量限制，如租户的每日预算
+type Limit interface {
+	// Exceeded 限制是否已达到
+	Exceeded() bool
+}
+
+// UsageMeter 累计一次运行中所有模型调用的用量
+type UsageMeter struct {
+	mu     sync.Mutex
+	budget Budget
+	limits []Limit
+	total  Usage
+}
+
+// NewUsageMeter 创建用量计量器，limits 中任一限制达到时同样视为超出预算
+func NewUsageMeter(budget Budget, limits ...Limit) *UsageMeter {
+	return &UsageMeter{budget: budget, limits: limits}
+}
+
+// Observe 记录一次调用的用量，可作为 UsageObserver 使用
+func (m *UsageMeter) Observe(_ context.Context, _ string, usage Usage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.total = m.total.Add(usage)
+}
+
+// Total 返回累计用量
+func (m *UsageMeter) Total() Usage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.total
+}
+
+// Exceeded 累计用量是否已达到预算，或任一外部限制已达到
+func (m *UsageMeter) Exceeded() bool {
+	m.mu.Lock()
+	exceeded := (m.budget.MaxTokens > 0 && m.total.TotalTokens() >= m.budget.MaxTokens) ||
+		(m.budget.MaxCost > 0 && m.total.Cost >= m.budget.MaxCost)
+	m.mu.Unlock()
+	if exceeded {
+		return true
+	}
+	for _, limit := range m.limits {
+		if limit.Exceeded() {
+			return true
+		}
+	}
+	return false
+}
+
+// EnforceBudget 累计用量达到预算后拒绝后续调用并返回 ErrBudgetExceeded。
+// 预算在调用前检查，已发出的调用不会被中断，因此实际用量可能略超预算
+func EnforceBudget(meter *UsageMeter) Middleware {
+	return func(next Chat) Chat {
+		return chatFunc{
+			completions: func(ctx context.Context, chatReq ChatRequest) (*ChatResponse, error) {
+				if meter.Exceeded() {
+					return nil, ErrBudgetExceeded
+				}
+				return next.Completions(ctx, chatReq)
+			},
+			stream: func(ctx context.Context, chatReq ChatRequest, onDelta func(ChatDelta) error) (*ChatResponse, error) {
+				if meter.Exceeded() {
+					return nil, ErrBudgetExceeded
+				}
+				return next.CompletionsStream(ctx, chatReq, onDelta)
+			},
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+type usageChat struct {
+	calls int
+}
+
+func (c *usageChat) Completions(ctx context.Context, chatReq ChatRequest) (*ChatResponse, error) {
+	c.calls++
+	return &ChatResponse{openai.ChatCompletionResponse{
+		Usage: openai.Usage{PromptTokens: 600, CompletionTokens: 400},
+	}}, nil
+}
+
+func (c *usageChat) CompletionsStream(ctx context.Context, chatReq ChatRequest, onDelta func(ChatDelta) error) (*ChatResponse, error) {
+	return c.Completions(ctx, chatReq)
+}
+
+func TestUsageBudget(t *testing.T) {
+	pricing := Pricing{"gpt-4o": {Prompt: 2, Completion: 8}}
+	meter := NewUsageMeter(Budget{MaxTokens: 1500})
+	inner := &usageChat{}
+	chat := Chain(inner, EnforceBudget(meter), ObserveUsage(pricing, meter.Observe))
+
+	for i := 0; i < 2; i++ {
+		if _, err := chat.Completions(context.Background(), NewChatRequest("openai/gpt-4o", nil)); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+	// 超出预算后拒绝调用，且不再请求服务商
+	if _, err := chat.Completions(context.Background(), NewChatRequest("openai/gpt-4o", nil)); !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("err = %v, want ErrBudgetExceeded", err)
+	}
+	if inner.calls != 2 {
+		t.Fatalf("calls = %d, want 2", inner.calls)
+	}
+	usage := meter.Total()
+	if usage.TotalTokens() != 2000 || usage.Cost != 2*(600*2+400*8)/1e6 {
+		t.Fatalf("usage = %+v", usage)
+	}
+}
+
+type exceededLimit bool
+
+func (l *exceededLimit) Exceeded() bool { return bool(*l) }
+
+func TestUsageMeterLimit(t *testing.T) {
+	limit := exceededLimit(false)
+	meter := NewUsageMeter(Budget{}, &limit)
+	inner := &usageChat{}
+	chat := Chain(inner, EnforceBudget(meter))
+
+	if _, err := chat.Completions(context.Background(), NewChatRequest("gpt-4o", nil)); err != nil {
+		t.Fatalf("call before limit: %v", err)
+	}
+	// 外部限制（如租户预算）达到后，即使运行自身未设预算也拒绝调用
+	limit = true
+	if _, err := chat.Completions(context.Background(), NewChatRequest("gpt-4o", nil)); !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("err = %v, want ErrBudgetExceeded", err)
+	}
+	if inner.calls != 1 {
+		t.Fatalf("calls = %d, want 1", inner.calls)
+	}
+}
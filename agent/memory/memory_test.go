@@ -0,0 +1,223 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"jas-agent/agent/core"
+	"jas-agent/agent/rag/vectordb"
+	"jas-agent/agent/window"
+)
+
+func TestGetLastMessageEmpty(t *testing.T) {
+	if msg := NewMemory().GetLastMessage(); msg.Role != "" || msg.Content != "" {
+		t.Fatalf("GetLastMessage() = %+v, want zero value", msg)
+	}
+}
+
+func TestWindowMemory(t *testing.T) {
+	inner := NewMemory()
+	m := NewWindowMemory(inner, 3)
+	m.AddMessages([]core.Message{
+		{Role: core.MessageRoleSystem, Content: "system prompt"},
+		{Role: core.MessageRoleUser, Content: "question"},
+		{Role: core.MessageRoleAssistant, ToolCalls: []core.ToolCall{{ID: "1", Name: "search"}}},
+		{Role: core.MessageRoleTool, ToolCallID: "1", Content: "result 1"},
+		{Role: core.MessageRoleAssistant, Content: "Action: search[b]"},
+		{Role: core.MessageRoleUser, Content: "Observation: result 2"},
+	})
+	got := m.GetMessages()
+	want := []string{"system prompt", "question", "Action: search[b]", "Observation: result 2"}
+	if len(got) != len(want) {
+		t.Fatalf("GetMessages() = %+v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i].Content != want[i] {
+			t.Fatalf("GetMessages()[%d] = %q, want %q", i, got[i].Content, want[i])
+		}
+	}
+	if len(inner.GetMessages()) != 6 {
+		t.Fatalf("inner memory must keep all messages")
+	}
+}
+
+func TestSummaryBufferMemory(t *testing.T) {
+	var summarized []string
+	summarizer := func(ctx context.Context, previous string, messages []core.Message) (string, error) {
+		for _, msg := range messages {
+			summarized = append(summarized, msg.Content)
+		}
+		return fmt.Sprintf("%s+%d", previous, len(messages)), nil
+	}
+	m := NewSummaryBufferMemory(context.Background(), NewMemory(), summarizer, window.TokenCounter{}, 100)
+	m.AddMessage(core.Message{Role: core.MessageRoleSystem, Content: "system prompt"})
+	for i := 0; i < 3; i++ {
+		m.AddMessage(core.Message{Role: core.MessageRoleUser, Content: fmt.Sprintf("question %d", i)})
+		m.AddMessage(core.Message{Role: core.MessageRoleAssistant, Content: strings.Repeat("a", 200)})
+	}
+	got := m.GetMessages()
+	if got[0].Content != "system prompt" || !strings.HasPrefix(got[1].Content, summaryNotePrefix) {
+		t.Fatalf("summary must follow the system prompt: %+v", got[:2])
+	}
+	if last := got[len(got)-2]; last.Content != "question 2" {
+		t.Fatalf("latest turn must be kept verbatim, got %q", last.Content)
+	}
+	if len(summarized) != 4 {
+		t.Fatalf("summarized %d messages, want 4", len(summarized))
+	}
+	// 再次读取不重复总结
+	m.GetMessages()
+	if len(summarized) != 4 {
+		t.Fatalf("summary must be incremental, summarized %d messages", len(summarized))
+	}
+	m.Clear()
+	if len(m.GetMessages()) != 0 {
+		t.Fatalf("Clear() must reset the memory")
+	}
+}
+
+func TestSummaryBufferMemorySummarizesWithoutLock(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	summarizer := func(ctx context.Context, previous string, messages []core.Message) (string, error) {
+		close(started)
+		<-release
+		return "summary", nil
+	}
+	m := NewSummaryBufferMemory(context.Background(), NewMemory(), summarizer, window.TokenCounter{}, 100)
+	for i := 0; i < 2; i++ {
+		m.AddMessage(core.Message{Role: core.MessageRoleUser, Content: fmt.Sprintf("question %d", i)})
+		m.AddMessage(core.Message{Role: core.MessageRoleAssistant, Content: strings.Repeat("a", 400)})
+	}
+	done := make(chan []core.Message)
+	go func() { done <- m.GetMessages() }()
+	<-started
+
+	// 生成摘要期间清空内存不被阻塞
+	cleared := make(chan struct{})
+	go func() {
+		m.Clear()
+		close(cleared)
+	}()
+	select {
+	case <-cleared:
+	case <-time.After(time.Second):
+		t.Fatal("Clear() blocked while the summary was being generated")
+	}
+	close(release)
+	<-done
+	// 清空前开始生成的摘要被丢弃
+	m.AddMessage(core.Message{Role: core.MessageRoleUser, Content: "new question"})
+	if got := m.GetMessages(); len(got) != 1 || got[0].Content != "new question" {
+		t.Fatalf("stale summary applied after Clear(): %+v", got)
+	}
+}
+
+// keywordEmbedder 按关键词出现次数生成向量，维度依次为 order、refund、shipping 与恒为 1 的公共维度
+type keywordEmbedder struct{}
+
+func (keywordEmbedder) Embed(_ context.Context, text string) ([]float32, error) {
+	vector := []float32{0, 0, 0, 1}
+	for _, word := range strings.Fields(text) {
+		switch word {
+		case "order":
+			vector[0]++
+		case "refund":
+			vector[1]++
+		case "shipping":
+			vector[2]++
+		}
+	}
+	return vector, nil
+}
+
+func (e keywordEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i], _ = e.Embed(ctx, text)
+	}
+	return vectors, nil
+}
+
+func (keywordEmbedder) Dimensions() int { return 4 }
+
+// recalledTexts 返回召回记录的文本，按在系统消息中的顺序
+func recalledTexts(messages []core.Message) []string {
+	var texts []string
+	for _, message := range messages {
+		if message.Role != core.MessageRoleSystem || !strings.HasPrefix(message.Content, recallNotePrefix) {
+			continue
+		}
+		for _, line := range strings.Split(strings.TrimSpace(message.Content), "\n")[1:] {
+			texts = append(texts, line[strings.Index(line, "] ")+2:])
+		}
+	}
+	return texts
+}
+
+// newSeededVectorStore 返回已有此前运行记录的向量存储，记录与问题 "refund order" 的相似度依次约为 1、0.82、0.67、0.58、0.41
+func newSeededVectorStore() vectordb.VectorStore {
+	ctx := context.Background()
+	store := vectordb.NewInMemoryStore(keywordEmbedder{}.Dimensions())
+	previous := NewVectorMemory(ctx, NewMemory(), keywordEmbedder{}, store, VectorMemoryConfig{Namespace: "agent-1"})
+	previous.AddMessages([]core.Message{
+		{Role: core.MessageRoleAssistant, Content: "shipping delay"},
+		{Role: core.MessageRoleAssistant, Content: "refund policy"},
+		{Role: core.MessageRoleAssistant, Content: "weather today"},
+		{Role: core.MessageRoleAssistant, Content: "refund order status"},
+		{Role: core.MessageRoleAssistant, Content: "shipping order"},
+		// 工具调用与观察结果不写入长期记忆
+		{Role: core.MessageRoleAssistant, Content: "refund order lookup", ToolCalls: []core.ToolCall{{ID: "1", Name: "lookup"}}},
+		{Role: core.MessageRoleTool, ToolCallID: "1", Content: "refund order tool result"},
+	})
+	// 其它命名空间的记录不召回
+	other := NewVectorMemory(ctx, NewMemory(), keywordEmbedder{}, store, VectorMemoryConfig{Namespace: "agent-2"})
+	other.AddMessage(core.Message{Role: core.MessageRoleAssistant, Content: "refund order elsewhere"})
+	return store
+}
+
+func TestVectorMemory(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		topK     int
+		minScore float64
+		want     []string
+	}{
+		{name: "default top k", want: []string{"refund order status", "refund policy", "shipping order", "weather today", "shipping delay"}},
+		{name: "top k", topK: 3, want: []string{"refund order status", "refund policy", "shipping order"}},
+		{name: "top k beyond records", topK: 10, want: []string{"refund order status", "refund policy", "shipping order", "weather today", "shipping delay"}},
+		{name: "min score", minScore: 0.6, want: []string{"refund order status", "refund policy", "shipping order"}},
+		{name: "top k and min score", topK: 1, minScore: 0.9, want: []string{"refund order status"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewVectorMemory(ctx, NewMemory(), keywordEmbedder{}, newSeededVectorStore(), VectorMemoryConfig{Namespace: "agent-1", TopK: tt.topK, MinScore: tt.minScore})
+			m.AddMessage(core.Message{Role: core.MessageRoleSystem, Content: "system prompt"})
+			m.AddMessage(core.Message{Role: core.MessageRoleUser, Content: "refund order"})
+
+			got := m.GetMessages()
+			if len(got) != 3 || got[0].Content != "system prompt" || got[2].Content != "refund order" {
+				t.Fatalf("recalled records must follow the system prompt: %+v", got)
+			}
+			if texts := recalledTexts(got); strings.Join(texts, "|") != strings.Join(tt.want, "|") {
+				t.Fatalf("recalled %q, want %q", texts, tt.want)
+			}
+		})
+	}
+
+	// 本次运行写入的记录不召回，下一轮用户输入重新召回
+	m := NewVectorMemory(ctx, NewMemory(), keywordEmbedder{}, newSeededVectorStore(), VectorMemoryConfig{Namespace: "agent-1", TopK: 3})
+	m.AddMessage(core.Message{Role: core.MessageRoleUser, Content: "shipping"})
+	m.AddMessage(core.Message{Role: core.MessageRoleAssistant, Content: "refund order answer"})
+	m.AddMessage(core.Message{Role: core.MessageRoleUser, Content: "refund order"})
+	if texts := recalledTexts(m.GetMessages()); strings.Join(texts, "|") != "refund order status|refund policy" {
+		t.Fatalf("recalled %q, want only records of earlier runs", texts)
+	}
+	m.Clear()
+	if len(m.GetMessages()) != 0 {
+		t.Fatalf("Clear() must drop the recalled records")
+	}
+}
@@ -22,15 +22,16 @@ func (m *simpleMemory) AddMessages(messages []core.Message) {
 		m.AddMessage(message)
 	}
 }
+
+// GetLastMessage 获取最后一条消息，没有消息时返回零值
 func (m *simpleMemory) GetLastMessage() core.Message {
+	if len(m.messages) == 0 {
+		return core.Message{}
+	}
 	return m.messages[len(m.messages)-1]
 }
 func (m *simpleMemory) GetFormatMessage() string {
-	bs := bytes.NewBufferString("")
-	for _, message := range m.messages {
-		bs.WriteString(fmt.Sprintf("role:%s content:%s\n", message.Role, message.Content))
-	}
-	return bs.String()
+	return formatMessages(m.messages)
 }
 func (m *simpleMemory) Clear() {
 	m.messages = nil
@@ -38,3 +39,24 @@ func (m *simpleMemory) Clear() {
 func (m *simpleMemory) GetMessages() []core.Message {
 	return m.messages
 }
+
+// Messages 返回记忆中保存的完整消息。窗口、摘要等记忆的 GetMessages 返回发送给模型的视图，
+// 这里逐层取出底层内存的消息，用于持久化运行状态等需要完整历史的场景
+func Messages(m core.Memory) []core.Message {
+	for {
+		wrapper, ok := m.(interface{ Unwrap() core.Memory })
+		if !ok {
+			return m.GetMessages()
+		}
+		m = wrapper.Unwrap()
+	}
+}
+
+// formatMessages 将消息格式化为文本
+func formatMessages(messages []core.Message) string {
+	bs := bytes.NewBufferString("")
+	for _, message := range messages {
+		bs.WriteString(fmt.Sprintf("role:%s content:%s\n", message.Role, message.Content))
+	}
+	return bs.String()
+}
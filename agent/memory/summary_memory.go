@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"jas-agent/agent/core"
+	"jas-agent/agent/window"
+)
+
+// summaryNotePrefix 历史摘要消息的前缀
+const summaryNotePrefix = "[历史对话摘要]"
+
+// summaryBufferMemory 摘要缓冲内存：最近的对话轮次保持原文，
+// 超出 token 上限后将较早的轮次通过模型压缩为滚动摘要。
+// 只压缩此前的轮次，当前轮次（最新的用户输入及其后的消息）始终保持原文，由上下文窗口管理器处理
+type summaryBufferMemory struct {
+	core.Memory
+	ctx        context.Context
+	counter    window.TokenCounter
+	maxTokens  int
+	summarizer window.Summarizer
+
+	mu         sync.Mutex
+	summary    string
+	summarized int // 已压缩进摘要的消息数（不含开头的系统消息）
+	generation int // 每次清空时递增，用于丢弃清空前开始生成的摘要
+}
+
+// NewSummaryBufferMemory 创建摘要缓冲内存，ctx 为本次运行的上下文，用于调用模型生成摘要；
+// maxTokens 为保持原文的消息 token 上限，按 counter 对应模型的分词方式计算
+func NewSummaryBufferMemory(ctx context.Context, inner core.Memory, summarizer window.Summarizer,
+	counter window.TokenCounter, maxTokens int) core.Memory {
+	return &summaryBufferMemory{
+		Memory:     inner,
+		ctx:        ctx,
+		counter:    counter,
+		maxTokens:  maxTokens,
+		summarizer: summarizer,
+	}
+}
+
+func (m *summaryBufferMemory) GetMessages() []core.Message {
+	messages := m.Memory.GetMessages()
+	system, rest := splitSystem(messages)
+
+	m.mu.Lock()
+	// 底层内存被清空或重置
+	if m.summarized > len(rest) {
+		m.summary, m.summarized = "", 0
+		m.generation++
+	}
+	summary, summarized, generation := m.summary, m.summarized, m.generation
+	m.mu.Unlock()
+
+	// 调用模型生成摘要时不持有锁，避免阻塞并发的读取与清空
+	if cut := m.cutIndex(rest, summarized); cut > summarized {
+		next, err := m.summarizer(m.ctx, summary, rest[summarized:cut])
+		// 摘要失败时保留原文，下次读取时重试
+		if err == nil {
+			summary, summarized = next, cut
+			m.mu.Lock()
+			// 摘要期间内存被清空，或其他读取已推进得更远时丢弃本次结果
+			if m.generation == generation && m.summarized < cut {
+				m.summary, m.summarized = summary, summarized
+			}
+			m.mu.Unlock()
+		}
+	}
+	if summarized == 0 {
+		return messages
+	}
+	result := make([]core.Message, 0, len(system)+1+len(rest)-summarized)
+	result = append(result, system...)
+	result = append(result, core.Message{
+		Role:    core.MessageRoleSystem,
+		Content: summaryNotePrefix + "\n" + summary,
+	})
+	return append(result, rest[summarized:]...)
+}
+
+// cutIndex 选择压缩的截止位置：最早的、其后消息不超过 token 上限的用户输入位置，
+// 都超出时为最新的用户输入位置；没有需要压缩的轮次时返回 0
+func (m *summaryBufferMemory) cutIndex(rest []core.Message, summarized int) int {
+	if m.counter.MessagesTokens(rest[summarized:]) <= m.maxTokens {
+		return 0
+	}
+	cut := 0
+	for i := summarized + 1; i < len(rest); i++ {
+		if !isUserTurn(rest[i]) {
+			continue
+		}
+		cut = i
+		if m.counter.MessagesTokens(rest[i:]) <= m.maxTokens {
+			break
+		}
+	}
+	return cut
+}
+
+// Unwrap 返回底层内存
+func (m *summaryBufferMemory) Unwrap() core.Memory {
+	return m.Memory
+}
+
+func (m *summaryBufferMemory) GetFormatMessage() string {
+	return formatMessages(m.GetMessages())
+}
+
+func (m *summaryBufferMemory) Clear() {
+	m.Memory.Clear()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.summary, m.summarized = "", 0
+	m.generation++
+}
@@ -0,0 +1,196 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"jas-agent/agent/core"
+	"jas-agent/agent/rag/embedding"
+	"jas-agent/agent/rag/loader"
+	"jas-agent/agent/rag/vectordb"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/google/uuid"
+)
+
+var vectorLogger = log.NewHelper(log.With(log.NewStdLogger(os.Stdout), "module", "agent/vector_memory"))
+
+const (
+	// vectorNamespaceKey 向量元数据中区分记忆归属（如 Agent）的字段
+	vectorNamespaceKey = "memory_namespace"
+	// recallNotePrefix 召回的历史记录消息的前缀
+	recallNotePrefix = "[相关历史记录]"
+	// maxRecallRunes 每条召回记录最多保留的字符数
+	maxRecallRunes = 500
+)
+
+// VectorMemoryConfig 长期语义记忆配置
+type VectorMemoryConfig struct {
+	// Namespace 记忆归属，只召回同一命名空间写入的记录，如 "agent-12"
+	Namespace string
+	// TopK 每轮召回的记录数，默认 5
+	TopK int
+	// MinScore 召回的最低相似度，默认 0 表示不过滤
+	MinScore float64
+}
+
+// vectorMemory 长期语义记忆：用户输入和助手回复在写入底层内存的同时向量化存入 VectorStore，
+// 每次收到新的用户输入时召回语义相关的历史记录，作为系统消息放在系统提示词之后
+type vectorMemory struct {
+	core.Memory
+	ctx      context.Context
+	embedder embedding.Embedder
+	store    vectordb.VectorStore
+	config   VectorMemoryConfig
+
+	mu       sync.Mutex
+	written  map[string]bool // 本次写入的记录，召回时排除
+	recalled []vectordb.SearchResult
+}
+
+// NewVectorMemory 创建长期语义记忆，ctx 为本次运行的上下文，用于向量化与检索
+func NewVectorMemory(ctx context.Context, inner core.Memory, embedder embedding.Embedder, store vectordb.VectorStore, config VectorMemoryConfig) core.Memory {
+	if config.TopK <= 0 {
+		config.TopK = 5
+	}
+	return &vectorMemory{
+		Memory:   inner,
+		ctx:      ctx,
+		embedder: embedder,
+		store:    store,
+		config:   config,
+		written:  map[string]bool{},
+	}
+}
+
+func (m *vectorMemory) AddMessage(message core.Message) {
+	m.Memory.AddMessage(message)
+	if !m.shouldRemember(message) {
+		return
+	}
+	vector, err := m.embedder.Embed(m.ctx, message.Content)
+	if err != nil {
+		vectorLogger.Warnf("embed message failed: %v", err)
+		return
+	}
+	// 新的用户输入先召回再写入，避免召回自身
+	if isUserTurn(message) {
+		m.recall(vector)
+	}
+	id := uuid.NewString()
+	metadata := map[string]string{
+		vectorNamespaceKey: m.config.Namespace,
+		"role":             string(message.Role),
+		"created_at":       time.Now().Format(time.RFC3339),
+	}
+	err = m.store.Insert(m.ctx, []vectordb.Vector{{
+		ID:     id,
+		Vector: vector,
+		Document: &loader.Document{
+			ID:       id,
+			Text:     message.Content,
+			Metadata: metadata,
+		},
+		Metadata: metadata,
+	}})
+	if err != nil {
+		vectorLogger.Warnf("store message vector failed: %v", err)
+		return
+	}
+	m.mu.Lock()
+	m.written[id] = true
+	m.mu.Unlock()
+}
+
+func (m *vectorMemory) AddMessages(messages []core.Message) {
+	for _, message := range messages {
+		m.AddMessage(message)
+	}
+}
+
+// shouldRemember 只记忆用户输入和助手的文本回复，工具调用与观察结果不写入长期记忆
+func (m *vectorMemory) shouldRemember(message core.Message) bool {
+	if strings.TrimSpace(message.Content) == "" || message.Kind != "" {
+		return false
+	}
+	return isUserTurn(message) || (message.Role == core.MessageRoleAssistant && len(message.ToolCalls) == 0)
+}
+
+// recall 检索与用户输入相关的历史记录
+func (m *vectorMemory) recall(vector []float32) {
+	results, err := m.store.Search(m.ctx, vector, m.config.TopK, map[string]string{vectorNamespaceKey: m.config.Namespace})
+	if err != nil {
+		vectorLogger.Warnf("recall memory failed: %v", err)
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recalled = m.recalled[:0]
+	for _, result := range results {
+		if result.Score < m.config.MinScore || m.written[result.ID] {
+			continue
+		}
+		m.recalled = append(m.recalled, result)
+	}
+}
+
+func (m *vectorMemory) GetMessages() []core.Message {
+	messages := m.Memory.GetMessages()
+	note := m.recallNote(messages)
+	if note == "" {
+		return messages
+	}
+	system, rest := splitSystem(messages)
+	result := make([]core.Message, 0, len(messages)+1)
+	result = append(result, system...)
+	result = append(result, core.Message{Role: core.MessageRoleSystem, Content: note})
+	return append(result, rest...)
+}
+
+// recallNote 将召回的记录格式化为系统消息，已在当前上下文中的记录不重复列出
+func (m *vectorMemory) recallNote(messages []core.Message) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.recalled) == 0 {
+		return ""
+	}
+	present := make(map[string]bool, len(messages))
+	for _, message := range messages {
+		present[message.Content] = true
+	}
+	var sb strings.Builder
+	for _, result := range m.recalled {
+		if present[result.Text] {
+			continue
+		}
+		text := []rune(result.Text)
+		if len(text) > maxRecallRunes {
+			text = append(text[:maxRecallRunes], []rune("...")...)
+		}
+		sb.WriteString(fmt.Sprintf("- [%s %s] %s\n", result.GetMetadata("created_at"), result.GetMetadata("role"), string(text)))
+	}
+	if sb.Len() == 0 {
+		return ""
+	}
+	return recallNotePrefix + " 以下是与当前问题语义相关的过往对话记录，可作为参考:\n" + sb.String()
+}
+
+// Unwrap 返回底层内存
+func (m *vectorMemory) Unwrap() core.Memory {
+	return m.Memory
+}
+
+func (m *vectorMemory) GetFormatMessage() string {
+	return formatMessages(m.GetMessages())
+}
+
+func (m *vectorMemory) Clear() {
+	m.Memory.Clear()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recalled = nil
+}
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"strings"
+
+	"jas-agent/agent/core"
+)
+
+// windowMemory 滑动窗口内存：完整消息仍写入底层内存（如会话持久化），
+// 读取时只返回开头的系统消息和最近的若干条消息
+type windowMemory struct {
+	core.Memory
+	size int
+}
+
+// NewWindowMemory 创建滑动窗口内存，size 为保留的最近消息数（不含开头的系统消息）
+func NewWindowMemory(inner core.Memory, size int) core.Memory {
+	if size < 1 {
+		size = 1
+	}
+	return &windowMemory{Memory: inner, size: size}
+}
+
+func (m *windowMemory) GetMessages() []core.Message {
+	messages := m.Memory.GetMessages()
+	system, rest := splitSystem(messages)
+	if len(rest) <= m.size {
+		return messages
+	}
+	start := len(rest) - m.size
+	// 窗口不能以 tool 消息开头，否则对应的工具调用已不在窗口内
+	for start < len(rest)-1 && rest[start].Role == core.MessageRoleTool {
+		start++
+	}
+	result := append([]core.Message(nil), system...)
+	// 最新的用户输入移出窗口时仍保留，避免丢失当前任务
+	if q := latestUserTurn(rest); q >= 0 && q < start {
+		result = append(result, rest[q])
+	}
+	return append(result, rest[start:]...)
+}
+
+// Unwrap 返回底层内存
+func (m *windowMemory) Unwrap() core.Memory {
+	return m.Memory
+}
+
+func (m *windowMemory) GetFormatMessage() string {
+	return formatMessages(m.GetMessages())
+}
+
+// splitSystem 拆分开头的系统消息与其余消息
+func splitSystem(messages []core.Message) ([]core.Message, []core.Message) {
+	i := 0
+	for i < len(messages) && messages[i].Role == core.MessageRoleSystem {
+		i++
+	}
+	return messages[:i], messages[i:]
+}
+
+// isObservation 是否为工具观察结果：tool 消息或文本模式下以 Observation: 开头的用户消息
+func isObservation(msg core.Message) bool {
+	return msg.Role == core.MessageRoleTool ||
+		(msg.Role == core.MessageRoleUser && strings.HasPrefix(msg.Content, "Observation:"))
+}
+
+// isUserTurn 是否为用户输入（一轮对话的开始）
+func isUserTurn(msg core.Message) bool {
+	return msg.Role == core.MessageRoleUser && !isObservation(msg)
+}
+
+// latestUserTurn 返回最新用户输入的位置，不存在时返回 -1
+func latestUserTurn(messages []core.Message) int {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if isUserTurn(messages[i]) {
+			return i
+		}
+	}
+	return -1
+}
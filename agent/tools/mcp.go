@@ -232,6 +232,13 @@ func (mgr *MCPToolManager) Start(isFresh bool) {
 	}
 
 }
+
+// Close 停止定时刷新并关闭 MCP 客户端
+func (mgr *MCPToolManager) Close() error {
+	mgr.isRunning.Store(false)
+	return mgr.client.Close()
+}
+
 func (mgr *MCPToolManager) ExecTool(ctx context.Context, tool *ToolCall, dataHandlers ...core.DataHandlerFilter) (string, error) {
 
 	if fun, ok := mgr.current()[tool.Name]; ok {
@@ -0,0 +1,203 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"jas-agent/agent/core"
+	"reflect"
+	"strings"
+)
+
+// InputArgument 未定义参数的工具在 function calling 模式下使用的参数名
+const InputArgument = "input"
+
+// ParametersSchema 返回工具参数的 JSON Schema。
+// 未定义 Input 的工具（如 calculator）接收原始字符串，统一包装为单个 input 字符串参数。
+func ParametersSchema(tool core.Tool) any {
+	if input := tool.Input(); input != nil {
+		return input
+	}
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			InputArgument: map[string]any{
+				"type":        "string",
+				"description": "工具输入",
+			},
+		},
+		"required": []string{InputArgument},
+	}
+}
+
+// ResolveInput 将 function calling 返回的参数转换为工具 Handler 的输入，
+// 与 ParametersSchema 的包装规则相对应。
+func ResolveInput(tool core.Tool, arguments string) string {
+	if tool == nil || tool.Input() != nil {
+		return arguments
+	}
+	var args map[string]any
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return arguments
+	}
+	value, ok := args[InputArgument]
+	if !ok {
+		return arguments
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return arguments
+	}
+	return string(data)
+}
+
+// ValidateInput 按工具 Input() 的 JSON Schema 校验输入，支持 type、properties、required、
+// additionalProperties、enum 与 items。未定义 Input 的工具接收任意字符串；值为 null 的字段视为稍后填充，不校验类型
+func ValidateInput(tool core.Tool, input string) error {
+	if tool == nil || tool.Input() == nil {
+		return nil
+	}
+	data, err := json.Marshal(tool.Input())
+	if err != nil {
+		return nil
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil
+	}
+	var value any
+	if err := json.Unmarshal([]byte(input), &value); err != nil {
+		return fmt.Errorf("input is not valid JSON: %w", err)
+	}
+	var problems []string
+	validateValue("input", schema, value, &problems)
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// validateValue 递归校验 value 是否符合 schema，问题追加到 problems
+func validateValue(path string, schema map[string]any, value any, problems *[]string) {
+	if value == nil {
+		return
+	}
+	if enum, ok := schema["enum"].([]any); ok && len(enum) > 0 {
+		matched := false
+		for _, option := range enum {
+			if reflect.DeepEqual(option, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			*problems = append(*problems, fmt.Sprintf("%s: %v is not one of %v", path, value, enum))
+			return
+		}
+	}
+	if types := schemaTypes(schema["type"]); len(types) > 0 {
+		matched := false
+		for _, t := range types {
+			if matchesType(t, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			*problems = append(*problems, fmt.Sprintf("%s: expected %s, got %s", path, strings.Join(types, " or "), jsonType(value)))
+			return
+		}
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		properties, _ := schema["properties"].(map[string]any)
+		if required, ok := schema["required"].([]any); ok {
+			for _, name := range required {
+				if key, ok := name.(string); ok {
+					if _, exists := v[key]; !exists {
+						*problems = append(*problems, fmt.Sprintf("%s: missing required property %q", path, key))
+					}
+				}
+			}
+		}
+		for key, field := range v {
+			propSchema, ok := properties[key].(map[string]any)
+			if !ok {
+				if additional, ok := schema["additionalProperties"].(bool); ok && !additional {
+					*problems = append(*problems, fmt.Sprintf("%s: unknown property %q", path, key))
+				}
+				continue
+			}
+			validateValue(path+"."+key, propSchema, field, problems)
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				validateValue(fmt.Sprintf("%s[%d]", path, i), items, item, problems)
+			}
+		}
+	}
+}
+
+// schemaTypes schema 中 type 的取值，可以是字符串或字符串数组
+func schemaTypes(t any) []string {
+	switch v := t.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		types := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+func matchesType(t string, value any) bool {
+	switch t {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == float64(int64(n))
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	}
+	// 未知类型不做限制
+	return true
+}
+
+func jsonType(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return "null"
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"jas-agent/agent/core"
 	"jas-agent/pkg/algorithm"
+	"path"
 	"strings"
 )
 
@@ -73,11 +74,29 @@ func (tm *ToolManager) ExecTool(ctx context.Context, tool *ToolCall) (string, er
 	return mcpToolManager.ExecTool(ctx, tool, dataHandlers...)
 }
 
+// GetTool 根据名称查找工具（包括 service@tool 形式的 MCP 工具）
+func (tm *ToolManager) GetTool(name string) (core.Tool, bool) {
+	if tool, ok := tm.tools[name]; ok {
+		return tool, true
+	}
+	args := strings.Split(name, MCP_SEP)
+	if len(args) != 2 {
+		return nil, false
+	}
+	mcpToolManager, ok := tm.mcpToolManagers[args[0]]
+	if !ok {
+		return nil, false
+	}
+	tool, ok := mcpToolManager.current()[name]
+	return tool, ok
+}
+
 func GetToolManager() *ToolManager {
 	return tm
 }
 
 type ToolCall struct {
+	ID    string // function calling 模式下的调用ID
 	Name  string
 	Input string
 }
@@ -94,6 +113,40 @@ func filter(tool core.Tool, filters ...core.FilterFunc) bool {
 	return true
 }
 
+// Subset 创建只包含名称匹配任一模式的工具的工具管理器，模式使用 path.Match 语法，
+// MCP 工具按 service@tool 名称匹配并以普通工具注册，保留原有的数据处理中间件
+func (tm *ToolManager) Subset(patterns []string) *ToolManager {
+	return tm.Filter(func(tool core.Tool) bool {
+		return matchAny(patterns, tool.Name())
+	})
+}
+
+// Filter 返回 keep 为 true 的工具组成的工具管理器，工具的数据处理中间件随工具保留
+func (tm *ToolManager) Filter(keep func(tool core.Tool) bool) *ToolManager {
+	sub := NewToolManager()
+	for _, tool := range tm.AvailableTools() {
+		name := tool.Name()
+		if !keep(tool) {
+			continue
+		}
+		dataHandlers, ok := tm.toolsMiddleware[name]
+		if !ok {
+			dataHandlers = tm.mcpToolMiddleware[name]
+		}
+		sub.RegisterTool(tool, dataHandlers...)
+	}
+	return sub
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
 func (tm *ToolManager) Inherit(baseToolManager *ToolManager) {
 	for k, v := range baseToolManager.tools {
 		tm.tools[k] = v
@@ -0,0 +1,135 @@
+package window
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"jas-agent/agent/core"
+)
+
+// ReadObservationToolName 读取转存观察结果的工具名称
+const ReadObservationToolName = "read_observation"
+
+// defaultReadChars read_observation 每次默认读取的字符数
+const defaultReadChars = 8000
+
+// ObservationStore 保存被转存的超长工具输出，供模型按需分段读取，生命周期与一次运行相同
+type ObservationStore struct {
+	mu      sync.RWMutex
+	entries map[string]observation
+	seq     int
+}
+
+type observation struct {
+	tool    string
+	content []rune
+}
+
+// NewObservationStore 创建观察结果存储
+func NewObservationStore() *ObservationStore {
+	return &ObservationStore{entries: map[string]observation{}}
+}
+
+// Put 保存工具输出并返回编号
+func (s *ObservationStore) Put(tool, content string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	id := fmt.Sprintf("obs-%d", s.seq)
+	s.entries[id] = observation{tool: tool, content: []rune(content)}
+	return id
+}
+
+// Read 从 offset（字符）开始读取最多 limit 个字符，返回片段与总长度
+func (s *ObservationStore) Read(id string, offset, limit int) (string, int, error) {
+	s.mu.RLock()
+	entry, ok := s.entries[id]
+	s.mu.RUnlock()
+	if !ok {
+		return "", 0, fmt.Errorf("observation %s not found", id)
+	}
+	total := len(entry.content)
+	if offset < 0 || offset > total {
+		return "", total, fmt.Errorf("offset %d out of range [0, %d]", offset, total)
+	}
+	if limit <= 0 {
+		limit = defaultReadChars
+	}
+	end := min(offset+limit, total)
+	return string(entry.content[offset:end]), total, nil
+}
+
+// readObservationTool 按编号分段读取被转存的工具输出
+type readObservationTool struct {
+	store *ObservationStore
+}
+
+// NewReadObservationTool 创建 read_observation 工具
+func NewReadObservationTool(store *ObservationStore) core.Tool {
+	return &readObservationTool{store: store}
+}
+
+func (t *readObservationTool) Name() string {
+	return ReadObservationToolName
+}
+
+func (t *readObservationTool) Description() string {
+	return `读取因过长而被转存的工具输出。输入 JSON：{"id": "obs-1", "offset": 0, "limit": 8000}，` +
+		`offset 与 limit 以字符计，limit 默认 8000`
+}
+
+func (t *readObservationTool) Input() any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"id": map[string]any{
+				"type":        "string",
+				"description": "观察结果编号，如 obs-1",
+			},
+			"offset": map[string]any{
+				"type":        "integer",
+				"description": "起始字符位置，默认 0",
+			},
+			"limit": map[string]any{
+				"type":        "integer",
+				"description": "读取的字符数，默认 8000",
+			},
+		},
+		"required": []string{"id"},
+	}
+}
+
+func (t *readObservationTool) Type() core.ToolType {
+	return core.Normal
+}
+
+func (t *readObservationTool) Handler(ctx context.Context, input string) (string, error) {
+	var args struct {
+		ID     string `json:"id"`
+		Offset int    `json:"offset"`
+		Limit  int    `json:"limit"`
+	}
+	input = strings.TrimSpace(input)
+	if strings.HasPrefix(input, "{") {
+		if err := json.Unmarshal([]byte(input), &args); err != nil {
+			return "", fmt.Errorf("parse read_observation input: %w", err)
+		}
+	} else {
+		args.ID = input
+	}
+	chunk, total, err := t.store.Read(args.ID, args.Offset, args.Limit)
+	if err != nil {
+		return "", err
+	}
+	end := args.Offset + len([]rune(chunk))
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("[%s 第 %d-%d 个字符，共 %d 个字符]\n", args.ID, args.Offset, end, total))
+	sb.WriteString(chunk)
+	if end < total {
+		sb.WriteString(fmt.Sprintf("\n[未读完，继续读取请使用 offset=%d]", end))
+	}
+	return sb.String(), nil
+}
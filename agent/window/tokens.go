@@ -0,0 +1,214 @@
+package window
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"github.com/pkoukk/tiktoken-go"
+
+	"jas-agent/agent/core"
+)
+
+// messageOverhead 每条消息除内容外的固定开销（角色、分隔符等）
+const messageOverhead = 4
+
+// defaultEncoding 分词方式未知的模型（如 claude、qwen）使用的近似编码
+const defaultEncoding = tiktoken.MODEL_CL100K_BASE
+
+// TokenCounter 按模型的分词方式计算 token 数。
+// 编码的词表首次使用时在后台加载（默认从 OpenAI 下载并缓存到 TIKTOKEN_CACHE_DIR），
+// 加载完成前或加载失败（如离线环境）时使用 EstimateTokens 的字符估算
+type TokenCounter struct {
+	encoding *encoding
+}
+
+// CounterForModel 返回模型的 token 计数器：OpenAI 模型使用其 tiktoken 编码，
+// 其他模型以 cl100k_base 近似；模型名可带服务商前缀（provider/model）
+func CounterForModel(model string) TokenCounter {
+	if _, name, ok := strings.Cut(model, "/"); ok {
+		model = name
+	}
+	name, ok := tiktoken.MODEL_TO_ENCODING[model]
+	if !ok {
+		name = defaultEncoding
+		for prefix, encodingName := range tiktoken.MODEL_PREFIX_TO_ENCODING {
+			if strings.HasPrefix(model, prefix) {
+				name = encodingName
+				break
+			}
+		}
+	}
+	return TokenCounter{encoding: encodings.get(name)}
+}
+
+// Count 计算文本的 token 数
+func (c TokenCounter) Count(text string) int {
+	if enc := c.encoding.load(); enc != nil {
+		return len(enc.EncodeOrdinary(text))
+	}
+	return EstimateTokens(text)
+}
+
+// Truncate 截取文本开头不超过 maxTokens 的部分，返回截取结果与是否发生截断
+func (c TokenCounter) Truncate(text string, maxTokens int) (string, bool) {
+	enc := c.encoding.load()
+	if enc == nil {
+		return truncateTokens(text, maxTokens)
+	}
+	tokens := enc.EncodeOrdinary(text)
+	if len(tokens) <= maxTokens {
+		return text, false
+	}
+	// 截断位置可能落在多字节字符（如中文）的中间，去掉末尾不完整的字符
+	prefix := enc.Decode(tokens[:max(maxTokens, 0)])
+	for len(prefix) > 0 {
+		r, size := utf8.DecodeLastRuneInString(prefix)
+		if r != utf8.RuneError || size > 1 {
+			break
+		}
+		prefix = prefix[:len(prefix)-size]
+	}
+	return prefix, true
+}
+
+// MessageTokens 计算一条消息的 token 数，包括工具调用的名称与参数
+func (c TokenCounter) MessageTokens(msg core.Message) int {
+	tokens := messageOverhead + c.Count(msg.Content)
+	for _, toolCall := range msg.ToolCalls {
+		tokens += messageOverhead + c.Count(toolCall.Name) + c.Count(toolCall.Arguments)
+	}
+	return tokens
+}
+
+// MessagesTokens 计算一组消息的 token 数
+func (c TokenCounter) MessagesTokens(messages []core.Message) int {
+	total := 0
+	for _, msg := range messages {
+		total += c.MessageTokens(msg)
+	}
+	return total
+}
+
+// encodings 已使用的编码，词表在进程内只加载一次
+var encodings = newEncodingCache(tiktoken.GetEncoding)
+
+type encodingCache struct {
+	loader    func(name string) (*tiktoken.Tiktoken, error)
+	encodings sync.Map // name -> *encoding
+}
+
+func newEncodingCache(loader func(name string) (*tiktoken.Tiktoken, error)) *encodingCache {
+	return &encodingCache{loader: loader}
+}
+
+// get 返回编码，首次使用时在后台加载词表，不阻塞调用方
+func (c *encodingCache) get(name string) *encoding {
+	value, loaded := c.encodings.LoadOrStore(name, &encoding{ready: make(chan struct{})})
+	enc := value.(*encoding)
+	if !loaded {
+		go func() {
+			defer close(enc.ready)
+			if tk, err := c.loader(name); err == nil {
+				enc.tiktoken.Store(tk)
+			}
+		}()
+	}
+	return enc
+}
+
+type encoding struct {
+	tiktoken atomic.Pointer[tiktoken.Tiktoken]
+	ready    chan struct{} // 加载结束（无论成功与否）时关闭
+}
+
+// load 返回已加载的编码，未加载完成或加载失败时返回 nil
+func (e *encoding) load() *tiktoken.Tiktoken {
+	if e == nil {
+		return nil
+	}
+	return e.tiktoken.Load()
+}
+
+// EstimateTokens 估算文本的 token 数：ASCII 字符约 4 个 1 token，
+// 中文等非 ASCII 字符约 1 个 1 token。估算偏保守，在模型编码不可用时使用
+func EstimateTokens(text string) int {
+	ascii, other := 0, 0
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return (ascii+3)/4 + other
+}
+
+// MessageTokens 按字符估算一条消息的 token 数，用于不确定模型的场景
+func MessageTokens(msg core.Message) int {
+	return TokenCounter{}.MessageTokens(msg)
+}
+
+// MessagesTokens 按字符估算一组消息的 token 数，用于不确定模型的场景
+func MessagesTokens(messages []core.Message) int {
+	return TokenCounter{}.MessagesTokens(messages)
+}
+
+// truncateTokens 截取文本开头不超过 maxTokens 的部分，返回截取结果与是否发生截断
+func truncateTokens(text string, maxTokens int) (string, bool) {
+	if EstimateTokens(text) <= maxTokens {
+		return text, false
+	}
+	tokens, ascii := 0, 0
+	for i, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+			if ascii%4 == 1 {
+				tokens++
+			}
+		} else {
+			tokens++
+		}
+		if tokens > maxTokens {
+			return text[:i], true
+		}
+	}
+	return text, false
+}
+
+// DefaultModelLimits 常见模型的上下文窗口（token），按模型名前缀匹配
+var DefaultModelLimits = map[string]int{
+	"gpt-3.5-turbo": 16385,
+	"gpt-4":         8192,
+	"gpt-4-turbo":   128000,
+	"gpt-4o":        128000,
+	"gpt-4.1":       1047576,
+	"o1":            200000,
+	"o3":            200000,
+	"o4-mini":       200000,
+	"claude":        200000,
+	"qwen":          32768,
+	"deepseek":      65536,
+	"llama3":        8192,
+}
+
+// modelLimit 按最长前缀匹配模型的上下文窗口；模型名带服务商前缀（provider/model）时也会去掉前缀再匹配
+func modelLimit(limits map[string]int, model string) (int, bool) {
+	candidates := []string{model}
+	if _, name, ok := strings.Cut(model, "/"); ok {
+		candidates = append(candidates, name)
+	}
+	for _, candidate := range candidates {
+		best, limit := -1, 0
+		for prefix, value := range limits {
+			if strings.HasPrefix(candidate, prefix) && len(prefix) > best {
+				best, limit = len(prefix), value
+			}
+		}
+		if best >= 0 {
+			return limit, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,66 @@
+package window
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/pkoukk/tiktoken-go"
+)
+
+func TestMain(m *testing.M) {
+	// 测试不下载词表，默认使用字符估算，保证结果与网络环境无关
+	encodings = newEncodingCache(func(string) (*tiktoken.Tiktoken, error) {
+		return nil, errors.New("offline")
+	})
+	os.Exit(m.Run())
+}
+
+// byteEncoding 每个字节一个 token 的编码，用于验证按编码计数与截断
+func byteEncoding(string) (*tiktoken.Tiktoken, error) {
+	ranks := make(map[string]int, 256)
+	for i := 0; i < 256; i++ {
+		ranks[string([]byte{byte(i)})] = i
+	}
+	bpe, err := tiktoken.NewCoreBPE(ranks, map[string]int{}, `\S+|\s+`)
+	if err != nil {
+		return nil, err
+	}
+	return tiktoken.NewTiktoken(bpe, &tiktoken.Encoding{}, map[string]any{}), nil
+}
+
+func TestTokenCounter(t *testing.T) {
+	text := "token 计数"
+	if got := CounterForModel("gpt-4o").Count(text); got != EstimateTokens(text) {
+		t.Fatalf("count without encoding = %d, want estimate %d", got, EstimateTokens(text))
+	}
+
+	saved := encodings
+	defer func() { encodings = saved }()
+	encodings = newEncodingCache(byteEncoding)
+	for _, model := range []string{"gpt-4o", "openai/gpt-4o-mini", "qwen-max"} {
+		counter := CounterForModel(model)
+		<-counter.encoding.ready
+		if got := counter.Count(text); got != len(text) {
+			t.Fatalf("%s: count = %d, want %d", model, got, len(text))
+		}
+	}
+
+	// 截断不能产生不完整的中文字符
+	counter := CounterForModel("gpt-4o")
+	cjk := strings.Repeat("上下文窗口", 10)
+	for _, maxTokens := range []int{1, 4, 5, 10} {
+		prefix, truncated := counter.Truncate(cjk, maxTokens)
+		if !truncated || !utf8.ValidString(prefix) || !strings.HasPrefix(cjk, prefix) {
+			t.Fatalf("truncate(%d) = %q, %v", maxTokens, prefix, truncated)
+		}
+		if got := counter.Count(prefix); got > maxTokens || got < maxTokens-2 {
+			t.Fatalf("truncate(%d) keeps %d tokens", maxTokens, got)
+		}
+	}
+	if prefix, truncated := counter.Truncate(cjk, len(cjk)); truncated || prefix != cjk {
+		t.Fatalf("text within limit must not be truncated")
+	}
+}
@@ -0,0 +1,328 @@
+package window
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"jas-agent/agent/core"
+	"jas-agent/agent/llm"
+)
+
+// 压缩方式
+const (
+	// CompactionTruncate 直接省略较早的消息
+	CompactionTruncate = "truncate"
+	// CompactionSummarize 将较早的消息总结为摘要
+	CompactionSummarize = "summarize"
+)
+
+const (
+	defaultContextTokens          = 32768
+	defaultReserveTokens          = 4096
+	defaultMaxObservationTokens   = 4000
+	defaultKeepRecentObservations = 3
+	// compactedObservationTokens 较早的观察结果压缩后保留的 token 数
+	compactedObservationTokens = 200
+	// compactionNotePrefix 压缩说明消息的前缀
+	compactionNotePrefix = "[上下文压缩]"
+)
+
+// Config 上下文窗口配置，零值字段使用默认值
+type Config struct {
+	// ModelLimits 模型名（前缀）到上下文窗口 token 数的映射，优先于 DefaultModelLimits
+	ModelLimits map[string]int
+	// DefaultContextTokens 无法识别的模型使用的上下文窗口，默认 32768
+	DefaultContextTokens int
+	// ReserveTokens 为模型输出预留的 token 数，默认 4096
+	ReserveTokens int
+	// MaxObservationTokens 单次工具输出超过该值时转存到 ObservationStore，消息中只保留预览，默认 4000，小于 0 时不转存
+	MaxObservationTokens int
+	// KeepRecentObservations 超出窗口时保持完整的最近观察结果数，默认 3
+	KeepRecentObservations int
+	// Compaction 超出窗口时对较早消息的处理方式：truncate（默认）或 summarize
+	Compaction string
+}
+
+// Summarizer 将较早的消息总结为摘要，previous 为此前已有的摘要
+type Summarizer func(ctx context.Context, previous string, messages []core.Message) (string, error)
+
+// Manager 位于记忆与模型请求之间的上下文窗口管理器：
+// 按模型的上下文窗口估算 token，超出时压缩较早的观察结果、省略或总结较早的消息，
+// 始终保留系统提示词与最新的用户输入；超长工具输出转存后由模型通过 read_observation 读取。
+// 压缩只作用于发送给模型的消息，不修改记忆
+type Manager struct {
+	config     Config
+	store      *ObservationStore
+	summarizer Summarizer
+
+	mu         sync.Mutex
+	summary    string // 已总结消息的摘要
+	summarized int    // 已总结的消息数
+}
+
+// Option Manager 选项
+type Option func(*Manager)
+
+// WithSummarizer 设置摘要生成方式，Compaction 为 summarize 时使用
+func WithSummarizer(summarizer Summarizer) Option {
+	return func(m *Manager) {
+		m.summarizer = summarizer
+	}
+}
+
+// NewManager 创建上下文窗口管理器
+func NewManager(config Config, opts ...Option) *Manager {
+	if config.DefaultContextTokens <= 0 {
+		config.DefaultContextTokens = defaultContextTokens
+	}
+	if config.ReserveTokens <= 0 {
+		config.ReserveTokens = defaultReserveTokens
+	}
+	if config.MaxObservationTokens == 0 {
+		config.MaxObservationTokens = defaultMaxObservationTokens
+	}
+	if config.KeepRecentObservations <= 0 {
+		config.KeepRecentObservations = defaultKeepRecentObservations
+	}
+	m := &Manager{config: config, store: NewObservationStore()}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Store 返回转存工具输出的存储
+func (m *Manager) Store() *ObservationStore {
+	return m.store
+}
+
+// ContextTokens 返回模型的上下文窗口大小
+func (m *Manager) ContextTokens(model string) int {
+	if limit, ok := modelLimit(m.config.ModelLimits, model); ok {
+		return limit
+	}
+	if limit, ok := modelLimit(DefaultModelLimits, model); ok {
+		return limit
+	}
+	return m.config.DefaultContextTokens
+}
+
+// Budget 返回模型可用于输入消息的 token 数
+func (m *Manager) Budget(model string) int {
+	return max(m.ContextTokens(model)-m.config.ReserveTokens, m.ContextTokens(model)/2)
+}
+
+// Offload 工具输出按模型的分词方式超过 MaxObservationTokens 时转存，返回带预览与读取方式的替代内容
+func (m *Manager) Offload(model, tool, content string) string {
+	if m.config.MaxObservationTokens < 0 || tool == ReadObservationToolName {
+		return content
+	}
+	counter := CounterForModel(model)
+	tokens := counter.Count(content)
+	if tokens <= m.config.MaxObservationTokens {
+		return content
+	}
+	id := m.store.Put(tool, content)
+	preview, _ := counter.Truncate(content, m.config.MaxObservationTokens/2)
+	return fmt.Sprintf("%s\n...\n[输出过长（约 %d tokens，%d 个字符）已转存为 %s，仅显示开头部分。"+
+		"如需更多内容，请调用 %s 工具，输入 {\"id\": \"%s\", \"offset\": %d}]",
+		preview, tokens, len([]rune(content)), id, ReadObservationToolName, id, len([]rune(preview)))
+}
+
+// Fit 按模型的分词方式将消息压缩到模型的上下文窗口内：
+//  1. 未超出时原样返回；
+//  2. 将最近 KeepRecentObservations 条之前的观察结果截断为简短预览；
+//  3. 仍超出时从最早的消息开始省略（助手的工具调用与对应的 tool 消息一起省略），
+//     summarize 模式下以摘要代替被省略的消息；
+//  4. 仍超出时截断所有观察结果。
+//
+// 开头的系统消息和最新的用户输入始终保留
+func (m *Manager) Fit(ctx context.Context, model string, messages []core.Message) []core.Message {
+	budget := m.Budget(model)
+	counter := CounterForModel(model)
+	if counter.MessagesTokens(messages) <= budget {
+		return messages
+	}
+	result := append([]core.Message(nil), messages...)
+	observations := observationIndexes(result)
+	if n := len(observations) - m.config.KeepRecentObservations; n > 0 {
+		compactObservations(counter, result, observations[:n])
+	}
+	if counter.MessagesTokens(result) <= budget {
+		return result
+	}
+	result = m.dropOldest(ctx, counter, result, budget)
+	if counter.MessagesTokens(result) > budget {
+		compactObservations(counter, result, observationIndexes(result))
+	}
+	return result
+}
+
+// dropOldest 省略最早的可省略消息直到不超出预算，最后一组消息始终保留
+func (m *Manager) dropOldest(ctx context.Context, counter TokenCounter, messages []core.Message, budget int) []core.Message {
+	pinned := pinnedIndexes(messages)
+	groups := droppableGroups(messages, pinned)
+	if len(groups) <= 1 {
+		return messages
+	}
+	excess := counter.MessagesTokens(messages) - budget
+	dropped := map[int]bool{}
+	var droppedMessages []core.Message
+	first := -1
+	for _, group := range groups[:len(groups)-1] {
+		if excess <= 0 {
+			break
+		}
+		for _, i := range group {
+			dropped[i] = true
+			droppedMessages = append(droppedMessages, messages[i])
+			excess -= counter.MessageTokens(messages[i])
+		}
+		if first < 0 {
+			first = group[0]
+		}
+	}
+	if first < 0 {
+		return messages
+	}
+	note := core.Message{
+		Role:    core.MessageRoleSystem,
+		Content: m.compactionNote(ctx, droppedMessages),
+	}
+	result := make([]core.Message, 0, len(messages)-len(dropped)+1)
+	for i, msg := range messages {
+		if i == first {
+			result = append(result, note)
+		}
+		if !dropped[i] {
+			result = append(result, msg)
+		}
+	}
+	return result
+}
+
+// compactionNote 生成替代被省略消息的说明，summarize 模式下为增量生成的摘要
+func (m *Manager) compactionNote(ctx context.Context, dropped []core.Message) string {
+	omitted := fmt.Sprintf("%s 为控制上下文长度，已省略 %d 条较早的消息", compactionNotePrefix, len(dropped))
+	if m.config.Compaction != CompactionSummarize || m.summarizer == nil {
+		return omitted
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// 记忆只追加，被省略的消息是上一次的超集；数量变少说明记忆被重置，需要重新总结
+	if m.summarized > len(dropped) {
+		m.summary, m.summarized = "", 0
+	}
+	if m.summarized < len(dropped) {
+		summary, err := m.summarizer(ctx, m.summary, dropped[m.summarized:])
+		if err != nil {
+			if m.summary == "" {
+				return omitted
+			}
+			return fmt.Sprintf("%s 较早执行过程的摘要（最近 %d 条被省略的消息未包含在内）:\n%s",
+				compactionNotePrefix, len(dropped)-m.summarized, m.summary)
+		}
+		m.summary, m.summarized = summary, len(dropped)
+	}
+	return compactionNotePrefix + " 较早执行过程的摘要:\n" + m.summary
+}
+
+// IsCompactionNote 是否为压缩时插入的省略说明或摘要
+func IsCompactionNote(msg core.Message) bool {
+	return msg.Role == core.MessageRoleSystem && strings.HasPrefix(msg.Content, compactionNotePrefix)
+}
+
+// NewLLMSummarizer 使用模型生成摘要
+func NewLLMSummarizer(chat llm.Chat, model string) Summarizer {
+	return func(ctx context.Context, previous string, messages []core.Message) (string, error) {
+		var sb strings.Builder
+		if previous != "" {
+			sb.WriteString("已有摘要:\n")
+			sb.WriteString(previous)
+			sb.WriteString("\n\n")
+		}
+		sb.WriteString("新增的执行过程:\n")
+		for _, msg := range messages {
+			content, _ := truncateTokens(msg.Content, defaultMaxObservationTokens)
+			sb.WriteString(fmt.Sprintf("[%s] %s\n", msg.Role, content))
+			for _, toolCall := range msg.ToolCalls {
+				sb.WriteString(fmt.Sprintf("[%s] Action: %s[%s]\n", msg.Role, toolCall.Name, toolCall.Arguments))
+			}
+		}
+		resp, err := chat.Completions(ctx, llm.NewChatRequest(model, []core.Message{
+			{
+				Role: core.MessageRoleSystem,
+				Content: "你负责压缩 Agent 的执行历史。请将已有摘要与新增的执行过程合并为一份简洁的摘要，" +
+					"保留已调用的工具、关键参数、关键结论与数据、尚未解决的问题，不要编造内容。",
+			},
+			{Role: core.MessageRoleUser, Content: sb.String()},
+		}))
+		if err != nil {
+			return "", err
+		}
+		return resp.Content(), nil
+	}
+}
+
+// isObservation 是否为工具观察结果：tool 消息或文本模式下以 Observation: 开头的用户消息
+func isObservation(msg core.Message) bool {
+	return msg.Role == core.MessageRoleTool ||
+		(msg.Role == core.MessageRoleUser && strings.HasPrefix(msg.Content, "Observation:"))
+}
+
+func observationIndexes(messages []core.Message) []int {
+	var indexes []int
+	for i, msg := range messages {
+		if isObservation(msg) {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// compactObservations 将指定的观察结果截断为简短预览
+func compactObservations(counter TokenCounter, messages []core.Message, indexes []int) {
+	for _, i := range indexes {
+		content, truncated := counter.Truncate(messages[i].Content, compactedObservationTokens)
+		if truncated {
+			messages[i].Content = content + "\n...[较早的观察结果已截断]"
+		}
+	}
+}
+
+// pinnedIndexes 始终保留的消息：开头的系统消息和最新的用户输入
+func pinnedIndexes(messages []core.Message) map[int]bool {
+	pinned := map[int]bool{}
+	for i := 0; i < len(messages) && messages[i].Role == core.MessageRoleSystem; i++ {
+		pinned[i] = true
+	}
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == core.MessageRoleUser && !isObservation(messages[i]) {
+			pinned[i] = true
+			break
+		}
+	}
+	return pinned
+}
+
+// droppableGroups 按时间顺序返回可省略的消息组，
+// 助手的工具调用与其后对应的 tool 消息组成一组，保证 tool_call 与结果成对出现
+func droppableGroups(messages []core.Message, pinned map[int]bool) [][]int {
+	var groups [][]int
+	for i := 0; i < len(messages); i++ {
+		if pinned[i] {
+			continue
+		}
+		group := []int{i}
+		if messages[i].Role == core.MessageRoleAssistant && len(messages[i].ToolCalls) > 0 {
+			for i+1 < len(messages) && messages[i+1].Role == core.MessageRoleTool {
+				i++
+				group = append(group, i)
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
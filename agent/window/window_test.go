@@ -0,0 +1,57 @@
+package window
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"jas-agent/agent/core"
+)
+
+func TestFit(t *testing.T) {
+	m := NewManager(Config{ModelLimits: map[string]int{"tiny": 600}, ReserveTokens: 100, KeepRecentObservations: 1})
+	big := strings.Repeat("x", 1200) // 约 300 tokens
+	messages := []core.Message{
+		{Role: core.MessageRoleSystem, Content: "system prompt"},
+		{Role: core.MessageRoleUser, Content: "question"},
+		{Role: core.MessageRoleAssistant, ToolCalls: []core.ToolCall{{ID: "1", Name: "search", Arguments: "{}"}}},
+		{Role: core.MessageRoleTool, ToolCallID: "1", Content: big},
+		{Role: core.MessageRoleAssistant, Content: "Action: search[b]"},
+		{Role: core.MessageRoleUser, Content: "Observation: " + big},
+		{Role: core.MessageRoleAssistant, Content: "Action: search[c]"},
+		{Role: core.MessageRoleUser, Content: "Observation: " + big},
+	}
+	fitted := m.Fit(context.Background(), "provider/tiny", messages)
+	if got := CounterForModel("provider/tiny").MessagesTokens(fitted); got > m.Budget("tiny") {
+		t.Fatalf("tokens = %d, budget = %d", got, m.Budget("tiny"))
+	}
+	if fitted[0].Content != "system prompt" || fitted[1].Content != "question" {
+		t.Fatalf("system prompt and user turn must be kept: %+v", fitted[:2])
+	}
+	if last := fitted[len(fitted)-1]; last.Content != messages[len(messages)-1].Content {
+		t.Fatalf("latest observation must be kept intact")
+	}
+	// tool 消息不能脱离对应的工具调用单独出现
+	for i, msg := range fitted {
+		if msg.Role == core.MessageRoleTool && (i == 0 || len(fitted[i-1].ToolCalls) == 0) {
+			t.Fatalf("orphaned tool message at %d", i)
+		}
+	}
+	// 记忆中的消息不被修改
+	if messages[3].Content != big {
+		t.Fatal("input messages must not be modified")
+	}
+}
+
+func TestOffload(t *testing.T) {
+	m := NewManager(Config{MaxObservationTokens: 100})
+	content := strings.Repeat("0123456789", 100)
+	preview := m.Offload("gpt-4o", "search", content)
+	if !strings.Contains(preview, "obs-1") || len(preview) >= len(content) {
+		t.Fatalf("unexpected preview: %s", preview)
+	}
+	out, err := NewReadObservationTool(m.Store()).Handler(context.Background(), `{"id":"obs-1","offset":990}`)
+	if err != nil || !strings.HasSuffix(out, "0123456789") {
+		t.Fatalf("read_observation = %q, %v", out, err)
+	}
+}
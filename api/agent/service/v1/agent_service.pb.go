@@ -33,6 +33,7 @@ const (
 	AgentType_SQL           AgentType = 3 // SQL Agent
 	AgentType_ELASTICSEARCH AgentType = 4 // Elasticsearch Agent
 	AgentType_ROOT_CAUSE    AgentType = 5 // Root Cause Agent (智能故障根因分析)
+	AgentType_SUPERVISOR    AgentType = 6 // Supervisor Agent (调度多个 Agent)
 )
 
 // Enum value maps for AgentType.
@@ -44,6 +45,7 @@ var (
 		3: "SQL",
 		4: "ELASTICSEARCH",
 		5: "ROOT_CAUSE",
+		6: "SUPERVISOR",
 	}
 	AgentType_value = map[string]int32{
 		"REACT":         0,
@@ -52,6 +54,7 @@ var (
 		"SQL":           3,
 		"ELASTICSEARCH": 4,
 		"ROOT_CAUSE":    5,
+		"SUPERVISOR":    6,
 	}
 )
 
@@ -85,12 +88,16 @@ func (AgentType) EnumDescriptor() ([]byte, []int) {
 type ChatStreamResponse_MessageType int32
 
 const (
-	ChatStreamResponse_THINKING    ChatStreamResponse_MessageType = 0 // 思考中
-	ChatStreamResponse_ACTION      ChatStreamResponse_MessageType = 1 // 执行动作
-	ChatStreamResponse_OBSERVATION ChatStreamResponse_MessageType = 2 // 观察结果
-	ChatStreamResponse_FINAL       ChatStreamResponse_MessageType = 3 // 最终答案
-	ChatStreamResponse_ERROR       ChatStreamResponse_MessageType = 4 // 错误
-	ChatStreamResponse_METADATA    ChatStreamResponse_MessageType = 5 // 元数据
+	ChatStreamResponse_THINKING          ChatStreamResponse_MessageType = 0 // 思考中
+	ChatStreamResponse_ACTION            ChatStreamResponse_MessageType = 1 // 执行动作
+	ChatStreamResponse_OBSERVATION       ChatStreamResponse_MessageType = 2 // 观察结果
+	ChatStreamResponse_FINAL             ChatStreamResponse_MessageType = 3 // 最终答案
+	ChatStreamResponse_ERROR             ChatStreamResponse_MessageType = 4 // 错误
+	ChatStreamResponse_METADATA          ChatStreamResponse_MessageType = 5 // 元数据
+	ChatStreamResponse_SUMMARY           ChatStreamResponse_MessageType = 6 // 总结（流式片段）
+	ChatStreamResponse_DELTA             ChatStreamResponse_MessageType = 7 // 模型输出的增量片段
+	ChatStreamResponse_APPROVAL_REQUIRED ChatStreamResponse_MessageType = 8 // 工具调用等待人工审批，运行暂停直到 ResolveApproval
+	ChatStreamResponse_PLAN_STEP         ChatStreamResponse_MessageType = 9 // 计划步骤的状态变化（Plan Agent）
 )
 
 // Enum value maps for ChatStreamResponse_MessageType.
@@ -102,14 +109,22 @@ var (
 		3: "FINAL",
 		4: "ERROR",
 		5: "METADATA",
+		6: "SUMMARY",
+		7: "DELTA",
+		8: "APPROVAL_REQUIRED",
+		9: "PLAN_STEP",
 	}
 	ChatStreamResponse_MessageType_value = map[string]int32{
-		"THINKING":    0,
-		"ACTION":      1,
-		"OBSERVATION": 2,
-		"FINAL":       3,
-		"ERROR":       4,
-		"METADATA":    5,
+		"THINKING":          0,
+		"ACTION":            1,
+		"OBSERVATION":       2,
+		"FINAL":             3,
+		"ERROR":             4,
+		"METADATA":          5,
+		"SUMMARY":           6,
+		"DELTA":             7,
+		"APPROVAL_REQUIRED": 8,
+		"PLAN_STEP":         9,
 	}
 )
 
@@ -137,7 +152,7 @@ func (x ChatStreamResponse_MessageType) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use ChatStreamResponse_MessageType.Descriptor instead.
 func (ChatStreamResponse_MessageType) EnumDescriptor() ([]byte, []int) {
-	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{3, 0}
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{6, 0}
 }
 
 // 空消息
@@ -190,6 +205,7 @@ type ChatRequest struct {
 	MaxSteps           int32             `protobuf:"varint,7,opt,name=max_steps,json=maxSteps,proto3" json:"max_steps,omitempty"`                                                      // 最大执行步数（可选，覆盖）
 	Config             map[string]string `protobuf:"bytes,8,rep,name=config,proto3" json:"config,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // 额外配置
 	EnabledMcpServices []string          `protobuf:"bytes,9,rep,name=enabled_mcp_services,json=enabledMcpServices,proto3" json:"enabled_mcp_services,omitempty"`                       // 启用的MCP服务（可选，覆盖）
+	TenantId           string            `protobuf:"bytes,10,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`                                                      // 租户ID，由服务端按认证网关写入的请求头 X-Tenant-ID 设置，请求中的值被忽略
 	unknownFields      protoimpl.UnknownFields
 	sizeCache          protoimpl.SizeCache
 }
@@ -287,6 +303,13 @@ func (x *ChatRequest) GetEnabledMcpServices() []string {
 	return nil
 }
 
+func (x *ChatRequest) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
 // 对话响应
 type ChatResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -294,6 +317,7 @@ type ChatResponse struct {
 	AgentType     string                 `protobuf:"bytes,2,opt,name=agent_type,json=agentType,proto3" json:"agent_type,omitempty"` // 使用的Agent类型
 	Metadata      *ExecutionMetadata     `protobuf:"bytes,3,opt,name=metadata,proto3" json:"metadata,omitempty"`                    // 执行元数据
 	Ret           *BaseResponse          `protobuf:"bytes,4,opt,name=ret,proto3" json:"ret,omitempty"`
+	FinalResult   *FinalResult           `protobuf:"bytes,5,opt,name=final_result,json=finalResult,proto3" json:"final_result,omitempty"` // 结构化最终结果
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -356,31 +380,40 @@ func (x *ChatResponse) GetRet() *BaseResponse {
 	return nil
 }
 
-// 流式对话响应
-type ChatStreamResponse struct {
-	state         protoimpl.MessageState         `protogen:"open.v1"`
-	Type          ChatStreamResponse_MessageType `protobuf:"varint,1,opt,name=type,proto3,enum=api.agent.service.v1.ChatStreamResponse_MessageType" json:"type,omitempty"` // 消息类型
-	Content       string                         `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`                                                     // 消息内容
-	Step          int32                          `protobuf:"varint,3,opt,name=step,proto3" json:"step,omitempty"`                                                          // 当前步骤
-	Metadata      *ExecutionMetadata             `protobuf:"bytes,4,opt,name=metadata,proto3" json:"metadata,omitempty"`                                                   // 执行元数据
+func (x *ChatResponse) GetFinalResult() *FinalResult {
+	if x != nil {
+		return x.FinalResult
+	}
+	return nil
+}
+
+// 结构化最终结果
+type FinalResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Answer        string                 `protobuf:"bytes,1,opt,name=answer,proto3" json:"answer,omitempty"`                                    // 最终答案
+	Citations     []string               `protobuf:"bytes,2,rep,name=citations,proto3" json:"citations,omitempty"`                              // 引用来源
+	Confidence    float64                `protobuf:"fixed64,3,opt,name=confidence,proto3" json:"confidence,omitempty"`                          // 置信度 0~1，0 表示未给出
+	ChainTrace    []*ChainNodeTrace      `protobuf:"bytes,4,rep,name=chain_trace,json=chainTrace,proto3" json:"chain_trace,omitempty"`          // 链式 Agent 各节点的执行记录
+	SubRuns       []*SubRunTrace         `protobuf:"bytes,5,rep,name=sub_runs,json=subRuns,proto3" json:"sub_runs,omitempty"`                   // 计划步骤委派的子 Agent 运行记录
+	PlanRevisions []*PlanRevision        `protobuf:"bytes,6,rep,name=plan_revisions,json=planRevisions,proto3" json:"plan_revisions,omitempty"` // 计划的修订记录
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ChatStreamResponse) Reset() {
-	*x = ChatStreamResponse{}
+func (x *FinalResult) Reset() {
+	*x = FinalResult{}
 	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ChatStreamResponse) String() string {
+func (x *FinalResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ChatStreamResponse) ProtoMessage() {}
+func (*FinalResult) ProtoMessage() {}
 
-func (x *ChatStreamResponse) ProtoReflect() protoreflect.Message {
+func (x *FinalResult) ProtoReflect() protoreflect.Message {
 	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -392,65 +425,83 @@ func (x *ChatStreamResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ChatStreamResponse.ProtoReflect.Descriptor instead.
-func (*ChatStreamResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use FinalResult.ProtoReflect.Descriptor instead.
+func (*FinalResult) Descriptor() ([]byte, []int) {
 	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *ChatStreamResponse) GetType() ChatStreamResponse_MessageType {
+func (x *FinalResult) GetAnswer() string {
 	if x != nil {
-		return x.Type
+		return x.Answer
 	}
-	return ChatStreamResponse_THINKING
+	return ""
 }
 
-func (x *ChatStreamResponse) GetContent() string {
+func (x *FinalResult) GetCitations() []string {
 	if x != nil {
-		return x.Content
+		return x.Citations
 	}
-	return ""
+	return nil
 }
 
-func (x *ChatStreamResponse) GetStep() int32 {
+func (x *FinalResult) GetConfidence() float64 {
 	if x != nil {
-		return x.Step
+		return x.Confidence
 	}
 	return 0
 }
 
-func (x *ChatStreamResponse) GetMetadata() *ExecutionMetadata {
+func (x *FinalResult) GetChainTrace() []*ChainNodeTrace {
 	if x != nil {
-		return x.Metadata
+		return x.ChainTrace
 	}
 	return nil
 }
 
-// 执行元数据
-type ExecutionMetadata struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	TotalSteps      int32                  `protobuf:"varint,1,opt,name=total_steps,json=totalSteps,proto3" json:"total_steps,omitempty"`                  // 总步骤数
-	ToolsCalled     int32                  `protobuf:"varint,2,opt,name=tools_called,json=toolsCalled,proto3" json:"tools_called,omitempty"`               // 调用的工具数量
-	ToolNames       []string               `protobuf:"bytes,3,rep,name=tool_names,json=toolNames,proto3" json:"tool_names,omitempty"`                      // 使用的工具名称
-	ExecutionTimeMs int64                  `protobuf:"varint,4,opt,name=execution_time_ms,json=executionTimeMs,proto3" json:"execution_time_ms,omitempty"` // 执行时间（毫秒）
-	State           string                 `protobuf:"bytes,5,opt,name=state,proto3" json:"state,omitempty"`                                               // 执行状态
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+func (x *FinalResult) GetSubRuns() []*SubRunTrace {
+	if x != nil {
+		return x.SubRuns
+	}
+	return nil
 }
 
-func (x *ExecutionMetadata) Reset() {
-	*x = ExecutionMetadata{}
+func (x *FinalResult) GetPlanRevisions() []*PlanRevision {
+	if x != nil {
+		return x.PlanRevisions
+	}
+	return nil
+}
+
+// 链式 Agent 中一个节点的执行记录
+type ChainNodeTrace struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          string                 `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`                                // 节点名称
+	AgentType     string                 `protobuf:"bytes,2,opt,name=agent_type,json=agentType,proto3" json:"agent_type,omitempty"`     // 节点的 Agent 类型
+	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`                            // completed、failed 或 skipped
+	Input         string                 `protobuf:"bytes,4,opt,name=input,proto3" json:"input,omitempty"`                              // 节点输入
+	Output        string                 `protobuf:"bytes,5,opt,name=output,proto3" json:"output,omitempty"`                            // 节点输出（转换后）
+	Steps         int32                  `protobuf:"varint,6,opt,name=steps,proto3" json:"steps,omitempty"`                             // 节点执行的步数
+	State         string                 `protobuf:"bytes,7,opt,name=state,proto3" json:"state,omitempty"`                              // 节点执行器的结束状态
+	DurationMs    int64                  `protobuf:"varint,8,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"` // 节点耗时
+	Error         string                 `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`                              // 节点失败的原因
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChainNodeTrace) Reset() {
+	*x = ChainNodeTrace{}
 	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ExecutionMetadata) String() string {
+func (x *ChainNodeTrace) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ExecutionMetadata) ProtoMessage() {}
+func (*ChainNodeTrace) ProtoMessage() {}
 
-func (x *ExecutionMetadata) ProtoReflect() protoreflect.Message {
+func (x *ChainNodeTrace) ProtoReflect() protoreflect.Message {
 	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -462,69 +513,109 @@ func (x *ExecutionMetadata) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ExecutionMetadata.ProtoReflect.Descriptor instead.
-func (*ExecutionMetadata) Descriptor() ([]byte, []int) {
+// Deprecated: Use ChainNodeTrace.ProtoReflect.Descriptor instead.
+func (*ChainNodeTrace) Descriptor() ([]byte, []int) {
 	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *ExecutionMetadata) GetTotalSteps() int32 {
+func (x *ChainNodeTrace) GetNode() string {
 	if x != nil {
-		return x.TotalSteps
+		return x.Node
 	}
-	return 0
+	return ""
 }
 
-func (x *ExecutionMetadata) GetToolsCalled() int32 {
+func (x *ChainNodeTrace) GetAgentType() string {
 	if x != nil {
-		return x.ToolsCalled
+		return x.AgentType
 	}
-	return 0
+	return ""
 }
 
-func (x *ExecutionMetadata) GetToolNames() []string {
+func (x *ChainNodeTrace) GetStatus() string {
 	if x != nil {
-		return x.ToolNames
+		return x.Status
 	}
-	return nil
+	return ""
 }
 
-func (x *ExecutionMetadata) GetExecutionTimeMs() int64 {
+func (x *ChainNodeTrace) GetInput() string {
 	if x != nil {
-		return x.ExecutionTimeMs
+		return x.Input
+	}
+	return ""
+}
+
+func (x *ChainNodeTrace) GetOutput() string {
+	if x != nil {
+		return x.Output
+	}
+	return ""
+}
+
+func (x *ChainNodeTrace) GetSteps() int32 {
+	if x != nil {
+		return x.Steps
 	}
 	return 0
 }
 
-func (x *ExecutionMetadata) GetState() string {
+func (x *ChainNodeTrace) GetState() string {
 	if x != nil {
 		return x.State
 	}
 	return ""
 }
 
-// Agent类型列表响应
-type AgentTypesResponse struct {
+func (x *ChainNodeTrace) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *ChainNodeTrace) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// 计划步骤委派的子 Agent 的一次运行记录
+type SubRunTrace struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Ret           *BaseResponse          `protobuf:"bytes,1,opt,name=ret,proto3" json:"ret,omitempty"`
-	Types         []*AgentTypeInfo       `protobuf:"bytes,2,rep,name=types,proto3" json:"types,omitempty"`
+	StepId        int32                  `protobuf:"varint,1,opt,name=step_id,json=stepId,proto3" json:"step_id,omitempty"`         // 委派的计划步骤，调度者 Agent 为调度时的步骤
+	Agent         string                 `protobuf:"bytes,2,opt,name=agent,proto3" json:"agent,omitempty"`                          // 步骤的 agent 字段或调度的工作 Agent：Agent 类型或 Agent ID
+	AgentType     string                 `protobuf:"bytes,3,opt,name=agent_type,json=agentType,proto3" json:"agent_type,omitempty"` // 子 Agent 的类型
+	Depth         int32                  `protobuf:"varint,4,opt,name=depth,proto3" json:"depth,omitempty"`                         // 子 Agent 所在的层数，从 1 开始
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`                        // completed 或 failed
+	Input         string                 `protobuf:"bytes,6,opt,name=input,proto3" json:"input,omitempty"`
+	Output        string                 `protobuf:"bytes,7,opt,name=output,proto3" json:"output,omitempty"`
+	Error         string                 `protobuf:"bytes,8,opt,name=error,proto3" json:"error,omitempty"`
+	Steps         int32                  `protobuf:"varint,9,opt,name=steps,proto3" json:"steps,omitempty"`                                // 子 Agent 执行的步数
+	ToolsCalled   []string               `protobuf:"bytes,10,rep,name=tools_called,json=toolsCalled,proto3" json:"tools_called,omitempty"` // 子 Agent 调用的工具
+	State         string                 `protobuf:"bytes,11,opt,name=state,proto3" json:"state,omitempty"`                                // 子 Agent 执行器的结束状态
+	DurationMs    int64                  `protobuf:"varint,12,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	SubRuns       []*SubRunTrace         `protobuf:"bytes,13,rep,name=sub_runs,json=subRuns,proto3" json:"sub_runs,omitempty"` // 子 Agent 自身委派的子运行
+	Source        string                 `protobuf:"bytes,14,opt,name=source,proto3" json:"source,omitempty"`                  // 来源路径，与子 Agent 推送的流式消息的 source 一致
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AgentTypesResponse) Reset() {
-	*x = AgentTypesResponse{}
+func (x *SubRunTrace) Reset() {
+	*x = SubRunTrace{}
 	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AgentTypesResponse) String() string {
+func (x *SubRunTrace) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AgentTypesResponse) ProtoMessage() {}
+func (*SubRunTrace) ProtoMessage() {}
 
-func (x *AgentTypesResponse) ProtoReflect() protoreflect.Message {
+func (x *SubRunTrace) ProtoReflect() protoreflect.Message {
 	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -536,50 +627,139 @@ func (x *AgentTypesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AgentTypesResponse.ProtoReflect.Descriptor instead.
-func (*AgentTypesResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use SubRunTrace.ProtoReflect.Descriptor instead.
+func (*SubRunTrace) Descriptor() ([]byte, []int) {
 	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *AgentTypesResponse) GetRet() *BaseResponse {
+func (x *SubRunTrace) GetStepId() int32 {
 	if x != nil {
-		return x.Ret
+		return x.StepId
+	}
+	return 0
+}
+
+func (x *SubRunTrace) GetAgent() string {
+	if x != nil {
+		return x.Agent
+	}
+	return ""
+}
+
+func (x *SubRunTrace) GetAgentType() string {
+	if x != nil {
+		return x.AgentType
+	}
+	return ""
+}
+
+func (x *SubRunTrace) GetDepth() int32 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
+func (x *SubRunTrace) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *SubRunTrace) GetInput() string {
+	if x != nil {
+		return x.Input
+	}
+	return ""
+}
+
+func (x *SubRunTrace) GetOutput() string {
+	if x != nil {
+		return x.Output
+	}
+	return ""
+}
+
+func (x *SubRunTrace) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *SubRunTrace) GetSteps() int32 {
+	if x != nil {
+		return x.Steps
+	}
+	return 0
+}
+
+func (x *SubRunTrace) GetToolsCalled() []string {
+	if x != nil {
+		return x.ToolsCalled
 	}
 	return nil
 }
 
-func (x *AgentTypesResponse) GetTypes() []*AgentTypeInfo {
+func (x *SubRunTrace) GetState() string {
 	if x != nil {
-		return x.Types
+		return x.State
+	}
+	return ""
+}
+
+func (x *SubRunTrace) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *SubRunTrace) GetSubRuns() []*SubRunTrace {
+	if x != nil {
+		return x.SubRuns
 	}
 	return nil
 }
 
-// Agent类型信息
-type AgentTypeInfo struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Type          AgentType              `protobuf:"varint,1,opt,name=type,proto3,enum=api.agent.service.v1.AgentType" json:"type,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
-	Available     bool                   `protobuf:"varint,4,opt,name=available,proto3" json:"available,omitempty"`
+func (x *SubRunTrace) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+// 流式对话响应
+type ChatStreamResponse struct {
+	state         protoimpl.MessageState         `protogen:"open.v1"`
+	Type          ChatStreamResponse_MessageType `protobuf:"varint,1,opt,name=type,proto3,enum=api.agent.service.v1.ChatStreamResponse_MessageType" json:"type,omitempty"` // 消息类型
+	Content       string                         `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`                                                     // 消息内容
+	Step          int32                          `protobuf:"varint,3,opt,name=step,proto3" json:"step,omitempty"`                                                          // 当前步骤
+	Metadata      *ExecutionMetadata             `protobuf:"bytes,4,opt,name=metadata,proto3" json:"metadata,omitempty"`                                                   // 执行元数据
+	FinalResult   *FinalResult                   `protobuf:"bytes,5,opt,name=final_result,json=finalResult,proto3" json:"final_result,omitempty"`                          // 结构化最终结果（FINAL 消息）
+	Approval      *ApprovalInfo                  `protobuf:"bytes,6,opt,name=approval,proto3" json:"approval,omitempty"`                                                   // 待审批的工具调用（APPROVAL_REQUIRED 消息）
+	PlanStep      *PlanStepInfo                  `protobuf:"bytes,7,opt,name=plan_step,json=planStep,proto3" json:"plan_step,omitempty"`                                   // 计划步骤的状态（PLAN_STEP 消息）
+	Source        string                         `protobuf:"bytes,8,opt,name=source,proto3" json:"source,omitempty"`                                                       // 嵌套运行（子 Agent、工作 Agent）的来源路径，主运行为空；step 按来源单独编号
+	Depth         int32                          `protobuf:"varint,9,opt,name=depth,proto3" json:"depth,omitempty"`                                                        // 嵌套运行的层数，主运行为 0
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AgentTypeInfo) Reset() {
-	*x = AgentTypeInfo{}
+func (x *ChatStreamResponse) Reset() {
+	*x = ChatStreamResponse{}
 	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AgentTypeInfo) String() string {
+func (x *ChatStreamResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AgentTypeInfo) ProtoMessage() {}
+func (*ChatStreamResponse) ProtoMessage() {}
 
-func (x *AgentTypeInfo) ProtoReflect() protoreflect.Message {
+func (x *ChatStreamResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -591,62 +771,104 @@ func (x *AgentTypeInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AgentTypeInfo.ProtoReflect.Descriptor instead.
-func (*AgentTypeInfo) Descriptor() ([]byte, []int) {
+// Deprecated: Use ChatStreamResponse.ProtoReflect.Descriptor instead.
+func (*ChatStreamResponse) Descriptor() ([]byte, []int) {
 	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *AgentTypeInfo) GetType() AgentType {
+func (x *ChatStreamResponse) GetType() ChatStreamResponse_MessageType {
 	if x != nil {
 		return x.Type
 	}
-	return AgentType_REACT
+	return ChatStreamResponse_THINKING
 }
 
-func (x *AgentTypeInfo) GetName() string {
+func (x *ChatStreamResponse) GetContent() string {
 	if x != nil {
-		return x.Name
+		return x.Content
 	}
 	return ""
 }
 
-func (x *AgentTypeInfo) GetDescription() string {
+func (x *ChatStreamResponse) GetStep() int32 {
 	if x != nil {
-		return x.Description
+		return x.Step
+	}
+	return 0
+}
+
+func (x *ChatStreamResponse) GetMetadata() *ExecutionMetadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *ChatStreamResponse) GetFinalResult() *FinalResult {
+	if x != nil {
+		return x.FinalResult
+	}
+	return nil
+}
+
+func (x *ChatStreamResponse) GetApproval() *ApprovalInfo {
+	if x != nil {
+		return x.Approval
+	}
+	return nil
+}
+
+func (x *ChatStreamResponse) GetPlanStep() *PlanStepInfo {
+	if x != nil {
+		return x.PlanStep
+	}
+	return nil
+}
+
+func (x *ChatStreamResponse) GetSource() string {
+	if x != nil {
+		return x.Source
 	}
 	return ""
 }
 
-func (x *AgentTypeInfo) GetAvailable() bool {
+func (x *ChatStreamResponse) GetDepth() int32 {
 	if x != nil {
-		return x.Available
+		return x.Depth
 	}
-	return false
+	return 0
 }
 
-// 工具列表响应
-type ToolsResponse struct {
+// 计划步骤的状态变化：pending → executing → completed / failed，依赖失败的步骤为 skipped
+type PlanStepInfo struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Ret           *BaseResponse          `protobuf:"bytes,1,opt,name=ret,proto3" json:"ret,omitempty"`
-	Tools         []*ToolInfo            `protobuf:"bytes,2,rep,name=tools,proto3" json:"tools,omitempty"`
+	StepId        int32                  `protobuf:"varint,1,opt,name=step_id,json=stepId,proto3" json:"step_id,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Tool          string                 `protobuf:"bytes,3,opt,name=tool,proto3" json:"tool,omitempty"`
+	Dependencies  []int32                `protobuf:"varint,4,rep,packed,name=dependencies,proto3" json:"dependencies,omitempty"` // 依赖的步骤ID
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`                     // pending、executing、completed、failed、skipped
+	Result        string                 `protobuf:"bytes,6,opt,name=result,proto3" json:"result,omitempty"`                     // completed 时的结果
+	Error         string                 `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`                       // failed 时的错误，skipped 时的跳过原因
+	Agent         string                 `protobuf:"bytes,8,opt,name=agent,proto3" json:"agent,omitempty"`                       // 委派执行该步骤的子 Agent
+	Input         string                 `protobuf:"bytes,9,opt,name=input,proto3" json:"input,omitempty"`                       // 步骤输入（未替换依赖引用）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ToolsResponse) Reset() {
-	*x = ToolsResponse{}
+func (x *PlanStepInfo) Reset() {
+	*x = PlanStepInfo{}
 	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ToolsResponse) String() string {
+func (x *PlanStepInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ToolsResponse) ProtoMessage() {}
+func (*PlanStepInfo) ProtoMessage() {}
 
-func (x *ToolsResponse) ProtoReflect() protoreflect.Message {
+func (x *PlanStepInfo) ProtoReflect() protoreflect.Message {
 	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -658,51 +880,1615 @@ func (x *ToolsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ToolsResponse.ProtoReflect.Descriptor instead.
-func (*ToolsResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use PlanStepInfo.ProtoReflect.Descriptor instead.
+func (*PlanStepInfo) Descriptor() ([]byte, []int) {
 	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *ToolsResponse) GetRet() *BaseResponse {
+func (x *PlanStepInfo) GetStepId() int32 {
+	if x != nil {
+		return x.StepId
+	}
+	return 0
+}
+
+func (x *PlanStepInfo) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *PlanStepInfo) GetTool() string {
+	if x != nil {
+		return x.Tool
+	}
+	return ""
+}
+
+func (x *PlanStepInfo) GetDependencies() []int32 {
+	if x != nil {
+		return x.Dependencies
+	}
+	return nil
+}
+
+func (x *PlanStepInfo) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *PlanStepInfo) GetResult() string {
+	if x != nil {
+		return x.Result
+	}
+	return ""
+}
+
+func (x *PlanStepInfo) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *PlanStepInfo) GetAgent() string {
+	if x != nil {
+		return x.Agent
+	}
+	return ""
+}
+
+func (x *PlanStepInfo) GetInput() string {
+	if x != nil {
+		return x.Input
+	}
+	return ""
+}
+
+// 计划的一次修订：评估步骤结果后重试步骤、插入步骤或重新规划剩余步骤
+type PlanRevision struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Revision       int32                  `protobuf:"varint,1,opt,name=revision,proto3" json:"revision,omitempty"`                                          // 从 1 开始
+	Decision       string                 `protobuf:"bytes,2,opt,name=decision,proto3" json:"decision,omitempty"`                                           // retry、insert 或 replan
+	Reason         string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`                                               // 修订的理由
+	Score          float64                `protobuf:"fixed64,4,opt,name=score,proto3" json:"score,omitempty"`                                               // 评估得分 0~1，步骤失败触发的重新规划为 0
+	EvaluatedSteps []int32                `protobuf:"varint,5,rep,packed,name=evaluated_steps,json=evaluatedSteps,proto3" json:"evaluated_steps,omitempty"` // 触发修订的步骤
+	RetriedStep    int32                  `protobuf:"varint,6,opt,name=retried_step,json=retriedStep,proto3" json:"retried_step,omitempty"`                 // retry 的步骤
+	RetryInput     string                 `protobuf:"bytes,7,opt,name=retry_input,json=retryInput,proto3" json:"retry_input,omitempty"`                     // retry 的新输入
+	AddedSteps     []*PlanStepInfo        `protobuf:"bytes,8,rep,name=added_steps,json=addedSteps,proto3" json:"added_steps,omitempty"`                     // 新增的步骤
+	RemovedSteps   []*PlanStepInfo        `protobuf:"bytes,9,rep,name=removed_steps,json=removedSteps,proto3" json:"removed_steps,omitempty"`               // replan 移除的未完成步骤
+	CreatedAt      string                 `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *PlanRevision) Reset() {
+	*x = PlanRevision{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlanRevision) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlanRevision) ProtoMessage() {}
+
+func (x *PlanRevision) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlanRevision.ProtoReflect.Descriptor instead.
+func (*PlanRevision) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *PlanRevision) GetRevision() int32 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+func (x *PlanRevision) GetDecision() string {
+	if x != nil {
+		return x.Decision
+	}
+	return ""
+}
+
+func (x *PlanRevision) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *PlanRevision) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *PlanRevision) GetEvaluatedSteps() []int32 {
+	if x != nil {
+		return x.EvaluatedSteps
+	}
+	return nil
+}
+
+func (x *PlanRevision) GetRetriedStep() int32 {
+	if x != nil {
+		return x.RetriedStep
+	}
+	return 0
+}
+
+func (x *PlanRevision) GetRetryInput() string {
+	if x != nil {
+		return x.RetryInput
+	}
+	return ""
+}
+
+func (x *PlanRevision) GetAddedSteps() []*PlanStepInfo {
+	if x != nil {
+		return x.AddedSteps
+	}
+	return nil
+}
+
+func (x *PlanRevision) GetRemovedSteps() []*PlanStepInfo {
+	if x != nil {
+		return x.RemovedSteps
+	}
+	return nil
+}
+
+func (x *PlanRevision) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+// 执行元数据
+type ExecutionMetadata struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	TotalSteps       int32                  `protobuf:"varint,1,opt,name=total_steps,json=totalSteps,proto3" json:"total_steps,omitempty"`                   // 总步骤数
+	ToolsCalled      int32                  `protobuf:"varint,2,opt,name=tools_called,json=toolsCalled,proto3" json:"tools_called,omitempty"`                // 调用的工具数量
+	ToolNames        []string               `protobuf:"bytes,3,rep,name=tool_names,json=toolNames,proto3" json:"tool_names,omitempty"`                       // 使用的工具名称
+	ExecutionTimeMs  int64                  `protobuf:"varint,4,opt,name=execution_time_ms,json=executionTimeMs,proto3" json:"execution_time_ms,omitempty"`  // 执行时间（毫秒）
+	State            string                 `protobuf:"bytes,5,opt,name=state,proto3" json:"state,omitempty"`                                                // 执行状态
+	PromptTokens     int64                  `protobuf:"varint,6,opt,name=prompt_tokens,json=promptTokens,proto3" json:"prompt_tokens,omitempty"`             // 输入 token 数（含计划、总结等所有模型调用）
+	CompletionTokens int64                  `protobuf:"varint,7,opt,name=completion_tokens,json=completionTokens,proto3" json:"completion_tokens,omitempty"` // 输出 token 数
+	TotalTokens      int64                  `protobuf:"varint,8,opt,name=total_tokens,json=totalTokens,proto3" json:"total_tokens,omitempty"`                // 总 token 数
+	Cost             float64                `protobuf:"fixed64,9,opt,name=cost,proto3" json:"cost,omitempty"`                                                // 按配置单价计算的费用
+	RunId            string                 `protobuf:"bytes,10,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`                                  // 运行ID，可用于查询、恢复或取消运行
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ExecutionMetadata) Reset() {
+	*x = ExecutionMetadata{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecutionMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecutionMetadata) ProtoMessage() {}
+
+func (x *ExecutionMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecutionMetadata.ProtoReflect.Descriptor instead.
+func (*ExecutionMetadata) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ExecutionMetadata) GetTotalSteps() int32 {
+	if x != nil {
+		return x.TotalSteps
+	}
+	return 0
+}
+
+func (x *ExecutionMetadata) GetToolsCalled() int32 {
+	if x != nil {
+		return x.ToolsCalled
+	}
+	return 0
+}
+
+func (x *ExecutionMetadata) GetToolNames() []string {
+	if x != nil {
+		return x.ToolNames
+	}
+	return nil
+}
+
+func (x *ExecutionMetadata) GetExecutionTimeMs() int64 {
+	if x != nil {
+		return x.ExecutionTimeMs
+	}
+	return 0
+}
+
+func (x *ExecutionMetadata) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *ExecutionMetadata) GetPromptTokens() int64 {
+	if x != nil {
+		return x.PromptTokens
+	}
+	return 0
+}
+
+func (x *ExecutionMetadata) GetCompletionTokens() int64 {
+	if x != nil {
+		return x.CompletionTokens
+	}
+	return 0
+}
+
+func (x *ExecutionMetadata) GetTotalTokens() int64 {
+	if x != nil {
+		return x.TotalTokens
+	}
+	return 0
+}
+
+func (x *ExecutionMetadata) GetCost() float64 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+func (x *ExecutionMetadata) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
+// Agent类型列表响应
+type AgentTypesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ret           *BaseResponse          `protobuf:"bytes,1,opt,name=ret,proto3" json:"ret,omitempty"`
+	Types         []*AgentTypeInfo       `protobuf:"bytes,2,rep,name=types,proto3" json:"types,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentTypesResponse) Reset() {
+	*x = AgentTypesResponse{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentTypesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentTypesResponse) ProtoMessage() {}
+
+func (x *AgentTypesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentTypesResponse.ProtoReflect.Descriptor instead.
+func (*AgentTypesResponse) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *AgentTypesResponse) GetRet() *BaseResponse {
+	if x != nil {
+		return x.Ret
+	}
+	return nil
+}
+
+func (x *AgentTypesResponse) GetTypes() []*AgentTypeInfo {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+// Agent类型信息
+type AgentTypeInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          AgentType              `protobuf:"varint,1,opt,name=type,proto3,enum=api.agent.service.v1.AgentType" json:"type,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Available     bool                   `protobuf:"varint,4,opt,name=available,proto3" json:"available,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentTypeInfo) Reset() {
+	*x = AgentTypeInfo{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentTypeInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentTypeInfo) ProtoMessage() {}
+
+func (x *AgentTypeInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentTypeInfo.ProtoReflect.Descriptor instead.
+func (*AgentTypeInfo) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *AgentTypeInfo) GetType() AgentType {
+	if x != nil {
+		return x.Type
+	}
+	return AgentType_REACT
+}
+
+func (x *AgentTypeInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AgentTypeInfo) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *AgentTypeInfo) GetAvailable() bool {
+	if x != nil {
+		return x.Available
+	}
+	return false
+}
+
+// 工具列表响应
+type ToolsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ret           *BaseResponse          `protobuf:"bytes,1,opt,name=ret,proto3" json:"ret,omitempty"`
+	Tools         []*ToolInfo            `protobuf:"bytes,2,rep,name=tools,proto3" json:"tools,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToolsResponse) Reset() {
+	*x = ToolsResponse{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToolsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolsResponse) ProtoMessage() {}
+
+func (x *ToolsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolsResponse.ProtoReflect.Descriptor instead.
+func (*ToolsResponse) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ToolsResponse) GetRet() *BaseResponse {
+	if x != nil {
+		return x.Ret
+	}
+	return nil
+}
+
+func (x *ToolsResponse) GetTools() []*ToolInfo {
+	if x != nil {
+		return x.Tools
+	}
+	return nil
+}
+
+// 工具信息
+type ToolInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`                               // Normal 或 Mcp
+	McpService    string                 `protobuf:"bytes,4,opt,name=mcp_service,json=mcpService,proto3" json:"mcp_service,omitempty"` // 所属的MCP服务名称（如果是MCP工具）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToolInfo) Reset() {
+	*x = ToolInfo{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToolInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolInfo) ProtoMessage() {}
+
+func (x *ToolInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolInfo.ProtoReflect.Descriptor instead.
+func (*ToolInfo) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ToolInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ToolInfo) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *ToolInfo) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ToolInfo) GetMcpService() string {
+	if x != nil {
+		return x.McpService
+	}
+	return ""
+}
+
+// MCP 服务请求
+type MCPServiceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`         // MCP服务名称
+	Endpoint      string                 `protobuf:"bytes,2,opt,name=endpoint,proto3" json:"endpoint,omitempty"` // MCP服务端点URL
+	ClientType    string                 `protobuf:"bytes,3,opt,name=clientType,proto3" json:"clientType,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MCPServiceRequest) Reset() {
+	*x = MCPServiceRequest{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MCPServiceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MCPServiceRequest) ProtoMessage() {}
+
+func (x *MCPServiceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MCPServiceRequest.ProtoReflect.Descriptor instead.
+func (*MCPServiceRequest) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *MCPServiceRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *MCPServiceRequest) GetEndpoint() string {
+	if x != nil {
+		return x.Endpoint
+	}
+	return ""
+}
+
+func (x *MCPServiceRequest) GetClientType() string {
+	if x != nil {
+		return x.ClientType
+	}
+	return ""
+}
+
+// MCP 服务响应
+type MCPServiceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ret           *BaseResponse          `protobuf:"bytes,1,opt,name=ret,proto3" json:"ret,omitempty"`
+	Service       *MCPServiceInfo        `protobuf:"bytes,2,opt,name=service,proto3" json:"service,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MCPServiceResponse) Reset() {
+	*x = MCPServiceResponse{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MCPServiceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MCPServiceResponse) ProtoMessage() {}
+
+func (x *MCPServiceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MCPServiceResponse.ProtoReflect.Descriptor instead.
+func (*MCPServiceResponse) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *MCPServiceResponse) GetRet() *BaseResponse {
+	if x != nil {
+		return x.Ret
+	}
+	return nil
+}
+
+func (x *MCPServiceResponse) GetService() *MCPServiceInfo {
+	if x != nil {
+		return x.Service
+	}
+	return nil
+}
+
+// MCP 服务列表响应
+type MCPServicesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ret           *BaseResponse          `protobuf:"bytes,1,opt,name=ret,proto3" json:"ret,omitempty"`
+	Services      []*MCPServiceInfo      `protobuf:"bytes,2,rep,name=services,proto3" json:"services,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MCPServicesResponse) Reset() {
+	*x = MCPServicesResponse{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MCPServicesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MCPServicesResponse) ProtoMessage() {}
+
+func (x *MCPServicesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MCPServicesResponse.ProtoReflect.Descriptor instead.
+func (*MCPServicesResponse) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *MCPServicesResponse) GetRet() *BaseResponse {
+	if x != nil {
+		return x.Ret
+	}
+	return nil
+}
+
+func (x *MCPServicesResponse) GetServices() []*MCPServiceInfo {
+	if x != nil {
+		return x.Services
+	}
+	return nil
+}
+
+// MCP 服务信息
+type MCPServiceInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Endpoint      string                 `protobuf:"bytes,2,opt,name=endpoint,proto3" json:"endpoint,omitempty"`
+	Active        bool                   `protobuf:"varint,3,opt,name=active,proto3" json:"active,omitempty"`
+	ToolCount     int32                  `protobuf:"varint,4,opt,name=tool_count,json=toolCount,proto3" json:"tool_count,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	LastRefresh   string                 `protobuf:"bytes,6,opt,name=last_refresh,json=lastRefresh,proto3" json:"last_refresh,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MCPServiceInfo) Reset() {
+	*x = MCPServiceInfo{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MCPServiceInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MCPServiceInfo) ProtoMessage() {}
+
+func (x *MCPServiceInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MCPServiceInfo.ProtoReflect.Descriptor instead.
+func (*MCPServiceInfo) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *MCPServiceInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *MCPServiceInfo) GetEndpoint() string {
+	if x != nil {
+		return x.Endpoint
+	}
+	return ""
+}
+
+func (x *MCPServiceInfo) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+func (x *MCPServiceInfo) GetToolCount() int32 {
+	if x != nil {
+		return x.ToolCount
+	}
+	return 0
+}
+
+func (x *MCPServiceInfo) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *MCPServiceInfo) GetLastRefresh() string {
+	if x != nil {
+		return x.LastRefresh
+	}
+	return ""
+}
+
+type MCPServiceWithIdInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Endpoint      string                 `protobuf:"bytes,3,opt,name=endpoint,proto3" json:"endpoint,omitempty"`
+	Active        bool                   `protobuf:"varint,4,opt,name=active,proto3" json:"active,omitempty"`
+	ToolCount     int32                  `protobuf:"varint,5,opt,name=tool_count,json=toolCount,proto3" json:"tool_count,omitempty"`
+	Description   string                 `protobuf:"bytes,6,opt,name=description,proto3" json:"description,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	LastRefresh   string                 `protobuf:"bytes,8,opt,name=last_refresh,json=lastRefresh,proto3" json:"last_refresh,omitempty"`
+	ClientType    string                 `protobuf:"bytes,9,opt,name=client_type,json=clientType,proto3" json:"client_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MCPServiceWithIdInfo) Reset() {
+	*x = MCPServiceWithIdInfo{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MCPServiceWithIdInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MCPServiceWithIdInfo) ProtoMessage() {}
+
+func (x *MCPServiceWithIdInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MCPServiceWithIdInfo.ProtoReflect.Descriptor instead.
+func (*MCPServiceWithIdInfo) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *MCPServiceWithIdInfo) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *MCPServiceWithIdInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *MCPServiceWithIdInfo) GetEndpoint() string {
+	if x != nil {
+		return x.Endpoint
+	}
+	return ""
+}
+
+func (x *MCPServiceWithIdInfo) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+func (x *MCPServiceWithIdInfo) GetToolCount() int32 {
+	if x != nil {
+		return x.ToolCount
+	}
+	return 0
+}
+
+func (x *MCPServiceWithIdInfo) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *MCPServiceWithIdInfo) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *MCPServiceWithIdInfo) GetLastRefresh() string {
+	if x != nil {
+		return x.LastRefresh
+	}
+	return ""
+}
+
+func (x *MCPServiceWithIdInfo) GetClientType() string {
+	if x != nil {
+		return x.ClientType
+	}
+	return ""
+}
+
+type MCPServicesWithIdResponse struct {
+	state         protoimpl.MessageState  `protogen:"open.v1"`
+	Ret           *BaseResponse           `protobuf:"bytes,1,opt,name=ret,proto3" json:"ret,omitempty"`
+	Services      []*MCPServiceWithIdInfo `protobuf:"bytes,2,rep,name=services,proto3" json:"services,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MCPServicesWithIdResponse) Reset() {
+	*x = MCPServicesWithIdResponse{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MCPServicesWithIdResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MCPServicesWithIdResponse) ProtoMessage() {}
+
+func (x *MCPServicesWithIdResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MCPServicesWithIdResponse.ProtoReflect.Descriptor instead.
+func (*MCPServicesWithIdResponse) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *MCPServicesWithIdResponse) GetRet() *BaseResponse {
+	if x != nil {
+		return x.Ret
+	}
+	return nil
+}
+
+func (x *MCPServicesWithIdResponse) GetServices() []*MCPServiceWithIdInfo {
+	if x != nil {
+		return x.Services
+	}
+	return nil
+}
+
+type MCPServiceToolsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MCPServiceToolsRequest) Reset() {
+	*x = MCPServiceToolsRequest{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MCPServiceToolsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MCPServiceToolsRequest) ProtoMessage() {}
+
+func (x *MCPServiceToolsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MCPServiceToolsRequest.ProtoReflect.Descriptor instead.
+func (*MCPServiceToolsRequest) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *MCPServiceToolsRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type MCPServiceToolInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	InputSchema   *structpb.Struct       `protobuf:"bytes,4,opt,name=input_schema,json=inputSchema,proto3" json:"input_schema,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MCPServiceToolInfo) Reset() {
+	*x = MCPServiceToolInfo{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MCPServiceToolInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MCPServiceToolInfo) ProtoMessage() {}
+
+func (x *MCPServiceToolInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MCPServiceToolInfo.ProtoReflect.Descriptor instead.
+func (*MCPServiceToolInfo) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *MCPServiceToolInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *MCPServiceToolInfo) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *MCPServiceToolInfo) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *MCPServiceToolInfo) GetInputSchema() *structpb.Struct {
+	if x != nil {
+		return x.InputSchema
+	}
+	return nil
+}
+
+type MCPServiceToolsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ret           *BaseResponse          `protobuf:"bytes,1,opt,name=ret,proto3" json:"ret,omitempty"`
+	Tools         []*MCPServiceToolInfo  `protobuf:"bytes,2,rep,name=tools,proto3" json:"tools,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MCPServiceToolsResponse) Reset() {
+	*x = MCPServiceToolsResponse{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MCPServiceToolsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MCPServiceToolsResponse) ProtoMessage() {}
+
+func (x *MCPServiceToolsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MCPServiceToolsResponse.ProtoReflect.Descriptor instead.
+func (*MCPServiceToolsResponse) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *MCPServiceToolsResponse) GetRet() *BaseResponse {
+	if x != nil {
+		return x.Ret
+	}
+	return nil
+}
+
+func (x *MCPServiceToolsResponse) GetTools() []*MCPServiceToolInfo {
+	if x != nil {
+		return x.Tools
+	}
+	return nil
+}
+
+// Agent 配置请求
+type AgentConfigRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`                                                                                  // Agent ID（更新时需要）
+	Name             string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`                                                                               // Agent名称
+	Framework        string                 `protobuf:"bytes,3,opt,name=framework,proto3" json:"framework,omitempty"`                                                                     // 框架类型: react, plan, chain, sql, elasticsearch
+	Description      string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`                                                                 // Agent描述
+	SystemPrompt     string                 `protobuf:"bytes,5,opt,name=system_prompt,json=systemPrompt,proto3" json:"system_prompt,omitempty"`                                           // 系统提示词
+	MaxSteps         int32                  `protobuf:"varint,6,opt,name=max_steps,json=maxSteps,proto3" json:"max_steps,omitempty"`                                                      // 最大执行步数
+	Model            string                 `protobuf:"bytes,7,opt,name=model,proto3" json:"model,omitempty"`                                                                             // 默认模型
+	McpServices      []string               `protobuf:"bytes,8,rep,name=mcp_services,json=mcpServices,proto3" json:"mcp_services,omitempty"`                                              // 绑定的MCP服务名称列表
+	Config           map[string]string      `protobuf:"bytes,9,rep,name=config,proto3" json:"config,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // 其他配置
+	ConnectionConfig string                 `protobuf:"bytes,10,opt,name=connection_config,json=connectionConfig,proto3" json:"connection_config,omitempty"`                              // 连接配置（JSON字符串）
+	ConfigJson       string                 `protobuf:"bytes,11,opt,name=config_json,json=configJson,proto3" json:"config_json,omitempty"`                                                // 运行时配置（JSON字符串，优先于 config）
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *AgentConfigRequest) Reset() {
+	*x = AgentConfigRequest{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentConfigRequest) ProtoMessage() {}
+
+func (x *AgentConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentConfigRequest.ProtoReflect.Descriptor instead.
+func (*AgentConfigRequest) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *AgentConfigRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *AgentConfigRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AgentConfigRequest) GetFramework() string {
+	if x != nil {
+		return x.Framework
+	}
+	return ""
+}
+
+func (x *AgentConfigRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *AgentConfigRequest) GetSystemPrompt() string {
+	if x != nil {
+		return x.SystemPrompt
+	}
+	return ""
+}
+
+func (x *AgentConfigRequest) GetMaxSteps() int32 {
+	if x != nil {
+		return x.MaxSteps
+	}
+	return 0
+}
+
+func (x *AgentConfigRequest) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *AgentConfigRequest) GetMcpServices() []string {
+	if x != nil {
+		return x.McpServices
+	}
+	return nil
+}
+
+func (x *AgentConfigRequest) GetConfig() map[string]string {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+func (x *AgentConfigRequest) GetConnectionConfig() string {
+	if x != nil {
+		return x.ConnectionConfig
+	}
+	return ""
+}
+
+func (x *AgentConfigRequest) GetConfigJson() string {
+	if x != nil {
+		return x.ConfigJson
+	}
+	return ""
+}
+
+// Agent 配置响应
+type AgentConfigResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ret           *BaseResponse          `protobuf:"bytes,1,opt,name=ret,proto3" json:"ret,omitempty"`
+	Agent         *AgentConfig           `protobuf:"bytes,2,opt,name=agent,proto3" json:"agent,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentConfigResponse) Reset() {
+	*x = AgentConfigResponse{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentConfigResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentConfigResponse) ProtoMessage() {}
+
+func (x *AgentConfigResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentConfigResponse.ProtoReflect.Descriptor instead.
+func (*AgentConfigResponse) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *AgentConfigResponse) GetRet() *BaseResponse {
+	if x != nil {
+		return x.Ret
+	}
+	return nil
+}
+
+func (x *AgentConfigResponse) GetAgent() *AgentConfig {
+	if x != nil {
+		return x.Agent
+	}
+	return nil
+}
+
+// Agent 删除请求
+type AgentDeleteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentDeleteRequest) Reset() {
+	*x = AgentDeleteRequest{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentDeleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentDeleteRequest) ProtoMessage() {}
+
+func (x *AgentDeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentDeleteRequest.ProtoReflect.Descriptor instead.
+func (*AgentDeleteRequest) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *AgentDeleteRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+// Agent 获取请求
+type AgentGetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentGetRequest) Reset() {
+	*x = AgentGetRequest{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentGetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentGetRequest) ProtoMessage() {}
+
+func (x *AgentGetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentGetRequest.ProtoReflect.Descriptor instead.
+func (*AgentGetRequest) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *AgentGetRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+// Agent 列表响应
+type AgentListResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ret           *BaseResponse          `protobuf:"bytes,1,opt,name=ret,proto3" json:"ret,omitempty"`
+	Agents        []*AgentConfig         `protobuf:"bytes,2,rep,name=agents,proto3" json:"agents,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentListResponse) Reset() {
+	*x = AgentListResponse{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentListResponse) ProtoMessage() {}
+
+func (x *AgentListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentListResponse.ProtoReflect.Descriptor instead.
+func (*AgentListResponse) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *AgentListResponse) GetRet() *BaseResponse {
+	if x != nil {
+		return x.Ret
+	}
+	return nil
+}
+
+func (x *AgentListResponse) GetAgents() []*AgentConfig {
+	if x != nil {
+		return x.Agents
+	}
+	return nil
+}
+
+// Agent 配置信息
+type AgentConfig struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name             string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Framework        string                 `protobuf:"bytes,3,opt,name=framework,proto3" json:"framework,omitempty"`
+	Description      string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	SystemPrompt     string                 `protobuf:"bytes,5,opt,name=system_prompt,json=systemPrompt,proto3" json:"system_prompt,omitempty"`
+	MaxSteps         int32                  `protobuf:"varint,6,opt,name=max_steps,json=maxSteps,proto3" json:"max_steps,omitempty"`
+	Model            string                 `protobuf:"bytes,7,opt,name=model,proto3" json:"model,omitempty"`
+	McpServices      []string               `protobuf:"bytes,8,rep,name=mcp_services,json=mcpServices,proto3" json:"mcp_services,omitempty"`
+	CreatedAt        string                 `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt        string                 `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	IsActive         bool                   `protobuf:"varint,11,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	ConnectionConfig string                 `protobuf:"bytes,12,opt,name=connection_config,json=connectionConfig,proto3" json:"connection_config,omitempty"` // 连接配置（JSON字符串）
+	ConfigJson       string                 `protobuf:"bytes,13,opt,name=config_json,json=configJson,proto3" json:"config_json,omitempty"`                   // 运行时配置（JSON字符串）
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *AgentConfig) Reset() {
+	*x = AgentConfig{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentConfig) ProtoMessage() {}
+
+func (x *AgentConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentConfig.ProtoReflect.Descriptor instead.
+func (*AgentConfig) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *AgentConfig) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *AgentConfig) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AgentConfig) GetFramework() string {
+	if x != nil {
+		return x.Framework
+	}
+	return ""
+}
+
+func (x *AgentConfig) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *AgentConfig) GetSystemPrompt() string {
+	if x != nil {
+		return x.SystemPrompt
+	}
+	return ""
+}
+
+func (x *AgentConfig) GetMaxSteps() int32 {
+	if x != nil {
+		return x.MaxSteps
+	}
+	return 0
+}
+
+func (x *AgentConfig) GetModel() string {
 	if x != nil {
-		return x.Ret
+		return x.Model
 	}
-	return nil
+	return ""
 }
 
-func (x *ToolsResponse) GetTools() []*ToolInfo {
+func (x *AgentConfig) GetMcpServices() []string {
 	if x != nil {
-		return x.Tools
+		return x.McpServices
 	}
 	return nil
 }
 
-// 工具信息
-type ToolInfo struct {
+func (x *AgentConfig) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *AgentConfig) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+func (x *AgentConfig) GetIsActive() bool {
+	if x != nil {
+		return x.IsActive
+	}
+	return false
+}
+
+func (x *AgentConfig) GetConnectionConfig() string {
+	if x != nil {
+		return x.ConnectionConfig
+	}
+	return ""
+}
+
+func (x *AgentConfig) GetConfigJson() string {
+	if x != nil {
+		return x.ConfigJson
+	}
+	return ""
+}
+
+// 会话创建请求
+type SessionRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
-	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`                               // Normal 或 Mcp
-	McpService    string                 `protobuf:"bytes,4,opt,name=mcp_service,json=mcpService,proto3" json:"mcp_service,omitempty"` // 所属的MCP服务名称（如果是MCP工具）
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`                           // 会话ID（可选，为空时自动生成）
+	AgentId       int32                  `protobuf:"varint,2,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"` // 所属Agent ID
+	Title         string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`                     // 会话标题
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ToolInfo) Reset() {
-	*x = ToolInfo{}
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[8]
+func (x *SessionRequest) Reset() {
+	*x = SessionRequest{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ToolInfo) String() string {
+func (x *SessionRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ToolInfo) ProtoMessage() {}
+func (*SessionRequest) ProtoMessage() {}
 
-func (x *ToolInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[8]
+func (x *SessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -713,64 +2499,55 @@ func (x *ToolInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ToolInfo.ProtoReflect.Descriptor instead.
-func (*ToolInfo) Descriptor() ([]byte, []int) {
-	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{8}
-}
-
-func (x *ToolInfo) GetName() string {
-	if x != nil {
-		return x.Name
-	}
-	return ""
+// Deprecated: Use SessionRequest.ProtoReflect.Descriptor instead.
+func (*SessionRequest) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{29}
 }
 
-func (x *ToolInfo) GetDescription() string {
+func (x *SessionRequest) GetId() string {
 	if x != nil {
-		return x.Description
+		return x.Id
 	}
 	return ""
 }
 
-func (x *ToolInfo) GetType() string {
+func (x *SessionRequest) GetAgentId() int32 {
 	if x != nil {
-		return x.Type
+		return x.AgentId
 	}
-	return ""
+	return 0
 }
 
-func (x *ToolInfo) GetMcpService() string {
+func (x *SessionRequest) GetTitle() string {
 	if x != nil {
-		return x.McpService
+		return x.Title
 	}
 	return ""
 }
 
-// MCP 服务请求
-type MCPServiceRequest struct {
+// 会话列表请求
+type SessionListRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`         // MCP服务名称
-	Endpoint      string                 `protobuf:"bytes,2,opt,name=endpoint,proto3" json:"endpoint,omitempty"` // MCP服务端点URL
-	ClientType    string                 `protobuf:"bytes,3,opt,name=clientType,proto3" json:"clientType,omitempty"`
+	AgentId       int32                  `protobuf:"varint,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"` // 按Agent过滤（可选）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *MCPServiceRequest) Reset() {
-	*x = MCPServiceRequest{}
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[9]
+func (x *SessionListRequest) Reset() {
+	*x = SessionListRequest{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MCPServiceRequest) String() string {
+func (x *SessionListRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MCPServiceRequest) ProtoMessage() {}
+func (*SessionListRequest) ProtoMessage() {}
 
-func (x *MCPServiceRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[9]
+func (x *SessionListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -781,56 +2558,86 @@ func (x *MCPServiceRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MCPServiceRequest.ProtoReflect.Descriptor instead.
-func (*MCPServiceRequest) Descriptor() ([]byte, []int) {
-	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use SessionListRequest.ProtoReflect.Descriptor instead.
+func (*SessionListRequest) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{30}
 }
 
-func (x *MCPServiceRequest) GetName() string {
+func (x *SessionListRequest) GetAgentId() int32 {
 	if x != nil {
-		return x.Name
+		return x.AgentId
 	}
-	return ""
+	return 0
 }
 
-func (x *MCPServiceRequest) GetEndpoint() string {
+// 会话获取请求
+type SessionGetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SessionGetRequest) Reset() {
+	*x = SessionGetRequest{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionGetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionGetRequest) ProtoMessage() {}
+
+func (x *SessionGetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[31]
 	if x != nil {
-		return x.Endpoint
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *MCPServiceRequest) GetClientType() string {
+// Deprecated: Use SessionGetRequest.ProtoReflect.Descriptor instead.
+func (*SessionGetRequest) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *SessionGetRequest) GetId() string {
 	if x != nil {
-		return x.ClientType
+		return x.Id
 	}
 	return ""
 }
 
-// MCP 服务响应
-type MCPServiceResponse struct {
+// 会话删除请求
+type SessionDeleteRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Ret           *BaseResponse          `protobuf:"bytes,1,opt,name=ret,proto3" json:"ret,omitempty"`
-	Service       *MCPServiceInfo        `protobuf:"bytes,2,opt,name=service,proto3" json:"service,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *MCPServiceResponse) Reset() {
-	*x = MCPServiceResponse{}
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[10]
+func (x *SessionDeleteRequest) Reset() {
+	*x = SessionDeleteRequest{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MCPServiceResponse) String() string {
+func (x *SessionDeleteRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MCPServiceResponse) ProtoMessage() {}
+func (*SessionDeleteRequest) ProtoMessage() {}
 
-func (x *MCPServiceResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[10]
+func (x *SessionDeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -841,49 +2648,46 @@ func (x *MCPServiceResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MCPServiceResponse.ProtoReflect.Descriptor instead.
-func (*MCPServiceResponse) Descriptor() ([]byte, []int) {
-	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{10}
-}
-
-func (x *MCPServiceResponse) GetRet() *BaseResponse {
-	if x != nil {
-		return x.Ret
-	}
-	return nil
+// Deprecated: Use SessionDeleteRequest.ProtoReflect.Descriptor instead.
+func (*SessionDeleteRequest) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{32}
 }
 
-func (x *MCPServiceResponse) GetService() *MCPServiceInfo {
+func (x *SessionDeleteRequest) GetId() string {
 	if x != nil {
-		return x.Service
+		return x.Id
 	}
-	return nil
+	return ""
 }
 
-// MCP 服务列表响应
-type MCPServicesResponse struct {
+// 会话信息
+type SessionInfo struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Ret           *BaseResponse          `protobuf:"bytes,1,opt,name=ret,proto3" json:"ret,omitempty"`
-	Services      []*MCPServiceInfo      `protobuf:"bytes,2,rep,name=services,proto3" json:"services,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	AgentId       int32                  `protobuf:"varint,2,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Title         string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	MessageCount  int32                  `protobuf:"varint,4,opt,name=message_count,json=messageCount,proto3" json:"message_count,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     string                 `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *MCPServicesResponse) Reset() {
-	*x = MCPServicesResponse{}
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[11]
+func (x *SessionInfo) Reset() {
+	*x = SessionInfo{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MCPServicesResponse) String() string {
+func (x *SessionInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MCPServicesResponse) ProtoMessage() {}
+func (*SessionInfo) ProtoMessage() {}
 
-func (x *MCPServicesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[11]
+func (x *SessionInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -894,53 +2698,81 @@ func (x *MCPServicesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MCPServicesResponse.ProtoReflect.Descriptor instead.
-func (*MCPServicesResponse) Descriptor() ([]byte, []int) {
-	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use SessionInfo.ProtoReflect.Descriptor instead.
+func (*SessionInfo) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{33}
 }
 
-func (x *MCPServicesResponse) GetRet() *BaseResponse {
+func (x *SessionInfo) GetId() string {
 	if x != nil {
-		return x.Ret
+		return x.Id
 	}
-	return nil
+	return ""
 }
 
-func (x *MCPServicesResponse) GetServices() []*MCPServiceInfo {
+func (x *SessionInfo) GetAgentId() int32 {
 	if x != nil {
-		return x.Services
+		return x.AgentId
 	}
-	return nil
+	return 0
 }
 
-// MCP 服务信息
-type MCPServiceInfo struct {
+func (x *SessionInfo) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *SessionInfo) GetMessageCount() int32 {
+	if x != nil {
+		return x.MessageCount
+	}
+	return 0
+}
+
+func (x *SessionInfo) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *SessionInfo) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+// 会话消息
+type SessionMessage struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Endpoint      string                 `protobuf:"bytes,2,opt,name=endpoint,proto3" json:"endpoint,omitempty"`
-	Active        bool                   `protobuf:"varint,3,opt,name=active,proto3" json:"active,omitempty"`
-	ToolCount     int32                  `protobuf:"varint,4,opt,name=tool_count,json=toolCount,proto3" json:"tool_count,omitempty"`
+	Role          string                 `protobuf:"bytes,1,opt,name=role,proto3" json:"role,omitempty"`
+	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	ToolCallId    string                 `protobuf:"bytes,4,opt,name=tool_call_id,json=toolCallId,proto3" json:"tool_call_id,omitempty"`
 	CreatedAt     string                 `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	LastRefresh   string                 `protobuf:"bytes,6,opt,name=last_refresh,json=lastRefresh,proto3" json:"last_refresh,omitempty"`
+	ToolCalls     []*SessionToolCall     `protobuf:"bytes,6,rep,name=tool_calls,json=toolCalls,proto3" json:"tool_calls,omitempty"` // 助手消息发起的工具调用（function calling 模式）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *MCPServiceInfo) Reset() {
-	*x = MCPServiceInfo{}
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[12]
+func (x *SessionMessage) Reset() {
+	*x = SessionMessage{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MCPServiceInfo) String() string {
+func (x *SessionMessage) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MCPServiceInfo) ProtoMessage() {}
+func (*SessionMessage) ProtoMessage() {}
 
-func (x *MCPServiceInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[12]
+func (x *SessionMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -951,83 +2783,78 @@ func (x *MCPServiceInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MCPServiceInfo.ProtoReflect.Descriptor instead.
-func (*MCPServiceInfo) Descriptor() ([]byte, []int) {
-	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use SessionMessage.ProtoReflect.Descriptor instead.
+func (*SessionMessage) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{34}
 }
 
-func (x *MCPServiceInfo) GetName() string {
+func (x *SessionMessage) GetRole() string {
 	if x != nil {
-		return x.Name
+		return x.Role
 	}
 	return ""
 }
 
-func (x *MCPServiceInfo) GetEndpoint() string {
+func (x *SessionMessage) GetContent() string {
 	if x != nil {
-		return x.Endpoint
+		return x.Content
 	}
 	return ""
 }
 
-func (x *MCPServiceInfo) GetActive() bool {
+func (x *SessionMessage) GetName() string {
 	if x != nil {
-		return x.Active
+		return x.Name
 	}
-	return false
+	return ""
 }
 
-func (x *MCPServiceInfo) GetToolCount() int32 {
+func (x *SessionMessage) GetToolCallId() string {
 	if x != nil {
-		return x.ToolCount
+		return x.ToolCallId
 	}
-	return 0
+	return ""
 }
 
-func (x *MCPServiceInfo) GetCreatedAt() string {
+func (x *SessionMessage) GetCreatedAt() string {
 	if x != nil {
 		return x.CreatedAt
 	}
 	return ""
 }
 
-func (x *MCPServiceInfo) GetLastRefresh() string {
+func (x *SessionMessage) GetToolCalls() []*SessionToolCall {
 	if x != nil {
-		return x.LastRefresh
+		return x.ToolCalls
 	}
-	return ""
+	return nil
 }
 
-type MCPServiceWithIdInfo struct {
+// 会话消息中的工具调用
+type SessionToolCall struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Endpoint      string                 `protobuf:"bytes,3,opt,name=endpoint,proto3" json:"endpoint,omitempty"`
-	Active        bool                   `protobuf:"varint,4,opt,name=active,proto3" json:"active,omitempty"`
-	ToolCount     int32                  `protobuf:"varint,5,opt,name=tool_count,json=toolCount,proto3" json:"tool_count,omitempty"`
-	Description   string                 `protobuf:"bytes,6,opt,name=description,proto3" json:"description,omitempty"`
-	CreatedAt     string                 `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	LastRefresh   string                 `protobuf:"bytes,8,opt,name=last_refresh,json=lastRefresh,proto3" json:"last_refresh,omitempty"`
-	ClientType    string                 `protobuf:"bytes,9,opt,name=client_type,json=clientType,proto3" json:"client_type,omitempty"`
+	Arguments     string                 `protobuf:"bytes,3,opt,name=arguments,proto3" json:"arguments,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *MCPServiceWithIdInfo) Reset() {
-	*x = MCPServiceWithIdInfo{}
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[13]
+func (x *SessionToolCall) Reset() {
+	*x = SessionToolCall{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MCPServiceWithIdInfo) String() string {
+func (x *SessionToolCall) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MCPServiceWithIdInfo) ProtoMessage() {}
+func (*SessionToolCall) ProtoMessage() {}
 
-func (x *MCPServiceWithIdInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[13]
+func (x *SessionToolCall) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1038,97 +2865,117 @@ func (x *MCPServiceWithIdInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MCPServiceWithIdInfo.ProtoReflect.Descriptor instead.
-func (*MCPServiceWithIdInfo) Descriptor() ([]byte, []int) {
-	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use SessionToolCall.ProtoReflect.Descriptor instead.
+func (*SessionToolCall) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{35}
 }
 
-func (x *MCPServiceWithIdInfo) GetId() int32 {
+func (x *SessionToolCall) GetId() string {
 	if x != nil {
 		return x.Id
 	}
-	return 0
+	return ""
 }
 
-func (x *MCPServiceWithIdInfo) GetName() string {
+func (x *SessionToolCall) GetName() string {
 	if x != nil {
 		return x.Name
 	}
 	return ""
 }
 
-func (x *MCPServiceWithIdInfo) GetEndpoint() string {
+func (x *SessionToolCall) GetArguments() string {
 	if x != nil {
-		return x.Endpoint
+		return x.Arguments
 	}
 	return ""
 }
 
-func (x *MCPServiceWithIdInfo) GetActive() bool {
-	if x != nil {
-		return x.Active
-	}
-	return false
+// 会话响应
+type SessionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ret           *BaseResponse          `protobuf:"bytes,1,opt,name=ret,proto3" json:"ret,omitempty"`
+	Session       *SessionInfo           `protobuf:"bytes,2,opt,name=session,proto3" json:"session,omitempty"`
+	Messages      []*SessionMessage      `protobuf:"bytes,3,rep,name=messages,proto3" json:"messages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *MCPServiceWithIdInfo) GetToolCount() int32 {
-	if x != nil {
-		return x.ToolCount
-	}
-	return 0
+func (x *SessionResponse) Reset() {
+	*x = SessionResponse{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *MCPServiceWithIdInfo) GetDescription() string {
+func (x *SessionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionResponse) ProtoMessage() {}
+
+func (x *SessionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[36]
 	if x != nil {
-		return x.Description
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *MCPServiceWithIdInfo) GetCreatedAt() string {
+// Deprecated: Use SessionResponse.ProtoReflect.Descriptor instead.
+func (*SessionResponse) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *SessionResponse) GetRet() *BaseResponse {
 	if x != nil {
-		return x.CreatedAt
+		return x.Ret
 	}
-	return ""
+	return nil
 }
 
-func (x *MCPServiceWithIdInfo) GetLastRefresh() string {
+func (x *SessionResponse) GetSession() *SessionInfo {
 	if x != nil {
-		return x.LastRefresh
+		return x.Session
 	}
-	return ""
+	return nil
 }
 
-func (x *MCPServiceWithIdInfo) GetClientType() string {
+func (x *SessionResponse) GetMessages() []*SessionMessage {
 	if x != nil {
-		return x.ClientType
+		return x.Messages
 	}
-	return ""
+	return nil
 }
 
-type MCPServicesWithIdResponse struct {
-	state         protoimpl.MessageState  `protogen:"open.v1"`
-	Ret           *BaseResponse           `protobuf:"bytes,1,opt,name=ret,proto3" json:"ret,omitempty"`
-	Services      []*MCPServiceWithIdInfo `protobuf:"bytes,2,rep,name=services,proto3" json:"services,omitempty"`
+// 会话列表响应
+type SessionListResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ret           *BaseResponse          `protobuf:"bytes,1,opt,name=ret,proto3" json:"ret,omitempty"`
+	Sessions      []*SessionInfo         `protobuf:"bytes,2,rep,name=sessions,proto3" json:"sessions,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *MCPServicesWithIdResponse) Reset() {
-	*x = MCPServicesWithIdResponse{}
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[14]
+func (x *SessionListResponse) Reset() {
+	*x = SessionListResponse{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MCPServicesWithIdResponse) String() string {
+func (x *SessionListResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MCPServicesWithIdResponse) ProtoMessage() {}
+func (*SessionListResponse) ProtoMessage() {}
 
-func (x *MCPServicesWithIdResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[14]
+func (x *SessionListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1139,47 +2986,48 @@ func (x *MCPServicesWithIdResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MCPServicesWithIdResponse.ProtoReflect.Descriptor instead.
-func (*MCPServicesWithIdResponse) Descriptor() ([]byte, []int) {
-	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{14}
+// Deprecated: Use SessionListResponse.ProtoReflect.Descriptor instead.
+func (*SessionListResponse) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{37}
 }
 
-func (x *MCPServicesWithIdResponse) GetRet() *BaseResponse {
+func (x *SessionListResponse) GetRet() *BaseResponse {
 	if x != nil {
 		return x.Ret
 	}
 	return nil
 }
 
-func (x *MCPServicesWithIdResponse) GetServices() []*MCPServiceWithIdInfo {
+func (x *SessionListResponse) GetSessions() []*SessionInfo {
 	if x != nil {
-		return x.Services
+		return x.Sessions
 	}
 	return nil
 }
 
-type MCPServiceToolsRequest struct {
+// 运行获取请求
+type RunGetRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *MCPServiceToolsRequest) Reset() {
-	*x = MCPServiceToolsRequest{}
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[15]
+func (x *RunGetRequest) Reset() {
+	*x = RunGetRequest{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MCPServiceToolsRequest) String() string {
+func (x *RunGetRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MCPServiceToolsRequest) ProtoMessage() {}
+func (*RunGetRequest) ProtoMessage() {}
 
-func (x *MCPServiceToolsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[15]
+func (x *RunGetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1190,43 +3038,41 @@ func (x *MCPServiceToolsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MCPServiceToolsRequest.ProtoReflect.Descriptor instead.
-func (*MCPServiceToolsRequest) Descriptor() ([]byte, []int) {
-	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{15}
+// Deprecated: Use RunGetRequest.ProtoReflect.Descriptor instead.
+func (*RunGetRequest) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{38}
 }
 
-func (x *MCPServiceToolsRequest) GetId() int32 {
+func (x *RunGetRequest) GetId() string {
 	if x != nil {
 		return x.Id
 	}
-	return 0
+	return ""
 }
 
-type MCPServiceToolInfo struct {
+// 运行恢复请求
+type RunResumeRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
-	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
-	InputSchema   *structpb.Struct       `protobuf:"bytes,4,opt,name=input_schema,json=inputSchema,proto3" json:"input_schema,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *MCPServiceToolInfo) Reset() {
-	*x = MCPServiceToolInfo{}
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[16]
+func (x *RunResumeRequest) Reset() {
+	*x = RunResumeRequest{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MCPServiceToolInfo) String() string {
+func (x *RunResumeRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MCPServiceToolInfo) ProtoMessage() {}
+func (*RunResumeRequest) ProtoMessage() {}
 
-func (x *MCPServiceToolInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[16]
+func (x *RunResumeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1237,62 +3083,41 @@ func (x *MCPServiceToolInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MCPServiceToolInfo.ProtoReflect.Descriptor instead.
-func (*MCPServiceToolInfo) Descriptor() ([]byte, []int) {
-	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{16}
-}
-
-func (x *MCPServiceToolInfo) GetName() string {
-	if x != nil {
-		return x.Name
-	}
-	return ""
-}
-
-func (x *MCPServiceToolInfo) GetDescription() string {
-	if x != nil {
-		return x.Description
-	}
-	return ""
+// Deprecated: Use RunResumeRequest.ProtoReflect.Descriptor instead.
+func (*RunResumeRequest) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{39}
 }
 
-func (x *MCPServiceToolInfo) GetType() string {
+func (x *RunResumeRequest) GetId() string {
 	if x != nil {
-		return x.Type
+		return x.Id
 	}
 	return ""
 }
 
-func (x *MCPServiceToolInfo) GetInputSchema() *structpb.Struct {
-	if x != nil {
-		return x.InputSchema
-	}
-	return nil
-}
-
-type MCPServiceToolsResponse struct {
+// 运行取消请求
+type RunCancelRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Ret           *BaseResponse          `protobuf:"bytes,1,opt,name=ret,proto3" json:"ret,omitempty"`
-	Tools         []*MCPServiceToolInfo  `protobuf:"bytes,2,rep,name=tools,proto3" json:"tools,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *MCPServiceToolsResponse) Reset() {
-	*x = MCPServiceToolsResponse{}
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[17]
+func (x *RunCancelRequest) Reset() {
+	*x = RunCancelRequest{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MCPServiceToolsResponse) String() string {
+func (x *RunCancelRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MCPServiceToolsResponse) ProtoMessage() {}
+func (*RunCancelRequest) ProtoMessage() {}
 
-func (x *MCPServiceToolsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[17]
+func (x *RunCancelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1303,58 +3128,54 @@ func (x *MCPServiceToolsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MCPServiceToolsResponse.ProtoReflect.Descriptor instead.
-func (*MCPServiceToolsResponse) Descriptor() ([]byte, []int) {
-	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{17}
-}
-
-func (x *MCPServiceToolsResponse) GetRet() *BaseResponse {
-	if x != nil {
-		return x.Ret
-	}
-	return nil
+// Deprecated: Use RunCancelRequest.ProtoReflect.Descriptor instead.
+func (*RunCancelRequest) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{40}
 }
 
-func (x *MCPServiceToolsResponse) GetTools() []*MCPServiceToolInfo {
+func (x *RunCancelRequest) GetId() string {
 	if x != nil {
-		return x.Tools
+		return x.Id
 	}
-	return nil
+	return ""
 }
 
-// Agent 配置请求
-type AgentConfigRequest struct {
+// 运行信息
+type RunInfo struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
-	Id               int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`                                                                                  // Agent ID（更新时需要）
-	Name             string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`                                                                               // Agent名称
-	Framework        string                 `protobuf:"bytes,3,opt,name=framework,proto3" json:"framework,omitempty"`                                                                     // 框架类型: react, plan, chain, sql, elasticsearch
-	Description      string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`                                                                 // Agent描述
-	SystemPrompt     string                 `protobuf:"bytes,5,opt,name=system_prompt,json=systemPrompt,proto3" json:"system_prompt,omitempty"`                                           // 系统提示词
-	MaxSteps         int32                  `protobuf:"varint,6,opt,name=max_steps,json=maxSteps,proto3" json:"max_steps,omitempty"`                                                      // 最大执行步数
-	Model            string                 `protobuf:"bytes,7,opt,name=model,proto3" json:"model,omitempty"`                                                                             // 默认模型
-	McpServices      []string               `protobuf:"bytes,8,rep,name=mcp_services,json=mcpServices,proto3" json:"mcp_services,omitempty"`                                              // 绑定的MCP服务名称列表
-	Config           map[string]string      `protobuf:"bytes,9,rep,name=config,proto3" json:"config,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // 其他配置
-	ConnectionConfig string                 `protobuf:"bytes,10,opt,name=connection_config,json=connectionConfig,proto3" json:"connection_config,omitempty"`                              // 连接配置（JSON字符串）
-	ConfigJson       string                 `protobuf:"bytes,11,opt,name=config_json,json=configJson,proto3" json:"config_json,omitempty"`                                                // 运行时配置（JSON字符串，优先于 config）
+	Id               string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	AgentId          int32                  `protobuf:"varint,2,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	SessionId        string                 `protobuf:"bytes,3,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Query            string                 `protobuf:"bytes,4,opt,name=query,proto3" json:"query,omitempty"`
+	Status           string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`                                // 执行状态：Running、Finish、Error、Timeout、Canceled、BudgetExceeded
+	CurrentStep      int32                  `protobuf:"varint,6,opt,name=current_step,json=currentStep,proto3" json:"current_step,omitempty"`  // 最近一次检查点的步骤数
+	Result           string                 `protobuf:"bytes,7,opt,name=result,proto3" json:"result,omitempty"`                                // 运行结果
+	FinalResult      *FinalResult           `protobuf:"bytes,8,opt,name=final_result,json=finalResult,proto3" json:"final_result,omitempty"`   // 结构化最终结果
+	Error            string                 `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`                                  // 失败原因
+	ResumeCount      int32                  `protobuf:"varint,10,opt,name=resume_count,json=resumeCount,proto3" json:"resume_count,omitempty"` // 已恢复次数
+	CreatedAt        string                 `protobuf:"bytes,11,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt        string                 `protobuf:"bytes,12,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	WebhookUrl       string                 `protobuf:"bytes,13,opt,name=webhook_url,json=webhookUrl,proto3" json:"webhook_url,omitempty"`                   // 完成回调地址
+	PendingApprovals []*ApprovalInfo        `protobuf:"bytes,14,rep,name=pending_approvals,json=pendingApprovals,proto3" json:"pending_approvals,omitempty"` // 等待审批的工具调用
 	unknownFields    protoimpl.UnknownFields
 	sizeCache        protoimpl.SizeCache
 }
 
-func (x *AgentConfigRequest) Reset() {
-	*x = AgentConfigRequest{}
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[18]
+func (x *RunInfo) Reset() {
+	*x = RunInfo{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AgentConfigRequest) String() string {
+func (x *RunInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AgentConfigRequest) ProtoMessage() {}
+func (*RunInfo) ProtoMessage() {}
 
-func (x *AgentConfigRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[18]
+func (x *RunInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1365,112 +3186,186 @@ func (x *AgentConfigRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AgentConfigRequest.ProtoReflect.Descriptor instead.
-func (*AgentConfigRequest) Descriptor() ([]byte, []int) {
-	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{18}
+// Deprecated: Use RunInfo.ProtoReflect.Descriptor instead.
+func (*RunInfo) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{41}
 }
 
-func (x *AgentConfigRequest) GetId() int32 {
+func (x *RunInfo) GetId() string {
 	if x != nil {
 		return x.Id
 	}
-	return 0
+	return ""
 }
 
-func (x *AgentConfigRequest) GetName() string {
+func (x *RunInfo) GetAgentId() int32 {
 	if x != nil {
-		return x.Name
+		return x.AgentId
 	}
-	return ""
+	return 0
 }
 
-func (x *AgentConfigRequest) GetFramework() string {
+func (x *RunInfo) GetSessionId() string {
 	if x != nil {
-		return x.Framework
+		return x.SessionId
 	}
 	return ""
 }
 
-func (x *AgentConfigRequest) GetDescription() string {
+func (x *RunInfo) GetQuery() string {
 	if x != nil {
-		return x.Description
+		return x.Query
 	}
 	return ""
 }
 
-func (x *AgentConfigRequest) GetSystemPrompt() string {
+func (x *RunInfo) GetStatus() string {
 	if x != nil {
-		return x.SystemPrompt
+		return x.Status
 	}
 	return ""
 }
 
-func (x *AgentConfigRequest) GetMaxSteps() int32 {
+func (x *RunInfo) GetCurrentStep() int32 {
 	if x != nil {
-		return x.MaxSteps
+		return x.CurrentStep
 	}
 	return 0
 }
 
-func (x *AgentConfigRequest) GetModel() string {
+func (x *RunInfo) GetResult() string {
 	if x != nil {
-		return x.Model
+		return x.Result
 	}
 	return ""
 }
 
-func (x *AgentConfigRequest) GetMcpServices() []string {
+func (x *RunInfo) GetFinalResult() *FinalResult {
 	if x != nil {
-		return x.McpServices
+		return x.FinalResult
 	}
 	return nil
 }
 
-func (x *AgentConfigRequest) GetConfig() map[string]string {
+func (x *RunInfo) GetError() string {
 	if x != nil {
-		return x.Config
+		return x.Error
 	}
-	return nil
+	return ""
 }
 
-func (x *AgentConfigRequest) GetConnectionConfig() string {
+func (x *RunInfo) GetResumeCount() int32 {
 	if x != nil {
-		return x.ConnectionConfig
+		return x.ResumeCount
+	}
+	return 0
+}
+
+func (x *RunInfo) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
 	}
 	return ""
 }
 
-func (x *AgentConfigRequest) GetConfigJson() string {
+func (x *RunInfo) GetUpdatedAt() string {
 	if x != nil {
-		return x.ConfigJson
+		return x.UpdatedAt
 	}
 	return ""
 }
 
-// Agent 配置响应
-type AgentConfigResponse struct {
+func (x *RunInfo) GetWebhookUrl() string {
+	if x != nil {
+		return x.WebhookUrl
+	}
+	return ""
+}
+
+func (x *RunInfo) GetPendingApprovals() []*ApprovalInfo {
+	if x != nil {
+		return x.PendingApprovals
+	}
+	return nil
+}
+
+// 运行响应
+type RunResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Ret           *BaseResponse          `protobuf:"bytes,1,opt,name=ret,proto3" json:"ret,omitempty"`
-	Agent         *AgentConfig           `protobuf:"bytes,2,opt,name=agent,proto3" json:"agent,omitempty"`
+	Run           *RunInfo               `protobuf:"bytes,2,opt,name=run,proto3" json:"run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunResponse) Reset() {
+	*x = RunResponse{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunResponse) ProtoMessage() {}
+
+func (x *RunResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunResponse.ProtoReflect.Descriptor instead.
+func (*RunResponse) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *RunResponse) GetRet() *BaseResponse {
+	if x != nil {
+		return x.Ret
+	}
+	return nil
+}
+
+func (x *RunResponse) GetRun() *RunInfo {
+	if x != nil {
+		return x.Run
+	}
+	return nil
+}
+
+// 异步运行提交请求
+type SubmitRunRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Request       *ChatRequest           `protobuf:"bytes,1,opt,name=request,proto3" json:"request,omitempty"`
+	WebhookUrl    string                 `protobuf:"bytes,2,opt,name=webhook_url,json=webhookUrl,proto3" json:"webhook_url,omitempty"` // 运行结束后 POST 运行信息到该地址，可选
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AgentConfigResponse) Reset() {
-	*x = AgentConfigResponse{}
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[19]
+func (x *SubmitRunRequest) Reset() {
+	*x = SubmitRunRequest{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AgentConfigResponse) String() string {
+func (x *SubmitRunRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AgentConfigResponse) ProtoMessage() {}
+func (*SubmitRunRequest) ProtoMessage() {}
 
-func (x *AgentConfigResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[19]
+func (x *SubmitRunRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1481,48 +3376,50 @@ func (x *AgentConfigResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AgentConfigResponse.ProtoReflect.Descriptor instead.
-func (*AgentConfigResponse) Descriptor() ([]byte, []int) {
-	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{19}
+// Deprecated: Use SubmitRunRequest.ProtoReflect.Descriptor instead.
+func (*SubmitRunRequest) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{43}
 }
 
-func (x *AgentConfigResponse) GetRet() *BaseResponse {
+func (x *SubmitRunRequest) GetRequest() *ChatRequest {
 	if x != nil {
-		return x.Ret
+		return x.Request
 	}
 	return nil
 }
 
-func (x *AgentConfigResponse) GetAgent() *AgentConfig {
+func (x *SubmitRunRequest) GetWebhookUrl() string {
 	if x != nil {
-		return x.Agent
+		return x.WebhookUrl
 	}
-	return nil
+	return ""
 }
 
-// Agent 删除请求
-type AgentDeleteRequest struct {
+// 运行事件请求
+type RunEventsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	AfterSeq      int64                  `protobuf:"varint,2,opt,name=after_seq,json=afterSeq,proto3" json:"after_seq,omitempty"` // 只返回序号大于该值的事件
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`                       // 单次返回的最大事件数，默认 100
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AgentDeleteRequest) Reset() {
-	*x = AgentDeleteRequest{}
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[20]
+func (x *RunEventsRequest) Reset() {
+	*x = RunEventsRequest{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AgentDeleteRequest) String() string {
+func (x *RunEventsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AgentDeleteRequest) ProtoMessage() {}
+func (*RunEventsRequest) ProtoMessage() {}
 
-func (x *AgentDeleteRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[20]
+func (x *RunEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1533,41 +3430,60 @@ func (x *AgentDeleteRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AgentDeleteRequest.ProtoReflect.Descriptor instead.
-func (*AgentDeleteRequest) Descriptor() ([]byte, []int) {
-	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{20}
+// Deprecated: Use RunEventsRequest.ProtoReflect.Descriptor instead.
+func (*RunEventsRequest) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{44}
 }
 
-func (x *AgentDeleteRequest) GetId() int32 {
+func (x *RunEventsRequest) GetId() string {
 	if x != nil {
 		return x.Id
 	}
+	return ""
+}
+
+func (x *RunEventsRequest) GetAfterSeq() int64 {
+	if x != nil {
+		return x.AfterSeq
+	}
 	return 0
 }
 
-// Agent 获取请求
-type AgentGetRequest struct {
+func (x *RunEventsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// 运行事件
+type RunEvent struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Seq           int64                  `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"` // 事件序号，单调递增
+	RunId         string                 `protobuf:"bytes,2,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`       // THINKING、ACTION、OBSERVATION、SUMMARY、METADATA、STATUS、FINAL、APPROVAL_REQUIRED、APPROVAL_DECISION、PLAN_STEP
+	Content       string                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"` // STATUS 事件为运行状态，FINAL 事件为运行结果
+	Step          int32                  `protobuf:"varint,5,opt,name=step,proto3" json:"step,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AgentGetRequest) Reset() {
-	*x = AgentGetRequest{}
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[21]
+func (x *RunEvent) Reset() {
+	*x = RunEvent{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[45]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AgentGetRequest) String() string {
+func (x *RunEvent) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AgentGetRequest) ProtoMessage() {}
+func (*RunEvent) ProtoMessage() {}
 
-func (x *AgentGetRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[21]
+func (x *RunEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[45]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1578,42 +3494,80 @@ func (x *AgentGetRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AgentGetRequest.ProtoReflect.Descriptor instead.
-func (*AgentGetRequest) Descriptor() ([]byte, []int) {
-	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{21}
+// Deprecated: Use RunEvent.ProtoReflect.Descriptor instead.
+func (*RunEvent) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{45}
 }
 
-func (x *AgentGetRequest) GetId() int32 {
+func (x *RunEvent) GetSeq() int64 {
 	if x != nil {
-		return x.Id
+		return x.Seq
 	}
 	return 0
 }
 
-// Agent 列表响应
-type AgentListResponse struct {
+func (x *RunEvent) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
+func (x *RunEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *RunEvent) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *RunEvent) GetStep() int32 {
+	if x != nil {
+		return x.Step
+	}
+	return 0
+}
+
+func (x *RunEvent) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+// 待审批的工具调用
+type ApprovalInfo struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Ret           *BaseResponse          `protobuf:"bytes,1,opt,name=ret,proto3" json:"ret,omitempty"`
-	Agents        []*AgentConfig         `protobuf:"bytes,2,rep,name=agents,proto3" json:"agents,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // 审批ID
+	RunId         string                 `protobuf:"bytes,2,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	ToolCallId    string                 `protobuf:"bytes,3,opt,name=tool_call_id,json=toolCallId,proto3" json:"tool_call_id,omitempty"`
+	ToolName      string                 `protobuf:"bytes,4,opt,name=tool_name,json=toolName,proto3" json:"tool_name,omitempty"`
+	Arguments     string                 `protobuf:"bytes,5,opt,name=arguments,proto3" json:"arguments,omitempty"` // 模型给出的调用参数
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AgentListResponse) Reset() {
-	*x = AgentListResponse{}
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[22]
+func (x *ApprovalInfo) Reset() {
+	*x = ApprovalInfo{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[46]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AgentListResponse) String() string {
+func (x *ApprovalInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AgentListResponse) ProtoMessage() {}
+func (*ApprovalInfo) ProtoMessage() {}
 
-func (x *AgentListResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[22]
+func (x *ApprovalInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[46]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1624,60 +3578,73 @@ func (x *AgentListResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AgentListResponse.ProtoReflect.Descriptor instead.
-func (*AgentListResponse) Descriptor() ([]byte, []int) {
-	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{22}
+// Deprecated: Use ApprovalInfo.ProtoReflect.Descriptor instead.
+func (*ApprovalInfo) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{46}
 }
 
-func (x *AgentListResponse) GetRet() *BaseResponse {
+func (x *ApprovalInfo) GetId() string {
 	if x != nil {
-		return x.Ret
+		return x.Id
 	}
-	return nil
+	return ""
 }
 
-func (x *AgentListResponse) GetAgents() []*AgentConfig {
+func (x *ApprovalInfo) GetRunId() string {
 	if x != nil {
-		return x.Agents
+		return x.RunId
 	}
-	return nil
+	return ""
 }
 
-// Agent 配置信息
-type AgentConfig struct {
-	state            protoimpl.MessageState `protogen:"open.v1"`
-	Id               int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name             string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Framework        string                 `protobuf:"bytes,3,opt,name=framework,proto3" json:"framework,omitempty"`
-	Description      string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
-	SystemPrompt     string                 `protobuf:"bytes,5,opt,name=system_prompt,json=systemPrompt,proto3" json:"system_prompt,omitempty"`
-	MaxSteps         int32                  `protobuf:"varint,6,opt,name=max_steps,json=maxSteps,proto3" json:"max_steps,omitempty"`
-	Model            string                 `protobuf:"bytes,7,opt,name=model,proto3" json:"model,omitempty"`
-	McpServices      []string               `protobuf:"bytes,8,rep,name=mcp_services,json=mcpServices,proto3" json:"mcp_services,omitempty"`
-	CreatedAt        string                 `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	UpdatedAt        string                 `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
-	IsActive         bool                   `protobuf:"varint,11,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
-	ConnectionConfig string                 `protobuf:"bytes,12,opt,name=connection_config,json=connectionConfig,proto3" json:"connection_config,omitempty"` // 连接配置（JSON字符串）
-	ConfigJson       string                 `protobuf:"bytes,13,opt,name=config_json,json=configJson,proto3" json:"config_json,omitempty"`                   // 运行时配置（JSON字符串）
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+func (x *ApprovalInfo) GetToolCallId() string {
+	if x != nil {
+		return x.ToolCallId
+	}
+	return ""
 }
 
-func (x *AgentConfig) Reset() {
-	*x = AgentConfig{}
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[23]
+func (x *ApprovalInfo) GetToolName() string {
+	if x != nil {
+		return x.ToolName
+	}
+	return ""
+}
+
+func (x *ApprovalInfo) GetArguments() string {
+	if x != nil {
+		return x.Arguments
+	}
+	return ""
+}
+
+// 审批请求
+type ResolveApprovalRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`                    // 审批ID
+	RunId         string                 `protobuf:"bytes,5,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"` // 审批所属的运行ID，WebSocket 中为当前连接的运行
+	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`            // approve、edit、reject
+	Arguments     string                 `protobuf:"bytes,3,opt,name=arguments,proto3" json:"arguments,omitempty"`      // edit 时的新参数
+	Reason        string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`            // 拒绝原因，作为观察结果返回给模型
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveApprovalRequest) Reset() {
+	*x = ResolveApprovalRequest{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[47]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AgentConfig) String() string {
+func (x *ResolveApprovalRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AgentConfig) ProtoMessage() {}
+func (*ResolveApprovalRequest) ProtoMessage() {}
 
-func (x *AgentConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[23]
+func (x *ResolveApprovalRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[47]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1688,100 +3655,158 @@ func (x *AgentConfig) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AgentConfig.ProtoReflect.Descriptor instead.
-func (*AgentConfig) Descriptor() ([]byte, []int) {
-	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{23}
+// Deprecated: Use ResolveApprovalRequest.ProtoReflect.Descriptor instead.
+func (*ResolveApprovalRequest) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{47}
 }
 
-func (x *AgentConfig) GetId() int32 {
+func (x *ResolveApprovalRequest) GetId() string {
 	if x != nil {
 		return x.Id
 	}
-	return 0
+	return ""
 }
 
-func (x *AgentConfig) GetName() string {
+func (x *ResolveApprovalRequest) GetRunId() string {
 	if x != nil {
-		return x.Name
+		return x.RunId
 	}
 	return ""
 }
 
-func (x *AgentConfig) GetFramework() string {
+func (x *ResolveApprovalRequest) GetAction() string {
 	if x != nil {
-		return x.Framework
+		return x.Action
 	}
 	return ""
 }
 
-func (x *AgentConfig) GetDescription() string {
+func (x *ResolveApprovalRequest) GetArguments() string {
 	if x != nil {
-		return x.Description
+		return x.Arguments
 	}
 	return ""
 }
 
-func (x *AgentConfig) GetSystemPrompt() string {
+func (x *ResolveApprovalRequest) GetReason() string {
 	if x != nil {
-		return x.SystemPrompt
+		return x.Reason
 	}
 	return ""
 }
 
-func (x *AgentConfig) GetMaxSteps() int32 {
-	if x != nil {
-		return x.MaxSteps
-	}
-	return 0
+// 审批响应
+type ResolveApprovalResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ret           *BaseResponse          `protobuf:"bytes,1,opt,name=ret,proto3" json:"ret,omitempty"`
+	Approval      *ApprovalInfo          `protobuf:"bytes,2,opt,name=approval,proto3" json:"approval,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AgentConfig) GetModel() string {
+func (x *ResolveApprovalResponse) Reset() {
+	*x = ResolveApprovalResponse{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveApprovalResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveApprovalResponse) ProtoMessage() {}
+
+func (x *ResolveApprovalResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[48]
 	if x != nil {
-		return x.Model
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *AgentConfig) GetMcpServices() []string {
+// Deprecated: Use ResolveApprovalResponse.ProtoReflect.Descriptor instead.
+func (*ResolveApprovalResponse) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *ResolveApprovalResponse) GetRet() *BaseResponse {
 	if x != nil {
-		return x.McpServices
+		return x.Ret
 	}
 	return nil
 }
 
-func (x *AgentConfig) GetCreatedAt() string {
+func (x *ResolveApprovalResponse) GetApproval() *ApprovalInfo {
 	if x != nil {
-		return x.CreatedAt
+		return x.Approval
 	}
-	return ""
+	return nil
 }
 
-func (x *AgentConfig) GetUpdatedAt() string {
+// 运行事件响应
+type RunEventsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ret           *BaseResponse          `protobuf:"bytes,1,opt,name=ret,proto3" json:"ret,omitempty"`
+	Events        []*RunEvent            `protobuf:"bytes,2,rep,name=events,proto3" json:"events,omitempty"`
+	Run           *RunInfo               `protobuf:"bytes,3,opt,name=run,proto3" json:"run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunEventsResponse) Reset() {
+	*x = RunEventsResponse{}
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunEventsResponse) ProtoMessage() {}
+
+func (x *RunEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_agent_service_v1_agent_service_proto_msgTypes[49]
 	if x != nil {
-		return x.UpdatedAt
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *AgentConfig) GetIsActive() bool {
+// Deprecated: Use RunEventsResponse.ProtoReflect.Descriptor instead.
+func (*RunEventsResponse) Descriptor() ([]byte, []int) {
+	return file_api_agent_service_v1_agent_service_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *RunEventsResponse) GetRet() *BaseResponse {
 	if x != nil {
-		return x.IsActive
+		return x.Ret
 	}
-	return false
+	return nil
 }
 
-func (x *AgentConfig) GetConnectionConfig() string {
+func (x *RunEventsResponse) GetEvents() []*RunEvent {
 	if x != nil {
-		return x.ConnectionConfig
+		return x.Events
 	}
-	return ""
+	return nil
 }
 
-func (x *AgentConfig) GetConfigJson() string {
+func (x *RunEventsResponse) GetRun() *RunInfo {
 	if x != nil {
-		return x.ConfigJson
+		return x.Run
 	}
-	return ""
+	return nil
 }
 
 var File_api_agent_service_v1_agent_service_proto protoreflect.FileDescriptor
@@ -1789,7 +3814,7 @@ var File_api_agent_service_v1_agent_service_proto protoreflect.FileDescriptor
 const file_api_agent_service_v1_agent_service_proto_rawDesc = "" +
 	"\n" +
 	"(api/agent/service/v1/agent_service.proto\x12\x14api.agent.service.v1\x1a\x1cgoogle/api/annotations.proto\x1a\x1cgoogle/protobuf/struct.proto\x1a!api/agent/service/v1/common.proto\"\a\n" +
-	"\x05Empty\"\xa9\x03\n" +
+	"\x05Empty\"\xc6\x03\n" +
 	"\vChatRequest\x12\x14\n" +
 	"\x05query\x18\x01 \x01(\tR\x05query\x12\x19\n" +
 	"\bagent_id\x18\x02 \x01(\x05R\aagentId\x12\x1d\n" +
@@ -1801,21 +3826,69 @@ const file_api_agent_service_v1_agent_service_proto_rawDesc = "" +
 	"\rsystem_prompt\x18\x06 \x01(\tR\fsystemPrompt\x12\x1b\n" +
 	"\tmax_steps\x18\a \x01(\x05R\bmaxSteps\x12E\n" +
 	"\x06config\x18\b \x03(\v2-.api.agent.service.v1.ChatRequest.ConfigEntryR\x06config\x120\n" +
-	"\x14enabled_mcp_services\x18\t \x03(\tR\x12enabledMcpServices\x1a9\n" +
+	"\x14enabled_mcp_services\x18\t \x03(\tR\x12enabledMcpServices\x12\x1b\n" +
+	"\ttenant_id\x18\n" +
+	" \x01(\tR\btenantId\x1a9\n" +
 	"\vConfigEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xc4\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x8a\x02\n" +
 	"\fChatResponse\x12\x1a\n" +
 	"\bresponse\x18\x01 \x01(\tR\bresponse\x12\x1d\n" +
 	"\n" +
 	"agent_type\x18\x02 \x01(\tR\tagentType\x12C\n" +
 	"\bmetadata\x18\x03 \x01(\v2'.api.agent.service.v1.ExecutionMetadataR\bmetadata\x124\n" +
-	"\x03ret\x18\x04 \x01(\v2\".api.agent.service.v1.BaseResponseR\x03ret\"\xaf\x02\n" +
+	"\x03ret\x18\x04 \x01(\v2\".api.agent.service.v1.BaseResponseR\x03ret\x12D\n" +
+	"\ffinal_result\x18\x05 \x01(\v2!.api.agent.service.v1.FinalResultR\vfinalResult\"\xb3\x02\n" +
+	"\vFinalResult\x12\x16\n" +
+	"\x06answer\x18\x01 \x01(\tR\x06answer\x12\x1c\n" +
+	"\tcitations\x18\x02 \x03(\tR\tcitations\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x03 \x01(\x01R\n" +
+	"confidence\x12E\n" +
+	"\vchain_trace\x18\x04 \x03(\v2$.api.agent.service.v1.ChainNodeTraceR\n" +
+	"chainTrace\x12<\n" +
+	"\bsub_runs\x18\x05 \x03(\v2!.api.agent.service.v1.SubRunTraceR\asubRuns\x12I\n" +
+	"\x0eplan_revisions\x18\x06 \x03(\v2\".api.agent.service.v1.PlanRevisionR\rplanRevisions\"\xec\x01\n" +
+	"\x0eChainNodeTrace\x12\x12\n" +
+	"\x04node\x18\x01 \x01(\tR\x04node\x12\x1d\n" +
+	"\n" +
+	"agent_type\x18\x02 \x01(\tR\tagentType\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x14\n" +
+	"\x05input\x18\x04 \x01(\tR\x05input\x12\x16\n" +
+	"\x06output\x18\x05 \x01(\tR\x06output\x12\x14\n" +
+	"\x05steps\x18\x06 \x01(\x05R\x05steps\x12\x14\n" +
+	"\x05state\x18\a \x01(\tR\x05state\x12\x1f\n" +
+	"\vduration_ms\x18\b \x01(\x03R\n" +
+	"durationMs\x12\x14\n" +
+	"\x05error\x18\t \x01(\tR\x05error\"\x93\x03\n" +
+	"\vSubRunTrace\x12\x17\n" +
+	"\astep_id\x18\x01 \x01(\x05R\x06stepId\x12\x14\n" +
+	"\x05agent\x18\x02 \x01(\tR\x05agent\x12\x1d\n" +
+	"\n" +
+	"agent_type\x18\x03 \x01(\tR\tagentType\x12\x14\n" +
+	"\x05depth\x18\x04 \x01(\x05R\x05depth\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12\x14\n" +
+	"\x05input\x18\x06 \x01(\tR\x05input\x12\x16\n" +
+	"\x06output\x18\a \x01(\tR\x06output\x12\x14\n" +
+	"\x05error\x18\b \x01(\tR\x05error\x12\x14\n" +
+	"\x05steps\x18\t \x01(\x05R\x05steps\x12!\n" +
+	"\ftools_called\x18\n" +
+	" \x03(\tR\vtoolsCalled\x12\x14\n" +
+	"\x05state\x18\v \x01(\tR\x05state\x12\x1f\n" +
+	"\vduration_ms\x18\f \x01(\x03R\n" +
+	"durationMs\x12<\n" +
+	"\bsub_runs\x18\r \x03(\v2!.api.agent.service.v1.SubRunTraceR\asubRuns\x12\x16\n" +
+	"\x06source\x18\x0e \x01(\tR\x06source\"\xe3\x04\n" +
 	"\x12ChatStreamResponse\x12H\n" +
 	"\x04type\x18\x01 \x01(\x0e24.api.agent.service.v1.ChatStreamResponse.MessageTypeR\x04type\x12\x18\n" +
 	"\acontent\x18\x02 \x01(\tR\acontent\x12\x12\n" +
 	"\x04step\x18\x03 \x01(\x05R\x04step\x12C\n" +
-	"\bmetadata\x18\x04 \x01(\v2'.api.agent.service.v1.ExecutionMetadataR\bmetadata\"\\\n" +
+	"\bmetadata\x18\x04 \x01(\v2'.api.agent.service.v1.ExecutionMetadataR\bmetadata\x12D\n" +
+	"\ffinal_result\x18\x05 \x01(\v2!.api.agent.service.v1.FinalResultR\vfinalResult\x12>\n" +
+	"\bapproval\x18\x06 \x01(\v2\".api.agent.service.v1.ApprovalInfoR\bapproval\x12?\n" +
+	"\tplan_step\x18\a \x01(\v2\".api.agent.service.v1.PlanStepInfoR\bplanStep\x12\x16\n" +
+	"\x06source\x18\b \x01(\tR\x06source\x12\x14\n" +
+	"\x05depth\x18\t \x01(\x05R\x05depth\"\x9a\x01\n" +
 	"\vMessageType\x12\f\n" +
 	"\bTHINKING\x10\x00\x12\n" +
 	"\n" +
@@ -1823,7 +3896,36 @@ const file_api_agent_service_v1_agent_service_proto_rawDesc = "" +
 	"\vOBSERVATION\x10\x02\x12\t\n" +
 	"\x05FINAL\x10\x03\x12\t\n" +
 	"\x05ERROR\x10\x04\x12\f\n" +
-	"\bMETADATA\x10\x05\"\xb8\x01\n" +
+	"\bMETADATA\x10\x05\x12\v\n" +
+	"\aSUMMARY\x10\x06\x12\t\n" +
+	"\x05DELTA\x10\a\x12\x15\n" +
+	"\x11APPROVAL_REQUIRED\x10\b\x12\r\n" +
+	"\tPLAN_STEP\x10\t\"\xf3\x01\n" +
+	"\fPlanStepInfo\x12\x17\n" +
+	"\astep_id\x18\x01 \x01(\x05R\x06stepId\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x12\n" +
+	"\x04tool\x18\x03 \x01(\tR\x04tool\x12\"\n" +
+	"\fdependencies\x18\x04 \x03(\x05R\fdependencies\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12\x16\n" +
+	"\x06result\x18\x06 \x01(\tR\x06result\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\x12\x14\n" +
+	"\x05agent\x18\b \x01(\tR\x05agent\x12\x14\n" +
+	"\x05input\x18\t \x01(\tR\x05input\"\x8e\x03\n" +
+	"\fPlanRevision\x12\x1a\n" +
+	"\brevision\x18\x01 \x01(\x05R\brevision\x12\x1a\n" +
+	"\bdecision\x18\x02 \x01(\tR\bdecision\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x14\n" +
+	"\x05score\x18\x04 \x01(\x01R\x05score\x12'\n" +
+	"\x0fevaluated_steps\x18\x05 \x03(\x05R\x0eevaluatedSteps\x12!\n" +
+	"\fretried_step\x18\x06 \x01(\x05R\vretriedStep\x12\x1f\n" +
+	"\vretry_input\x18\a \x01(\tR\n" +
+	"retryInput\x12C\n" +
+	"\vadded_steps\x18\b \x03(\v2\".api.agent.service.v1.PlanStepInfoR\n" +
+	"addedSteps\x12G\n" +
+	"\rremoved_steps\x18\t \x03(\v2\".api.agent.service.v1.PlanStepInfoR\fremovedSteps\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\tR\tcreatedAt\"\xd8\x02\n" +
 	"\x11ExecutionMetadata\x12\x1f\n" +
 	"\vtotal_steps\x18\x01 \x01(\x05R\n" +
 	"totalSteps\x12!\n" +
@@ -1831,7 +3933,13 @@ const file_api_agent_service_v1_agent_service_proto_rawDesc = "" +
 	"\n" +
 	"tool_names\x18\x03 \x03(\tR\ttoolNames\x12*\n" +
 	"\x11execution_time_ms\x18\x04 \x01(\x03R\x0fexecutionTimeMs\x12\x14\n" +
-	"\x05state\x18\x05 \x01(\tR\x05state\"\x85\x01\n" +
+	"\x05state\x18\x05 \x01(\tR\x05state\x12#\n" +
+	"\rprompt_tokens\x18\x06 \x01(\x03R\fpromptTokens\x12+\n" +
+	"\x11completion_tokens\x18\a \x01(\x03R\x10completionTokens\x12!\n" +
+	"\ftotal_tokens\x18\b \x01(\x03R\vtotalTokens\x12\x12\n" +
+	"\x04cost\x18\t \x01(\x01R\x04cost\x12\x15\n" +
+	"\x06run_id\x18\n" +
+	" \x01(\tR\x05runId\"\x85\x01\n" +
 	"\x12AgentTypesResponse\x124\n" +
 	"\x03ret\x18\x01 \x01(\v2\".api.agent.service.v1.BaseResponseR\x03ret\x129\n" +
 	"\x05types\x18\x02 \x03(\v2#.api.agent.service.v1.AgentTypeInfoR\x05types\"\x98\x01\n" +
@@ -1940,7 +4048,112 @@ const file_api_agent_service_v1_agent_service_proto_rawDesc = "" +
 	"\tis_active\x18\v \x01(\bR\bisActive\x12+\n" +
 	"\x11connection_config\x18\f \x01(\tR\x10connectionConfig\x12\x1f\n" +
 	"\vconfig_json\x18\r \x01(\tR\n" +
-	"configJson*W\n" +
+	"configJson\"Q\n" +
+	"\x0eSessionRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
+	"\bagent_id\x18\x02 \x01(\x05R\aagentId\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\"/\n" +
+	"\x12SessionListRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\x05R\aagentId\"#\n" +
+	"\x11SessionGetRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"&\n" +
+	"\x14SessionDeleteRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xb1\x01\n" +
+	"\vSessionInfo\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
+	"\bagent_id\x18\x02 \x01(\x05R\aagentId\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12#\n" +
+	"\rmessage_count\x18\x04 \x01(\x05R\fmessageCount\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x06 \x01(\tR\tupdatedAt\"\xd9\x01\n" +
+	"\x0eSessionMessage\x12\x12\n" +
+	"\x04role\x18\x01 \x01(\tR\x04role\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12 \n" +
+	"\ftool_call_id\x18\x04 \x01(\tR\n" +
+	"toolCallId\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\tR\tcreatedAt\x12D\n" +
+	"\n" +
+	"tool_calls\x18\x06 \x03(\v2%.api.agent.service.v1.SessionToolCallR\ttoolCalls\"S\n" +
+	"\x0fSessionToolCall\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1c\n" +
+	"\targuments\x18\x03 \x01(\tR\targuments\"\xc6\x01\n" +
+	"\x0fSessionResponse\x124\n" +
+	"\x03ret\x18\x01 \x01(\v2\".api.agent.service.v1.BaseResponseR\x03ret\x12;\n" +
+	"\asession\x18\x02 \x01(\v2!.api.agent.service.v1.SessionInfoR\asession\x12@\n" +
+	"\bmessages\x18\x03 \x03(\v2$.api.agent.service.v1.SessionMessageR\bmessages\"\x8a\x01\n" +
+	"\x13SessionListResponse\x124\n" +
+	"\x03ret\x18\x01 \x01(\v2\".api.agent.service.v1.BaseResponseR\x03ret\x12=\n" +
+	"\bsessions\x18\x02 \x03(\v2!.api.agent.service.v1.SessionInfoR\bsessions\"\x1f\n" +
+	"\rRunGetRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\"\n" +
+	"\x10RunResumeRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\"\n" +
+	"\x10RunCancelRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xeb\x03\n" +
+	"\aRunInfo\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
+	"\bagent_id\x18\x02 \x01(\x05R\aagentId\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x03 \x01(\tR\tsessionId\x12\x14\n" +
+	"\x05query\x18\x04 \x01(\tR\x05query\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12!\n" +
+	"\fcurrent_step\x18\x06 \x01(\x05R\vcurrentStep\x12\x16\n" +
+	"\x06result\x18\a \x01(\tR\x06result\x12D\n" +
+	"\ffinal_result\x18\b \x01(\v2!.api.agent.service.v1.FinalResultR\vfinalResult\x12\x14\n" +
+	"\x05error\x18\t \x01(\tR\x05error\x12!\n" +
+	"\fresume_count\x18\n" +
+	" \x01(\x05R\vresumeCount\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\v \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\f \x01(\tR\tupdatedAt\x12\x1f\n" +
+	"\vwebhook_url\x18\r \x01(\tR\n" +
+	"webhookUrl\x12O\n" +
+	"\x11pending_approvals\x18\x0e \x03(\v2\".api.agent.service.v1.ApprovalInfoR\x10pendingApprovals\"t\n" +
+	"\vRunResponse\x124\n" +
+	"\x03ret\x18\x01 \x01(\v2\".api.agent.service.v1.BaseResponseR\x03ret\x12/\n" +
+	"\x03run\x18\x02 \x01(\v2\x1d.api.agent.service.v1.RunInfoR\x03run\"p\n" +
+	"\x10SubmitRunRequest\x12;\n" +
+	"\arequest\x18\x01 \x01(\v2!.api.agent.service.v1.ChatRequestR\arequest\x12\x1f\n" +
+	"\vwebhook_url\x18\x02 \x01(\tR\n" +
+	"webhookUrl\"U\n" +
+	"\x10RunEventsRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tafter_seq\x18\x02 \x01(\x03R\bafterSeq\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"\x94\x01\n" +
+	"\bRunEvent\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x03R\x03seq\x12\x15\n" +
+	"\x06run_id\x18\x02 \x01(\tR\x05runId\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x18\n" +
+	"\acontent\x18\x04 \x01(\tR\acontent\x12\x12\n" +
+	"\x04step\x18\x05 \x01(\x05R\x04step\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\tR\tcreatedAt\"\x92\x01\n" +
+	"\fApprovalInfo\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x15\n" +
+	"\x06run_id\x18\x02 \x01(\tR\x05runId\x12 \n" +
+	"\ftool_call_id\x18\x03 \x01(\tR\n" +
+	"toolCallId\x12\x1b\n" +
+	"\ttool_name\x18\x04 \x01(\tR\btoolName\x12\x1c\n" +
+	"\targuments\x18\x05 \x01(\tR\targuments\"\x8d\x01\n" +
+	"\x16ResolveApprovalRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x15\n" +
+	"\x06run_id\x18\x05 \x01(\tR\x05runId\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x12\x1c\n" +
+	"\targuments\x18\x03 \x01(\tR\targuments\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\"\x8f\x01\n" +
+	"\x17ResolveApprovalResponse\x124\n" +
+	"\x03ret\x18\x01 \x01(\v2\".api.agent.service.v1.BaseResponseR\x03ret\x12>\n" +
+	"\bapproval\x18\x02 \x01(\v2\".api.agent.service.v1.ApprovalInfoR\bapproval\"\xb2\x01\n" +
+	"\x11RunEventsResponse\x124\n" +
+	"\x03ret\x18\x01 \x01(\v2\".api.agent.service.v1.BaseResponseR\x03ret\x126\n" +
+	"\x06events\x18\x02 \x03(\v2\x1e.api.agent.service.v1.RunEventR\x06events\x12/\n" +
+	"\x03run\x18\x03 \x01(\v2\x1d.api.agent.service.v1.RunInfoR\x03run*g\n" +
 	"\tAgentType\x12\t\n" +
 	"\x05REACT\x10\x00\x12\t\n" +
 	"\x05CHAIN\x10\x01\x12\b\n" +
@@ -1948,7 +4161,9 @@ const file_api_agent_service_v1_agent_service_proto_rawDesc = "" +
 	"\x03SQL\x10\x03\x12\x11\n" +
 	"\rELASTICSEARCH\x10\x04\x12\x0e\n" +
 	"\n" +
-	"ROOT_CAUSE\x10\x052\xc7\r\n" +
+	"ROOT_CAUSE\x10\x05\x12\x0e\n" +
+	"\n" +
+	"SUPERVISOR\x10\x062\xfb\x17\n" +
 	"\fAgentService\x12c\n" +
 	"\x04Chat\x12!.api.agent.service.v1.ChatRequest\x1a\".api.agent.service.v1.ChatResponse\"\x14\x82\xd3\xe4\x93\x02\x0e:\x01*\"\t/api/chat\x12x\n" +
 	"\n" +
@@ -1966,7 +4181,19 @@ const file_api_agent_service_v1_agent_service_proto_rawDesc = "" +
 	"\vDeleteAgent\x12(.api.agent.service.v1.AgentDeleteRequest\x1a).api.agent.service.v1.AgentConfigResponse\"\x18\x82\xd3\xe4\x93\x02\x12*\x10/api/agents/{id}\x12v\n" +
 	"\bGetAgent\x12%.api.agent.service.v1.AgentGetRequest\x1a).api.agent.service.v1.AgentConfigResponse\"\x18\x82\xd3\xe4\x93\x02\x12\x12\x10/api/agents/{id}\x12g\n" +
 	"\n" +
-	"ListAgents\x12\x1b.api.agent.service.v1.Empty\x1a'.api.agent.service.v1.AgentListResponse\"\x13\x82\xd3\xe4\x93\x02\r\x12\v/api/agentsB#Z!jas-agent/api/agent/service/v1;v1b\x06proto3"
+	"ListAgents\x12\x1b.api.agent.service.v1.Empty\x1a'.api.agent.service.v1.AgentListResponse\"\x13\x82\xd3\xe4\x93\x02\r\x12\v/api/agents\x12v\n" +
+	"\rCreateSession\x12$.api.agent.service.v1.SessionRequest\x1a%.api.agent.service.v1.SessionResponse\"\x18\x82\xd3\xe4\x93\x02\x12:\x01*\"\r/api/sessions\x12z\n" +
+	"\fListSessions\x12(.api.agent.service.v1.SessionListRequest\x1a).api.agent.service.v1.SessionListResponse\"\x15\x82\xd3\xe4\x93\x02\x0f\x12\r/api/sessions\x12x\n" +
+	"\n" +
+	"GetSession\x12'.api.agent.service.v1.SessionGetRequest\x1a%.api.agent.service.v1.SessionResponse\"\x1a\x82\xd3\xe4\x93\x02\x14\x12\x12/api/sessions/{id}\x12~\n" +
+	"\rDeleteSession\x12*.api.agent.service.v1.SessionDeleteRequest\x1a%.api.agent.service.v1.SessionResponse\"\x1a\x82\xd3\xe4\x93\x02\x14*\x12/api/sessions/{id}\x12h\n" +
+	"\x06GetRun\x12#.api.agent.service.v1.RunGetRequest\x1a!.api.agent.service.v1.RunResponse\"\x16\x82\xd3\xe4\x93\x02\x10\x12\x0e/api/runs/{id}\x12x\n" +
+	"\tResumeRun\x12&.api.agent.service.v1.RunResumeRequest\x1a!.api.agent.service.v1.RunResponse\" \x82\xd3\xe4\x93\x02\x1a:\x01*\"\x15/api/runs/{id}/resume\x12x\n" +
+	"\tCancelRun\x12&.api.agent.service.v1.RunCancelRequest\x1a!.api.agent.service.v1.RunResponse\" \x82\xd3\xe4\x93\x02\x1a:\x01*\"\x15/api/runs/{id}/cancel\x12l\n" +
+	"\tSubmitRun\x12&.api.agent.service.v1.SubmitRunRequest\x1a!.api.agent.service.v1.RunResponse\"\x14\x82\xd3\xe4\x93\x02\x0e:\x01*\"\t/api/runs\x12\x7f\n" +
+	"\rListRunEvents\x12&.api.agent.service.v1.RunEventsRequest\x1a'.api.agent.service.v1.RunEventsResponse\"\x1d\x82\xd3\xe4\x93\x02\x17\x12\x15/api/runs/{id}/events\x12X\n" +
+	"\fSubscribeRun\x12&.api.agent.service.v1.RunEventsRequest\x1a\x1e.api.agent.service.v1.RunEvent0\x01\x12\x9c\x01\n" +
+	"\x0fResolveApproval\x12,.api.agent.service.v1.ResolveApprovalRequest\x1a-.api.agent.service.v1.ResolveApprovalResponse\",\x82\xd3\xe4\x93\x02&:\x01*\"!/api/runs/{run_id}/approvals/{id}B#Z!jas-agent/api/agent/service/v1;v1b\x06proto3"
 
 var (
 	file_api_agent_service_v1_agent_service_proto_rawDescOnce sync.Once
@@ -1981,98 +4208,172 @@ func file_api_agent_service_v1_agent_service_proto_rawDescGZIP() []byte {
 }
 
 var file_api_agent_service_v1_agent_service_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_api_agent_service_v1_agent_service_proto_msgTypes = make([]protoimpl.MessageInfo, 26)
+var file_api_agent_service_v1_agent_service_proto_msgTypes = make([]protoimpl.MessageInfo, 52)
 var file_api_agent_service_v1_agent_service_proto_goTypes = []any{
 	(AgentType)(0),                      // 0: api.agent.service.v1.AgentType
 	(ChatStreamResponse_MessageType)(0), // 1: api.agent.service.v1.ChatStreamResponse.MessageType
 	(*Empty)(nil),                       // 2: api.agent.service.v1.Empty
 	(*ChatRequest)(nil),                 // 3: api.agent.service.v1.ChatRequest
 	(*ChatResponse)(nil),                // 4: api.agent.service.v1.ChatResponse
-	(*ChatStreamResponse)(nil),          // 5: api.agent.service.v1.ChatStreamResponse
-	(*ExecutionMetadata)(nil),           // 6: api.agent.service.v1.ExecutionMetadata
-	(*AgentTypesResponse)(nil),          // 7: api.agent.service.v1.AgentTypesResponse
-	(*AgentTypeInfo)(nil),               // 8: api.agent.service.v1.AgentTypeInfo
-	(*ToolsResponse)(nil),               // 9: api.agent.service.v1.ToolsResponse
-	(*ToolInfo)(nil),                    // 10: api.agent.service.v1.ToolInfo
-	(*MCPServiceRequest)(nil),           // 11: api.agent.service.v1.MCPServiceRequest
-	(*MCPServiceResponse)(nil),          // 12: api.agent.service.v1.MCPServiceResponse
-	(*MCPServicesResponse)(nil),         // 13: api.agent.service.v1.MCPServicesResponse
-	(*MCPServiceInfo)(nil),              // 14: api.agent.service.v1.MCPServiceInfo
-	(*MCPServiceWithIdInfo)(nil),        // 15: api.agent.service.v1.MCPServiceWithIdInfo
-	(*MCPServicesWithIdResponse)(nil),   // 16: api.agent.service.v1.MCPServicesWithIdResponse
-	(*MCPServiceToolsRequest)(nil),      // 17: api.agent.service.v1.MCPServiceToolsRequest
-	(*MCPServiceToolInfo)(nil),          // 18: api.agent.service.v1.MCPServiceToolInfo
-	(*MCPServiceToolsResponse)(nil),     // 19: api.agent.service.v1.MCPServiceToolsResponse
-	(*AgentConfigRequest)(nil),          // 20: api.agent.service.v1.AgentConfigRequest
-	(*AgentConfigResponse)(nil),         // 21: api.agent.service.v1.AgentConfigResponse
-	(*AgentDeleteRequest)(nil),          // 22: api.agent.service.v1.AgentDeleteRequest
-	(*AgentGetRequest)(nil),             // 23: api.agent.service.v1.AgentGetRequest
-	(*AgentListResponse)(nil),           // 24: api.agent.service.v1.AgentListResponse
-	(*AgentConfig)(nil),                 // 25: api.agent.service.v1.AgentConfig
-	nil,                                 // 26: api.agent.service.v1.ChatRequest.ConfigEntry
-	nil,                                 // 27: api.agent.service.v1.AgentConfigRequest.ConfigEntry
-	(*BaseResponse)(nil),                // 28: api.agent.service.v1.BaseResponse
-	(*structpb.Struct)(nil),             // 29: google.protobuf.Struct
+	(*FinalResult)(nil),                 // 5: api.agent.service.v1.FinalResult
+	(*ChainNodeTrace)(nil),              // 6: api.agent.service.v1.ChainNodeTrace
+	(*SubRunTrace)(nil),                 // 7: api.agent.service.v1.SubRunTrace
+	(*ChatStreamResponse)(nil),          // 8: api.agent.service.v1.ChatStreamResponse
+	(*PlanStepInfo)(nil),                // 9: api.agent.service.v1.PlanStepInfo
+	(*PlanRevision)(nil),                // 10: api.agent.service.v1.PlanRevision
+	(*ExecutionMetadata)(nil),           // 11: api.agent.service.v1.ExecutionMetadata
+	(*AgentTypesResponse)(nil),          // 12: api.agent.service.v1.AgentTypesResponse
+	(*AgentTypeInfo)(nil),               // 13: api.agent.service.v1.AgentTypeInfo
+	(*ToolsResponse)(nil),               // 14: api.agent.service.v1.ToolsResponse
+	(*ToolInfo)(nil),                    // 15: api.agent.service.v1.ToolInfo
+	(*MCPServiceRequest)(nil),           // 16: api.agent.service.v1.MCPServiceRequest
+	(*MCPServiceResponse)(nil),          // 17: api.agent.service.v1.MCPServiceResponse
+	(*MCPServicesResponse)(nil),         // 18: api.agent.service.v1.MCPServicesResponse
+	(*MCPServiceInfo)(nil),              // 19: api.agent.service.v1.MCPServiceInfo
+	(*MCPServiceWithIdInfo)(nil),        // 20: api.agent.service.v1.MCPServiceWithIdInfo
+	(*MCPServicesWithIdResponse)(nil),   // 21: api.agent.service.v1.MCPServicesWithIdResponse
+	(*MCPServiceToolsRequest)(nil),      // 22: api.agent.service.v1.MCPServiceToolsRequest
+	(*MCPServiceToolInfo)(nil),          // 23: api.agent.service.v1.MCPServiceToolInfo
+	(*MCPServiceToolsResponse)(nil),     // 24: api.agent.service.v1.MCPServiceToolsResponse
+	(*AgentConfigRequest)(nil),          // 25: api.agent.service.v1.AgentConfigRequest
+	(*AgentConfigResponse)(nil),         // 26: api.agent.service.v1.AgentConfigResponse
+	(*AgentDeleteRequest)(nil),          // 27: api.agent.service.v1.AgentDeleteRequest
+	(*AgentGetRequest)(nil),             // 28: api.agent.service.v1.AgentGetRequest
+	(*AgentListResponse)(nil),           // 29: api.agent.service.v1.AgentListResponse
+	(*AgentConfig)(nil),                 // 30: api.agent.service.v1.AgentConfig
+	(*SessionRequest)(nil),              // 31: api.agent.service.v1.SessionRequest
+	(*SessionListRequest)(nil),          // 32: api.agent.service.v1.SessionListRequest
+	(*SessionGetRequest)(nil),           // 33: api.agent.service.v1.SessionGetRequest
+	(*SessionDeleteRequest)(nil),        // 34: api.agent.service.v1.SessionDeleteRequest
+	(*SessionInfo)(nil),                 // 35: api.agent.service.v1.SessionInfo
+	(*SessionMessage)(nil),              // 36: api.agent.service.v1.SessionMessage
+	(*SessionToolCall)(nil),             // 37: api.agent.service.v1.SessionToolCall
+	(*SessionResponse)(nil),             // 38: api.agent.service.v1.SessionResponse
+	(*SessionListResponse)(nil),         // 39: api.agent.service.v1.SessionListResponse
+	(*RunGetRequest)(nil),               // 40: api.agent.service.v1.RunGetRequest
+	(*RunResumeRequest)(nil),            // 41: api.agent.service.v1.RunResumeRequest
+	(*RunCancelRequest)(nil),            // 42: api.agent.service.v1.RunCancelRequest
+	(*RunInfo)(nil),                     // 43: api.agent.service.v1.RunInfo
+	(*RunResponse)(nil),                 // 44: api.agent.service.v1.RunResponse
+	(*SubmitRunRequest)(nil),            // 45: api.agent.service.v1.SubmitRunRequest
+	(*RunEventsRequest)(nil),            // 46: api.agent.service.v1.RunEventsRequest
+	(*RunEvent)(nil),                    // 47: api.agent.service.v1.RunEvent
+	(*ApprovalInfo)(nil),                // 48: api.agent.service.v1.ApprovalInfo
+	(*ResolveApprovalRequest)(nil),      // 49: api.agent.service.v1.ResolveApprovalRequest
+	(*ResolveApprovalResponse)(nil),     // 50: api.agent.service.v1.ResolveApprovalResponse
+	(*RunEventsResponse)(nil),           // 51: api.agent.service.v1.RunEventsResponse
+	nil,                                 // 52: api.agent.service.v1.ChatRequest.ConfigEntry
+	nil,                                 // 53: api.agent.service.v1.AgentConfigRequest.ConfigEntry
+	(*BaseResponse)(nil),                // 54: api.agent.service.v1.BaseResponse
+	(*structpb.Struct)(nil),             // 55: google.protobuf.Struct
 }
 var file_api_agent_service_v1_agent_service_proto_depIdxs = []int32{
 	0,  // 0: api.agent.service.v1.ChatRequest.agent_type:type_name -> api.agent.service.v1.AgentType
-	26, // 1: api.agent.service.v1.ChatRequest.config:type_name -> api.agent.service.v1.ChatRequest.ConfigEntry
-	6,  // 2: api.agent.service.v1.ChatResponse.metadata:type_name -> api.agent.service.v1.ExecutionMetadata
-	28, // 3: api.agent.service.v1.ChatResponse.ret:type_name -> api.agent.service.v1.BaseResponse
-	1,  // 4: api.agent.service.v1.ChatStreamResponse.type:type_name -> api.agent.service.v1.ChatStreamResponse.MessageType
-	6,  // 5: api.agent.service.v1.ChatStreamResponse.metadata:type_name -> api.agent.service.v1.ExecutionMetadata
-	28, // 6: api.agent.service.v1.AgentTypesResponse.ret:type_name -> api.agent.service.v1.BaseResponse
-	8,  // 7: api.agent.service.v1.AgentTypesResponse.types:type_name -> api.agent.service.v1.AgentTypeInfo
-	0,  // 8: api.agent.service.v1.AgentTypeInfo.type:type_name -> api.agent.service.v1.AgentType
-	28, // 9: api.agent.service.v1.ToolsResponse.ret:type_name -> api.agent.service.v1.BaseResponse
-	10, // 10: api.agent.service.v1.ToolsResponse.tools:type_name -> api.agent.service.v1.ToolInfo
-	28, // 11: api.agent.service.v1.MCPServiceResponse.ret:type_name -> api.agent.service.v1.BaseResponse
-	14, // 12: api.agent.service.v1.MCPServiceResponse.service:type_name -> api.agent.service.v1.MCPServiceInfo
-	28, // 13: api.agent.service.v1.MCPServicesResponse.ret:type_name -> api.agent.service.v1.BaseResponse
-	14, // 14: api.agent.service.v1.MCPServicesResponse.services:type_name -> api.agent.service.v1.MCPServiceInfo
-	28, // 15: api.agent.service.v1.MCPServicesWithIdResponse.ret:type_name -> api.agent.service.v1.BaseResponse
-	15, // 16: api.agent.service.v1.MCPServicesWithIdResponse.services:type_name -> api.agent.service.v1.MCPServiceWithIdInfo
-	29, // 17: api.agent.service.v1.MCPServiceToolInfo.input_schema:type_name -> google.protobuf.Struct
-	28, // 18: api.agent.service.v1.MCPServiceToolsResponse.ret:type_name -> api.agent.service.v1.BaseResponse
-	18, // 19: api.agent.service.v1.MCPServiceToolsResponse.tools:type_name -> api.agent.service.v1.MCPServiceToolInfo
-	27, // 20: api.agent.service.v1.AgentConfigRequest.config:type_name -> api.agent.service.v1.AgentConfigRequest.ConfigEntry
-	28, // 21: api.agent.service.v1.AgentConfigResponse.ret:type_name -> api.agent.service.v1.BaseResponse
-	25, // 22: api.agent.service.v1.AgentConfigResponse.agent:type_name -> api.agent.service.v1.AgentConfig
-	28, // 23: api.agent.service.v1.AgentListResponse.ret:type_name -> api.agent.service.v1.BaseResponse
-	25, // 24: api.agent.service.v1.AgentListResponse.agents:type_name -> api.agent.service.v1.AgentConfig
-	3,  // 25: api.agent.service.v1.AgentService.Chat:input_type -> api.agent.service.v1.ChatRequest
-	3,  // 26: api.agent.service.v1.AgentService.StreamChat:input_type -> api.agent.service.v1.ChatRequest
-	2,  // 27: api.agent.service.v1.AgentService.ListAgentTypes:input_type -> api.agent.service.v1.Empty
-	2,  // 28: api.agent.service.v1.AgentService.ListTools:input_type -> api.agent.service.v1.Empty
-	11, // 29: api.agent.service.v1.AgentService.AddMCPService:input_type -> api.agent.service.v1.MCPServiceRequest
-	11, // 30: api.agent.service.v1.AgentService.RemoveMCPService:input_type -> api.agent.service.v1.MCPServiceRequest
-	2,  // 31: api.agent.service.v1.AgentService.ListMCPServices:input_type -> api.agent.service.v1.Empty
-	2,  // 32: api.agent.service.v1.AgentService.ListMCPServicesWithId:input_type -> api.agent.service.v1.Empty
-	17, // 33: api.agent.service.v1.AgentService.GetMCPServiceTools:input_type -> api.agent.service.v1.MCPServiceToolsRequest
-	20, // 34: api.agent.service.v1.AgentService.CreateAgent:input_type -> api.agent.service.v1.AgentConfigRequest
-	20, // 35: api.agent.service.v1.AgentService.UpdateAgent:input_type -> api.agent.service.v1.AgentConfigRequest
-	22, // 36: api.agent.service.v1.AgentService.DeleteAgent:input_type -> api.agent.service.v1.AgentDeleteRequest
-	23, // 37: api.agent.service.v1.AgentService.GetAgent:input_type -> api.agent.service.v1.AgentGetRequest
-	2,  // 38: api.agent.service.v1.AgentService.ListAgents:input_type -> api.agent.service.v1.Empty
-	4,  // 39: api.agent.service.v1.AgentService.Chat:output_type -> api.agent.service.v1.ChatResponse
-	5,  // 40: api.agent.service.v1.AgentService.StreamChat:output_type -> api.agent.service.v1.ChatStreamResponse
-	7,  // 41: api.agent.service.v1.AgentService.ListAgentTypes:output_type -> api.agent.service.v1.AgentTypesResponse
-	9,  // 42: api.agent.service.v1.AgentService.ListTools:output_type -> api.agent.service.v1.ToolsResponse
-	12, // 43: api.agent.service.v1.AgentService.AddMCPService:output_type -> api.agent.service.v1.MCPServiceResponse
-	12, // 44: api.agent.service.v1.AgentService.RemoveMCPService:output_type -> api.agent.service.v1.MCPServiceResponse
-	13, // 45: api.agent.service.v1.AgentService.ListMCPServices:output_type -> api.agent.service.v1.MCPServicesResponse
-	16, // 46: api.agent.service.v1.AgentService.ListMCPServicesWithId:output_type -> api.agent.service.v1.MCPServicesWithIdResponse
-	19, // 47: api.agent.service.v1.AgentService.GetMCPServiceTools:output_type -> api.agent.service.v1.MCPServiceToolsResponse
-	21, // 48: api.agent.service.v1.AgentService.CreateAgent:output_type -> api.agent.service.v1.AgentConfigResponse
-	21, // 49: api.agent.service.v1.AgentService.UpdateAgent:output_type -> api.agent.service.v1.AgentConfigResponse
-	21, // 50: api.agent.service.v1.AgentService.DeleteAgent:output_type -> api.agent.service.v1.AgentConfigResponse
-	21, // 51: api.agent.service.v1.AgentService.GetAgent:output_type -> api.agent.service.v1.AgentConfigResponse
-	24, // 52: api.agent.service.v1.AgentService.ListAgents:output_type -> api.agent.service.v1.AgentListResponse
-	39, // [39:53] is the sub-list for method output_type
-	25, // [25:39] is the sub-list for method input_type
-	25, // [25:25] is the sub-list for extension type_name
-	25, // [25:25] is the sub-list for extension extendee
-	0,  // [0:25] is the sub-list for field type_name
+	52, // 1: api.agent.service.v1.ChatRequest.config:type_name -> api.agent.service.v1.ChatRequest.ConfigEntry
+	11, // 2: api.agent.service.v1.ChatResponse.metadata:type_name -> api.agent.service.v1.ExecutionMetadata
+	54, // 3: api.agent.service.v1.ChatResponse.ret:type_name -> api.agent.service.v1.BaseResponse
+	5,  // 4: api.agent.service.v1.ChatResponse.final_result:type_name -> api.agent.service.v1.FinalResult
+	6,  // 5: api.agent.service.v1.FinalResult.chain_trace:type_name -> api.agent.service.v1.ChainNodeTrace
+	7,  // 6: api.agent.service.v1.FinalResult.sub_runs:type_name -> api.agent.service.v1.SubRunTrace
+	10, // 7: api.agent.service.v1.FinalResult.plan_revisions:type_name -> api.agent.service.v1.PlanRevision
+	7,  // 8: api.agent.service.v1.SubRunTrace.sub_runs:type_name -> api.agent.service.v1.SubRunTrace
+	1,  // 9: api.agent.service.v1.ChatStreamResponse.type:type_name -> api.agent.service.v1.ChatStreamResponse.MessageType
+	11, // 10: api.agent.service.v1.ChatStreamResponse.metadata:type_name -> api.agent.service.v1.ExecutionMetadata
+	5,  // 11: api.agent.service.v1.ChatStreamResponse.final_result:type_name -> api.agent.service.v1.FinalResult
+	48, // 12: api.agent.service.v1.ChatStreamResponse.approval:type_name -> api.agent.service.v1.ApprovalInfo
+	9,  // 13: api.agent.service.v1.ChatStreamResponse.plan_step:type_name -> api.agent.service.v1.PlanStepInfo
+	9,  // 14: api.agent.service.v1.PlanRevision.added_steps:type_name -> api.agent.service.v1.PlanStepInfo
+	9,  // 15: api.agent.service.v1.PlanRevision.removed_steps:type_name -> api.agent.service.v1.PlanStepInfo
+	54, // 16: api.agent.service.v1.AgentTypesResponse.ret:type_name -> api.agent.service.v1.BaseResponse
+	13, // 17: api.agent.service.v1.AgentTypesResponse.types:type_name -> api.agent.service.v1.AgentTypeInfo
+	0,  // 18: api.agent.service.v1.AgentTypeInfo.type:type_name -> api.agent.service.v1.AgentType
+	54, // 19: api.agent.service.v1.ToolsResponse.ret:type_name -> api.agent.service.v1.BaseResponse
+	15, // 20: api.agent.service.v1.ToolsResponse.tools:type_name -> api.agent.service.v1.ToolInfo
+	54, // 21: api.agent.service.v1.MCPServiceResponse.ret:type_name -> api.agent.service.v1.BaseResponse
+	19, // 22: api.agent.service.v1.MCPServiceResponse.service:type_name -> api.agent.service.v1.MCPServiceInfo
+	54, // 23: api.agent.service.v1.MCPServicesResponse.ret:type_name -> api.agent.service.v1.BaseResponse
+	19, // 24: api.agent.service.v1.MCPServicesResponse.services:type_name -> api.agent.service.v1.MCPServiceInfo
+	54, // 25: api.agent.service.v1.MCPServicesWithIdResponse.ret:type_name -> api.agent.service.v1.BaseResponse
+	20, // 26: api.agent.service.v1.MCPServicesWithIdResponse.services:type_name -> api.agent.service.v1.MCPServiceWithIdInfo
+	55, // 27: api.agent.service.v1.MCPServiceToolInfo.input_schema:type_name -> google.protobuf.Struct
+	54, // 28: api.agent.service.v1.MCPServiceToolsResponse.ret:type_name -> api.agent.service.v1.BaseResponse
+	23, // 29: api.agent.service.v1.MCPServiceToolsResponse.tools:type_name -> api.agent.service.v1.MCPServiceToolInfo
+	53, // 30: api.agent.service.v1.AgentConfigRequest.config:type_name -> api.agent.service.v1.AgentConfigRequest.ConfigEntry
+	54, // 31: api.agent.service.v1.AgentConfigResponse.ret:type_name -> api.agent.service.v1.BaseResponse
+	30, // 32: api.agent.service.v1.AgentConfigResponse.agent:type_name -> api.agent.service.v1.AgentConfig
+	54, // 33: api.agent.service.v1.AgentListResponse.ret:type_name -> api.agent.service.v1.BaseResponse
+	30, // 34: api.agent.service.v1.AgentListResponse.agents:type_name -> api.agent.service.v1.AgentConfig
+	37, // 35: api.agent.service.v1.SessionMessage.tool_calls:type_name -> api.agent.service.v1.SessionToolCall
+	54, // 36: api.agent.service.v1.SessionResponse.ret:type_name -> api.agent.service.v1.BaseResponse
+	35, // 37: api.agent.service.v1.SessionResponse.session:type_name -> api.agent.service.v1.SessionInfo
+	36, // 38: api.agent.service.v1.SessionResponse.messages:type_name -> api.agent.service.v1.SessionMessage
+	54, // 39: api.agent.service.v1.SessionListResponse.ret:type_name -> api.agent.service.v1.BaseResponse
+	35, // 40: api.agent.service.v1.SessionListResponse.sessions:type_name -> api.agent.service.v1.SessionInfo
+	5,  // 41: api.agent.service.v1.RunInfo.final_result:type_name -> api.agent.service.v1.FinalResult
+	48, // 42: api.agent.service.v1.RunInfo.pending_approvals:type_name -> api.agent.service.v1.ApprovalInfo
+	54, // 43: api.agent.service.v1.RunResponse.ret:type_name -> api.agent.service.v1.BaseResponse
+	43, // 44: api.agent.service.v1.RunResponse.run:type_name -> api.agent.service.v1.RunInfo
+	3,  // 45: api.agent.service.v1.SubmitRunRequest.request:type_name -> api.agent.service.v1.ChatRequest
+	54, // 46: api.agent.service.v1.ResolveApprovalResponse.ret:type_name -> api.agent.service.v1.BaseResponse
+	48, // 47: api.agent.service.v1.ResolveApprovalResponse.approval:type_name -> api.agent.service.v1.ApprovalInfo
+	54, // 48: api.agent.service.v1.RunEventsResponse.ret:type_name -> api.agent.service.v1.BaseResponse
+	47, // 49: api.agent.service.v1.RunEventsResponse.events:type_name -> api.agent.service.v1.RunEvent
+	43, // 50: api.agent.service.v1.RunEventsResponse.run:type_name -> api.agent.service.v1.RunInfo
+	3,  // 51: api.agent.service.v1.AgentService.Chat:input_type -> api.agent.service.v1.ChatRequest
+	3,  // 52: api.agent.service.v1.AgentService.StreamChat:input_type -> api.agent.service.v1.ChatRequest
+	2,  // 53: api.agent.service.v1.AgentService.ListAgentTypes:input_type -> api.agent.service.v1.Empty
+	2,  // 54: api.agent.service.v1.AgentService.ListTools:input_type -> api.agent.service.v1.Empty
+	16, // 55: api.agent.service.v1.AgentService.AddMCPService:input_type -> api.agent.service.v1.MCPServiceRequest
+	16, // 56: api.agent.service.v1.AgentService.RemoveMCPService:input_type -> api.agent.service.v1.MCPServiceRequest
+	2,  // 57: api.agent.service.v1.AgentService.ListMCPServices:input_type -> api.agent.service.v1.Empty
+	2,  // 58: api.agent.service.v1.AgentService.ListMCPServicesWithId:input_type -> api.agent.service.v1.Empty
+	22, // 59: api.agent.service.v1.AgentService.GetMCPServiceTools:input_type -> api.agent.service.v1.MCPServiceToolsRequest
+	25, // 60: api.agent.service.v1.AgentService.CreateAgent:input_type -> api.agent.service.v1.AgentConfigRequest
+	25, // 61: api.agent.service.v1.AgentService.UpdateAgent:input_type -> api.agent.service.v1.AgentConfigRequest
+	27, // 62: api.agent.service.v1.AgentService.DeleteAgent:input_type -> api.agent.service.v1.AgentDeleteRequest
+	28, // 63: api.agent.service.v1.AgentService.GetAgent:input_type -> api.agent.service.v1.AgentGetRequest
+	2,  // 64: api.agent.service.v1.AgentService.ListAgents:input_type -> api.agent.service.v1.Empty
+	31, // 65: api.agent.service.v1.AgentService.CreateSession:input_type -> api.agent.service.v1.SessionRequest
+	32, // 66: api.agent.service.v1.AgentService.ListSessions:input_type -> api.agent.service.v1.SessionListRequest
+	33, // 67: api.agent.service.v1.AgentService.GetSession:input_type -> api.agent.service.v1.SessionGetRequest
+	34, // 68: api.agent.service.v1.AgentService.DeleteSession:input_type -> api.agent.service.v1.SessionDeleteRequest
+	40, // 69: api.agent.service.v1.AgentService.GetRun:input_type -> api.agent.service.v1.RunGetRequest
+	41, // 70: api.agent.service.v1.AgentService.ResumeRun:input_type -> api.agent.service.v1.RunResumeRequest
+	42, // 71: api.agent.service.v1.AgentService.CancelRun:input_type -> api.agent.service.v1.RunCancelRequest
+	45, // 72: api.agent.service.v1.AgentService.SubmitRun:input_type -> api.agent.service.v1.SubmitRunRequest
+	46, // 73: api.agent.service.v1.AgentService.ListRunEvents:input_type -> api.agent.service.v1.RunEventsRequest
+	46, // 74: api.agent.service.v1.AgentService.SubscribeRun:input_type -> api.agent.service.v1.RunEventsRequest
+	49, // 75: api.agent.service.v1.AgentService.ResolveApproval:input_type -> api.agent.service.v1.ResolveApprovalRequest
+	4,  // 76: api.agent.service.v1.AgentService.Chat:output_type -> api.agent.service.v1.ChatResponse
+	8,  // 77: api.agent.service.v1.AgentService.StreamChat:output_type -> api.agent.service.v1.ChatStreamResponse
+	12, // 78: api.agent.service.v1.AgentService.ListAgentTypes:output_type -> api.agent.service.v1.AgentTypesResponse
+	14, // 79: api.agent.service.v1.AgentService.ListTools:output_type -> api.agent.service.v1.ToolsResponse
+	17, // 80: api.agent.service.v1.AgentService.AddMCPService:output_type -> api.agent.service.v1.MCPServiceResponse
+	17, // 81: api.agent.service.v1.AgentService.RemoveMCPService:output_type -> api.agent.service.v1.MCPServiceResponse
+	18, // 82: api.agent.service.v1.AgentService.ListMCPServices:output_type -> api.agent.service.v1.MCPServicesResponse
+	21, // 83: api.agent.service.v1.AgentService.ListMCPServicesWithId:output_type -> api.agent.service.v1.MCPServicesWithIdResponse
+	24, // 84: api.agent.service.v1.AgentService.GetMCPServiceTools:output_type -> api.agent.service.v1.MCPServiceToolsResponse
+	26, // 85: api.agent.service.v1.AgentService.CreateAgent:output_type -> api.agent.service.v1.AgentConfigResponse
+	26, // 86: api.agent.service.v1.AgentService.UpdateAgent:output_type -> api.agent.service.v1.AgentConfigResponse
+	26, // 87: api.agent.service.v1.AgentService.DeleteAgent:output_type -> api.agent.service.v1.AgentConfigResponse
+	26, // 88: api.agent.service.v1.AgentService.GetAgent:output_type -> api.agent.service.v1.AgentConfigResponse
+	29, // 89: api.agent.service.v1.AgentService.ListAgents:output_type -> api.agent.service.v1.AgentListResponse
+	38, // 90: api.agent.service.v1.AgentService.CreateSession:output_type -> api.agent.service.v1.SessionResponse
+	39, // 91: api.agent.service.v1.AgentService.ListSessions:output_type -> api.agent.service.v1.SessionListResponse
+	38, // 92: api.agent.service.v1.AgentService.GetSession:output_type -> api.agent.service.v1.SessionResponse
+	38, // 93: api.agent.service.v1.AgentService.DeleteSession:output_type -> api.agent.service.v1.SessionResponse
+	44, // 94: api.agent.service.v1.AgentService.GetRun:output_type -> api.agent.service.v1.RunResponse
+	44, // 95: api.agent.service.v1.AgentService.ResumeRun:output_type -> api.agent.service.v1.RunResponse
+	44, // 96: api.agent.service.v1.AgentService.CancelRun:output_type -> api.agent.service.v1.RunResponse
+	44, // 97: api.agent.service.v1.AgentService.SubmitRun:output_type -> api.agent.service.v1.RunResponse
+	51, // 98: api.agent.service.v1.AgentService.ListRunEvents:output_type -> api.agent.service.v1.RunEventsResponse
+	47, // 99: api.agent.service.v1.AgentService.SubscribeRun:output_type -> api.agent.service.v1.RunEvent
+	50, // 100: api.agent.service.v1.AgentService.ResolveApproval:output_type -> api.agent.service.v1.ResolveApprovalResponse
+	76, // [76:101] is the sub-list for method output_type
+	51, // [51:76] is the sub-list for method input_type
+	51, // [51:51] is the sub-list for extension type_name
+	51, // [51:51] is the sub-list for extension extendee
+	0,  // [0:51] is the sub-list for field type_name
 }
 
 func init() { file_api_agent_service_v1_agent_service_proto_init() }
@@ -2087,7 +4388,7 @@ func file_api_agent_service_v1_agent_service_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_agent_service_v1_agent_service_proto_rawDesc), len(file_api_agent_service_v1_agent_service_proto_rawDesc)),
 			NumEnums:      2,
-			NumMessages:   26,
+			NumMessages:   52,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
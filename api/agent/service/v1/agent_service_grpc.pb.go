@@ -33,6 +33,17 @@ const (
 	AgentService_DeleteAgent_FullMethodName           = "/api.agent.service.v1.AgentService/DeleteAgent"
 	AgentService_GetAgent_FullMethodName              = "/api.agent.service.v1.AgentService/GetAgent"
 	AgentService_ListAgents_FullMethodName            = "/api.agent.service.v1.AgentService/ListAgents"
+	AgentService_CreateSession_FullMethodName         = "/api.agent.service.v1.AgentService/CreateSession"
+	AgentService_ListSessions_FullMethodName          = "/api.agent.service.v1.AgentService/ListSessions"
+	AgentService_GetSession_FullMethodName            = "/api.agent.service.v1.AgentService/GetSession"
+	AgentService_DeleteSession_FullMethodName         = "/api.agent.service.v1.AgentService/DeleteSession"
+	AgentService_GetRun_FullMethodName                = "/api.agent.service.v1.AgentService/GetRun"
+	AgentService_ResumeRun_FullMethodName             = "/api.agent.service.v1.AgentService/ResumeRun"
+	AgentService_CancelRun_FullMethodName             = "/api.agent.service.v1.AgentService/CancelRun"
+	AgentService_SubmitRun_FullMethodName             = "/api.agent.service.v1.AgentService/SubmitRun"
+	AgentService_ListRunEvents_FullMethodName         = "/api.agent.service.v1.AgentService/ListRunEvents"
+	AgentService_SubscribeRun_FullMethodName          = "/api.agent.service.v1.AgentService/SubscribeRun"
+	AgentService_ResolveApproval_FullMethodName       = "/api.agent.service.v1.AgentService/ResolveApproval"
 )
 
 // AgentServiceClient is the client API for AgentService service.
@@ -61,6 +72,23 @@ type AgentServiceClient interface {
 	DeleteAgent(ctx context.Context, in *AgentDeleteRequest, opts ...grpc.CallOption) (*AgentConfigResponse, error)
 	GetAgent(ctx context.Context, in *AgentGetRequest, opts ...grpc.CallOption) (*AgentConfigResponse, error)
 	ListAgents(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*AgentListResponse, error)
+	// 会话管理
+	CreateSession(ctx context.Context, in *SessionRequest, opts ...grpc.CallOption) (*SessionResponse, error)
+	ListSessions(ctx context.Context, in *SessionListRequest, opts ...grpc.CallOption) (*SessionListResponse, error)
+	GetSession(ctx context.Context, in *SessionGetRequest, opts ...grpc.CallOption) (*SessionResponse, error)
+	DeleteSession(ctx context.Context, in *SessionDeleteRequest, opts ...grpc.CallOption) (*SessionResponse, error)
+	// 运行管理
+	GetRun(ctx context.Context, in *RunGetRequest, opts ...grpc.CallOption) (*RunResponse, error)
+	ResumeRun(ctx context.Context, in *RunResumeRequest, opts ...grpc.CallOption) (*RunResponse, error)
+	CancelRun(ctx context.Context, in *RunCancelRequest, opts ...grpc.CallOption) (*RunResponse, error)
+	// 异步运行：提交后立即返回运行ID，由后台 worker 执行
+	SubmitRun(ctx context.Context, in *SubmitRunRequest, opts ...grpc.CallOption) (*RunResponse, error)
+	// 分页拉取运行事件
+	ListRunEvents(ctx context.Context, in *RunEventsRequest, opts ...grpc.CallOption) (*RunEventsResponse, error)
+	// 订阅运行事件：先回放 after_seq 之后的事件，运行结束后关闭流
+	SubscribeRun(ctx context.Context, in *RunEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RunEvent], error)
+	// 审批等待确认的工具调用：批准、修改参数后执行或拒绝
+	ResolveApproval(ctx context.Context, in *ResolveApprovalRequest, opts ...grpc.CallOption) (*ResolveApprovalResponse, error)
 }
 
 type agentServiceClient struct {
@@ -220,6 +248,125 @@ func (c *agentServiceClient) ListAgents(ctx context.Context, in *Empty, opts ...
 	return out, nil
 }
 
+func (c *agentServiceClient) CreateSession(ctx context.Context, in *SessionRequest, opts ...grpc.CallOption) (*SessionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SessionResponse)
+	err := c.cc.Invoke(ctx, AgentService_CreateSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) ListSessions(ctx context.Context, in *SessionListRequest, opts ...grpc.CallOption) (*SessionListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SessionListResponse)
+	err := c.cc.Invoke(ctx, AgentService_ListSessions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) GetSession(ctx context.Context, in *SessionGetRequest, opts ...grpc.CallOption) (*SessionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SessionResponse)
+	err := c.cc.Invoke(ctx, AgentService_GetSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) DeleteSession(ctx context.Context, in *SessionDeleteRequest, opts ...grpc.CallOption) (*SessionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SessionResponse)
+	err := c.cc.Invoke(ctx, AgentService_DeleteSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) GetRun(ctx context.Context, in *RunGetRequest, opts ...grpc.CallOption) (*RunResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RunResponse)
+	err := c.cc.Invoke(ctx, AgentService_GetRun_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) ResumeRun(ctx context.Context, in *RunResumeRequest, opts ...grpc.CallOption) (*RunResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RunResponse)
+	err := c.cc.Invoke(ctx, AgentService_ResumeRun_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) CancelRun(ctx context.Context, in *RunCancelRequest, opts ...grpc.CallOption) (*RunResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RunResponse)
+	err := c.cc.Invoke(ctx, AgentService_CancelRun_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) SubmitRun(ctx context.Context, in *SubmitRunRequest, opts ...grpc.CallOption) (*RunResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RunResponse)
+	err := c.cc.Invoke(ctx, AgentService_SubmitRun_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) ListRunEvents(ctx context.Context, in *RunEventsRequest, opts ...grpc.CallOption) (*RunEventsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RunEventsResponse)
+	err := c.cc.Invoke(ctx, AgentService_ListRunEvents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) SubscribeRun(ctx context.Context, in *RunEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RunEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[1], AgentService_SubscribeRun_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[RunEventsRequest, RunEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_SubscribeRunClient = grpc.ServerStreamingClient[RunEvent]
+
+func (c *agentServiceClient) ResolveApproval(ctx context.Context, in *ResolveApprovalRequest, opts ...grpc.CallOption) (*ResolveApprovalResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResolveApprovalResponse)
+	err := c.cc.Invoke(ctx, AgentService_ResolveApproval_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AgentServiceServer is the server API for AgentService service.
 // All implementations must embed UnimplementedAgentServiceServer
 // for forward compatibility.
@@ -246,6 +393,23 @@ type AgentServiceServer interface {
 	DeleteAgent(context.Context, *AgentDeleteRequest) (*AgentConfigResponse, error)
 	GetAgent(context.Context, *AgentGetRequest) (*AgentConfigResponse, error)
 	ListAgents(context.Context, *Empty) (*AgentListResponse, error)
+	// 会话管理
+	CreateSession(context.Context, *SessionRequest) (*SessionResponse, error)
+	ListSessions(context.Context, *SessionListRequest) (*SessionListResponse, error)
+	GetSession(context.Context, *SessionGetRequest) (*SessionResponse, error)
+	DeleteSession(context.Context, *SessionDeleteRequest) (*SessionResponse, error)
+	// 运行管理
+	GetRun(context.Context, *RunGetRequest) (*RunResponse, error)
+	ResumeRun(context.Context, *RunResumeRequest) (*RunResponse, error)
+	CancelRun(context.Context, *RunCancelRequest) (*RunResponse, error)
+	// 异步运行：提交后立即返回运行ID，由后台 worker 执行
+	SubmitRun(context.Context, *SubmitRunRequest) (*RunResponse, error)
+	// 分页拉取运行事件
+	ListRunEvents(context.Context, *RunEventsRequest) (*RunEventsResponse, error)
+	// 订阅运行事件：先回放 after_seq 之后的事件，运行结束后关闭流
+	SubscribeRun(*RunEventsRequest, grpc.ServerStreamingServer[RunEvent]) error
+	// 审批等待确认的工具调用：批准、修改参数后执行或拒绝
+	ResolveApproval(context.Context, *ResolveApprovalRequest) (*ResolveApprovalResponse, error)
 	mustEmbedUnimplementedAgentServiceServer()
 }
 
@@ -298,6 +462,39 @@ func (UnimplementedAgentServiceServer) GetAgent(context.Context, *AgentGetReques
 func (UnimplementedAgentServiceServer) ListAgents(context.Context, *Empty) (*AgentListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListAgents not implemented")
 }
+func (UnimplementedAgentServiceServer) CreateSession(context.Context, *SessionRequest) (*SessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateSession not implemented")
+}
+func (UnimplementedAgentServiceServer) ListSessions(context.Context, *SessionListRequest) (*SessionListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSessions not implemented")
+}
+func (UnimplementedAgentServiceServer) GetSession(context.Context, *SessionGetRequest) (*SessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSession not implemented")
+}
+func (UnimplementedAgentServiceServer) DeleteSession(context.Context, *SessionDeleteRequest) (*SessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSession not implemented")
+}
+func (UnimplementedAgentServiceServer) GetRun(context.Context, *RunGetRequest) (*RunResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRun not implemented")
+}
+func (UnimplementedAgentServiceServer) ResumeRun(context.Context, *RunResumeRequest) (*RunResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeRun not implemented")
+}
+func (UnimplementedAgentServiceServer) CancelRun(context.Context, *RunCancelRequest) (*RunResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelRun not implemented")
+}
+func (UnimplementedAgentServiceServer) SubmitRun(context.Context, *SubmitRunRequest) (*RunResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitRun not implemented")
+}
+func (UnimplementedAgentServiceServer) ListRunEvents(context.Context, *RunEventsRequest) (*RunEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRunEvents not implemented")
+}
+func (UnimplementedAgentServiceServer) SubscribeRun(*RunEventsRequest, grpc.ServerStreamingServer[RunEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeRun not implemented")
+}
+func (UnimplementedAgentServiceServer) ResolveApproval(context.Context, *ResolveApprovalRequest) (*ResolveApprovalResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResolveApproval not implemented")
+}
 func (UnimplementedAgentServiceServer) mustEmbedUnimplementedAgentServiceServer() {}
 func (UnimplementedAgentServiceServer) testEmbeddedByValue()                      {}
 
@@ -564,6 +761,197 @@ func _AgentService_ListAgents_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AgentService_CreateSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).CreateSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_CreateSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).CreateSession(ctx, req.(*SessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_ListSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SessionListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).ListSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_ListSessions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).ListSessions(ctx, req.(*SessionListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_GetSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SessionGetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).GetSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_GetSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).GetSession(ctx, req.(*SessionGetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_DeleteSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SessionDeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).DeleteSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_DeleteSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).DeleteSession(ctx, req.(*SessionDeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_GetRun_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunGetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).GetRun(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_GetRun_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).GetRun(ctx, req.(*RunGetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_ResumeRun_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunResumeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).ResumeRun(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_ResumeRun_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).ResumeRun(ctx, req.(*RunResumeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_CancelRun_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunCancelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).CancelRun(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_CancelRun_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).CancelRun(ctx, req.(*RunCancelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_SubmitRun_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitRunRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).SubmitRun(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_SubmitRun_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).SubmitRun(ctx, req.(*SubmitRunRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_ListRunEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).ListRunEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_ListRunEvents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).ListRunEvents(ctx, req.(*RunEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_SubscribeRun_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RunEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AgentServiceServer).SubscribeRun(m, &grpc.GenericServerStream[RunEventsRequest, RunEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_SubscribeRunServer = grpc.ServerStreamingServer[RunEvent]
+
+func _AgentService_ResolveApproval_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResolveApprovalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).ResolveApproval(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_ResolveApproval_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).ResolveApproval(ctx, req.(*ResolveApprovalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // AgentService_ServiceDesc is the grpc.ServiceDesc for AgentService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -623,6 +1011,46 @@ var AgentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListAgents",
 			Handler:    _AgentService_ListAgents_Handler,
 		},
+		{
+			MethodName: "CreateSession",
+			Handler:    _AgentService_CreateSession_Handler,
+		},
+		{
+			MethodName: "ListSessions",
+			Handler:    _AgentService_ListSessions_Handler,
+		},
+		{
+			MethodName: "GetSession",
+			Handler:    _AgentService_GetSession_Handler,
+		},
+		{
+			MethodName: "DeleteSession",
+			Handler:    _AgentService_DeleteSession_Handler,
+		},
+		{
+			MethodName: "GetRun",
+			Handler:    _AgentService_GetRun_Handler,
+		},
+		{
+			MethodName: "ResumeRun",
+			Handler:    _AgentService_ResumeRun_Handler,
+		},
+		{
+			MethodName: "CancelRun",
+			Handler:    _AgentService_CancelRun_Handler,
+		},
+		{
+			MethodName: "SubmitRun",
+			Handler:    _AgentService_SubmitRun_Handler,
+		},
+		{
+			MethodName: "ListRunEvents",
+			Handler:    _AgentService_ListRunEvents_Handler,
+		},
+		{
+			MethodName: "ResolveApproval",
+			Handler:    _AgentService_ResolveApproval_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -630,6 +1058,11 @@ var AgentService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _AgentService_StreamChat_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "SubscribeRun",
+			Handler:       _AgentService_SubscribeRun_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "api/agent/service/v1/agent_service.proto",
 }
@@ -20,37 +20,62 @@ var _ = binding.EncodeURL
 const _ = http.SupportPackageIsVersion1
 
 const OperationAgentServiceAddMCPService = "/api.agent.service.v1.AgentService/AddMCPService"
+const OperationAgentServiceCancelRun = "/api.agent.service.v1.AgentService/CancelRun"
 const OperationAgentServiceChat = "/api.agent.service.v1.AgentService/Chat"
 const OperationAgentServiceCreateAgent = "/api.agent.service.v1.AgentService/CreateAgent"
+const OperationAgentServiceCreateSession = "/api.agent.service.v1.AgentService/CreateSession"
 const OperationAgentServiceDeleteAgent = "/api.agent.service.v1.AgentService/DeleteAgent"
+const OperationAgentServiceDeleteSession = "/api.agent.service.v1.AgentService/DeleteSession"
 const OperationAgentServiceGetAgent = "/api.agent.service.v1.AgentService/GetAgent"
 const OperationAgentServiceGetMCPServiceTools = "/api.agent.service.v1.AgentService/GetMCPServiceTools"
+const OperationAgentServiceGetRun = "/api.agent.service.v1.AgentService/GetRun"
+const OperationAgentServiceGetSession = "/api.agent.service.v1.AgentService/GetSession"
 const OperationAgentServiceListAgentTypes = "/api.agent.service.v1.AgentService/ListAgentTypes"
 const OperationAgentServiceListAgents = "/api.agent.service.v1.AgentService/ListAgents"
 const OperationAgentServiceListMCPServices = "/api.agent.service.v1.AgentService/ListMCPServices"
 const OperationAgentServiceListMCPServicesWithId = "/api.agent.service.v1.AgentService/ListMCPServicesWithId"
+const OperationAgentServiceListRunEvents = "/api.agent.service.v1.AgentService/ListRunEvents"
+const OperationAgentServiceListSessions = "/api.agent.service.v1.AgentService/ListSessions"
 const OperationAgentServiceListTools = "/api.agent.service.v1.AgentService/ListTools"
 const OperationAgentServiceRemoveMCPService = "/api.agent.service.v1.AgentService/RemoveMCPService"
+const OperationAgentServiceResolveApproval = "/api.agent.service.v1.AgentService/ResolveApproval"
+const OperationAgentServiceResumeRun = "/api.agent.service.v1.AgentService/ResumeRun"
+const OperationAgentServiceSubmitRun = "/api.agent.service.v1.AgentService/SubmitRun"
 const OperationAgentServiceUpdateAgent = "/api.agent.service.v1.AgentService/UpdateAgent"
 
 type AgentServiceHTTPServer interface {
 	// AddMCPService MCP 服务管理
 	AddMCPService(context.Context, *MCPServiceRequest) (*MCPServiceResponse, error)
+	CancelRun(context.Context, *RunCancelRequest) (*RunResponse, error)
 	// Chat 单次对话请求
 	Chat(context.Context, *ChatRequest) (*ChatResponse, error)
 	// CreateAgent Agent 管理
 	CreateAgent(context.Context, *AgentConfigRequest) (*AgentConfigResponse, error)
+	// CreateSession 会话管理
+	CreateSession(context.Context, *SessionRequest) (*SessionResponse, error)
 	DeleteAgent(context.Context, *AgentDeleteRequest) (*AgentConfigResponse, error)
+	DeleteSession(context.Context, *SessionDeleteRequest) (*SessionResponse, error)
 	GetAgent(context.Context, *AgentGetRequest) (*AgentConfigResponse, error)
 	GetMCPServiceTools(context.Context, *MCPServiceToolsRequest) (*MCPServiceToolsResponse, error)
+	// GetRun 运行管理
+	GetRun(context.Context, *RunGetRequest) (*RunResponse, error)
+	GetSession(context.Context, *SessionGetRequest) (*SessionResponse, error)
 	// ListAgentTypes 获取可用的Agent类型
 	ListAgentTypes(context.Context, *Empty) (*AgentTypesResponse, error)
 	ListAgents(context.Context, *Empty) (*AgentListResponse, error)
 	ListMCPServices(context.Context, *Empty) (*MCPServicesResponse, error)
 	ListMCPServicesWithId(context.Context, *Empty) (*MCPServicesWithIdResponse, error)
+	// ListRunEvents 分页拉取运行事件
+	ListRunEvents(context.Context, *RunEventsRequest) (*RunEventsResponse, error)
+	ListSessions(context.Context, *SessionListRequest) (*SessionListResponse, error)
 	// ListTools 获取可用的工具列表
 	ListTools(context.Context, *Empty) (*ToolsResponse, error)
 	RemoveMCPService(context.Context, *MCPServiceRequest) (*MCPServiceResponse, error)
+	// ResolveApproval 审批等待确认的工具调用：批准、修改参数后执行或拒绝
+	ResolveApproval(context.Context, *ResolveApprovalRequest) (*ResolveApprovalResponse, error)
+	ResumeRun(context.Context, *RunResumeRequest) (*RunResponse, error)
+	// SubmitRun 异步运行：提交后立即返回运行ID，由后台 worker 执行
+	SubmitRun(context.Context, *SubmitRunRequest) (*RunResponse, error)
 	UpdateAgent(context.Context, *AgentConfigRequest) (*AgentConfigResponse, error)
 }
 
@@ -69,6 +94,16 @@ func RegisterAgentServiceHTTPServer(s *http.Server, srv AgentServiceHTTPServer)
 	r.DELETE("/api/agents/{id}", _AgentService_DeleteAgent0_HTTP_Handler(srv))
 	r.GET("/api/agents/{id}", _AgentService_GetAgent0_HTTP_Handler(srv))
 	r.GET("/api/agents", _AgentService_ListAgents0_HTTP_Handler(srv))
+	r.POST("/api/sessions", _AgentService_CreateSession0_HTTP_Handler(srv))
+	r.GET("/api/sessions", _AgentService_ListSessions0_HTTP_Handler(srv))
+	r.GET("/api/sessions/{id}", _AgentService_GetSession0_HTTP_Handler(srv))
+	r.DELETE("/api/sessions/{id}", _AgentService_DeleteSession0_HTTP_Handler(srv))
+	r.GET("/api/runs/{id}", _AgentService_GetRun0_HTTP_Handler(srv))
+	r.POST("/api/runs/{id}/resume", _AgentService_ResumeRun0_HTTP_Handler(srv))
+	r.POST("/api/runs/{id}/cancel", _AgentService_CancelRun0_HTTP_Handler(srv))
+	r.POST("/api/runs", _AgentService_SubmitRun0_HTTP_Handler(srv))
+	r.GET("/api/runs/{id}/events", _AgentService_ListRunEvents0_HTTP_Handler(srv))
+	r.POST("/api/runs/{run_id}/approvals/{id}", _AgentService_ResolveApproval0_HTTP_Handler(srv))
 }
 
 func _AgentService_Chat0_HTTP_Handler(srv AgentServiceHTTPServer) func(ctx http.Context) error {
@@ -345,19 +380,255 @@ func _AgentService_ListAgents0_HTTP_Handler(srv AgentServiceHTTPServer) func(ctx
 	}
 }
 
+func _AgentService_CreateSession0_HTTP_Handler(srv AgentServiceHTTPServer) func(ctx http.Context) error {
+	return func(ctx http.Context) error {
+		var in SessionRequest
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		if err := ctx.BindQuery(&in); err != nil {
+			return err
+		}
+		http.SetOperation(ctx, OperationAgentServiceCreateSession)
+		h := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.CreateSession(ctx, req.(*SessionRequest))
+		})
+		out, err := h(ctx, &in)
+		if err != nil {
+			return err
+		}
+		reply := out.(*SessionResponse)
+		return ctx.Result(200, reply)
+	}
+}
+
+func _AgentService_ListSessions0_HTTP_Handler(srv AgentServiceHTTPServer) func(ctx http.Context) error {
+	return func(ctx http.Context) error {
+		var in SessionListRequest
+		if err := ctx.BindQuery(&in); err != nil {
+			return err
+		}
+		http.SetOperation(ctx, OperationAgentServiceListSessions)
+		h := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.ListSessions(ctx, req.(*SessionListRequest))
+		})
+		out, err := h(ctx, &in)
+		if err != nil {
+			return err
+		}
+		reply := out.(*SessionListResponse)
+		return ctx.Result(200, reply)
+	}
+}
+
+func _AgentService_GetSession0_HTTP_Handler(srv AgentServiceHTTPServer) func(ctx http.Context) error {
+	return func(ctx http.Context) error {
+		var in SessionGetRequest
+		if err := ctx.BindQuery(&in); err != nil {
+			return err
+		}
+		if err := ctx.BindVars(&in); err != nil {
+			return err
+		}
+		http.SetOperation(ctx, OperationAgentServiceGetSession)
+		h := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.GetSession(ctx, req.(*SessionGetRequest))
+		})
+		out, err := h(ctx, &in)
+		if err != nil {
+			return err
+		}
+		reply := out.(*SessionResponse)
+		return ctx.Result(200, reply)
+	}
+}
+
+func _AgentService_DeleteSession0_HTTP_Handler(srv AgentServiceHTTPServer) func(ctx http.Context) error {
+	return func(ctx http.Context) error {
+		var in SessionDeleteRequest
+		if err := ctx.BindQuery(&in); err != nil {
+			return err
+		}
+		if err := ctx.BindVars(&in); err != nil {
+			return err
+		}
+		http.SetOperation(ctx, OperationAgentServiceDeleteSession)
+		h := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.DeleteSession(ctx, req.(*SessionDeleteRequest))
+		})
+		out, err := h(ctx, &in)
+		if err != nil {
+			return err
+		}
+		reply := out.(*SessionResponse)
+		return ctx.Result(200, reply)
+	}
+}
+
+func _AgentService_GetRun0_HTTP_Handler(srv AgentServiceHTTPServer) func(ctx http.Context) error {
+	return func(ctx http.Context) error {
+		var in RunGetRequest
+		if err := ctx.BindQuery(&in); err != nil {
+			return err
+		}
+		if err := ctx.BindVars(&in); err != nil {
+			return err
+		}
+		http.SetOperation(ctx, OperationAgentServiceGetRun)
+		h := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.GetRun(ctx, req.(*RunGetRequest))
+		})
+		out, err := h(ctx, &in)
+		if err != nil {
+			return err
+		}
+		reply := out.(*RunResponse)
+		return ctx.Result(200, reply)
+	}
+}
+
+func _AgentService_ResumeRun0_HTTP_Handler(srv AgentServiceHTTPServer) func(ctx http.Context) error {
+	return func(ctx http.Context) error {
+		var in RunResumeRequest
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		if err := ctx.BindQuery(&in); err != nil {
+			return err
+		}
+		if err := ctx.BindVars(&in); err != nil {
+			return err
+		}
+		http.SetOperation(ctx, OperationAgentServiceResumeRun)
+		h := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.ResumeRun(ctx, req.(*RunResumeRequest))
+		})
+		out, err := h(ctx, &in)
+		if err != nil {
+			return err
+		}
+		reply := out.(*RunResponse)
+		return ctx.Result(200, reply)
+	}
+}
+
+func _AgentService_CancelRun0_HTTP_Handler(srv AgentServiceHTTPServer) func(ctx http.Context) error {
+	return func(ctx http.Context) error {
+		var in RunCancelRequest
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		if err := ctx.BindQuery(&in); err != nil {
+			return err
+		}
+		if err := ctx.BindVars(&in); err != nil {
+			return err
+		}
+		http.SetOperation(ctx, OperationAgentServiceCancelRun)
+		h := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.CancelRun(ctx, req.(*RunCancelRequest))
+		})
+		out, err := h(ctx, &in)
+		if err != nil {
+			return err
+		}
+		reply := out.(*RunResponse)
+		return ctx.Result(200, reply)
+	}
+}
+
+func _AgentService_SubmitRun0_HTTP_Handler(srv AgentServiceHTTPServer) func(ctx http.Context) error {
+	return func(ctx http.Context) error {
+		var in SubmitRunRequest
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		if err := ctx.BindQuery(&in); err != nil {
+			return err
+		}
+		http.SetOperation(ctx, OperationAgentServiceSubmitRun)
+		h := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.SubmitRun(ctx, req.(*SubmitRunRequest))
+		})
+		out, err := h(ctx, &in)
+		if err != nil {
+			return err
+		}
+		reply := out.(*RunResponse)
+		return ctx.Result(200, reply)
+	}
+}
+
+func _AgentService_ListRunEvents0_HTTP_Handler(srv AgentServiceHTTPServer) func(ctx http.Context) error {
+	return func(ctx http.Context) error {
+		var in RunEventsRequest
+		if err := ctx.BindQuery(&in); err != nil {
+			return err
+		}
+		if err := ctx.BindVars(&in); err != nil {
+			return err
+		}
+		http.SetOperation(ctx, OperationAgentServiceListRunEvents)
+		h := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.ListRunEvents(ctx, req.(*RunEventsRequest))
+		})
+		out, err := h(ctx, &in)
+		if err != nil {
+			return err
+		}
+		reply := out.(*RunEventsResponse)
+		return ctx.Result(200, reply)
+	}
+}
+
+func _AgentService_ResolveApproval0_HTTP_Handler(srv AgentServiceHTTPServer) func(ctx http.Context) error {
+	return func(ctx http.Context) error {
+		var in ResolveApprovalRequest
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		if err := ctx.BindQuery(&in); err != nil {
+			return err
+		}
+		if err := ctx.BindVars(&in); err != nil {
+			return err
+		}
+		http.SetOperation(ctx, OperationAgentServiceResolveApproval)
+		h := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.ResolveApproval(ctx, req.(*ResolveApprovalRequest))
+		})
+		out, err := h(ctx, &in)
+		if err != nil {
+			return err
+		}
+		reply := out.(*ResolveApprovalResponse)
+		return ctx.Result(200, reply)
+	}
+}
+
 type AgentServiceHTTPClient interface {
 	AddMCPService(ctx context.Context, req *MCPServiceRequest, opts ...http.CallOption) (rsp *MCPServiceResponse, err error)
+	CancelRun(ctx context.Context, req *RunCancelRequest, opts ...http.CallOption) (rsp *RunResponse, err error)
 	Chat(ctx context.Context, req *ChatRequest, opts ...http.CallOption) (rsp *ChatResponse, err error)
 	CreateAgent(ctx context.Context, req *AgentConfigRequest, opts ...http.CallOption) (rsp *AgentConfigResponse, err error)
+	CreateSession(ctx context.Context, req *SessionRequest, opts ...http.CallOption) (rsp *SessionResponse, err error)
 	DeleteAgent(ctx context.Context, req *AgentDeleteRequest, opts ...http.CallOption) (rsp *AgentConfigResponse, err error)
+	DeleteSession(ctx context.Context, req *SessionDeleteRequest, opts ...http.CallOption) (rsp *SessionResponse, err error)
 	GetAgent(ctx context.Context, req *AgentGetRequest, opts ...http.CallOption) (rsp *AgentConfigResponse, err error)
 	GetMCPServiceTools(ctx context.Context, req *MCPServiceToolsRequest, opts ...http.CallOption) (rsp *MCPServiceToolsResponse, err error)
+	GetRun(ctx context.Context, req *RunGetRequest, opts ...http.CallOption) (rsp *RunResponse, err error)
+	GetSession(ctx context.Context, req *SessionGetRequest, opts ...http.CallOption) (rsp *SessionResponse, err error)
 	ListAgentTypes(ctx context.Context, req *Empty, opts ...http.CallOption) (rsp *AgentTypesResponse, err error)
 	ListAgents(ctx context.Context, req *Empty, opts ...http.CallOption) (rsp *AgentListResponse, err error)
 	ListMCPServices(ctx context.Context, req *Empty, opts ...http.CallOption) (rsp *MCPServicesResponse, err error)
 	ListMCPServicesWithId(ctx context.Context, req *Empty, opts ...http.CallOption) (rsp *MCPServicesWithIdResponse, err error)
+	ListRunEvents(ctx context.Context, req *RunEventsRequest, opts ...http.CallOption) (rsp *RunEventsResponse, err error)
+	ListSessions(ctx context.Context, req *SessionListRequest, opts ...http.CallOption) (rsp *SessionListResponse, err error)
 	ListTools(ctx context.Context, req *Empty, opts ...http.CallOption) (rsp *ToolsResponse, err error)
 	RemoveMCPService(ctx context.Context, req *MCPServiceRequest, opts ...http.CallOption) (rsp *MCPServiceResponse, err error)
+	ResolveApproval(ctx context.Context, req *ResolveApprovalRequest, opts ...http.CallOption) (rsp *ResolveApprovalResponse, err error)
+	ResumeRun(ctx context.Context, req *RunResumeRequest, opts ...http.CallOption) (rsp *RunResponse, err error)
+	SubmitRun(ctx context.Context, req *SubmitRunRequest, opts ...http.CallOption) (rsp *RunResponse, err error)
 	UpdateAgent(ctx context.Context, req *AgentConfigRequest, opts ...http.CallOption) (rsp *AgentConfigResponse, err error)
 }
 
@@ -382,6 +653,19 @@ func (c *AgentServiceHTTPClientImpl) AddMCPService(ctx context.Context, in *MCPS
 	return &out, nil
 }
 
+func (c *AgentServiceHTTPClientImpl) CancelRun(ctx context.Context, in *RunCancelRequest, opts ...http.CallOption) (*RunResponse, error) {
+	var out RunResponse
+	pattern := "/api/runs/{id}/cancel"
+	path := binding.EncodeURL(pattern, in, false)
+	opts = append(opts, http.Operation(OperationAgentServiceCancelRun))
+	opts = append(opts, http.PathTemplate(pattern))
+	err := c.cc.Invoke(ctx, "POST", path, in, &out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
 func (c *AgentServiceHTTPClientImpl) Chat(ctx context.Context, in *ChatRequest, opts ...http.CallOption) (*ChatResponse, error) {
 	var out ChatResponse
 	pattern := "/api/chat"
@@ -408,6 +692,19 @@ func (c *AgentServiceHTTPClientImpl) CreateAgent(ctx context.Context, in *AgentC
 	return &out, nil
 }
 
+func (c *AgentServiceHTTPClientImpl) CreateSession(ctx context.Context, in *SessionRequest, opts ...http.CallOption) (*SessionResponse, error) {
+	var out SessionResponse
+	pattern := "/api/sessions"
+	path := binding.EncodeURL(pattern, in, false)
+	opts = append(opts, http.Operation(OperationAgentServiceCreateSession))
+	opts = append(opts, http.PathTemplate(pattern))
+	err := c.cc.Invoke(ctx, "POST", path, in, &out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
 func (c *AgentServiceHTTPClientImpl) DeleteAgent(ctx context.Context, in *AgentDeleteRequest, opts ...http.CallOption) (*AgentConfigResponse, error) {
 	var out AgentConfigResponse
 	pattern := "/api/agents/{id}"
@@ -421,6 +718,19 @@ func (c *AgentServiceHTTPClientImpl) DeleteAgent(ctx context.Context, in *AgentD
 	return &out, nil
 }
 
+func (c *AgentServiceHTTPClientImpl) DeleteSession(ctx context.Context, in *SessionDeleteRequest, opts ...http.CallOption) (*SessionResponse, error) {
+	var out SessionResponse
+	pattern := "/api/sessions/{id}"
+	path := binding.EncodeURL(pattern, in, true)
+	opts = append(opts, http.Operation(OperationAgentServiceDeleteSession))
+	opts = append(opts, http.PathTemplate(pattern))
+	err := c.cc.Invoke(ctx, "DELETE", path, nil, &out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
 func (c *AgentServiceHTTPClientImpl) GetAgent(ctx context.Context, in *AgentGetRequest, opts ...http.CallOption) (*AgentConfigResponse, error) {
 	var out AgentConfigResponse
 	pattern := "/api/agents/{id}"
@@ -447,6 +757,32 @@ func (c *AgentServiceHTTPClientImpl) GetMCPServiceTools(ctx context.Context, in
 	return &out, nil
 }
 
+func (c *AgentServiceHTTPClientImpl) GetRun(ctx context.Context, in *RunGetRequest, opts ...http.CallOption) (*RunResponse, error) {
+	var out RunResponse
+	pattern := "/api/runs/{id}"
+	path := binding.EncodeURL(pattern, in, true)
+	opts = append(opts, http.Operation(OperationAgentServiceGetRun))
+	opts = append(opts, http.PathTemplate(pattern))
+	err := c.cc.Invoke(ctx, "GET", path, nil, &out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *AgentServiceHTTPClientImpl) GetSession(ctx context.Context, in *SessionGetRequest, opts ...http.CallOption) (*SessionResponse, error) {
+	var out SessionResponse
+	pattern := "/api/sessions/{id}"
+	path := binding.EncodeURL(pattern, in, true)
+	opts = append(opts, http.Operation(OperationAgentServiceGetSession))
+	opts = append(opts, http.PathTemplate(pattern))
+	err := c.cc.Invoke(ctx, "GET", path, nil, &out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
 func (c *AgentServiceHTTPClientImpl) ListAgentTypes(ctx context.Context, in *Empty, opts ...http.CallOption) (*AgentTypesResponse, error) {
 	var out AgentTypesResponse
 	pattern := "/api/agent-types"
@@ -499,6 +835,32 @@ func (c *AgentServiceHTTPClientImpl) ListMCPServicesWithId(ctx context.Context,
 	return &out, nil
 }
 
+func (c *AgentServiceHTTPClientImpl) ListRunEvents(ctx context.Context, in *RunEventsRequest, opts ...http.CallOption) (*RunEventsResponse, error) {
+	var out RunEventsResponse
+	pattern := "/api/runs/{id}/events"
+	path := binding.EncodeURL(pattern, in, true)
+	opts = append(opts, http.Operation(OperationAgentServiceListRunEvents))
+	opts = append(opts, http.PathTemplate(pattern))
+	err := c.cc.Invoke(ctx, "GET", path, nil, &out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *AgentServiceHTTPClientImpl) ListSessions(ctx context.Context, in *SessionListRequest, opts ...http.CallOption) (*SessionListResponse, error) {
+	var out SessionListResponse
+	pattern := "/api/sessions"
+	path := binding.EncodeURL(pattern, in, true)
+	opts = append(opts, http.Operation(OperationAgentServiceListSessions))
+	opts = append(opts, http.PathTemplate(pattern))
+	err := c.cc.Invoke(ctx, "GET", path, nil, &out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
 func (c *AgentServiceHTTPClientImpl) ListTools(ctx context.Context, in *Empty, opts ...http.CallOption) (*ToolsResponse, error) {
 	var out ToolsResponse
 	pattern := "/api/tools"
@@ -525,6 +887,45 @@ func (c *AgentServiceHTTPClientImpl) RemoveMCPService(ctx context.Context, in *M
 	return &out, nil
 }
 
+func (c *AgentServiceHTTPClientImpl) ResolveApproval(ctx context.Context, in *ResolveApprovalRequest, opts ...http.CallOption) (*ResolveApprovalResponse, error) {
+	var out ResolveApprovalResponse
+	pattern := "/api/runs/{run_id}/approvals/{id}"
+	path := binding.EncodeURL(pattern, in, false)
+	opts = append(opts, http.Operation(OperationAgentServiceResolveApproval))
+	opts = append(opts, http.PathTemplate(pattern))
+	err := c.cc.Invoke(ctx, "POST", path, in, &out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *AgentServiceHTTPClientImpl) ResumeRun(ctx context.Context, in *RunResumeRequest, opts ...http.CallOption) (*RunResponse, error) {
+	var out RunResponse
+	pattern := "/api/runs/{id}/resume"
+	path := binding.EncodeURL(pattern, in, false)
+	opts = append(opts, http.Operation(OperationAgentServiceResumeRun))
+	opts = append(opts, http.PathTemplate(pattern))
+	err := c.cc.Invoke(ctx, "POST", path, in, &out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *AgentServiceHTTPClientImpl) SubmitRun(ctx context.Context, in *SubmitRunRequest, opts ...http.CallOption) (*RunResponse, error) {
+	var out RunResponse
+	pattern := "/api/runs"
+	path := binding.EncodeURL(pattern, in, false)
+	opts = append(opts, http.Operation(OperationAgentServiceSubmitRun))
+	opts = append(opts, http.PathTemplate(pattern))
+	err := c.cc.Invoke(ctx, "POST", path, in, &out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
 func (c *AgentServiceHTTPClientImpl) UpdateAgent(ctx context.Context, in *AgentConfigRequest, opts ...http.CallOption) (*AgentConfigResponse, error) {
 	var out AgentConfigResponse
 	pattern := "/api/agents/{id}"
@@ -6,6 +6,7 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
 	"jas-agent/agent/rag/embedding"
 	"jas-agent/agent/rag/graphrag"
 
@@ -18,11 +19,15 @@ import (
 	"jas-agent/internal/conf"
 	"jas-agent/internal/data"
 	"jas-agent/internal/server"
+	"jas-agent/internal/server/middleware"
 	"jas-agent/internal/service"
 )
 
 var errMissingLLMConfig = errors.New("llm config is required")
 
+// defaultProviderName llm.api_key/base_url 对应的服务商名称
+const defaultProviderName = "default"
+
 func wireApp(c *conf.Bootstrap, logger log.Logger) (*kratos.App, func(), error) {
 	wire.Build(
 		data.ProviderSet,
@@ -45,10 +50,35 @@ func newChat(c *conf.Bootstrap) (llm.Chat, error) {
 	if c.Llm == nil {
 		return nil, errMissingLLMConfig
 	}
-	return llm.NewChat(&llm.Config{
-		ApiKey:  c.Llm.ApiKey,
-		BaseURL: c.Llm.BaseUrl,
-	}), nil
+	registry := llm.NewRegistry(c.Llm.DefaultProvider, c.Llm.Model)
+	// 兼容单服务商配置：api_key/base_url 注册为 default 服务商
+	if c.Llm.ApiKey != "" || c.Llm.BaseUrl != "" {
+		if !llm.ValidStructuredOutput(c.Llm.StructuredOutput) {
+			return nil, fmt.Errorf("llm: unsupported structured output %q", c.Llm.StructuredOutput)
+		}
+		registry.Register(defaultProviderName, llm.NewChat(&llm.Config{
+			ApiKey:           c.Llm.ApiKey,
+			BaseURL:          c.Llm.BaseUrl,
+			StructuredOutput: c.Llm.StructuredOutput,
+		}))
+	}
+	for _, provider := range c.Llm.Providers {
+		chat, err := llm.NewProviderChat(llm.ProviderConfig{
+			Name:             provider.Name,
+			Protocol:         provider.Protocol,
+			ApiKey:           provider.ApiKey,
+			BaseURL:          provider.BaseUrl,
+			StructuredOutput: provider.StructuredOutput,
+		})
+		if err != nil {
+			return nil, err
+		}
+		registry.Register(provider.Name, chat)
+	}
+	if len(registry.Providers()) == 0 {
+		return nil, errMissingLLMConfig
+	}
+	return registry, nil
 }
 
 func provideServerConfig(c *conf.Bootstrap) *conf.Server {
@@ -80,7 +110,11 @@ func newEmbedder(c *conf.Bootstrap) embedding.Embedder {
 		Model:   "text-embedding-3-small",
 	})
 }
-func provideLLMExtractor(chat llm.Chat) *graphrag.LLMExtractor {
+func provideLLMExtractor(chat llm.Chat, pricing llm.Pricing) *graphrag.LLMExtractor {
+	// 图谱抽取不属于任何 Agent，用量只导出为指标
+	chat = llm.Chain(chat, llm.ObserveUsage(pricing, func(ctx context.Context, model string, usage llm.Usage) {
+		middleware.RecordTokenUsage(ctx, "", "graphrag", model, usage.PromptTokens, usage.CompletionTokens, usage.Cost)
+	}))
 	return graphrag.NewLLMExtractor(chat, "gpt-3.5-turbo")
 }
 func provideEngine(llmExtractor *graphrag.LLMExtractor, neo4j *graphrag.Neo4jStore) *graphrag.Engine {
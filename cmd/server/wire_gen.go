@@ -9,6 +9,7 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/go-kratos/kratos/v2"
 	"github.com/go-kratos/kratos/v2/log"
 	"jas-agent/agent/llm"
@@ -18,6 +19,7 @@ import (
 	"jas-agent/internal/conf"
 	"jas-agent/internal/data"
 	"jas-agent/internal/server"
+	"jas-agent/internal/server/middleware"
 	"jas-agent/internal/service"
 )
 
@@ -44,26 +46,37 @@ func wireApp(c *conf.Bootstrap, logger log.Logger) (*kratos.App, func(), error)
 	}
 	agentRepo := data.NewAgentRepo(dataData)
 	agentFactory := biz.NewAgentFactory()
-	agentUsecase := biz.NewAgentUsecase(chat, agentRepo, agentFactory, logger)
+	sessionRepo := data.NewSessionRepo(dataData)
+	sessionUsecase := biz.NewSessionUsecase(sessionRepo, agentRepo, logger)
+	tokenUsageRepo := data.NewTokenUsageRepo(dataData)
+	tenantBudgets := biz.NewTenantBudgets(c, tokenUsageRepo, logger)
+	runRepo := data.NewRunRepo(dataData)
+	runQueue := data.NewRunQueue(dataData)
+	approvalRepo := data.NewApprovalRepo(dataData)
+	runWebhook := biz.NewRunWebhook(c, logger)
+	pricing := biz.NewLLMPricing(c)
+	embedder := newEmbedder(c)
+	data_Milvus := provideMilvus(c)
+	agentUsecase := biz.NewAgentUsecase(chat, agentRepo, agentFactory, sessionUsecase, tokenUsageRepo, tenantBudgets, runRepo, runQueue, approvalRepo, runWebhook, pricing, embedder, data_Milvus, logger)
 	mcpRepo := data.NewMCPRepo(dataData)
 	mcpUsecase := biz.NewMcpUsecase(mcpRepo, logger)
 	knowledgeBaseRepo := data.NewKnowledgeBaseRepo(dataData)
 	documentRepo := data.NewDocumentRepo(dataData)
-	embedder := newEmbedder(c)
-	data_Milvus := provideMilvus(c)
-	llmExtractor := provideLLMExtractor(chat)
+	llmExtractor := provideLLMExtractor(chat, pricing)
 	neo4jStore := provideNeo4j(confData)
 	engine := provideEngine(llmExtractor, neo4jStore)
 	knowledgeUsecase := biz.NewKnowledgeUsecase(knowledgeBaseRepo, documentRepo, logger, c, embedder, data_Milvus, engine)
-	agentService, err := service.NewAgentService(agentUsecase, mcpUsecase, knowledgeUsecase)
+	agentService, err := service.NewAgentService(agentUsecase, mcpUsecase, knowledgeUsecase, sessionUsecase)
 	if err != nil {
 		cleanup()
 		return nil, nil, err
 	}
 	grpcServer := server.NewGRPCServer(confServer, agentService, logger)
 	knowledgeServiceImpl := service.NewKnowledgeServiceImpl(knowledgeUsecase)
-	httpServer := server.NewHTTPServer(confServer, agentService, knowledgeServiceImpl, logger)
-	app := server.NewApp(logger, grpcServer, httpServer)
+	mcpServerService := service.NewMCPServerService(confServer, agentUsecase, logger)
+	httpServer := server.NewHTTPServer(confServer, agentService, knowledgeServiceImpl, mcpServerService, logger)
+	runWorkerPool := biz.NewRunWorkerPool(c, agentUsecase, logger)
+	app := server.NewApp(logger, grpcServer, httpServer, runWorkerPool, agentService)
 	return app, func() {
 		cleanup()
 	}, nil
@@ -73,14 +86,42 @@ func wireApp(c *conf.Bootstrap, logger log.Logger) (*kratos.App, func(), error)
 
 var errMissingLLMConfig = errors.New("llm config is required")
 
+// defaultProviderName llm.api_key/base_url 对应的服务商名称
+const defaultProviderName = "default"
+
 func newChat(c *conf.Bootstrap) (llm.Chat, error) {
 	if c.Llm == nil {
 		return nil, errMissingLLMConfig
 	}
-	return llm.NewChat(&llm.Config{
-		ApiKey:  c.Llm.ApiKey,
-		BaseURL: c.Llm.BaseUrl,
-	}), nil
+	registry := llm.NewRegistry(c.Llm.DefaultProvider, c.Llm.Model)
+
+	if c.Llm.ApiKey != "" || c.Llm.BaseUrl != "" {
+		if !llm.ValidStructuredOutput(c.Llm.StructuredOutput) {
+			return nil, fmt.Errorf("llm: unsupported structured output %q", c.Llm.StructuredOutput)
+		}
+		registry.Register(defaultProviderName, llm.NewChat(&llm.Config{
+			ApiKey:           c.Llm.ApiKey,
+			BaseURL:          c.Llm.BaseUrl,
+			StructuredOutput: c.Llm.StructuredOutput,
+		}))
+	}
+	for _, provider := range c.Llm.Providers {
+		chat, err := llm.NewProviderChat(llm.ProviderConfig{
+			Name:             provider.Name,
+			Protocol:         provider.Protocol,
+			ApiKey:           provider.ApiKey,
+			BaseURL:          provider.BaseUrl,
+			StructuredOutput: provider.StructuredOutput,
+		})
+		if err != nil {
+			return nil, err
+		}
+		registry.Register(provider.Name, chat)
+	}
+	if len(registry.Providers()) == 0 {
+		return nil, errMissingLLMConfig
+	}
+	return registry, nil
 }
 
 func provideServerConfig(c *conf.Bootstrap) *conf.Server {
@@ -115,7 +156,11 @@ func newEmbedder(c *conf.Bootstrap) embedding.Embedder {
 	})
 }
 
-func provideLLMExtractor(chat llm.Chat) *graphrag.LLMExtractor {
+func provideLLMExtractor(chat llm.Chat, pricing llm.Pricing) *graphrag.LLMExtractor {
+
+	chat = llm.Chain(chat, llm.ObserveUsage(pricing, func(ctx context.Context, model string, usage llm.Usage) {
+		middleware.RecordTokenUsage(ctx, "", "graphrag", model, usage.PromptTokens, usage.CompletionTokens, usage.Cost)
+	}))
 	return graphrag.NewLLMExtractor(chat, "gpt-3.5-turbo")
 }
 
@@ -2,27 +2,58 @@ package biz
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	agent "jas-agent/agent/agent"
 	"jas-agent/agent/core"
 	"jas-agent/agent/llm"
 	"jas-agent/agent/memory"
+	"jas-agent/agent/rag/embedding"
 	tools "jas-agent/agent/tools"
+	"jas-agent/agent/window"
 	pb "jas-agent/api/agent/service/v1"
+	"jas-agent/internal/conf"
+	"jas-agent/internal/server/middleware"
 
 	"github.com/go-kratos/kratos/v2/log"
 	_ "github.com/go-sql-driver/mysql"
 )
 
+// errStreamClosed 运行结束后仍有消息推送
+var errStreamClosed = errors.New("stream closed")
+
 // AgentUsecase 负责 Agent 相关业务逻辑
 type AgentUsecase struct {
-	chat      llm.Chat
-	agentRepo AgentRepo
-	logger    *log.Helper
-	factory   *AgentFactory
+	chat        llm.Chat
+	agentRepo   AgentRepo
+	logger      *log.Helper
+	factory     *AgentFactory
+	sessions    *SessionUsecase
+	usageRepo   TokenUsageRepo
+	budgets     *TenantBudgets
+	pricing     llm.Pricing
+	middlewares *llmMiddlewares
+	memories    *agentMemories
+	runRepo     RunRepo
+	runQueue    RunQueue
+	instanceID  string // 本实例的标识，执行运行前以此认领
+	// heartbeatInterval 执行中的运行刷新心跳、检查取消请求的间隔
+	heartbeatInterval time.Duration
+	runs              sync.Map // runID -> *activeRun
+	runEvents         runEventNotifier
+	approvalRepo      ApprovalRepo
+	approvals         sync.Map // approvalID -> *pendingApproval
+	// approvalPollInterval 检查其他实例写入的审批决定的间隔
+	approvalPollInterval time.Duration
+	queued               chan struct{} // 提交异步运行时唤醒空闲的 worker
+	webhook              *RunWebhook
+	// exposedTools agentID -> *exposedTools，以 MCP 服务端对外提供的 Agent 自身工具
+	exposedTools sync.Map
 }
 
 // MCPServiceInfo MCP服务信息
@@ -37,19 +68,54 @@ type MCPServiceInfo struct {
 }
 
 // NewAgentUsecase 创建新的 AgentUsecase。
-func NewAgentUsecase(chat llm.Chat, agentRepo AgentRepo, factory *AgentFactory, logger log.Logger) *AgentUsecase {
+func NewAgentUsecase(chat llm.Chat, agentRepo AgentRepo, factory *AgentFactory, sessions *SessionUsecase,
+	usageRepo TokenUsageRepo, budgets *TenantBudgets, runRepo RunRepo, runQueue RunQueue, approvalRepo ApprovalRepo, webhook *RunWebhook, pricing llm.Pricing,
+	embedder embedding.Embedder, milvus *conf.Data_Milvus, logger log.Logger) *AgentUsecase {
 	uc := &AgentUsecase{
-		chat:      chat,
-		agentRepo: agentRepo,
-		logger:    log.NewHelper(log.With(logger, "module", "biz/agent")),
-		factory:   factory,
+		chat:                 chat,
+		agentRepo:            agentRepo,
+		logger:               log.NewHelper(log.With(logger, "module", "biz/agent")),
+		factory:              factory,
+		sessions:             sessions,
+		usageRepo:            usageRepo,
+		budgets:              budgets,
+		runRepo:              runRepo,
+		runQueue:             runQueue,
+		instanceID:           newInstanceID(),
+		heartbeatInterval:    runHeartbeatInterval,
+		approvalRepo:         approvalRepo,
+		approvalPollInterval: defaultApprovalPollInterval,
+		queued:               make(chan struct{}, 1),
+		webhook:              webhook,
+		pricing:              pricing,
+		middlewares:          newLLMMiddlewares(),
+		memories:             newAgentMemories(embedder, milvus, logger),
 	}
+	// 调度者需要从 agents 表加载工作 Agent，在此注册
+	factory.RegisterAgent(&supervisorAgent{workers: uc.subAgentFactory})
 	return uc
 }
 
 // Chat 实现单次对话
 func (s *AgentUsecase) Chat(ctx context.Context, req *pb.ChatRequest) (*pb.ChatResponse, error) {
-	return nil, nil
+	startTime := time.Now()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	executor, err := s.createExecutor(ctx, req, nil)
+	if err != nil {
+		return nil, err
+	}
+	run := s.startRun(ctx, req, executor, cancel)
+	result := executor.Run(ctx, req.Query)
+	s.finishRun(ctx, run, executor, result)
+	metadata := s.buildMetadata(executor, startTime)
+	metadata.RunId = run.ID
+	return &pb.ChatResponse{
+		Response:    result,
+		AgentType:   string(executor.GetAgentType()),
+		Metadata:    metadata,
+		FinalResult: FinalResultToProto(executor.GetFinalResult()),
+	}, nil
 }
 
 // StreamChat 实现流式对话
@@ -60,11 +126,28 @@ func (s *AgentUsecase) StreamChat(req *pb.ChatRequest, stream pb.AgentService_St
 // StreamChatWithSender 使用自定义发送函数实现流式对话，可用于 WebSocket 等场景。
 func (s *AgentUsecase) StreamChatWithSender(ctx context.Context, req *pb.ChatRequest, send func(*pb.ChatStreamResponse) error) error {
 	startTime := time.Now()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 	resultChan := make(chan string, 1)
 	messageChan := make(chan core.Message, 10)
+	// 运行结束后由执行协程关闭 messageChan，消费方读完缓冲中的全部消息后再推送 FINAL
+	var (
+		sendMu sync.Mutex
+		closed bool
+	)
 	executor, err := s.createExecutor(ctx, req, func(c context.Context, msg core.Message) error {
-		messageChan <- msg
-		return nil
+		sendMu.Lock()
+		defer sendMu.Unlock()
+		if closed {
+			return errStreamClosed
+		}
+		// 消费方已退出时不再阻塞执行协程
+		select {
+		case messageChan <- msg:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 	})
 	if err != nil {
 		return send(&pb.ChatStreamResponse{
@@ -73,34 +156,38 @@ func (s *AgentUsecase) StreamChatWithSender(ctx context.Context, req *pb.ChatReq
 		})
 	}
 
+	run := s.startRun(ctx, req, executor, cancel)
 	go func() {
-		defer close(resultChan)
-		resultChan <- executor.Run(req.Query)
+		result := executor.Run(ctx, req.Query)
+		s.finishRun(ctx, run, executor, result)
+		resultChan <- result
+		sendMu.Lock()
+		closed = true
+		close(messageChan)
+		sendMu.Unlock()
 	}()
 
-	buildMetadata := func() *pb.ExecutionMetadata {
-		metadata := &pb.ExecutionMetadata{
-			TotalSteps:      int32(executor.GetCurrentStep()),
-			ExecutionTimeMs: time.Since(startTime).Milliseconds(),
-			State:           string(executor.GetState()),
-		}
-
-		toolNames := s.extractToolNames(executor.GetMemory())
-		metadata.ToolNames = toolNames
-		metadata.ToolsCalled = int32(len(toolNames))
-
-		return metadata
+	// 首先推送运行ID，客户端可据此取消运行，或在断线后查询、恢复运行
+	if err := send(&pb.ChatStreamResponse{
+		Type:     pb.ChatStreamResponse_METADATA,
+		Metadata: &pb.ExecutionMetadata{RunId: run.ID, State: string(agent.RunningState)},
+	}); err != nil {
+		return err
 	}
 
 	sendFinal := func(result string) error {
+		metadata := s.buildMetadata(executor, startTime)
+		metadata.RunId = run.ID
 		return send(&pb.ChatStreamResponse{
-			Type:     pb.ChatStreamResponse_FINAL,
-			Content:  result,
-			Metadata: buildMetadata(),
+			Type:        pb.ChatStreamResponse_FINAL,
+			Content:     result,
+			Metadata:    metadata,
+			FinalResult: FinalResultToProto(executor.GetFinalResult()),
 		})
 	}
 
-	step := 0
+	// 嵌套运行（子 Agent、工作 Agent）的消息按来源单独编号步骤
+	steps := make(map[string]int)
 	for {
 		select {
 		case <-ctx.Done():
@@ -108,27 +195,30 @@ func (s *AgentUsecase) StreamChatWithSender(ctx context.Context, req *pb.ChatReq
 
 		case msg, ok := <-messageChan:
 			if !ok {
-				if result, ok := <-resultChan; ok {
-					return sendFinal(result)
-				}
-				return nil
+				return sendFinal(<-resultChan)
 			}
 
-			step++
+			// 流式片段不计入步骤
+			if msg.Kind == "" {
+				steps[msg.Source]++
+			}
 			msgType, content := s.parseMessage(msg)
-			if err = send(&pb.ChatStreamResponse{
+			resp := &pb.ChatStreamResponse{
 				Type:    msgType,
 				Content: content,
-				Step:    int32(step),
-			}); err != nil {
-				return err
+				Step:    int32(steps[msg.Source]),
+				Source:  msg.Source,
+				Depth:   int32(msg.Depth),
 			}
-
-		case result, ok := <-resultChan:
-			if !ok {
-				return nil
+			switch msgType {
+			case pb.ChatStreamResponse_APPROVAL_REQUIRED:
+				resp.Approval = approvalToProto(run.ID, content)
+			case pb.ChatStreamResponse_PLAN_STEP:
+				resp.PlanStep = planStepToProto(content)
+			}
+			if err = send(resp); err != nil {
+				return err
 			}
-			return sendFinal(result)
 		}
 	}
 }
@@ -172,6 +262,12 @@ func (s *AgentUsecase) ListAgentTypes(ctx context.Context, req *pb.Empty) (*pb.A
 			Description: "智能故障根因分析专家，通过关联Trace和日志数据定位故障根本原因",
 			Available:   false, // 需要Trace和日志连接
 		},
+		{
+			Type:        pb.AgentType_SUPERVISOR,
+			Name:        "Supervisor Agent",
+			Description: "调度代理，把子任务分派给多个Agent并综合它们的回答",
+			Available:   false, // 需要配置工作 Agent
+		},
 	}
 
 	return &pb.AgentTypesResponse{
@@ -198,6 +294,9 @@ func (s *AgentUsecase) CreateAgent(ctx context.Context, req *pb.AgentConfigReque
 	if agentConfig.ConnectionConfig == "" {
 		agentConfig.ConnectionConfig = "{}"
 	}
+	if err := s.validateAgent(agentConfig); err != nil {
+		return err
+	}
 	return s.agentRepo.CreateAgent(ctx, agentConfig)
 }
 
@@ -215,10 +314,27 @@ func (s *AgentUsecase) UpdateAgent(ctx context.Context, req *pb.AgentConfigReque
 		ConfigJSON:       req.ConfigJson,
 		IsActive:         true,
 	}
-
+	if err := s.validateAgent(agentConfig); err != nil {
+		return err
+	}
 	return s.agentRepo.UpdateAgent(ctx, agentConfig)
 }
 
+// validateAgent 校验 config_json 与 Agent 类型所需的配置（如链定义）
+func (s *AgentUsecase) validateAgent(agentConfig *Agent) error {
+	runtimeConfig, err := ParseAgentRuntimeConfig(agentConfig.ConfigJSON)
+	if err != nil {
+		return err
+	}
+	if err := s.validateDelegation(runtimeConfig.Delegation); err != nil {
+		return fmt.Errorf("validate agent %s: %w", agentConfig.Name, err)
+	}
+	if err := s.factory.Validate(agentConfig); err != nil {
+		return fmt.Errorf("validate agent %s: %w", agentConfig.Name, err)
+	}
+	return nil
+}
+
 func (s *AgentUsecase) DeleteAgent(ctx context.Context, req *pb.AgentDeleteRequest) error {
 	return s.agentRepo.DeleteAgent(ctx, int(req.Id))
 }
@@ -258,6 +374,10 @@ func (s *AgentUsecase) createExecutor(ctx context.Context,
 		return nil, fmt.Errorf("failed to load agent config: %w", err)
 	}
 	s.logger.Infof("Loaded agent config: id=%d name=%s framework=%s", agentConfig.ID, agentConfig.Name, agentConfig.Framework)
+	runtimeConfig, err := ParseAgentRuntimeConfig(agentConfig.ConfigJSON)
+	if err != nil {
+		return nil, err
+	}
 	tm := tools.NewToolManager()
 	tm.Inherit(tools.GetToolManager())
 	for _, server := range agentConfig.MCPServers {
@@ -267,20 +387,61 @@ func (s *AgentUsecase) createExecutor(ctx context.Context,
 		}
 		mcpManager.DiscoverAndRegisterTools()
 	}
+	// 请求未指定模型时使用 Agent 配置的模型，都为空时由 LLM 注册表使用默认模型
+	model := req.Model
+	if model == "" {
+		model = agentConfig.Model
+	}
+	// 用量在每次实际调用后统计，运行预算与租户每日预算在每次调用前检查
+	meter := llm.NewUsageMeter(runtimeConfig.Budget.Budget(), s.budgets.Limits(req.TenantId)...)
+	usage := llm.ObserveUsage(s.pricing, meter.Observe, s.usageObserver(agentConfig, req.TenantId))
+	chat := llm.Chain(s.middlewares.wrap(s.chat, agentConfig.ID, runtimeConfig.LLMMiddleware, usage), llm.EnforceBudget(meter))
+	// 摘要使用总结阶段的模型
+	summaryModel := runtimeConfig.Models.Summary
+	if summaryModel == "" {
+		summaryModel = model
+	}
+	summarizer := window.NewLLMSummarizer(chat, summaryModel)
+	// 携带会话ID时复用该会话的历史消息，实现多轮对话
 	mem := memory.NewMemory()
-	agentCtx := agent.NewContext(agent.WithModel(req.Model),
-		agent.WithChat(s.chat),
+	if req.SessionId != "" {
+		mem, err = s.sessions.OpenMemory(ctx, req.SessionId, agentConfig.ID, req.Query, runtimeConfig.Memory.persistAll())
+		if err != nil {
+			return nil, fmt.Errorf("failed to open session %s: %w", req.SessionId, err)
+		}
+	}
+	mem, err = s.memories.wrap(ctx, mem, agentConfig.ID, model, runtimeConfig.Memory, summarizer)
+	if err != nil {
+		return nil, err
+	}
+	opts := []agent.Option{
+		agent.WithModel(model),
+		agent.WithChat(chat),
+		agent.WithUsageMeter(meter),
 		agent.WithMemory(mem),
 		agent.WithToolManager(tm),
 		agent.WithSend(send),
-	)
+		agent.WithStreamTokens(send != nil && runtimeConfig.StreamTokensEnabled()),
+	}
+	opts = append(opts, runtimeConfig.executionOptions()...)
+	if !runtimeConfig.ContextWindow.Disabled {
+		manager := window.NewManager(runtimeConfig.ContextWindow.Config(), window.WithSummarizer(summarizer))
+		opts = append(opts, agent.WithContextWindow(manager))
+	}
+	if len(runtimeConfig.Delegation.Agents) > 0 {
+		factory, subAgents, err := s.subAgentFactory(ctx, agentConfig, runtimeConfig.Delegation)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, agent.WithSubAgents(factory, subAgents))
+	}
+	// 嵌套层数上限同时约束委派的子 Agent 与调度者的工作 Agent
+	opts = append(opts, agent.WithMaxAgentDepth(runtimeConfig.Delegation.MaxDepth))
+	agentCtx := agent.NewContext(opts...)
 	// 使用配置中的参数（如果请求中没有覆盖）
 	maxSteps := int(req.MaxSteps)
 	if maxSteps == 0 {
 		maxSteps = agentConfig.MaxSteps
-		if maxSteps == 0 {
-			maxSteps = 10
-		}
 	}
 
 	systemPrompt := req.SystemPrompt
@@ -299,38 +460,224 @@ func (s *AgentUsecase) createExecutor(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+	// 未配置时保留各类型执行器自身的默认步数
+	if maxSteps > 0 {
+		executor.SetMaxSteps(maxSteps)
+	}
+	executor.SetRunTimeout(runtimeConfig.RunTimeout.Std())
+	executor.SetSummaryPolicy(runtimeConfig.Summary.SummaryPolicy())
 	return executor, nil
 }
 
-func (s *AgentUsecase) extractToolNames(mem core.Memory) []string {
-	toolNames := make(map[string]bool)
-	messages := mem.GetMessages()
-
-	for _, msg := range messages {
-		if msg.Role == core.MessageRoleUser && strings.Contains(msg.Content, "Observation:") {
-			// 简单提取工具名称
-			content := msg.Content
-			if idx := strings.Index(content, "["); idx != -1 {
-				if endIdx := strings.Index(content[idx:], "]"); endIdx != -1 {
-					toolName := content[idx+1 : idx+endIdx]
-					toolNames[toolName] = true
-				}
-			}
+// usageObserver 将每次模型调用的用量导出为指标，并累加到租户下 Agent 的每日用量汇总
+func (s *AgentUsecase) usageObserver(agentConfig *Agent, tenant string) llm.UsageObserver {
+	return func(ctx context.Context, model string, usage llm.Usage) {
+		middleware.RecordTokenUsage(ctx, tenant, agentConfig.Name, model, usage.PromptTokens, usage.CompletionTokens, usage.Cost)
+		// 运行被取消时已发生的用量仍需记录
+		if err := s.usageRepo.AddDailyUsage(context.WithoutCancel(ctx), tenant, agentConfig.ID, time.Now(), model, usage); err != nil {
+			s.logger.Warnf("record token usage for agent %d: %v", agentConfig.ID, err)
 		}
+		s.budgets.Observe(tenant, usage)
 	}
+}
 
-	result := make([]string, 0, len(toolNames))
-	for name := range toolNames {
+// buildMetadata 根据执行器状态构建执行元数据
+func (s *AgentUsecase) buildMetadata(executor *agent.AgentExecutor, startTime time.Time) *pb.ExecutionMetadata {
+	toolCalls := executor.GetToolCalls()
+	usage := executor.GetUsage()
+	return &pb.ExecutionMetadata{
+		TotalSteps:       int32(executor.GetCurrentStep()),
+		ToolsCalled:      int32(len(toolCalls)),
+		ToolNames:        uniqueToolNames(toolCalls),
+		ExecutionTimeMs:  time.Since(startTime).Milliseconds(),
+		State:            string(executor.GetState()),
+		PromptTokens:     int64(usage.PromptTokens),
+		CompletionTokens: int64(usage.CompletionTokens),
+		TotalTokens:      int64(usage.TotalTokens()),
+		Cost:             usage.Cost,
+	}
+}
+
+// FinalResultToProto 转换结构化最终结果，未结束时返回 nil
+func FinalResultToProto(result *agent.FinalResult) *pb.FinalResult {
+	if result == nil {
+		return nil
+	}
+	pbResult := &pb.FinalResult{
+		Answer:     result.Answer,
+		Citations:  result.Citations,
+		Confidence: result.Confidence,
+	}
+	for _, trace := range result.ChainTrace {
+		pbResult.ChainTrace = append(pbResult.ChainTrace, &pb.ChainNodeTrace{
+			Node:       trace.Node,
+			AgentType:  trace.AgentType,
+			Status:     trace.Status,
+			Input:      trace.Input,
+			Output:     trace.Output,
+			Steps:      int32(trace.Steps),
+			State:      trace.State,
+			DurationMs: trace.DurationMs,
+			Error:      trace.Error,
+		})
+	}
+	pbResult.SubRuns = subRunsToProto(result.SubRuns)
+	pbResult.PlanRevisions = planRevisionsToProto(result.PlanRevisions)
+	return pbResult
+}
+
+// subRunsToProto 转换子 Agent 运行记录，包括其嵌套的子运行
+func subRunsToProto(traces []agent.SubRunTrace) []*pb.SubRunTrace {
+	var pbTraces []*pb.SubRunTrace
+	for _, trace := range traces {
+		pbTraces = append(pbTraces, &pb.SubRunTrace{
+			StepId:      int32(trace.StepID),
+			Agent:       trace.Agent,
+			AgentType:   trace.AgentType,
+			Depth:       int32(trace.Depth),
+			Status:      trace.Status,
+			Input:       trace.Input,
+			Output:      trace.Output,
+			Error:       trace.Error,
+			Steps:       int32(trace.Steps),
+			ToolsCalled: trace.ToolsCalled,
+			State:       trace.State,
+			DurationMs:  trace.DurationMs,
+			SubRuns:     subRunsToProto(trace.SubRuns),
+			Source:      trace.Source,
+		})
+	}
+	return pbTraces
+}
+
+// uniqueToolNames 按首次调用顺序去重工具名称
+func uniqueToolNames(toolCalls []string) []string {
+	seen := make(map[string]bool, len(toolCalls))
+	result := make([]string, 0, len(toolCalls))
+	for _, name := range toolCalls {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
 		result = append(result, name)
 	}
 	return result
 }
 
+// approvalToProto 将审批请求消息转换为 ApprovalInfo，内容无法解析时返回 nil
+func approvalToProto(runID, content string) *pb.ApprovalInfo {
+	var req agent.ApprovalRequest
+	if err := json.Unmarshal([]byte(content), &req); err != nil {
+		return nil
+	}
+	return &pb.ApprovalInfo{
+		Id:         req.ID,
+		RunId:      runID,
+		ToolCallId: req.ToolCallID,
+		ToolName:   req.ToolName,
+		Arguments:  req.Arguments,
+	}
+}
+
+// planStepToProto 将计划步骤事件消息转换为 PlanStepInfo，内容无法解析时返回 nil
+func planStepToProto(content string) *pb.PlanStepInfo {
+	var event agent.PlanStepEvent
+	if err := json.Unmarshal([]byte(content), &event); err != nil {
+		return nil
+	}
+	return &pb.PlanStepInfo{
+		StepId:       int32(event.StepID),
+		Description:  event.Description,
+		Tool:         event.Tool,
+		Agent:        event.Agent,
+		Input:        event.Input,
+		Dependencies: stepIDsToProto(event.Dependencies),
+		Status:       event.Status,
+		Result:       event.Result,
+		Error:        event.Error,
+	}
+}
+
+// planRevisionsToProto 转换计划修订记录
+func planRevisionsToProto(revisions []agent.PlanRevision) []*pb.PlanRevision {
+	var pbRevisions []*pb.PlanRevision
+	for _, revision := range revisions {
+		pbRevisions = append(pbRevisions, &pb.PlanRevision{
+			Revision:       int32(revision.Revision),
+			Decision:       string(revision.Decision),
+			Reason:         revision.Reason,
+			Score:          revision.Score,
+			EvaluatedSteps: stepIDsToProto(revision.EvaluatedSteps),
+			RetriedStep:    int32(revision.RetriedStep),
+			RetryInput:     revision.RetryInput,
+			AddedSteps:     planStepsToProto(revision.AddedSteps),
+			RemovedSteps:   planStepsToProto(revision.RemovedSteps),
+			CreatedAt:      revision.Created.Format("2006-01-02 15:04:05"),
+		})
+	}
+	return pbRevisions
+}
+
+func planStepsToProto(steps []agent.PlanStep) []*pb.PlanStepInfo {
+	var pbSteps []*pb.PlanStepInfo
+	for _, step := range steps {
+		info := &pb.PlanStepInfo{
+			StepId:       int32(step.ID),
+			Description:  step.Description,
+			Tool:         step.Tool,
+			Agent:        step.Agent,
+			Input:        step.Input,
+			Dependencies: stepIDsToProto(step.Dependencies),
+			Status:       step.Status,
+		}
+		if step.Status == agent.PlanStepFailed {
+			info.Error = step.Result
+		} else {
+			info.Result = step.Result
+		}
+		pbSteps = append(pbSteps, info)
+	}
+	return pbSteps
+}
+
+func stepIDsToProto(ids []int) []int32 {
+	pbIDs := make([]int32, 0, len(ids))
+	for _, id := range ids {
+		pbIDs = append(pbIDs, int32(id))
+	}
+	return pbIDs
+}
+
 func (s *AgentUsecase) parseMessage(msg core.Message) (pb.ChatStreamResponse_MessageType, string) {
 	content := msg.Content
+	switch msg.Kind {
+	case core.MessageKindSummary:
+		return pb.ChatStreamResponse_SUMMARY, content
+	case core.MessageKindApproval:
+		return pb.ChatStreamResponse_APPROVAL_REQUIRED, content
+	case core.MessageKindPlanStep:
+		return pb.ChatStreamResponse_PLAN_STEP, content
+	case core.MessageKindDelta:
+		// 工具调用参数片段与文本片段一样按顺序推送，由客户端拼接
+		for _, toolCall := range msg.ToolCalls {
+			content += toolCall.Arguments
+		}
+		return pb.ChatStreamResponse_DELTA, content
+	}
 
 	switch msg.Role {
 	case core.MessageRoleAssistant:
+		// function calling 模式下工具调用以 tool_calls 返回，按文本模式的格式展示
+		if len(msg.ToolCalls) > 0 {
+			var actions []string
+			if content != "" {
+				actions = append(actions, content)
+			}
+			for _, toolCall := range msg.ToolCalls {
+				actions = append(actions, fmt.Sprintf("Action: %s[%s]", toolCall.Name, toolCall.Arguments))
+			}
+			return pb.ChatStreamResponse_ACTION, strings.Join(actions, "\n")
+		}
 		if strings.Contains(content, "Thought:") {
 			return pb.ChatStreamResponse_THINKING, content
 		} else if strings.Contains(content, "Action:") {
@@ -344,6 +691,9 @@ func (s *AgentUsecase) parseMessage(msg core.Message) (pb.ChatStreamResponse_Mes
 		}
 		return pb.ChatStreamResponse_OBSERVATION, content
 
+	case core.MessageRoleTool:
+		return pb.ChatStreamResponse_OBSERVATION, fmt.Sprintf("Observation: %s", content)
+
 	default:
 		return pb.ChatStreamResponse_METADATA, content
 	}
@@ -0,0 +1,370 @@
+package biz
+
+import (
+	"encoding/json"
+	"fmt"
+	"jas-agent/agent/agent"
+	"jas-agent/agent/llm"
+	"jas-agent/agent/window"
+	"path"
+	"strings"
+	"time"
+)
+
+// AgentRuntimeConfig Agent 运行时配置，对应 agents.config（config_json）字段
+type AgentRuntimeConfig struct {
+	RunTimeout   Duration `json:"run_timeout"`    // 单次运行超时，如 "5m"
+	ToolTimeout  Duration `json:"tool_timeout"`   // 单次工具调用超时，如 "30s"
+	ToolCallMode string   `json:"tool_call_mode"` // 工具调用协议：text（默认）或 native
+	// MaxParallelTools 同一步骤内并发执行的工具调用数上限，默认 1（串行）
+	MaxParallelTools int `json:"max_parallel_tools"`
+	// MaxParallelSteps 计划中依赖已满足的步骤同时执行的数量上限，默认 4
+	MaxParallelSteps int `json:"max_parallel_steps"`
+	// PlanEvaluation 计划步骤评估与计划修订配置
+	PlanEvaluation PlanEvaluationConfig `json:"plan_evaluation"`
+	// PlanRepairAttempts 生成的计划未通过校验（工具不存在、输入不符合工具参数、依赖成环等）时交由模型修正的次数，默认 2，0 表示不修正
+	PlanRepairAttempts *int `json:"plan_repair_attempts"`
+	// Summary 完成后的总结阶段配置
+	Summary SummaryConfig `json:"summary"`
+	// StreamTokens 流式对话时是否推送模型输出的增量片段，默认开启
+	StreamTokens *bool `json:"stream_tokens"`
+	// Models 各执行阶段使用的模型，支持 "provider/model"，未配置的阶段使用 Agent 的模型
+	Models PhaseModels `json:"models"`
+	// LLMMiddleware 模型调用的重试、熔断、降级与限流配置
+	LLMMiddleware LLMMiddlewareConfig `json:"llm_middleware"`
+	// Budget 单次运行的用量预算，超出后以 BudgetExceeded 状态结束
+	Budget BudgetConfig `json:"budget"`
+	// ContextWindow 上下文窗口管理配置
+	ContextWindow ContextWindowConfig `json:"context_window"`
+	// Memory 对话记忆策略
+	Memory MemoryConfig `json:"memory"`
+	// ToolPolicy 工具调用策略：auto 直接执行，confirm 需人工审批，deny 禁止调用
+	ToolPolicy ToolPolicyConfig `json:"tool_policy"`
+	// Chain 链式 Agent 的节点与连线定义
+	Chain ChainConfig `json:"chain"`
+	// Delegation 计划步骤委派子 Agent 的配置
+	Delegation DelegationConfig `json:"delegation"`
+	// Supervisor 调度者 Agent 的工作 Agent 配置
+	Supervisor SupervisorConfig `json:"supervisor"`
+	// Expose 以 MCP 服务端对外提供该 Agent 的配置
+	Expose ExposeConfig `json:"expose"`
+}
+
+// ChainConfig 链定义，可以是 JSON 对象，也可以是内容为 YAML 的字符串
+type ChainConfig struct {
+	Spec *agent.ChainSpec
+}
+
+func (c *ChainConfig) UnmarshalJSON(data []byte) error {
+	c.Spec = nil
+	if string(data) == "null" {
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		if strings.TrimSpace(text) == "" {
+			return nil
+		}
+		data = []byte(text)
+	}
+	spec, err := agent.ParseChainSpec(data)
+	if err != nil {
+		return err
+	}
+	c.Spec = spec
+	return nil
+}
+
+func (c ChainConfig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.Spec)
+}
+
+// PlanEvaluationConfig Plan Agent 的步骤评估配置
+type PlanEvaluationConfig struct {
+	// Enabled 每批步骤结束后由模型评估结果，决定继续、重试步骤、插入步骤或重新规划剩余步骤
+	Enabled bool `json:"enabled"`
+	// MaxRevisions 计划的修订次数上限（包括步骤失败触发的重新规划），默认 5
+	MaxRevisions int `json:"max_revisions"`
+}
+
+// ToolPolicyConfig 工具调用策略配置，规则按顺序匹配，都不匹配时使用 default
+type ToolPolicyConfig struct {
+	Default string           `json:"default"` // auto（默认）、confirm 或 deny
+	Rules   []ToolPolicyRule `json:"rules"`
+}
+
+// ToolPolicyRule 按工具名匹配的策略
+type ToolPolicyRule struct {
+	Pattern string `json:"pattern"` // 工具名模式，支持 * 与 ? 通配，如 "k8s@restart_*"
+	Policy  string `json:"policy"`
+}
+
+// Policies 转换为 agent.ToolPolicies
+func (c ToolPolicyConfig) Policies() agent.ToolPolicies {
+	policies := agent.ToolPolicies{Default: agent.ToolPolicy(c.Default)}
+	for _, rule := range c.Rules {
+		policies.Rules = append(policies.Rules, agent.ToolPolicyRule{Pattern: rule.Pattern, Policy: agent.ToolPolicy(rule.Policy)})
+	}
+	return policies
+}
+
+func (c ToolPolicyConfig) validate() error {
+	if !validToolPolicy(c.Default, true) {
+		return fmt.Errorf("unknown tool_policy.default %q", c.Default)
+	}
+	for _, rule := range c.Rules {
+		if _, err := path.Match(rule.Pattern, ""); err != nil || rule.Pattern == "" {
+			return fmt.Errorf("invalid tool_policy pattern %q", rule.Pattern)
+		}
+		if !validToolPolicy(rule.Policy, false) {
+			return fmt.Errorf("unknown tool_policy %q for pattern %q", rule.Policy, rule.Pattern)
+		}
+	}
+	return nil
+}
+
+func validToolPolicy(policy string, allowEmpty bool) bool {
+	switch agent.ToolPolicy(policy) {
+	case agent.ToolPolicyAuto, agent.ToolPolicyConfirm, agent.ToolPolicyDeny:
+		return true
+	case "":
+		return allowEmpty
+	}
+	return false
+}
+
+// MemoryConfig 对话记忆配置，未配置的项使用默认值
+type MemoryConfig struct {
+	Type       string  `json:"type"`        // simple（默认）、window、summary_buffer 或 vector
+	WindowSize int     `json:"window_size"` // window 与 vector 保留的最近消息数，默认 20
+	MaxTokens  int     `json:"max_tokens"`  // summary_buffer 保持原文的 token 上限，默认 2000
+	TopK       int     `json:"top_k"`       // vector 每轮召回的记录数，默认 5
+	MinScore   float64 `json:"min_score"`   // vector 召回的最低相似度
+	Store      string  `json:"store"`       // vector 的存储：memory（默认）或 milvus
+	Collection string  `json:"collection"`  // store 为 milvus 时的集合名，默认 agent_memory
+	// Persist 会话消息的保存方式：turns（默认）只保存每轮的问题与最终回答，all 保存包括中间思考与工具调用在内的全部消息
+	Persist string `json:"persist"`
+}
+
+// persistAll 会话是否保存全部消息
+func (c MemoryConfig) persistAll() bool {
+	return c.Persist == SessionPersistAll
+}
+
+func (c MemoryConfig) windowSize() int {
+	if c.WindowSize <= 0 {
+		return defaultMemoryWindowSize
+	}
+	return c.WindowSize
+}
+
+// ContextWindowConfig 上下文窗口管理配置，未配置的项使用默认值
+type ContextWindowConfig struct {
+	Disabled               bool           `json:"disabled"`                 // 关闭上下文窗口管理
+	ModelLimits            map[string]int `json:"model_limits"`             // 模型名（前缀）到上下文窗口 token 数
+	DefaultLimit           int            `json:"default_limit"`            // 未知模型的上下文窗口
+	ReserveTokens          int            `json:"reserve_tokens"`           // 为模型输出预留的 token 数
+	MaxObservationTokens   int            `json:"max_observation_tokens"`   // 超过该值的工具输出转存，-1 表示不转存
+	KeepRecentObservations int            `json:"keep_recent_observations"` // 压缩时保持完整的最近观察结果数
+	Compaction             string         `json:"compaction"`               // truncate（默认）或 summarize
+}
+
+// Config 转换为 window.Config
+func (c ContextWindowConfig) Config() window.Config {
+	return window.Config{
+		ModelLimits:            c.ModelLimits,
+		DefaultContextTokens:   c.DefaultLimit,
+		ReserveTokens:          c.ReserveTokens,
+		MaxObservationTokens:   c.MaxObservationTokens,
+		KeepRecentObservations: c.KeepRecentObservations,
+		Compaction:             c.Compaction,
+	}
+}
+
+// BudgetConfig 单次运行的用量预算，0 表示不限制
+type BudgetConfig struct {
+	MaxTokens int     `json:"max_tokens"` // 累计 token 数上限
+	MaxCost   float64 `json:"max_cost"`   // 累计费用上限，按配置文件 llm.pricing 计算
+}
+
+// Budget 转换为 llm.Budget
+func (c BudgetConfig) Budget() llm.Budget {
+	return llm.Budget{MaxTokens: c.MaxTokens, MaxCost: c.MaxCost}
+}
+
+// LLMMiddlewareConfig 模型调用中间件配置
+type LLMMiddlewareConfig struct {
+	Retry          *LLMRetryConfig     `json:"retry"`           // 未配置时默认最多尝试 3 次
+	CircuitBreaker bool                `json:"circuit_breaker"` // 按服务商熔断
+	FallbackModel  string              `json:"fallback_model"`  // 主模型失败时使用的备用模型
+	RateLimit      *LLMRateLimitConfig `json:"rate_limit"`      // 客户端令牌桶限流
+}
+
+// LLMRetryConfig 重试配置
+type LLMRetryConfig struct {
+	MaxAttempts int      `json:"max_attempts"` // 最大尝试次数（含首次），1 表示不重试
+	BaseDelay   Duration `json:"base_delay"`   // 退避基础时长，默认 500ms
+	MaxDelay    Duration `json:"max_delay"`    // 单次等待上限，默认 20s
+}
+
+// LLMRateLimitConfig 令牌桶限流配置
+type LLMRateLimitConfig struct {
+	RPS   float64 `json:"rps"`   // 每秒请求数
+	Burst int     `json:"burst"` // 突发容量，默认 1
+}
+
+// PhaseModels 各执行阶段的模型配置
+type PhaseModels struct {
+	Plan    string `json:"plan"`    // 计划生成与重新规划
+	React   string `json:"react"`   // ReAct 思考步骤
+	Summary string `json:"summary"` // 总结（summary.model 优先）
+}
+
+// Options 转换为 Agent 上下文选项
+func (m PhaseModels) Options() []agent.Option {
+	return []agent.Option{
+		agent.WithPhaseModel(agent.ModelPhasePlan, m.Plan),
+		agent.WithPhaseModel(agent.ModelPhaseReact, m.React),
+		agent.WithPhaseModel(agent.ModelPhaseSummary, m.Summary),
+	}
+}
+
+// executionOptions 工具策略、工具调用、计划执行与各阶段模型的执行选项，
+// 创建 Agent 与调度 agents 表中的工作 Agent 时都按 Agent 自身的配置设置
+func (c *AgentRuntimeConfig) executionOptions() []agent.Option {
+	opts := []agent.Option{
+		agent.WithToolTimeout(c.ToolTimeout.Std()),
+		agent.WithToolCallMode(agent.ToolCallMode(c.ToolCallMode)),
+		agent.WithMaxParallelTools(c.MaxParallelTools),
+		agent.WithMaxParallelSteps(c.MaxParallelSteps),
+		agent.WithPlanEvaluation(c.PlanEvaluation.Enabled),
+		agent.WithMaxPlanRevisions(c.PlanEvaluation.MaxRevisions),
+		agent.WithPlanRepairAttempts(c.PlanRepairs()),
+		agent.WithToolPolicies(c.ToolPolicy.Policies()),
+	}
+	return append(opts, c.Models.Options()...)
+}
+
+// StreamTokensEnabled 是否开启 token 级流式输出
+func (c *AgentRuntimeConfig) StreamTokensEnabled() bool {
+	return c.StreamTokens == nil || *c.StreamTokens
+}
+
+// PlanRepairs 计划修正次数，未配置时返回 -1 表示使用默认值
+func (c *AgentRuntimeConfig) PlanRepairs() int {
+	if c.PlanRepairAttempts == nil {
+		return -1
+	}
+	return *c.PlanRepairAttempts
+}
+
+// SummaryConfig 总结阶段配置
+type SummaryConfig struct {
+	Policy         string `json:"policy"`          // always（默认）、never 或 min_steps
+	MinSteps       int    `json:"min_steps"`       // policy 为 min_steps 时，执行步数超过该值才总结
+	Model          string `json:"model"`           // 总结使用的模型，为空时使用 Agent 的模型
+	PromptTemplate string `json:"prompt_template"` // 已注册的模版名称或提示词文本
+}
+
+// SummaryPolicy 转换为执行器的总结策略
+func (c SummaryConfig) SummaryPolicy() agent.SummaryPolicy {
+	return agent.SummaryPolicy{
+		Mode:           agent.SummaryMode(c.Policy),
+		MinSteps:       c.MinSteps,
+		Model:          c.Model,
+		PromptTemplate: c.PromptTemplate,
+	}
+}
+
+// ParseAgentRuntimeConfig 解析 config_json，空配置返回默认值
+func ParseAgentRuntimeConfig(configJSON string) (*AgentRuntimeConfig, error) {
+	cfg := &AgentRuntimeConfig{}
+	if strings.TrimSpace(configJSON) == "" {
+		return cfg, nil
+	}
+	if err := json.Unmarshal([]byte(configJSON), cfg); err != nil {
+		return nil, fmt.Errorf("parse config_json: %w", err)
+	}
+	switch agent.SummaryMode(cfg.Summary.Policy) {
+	case "", agent.SummaryAlways, agent.SummaryNever, agent.SummaryMinSteps:
+	default:
+		return nil, fmt.Errorf("parse config_json: unknown summary policy %q", cfg.Summary.Policy)
+	}
+	switch cfg.ContextWindow.Compaction {
+	case "", window.CompactionTruncate, window.CompactionSummarize:
+	default:
+		return nil, fmt.Errorf("parse config_json: unknown context_window.compaction %q", cfg.ContextWindow.Compaction)
+	}
+	switch cfg.Memory.Type {
+	case "", MemoryTypeSimple, MemoryTypeWindow, MemoryTypeSummaryBuffer, MemoryTypeVector:
+	default:
+		return nil, fmt.Errorf("parse config_json: unknown memory.type %q", cfg.Memory.Type)
+	}
+	switch cfg.Memory.Persist {
+	case "", SessionPersistTurns, SessionPersistAll:
+	default:
+		return nil, fmt.Errorf("parse config_json: unknown memory.persist %q", cfg.Memory.Persist)
+	}
+	switch cfg.Memory.Store {
+	case "", MemoryStoreMemory, MemoryStoreMilvus:
+	default:
+		return nil, fmt.Errorf("parse config_json: unknown memory.store %q", cfg.Memory.Store)
+	}
+	if cfg.Budget.MaxTokens < 0 || cfg.Budget.MaxCost < 0 {
+		return nil, fmt.Errorf("parse config_json: budget must not be negative")
+	}
+	if err := cfg.ToolPolicy.validate(); err != nil {
+		return nil, fmt.Errorf("parse config_json: %w", err)
+	}
+	if cfg.PlanEvaluation.MaxRevisions < 0 {
+		return nil, fmt.Errorf("parse config_json: plan_evaluation.max_revisions must not be negative")
+	}
+	if cfg.PlanRepairAttempts != nil && *cfg.PlanRepairAttempts < 0 {
+		return nil, fmt.Errorf("parse config_json: plan_repair_attempts must not be negative")
+	}
+	if cfg.Delegation.MaxDepth < 0 {
+		return nil, fmt.Errorf("parse config_json: delegation.max_depth must not be negative")
+	}
+	if err := cfg.Expose.validate(); err != nil {
+		return nil, fmt.Errorf("parse config_json: %w", err)
+	}
+	return cfg, nil
+}
+
+// Duration 支持 "30s" 形式字符串或以秒为单位数字的时长
+type Duration time.Duration
+
+// Std 转换为 time.Duration
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch value := v.(type) {
+	case nil:
+		*d = 0
+	case float64:
+		*d = Duration(value * float64(time.Second))
+	case string:
+		if value == "" {
+			*d = 0
+			return nil
+		}
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q: %w", value, err)
+		}
+		*d = Duration(parsed)
+	default:
+		return fmt.Errorf("invalid duration: %s", string(data))
+	}
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
@@ -17,7 +17,7 @@ func NewAgentFactory() *AgentFactory {
 	af := &AgentFactory{factory: make(map[agent.AgentType]IAgent)}
 	af.RegisterAgent(&reactAgent{})
 	af.RegisterAgent(&planAgent{})
-	af.RegisterAgent(&chainAgent{})
+	af.RegisterAgent(&chainAgent{factory: af})
 	af.RegisterAgent(&sqlAgent{})
 	af.RegisterAgent(&esAgent{})
 	af.RegisterAgent(&rootCauseAgent{})
@@ -46,6 +46,15 @@ func (factory *AgentFactory) CreateAgentExecutor(ctx context.Context,
 	return iAgent.CreateAgentExecutor(ctx, agentConfig, agentCtx)
 }
 
+// Validate 按 Agent 类型校验配置，未注册的类型按 react 处理，不做额外校验
+func (factory *AgentFactory) Validate(agentConfig *Agent) error {
+	iAgent := factory.findIAgent(agentConfig.Framework)
+	if iAgent == nil {
+		return nil
+	}
+	return iAgent.Validate(agentConfig)
+}
+
 func (factory *AgentFactory) findIAgent(framework string) IAgent {
 	iAgent, ok := factory.factory[agent.AgentType(framework)]
 	if ok {
@@ -62,8 +71,8 @@ func (factory *AgentFactory) findIAgent(framework string) IAgent {
 type planAgent struct {
 }
 
-func (s *planAgent) Validate() bool {
-	return true
+func (s *planAgent) Validate(agentConfig *Agent) error {
+	return nil
 }
 func (s *planAgent) CreateAgentExecutor(ctx context.Context,
 	agentConfig *Agent,
@@ -84,8 +93,8 @@ func (s *planAgent) Alias() string {
 type reactAgent struct {
 }
 
-func (s *reactAgent) Validate() bool {
-	return true
+func (s *reactAgent) Validate(agentConfig *Agent) error {
+	return nil
 }
 func (s *reactAgent) CreateAgentExecutor(ctx context.Context,
 	agentConfig *Agent,
@@ -103,19 +112,83 @@ func (s *reactAgent) Alias() string {
 }
 
 type chainAgent struct {
+	factory *AgentFactory
 }
 
-func (s *chainAgent) Validate() bool {
-	return true
+// Validate 校验 config_json 中的链定义，节点类型必须是已注册的非组合类型
+func (s *chainAgent) Validate(agentConfig *Agent) error {
+	runtimeConfig, err := ParseAgentRuntimeConfig(agentConfig.ConfigJSON)
+	if err != nil {
+		return err
+	}
+	spec := runtimeConfig.Chain.Spec
+	if spec == nil {
+		return nil
+	}
+	if err := spec.Validate(s.validNodeType); err != nil {
+		return fmt.Errorf("invalid chain: %w", err)
+	}
+	for _, node := range spec.Nodes {
+		if node.Type == agent.ChainNodeTypeMerge {
+			continue
+		}
+		nodeConfig, err := chainNodeConfig(agentConfig, node)
+		if err != nil {
+			return err
+		}
+		if err := s.factory.findIAgent(node.Type).Validate(nodeConfig); err != nil {
+			return fmt.Errorf("invalid chain node %s: %w", node.Name, err)
+		}
+	}
+	return nil
 }
+
+// CreateAgentExecutor 按 config_json 中的链定义创建执行器，未定义时使用单个 react 节点
 func (s *chainAgent) CreateAgentExecutor(ctx context.Context,
 	agentConfig *Agent,
 	agentCtx *agent.Context) (*agent.AgentExecutor, error) {
-	builder := agent.NewChainBuilder(agentCtx)
-	builder.AddNode("main", agent.ReactAgentType, 100)
-	ca := builder.Build()
-	return agent.NewChainAgentExecutor(agentCtx, ca), nil
+	runtimeConfig, err := ParseAgentRuntimeConfig(agentConfig.ConfigJSON)
+	if err != nil {
+		return nil, err
+	}
+	spec := runtimeConfig.Chain.Spec
+	if spec == nil {
+		builder := agent.NewChainBuilder(agentCtx)
+		builder.AddNode("main", agent.ReactAgentType, 100)
+		ca := builder.Build()
+		return agent.NewChainAgentExecutor(agentCtx, ca), nil
+	}
+	if err := spec.Validate(s.validNodeType); err != nil {
+		return nil, fmt.Errorf("invalid chain: %w", err)
+	}
+	return agent.BuildChain(agentCtx, spec, func(node agent.ChainNodeSpec, nodeCtx *agent.Context) (*agent.AgentExecutor, error) {
+		nodeConfig, err := chainNodeConfig(agentConfig, node)
+		if err != nil {
+			return nil, err
+		}
+		return s.factory.findIAgent(node.Type).CreateAgentExecutor(ctx, nodeConfig, nodeCtx)
+	})
+}
+
+func (s *chainAgent) validNodeType(nodeType string) bool {
+	iAgent := s.factory.findIAgent(nodeType)
+	return iAgent != nil && !isComposite(iAgent)
 }
+
+// chainNodeConfig 链节点使用的 Agent 配置，节点未配置连接时沿用链的连接配置
+func chainNodeConfig(agentConfig *Agent, node agent.ChainNodeSpec) (*Agent, error) {
+	nodeConfig := *agentConfig
+	nodeConfig.Framework = node.Type
+	if node.ConnectionConfig != nil {
+		data, err := json.Marshal(node.ConnectionConfig)
+		if err != nil {
+			return nil, fmt.Errorf("chain node %s: invalid connection_config: %w", node.Name, err)
+		}
+		nodeConfig.ConnectionConfig = string(data)
+	}
+	return &nodeConfig, nil
+}
+
 func (s *chainAgent) AgentType() agent.AgentType {
 	return agent.ChainAgentType
 }
@@ -129,8 +202,8 @@ func (s *chainAgent) Alias() string {
 type sqlAgent struct {
 }
 
-func (s *sqlAgent) Validate() bool {
-	return true
+func (s *sqlAgent) Validate(agentConfig *Agent) error {
+	return nil
 }
 
 type sqlConnectionConfig struct {
@@ -204,8 +277,8 @@ func (s *sqlAgent) Alias() string {
 type esAgent struct {
 }
 
-func (s *esAgent) Validate() bool {
-	return true
+func (s *esAgent) Validate(agentConfig *Agent) error {
+	return nil
 }
 func (s *esAgent) CreateAgentExecutor(ctx context.Context,
 	agentConfig *Agent,
@@ -269,8 +342,8 @@ func (s *esAgent) Alias() string {
 type rootCauseAgent struct {
 }
 
-func (s *rootCauseAgent) Validate() bool {
-	return true
+func (s *rootCauseAgent) Validate(agentConfig *Agent) error {
+	return nil
 }
 
 type rootCauseConnectionConfig struct {
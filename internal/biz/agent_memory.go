@@ -0,0 +1,133 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+
+	"jas-agent/agent/core"
+	"jas-agent/agent/memory"
+	"jas-agent/agent/rag/embedding"
+	"jas-agent/agent/rag/vectordb"
+	"jas-agent/agent/window"
+	"jas-agent/internal/conf"
+)
+
+// 记忆类型
+const (
+	MemoryTypeSimple        = "simple"
+	MemoryTypeWindow        = "window"
+	MemoryTypeSummaryBuffer = "summary_buffer"
+	MemoryTypeVector        = "vector"
+)
+
+// 长期记忆的向量存储
+const (
+	MemoryStoreMemory = "memory"
+	MemoryStoreMilvus = "milvus"
+)
+
+// 会话消息的保存方式
+const (
+	SessionPersistTurns = "turns"
+	SessionPersistAll   = "all"
+)
+
+const (
+	defaultMemoryWindowSize       = 20
+	defaultMemoryMaxTokens        = 2000
+	defaultMemoryMilvusCollection = "agent_memory"
+	// memoryStoreConnectTimeout 创建长期记忆向量存储（连接 Milvus、检查集合）的超时
+	memoryStoreConnectTimeout = 30 * time.Second
+)
+
+// agentMemories 按 Agent 配置组装记忆，长期记忆的向量存储在所有 Agent 间共享，按命名空间隔离
+type agentMemories struct {
+	embedder embedding.Embedder
+	milvus   *conf.Data_Milvus
+	logger   *log.Helper
+
+	mu     sync.Mutex
+	stores map[string]vectordb.VectorStore // store/collection -> VectorStore
+}
+
+func newAgentMemories(embedder embedding.Embedder, milvus *conf.Data_Milvus, logger log.Logger) *agentMemories {
+	return &agentMemories{
+		embedder: embedder,
+		milvus:   milvus,
+		logger:   log.NewHelper(log.With(logger, "module", "biz/memory")),
+		stores:   map[string]vectordb.VectorStore{},
+	}
+}
+
+// wrap 在会话或内存记忆之上叠加配置的记忆策略，完整消息仍写入 inner，token 按 model 的分词方式计算
+func (m *agentMemories) wrap(ctx context.Context, inner core.Memory, agentID int, model string, config MemoryConfig,
+	summarizer window.Summarizer) (core.Memory, error) {
+	switch config.Type {
+	case "", MemoryTypeSimple:
+		return inner, nil
+	case MemoryTypeWindow:
+		return memory.NewWindowMemory(inner, config.windowSize()), nil
+	case MemoryTypeSummaryBuffer:
+		maxTokens := config.MaxTokens
+		if maxTokens <= 0 {
+			maxTokens = defaultMemoryMaxTokens
+		}
+		return memory.NewSummaryBufferMemory(ctx, inner, summarizer, window.CounterForModel(model), maxTokens), nil
+	case MemoryTypeVector:
+		store, err := m.store(config)
+		if err != nil {
+			return nil, err
+		}
+		return memory.NewVectorMemory(ctx, memory.NewWindowMemory(inner, config.windowSize()), m.embedder, store,
+			memory.VectorMemoryConfig{
+				Namespace: fmt.Sprintf("agent-%d", agentID),
+				TopK:      config.TopK,
+				MinScore:  config.MinScore,
+			}), nil
+	default:
+		return nil, fmt.Errorf("unknown memory type %q", config.Type)
+	}
+}
+
+// store 获取长期记忆的向量存储，首次使用时创建。
+// 存储在所有运行间共享，不使用触发创建的请求上下文，避免请求结束后存储随之失效
+func (m *agentMemories) store(config MemoryConfig) (vectordb.VectorStore, error) {
+	if m.embedder == nil {
+		return nil, fmt.Errorf("vector memory: embedder is not configured")
+	}
+	collection := config.Collection
+	if collection == "" {
+		collection = defaultMemoryMilvusCollection
+	}
+	key := config.Store + "/" + collection
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if store, ok := m.stores[key]; ok {
+		return store, nil
+	}
+	var store vectordb.VectorStore
+	switch config.Store {
+	case MemoryStoreMilvus:
+		if m.milvus == nil {
+			return nil, fmt.Errorf("vector memory: milvus is not configured")
+		}
+		milvusCfg := vectordb.DefaultMilvusConfig(m.milvus.Host, m.milvus.Port, collection, m.embedder.Dimensions())
+		ctx, cancel := context.WithTimeout(context.Background(), memoryStoreConnectTimeout)
+		defer cancel()
+		var err error
+		store, err = vectordb.NewMilvusStore(ctx, milvusCfg)
+		if err != nil {
+			return nil, fmt.Errorf("vector memory: create milvus store: %w", err)
+		}
+	default:
+		// 默认使用进程内存储：不持久化，服务重启后记忆丢失，多实例部署时各实例的记忆互不可见
+		m.logger.Warnf("vector memory collection %s uses the in-process store, memories are lost on restart and not shared between instances; set memory.store to milvus for durable memory", collection)
+		store = vectordb.NewInMemoryStore(m.embedder.Dimensions())
+	}
+	m.stores[key] = store
+	return store, nil
+}
@@ -0,0 +1,106 @@
+package biz
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+
+	"jas-agent/agent/core"
+	"jas-agent/agent/memory"
+)
+
+// topicEmbedder 按关键词生成向量，维度依次为 order、refund 与恒为 1 的公共维度
+type topicEmbedder struct{}
+
+func (topicEmbedder) Embed(_ context.Context, text string) ([]float32, error) {
+	vector := []float32{0, 0, 1}
+	for _, word := range strings.Fields(text) {
+		switch word {
+		case "order":
+			vector[0]++
+		case "refund":
+			vector[1]++
+		}
+	}
+	return vector, nil
+}
+
+func (e topicEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i], _ = e.Embed(ctx, text)
+	}
+	return vectors, nil
+}
+
+func (topicEmbedder) Dimensions() int { return 3 }
+
+// recallNote 返回召回历史记录的系统消息
+func recallNote(messages []core.Message) string {
+	for _, message := range messages {
+		if message.Role == core.MessageRoleSystem && strings.HasPrefix(message.Content, "[相关历史记录]") {
+			return message.Content
+		}
+	}
+	return ""
+}
+
+func TestAgentMemoriesVector(t *testing.T) {
+	ctx := context.Background()
+	config := MemoryConfig{Type: MemoryTypeVector}
+	tests := []struct {
+		name    string
+		agentID int
+		topK    int
+		want    []string
+	}{
+		// 按相似度排序召回
+		{name: "same agent", agentID: 1, want: []string{"refund order approved", "order shipped"}},
+		{name: "top k", agentID: 1, topK: 1, want: []string{"refund order approved"}},
+		// 存储在 Agent 间共享，召回按 Agent 隔离
+		{name: "other agent", agentID: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memories := newAgentMemories(topicEmbedder{}, nil, log.DefaultLogger)
+			// Agent 1 此前的运行写入两条记录
+			previous, err := memories.wrap(ctx, memory.NewMemory(), 1, "", config, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			previous.AddMessages([]core.Message{
+				{Role: core.MessageRoleAssistant, Content: "order shipped"},
+				{Role: core.MessageRoleAssistant, Content: "refund order approved"},
+			})
+
+			config := config
+			config.TopK = tt.topK
+			mem, err := memories.wrap(ctx, memory.NewMemory(), tt.agentID, "", config, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			mem.AddMessage(core.Message{Role: core.MessageRoleUser, Content: "refund"})
+
+			note := recallNote(mem.GetMessages())
+			var got []string
+			for _, line := range strings.Split(note, "\n")[1:] {
+				if line != "" {
+					got = append(got, line[strings.Index(line, "] ")+2:])
+				}
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Fatalf("recalled %q, want %q", got, tt.want)
+			}
+			if len(memories.stores) != 1 {
+				t.Fatalf("created %d stores, want one shared store", len(memories.stores))
+			}
+		})
+	}
+
+	// 未配置 embedding 时不能使用长期记忆
+	if _, err := newAgentMemories(nil, nil, log.DefaultLogger).wrap(ctx, memory.NewMemory(), 1, "", config, nil); err == nil {
+		t.Fatal("wrap() created vector memory without an embedder")
+	}
+}
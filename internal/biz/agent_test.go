@@ -0,0 +1,90 @@
+package biz
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+
+	"jas-agent/agent/agent"
+	"jas-agent/agent/llm"
+	pb "jas-agent/api/agent/service/v1"
+)
+
+func TestChatMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxSteps int
+		// wantSteps 执行的步数，wantTools 调用的工具
+		wantSteps    int32
+		wantTools    []string
+		wantState    agent.State
+		wantResponse string
+	}{
+		{
+			name:         "finished",
+			wantSteps:    2,
+			wantTools:    []string{"lookup"},
+			wantState:    agent.FinishState,
+			wantResponse: "the answer is 42",
+		},
+		{
+			// 达到最大步数时返回已执行的步骤与错误状态
+			name:      "max steps reached",
+			maxSteps:  1,
+			wantSteps: 1,
+			wantTools: []string{"lookup"},
+			wantState: agent.ErrorState,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 每次模型调用消耗 100 个输入 token 与 10 个输出 token
+			chat := &fakeChat{respond: func(req llm.ChatRequest) (*llm.ChatResponse, error) {
+				resp, err := lookupThenAnswer(req)
+				resp.Usage = openai.Usage{PromptTokens: 100, CompletionTokens: 10, TotalTokens: 110}
+				return resp, err
+			}}
+			agentConfig := nativeAgent(1)
+			agentConfig.Model = "test-model"
+			runs := newFakeRunRepo()
+			uc := newTestUsecase(chat, &fakeAgentRepo{agents: map[int]*Agent{1: agentConfig}}, runs)
+			uc.pricing = llm.Pricing{"test-model": {Prompt: 1000, Completion: 2000}}
+
+			resp, err := uc.Chat(context.Background(), &pb.ChatRequest{Query: "what is the answer", AgentId: 1, MaxSteps: int32(tt.maxSteps)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantResponse != "" && resp.Response != tt.wantResponse {
+				t.Fatalf("response = %q, want %q", resp.Response, tt.wantResponse)
+			}
+			if resp.AgentType != string(agent.ReactAgentType) {
+				t.Fatalf("agent type = %q", resp.AgentType)
+			}
+			if (resp.FinalResult != nil) != (tt.wantState == agent.FinishState) {
+				t.Fatalf("final result = %+v in state %s", resp.FinalResult, tt.wantState)
+			}
+
+			metadata := resp.Metadata
+			if metadata.TotalSteps != tt.wantSteps || metadata.State != string(tt.wantState) || metadata.ExecutionTimeMs < 0 {
+				t.Fatalf("metadata = %+v, want %d steps in state %s", metadata, tt.wantSteps, tt.wantState)
+			}
+			if metadata.ToolsCalled != int32(len(tt.wantTools)) || len(metadata.ToolNames) != len(tt.wantTools) || metadata.ToolNames[0] != tt.wantTools[0] {
+				t.Fatalf("tools called = %d %q, want %q", metadata.ToolsCalled, metadata.ToolNames, tt.wantTools)
+			}
+			// 用量包含本次运行的所有模型调用（含总结）
+			calls := int64(chat.calls())
+			if metadata.PromptTokens != 100*calls || metadata.CompletionTokens != 10*calls || metadata.TotalTokens != 110*calls {
+				t.Fatalf("usage = %d/%d/%d after %d model calls", metadata.PromptTokens, metadata.CompletionTokens, metadata.TotalTokens, calls)
+			}
+			if wantCost := float64(calls) * (100*1000 + 10*2000) / 1e6; math.Abs(metadata.Cost-wantCost) > 1e-9 {
+				t.Fatalf("cost = %v, want %v", metadata.Cost, wantCost)
+			}
+			// 返回的运行与保存的运行记录一致
+			if run := runs.waitRun(t, metadata.RunId); run.Status != metadata.State {
+				t.Fatalf("run status = %s, metadata state = %s", run.Status, metadata.State)
+			}
+		})
+	}
+}
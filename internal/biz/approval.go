@@ -0,0 +1,184 @@
+package biz
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"jas-agent/agent/agent"
+)
+
+var (
+	// ErrApprovalNotFound 审批不存在或已处理
+	ErrApprovalNotFound = errors.New("approval not found")
+	// ErrInvalidApproval 审批决定无效
+	ErrInvalidApproval = errors.New("invalid approval decision")
+)
+
+// defaultApprovalPollInterval 执行实例检查其他实例写入的审批决定的间隔
+const defaultApprovalPollInterval = time.Second
+
+const (
+	// RunEventApprovalRequired 工具调用等待审批，内容为审批请求
+	RunEventApprovalRequired = "APPROVAL_REQUIRED"
+	// RunEventApprovalDecision 审批结果，内容为审批请求与决定
+	RunEventApprovalDecision = "APPROVAL_DECISION"
+)
+
+// 审批状态
+const (
+	// ApprovalPending 等待决定
+	ApprovalPending = "Pending"
+	// ApprovalResolved 已作出决定
+	ApprovalResolved = "Resolved"
+	// ApprovalExpired 运行在作出决定前结束、中断或被恢复，审批失效
+	ApprovalExpired = "Expired"
+)
+
+// Approval 工具调用审批，审批请求与决定随运行记录持久化
+type Approval struct {
+	agent.ApprovalRequest
+	RunID      string
+	Status     string // Pending、Resolved、Expired
+	Decision   *agent.ApprovalDecision
+	CreatedAt  time.Time
+	ResolvedAt time.Time
+}
+
+// ApprovalRepo 定义审批记录数据访问接口，未配置数据库时审批只登记在本进程中
+type ApprovalRepo interface {
+	CreateApproval(ctx context.Context, approval *Approval) error
+	// GetApproval 获取审批，不存在时返回 ErrApprovalNotFound
+	GetApproval(ctx context.Context, id string) (*Approval, error)
+	// ResolveApproval 记录运行 runID 中等待决定的审批的决定，审批不属于该运行或已不在等待中时返回 false
+	ResolveApproval(ctx context.Context, runID, id string, decision agent.ApprovalDecision) (bool, error)
+	// ExpireApprovals 使运行中等待决定的审批失效，ids 为空时使该运行的全部等待中的审批失效
+	ExpireApprovals(ctx context.Context, runID string, ids ...string) error
+	// ListApprovals 按登记时间列出运行中指定状态的审批
+	ListApprovals(ctx context.Context, runID, status string) ([]*Approval, error)
+}
+
+// pendingApproval 本进程中执行的运行里等待决定的审批
+type pendingApproval struct {
+	approval *Approval
+	decision chan agent.ApprovalDecision
+}
+
+// approvalRecord 写入运行事件的审批结果
+type approvalRecord struct {
+	agent.ApprovalRequest
+	Decision agent.ApprovalDecision `json:"decision"`
+}
+
+// runApprover 登记运行中等待审批的工具调用，审批请求与结果写入运行事件。
+// 决定可以由任一实例作出：本实例直接送达，其他实例写入的决定通过轮询审批记录送达
+func (s *AgentUsecase) runApprover(runID string) agent.Approver {
+	return agent.ApproverFunc(func(ctx context.Context, req agent.ApprovalRequest) (<-chan agent.ApprovalDecision, error) {
+		pending := &pendingApproval{
+			approval: &Approval{ApprovalRequest: req, RunID: runID, Status: ApprovalPending, CreatedAt: time.Now()},
+			decision: make(chan agent.ApprovalDecision, 1),
+		}
+		if err := s.approvalRepo.CreateApproval(ctx, pending.approval); err != nil {
+			return nil, err
+		}
+		s.approvals.Store(req.ID, pending)
+		// 运行结束时未处理的审批随之失效
+		context.AfterFunc(ctx, func() {
+			if _, ok := s.approvals.LoadAndDelete(req.ID); !ok {
+				return
+			}
+			if err := s.approvalRepo.ExpireApprovals(context.Background(), runID, req.ID); err != nil {
+				s.logger.Warnf("expire approval %s: %v", req.ID, err)
+			}
+		})
+		if data, err := json.Marshal(req); err == nil {
+			s.addRunEvent(ctx, runID, RunEventApprovalRequired, string(data), 0)
+		}
+		go s.watchApproval(ctx, pending)
+		return pending.decision, nil
+	})
+}
+
+// watchApproval 定期检查审批记录，将其他实例写入的决定送达等待中的运行
+func (s *AgentUsecase) watchApproval(ctx context.Context, pending *pendingApproval) {
+	ticker := time.NewTicker(s.approvalPollInterval)
+	defer ticker.Stop()
+	id := pending.approval.ID
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		approval, err := s.approvalRepo.GetApproval(ctx, id)
+		if errors.Is(err, ErrApprovalNotFound) {
+			// 审批未持久化，只能在本实例处理
+			return
+		}
+		if err != nil {
+			s.logger.Warnf("get approval %s: %v", id, err)
+			continue
+		}
+		switch approval.Status {
+		case ApprovalPending:
+			continue
+		case ApprovalResolved:
+			if _, ok := s.approvals.LoadAndDelete(id); ok && approval.Decision != nil {
+				pending.decision <- *approval.Decision
+			}
+		}
+		return
+	}
+}
+
+// ResolveApproval 处理运行 runID 中的审批：approve 按原参数执行，edit 按新参数执行，reject 不执行。
+// 审批不属于该运行或已处理时返回 ErrApprovalNotFound
+func (s *AgentUsecase) ResolveApproval(ctx context.Context, runID, id string, decision agent.ApprovalDecision) (*Approval, error) {
+	switch decision.Action {
+	case agent.ApprovalApprove, agent.ApprovalReject:
+	case agent.ApprovalEdit:
+		if decision.Arguments == "" {
+			return nil, fmt.Errorf("%w: arguments are required for edit", ErrInvalidApproval)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown action %q", ErrInvalidApproval, decision.Action)
+	}
+	approval, err := s.approvalRepo.GetApproval(ctx, id)
+	persisted := err == nil
+	if errors.Is(err, ErrApprovalNotFound) {
+		// 未持久化的审批只登记在本进程中
+		if v, ok := s.approvals.Load(id); ok {
+			approval, err = v.(*pendingApproval).approval, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if approval.RunID != runID || approval.Status != ApprovalPending {
+		return nil, fmt.Errorf("%w: %s", ErrApprovalNotFound, id)
+	}
+	if persisted {
+		resolved, err := s.approvalRepo.ResolveApproval(ctx, runID, id, decision)
+		if err != nil {
+			return nil, err
+		}
+		if !resolved {
+			return nil, fmt.Errorf("%w: %s", ErrApprovalNotFound, id)
+		}
+	}
+	// 运行在本实例执行时直接送达，否则由执行实例轮询审批记录
+	if v, ok := s.approvals.LoadAndDelete(id); ok {
+		v.(*pendingApproval).decision <- decision
+	} else if !persisted {
+		return nil, fmt.Errorf("%w: %s", ErrApprovalNotFound, id)
+	}
+	if data, err := json.Marshal(approvalRecord{ApprovalRequest: approval.ApprovalRequest, Decision: decision}); err == nil {
+		s.addRunEvent(ctx, runID, RunEventApprovalDecision, string(data), 0)
+	}
+	s.logger.Infof("Approval %s of run %s resolved: %s %s", id, runID, decision.Action, approval.ToolName)
+	result := *approval
+	result.Status, result.Decision, result.ResolvedAt = ApprovalResolved, &decision, time.Now()
+	return &result, nil
+}
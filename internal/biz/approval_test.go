@@ -0,0 +1,206 @@
+package biz
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"jas-agent/agent/agent"
+	pb "jas-agent/api/agent/service/v1"
+)
+
+// confirmAgent 调用 lookup 前需要审批的 Agent
+func confirmAgent(id int) *Agent {
+	config, _ := json.Marshal(map[string]any{
+		"tool_call_mode": "native",
+		"tool_policy":    map[string]any{"rules": []map[string]string{{"pattern": "look*", "policy": "confirm"}}},
+	})
+	result := nativeAgent(id)
+	result.ConfigJSON = string(config)
+	return result
+}
+
+// startApprovalRun 在 worker 实例上执行一个运行，返回运行ID与等待中的审批
+func startApprovalRun(t *testing.T, worker *AgentUsecase, runs *fakeRunRepo) (string, *Approval) {
+	t.Helper()
+	if _, err := worker.SubmitRun(context.Background(), &pb.ChatRequest{AgentId: 1, Query: "what is the answer"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	run, err := runs.Dequeue(context.Background(), worker.instanceID)
+	if err != nil || run == nil {
+		t.Fatalf("Dequeue() = %v, %v", run, err)
+	}
+	go worker.executeRun(run)
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if v, ok := worker.approvals.Load(pendingApprovalID(worker, run.ID)); ok {
+			return run.ID, v.(*pendingApproval).approval
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("run did not request approval")
+	return "", nil
+}
+
+// pendingApprovalID 本实例中运行 runID 等待决定的审批ID
+func pendingApprovalID(uc *AgentUsecase, runID string) string {
+	var id string
+	uc.approvals.Range(func(key, v any) bool {
+		if v.(*pendingApproval).approval.RunID == runID {
+			id = key.(string)
+			return false
+		}
+		return true
+	})
+	return id
+}
+
+func TestResolveApproval(t *testing.T) {
+	agents := &fakeAgentRepo{agents: map[int]*Agent{1: confirmAgent(1)}}
+	runs := newFakeRunRepo()
+	chat := &fakeChat{respond: lookupThenAnswer}
+	uc := newTestUsecase(chat, agents, runs)
+	runID, approval := startApprovalRun(t, uc, runs)
+
+	if approval.ToolName != "lookup" || approval.Status != ApprovalPending {
+		t.Fatalf("approval = %+v", approval)
+	}
+	run, err := uc.GetRun(context.Background(), runID)
+	if err != nil || len(run.PendingApprovals) != 1 || run.PendingApprovals[0].ID != approval.ID {
+		t.Fatalf("GetRun() pending approvals = %v, %v", run.PendingApprovals, err)
+	}
+
+	invalid := []struct {
+		name     string
+		runID    string
+		decision agent.ApprovalDecision
+		wantErr  error
+	}{
+		// 审批只能在其所属的运行中处理
+		{"other run", "other-run", agent.ApprovalDecision{Action: agent.ApprovalApprove}, ErrApprovalNotFound},
+		{"no run", "", agent.ApprovalDecision{Action: agent.ApprovalApprove}, ErrApprovalNotFound},
+		{"unknown action", runID, agent.ApprovalDecision{Action: "maybe"}, ErrInvalidApproval},
+		{"edit without arguments", runID, agent.ApprovalDecision{Action: agent.ApprovalEdit}, ErrInvalidApproval},
+	}
+	for _, tt := range invalid {
+		if _, err := uc.ResolveApproval(context.Background(), tt.runID, approval.ID, tt.decision); !errors.Is(err, tt.wantErr) {
+			t.Fatalf("%s: ResolveApproval() error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+	if stored, _ := runs.GetApproval(context.Background(), approval.ID); stored.Status != ApprovalPending {
+		t.Fatalf("approval is %s after invalid decisions", stored.Status)
+	}
+
+	resolved, err := uc.ResolveApproval(context.Background(), runID, approval.ID, agent.ApprovalDecision{Action: agent.ApprovalApprove})
+	if err != nil || resolved.Status != ApprovalResolved {
+		t.Fatalf("ResolveApproval() = %+v, %v", resolved, err)
+	}
+	done := runs.waitRun(t, runID)
+	if done.Status != string(agent.FinishState) || done.Result != "the answer is 42" {
+		t.Fatalf("run = %s %q", done.Status, done.Result)
+	}
+	stored, _ := runs.GetApproval(context.Background(), approval.ID)
+	if stored.Status != ApprovalResolved || stored.Decision == nil || stored.Decision.Action != agent.ApprovalApprove {
+		t.Fatalf("stored approval = %+v", stored)
+	}
+	// 已处理的审批不能再次处理
+	if _, err := uc.ResolveApproval(context.Background(), runID, approval.ID, agent.ApprovalDecision{Action: agent.ApprovalReject}); !errors.Is(err, ErrApprovalNotFound) {
+		t.Fatalf("second ResolveApproval() error = %v", err)
+	}
+	var decisions int
+	events, _ := runs.ListRunEvents(context.Background(), runID, 0, maxRunEventLimit)
+	for _, event := range events {
+		if event.Type == RunEventApprovalDecision {
+			decisions++
+		}
+	}
+	if decisions != 1 {
+		t.Fatalf("recorded %d approval decisions, want 1", decisions)
+	}
+}
+
+func TestResolveApprovalOnAnotherInstance(t *testing.T) {
+	agents := &fakeAgentRepo{agents: map[int]*Agent{1: confirmAgent(1)}}
+	runs := newFakeRunRepo()
+	chat := &fakeChat{respond: lookupThenAnswer}
+	worker := newTestUsecase(chat, agents, runs)
+	worker.approvalPollInterval = 10 * time.Millisecond
+	api := newTestUsecase(&fakeChat{respond: lookupThenAnswer}, agents, runs)
+	runID, approval := startApprovalRun(t, worker, runs)
+
+	// 审批请求发往未执行该运行的实例，执行实例从审批记录中读取决定
+	decision := agent.ApprovalDecision{Action: agent.ApprovalReject, Reason: "change freeze"}
+	if _, err := api.ResolveApproval(context.Background(), runID, approval.ID, decision); err != nil {
+		t.Fatal(err)
+	}
+	done := runs.waitRun(t, runID)
+	if done.Status != string(agent.FinishState) {
+		t.Fatalf("run = %s", done.Status)
+	}
+	rejected := false
+	for _, req := range chat.requests {
+		if strings.Contains(toolResults(req), "change freeze") {
+			rejected = true
+		}
+		if strings.Contains(toolResults(req), "lookup result 42") {
+			t.Fatal("rejected tool call was executed")
+		}
+	}
+	if !rejected {
+		t.Fatal("rejection reason was not returned to the model")
+	}
+}
+
+func TestApprovalWithoutPersistence(t *testing.T) {
+	agents := &fakeAgentRepo{agents: map[int]*Agent{1: confirmAgent(1)}}
+	runs := newFakeRunRepo()
+	runs.noApprovals = true
+	worker := newTestUsecase(&fakeChat{respond: lookupThenAnswer}, agents, runs)
+	api := newTestUsecase(&fakeChat{respond: lookupThenAnswer}, agents, runs)
+	runID, approval := startApprovalRun(t, worker, runs)
+
+	// 未持久化的审批只能由执行运行的实例处理
+	if _, err := api.ResolveApproval(context.Background(), runID, approval.ID, agent.ApprovalDecision{Action: agent.ApprovalApprove}); !errors.Is(err, ErrApprovalNotFound) {
+		t.Fatalf("ResolveApproval() on another instance error = %v", err)
+	}
+	if _, err := worker.ResolveApproval(context.Background(), "other-run", approval.ID, agent.ApprovalDecision{Action: agent.ApprovalApprove}); !errors.Is(err, ErrApprovalNotFound) {
+		t.Fatalf("ResolveApproval() for another run error = %v", err)
+	}
+	if _, err := worker.ResolveApproval(context.Background(), runID, approval.ID, agent.ApprovalDecision{Action: agent.ApprovalApprove}); err != nil {
+		t.Fatal(err)
+	}
+	if done := runs.waitRun(t, runID); done.Status != string(agent.FinishState) {
+		t.Fatalf("run = %s", done.Status)
+	}
+}
+
+func TestApprovalExpiresWhenRunEnds(t *testing.T) {
+	agents := &fakeAgentRepo{agents: map[int]*Agent{1: confirmAgent(1)}}
+	runs := newFakeRunRepo()
+	uc := newTestUsecase(&fakeChat{respond: lookupThenAnswer}, agents, runs)
+	runID, approval := startApprovalRun(t, uc, runs)
+
+	if _, err := uc.CancelRun(context.Background(), runID); err != nil {
+		t.Fatal(err)
+	}
+	if done := runs.waitRun(t, runID); done.Status != string(agent.CanceledState) {
+		t.Fatalf("run = %s", done.Status)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		stored, _ := runs.GetApproval(context.Background(), approval.ID)
+		if stored.Status == ApprovalExpired {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("approval is %s after the run was canceled", stored.Status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if _, err := uc.ResolveApproval(context.Background(), runID, approval.ID, agent.ApprovalDecision{Action: agent.ApprovalApprove}); !errors.Is(err, ErrApprovalNotFound) {
+		t.Fatalf("ResolveApproval() of an expired approval error = %v", err)
+	}
+}
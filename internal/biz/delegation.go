@@ -0,0 +1,150 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"jas-agent/agent/agent"
+	"jas-agent/agent/core"
+	"jas-agent/agent/tools"
+)
+
+// DelegationConfig 计划步骤委派子 Agent 的配置
+type DelegationConfig struct {
+	// Agents 可委派的子 Agent：Agent 类型（如 "sql"，沿用本 Agent 的连接配置）或 agents 表中的 Agent ID（如 "12"）
+	Agents []string `json:"agents"`
+	// MaxDepth 子 Agent 的嵌套层数上限，默认 2
+	MaxDepth int `json:"max_depth"`
+}
+
+// validateDelegation 可委派的子 Agent 必须是已注册的非组合类型或 Agent ID
+func (s *AgentUsecase) validateDelegation(cfg DelegationConfig) error {
+	for _, name := range cfg.Agents {
+		if s.delegableType(name) != nil {
+			continue
+		}
+		if id, err := strconv.Atoi(name); err != nil || id <= 0 {
+			return fmt.Errorf("invalid delegation agent %q: must be an agent type or agent id", name)
+		}
+	}
+	return nil
+}
+
+// delegableType 可作为子 Agent 的类型，链式与调度者类型依赖自身的链定义或工作 Agent，不能按类型委派
+func (s *AgentUsecase) delegableType(name string) IAgent {
+	iAgent := s.factory.findIAgent(name)
+	if iAgent == nil || isComposite(iAgent) {
+		return nil
+	}
+	return iAgent
+}
+
+// isComposite 是否为由其它 Agent 组合而成的类型，这类 Agent 只能通过 Agent ID 引用
+func isComposite(iAgent IAgent) bool {
+	return iAgent.AgentType() == agent.ChainAgentType || iAgent.AgentType() == agent.SupervisorAgentType
+}
+
+// subAgentFactory 按委派配置加载子 Agent 的配置，返回创建子 Agent 的工厂与提供给模型的子 Agent 列表
+func (s *AgentUsecase) subAgentFactory(ctx context.Context, agentConfig *Agent, cfg DelegationConfig) (agent.SubAgentFactory, []agent.SubAgent, error) {
+	configs := make(map[string]*Agent, len(cfg.Agents))
+	// runtimeConfigs agents 表中子 Agent 自身的 config_json，按类型委派的子 Agent 沿用本 Agent 的配置
+	runtimeConfigs := make(map[string]*AgentRuntimeConfig, len(cfg.Agents))
+	subAgents := make([]agent.SubAgent, 0, len(cfg.Agents))
+	for _, name := range cfg.Agents {
+		if iAgent := s.delegableType(name); iAgent != nil {
+			subConfig := *agentConfig
+			subConfig.Framework = name
+			subConfig.SystemPrompt = ""
+			subConfig.MaxSteps = 0
+			configs[name] = &subConfig
+			subAgents = append(subAgents, agent.SubAgent{Name: name, Description: iAgent.Description()})
+			continue
+		}
+		id, err := strconv.Atoi(name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid delegation agent %q: must be an agent type or agent id", name)
+		}
+		subConfig, err := s.agentRepo.GetAgent(ctx, id)
+		if err != nil {
+			return nil, nil, fmt.Errorf("load delegation agent %d: %w", id, err)
+		}
+		if !subConfig.IsActive {
+			return nil, nil, fmt.Errorf("delegation agent %d (%s) is not active", id, subConfig.Name)
+		}
+		runtimeConfig, err := ParseAgentRuntimeConfig(subConfig.ConfigJSON)
+		if err != nil {
+			return nil, nil, fmt.Errorf("delegation agent %d (%s): %w", id, subConfig.Name, err)
+		}
+		configs[name] = subConfig
+		runtimeConfigs[name] = runtimeConfig
+		subAgents = append(subAgents, agent.SubAgent{
+			Name:        name,
+			Description: fmt.Sprintf("%s（%s）%s", subConfig.Name, subConfig.Framework, subConfig.Description),
+		})
+	}
+
+	factory := func(ctx context.Context, name string, subCtx *agent.Context) (*agent.AgentExecutor, error) {
+		subConfig, ok := configs[name]
+		if !ok {
+			return nil, fmt.Errorf("agent %q is not available for delegation", name)
+		}
+		// agents 表中的 Agent 使用自身的模型、MCP 工具、系统提示词与 config_json 中的工具策略、执行选项、
+		// 各阶段模型、运行预算与委派配置，不沿用上级 Agent 的设置；嵌套层数上限仍由最上层的 Agent 决定
+		runtimeConfig := runtimeConfigs[name]
+		if runtimeConfig != nil {
+			opts := append([]agent.Option{agent.WithoutPhaseModels()}, runtimeConfig.executionOptions()...)
+			opts = append(opts, agent.WithRunBudget(runtimeConfig.Budget.Budget(), s.pricing), agent.WithSubAgents(nil, nil))
+			if len(runtimeConfig.Delegation.Agents) > 0 {
+				subFactory, subSubAgents, err := s.subAgentFactory(ctx, subConfig, runtimeConfig.Delegation)
+				if err != nil {
+					return nil, err
+				}
+				opts = append(opts, agent.WithSubAgents(subFactory, subSubAgents))
+			}
+			subCtx = subCtx.Derive(opts...)
+		}
+		if subConfig.Model != "" {
+			subCtx = subCtx.Derive(agent.WithModel(subConfig.Model))
+		}
+		var closers []io.Closer
+		closeAll := func() {
+			for _, closer := range closers {
+				_ = closer.Close()
+			}
+		}
+		for _, server := range subConfig.MCPServers {
+			mcpManager, err := tools.NewMCPToolManager(server.Name, server.Endpoint, subCtx.GetToolManager(), tools.TransferToMcpClientType(server.ClientType))
+			if err != nil {
+				closeAll()
+				return nil, err
+			}
+			closers = append(closers, mcpManager)
+			mcpManager.DiscoverAndRegisterTools()
+		}
+		if subConfig.SystemPrompt != "" {
+			subCtx.GetMemory().AddMessage(core.Message{
+				Role:    core.MessageRoleSystem,
+				Content: subConfig.SystemPrompt,
+			})
+		}
+		executor, err := s.factory.CreateAgentExecutor(ctx, subConfig, subCtx)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		// 子 Agent 连接的 MCP 客户端在其运行结束后关闭
+		for _, closer := range closers {
+			executor.AddCloser(closer)
+		}
+		if subConfig.MaxSteps > 0 {
+			executor.SetMaxSteps(subConfig.MaxSteps)
+		}
+		if runtimeConfig != nil {
+			executor.SetRunTimeout(runtimeConfig.RunTimeout.Std())
+		}
+		return executor, nil
+	}
+	return factory, subAgents, nil
+}
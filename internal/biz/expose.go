@@ -0,0 +1,219 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	agent "jas-agent/agent/agent"
+	"jas-agent/agent/core"
+	tools "jas-agent/agent/tools"
+	pb "jas-agent/api/agent/service/v1"
+
+	"github.com/google/uuid"
+)
+
+// ErrAgentNotExposed Agent 不存在、未启用或未开启对外提供
+var ErrAgentNotExposed = errors.New("agent is not exposed")
+
+// exposedToolNamePattern 对外的工具名只能包含字母、数字、下划线与中划线
+var exposedToolNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// ExposeConfig 以 MCP 服务端对外提供 Agent 的配置
+type ExposeConfig struct {
+	// MCP 是否作为 MCP 工具对外提供，工具输入为 query 与可选的 session_id
+	MCP bool `json:"mcp"`
+	// ToolName 对外的工具名，默认 agent_<id>
+	ToolName string `json:"tool_name"`
+	// Tools 同时对外提供的 Agent 自身工具（如按连接配置注册的 SQL、ES 工具或 MCP 工具），
+	// path.Match 模式，对外名称为 <tool_name>_<工具名>
+	Tools []string `json:"tools"`
+}
+
+func (c ExposeConfig) validate() error {
+	if c.ToolName != "" && !exposedToolNamePattern.MatchString(c.ToolName) {
+		return fmt.Errorf("invalid expose.tool_name %q: only letters, digits, '_' and '-' are allowed", c.ToolName)
+	}
+	for _, pattern := range c.Tools {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			return fmt.Errorf("invalid expose.tools pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+// ExposedAgent 对外提供的 Agent
+type ExposedAgent struct {
+	Agent    *Agent
+	ToolName string
+	// Tools 对外提供的 Agent 自身工具，未配置 expose.tools 或创建失败时为 nil
+	Tools *tools.ToolManager
+}
+
+// exposedTools 按 Agent 更新时间缓存的对外工具，Agent 更新后重新创建
+type exposedTools struct {
+	updatedAt time.Time
+	patterns  string
+	tools     *tools.ToolManager
+}
+
+// ExposedToolName 将工具名中 MCP 工具名不允许的字符替换为下划线
+func ExposedToolName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == '-' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// ListExposedAgents 列出已启用且开启 expose.mcp 的 Agent 及其对外提供的自身工具，
+// 配置无法解析或工具创建失败的 Agent 记录日志后跳过或不提供工具
+func (s *AgentUsecase) ListExposedAgents(ctx context.Context) ([]*ExposedAgent, error) {
+	agents, err := s.agentRepo.ListAgents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list agents: %w", err)
+	}
+	var exposed []*ExposedAgent
+	for _, agentConfig := range agents {
+		if !agentConfig.IsActive {
+			continue
+		}
+		runtimeConfig, err := ParseAgentRuntimeConfig(agentConfig.ConfigJSON)
+		if err != nil {
+			s.logger.Warnf("skip exposing agent %d: %v", agentConfig.ID, err)
+			continue
+		}
+		if !runtimeConfig.Expose.MCP {
+			continue
+		}
+		item := &ExposedAgent{Agent: agentConfig, ToolName: exposedAgentToolName(agentConfig, runtimeConfig.Expose)}
+		if len(runtimeConfig.Expose.Tools) > 0 {
+			item.Tools, err = s.exposedAgentTools(ctx, agentConfig, runtimeConfig)
+			if err != nil {
+				s.logger.Warnf("expose tools of agent %d: %v", agentConfig.ID, err)
+			}
+		}
+		exposed = append(exposed, item)
+	}
+	return exposed, nil
+}
+
+// GetExposedAgent 获取对外提供的 Agent，未启用或未开启 expose.mcp 时返回 ErrAgentNotExposed
+func (s *AgentUsecase) GetExposedAgent(ctx context.Context, id int) (*Agent, error) {
+	agentConfig, err := s.agentRepo.GetAgent(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: agent %d: %v", ErrAgentNotExposed, id, err)
+	}
+	if !agentConfig.IsActive {
+		return nil, fmt.Errorf("%w: agent %d is not active", ErrAgentNotExposed, id)
+	}
+	runtimeConfig, err := ParseAgentRuntimeConfig(agentConfig.ConfigJSON)
+	if err != nil {
+		return nil, err
+	}
+	if !runtimeConfig.Expose.MCP {
+		return nil, fmt.Errorf("%w: agent %d", ErrAgentNotExposed, id)
+	}
+	return agentConfig, nil
+}
+
+// CheckExposedTool 检查 Agent 仍对外提供且其工具策略允许直接调用 toolName。
+// 对外的工具调用不经过审批，deny 与 confirm 的工具不能通过 MCP 调用
+func (s *AgentUsecase) CheckExposedTool(ctx context.Context, id int, toolName string) error {
+	agentConfig, err := s.GetExposedAgent(ctx, id)
+	if err != nil {
+		return err
+	}
+	runtimeConfig, err := ParseAgentRuntimeConfig(agentConfig.ConfigJSON)
+	if err != nil {
+		return err
+	}
+	if policy := runtimeConfig.ToolPolicy.Policies().PolicyFor(toolName); policy != agent.ToolPolicyAuto {
+		return fmt.Errorf("%w: %s of agent %d has tool policy %s", agent.ErrToolDenied, toolName, id, policy)
+	}
+	return nil
+}
+
+// ChatExposed 调用对外提供的 Agent 完成一次对话，用量计入 tenant，返回本次对话使用的会话ID。
+// 携带的会话必须已存在且属于该 Agent，未携带时创建新会话，调用方以返回的会话ID继续多轮对话；
+// 会话创建失败时记录日志，本次对话不使用会话
+func (s *AgentUsecase) ChatExposed(ctx context.Context, id int, query, sessionID, tenant string) (*pb.ChatResponse, string, error) {
+	if _, err := s.GetExposedAgent(ctx, id); err != nil {
+		return nil, "", err
+	}
+	switch {
+	case s.sessions == nil:
+		if sessionID != "" {
+			return nil, "", fmt.Errorf("%w: %s: sessions are not available", ErrSessionNotFound, sessionID)
+		}
+	case sessionID != "":
+		session, err := s.sessions.sessionRepo.GetSession(ctx, sessionID)
+		if err != nil {
+			return nil, "", err
+		}
+		if session.AgentID != id {
+			return nil, "", fmt.Errorf("%w: session %s, agent %d", ErrSessionAgentMismatch, sessionID, id)
+		}
+	default:
+		session := &Session{ID: uuid.NewString(), AgentID: id, Title: sessionTitle(query)}
+		if err := s.sessions.sessionRepo.CreateSession(ctx, session); err != nil {
+			s.logger.Warnf("create session for exposed agent %d: %v", id, err)
+		} else {
+			sessionID = session.ID
+		}
+	}
+	resp, err := s.Chat(ctx, &pb.ChatRequest{Query: query, AgentId: int32(id), SessionId: sessionID, TenantId: tenant})
+	if err != nil {
+		return nil, "", err
+	}
+	return resp, sessionID, nil
+}
+
+// exposedAgentTools 创建 Agent 的执行器以注册其自身工具（不含全局工具），返回名称匹配 expose.tools 的工具。
+// 对外的工具调用不经过审批，工具策略为 deny 或 confirm 的工具不对外提供
+func (s *AgentUsecase) exposedAgentTools(ctx context.Context, agentConfig *Agent, runtimeConfig *AgentRuntimeConfig) (*tools.ToolManager, error) {
+	patterns := runtimeConfig.Expose.Tools
+	key := strings.Join(patterns, ",")
+	if cached, ok := s.exposedTools.Load(agentConfig.ID); ok {
+		entry := cached.(*exposedTools)
+		if entry.updatedAt.Equal(agentConfig.UpdatedAt) && entry.patterns == key {
+			return entry.tools, nil
+		}
+	}
+	tm := tools.NewToolManager()
+	for _, server := range agentConfig.MCPServers {
+		mcpManager, err := tools.NewMCPToolManager(server.Name, server.Endpoint, tm, tools.TransferToMcpClientType(server.ClientType))
+		if err != nil {
+			return nil, err
+		}
+		mcpManager.DiscoverAndRegisterTools()
+	}
+	agentCtx := agent.NewContext(agent.WithChat(s.chat), agent.WithModel(agentConfig.Model), agent.WithToolManager(tm))
+	if _, err := s.factory.CreateAgentExecutor(ctx, agentConfig, agentCtx); err != nil {
+		return nil, err
+	}
+	policies := runtimeConfig.ToolPolicy.Policies()
+	subset := agentCtx.GetToolManager().Subset(patterns).Filter(func(tool core.Tool) bool {
+		if policy := policies.PolicyFor(tool.Name()); policy != agent.ToolPolicyAuto {
+			s.logger.Warnf("skip exposing tool %s of agent %d: tool policy is %s", tool.Name(), agentConfig.ID, policy)
+			return false
+		}
+		return true
+	})
+	s.exposedTools.Store(agentConfig.ID, &exposedTools{updatedAt: agentConfig.UpdatedAt, patterns: key, tools: subset})
+	return subset, nil
+}
+
+// exposedAgentToolName Agent 对外的工具名，未配置时为 agent_<id>
+func exposedAgentToolName(agentConfig *Agent, cfg ExposeConfig) string {
+	if cfg.ToolName != "" {
+		return cfg.ToolName
+	}
+	return agent.WorkerToolPrefix + strconv.Itoa(agentConfig.ID)
+}
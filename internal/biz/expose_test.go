@@ -0,0 +1,215 @@
+package biz
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+
+	"jas-agent/agent/agent"
+	"jas-agent/agent/llm"
+)
+
+// exposedAgent 按 expose 配置创建的 react Agent
+func exposedAgent(id int, active bool, expose ExposeConfig) *Agent {
+	agentConfig := nativeAgent(id)
+	config, _ := json.Marshal(map[string]any{"tool_call_mode": "native", "summary": map[string]string{"policy": "never"}, "expose": expose})
+	agentConfig.ConfigJSON = string(config)
+	agentConfig.IsActive = active
+	return agentConfig
+}
+
+// newExposeTestUsecase Agent 1 对外提供，2 未开启 expose.mcp，3 已停用，4 以自定义工具名对外提供
+func newExposeTestUsecase(chat llm.Chat, sessions *fakeSessionRepo) *AgentUsecase {
+	agents := &fakeAgentRepo{agents: map[int]*Agent{
+		1: exposedAgent(1, true, ExposeConfig{MCP: true}),
+		2: exposedAgent(2, true, ExposeConfig{}),
+		3: exposedAgent(3, false, ExposeConfig{MCP: true}),
+		4: exposedAgent(4, true, ExposeConfig{MCP: true, ToolName: "orders"}),
+	}}
+	uc := newTestUsecase(chat, agents, newFakeRunRepo())
+	uc.sessions = NewSessionUsecase(sessions, agents, log.DefaultLogger)
+	return uc
+}
+
+func TestListExposedAgents(t *testing.T) {
+	uc := newExposeTestUsecase(&fakeChat{}, newFakeSessionRepo())
+	exposed, err := uc.ListExposedAgents(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, item := range exposed {
+		names = append(names, item.ToolName)
+	}
+	// 未开启 expose.mcp 与已停用的 Agent 不对外提供
+	if got := strings.Join(names, ","); got != "agent_1,orders" {
+		t.Fatalf("exposed tools = %s", got)
+	}
+}
+
+func TestChatExposedRejects(t *testing.T) {
+	tests := []struct {
+		name      string
+		agentID   int
+		sessionID string
+		wantErr   error
+	}{
+		{name: "expose.mcp disabled", agentID: 2, wantErr: ErrAgentNotExposed},
+		{name: "inactive agent", agentID: 3, wantErr: ErrAgentNotExposed},
+		{name: "unknown agent", agentID: 9, wantErr: ErrAgentNotExposed},
+		// 客户端不能自行指定新的会话ID
+		{name: "unknown session", agentID: 1, sessionID: "chosen-by-client", wantErr: ErrSessionNotFound},
+		{name: "session of another agent", agentID: 1, sessionID: "session-4", wantErr: ErrSessionAgentMismatch},
+		// 会话属于当前 Agent，但 Agent 未对外提供
+		{name: "session of a hidden agent", agentID: 2, sessionID: "session-2", wantErr: ErrAgentNotExposed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions := newFakeSessionRepo(&Session{ID: "session-2", AgentID: 2}, &Session{ID: "session-4", AgentID: 4})
+			chat := &fakeChat{respond: func(llm.ChatRequest) (*llm.ChatResponse, error) { return textReply("answer"), nil }}
+			uc := newExposeTestUsecase(chat, sessions)
+
+			resp, sessionID, err := uc.ChatExposed(context.Background(), tt.agentID, "hello", tt.sessionID, "tenant-a")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ChatExposed() error = %v, want %v", err, tt.wantErr)
+			}
+			if resp != nil || sessionID != "" {
+				t.Fatalf("ChatExposed() = %v, %q", resp, sessionID)
+			}
+			if chat.calls() != 0 {
+				t.Fatal("model was called for a rejected request")
+			}
+			if len(sessions.sessions) != 2 {
+				t.Fatalf("sessions = %v, want no session created", sessions.sessions)
+			}
+		})
+	}
+}
+
+func TestChatExposedSessions(t *testing.T) {
+	sessions := newFakeSessionRepo()
+	chat := &fakeChat{respond: func(req llm.ChatRequest) (*llm.ChatResponse, error) {
+		messages := req.Request().Messages
+		return textReply("answer " + messages[len(messages)-1].Content), nil
+	}}
+	uc := newExposeTestUsecase(chat, sessions)
+
+	// 未携带会话时创建属于该 Agent 的新会话并返回其ID
+	resp, sessionID, err := uc.ChatExposed(context.Background(), 1, "first question", "", "tenant-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Response != "answer first question" {
+		t.Fatalf("response = %q", resp.Response)
+	}
+	session, err := sessions.GetSession(context.Background(), sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.AgentID != 1 || session.Title != "first question" {
+		t.Fatalf("session = %+v", session)
+	}
+
+	// 携带返回的会话ID继续对话，复用该会话的历史消息
+	resp, next, err := uc.ChatExposed(context.Background(), 1, "second question", sessionID, "tenant-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != sessionID || resp.Response != "answer second question" {
+		t.Fatalf("ChatExposed() = %q, %q", resp.Response, next)
+	}
+	var history bool
+	for _, message := range chat.requests[len(chat.requests)-1].Request().Messages {
+		history = history || message.Content == "first question"
+	}
+	if !history {
+		t.Fatal("second turn does not contain the history of the session")
+	}
+	if len(sessions.sessions) != 1 {
+		t.Fatalf("sessions = %v, want one session", sessions.sessions)
+	}
+}
+
+// toolboxAgent 创建执行器时注册 lookup、drop_table 与 restart 三个自身工具
+type toolboxAgent struct{}
+
+func (toolboxAgent) Validate(*Agent) error      { return nil }
+func (toolboxAgent) AgentType() agent.AgentType { return "toolbox" }
+func (toolboxAgent) Description() string        { return "toolbox" }
+func (toolboxAgent) Alias() string              { return "" }
+
+func (toolboxAgent) CreateAgentExecutor(_ context.Context, _ *Agent, agentCtx *agent.Context) (*agent.AgentExecutor, error) {
+	for _, name := range []string{"lookup", "drop_table", "restart"} {
+		agentCtx.GetToolManager().RegisterTool(&fakeTool{name: name, handler: func(context.Context, string) (string, error) {
+			return "ok", nil
+		}})
+	}
+	return agent.NewAgentExecutor(agentCtx), nil
+}
+
+// toolboxPolicy 禁止 drop_*，restart 需要审批
+var toolboxPolicy = map[string]any{"rules": []map[string]string{
+	{"pattern": "drop_*", "policy": "deny"},
+	{"pattern": "restart", "policy": "confirm"},
+}}
+
+func TestExposedToolPolicy(t *testing.T) {
+	config, _ := json.Marshal(map[string]any{"expose": ExposeConfig{MCP: true, Tools: []string{"*"}}, "tool_policy": toolboxPolicy})
+	toolbox := &Agent{ID: 5, Name: "toolbox", Framework: "toolbox", ConfigJSON: string(config), IsActive: true}
+	uc := newExposeTestUsecase(&fakeChat{}, newFakeSessionRepo())
+	uc.agentRepo.(*fakeAgentRepo).agents[5] = toolbox
+	uc.factory.RegisterAgent(toolboxAgent{})
+
+	// 对外的工具调用不经过审批，deny 与 confirm 的工具不对外提供
+	exposed, err := uc.ListExposedAgents(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := exposed[len(exposed)-1]
+	if item.Agent.ID != 5 || item.Tools == nil {
+		t.Fatalf("exposed = %+v", item)
+	}
+	var names []string
+	for _, tool := range item.Tools.AvailableTools() {
+		names = append(names, tool.Name())
+	}
+	if strings.Join(names, ",") != "lookup" {
+		t.Fatalf("exposed tools = %v, want lookup only", names)
+	}
+
+	// 调用时按 Agent 当前的工具策略重新检查
+	tests := []struct {
+		agentID int
+		tool    string
+		wantErr error
+	}{
+		{agentID: 5, tool: "lookup"},
+		{agentID: 5, tool: "drop_table", wantErr: agent.ErrToolDenied},
+		{agentID: 5, tool: "restart", wantErr: agent.ErrToolDenied},
+		{agentID: 2, tool: "lookup", wantErr: ErrAgentNotExposed},
+	}
+	for _, tt := range tests {
+		if err := uc.CheckExposedTool(context.Background(), tt.agentID, tt.tool); !errors.Is(err, tt.wantErr) {
+			t.Errorf("CheckExposedTool(%d, %s) error = %v, want %v", tt.agentID, tt.tool, err, tt.wantErr)
+		}
+	}
+}
+
+func TestChatExposedWithoutSessions(t *testing.T) {
+	chat := &fakeChat{respond: func(llm.ChatRequest) (*llm.ChatResponse, error) { return textReply("answer"), nil }}
+	uc := newExposeTestUsecase(chat, newFakeSessionRepo())
+	uc.sessions = nil
+
+	// 未配置会话存储时不能携带会话，不携带时不使用会话
+	if _, _, err := uc.ChatExposed(context.Background(), 1, "hello", "session-1", ""); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("ChatExposed() error = %v, want %v", err, ErrSessionNotFound)
+	}
+	resp, sessionID, err := uc.ChatExposed(context.Background(), 1, "hello", "", "")
+	if err != nil || sessionID != "" || resp.Response != "answer" {
+		t.Fatalf("ChatExposed() = %v, %q, %v", resp, sessionID, err)
+	}
+}
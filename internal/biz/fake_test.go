@@ -0,0 +1,529 @@
+package biz
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/sashabaranov/go-openai"
+
+	"jas-agent/agent/agent"
+	"jas-agent/agent/core"
+	"jas-agent/agent/llm"
+	"jas-agent/agent/memory"
+	"jas-agent/internal/conf"
+)
+
+// fakeChat 测试用模型，按请求生成响应
+type fakeChat struct {
+	mu       sync.Mutex
+	requests []llm.ChatRequest
+	respond  func(req llm.ChatRequest) (*llm.ChatResponse, error)
+}
+
+var _ llm.Chat = (*fakeChat)(nil)
+
+func (c *fakeChat) Completions(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	c.mu.Lock()
+	c.requests = append(c.requests, req)
+	c.mu.Unlock()
+	return c.respond(req)
+}
+
+func (c *fakeChat) CompletionsStream(ctx context.Context, req llm.ChatRequest, onDelta func(llm.ChatDelta) error) (*llm.ChatResponse, error) {
+	return c.Completions(ctx, req)
+}
+
+func (c *fakeChat) calls() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.requests)
+}
+
+// textReply 内容为 content 的助手响应
+func textReply(content string) *llm.ChatResponse {
+	return &llm.ChatResponse{ChatCompletionResponse: openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: content}}},
+	}}
+}
+
+// toolCallReply 以 tool_calls 调用 name 函数的助手响应
+func toolCallReply(id, name, arguments string) *llm.ChatResponse {
+	resp := textReply("")
+	resp.Choices[0].Message.ToolCalls = []openai.ToolCall{{
+		ID:       id,
+		Type:     openai.ToolTypeFunction,
+		Function: openai.FunctionCall{Name: name, Arguments: arguments},
+	}}
+	return resp
+}
+
+// hasToolResult 请求中是否已有工具结果
+func hasToolResult(req llm.ChatRequest) bool {
+	for _, msg := range req.Request().Messages {
+		if msg.Role == openai.ChatMessageRoleTool {
+			return true
+		}
+	}
+	return false
+}
+
+// fakeTool 测试用工具
+type fakeTool struct {
+	name    string
+	handler func(ctx context.Context, input string) (string, error)
+}
+
+func (t *fakeTool) Name() string        { return t.name }
+func (t *fakeTool) Description() string { return "fake tool " + t.name }
+func (t *fakeTool) Input() any          { return nil }
+func (t *fakeTool) Type() core.ToolType { return core.Normal }
+
+func (t *fakeTool) Handler(ctx context.Context, input string) (string, error) {
+	if t.handler == nil {
+		return t.name + " done", nil
+	}
+	return t.handler(ctx, input)
+}
+
+type fakeAgentRepo struct {
+	agents map[int]*Agent
+}
+
+func (r *fakeAgentRepo) CreateAgent(context.Context, *Agent) error { return nil }
+func (r *fakeAgentRepo) UpdateAgent(context.Context, *Agent) error { return nil }
+func (r *fakeAgentRepo) DeleteAgent(context.Context, int) error    { return nil }
+
+func (r *fakeAgentRepo) GetAgent(_ context.Context, id int) (*Agent, error) {
+	agentConfig, ok := r.agents[id]
+	if !ok {
+		return nil, fmt.Errorf("agent %d not found", id)
+	}
+	return agentConfig, nil
+}
+
+func (r *fakeAgentRepo) ListAgents(context.Context) ([]*Agent, error) {
+	agents := make([]*Agent, 0, len(r.agents))
+	for _, agentConfig := range r.agents {
+		agents = append(agents, agentConfig)
+	}
+	sort.Slice(agents, func(i, j int) bool { return agents[i].ID < agents[j].ID })
+	return agents, nil
+}
+
+// fakeSessionRepo 内存中的会话仓库，Memory 预先加载会话已保存的消息
+type fakeSessionRepo struct {
+	mu       sync.Mutex
+	sessions map[string]*Session
+	messages map[string][]core.Message
+}
+
+func newFakeSessionRepo(sessions ...*Session) *fakeSessionRepo {
+	r := &fakeSessionRepo{sessions: map[string]*Session{}, messages: map[string][]core.Message{}}
+	for _, session := range sessions {
+		r.sessions[session.ID] = session
+	}
+	return r
+}
+
+func (r *fakeSessionRepo) CreateSession(_ context.Context, session *Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.sessions[session.ID]; ok {
+		return fmt.Errorf("session %s already exists", session.ID)
+	}
+	copied := *session
+	r.sessions[session.ID] = &copied
+	return nil
+}
+
+func (r *fakeSessionRepo) GetSession(_ context.Context, id string) (*Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, id)
+	}
+	copied := *session
+	copied.MessageCount = len(r.messages[id])
+	return &copied, nil
+}
+
+func (r *fakeSessionRepo) ListSessions(_ context.Context, agentID int) ([]*Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var sessions []*Session
+	for _, session := range r.sessions {
+		if agentID == 0 || session.AgentID == agentID {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (r *fakeSessionRepo) DeleteSession(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sessions, id)
+	delete(r.messages, id)
+	return nil
+}
+
+func (r *fakeSessionRepo) ListMessages(context.Context, string) ([]*SessionMessage, error) {
+	return nil, nil
+}
+
+func (r *fakeSessionRepo) Memory(_ context.Context, sessionID string, persistAll bool) (core.Memory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	mem := memory.NewMemory()
+	mem.AddMessages(r.messages[sessionID])
+	if persistAll {
+		return &fakeSessionMemory{Memory: mem, repo: r, sessionID: sessionID}, nil
+	}
+	return mem, nil
+}
+
+// fakeSessionMemory 与 MySQL 实现一致，persistAll 时每条非系统消息写入会话
+type fakeSessionMemory struct {
+	core.Memory
+	repo      *fakeSessionRepo
+	sessionID string
+}
+
+func (m *fakeSessionMemory) AddMessage(message core.Message) {
+	m.Memory.AddMessage(message)
+	if message.Role != core.MessageRoleSystem {
+		_ = m.repo.AddMessages(context.Background(), m.sessionID, []core.Message{message})
+	}
+}
+
+func (m *fakeSessionMemory) AddMessages(messages []core.Message) {
+	for _, message := range messages {
+		m.AddMessage(message)
+	}
+}
+
+// sessionMessages 会话中保存的消息
+func (r *fakeSessionRepo) sessionMessages(sessionID string) []core.Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]core.Message(nil), r.messages[sessionID]...)
+}
+
+func (r *fakeSessionRepo) AddMessages(_ context.Context, sessionID string, messages []core.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages[sessionID] = append(r.messages[sessionID], messages...)
+	return nil
+}
+
+type fakeUsageRepo struct {
+	mu    sync.Mutex
+	daily map[string]llm.Usage // tenant -> 当天用量
+	loads int
+}
+
+func (r *fakeUsageRepo) AddDailyUsage(_ context.Context, tenant string, _ int, _ time.Time, _ string, usage llm.Usage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.daily[tenant] = r.daily[tenant].Add(usage)
+	return nil
+}
+
+func (r *fakeUsageRepo) GetTenantDailyUsage(_ context.Context, tenant string, _ time.Time) (llm.Usage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.loads++
+	return r.daily[tenant], nil
+}
+
+// fakeRunRepo 内存中的运行记录，同时实现 RunRepo、RunQueue 与 ApprovalRepo，认领语义与 MySQL 实现一致
+type fakeRunRepo struct {
+	mu          sync.Mutex
+	runs        map[string]*Run
+	checkpoints map[string][][]byte // 运行的全部检查点，按保存顺序
+	events      []*RunEvent
+	approvals   map[string]*Approval
+	// noApprovals 模拟未配置数据库，审批不持久化
+	noApprovals bool
+	changed     chan struct{} // 运行状态变化时写入，用于等待后台运行结束
+}
+
+var (
+	_ RunRepo      = (*fakeRunRepo)(nil)
+	_ RunQueue     = (*fakeRunRepo)(nil)
+	_ ApprovalRepo = (*fakeRunRepo)(nil)
+)
+
+func newFakeRunRepo() *fakeRunRepo {
+	return &fakeRunRepo{
+		runs:        map[string]*Run{},
+		checkpoints: map[string][][]byte{},
+		approvals:   map[string]*Approval{},
+		changed:     make(chan struct{}, 100),
+	}
+}
+
+func (r *fakeRunRepo) put(run *Run) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *run
+	r.runs[run.ID] = &stored
+}
+
+func (r *fakeRunRepo) CreateRun(_ context.Context, run *Run) error {
+	run.CreatedAt = time.Now()
+	r.put(run)
+	return nil
+}
+
+func (r *fakeRunRepo) GetRun(_ context.Context, id string) (*Run, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	run, ok := r.runs[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrRunNotFound, id)
+	}
+	result := *run
+	return &result, nil
+}
+
+func (r *fakeRunRepo) SaveCheckpoint(_ context.Context, id string, step int, checkpoint []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	run := r.runs[id]
+	run.CurrentStep, run.Checkpoint = step, checkpoint
+	r.checkpoints[id] = append(r.checkpoints[id], checkpoint)
+	return nil
+}
+
+func (r *fakeRunRepo) UpdateRun(_ context.Context, run *Run) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.runs[run.ID]
+	if !ok || (run.Owner != "" && stored.Owner != run.Owner) {
+		return nil
+	}
+	stored.Status, stored.CurrentStep, stored.Result = run.Status, run.CurrentStep, run.Result
+	stored.FinalResult, stored.Error, stored.ResumeCount = run.FinalResult, run.Error, run.ResumeCount
+	r.notify()
+	return nil
+}
+
+func (r *fakeRunRepo) notify() {
+	select {
+	case r.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (r *fakeRunRepo) ListRuns(_ context.Context, status string) ([]*Run, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var runs []*Run
+	for _, run := range r.runs {
+		if run.Status == status {
+			result := *run
+			runs = append(runs, &result)
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].ID < runs[j].ID })
+	return runs, nil
+}
+
+func (r *fakeRunRepo) ClaimRun(_ context.Context, id, owner string, statuses []string, staleBefore time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	run := r.staleRun(id, statuses, staleBefore)
+	if run == nil {
+		return false, nil
+	}
+	run.Status, run.Owner, run.HeartbeatAt, run.CancelRequested = string(agent.RunningState), owner, time.Now(), false
+	return true, nil
+}
+
+func (r *fakeRunRepo) CancelRun(_ context.Context, id string, statuses []string, staleBefore time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	run := r.staleRun(id, statuses, staleBefore)
+	if run == nil {
+		return false, nil
+	}
+	run.Status = string(agent.CanceledState)
+	r.notify()
+	return true, nil
+}
+
+// staleRun 状态在 statuses 中、且不是由存活实例执行中的运行
+func (r *fakeRunRepo) staleRun(id string, statuses []string, staleBefore time.Time) *Run {
+	run, ok := r.runs[id]
+	if !ok || !slices.Contains(statuses, run.Status) {
+		return nil
+	}
+	if run.Status == string(agent.RunningState) && run.Owner != "" && !run.HeartbeatAt.Before(staleBefore) {
+		return nil
+	}
+	return run
+}
+
+func (r *fakeRunRepo) RequestCancel(_ context.Context, id string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	run, ok := r.runs[id]
+	if !ok || run.Status != string(agent.RunningState) {
+		return false, nil
+	}
+	run.CancelRequested = true
+	return true, nil
+}
+
+func (r *fakeRunRepo) Heartbeat(_ context.Context, id, owner string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	run, ok := r.runs[id]
+	if !ok || run.Owner != owner || run.Status != string(agent.RunningState) {
+		return false, fmt.Errorf("%w: %s", ErrRunNotOwned, id)
+	}
+	run.HeartbeatAt = time.Now()
+	return run.CancelRequested, nil
+}
+
+func (r *fakeRunRepo) AddRunEvent(_ context.Context, event *RunEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	event.Seq = int64(len(r.events) + 1)
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *fakeRunRepo) ListRunEvents(_ context.Context, runID string, afterSeq int64, limit int) ([]*RunEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var events []*RunEvent
+	for _, event := range r.events {
+		if event.RunID == runID && event.Seq > afterSeq && len(events) < limit {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (r *fakeRunRepo) Enqueue(ctx context.Context, run *Run) error {
+	return r.CreateRun(ctx, run)
+}
+
+func (r *fakeRunRepo) Dequeue(_ context.Context, owner string) (*Run, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var next *Run
+	for _, run := range r.runs {
+		if run.Status == RunQueued && (next == nil || run.CreatedAt.Before(next.CreatedAt)) {
+			next = run
+		}
+	}
+	if next == nil {
+		return nil, nil
+	}
+	next.Status, next.Owner, next.HeartbeatAt = string(agent.RunningState), owner, time.Now()
+	result := *next
+	return &result, nil
+}
+
+func (r *fakeRunRepo) CreateApproval(_ context.Context, approval *Approval) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.noApprovals {
+		stored := *approval
+		r.approvals[approval.ID] = &stored
+	}
+	return nil
+}
+
+func (r *fakeRunRepo) GetApproval(_ context.Context, id string) (*Approval, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	approval, ok := r.approvals[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrApprovalNotFound, id)
+	}
+	result := *approval
+	return &result, nil
+}
+
+func (r *fakeRunRepo) ResolveApproval(_ context.Context, runID, id string, decision agent.ApprovalDecision) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	approval, ok := r.approvals[id]
+	if !ok || approval.RunID != runID || approval.Status != ApprovalPending {
+		return false, nil
+	}
+	approval.Status, approval.Decision, approval.ResolvedAt = ApprovalResolved, &decision, time.Now()
+	return true, nil
+}
+
+func (r *fakeRunRepo) ExpireApprovals(_ context.Context, runID string, ids ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, approval := range r.approvals {
+		if approval.RunID == runID && approval.Status == ApprovalPending && (len(ids) == 0 || slices.Contains(ids, approval.ID)) {
+			approval.Status = ApprovalExpired
+		}
+	}
+	return nil
+}
+
+func (r *fakeRunRepo) ListApprovals(_ context.Context, runID, status string) ([]*Approval, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var approvals []*Approval
+	for _, approval := range r.approvals {
+		if approval.RunID == runID && approval.Status == status {
+			result := *approval
+			approvals = append(approvals, &result)
+		}
+	}
+	sort.Slice(approvals, func(i, j int) bool { return approvals[i].CreatedAt.Before(approvals[j].CreatedAt) })
+	return approvals, nil
+}
+
+// waitRun 等待运行进入非执行中的状态
+func (r *fakeRunRepo) waitRun(t *testing.T, id string) *Run {
+	t.Helper()
+	deadline := time.After(5 * time.Second)
+	for {
+		run, err := r.GetRun(context.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !runInProgress(run.Status) {
+			return run
+		}
+		select {
+		case <-r.changed:
+		case <-time.After(10 * time.Millisecond):
+		case <-deadline:
+			t.Fatalf("run %s is still %s", id, run.Status)
+		}
+	}
+}
+
+// newTestUsecase 使用内存中的仓库与 fakeChat 创建 AgentUsecase，runs 可在多个实例间共享
+func newTestUsecase(chat llm.Chat, agents *fakeAgentRepo, runs *fakeRunRepo) *AgentUsecase {
+	usage := &fakeUsageRepo{daily: map[string]llm.Usage{}}
+	bootstrap := &conf.Bootstrap{}
+	return NewAgentUsecase(chat, agents, NewAgentFactory(), nil, usage, NewTenantBudgets(bootstrap, usage, log.DefaultLogger),
+		runs, runs, runs, NewRunWebhook(bootstrap, log.DefaultLogger), llm.Pricing{}, nil, nil, log.DefaultLogger)
+}
+
+// nativeAgent 使用 native 工具调用的 react Agent 配置
+func nativeAgent(id int) *Agent {
+	config, _ := json.Marshal(map[string]any{"tool_call_mode": "native"})
+	return &Agent{ID: id, Name: fmt.Sprintf("agent-%d", id), Framework: string(agent.ReactAgentType), ConfigJSON: string(config)}
+}
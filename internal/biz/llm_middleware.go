@@ -0,0 +1,67 @@
+package biz
+
+import (
+	"sync"
+
+	"jas-agent/agent/llm"
+)
+
+// defaultLLMRetryAttempts 未配置重试时的默认最大尝试次数
+const defaultLLMRetryAttempts = 3
+
+// llmMiddlewares 按 Agent 配置组装模型调用中间件。
+// 熔断器按服务商在所有 Agent 间共享，令牌桶按 Agent 在多次运行间共享
+type llmMiddlewares struct {
+	breakers *llm.BreakerGroup
+	limiters sync.Map // agentID -> *agentLimiter
+}
+
+type agentLimiter struct {
+	config LLMRateLimitConfig
+	bucket *llm.TokenBucket
+}
+
+func newLLMMiddlewares() *llmMiddlewares {
+	return &llmMiddlewares{breakers: llm.NewBreakerGroup()}
+}
+
+// wrap 组装中间件：降级 → 重试 → 限流 → 用量统计 → 熔断 → 服务商。
+// 每次重试都经过限流与熔断，重试耗尽后再使用备用模型；
+// 用量统计位于熔断之外，使熔断器仍能直接从服务商注册表解析服务商
+func (m *llmMiddlewares) wrap(chat llm.Chat, agentID int, config LLMMiddlewareConfig, usage llm.Middleware) llm.Chat {
+	var middlewares []llm.Middleware
+	if config.FallbackModel != "" {
+		middlewares = append(middlewares, llm.Fallback(config.FallbackModel))
+	}
+	retry := llm.RetryConfig{MaxAttempts: defaultLLMRetryAttempts}
+	if config.Retry != nil {
+		retry = llm.RetryConfig{
+			MaxAttempts: config.Retry.MaxAttempts,
+			BaseDelay:   config.Retry.BaseDelay.Std(),
+			MaxDelay:    config.Retry.MaxDelay.Std(),
+		}
+	}
+	if retry.MaxAttempts > 1 {
+		middlewares = append(middlewares, llm.Retry(retry))
+	}
+	if config.RateLimit != nil && config.RateLimit.RPS > 0 {
+		middlewares = append(middlewares, llm.RateLimit(m.limiter(agentID, *config.RateLimit)))
+	}
+	middlewares = append(middlewares, usage)
+	if config.CircuitBreaker {
+		middlewares = append(middlewares, llm.CircuitBreaker(m.breakers))
+	}
+	return llm.Chain(chat, middlewares...)
+}
+
+// limiter 获取 Agent 的令牌桶，限流配置变化时重新创建
+func (m *llmMiddlewares) limiter(agentID int, config LLMRateLimitConfig) *llm.TokenBucket {
+	if v, ok := m.limiters.Load(agentID); ok {
+		if limiter := v.(*agentLimiter); limiter.config == config {
+			return limiter.bucket
+		}
+	}
+	limiter := &agentLimiter{config: config, bucket: llm.NewTokenBucket(config.RPS, config.Burst)}
+	m.limiters.Store(agentID, limiter)
+	return limiter.bucket
+}
@@ -0,0 +1,463 @@
+package biz
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"jas-agent/agent/agent"
+	pb "jas-agent/api/agent/service/v1"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+var (
+	// ErrRunNotFound 运行不存在
+	ErrRunNotFound = errors.New("run not found")
+	// ErrRunActive 运行正在执行中
+	ErrRunActive = errors.New("run is active")
+	// ErrRunFinished 运行已完成
+	ErrRunFinished = errors.New("run already finished")
+	// ErrRunQueued 运行仍在排队中
+	ErrRunQueued = errors.New("run is queued")
+	// ErrRunNotOwned 运行已不由本实例执行，如心跳超时后被其他实例接管
+	ErrRunNotOwned = errors.New("run is not owned by this instance")
+)
+
+// RunQueued 异步运行已提交、尚未被 worker 领取
+const RunQueued = "Queued"
+
+const (
+	// runHeartbeatInterval 执行中的运行刷新心跳的间隔
+	runHeartbeatInterval = 10 * time.Second
+	// runLease 心跳超过该时长未刷新的 Running 运行视为执行实例已退出，可由其他实例接管
+	runLease = 30 * time.Second
+)
+
+// resumableStatuses 可以手动恢复的运行状态，Running 只有在原执行实例的心跳过期后才能恢复
+var resumableStatuses = []string{
+	string(agent.RunningState),
+	string(agent.ErrorState),
+	string(agent.TimeoutState),
+	string(agent.CanceledState),
+	string(agent.BudgetExceededState),
+}
+
+// cancelableStatuses 可以直接置为 Canceled 的运行状态，Running 只有在原执行实例的心跳过期后才能直接取消，
+// 否则由执行实例在下一次心跳时发现取消请求后中断执行
+var cancelableStatuses = []string{
+	RunQueued,
+	string(agent.RunningState),
+	string(agent.ErrorState),
+	string(agent.TimeoutState),
+	string(agent.BudgetExceededState),
+}
+
+// cancelRunAttempts 取消运行时状态被并发修改（如刚被 worker 领取）后重试的次数
+const cancelRunAttempts = 3
+
+// Run 一次 Agent 运行，执行器每一步结束后保存检查点，进程重启后可从检查点继续
+type Run struct {
+	ID          string
+	AgentID     int
+	SessionID   string
+	Query       string
+	Request     []byte // 发起运行的 ChatRequest（protojson），恢复时用于重建执行器
+	Status      string // Queued 或执行器状态：Running、Finish、Error、Timeout、Canceled、BudgetExceeded
+	CurrentStep int
+	Checkpoint  []byte // 最近一次检查点（agent.Checkpoint 的 JSON）
+	Result      string
+	FinalResult *agent.FinalResult
+	Error       string
+	ResumeCount int
+	WebhookURL  string // 运行结束后的回调地址
+	Owner       string // 执行该运行的实例
+	HeartbeatAt time.Time
+	// CancelRequested 已请求取消、等待执行实例中断的运行
+	CancelRequested bool
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	// PendingApprovals 等待审批的工具调用，只在查询时填充
+	PendingApprovals []*Approval
+}
+
+// RunRepo 定义运行记录数据访问接口
+type RunRepo interface {
+	CreateRun(ctx context.Context, run *Run) error
+	GetRun(ctx context.Context, id string) (*Run, error)
+	// SaveCheckpoint 保存检查点与对应的步骤数
+	SaveCheckpoint(ctx context.Context, id string, step int, checkpoint []byte) error
+	// UpdateRun 更新状态、结果与恢复次数，run.Owner 不为空时只更新仍由该实例执行的运行
+	UpdateRun(ctx context.Context, run *Run) error
+	// ListRuns 按状态列出运行
+	ListRuns(ctx context.Context, status string) ([]*Run, error)
+	// ClaimRun 由 owner 认领运行并将其置为 Running：运行状态需在 statuses 中，
+	// 状态为 Running 时原执行实例的心跳需早于 staleBefore。多个实例同时认领时只有一个成功
+	ClaimRun(ctx context.Context, id, owner string, statuses []string, staleBefore time.Time) (bool, error)
+	// Heartbeat 刷新 owner 正在执行的运行的心跳并返回运行是否已被请求取消，
+	// 运行已结束或被其他实例接管时返回 ErrRunNotOwned
+	Heartbeat(ctx context.Context, id, owner string) (bool, error)
+	// CancelRun 将运行置为 Canceled：运行状态需在 statuses 中，状态为 Running 时原执行实例的心跳需早于 staleBefore。
+	// 返回是否取消成功
+	CancelRun(ctx context.Context, id string, statuses []string, staleBefore time.Time) (bool, error)
+	// RequestCancel 为执行中的运行设置取消请求，由执行实例在心跳时发现并中断执行。运行不在执行中时返回 false
+	RequestCancel(ctx context.Context, id string) (bool, error)
+	// AddRunEvent 追加运行事件并回填序号
+	AddRunEvent(ctx context.Context, event *RunEvent) error
+	// ListRunEvents 按序号升序列出 afterSeq 之后的事件
+	ListRunEvents(ctx context.Context, runID string, afterSeq int64, limit int) ([]*RunEvent, error)
+}
+
+// newInstanceID 生成本实例的标识：主机名加随机后缀，同一主机上的多个进程互不相同
+func newInstanceID() string {
+	host, _ := os.Hostname()
+	return host + "-" + uuid.NewString()[:8]
+}
+
+// activeRun 本进程中正在执行的运行
+type activeRun struct {
+	run    *Run
+	cancel context.CancelFunc
+	done   chan struct{} // 运行结束时关闭，停止刷新心跳
+	lost   atomic.Bool   // 运行已被其他实例接管，本地执行结果不再写入
+}
+
+// trackRun 登记本实例正在执行的运行，持久化的运行在结束前定期刷新心跳，
+// 心跳失败说明运行已被其他实例接管，运行被请求取消时同样中断本地执行。运行已在本实例执行时返回 false
+func (s *AgentUsecase) trackRun(run *Run, cancel context.CancelFunc) bool {
+	active := &activeRun{run: run, cancel: cancel, done: make(chan struct{})}
+	if _, loaded := s.runs.LoadOrStore(run.ID, active); loaded {
+		return false
+	}
+	if run.Owner != "" {
+		go s.heartbeat(active)
+	}
+	return true
+}
+
+// heartbeat 定期刷新运行的心跳，直到运行结束、被其他实例接管或被请求取消
+func (s *AgentUsecase) heartbeat(active *activeRun) {
+	ticker := time.NewTicker(s.heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-active.done:
+			return
+		case <-ticker.C:
+		}
+		canceled, err := s.runRepo.Heartbeat(context.Background(), active.run.ID, active.run.Owner)
+		if canceled {
+			s.logger.Infof("run %s was canceled, stop executing", active.run.ID)
+			active.cancel()
+			return
+		}
+		if errors.Is(err, ErrRunNotOwned) {
+			select {
+			case <-active.done:
+				return
+			default:
+			}
+			s.logger.Warnf("run %s was taken over by another instance, stop executing", active.run.ID)
+			active.lost.Store(true)
+			active.cancel()
+			return
+		}
+		if err != nil {
+			s.logger.Warnf("heartbeat run %s: %v", active.run.ID, err)
+		}
+	}
+}
+
+// startRun 创建运行记录并登记为执行中，执行器每一步结束后保存检查点。
+// 运行记录写入失败时本次对话照常执行，只是无法恢复
+func (s *AgentUsecase) startRun(ctx context.Context, req *pb.ChatRequest, executor *agent.AgentExecutor, cancel context.CancelFunc) *Run {
+	run := &Run{
+		ID:          uuid.NewString(),
+		AgentID:     int(req.AgentId),
+		SessionID:   req.SessionId,
+		Query:       req.Query,
+		Status:      string(agent.RunningState),
+		Owner:       s.instanceID,
+		HeartbeatAt: time.Now(),
+	}
+	request, err := protojson.Marshal(req)
+	if err == nil {
+		run.Request = request
+		err = s.runRepo.CreateRun(ctx, run)
+	}
+	if err != nil {
+		s.logger.Warnf("create run for agent %d: %v", run.AgentID, err)
+		// 运行记录不存在，无需刷新心跳
+		run.Owner = ""
+	} else {
+		executor.SetCheckpointer(s.runCheckpointer(run.ID))
+	}
+	executor.SetApprover(s.runApprover(run.ID))
+	s.trackRun(run, cancel)
+	return run
+}
+
+// runCheckpointer 将检查点写入运行记录
+func (s *AgentUsecase) runCheckpointer(runID string) agent.Checkpointer {
+	return agent.CheckpointerFunc(func(ctx context.Context, checkpoint *agent.Checkpoint) error {
+		data, err := json.Marshal(checkpoint)
+		if err != nil {
+			return fmt.Errorf("marshal checkpoint: %w", err)
+		}
+		return s.runRepo.SaveCheckpoint(ctx, runID, checkpoint.CurrentStep, data)
+	})
+}
+
+// finishRun 记录运行结果并注销执行中的运行
+func (s *AgentUsecase) finishRun(ctx context.Context, run *Run, executor *agent.AgentExecutor, result string) {
+	run.Status = string(executor.GetState())
+	run.CurrentStep = executor.GetCurrentStep()
+	run.Result = result
+	run.FinalResult = executor.GetFinalResult()
+	s.saveSessionTurn(ctx, run)
+	s.completeRun(ctx, run)
+}
+
+// saveSessionTurn 会话只保存每轮问题与最终回答时，在运行完成后写入本轮对话
+func (s *AgentUsecase) saveSessionTurn(ctx context.Context, run *Run) {
+	if run.SessionID == "" || run.Status != string(agent.FinishState) {
+		return
+	}
+	agentConfig, err := s.agentRepo.GetAgent(ctx, run.AgentID)
+	if err != nil {
+		s.logger.Warnf("save session %s turn: %v", run.SessionID, err)
+		return
+	}
+	runtimeConfig, err := ParseAgentRuntimeConfig(agentConfig.ConfigJSON)
+	if err != nil || runtimeConfig.Memory.persistAll() {
+		return
+	}
+	answer := run.Result
+	if run.FinalResult != nil && run.FinalResult.Answer != "" {
+		answer = run.FinalResult.Answer
+	}
+	if err := s.sessions.SaveTurn(context.WithoutCancel(ctx), run.SessionID, run.Query, answer); err != nil {
+		s.logger.Warnf("save session %s turn: %v", run.SessionID, err)
+	}
+}
+
+// failRun 记录无法执行的运行
+func (s *AgentUsecase) failRun(ctx context.Context, run *Run, err error) {
+	run.Status = string(agent.ErrorState)
+	run.Error = err.Error()
+	s.completeRun(ctx, run)
+}
+
+// completeRun 注销执行中的运行，写入结束事件与最终状态，并触发完成回调。
+// 结束事件先于状态写入，订阅方看到结束状态时即可拉取到全部事件
+func (s *AgentUsecase) completeRun(ctx context.Context, run *Run) {
+	if v, ok := s.runs.LoadAndDelete(run.ID); ok {
+		active := v.(*activeRun)
+		close(active.done)
+		// 运行已由其他实例接管，本地被中断的结果不能覆盖接管后的执行
+		if active.lost.Load() {
+			return
+		}
+	}
+	// 运行被取消时也需要记录最终状态
+	ctx = context.WithoutCancel(ctx)
+	if run.Status == string(agent.FinishState) {
+		s.addRunEvent(ctx, run.ID, RunEventFinal, run.Result, run.CurrentStep)
+	}
+	s.addRunEvent(ctx, run.ID, RunEventStatus, run.Status, run.CurrentStep)
+	if err := s.runRepo.UpdateRun(ctx, run); err != nil {
+		s.logger.Warnf("update run %s: %v", run.ID, err)
+	}
+	// 状态在事件之后写入，需再次唤醒订阅方
+	s.runEvents.notify()
+	if run.WebhookURL != "" {
+		s.webhook.Notify(run)
+	}
+}
+
+// GetRun 获取运行
+func (s *AgentUsecase) GetRun(ctx context.Context, id string) (*Run, error) {
+	run, err := s.runRepo.GetRun(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	run.PendingApprovals, err = s.approvalRepo.ListApprovals(ctx, id, ApprovalPending)
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// ResumeRun 从最近的检查点在后台继续执行运行
+func (s *AgentUsecase) ResumeRun(ctx context.Context, id string) (*Run, error) {
+	if _, ok := s.runs.Load(id); ok {
+		return nil, fmt.Errorf("%w: %s", ErrRunActive, id)
+	}
+	run, err := s.runRepo.GetRun(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	switch run.Status {
+	case string(agent.FinishState):
+		return nil, fmt.Errorf("%w: %s", ErrRunFinished, id)
+	case RunQueued:
+		return nil, fmt.Errorf("%w: %s", ErrRunQueued, id)
+	}
+	if err := s.resumeRun(ctx, run, resumableStatuses); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// CancelRun 取消运行：本实例执行的运行直接中断；其他实例执行的运行设置取消请求，由执行实例在下一次心跳时中断；
+// 排队中、已中断或执行实例已退出的运行直接置为 Canceled，不再被 worker 领取或自动恢复
+func (s *AgentUsecase) CancelRun(ctx context.Context, id string) (*Run, error) {
+	if v, ok := s.runs.Load(id); ok {
+		active := v.(*activeRun)
+		active.cancel()
+		result := *active.run
+		result.Status = string(agent.CanceledState)
+		return &result, nil
+	}
+	for attempt := 0; attempt < cancelRunAttempts; attempt++ {
+		run, err := s.runRepo.GetRun(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		switch agent.State(run.Status) {
+		case agent.FinishState:
+			return nil, fmt.Errorf("%w: %s", ErrRunFinished, id)
+		case agent.CanceledState:
+			return run, nil
+		}
+		canceled, err := s.runRepo.CancelRun(ctx, id, cancelableStatuses, time.Now().Add(-runLease))
+		if err != nil {
+			return nil, err
+		}
+		if canceled {
+			run.Status = string(agent.CanceledState)
+			s.addRunEvent(ctx, run.ID, RunEventStatus, run.Status, run.CurrentStep)
+			return run, nil
+		}
+		// 运行由其他实例执行中（包括刚被 worker 领取的运行）
+		requested, err := s.runRepo.RequestCancel(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if requested {
+			run.Status = string(agent.CanceledState)
+			run.CancelRequested = true
+			return run, nil
+		}
+		// 两次条件更新之间运行状态发生变化，重新读取后再试
+	}
+	return nil, fmt.Errorf("%w: %s", ErrRunActive, id)
+}
+
+// ResumeInterruptedRuns 接管因执行实例退出而中断的运行：状态仍为 Running、心跳已过期且不在本进程中执行。
+// 在服务启动后以及 worker 运行期间定期调用，每个运行只会被一个实例认领
+func (s *AgentUsecase) ResumeInterruptedRuns(ctx context.Context) error {
+	runs, err := s.runRepo.ListRuns(ctx, string(agent.RunningState))
+	if err != nil {
+		return err
+	}
+	staleBefore := time.Now().Add(-runLease)
+	for _, run := range runs {
+		if _, ok := s.runs.Load(run.ID); ok {
+			continue
+		}
+		// 执行实例仍在刷新心跳
+		if run.Owner != "" && run.HeartbeatAt.After(staleBefore) {
+			continue
+		}
+		// 执行实例在发现取消请求前退出，运行不再恢复
+		if run.CancelRequested {
+			canceled, err := s.runRepo.CancelRun(ctx, run.ID, []string{run.Status}, staleBefore)
+			if err != nil {
+				s.logger.Errorf("cancel run %s: %v", run.ID, err)
+			}
+			if canceled {
+				s.addRunEvent(ctx, run.ID, RunEventStatus, string(agent.CanceledState), run.CurrentStep)
+			}
+			continue
+		}
+		err := s.resumeRun(ctx, run, []string{string(agent.RunningState)})
+		if errors.Is(err, ErrRunActive) {
+			continue
+		}
+		if err != nil {
+			s.logger.Errorf("resume run %s: %v", run.ID, err)
+			continue
+		}
+		s.logger.Infof("Resumed run %s of agent %d from step %d", run.ID, run.AgentID, run.CurrentStep)
+	}
+	return nil
+}
+
+// resumeRun 认领运行后重建执行器、恢复检查点并在后台继续执行，没有检查点时重新执行。
+// 运行状态不在 statuses 中或仍由其他实例执行时返回 ErrRunActive
+func (s *AgentUsecase) resumeRun(ctx context.Context, run *Run, statuses []string) error {
+	req := &pb.ChatRequest{}
+	if err := protojson.Unmarshal(run.Request, req); err != nil {
+		return fmt.Errorf("parse run %s request: %w", run.ID, err)
+	}
+	var checkpoint *agent.Checkpoint
+	if len(run.Checkpoint) > 0 {
+		checkpoint = &agent.Checkpoint{}
+		if err := json.Unmarshal(run.Checkpoint, checkpoint); err != nil {
+			return fmt.Errorf("parse run %s checkpoint: %w", run.ID, err)
+		}
+	}
+	claimed, err := s.runRepo.ClaimRun(ctx, run.ID, s.instanceID, statuses, time.Now().Add(-runLease))
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return fmt.Errorf("%w: %s", ErrRunActive, run.ID)
+	}
+	run.Owner = s.instanceID
+	// 中断前等待中的审批随之失效，恢复后重新执行该步骤时再次请求审批
+	if err := s.approvalRepo.ExpireApprovals(ctx, run.ID); err != nil {
+		s.logger.Warnf("expire approvals of run %s: %v", run.ID, err)
+	}
+	// 后台运行不依赖发起恢复的请求，只能通过 CancelRun 取消
+	ctx, cancel := context.WithCancel(context.Background())
+	if !s.trackRun(run, cancel) {
+		cancel()
+		return fmt.Errorf("%w: %s", ErrRunActive, run.ID)
+	}
+	executor, err := s.createExecutor(ctx, req, s.runEventSender(run.ID))
+	if err == nil && checkpoint != nil {
+		err = executor.Restore(checkpoint)
+	}
+	if err != nil {
+		cancel()
+		s.failRun(context.Background(), run, err)
+		return err
+	}
+	executor.SetCheckpointer(s.runCheckpointer(run.ID))
+	executor.SetApprover(s.runApprover(run.ID))
+	run.Status = string(agent.RunningState)
+	run.Error = ""
+	run.ResumeCount++
+	if err := s.runRepo.UpdateRun(ctx, run); err != nil {
+		s.logger.Warnf("update run %s: %v", run.ID, err)
+	}
+	s.addRunEvent(ctx, run.ID, RunEventStatus, run.Status, run.CurrentStep)
+	go func() {
+		defer cancel()
+		var result string
+		if checkpoint != nil {
+			result = executor.Resume(ctx)
+		} else {
+			result = executor.Run(ctx, run.Query)
+		}
+		s.finishRun(ctx, run, executor, result)
+	}()
+	return nil
+}
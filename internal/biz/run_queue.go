@@ -0,0 +1,226 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"jas-agent/agent/agent"
+	"jas-agent/agent/core"
+	pb "jas-agent/api/agent/service/v1"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	// RunEventStatus 运行状态变化，内容为新状态
+	RunEventStatus = "STATUS"
+	// RunEventFinal 运行完成，内容为运行结果
+	RunEventFinal = "FINAL"
+
+	defaultRunEventLimit = 100
+	maxRunEventLimit     = 1000
+	// runSubscribePollInterval 订阅方在没有本进程事件通知时重新查询的间隔，用于感知其他实例写入的事件
+	runSubscribePollInterval = time.Second
+)
+
+// RunQueue 异步运行队列，默认实现基于 MySQL 的 agent_runs 表，可替换为其他消息队列
+type RunQueue interface {
+	// Enqueue 保存状态为 Queued 的运行
+	Enqueue(ctx context.Context, run *Run) error
+	// Dequeue 以 owner 领取最早提交的排队运行并将其标记为 Running，队列为空时返回 nil。
+	// 多个 worker（包括其他实例）同时领取时每个运行只会被领取一次
+	Dequeue(ctx context.Context, owner string) (*Run, error)
+}
+
+// RunEvent 运行过程中的事件，用于轮询或订阅运行进度
+type RunEvent struct {
+	Seq       int64
+	RunID     string
+	Type      string // THINKING、ACTION、OBSERVATION、SUMMARY、METADATA、STATUS、FINAL
+	Content   string
+	Step      int
+	CreatedAt time.Time
+}
+
+// runEventNotifier 在本进程写入新事件时唤醒所有订阅方，订阅方被唤醒后重新查询事件
+type runEventNotifier struct {
+	mu sync.Mutex
+	ch chan struct{}
+}
+
+func (n *runEventNotifier) wait() <-chan struct{} {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.ch == nil {
+		n.ch = make(chan struct{})
+	}
+	return n.ch
+}
+
+func (n *runEventNotifier) notify() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.ch != nil {
+		close(n.ch)
+		n.ch = nil
+	}
+}
+
+// SubmitRun 提交异步运行，运行由后台 worker 执行，结束后回调 webhookURL（可为空）
+func (s *AgentUsecase) SubmitRun(ctx context.Context, req *pb.ChatRequest, webhookURL string) (*Run, error) {
+	if webhookURL != "" {
+		if err := s.webhook.Validate(webhookURL); err != nil {
+			return nil, err
+		}
+	}
+	// 提交时校验 Agent 配置，避免排队后才发现无法执行
+	agentConfig, err := s.agentRepo.GetAgent(ctx, int(req.AgentId))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load agent config: %w", err)
+	}
+	if _, err := ParseAgentRuntimeConfig(agentConfig.ConfigJSON); err != nil {
+		return nil, err
+	}
+	request, err := protojson.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshal run request: %w", err)
+	}
+	run := &Run{
+		ID:         uuid.NewString(),
+		AgentID:    int(req.AgentId),
+		SessionID:  req.SessionId,
+		Query:      req.Query,
+		Request:    request,
+		Status:     RunQueued,
+		WebhookURL: webhookURL,
+	}
+	if err := s.runQueue.Enqueue(ctx, run); err != nil {
+		return nil, err
+	}
+	s.addRunEvent(ctx, run.ID, RunEventStatus, run.Status, 0)
+	// 唤醒空闲的 worker，不必等到下一次轮询
+	select {
+	case s.queued <- struct{}{}:
+	default:
+	}
+	return run, nil
+}
+
+// executeRun 执行 worker 领取的运行，运行结束后返回。
+// 运行不随 worker 停止而取消，进程退出时仍为 Running 的运行在下次启动时从检查点恢复
+func (s *AgentUsecase) executeRun(run *Run) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if !s.trackRun(run, cancel) {
+		return
+	}
+	req := &pb.ChatRequest{}
+	if err := protojson.Unmarshal(run.Request, req); err != nil {
+		s.failRun(ctx, run, fmt.Errorf("parse run %s request: %w", run.ID, err))
+		return
+	}
+	executor, err := s.createExecutor(ctx, req, s.runEventSender(run.ID))
+	if err != nil {
+		s.failRun(ctx, run, err)
+		return
+	}
+	executor.SetCheckpointer(s.runCheckpointer(run.ID))
+	executor.SetApprover(s.runApprover(run.ID))
+	s.addRunEvent(ctx, run.ID, RunEventStatus, run.Status, 0)
+	result := executor.Run(ctx, run.Query)
+	s.finishRun(ctx, run, executor, result)
+}
+
+// runEventSender 将后台运行的执行过程写为运行事件，流式片段不记录，审批请求由审批方记录
+func (s *AgentUsecase) runEventSender(runID string) func(ctx context.Context, msg core.Message) error {
+	var step atomic.Int32
+	return func(ctx context.Context, msg core.Message) error {
+		if msg.Kind == core.MessageKindDelta || msg.Kind == core.MessageKindApproval {
+			return nil
+		}
+		current := step.Load()
+		// 嵌套运行的消息记在主运行的当前步骤下
+		if msg.Kind == "" && msg.Source == "" {
+			current = step.Add(1)
+		}
+		msgType, content := s.parseMessage(msg)
+		s.addRunEvent(ctx, runID, msgType.String(), content, int(current))
+		return nil
+	}
+}
+
+// addRunEvent 写入运行事件并唤醒订阅方，失败时只记录日志
+func (s *AgentUsecase) addRunEvent(ctx context.Context, runID, eventType, content string, step int) {
+	event := &RunEvent{RunID: runID, Type: eventType, Content: content, Step: step}
+	if err := s.runRepo.AddRunEvent(context.WithoutCancel(ctx), event); err != nil {
+		s.logger.Warnf("add run %s event: %v", runID, err)
+	}
+	s.runEvents.notify()
+}
+
+// ListRunEvents 获取运行及其 afterSeq 之后的事件
+func (s *AgentUsecase) ListRunEvents(ctx context.Context, id string, afterSeq int64, limit int) (*Run, []*RunEvent, error) {
+	run, err := s.runRepo.GetRun(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	events, err := s.runRepo.ListRunEvents(ctx, id, afterSeq, runEventLimit(limit))
+	if err != nil {
+		return nil, nil, err
+	}
+	return run, events, nil
+}
+
+// SubscribeRun 依次推送 afterSeq 之后的事件并等待新事件，运行结束且事件推送完毕后返回
+func (s *AgentUsecase) SubscribeRun(ctx context.Context, id string, afterSeq int64, send func(*RunEvent) error) error {
+	for {
+		wait := s.runEvents.wait()
+		// 先读状态再拉取事件：结束事件先于结束状态写入，读到结束状态时事件已全部可见
+		run, err := s.runRepo.GetRun(ctx, id)
+		if err != nil {
+			return err
+		}
+		for {
+			events, err := s.runRepo.ListRunEvents(ctx, id, afterSeq, defaultRunEventLimit)
+			if err != nil {
+				return err
+			}
+			for _, event := range events {
+				if err := send(event); err != nil {
+					return err
+				}
+				afterSeq = event.Seq
+			}
+			if len(events) < defaultRunEventLimit {
+				break
+			}
+		}
+		if !runInProgress(run.Status) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wait:
+		case <-time.After(runSubscribePollInterval):
+		}
+	}
+}
+
+func runInProgress(status string) bool {
+	return status == RunQueued || status == string(agent.RunningState)
+}
+
+func runEventLimit(limit int) int {
+	if limit <= 0 {
+		return defaultRunEventLimit
+	}
+	if limit > maxRunEventLimit {
+		return maxRunEventLimit
+	}
+	return limit
+}
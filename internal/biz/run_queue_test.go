@@ -0,0 +1,209 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+
+	"jas-agent/agent/agent"
+	"jas-agent/agent/llm"
+	pb "jas-agent/api/agent/service/v1"
+	"jas-agent/internal/conf"
+)
+
+// waitingChat 收到请求后等待上下文结束，用于模拟执行中的运行
+type waitingChat struct {
+	started chan struct{}
+}
+
+func (c *waitingChat) Completions(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	select {
+	case c.started <- struct{}{}:
+	default:
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (c *waitingChat) CompletionsStream(ctx context.Context, req llm.ChatRequest, onDelta func(llm.ChatDelta) error) (*llm.ChatResponse, error) {
+	return c.Completions(ctx, req)
+}
+
+func TestRunQueueClaim(t *testing.T) {
+	agents := &fakeAgentRepo{agents: map[int]*Agent{1: nativeAgent(1)}}
+	runs := newFakeRunRepo()
+	bootstrap := &conf.Bootstrap{Server: &conf.Server{Worker: &conf.Server_Worker{Concurrency: 3, PollInterval: "10ms"}}}
+
+	// 两个实例的 worker 同时消费同一队列
+	var usecases []*AgentUsecase
+	for i := 0; i < 2; i++ {
+		uc := newTestUsecase(&fakeChat{respond: lookupThenAnswer}, agents, runs)
+		pool := NewRunWorkerPool(bootstrap, uc, log.DefaultLogger)
+		if err := pool.Start(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = pool.Stop(context.Background()) })
+		usecases = append(usecases, uc)
+	}
+	var ids []string
+	for i := 0; i < 10; i++ {
+		run, err := usecases[i%2].SubmitRun(context.Background(), &pb.ChatRequest{AgentId: 1, Query: fmt.Sprintf("query %d", i)}, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if run.Status != RunQueued {
+			t.Fatalf("submitted run is %s, want %s", run.Status, RunQueued)
+		}
+		ids = append(ids, run.ID)
+	}
+
+	owners := map[string]bool{}
+	for _, id := range ids {
+		run := runs.waitRun(t, id)
+		if run.Status != string(agent.FinishState) || run.Result != "the answer is 42" {
+			t.Fatalf("run %s = %s %q", id, run.Status, run.Result)
+		}
+		owners[run.Owner] = true
+		// 每个运行只被领取并执行一次
+		started := 0
+		events, _ := runs.ListRunEvents(context.Background(), id, 0, maxRunEventLimit)
+		for _, event := range events {
+			if event.Type == RunEventStatus && event.Content == string(agent.RunningState) {
+				started++
+			}
+		}
+		if started != 1 {
+			t.Fatalf("run %s was executed %d times", id, started)
+		}
+	}
+	for owner := range owners {
+		if owner != usecases[0].instanceID && owner != usecases[1].instanceID {
+			t.Fatalf("run owned by unknown instance %q", owner)
+		}
+	}
+}
+
+func TestCancelRun(t *testing.T) {
+	agents := &fakeAgentRepo{agents: map[int]*Agent{1: nativeAgent(1)}}
+	stale := time.Now().Add(-2 * runLease)
+
+	tests := []struct {
+		name       string
+		run        *Run
+		wantStatus string // 取消后运行记录的状态
+		wantErr    error
+	}{
+		{
+			name:       "queued",
+			run:        &Run{ID: "queued", AgentID: 1, Status: RunQueued},
+			wantStatus: string(agent.CanceledState),
+		},
+		{
+			name:       "interrupted",
+			run:        &Run{ID: "interrupted", AgentID: 1, Status: string(agent.TimeoutState)},
+			wantStatus: string(agent.CanceledState),
+		},
+		{
+			name:       "owner gone",
+			run:        &Run{ID: "owner-gone", AgentID: 1, Status: string(agent.RunningState), Owner: "crashed-instance", HeartbeatAt: stale},
+			wantStatus: string(agent.CanceledState),
+		},
+		{
+			// 执行实例存活时只设置取消请求，由执行实例中断
+			name:       "live owner",
+			run:        &Run{ID: "live", AgentID: 1, Status: string(agent.RunningState), Owner: "live-instance", HeartbeatAt: time.Now()},
+			wantStatus: string(agent.RunningState),
+		},
+		{
+			name:       "finished",
+			run:        &Run{ID: "finished", AgentID: 1, Status: string(agent.FinishState)},
+			wantStatus: string(agent.FinishState),
+			wantErr:    ErrRunFinished,
+		},
+		{
+			name:    "not found",
+			run:     nil,
+			wantErr: ErrRunNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs := newFakeRunRepo()
+			id := "missing"
+			if tt.run != nil {
+				runs.put(tt.run)
+				id = tt.run.ID
+			}
+			uc := newTestUsecase(&fakeChat{respond: lookupThenAnswer}, agents, runs)
+			result, err := uc.CancelRun(context.Background(), id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CancelRun() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && result.Status != string(agent.CanceledState) {
+				t.Fatalf("CancelRun() status = %s", result.Status)
+			}
+			if tt.run == nil {
+				return
+			}
+			stored, _ := runs.GetRun(context.Background(), id)
+			if stored.Status != tt.wantStatus {
+				t.Fatalf("stored status = %s, want %s", stored.Status, tt.wantStatus)
+			}
+			if stored.Status == string(agent.RunningState) && !stored.CancelRequested {
+				t.Fatal("cancel was not requested for the running run")
+			}
+			// 已取消的运行不再被领取
+			if next, _ := runs.Dequeue(context.Background(), "worker"); next != nil {
+				t.Fatalf("canceled run %s was dequeued", next.ID)
+			}
+		})
+	}
+}
+
+func TestCancelRunOnAnotherInstance(t *testing.T) {
+	agents := &fakeAgentRepo{agents: map[int]*Agent{1: nativeAgent(1)}}
+	runs := newFakeRunRepo()
+	chat := &waitingChat{started: make(chan struct{}, 1)}
+	worker := newTestUsecase(chat, agents, runs)
+	worker.heartbeatInterval = 10 * time.Millisecond
+	api := newTestUsecase(&fakeChat{respond: lookupThenAnswer}, agents, runs)
+
+	submitted, err := api.SubmitRun(context.Background(), &pb.ChatRequest{AgentId: 1, Query: "wait"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	run, err := runs.Dequeue(context.Background(), worker.instanceID)
+	if err != nil || run == nil {
+		t.Fatalf("Dequeue() = %v, %v", run, err)
+	}
+	go worker.executeRun(run)
+	select {
+	case <-chat.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("run did not start")
+	}
+
+	// 运行不在发起取消的实例上执行，由执行实例在心跳时发现取消请求
+	if _, err := api.CancelRun(context.Background(), submitted.ID); err != nil {
+		t.Fatal(err)
+	}
+	canceled := runs.waitRun(t, submitted.ID)
+	if canceled.Status != string(agent.CanceledState) || canceled.Owner != worker.instanceID {
+		t.Fatalf("run = %s owned by %q, want Canceled by the worker", canceled.Status, canceled.Owner)
+	}
+
+	// 执行实例在中断前退出：取消请求保留，恢复时直接取消而不是继续执行
+	orphan := &Run{ID: "orphan", AgentID: 1, Request: run.Request, Status: string(agent.RunningState),
+		Owner: "crashed-instance", HeartbeatAt: time.Now().Add(-2 * runLease), CancelRequested: true}
+	runs.put(orphan)
+	if err := api.ResumeInterruptedRuns(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if stored, _ := runs.GetRun(context.Background(), "orphan"); stored.Status != string(agent.CanceledState) || stored.ResumeCount != 0 {
+		t.Fatalf("orphan run = %s resumed %d times, want Canceled", stored.Status, stored.ResumeCount)
+	}
+}
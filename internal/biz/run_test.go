@@ -0,0 +1,134 @@
+package biz
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"jas-agent/agent/agent"
+	"jas-agent/agent/llm"
+	"jas-agent/agent/tools"
+	pb "jas-agent/api/agent/service/v1"
+)
+
+func init() {
+	tools.GetToolManager().RegisterTool(&fakeTool{name: "lookup", handler: func(context.Context, string) (string, error) {
+		return "lookup result 42", nil
+	}})
+}
+
+// lookupThenAnswer 先调用 lookup 工具，拿到结果后给出回答；总结请求不带工具，直接回答
+func lookupThenAnswer(req llm.ChatRequest) (*llm.ChatResponse, error) {
+	if hasToolResult(req) || len(req.Request().Tools) == 0 {
+		return textReply("the answer is 42"), nil
+	}
+	return toolCallReply("call-1", "lookup", "{}"), nil
+}
+
+func TestRunCheckpointAndResume(t *testing.T) {
+	agents := &fakeAgentRepo{agents: map[int]*Agent{1: nativeAgent(1)}}
+	runs := newFakeRunRepo()
+
+	// 第一个实例执行完整的运行，每一步结束后保存检查点
+	first := newTestUsecase(&fakeChat{respond: lookupThenAnswer}, agents, runs)
+	req := &pb.ChatRequest{Query: "what is the answer", AgentId: 1}
+	resp, err := first.Chat(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := runs.waitRun(t, resp.Metadata.RunId)
+	if done.Status != string(agent.FinishState) || done.Result != "the answer is 42" || done.Owner != first.instanceID {
+		t.Fatalf("run = %s %q owned by %q, want Finish owned by %q", done.Status, done.Result, done.Owner, first.instanceID)
+	}
+	saved := runs.checkpoints[done.ID]
+	if len(saved) < 2 {
+		t.Fatalf("saved %d checkpoints, want one per step", len(saved))
+	}
+	// 取工具调用之后的检查点，模拟执行实例在第一步结束后退出
+	var checkpoint agent.Checkpoint
+	var afterTool []byte
+	for _, data := range saved {
+		if err := json.Unmarshal(data, &checkpoint); err != nil {
+			t.Fatalf("checkpoint is not valid JSON: %v", err)
+		}
+		if checkpoint.CurrentStep == 1 && len(checkpoint.ToolCalls) == 1 {
+			afterTool = data
+			break
+		}
+	}
+	if afterTool == nil {
+		t.Fatal("no checkpoint after the tool call")
+	}
+
+	request, _ := protojson.Marshal(req)
+	stale := time.Now().Add(-2 * runLease)
+	interrupted := &Run{ID: "interrupted", AgentID: 1, Query: req.Query, Request: request, Status: string(agent.RunningState),
+		CurrentStep: 1, Checkpoint: afterTool, Owner: "crashed-instance", HeartbeatAt: stale}
+	live := &Run{ID: "live", AgentID: 1, Query: req.Query, Request: request, Status: string(agent.RunningState),
+		Owner: "live-instance", HeartbeatAt: time.Now()}
+	runs.put(interrupted)
+	runs.put(live)
+
+	// 两个实例同时恢复：心跳过期的运行只被认领一次，仍在刷新心跳的运行不被接管
+	var resumedChats []*fakeChat
+	var resumers []*AgentUsecase
+	for i := 0; i < 2; i++ {
+		chat := &fakeChat{respond: lookupThenAnswer}
+		resumedChats = append(resumedChats, chat)
+		resumers = append(resumers, newTestUsecase(chat, agents, runs))
+	}
+	for _, uc := range resumers {
+		if err := uc.ResumeInterruptedRuns(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	resumed := runs.waitRun(t, "interrupted")
+	if resumed.Status != string(agent.FinishState) || resumed.Result != "the answer is 42" || resumed.ResumeCount != 1 {
+		t.Fatalf("resumed run = %s %q, resumed %d times", resumed.Status, resumed.Result, resumed.ResumeCount)
+	}
+	if resumed.Owner != resumers[0].instanceID && resumed.Owner != resumers[1].instanceID {
+		t.Fatalf("resumed run owned by %q", resumed.Owner)
+	}
+	calls := 0
+	for _, chat := range resumedChats {
+		calls += chat.calls()
+		// 从检查点恢复：继续执行时模型看到检查点中的工具结果，不再重复调用工具
+		for _, request := range chat.requests {
+			if len(request.Request().Tools) > 0 && !strings.Contains(toolResults(request), "lookup result 42") {
+				t.Fatalf("resumed request does not contain the restored tool result")
+			}
+		}
+	}
+	if calls == 0 {
+		t.Fatal("interrupted run was not resumed")
+	}
+	for _, chat := range resumedChats {
+		if chat.calls() > 0 && chat.calls() != calls {
+			t.Fatal("interrupted run was resumed by both instances")
+		}
+	}
+	if run, _ := runs.GetRun(context.Background(), "live"); run.Status != string(agent.RunningState) || run.Owner != "live-instance" {
+		t.Fatalf("run with a live owner was taken over: %+v", run)
+	}
+
+	// 手动恢复同样需要认领：仍由存活实例执行的运行不能恢复
+	if _, err := resumers[0].ResumeRun(context.Background(), "live"); err == nil {
+		t.Fatal("ResumeRun took over a run with a live owner")
+	}
+}
+
+// toolResults 请求中全部工具结果的内容
+func toolResults(req llm.ChatRequest) string {
+	var sb strings.Builder
+	for _, msg := range req.Request().Messages {
+		if msg.Role == openai.ChatMessageRoleTool {
+			sb.WriteString(msg.Content)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,179 @@
+package biz
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"time"
+
+	"jas-agent/agent/agent"
+	"jas-agent/internal/conf"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+const (
+	defaultWebhookTimeout = 10 * time.Second
+	webhookMaxAttempts    = 3
+)
+
+// ErrWebhookNotAllowed 回调地址的协议或主机不在配置允许的范围内
+var ErrWebhookNotAllowed = errors.New("webhook url not allowed")
+
+// defaultWebhookSchemes 未配置 webhook_schemes 时允许的回调协议
+var defaultWebhookSchemes = []string{"https"}
+
+// RunWebhook 在运行结束后将运行信息 POST 到提交时指定的回调地址，回调地址的协议与主机需在配置允许的范围内。
+// 配置了签名密钥时请求头 X-Timestamp 为发送时的 Unix 秒数，X-Signature 为 "sha256=" 加
+// "<X-Timestamp>.<请求体>" 的 HMAC-SHA256 十六进制值，接收方可据此拒绝过期的重放请求
+type RunWebhook struct {
+	client  *http.Client
+	secret  []byte
+	hosts   []string
+	schemes []string
+	logger  *log.Helper
+}
+
+// runWebhookPayload 回调请求体
+type runWebhookPayload struct {
+	RunID       string             `json:"run_id"`
+	AgentID     int                `json:"agent_id"`
+	SessionID   string             `json:"session_id,omitempty"`
+	Query       string             `json:"query"`
+	Status      string             `json:"status"`
+	CurrentStep int                `json:"current_step"`
+	Result      string             `json:"result"`
+	FinalResult *agent.FinalResult `json:"final_result,omitempty"`
+	Error       string             `json:"error,omitempty"`
+	ResumeCount int                `json:"resume_count"`
+	FinishedAt  time.Time          `json:"finished_at"`
+}
+
+// NewRunWebhook 从配置文件读取回调的签名密钥、超时与允许的回调地址
+func NewRunWebhook(c *conf.Bootstrap, logger log.Logger) *RunWebhook {
+	worker := c.GetServer().GetWorker()
+	schemes := worker.GetWebhookSchemes()
+	if len(schemes) == 0 {
+		schemes = defaultWebhookSchemes
+	}
+	return &RunWebhook{
+		client: &http.Client{
+			Timeout: parseDuration(worker.GetWebhookTimeout(), defaultWebhookTimeout),
+			// 重定向的目标同样需要校验，直接视为失败
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		secret:  []byte(worker.GetWebhookSecret()),
+		hosts:   worker.GetWebhookHosts(),
+		schemes: schemes,
+		logger:  log.NewHelper(log.With(logger, "module", "biz/run_webhook")),
+	}
+}
+
+// Validate 校验回调地址的协议与主机名是否在配置允许的范围内，未配置允许的主机时拒绝所有回调地址
+func (w *RunWebhook) Validate(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("%w: %s", ErrWebhookNotAllowed, rawURL)
+	}
+	if !slices.Contains(w.schemes, u.Scheme) {
+		return fmt.Errorf("%w: scheme %q", ErrWebhookNotAllowed, u.Scheme)
+	}
+	host := u.Hostname()
+	for _, pattern := range w.hosts {
+		if ok, _ := path.Match(pattern, host); ok {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: host %q", ErrWebhookNotAllowed, host)
+}
+
+// Notify 在后台发送回调，失败时按 1s、2s 退避重试，最终失败只记录日志
+func (w *RunWebhook) Notify(run *Run) {
+	body, err := json.Marshal(&runWebhookPayload{
+		RunID:       run.ID,
+		AgentID:     run.AgentID,
+		SessionID:   run.SessionID,
+		Query:       run.Query,
+		Status:      run.Status,
+		CurrentStep: run.CurrentStep,
+		Result:      run.Result,
+		FinalResult: run.FinalResult,
+		Error:       run.Error,
+		ResumeCount: run.ResumeCount,
+		FinishedAt:  time.Now(),
+	})
+	if err != nil {
+		w.logger.Errorf("marshal webhook payload of run %s: %v", run.ID, err)
+		return
+	}
+	// 回调地址在提交时已校验，配置变更后重启的实例再次校验
+	if err := w.Validate(run.WebhookURL); err != nil {
+		w.logger.Errorf("webhook of run %s: %v", run.ID, err)
+		return
+	}
+	go func() {
+		backoff := time.Second
+		for attempt := 1; ; attempt++ {
+			err := w.post(context.Background(), run.WebhookURL, run.ID, body)
+			if err == nil {
+				return
+			}
+			if attempt == webhookMaxAttempts {
+				w.logger.Errorf("webhook of run %s failed after %d attempts: %v", run.ID, attempt, err)
+				return
+			}
+			w.logger.Warnf("webhook of run %s failed, retrying: %v", run.ID, err)
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}()
+}
+
+func (w *RunWebhook) post(ctx context.Context, url, runID string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Run-Id", runID)
+	if len(w.secret) > 0 {
+		// 每次发送（包括重试）使用当前时间签名
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set("X-Timestamp", timestamp)
+		req.Header.Set("X-Signature", "sha256="+w.sign(timestamp, body))
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+func (w *RunWebhook) sign(timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, w.secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// parseDuration 解析配置中的时长，为空或格式错误时使用默认值
+func parseDuration(value string, def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return d
+	}
+	return def
+}
@@ -0,0 +1,121 @@
+package biz
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+
+	pb "jas-agent/api/agent/service/v1"
+	"jas-agent/internal/conf"
+)
+
+func newTestWebhook(worker *conf.Server_Worker) *RunWebhook {
+	return NewRunWebhook(&conf.Bootstrap{Server: &conf.Server{Worker: worker}}, log.DefaultLogger)
+}
+
+func TestRunWebhookValidate(t *testing.T) {
+	webhook := newTestWebhook(&conf.Server_Worker{WebhookHosts: []string{"hooks.example.com", "*.alerts.example.com"}})
+	tests := []struct {
+		url string
+		ok  bool
+	}{
+		{"https://hooks.example.com/run", true},
+		{"https://hooks.example.com:8443/run", true},
+		{"https://pager.alerts.example.com/run", true},
+		{"http://hooks.example.com/run", false},
+		{"https://example.com/run", false},
+		{"https://hooks.example.com.evil.com/run", false},
+		{"https://169.254.169.254/latest/meta-data", false},
+		{"https://127.0.0.1/run", false},
+		{"file:///etc/passwd", false},
+		{"hooks.example.com/run", false},
+	}
+	for _, tt := range tests {
+		err := webhook.Validate(tt.url)
+		if (err == nil) != tt.ok {
+			t.Errorf("Validate(%q) = %v, want ok %v", tt.url, err, tt.ok)
+		}
+		if err != nil && !errors.Is(err, ErrWebhookNotAllowed) {
+			t.Errorf("Validate(%q) = %v, want ErrWebhookNotAllowed", tt.url, err)
+		}
+	}
+
+	// 未配置允许的主机时拒绝所有回调地址
+	if err := newTestWebhook(nil).Validate("https://hooks.example.com/run"); !errors.Is(err, ErrWebhookNotAllowed) {
+		t.Errorf("Validate() without allowed hosts = %v", err)
+	}
+	// 配置 webhook_schemes 后允许 http
+	if err := newTestWebhook(&conf.Server_Worker{WebhookHosts: []string{"hooks.example.com"}, WebhookSchemes: []string{"http", "https"}}).
+		Validate("http://hooks.example.com/run"); err != nil {
+		t.Errorf("Validate() with http allowed = %v", err)
+	}
+}
+
+func TestSubmitRunRejectsWebhook(t *testing.T) {
+	agents := &fakeAgentRepo{agents: map[int]*Agent{1: nativeAgent(1)}}
+	runs := newFakeRunRepo()
+	uc := newTestUsecase(&fakeChat{respond: lookupThenAnswer}, agents, runs)
+	_, err := uc.SubmitRun(context.Background(), &pb.ChatRequest{AgentId: 1, Query: "q"}, "http://10.0.0.1/admin")
+	if !errors.Is(err, ErrWebhookNotAllowed) {
+		t.Fatalf("SubmitRun() error = %v, want ErrWebhookNotAllowed", err)
+	}
+	if len(runs.runs) != 0 {
+		t.Fatal("run with a rejected webhook was enqueued")
+	}
+}
+
+func TestRunWebhookSignature(t *testing.T) {
+	type delivery struct {
+		header http.Header
+		body   []byte
+	}
+	deliveries := make(chan delivery, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		deliveries <- delivery{header: r.Header, body: body}
+	}))
+	defer server.Close()
+
+	secret := "s3cret"
+	webhook := newTestWebhook(&conf.Server_Worker{
+		WebhookSecret:  secret,
+		WebhookHosts:   []string{"127.0.0.1"},
+		WebhookSchemes: []string{"http"},
+	})
+	webhook.Notify(&Run{ID: "run-1", AgentID: 1, Status: "Finish", Result: "done", WebhookURL: server.URL + "/hook"})
+
+	var got delivery
+	select {
+	case got = <-deliveries:
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+	if got.header.Get("X-Run-Id") != "run-1" {
+		t.Fatalf("X-Run-Id = %q", got.header.Get("X-Run-Id"))
+	}
+	timestamp := got.header.Get("X-Timestamp")
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || time.Since(time.Unix(sent, 0)) > time.Minute {
+		t.Fatalf("X-Timestamp = %q", timestamp)
+	}
+	// 签名覆盖时间戳与请求体，修改任一部分后签名不再匹配
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + string(got.body)))
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got.header.Get("X-Signature") != want {
+		t.Fatalf("X-Signature = %q, want %q", got.header.Get("X-Signature"), want)
+	}
+	if webhook.sign(strconv.FormatInt(sent+1, 10), got.body) == webhook.sign(timestamp, got.body) {
+		t.Fatal("signature does not depend on the timestamp")
+	}
+}
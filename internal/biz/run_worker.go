@@ -0,0 +1,116 @@
+package biz
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"jas-agent/internal/conf"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+const (
+	defaultWorkerConcurrency  = 4
+	defaultWorkerPollInterval = 2 * time.Second
+)
+
+// RunWorkerPool 从运行队列领取异步运行并在后台执行，每个 worker 同时只执行一个运行。
+// 实现 transport.Server，随应用启动与停止
+type RunWorkerPool struct {
+	uc           *AgentUsecase
+	concurrency  int
+	pollInterval time.Duration
+	logger       *log.Helper
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
+}
+
+// NewRunWorkerPool 从配置文件读取并发数与轮询间隔
+func NewRunWorkerPool(c *conf.Bootstrap, uc *AgentUsecase, logger log.Logger) *RunWorkerPool {
+	worker := c.GetServer().GetWorker()
+	concurrency := int(worker.GetConcurrency())
+	if concurrency == 0 {
+		concurrency = defaultWorkerConcurrency
+	}
+	return &RunWorkerPool{
+		uc:           uc,
+		concurrency:  concurrency,
+		pollInterval: parseDuration(worker.GetPollInterval(), defaultWorkerPollInterval),
+		logger:       log.NewHelper(log.With(logger, "module", "biz/run_worker")),
+	}
+}
+
+// Start 启动 worker，并发数小于 0 时不启动
+func (p *RunWorkerPool) Start(context.Context) error {
+	if p.concurrency < 0 {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	for i := 0; i < p.concurrency; i++ {
+		p.wg.Add(1)
+		go p.work(ctx)
+	}
+	p.wg.Add(1)
+	go p.recoverRuns(ctx)
+	p.logger.Infof("Started %d run workers, poll interval %s", p.concurrency, p.pollInterval)
+	return nil
+}
+
+// Stop 停止领取新的运行，并在 ctx 结束前等待执行中的运行完成。
+// 未完成的运行保持 Running 状态，下次启动时从检查点恢复
+func (p *RunWorkerPool) Stop(ctx context.Context) error {
+	if p.cancel == nil {
+		return nil
+	}
+	p.cancel()
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		p.logger.Warn("Stopped run workers with runs still in progress")
+	}
+	return nil
+}
+
+// recoverRuns 定期接管心跳过期的运行，其他实例退出后其执行中的运行由存活的实例继续
+func (p *RunWorkerPool) recoverRuns(ctx context.Context) {
+	defer p.wg.Done()
+	ticker := time.NewTicker(runLease)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := p.uc.ResumeInterruptedRuns(ctx); err != nil && ctx.Err() == nil {
+			p.logger.Errorf("resume interrupted runs: %v", err)
+		}
+	}
+}
+
+func (p *RunWorkerPool) work(ctx context.Context) {
+	defer p.wg.Done()
+	for {
+		run, err := p.uc.runQueue.Dequeue(ctx, p.uc.instanceID)
+		if err != nil && ctx.Err() == nil {
+			p.logger.Errorf("dequeue run: %v", err)
+		}
+		if run != nil {
+			p.uc.executeRun(run)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.uc.queued:
+		case <-time.After(p.pollInterval):
+		}
+	}
+}
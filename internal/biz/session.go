@@ -0,0 +1,168 @@
+package biz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"jas-agent/agent/core"
+	pb "jas-agent/api/agent/service/v1"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrSessionNotFound 会话不存在
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrSessionAgentMismatch 会话属于其它 Agent
+	ErrSessionAgentMismatch = errors.New("session belongs to another agent")
+)
+
+// Session 会话领域模型
+type Session struct {
+	ID           string
+	AgentID      int
+	Title        string
+	MessageCount int
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// SessionMessage 会话消息领域模型
+type SessionMessage struct {
+	ID         int64
+	SessionID  string
+	Role       string
+	Content    string
+	Name       string
+	ToolCallID string
+	ToolCalls  []core.ToolCall
+	CreatedAt  time.Time
+}
+
+// ToCore 转换为 Agent 消息
+func (m *SessionMessage) ToCore() core.Message {
+	return core.Message{
+		Role:       core.RoleType(m.Role),
+		Content:    m.Content,
+		Name:       m.Name,
+		ToolCallID: m.ToolCallID,
+		ToolCalls:  m.ToolCalls,
+	}
+}
+
+// SessionRepo 定义会话数据访问接口
+type SessionRepo interface {
+	CreateSession(ctx context.Context, session *Session) error
+	GetSession(ctx context.Context, id string) (*Session, error)
+	ListSessions(ctx context.Context, agentID int) ([]*Session, error)
+	DeleteSession(ctx context.Context, id string) error
+	ListMessages(ctx context.Context, sessionID string) ([]*SessionMessage, error)
+	// Memory 打开会话对应的内存，历史消息会被预先加载。
+	// persistAll 为 true 时每条非系统消息都写入会话，否则本次运行的消息只保存在内存中
+	Memory(ctx context.Context, sessionID string, persistAll bool) (core.Memory, error)
+	// AddMessages 向会话追加消息
+	AddMessages(ctx context.Context, sessionID string, messages []core.Message) error
+}
+
+// SessionUsecase 负责会话相关业务逻辑
+type SessionUsecase struct {
+	sessionRepo SessionRepo
+	agentRepo   AgentRepo
+	logger      *log.Helper
+}
+
+// NewSessionUsecase 创建 SessionUsecase
+func NewSessionUsecase(sessionRepo SessionRepo, agentRepo AgentRepo, logger log.Logger) *SessionUsecase {
+	return &SessionUsecase{
+		sessionRepo: sessionRepo,
+		agentRepo:   agentRepo,
+		logger:      log.NewHelper(log.With(logger, "module", "biz/session")),
+	}
+}
+
+// CreateSession 创建会话
+func (uc *SessionUsecase) CreateSession(ctx context.Context, req *pb.SessionRequest) (*Session, error) {
+	if _, err := uc.agentRepo.GetAgent(ctx, int(req.AgentId)); err != nil {
+		return nil, err
+	}
+	session := &Session{
+		ID:      req.Id,
+		AgentID: int(req.AgentId),
+		Title:   req.Title,
+	}
+	if session.ID == "" {
+		session.ID = uuid.NewString()
+	}
+	if err := uc.sessionRepo.CreateSession(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// GetSession 获取会话及其消息
+func (uc *SessionUsecase) GetSession(ctx context.Context, id string) (*Session, []*SessionMessage, error) {
+	session, err := uc.sessionRepo.GetSession(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	messages, err := uc.sessionRepo.ListMessages(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return session, messages, nil
+}
+
+// ListSessions 列出会话，agentID 为 0 时返回全部
+func (uc *SessionUsecase) ListSessions(ctx context.Context, agentID int) ([]*Session, error) {
+	return uc.sessionRepo.ListSessions(ctx, agentID)
+}
+
+// DeleteSession 删除会话及其消息
+func (uc *SessionUsecase) DeleteSession(ctx context.Context, id string) error {
+	return uc.sessionRepo.DeleteSession(ctx, id)
+}
+
+// OpenMemory 打开会话内存，会话不存在时以给定 ID 自动创建，会话属于其它 Agent 时返回 ErrSessionAgentMismatch。
+// persistAll 为 false 时只在运行完成后由 SaveTurn 保存问题与最终回答
+func (uc *SessionUsecase) OpenMemory(ctx context.Context, sessionID string, agentID int, query string, persistAll bool) (core.Memory, error) {
+	if session, err := uc.sessionRepo.GetSession(ctx, sessionID); err == nil {
+		if session.AgentID != agentID {
+			return nil, fmt.Errorf("%w: session %s, agent %d", ErrSessionAgentMismatch, sessionID, agentID)
+		}
+	} else {
+		if !errors.Is(err, ErrSessionNotFound) {
+			return nil, err
+		}
+		session := &Session{
+			ID:      sessionID,
+			AgentID: agentID,
+			Title:   sessionTitle(query),
+		}
+		if err := uc.sessionRepo.CreateSession(ctx, session); err != nil {
+			return nil, fmt.Errorf("auto create session %s: %w", sessionID, err)
+		}
+		uc.logger.Infof("Created session %s for agent %d", sessionID, agentID)
+	}
+	return uc.sessionRepo.Memory(ctx, sessionID, persistAll)
+}
+
+// SaveTurn 将一轮对话的问题与最终回答写入会话
+func (uc *SessionUsecase) SaveTurn(ctx context.Context, sessionID, query, answer string) error {
+	return uc.sessionRepo.AddMessages(ctx, sessionID, []core.Message{
+		{Role: core.MessageRoleUser, Content: query},
+		{Role: core.MessageRoleAssistant, Content: answer},
+	})
+}
+
+// sessionTitle 使用首个问题作为会话标题
+func sessionTitle(query string) string {
+	const maxRunes = 50
+	if utf8.RuneCountInString(query) <= maxRunes {
+		return query
+	}
+	return string([]rune(query)[:maxRunes]) + "..."
+}
@@ -0,0 +1,140 @@
+package biz
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+
+	"jas-agent/agent/agent"
+	"jas-agent/agent/core"
+	pb "jas-agent/api/agent/service/v1"
+)
+
+func TestOpenMemory(t *testing.T) {
+	history := []core.Message{
+		{Role: core.MessageRoleUser, Content: "earlier question"},
+		{Role: core.MessageRoleAssistant, Content: "earlier answer"},
+	}
+	tests := []struct {
+		name       string
+		sessionID  string
+		persistAll bool
+		wantErr    error
+		// wantHistory 打开后内存中的历史消息数
+		wantHistory int
+	}{
+		{name: "own session", sessionID: "session-1", wantHistory: 2},
+		{name: "own session persist all", sessionID: "session-1", persistAll: true, wantHistory: 2},
+		{name: "session of another agent", sessionID: "session-2", wantErr: ErrSessionAgentMismatch},
+		// 不存在的会话以给定 ID 自动创建，标题为首个问题
+		{name: "missing session", sessionID: "new-session"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeSessionRepo(&Session{ID: "session-1", AgentID: 1}, &Session{ID: "session-2", AgentID: 2})
+			repo.messages["session-1"] = append([]core.Message(nil), history...)
+			uc := NewSessionUsecase(repo, &fakeAgentRepo{agents: map[int]*Agent{}}, log.DefaultLogger)
+
+			mem, err := uc.OpenMemory(context.Background(), tt.sessionID, 1, "what is the answer", tt.persistAll)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("OpenMemory() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				// 其它 Agent 的会话不被修改
+				if session, _ := repo.GetSession(context.Background(), tt.sessionID); session.AgentID != 2 || session.MessageCount != 0 {
+					t.Fatalf("session = %+v", session)
+				}
+				return
+			}
+			if got := len(mem.GetMessages()); got != tt.wantHistory {
+				t.Fatalf("memory has %d messages, want %d", got, tt.wantHistory)
+			}
+			session, err := repo.GetSession(context.Background(), tt.sessionID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if session.AgentID != 1 || (tt.sessionID == "new-session" && session.Title != "what is the answer") {
+				t.Fatalf("session = %+v", session)
+			}
+
+			// persistAll 时写入内存的消息同时保存到会话，否则只保存在内存中
+			mem.AddMessage(core.Message{Role: core.MessageRoleSystem, Content: "system prompt"})
+			mem.AddMessage(core.Message{Role: core.MessageRoleUser, Content: "what is the answer"})
+			wantSaved := tt.wantHistory
+			if tt.persistAll {
+				wantSaved++
+			}
+			if saved := repo.sessionMessages(tt.sessionID); len(saved) != wantSaved {
+				t.Fatalf("session messages = %+v, want %d", saved, wantSaved)
+			}
+		})
+	}
+}
+
+func TestSessionTurns(t *testing.T) {
+	tests := []struct {
+		name     string
+		persist  string
+		maxSteps int
+		// wantRoles 运行结束后会话中的消息
+		wantRoles []core.RoleType
+		wantState agent.State
+	}{
+		{
+			// 只保存每轮问答：运行完成后写入问题与最终回答
+			name:      "persist turns",
+			persist:   SessionPersistTurns,
+			wantRoles: []core.RoleType{core.MessageRoleUser, core.MessageRoleAssistant},
+			wantState: agent.FinishState,
+		},
+		{
+			// 未完成的运行不写入会话
+			name:      "persist turns unfinished",
+			persist:   SessionPersistTurns,
+			maxSteps:  1,
+			wantState: agent.ErrorState,
+		},
+		{
+			// 保存全部消息：运行过程中逐条写入，完成后不再重复写入问答
+			name:      "persist all",
+			persist:   SessionPersistAll,
+			wantRoles: []core.RoleType{core.MessageRoleUser, core.MessageRoleAssistant, core.MessageRoleTool, core.MessageRoleAssistant},
+			wantState: agent.FinishState,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agentConfig := nativeAgent(1)
+			config, _ := json.Marshal(map[string]any{"tool_call_mode": "native", "memory": map[string]string{"persist": tt.persist}})
+			agentConfig.ConfigJSON = string(config)
+			agents := &fakeAgentRepo{agents: map[int]*Agent{1: agentConfig}}
+			runs := newFakeRunRepo()
+			sessions := newFakeSessionRepo()
+			uc := newTestUsecase(&fakeChat{respond: lookupThenAnswer}, agents, runs)
+			uc.sessions = NewSessionUsecase(sessions, agents, log.DefaultLogger)
+
+			resp, err := uc.Chat(context.Background(), &pb.ChatRequest{Query: "what is the answer", AgentId: 1, SessionId: "session-1", MaxSteps: int32(tt.maxSteps)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if run := runs.waitRun(t, resp.Metadata.RunId); run.Status != string(tt.wantState) {
+				t.Fatalf("run status = %s, want %s", run.Status, tt.wantState)
+			}
+			saved := sessions.sessionMessages("session-1")
+			if len(saved) != len(tt.wantRoles) {
+				t.Fatalf("session messages = %+v, want roles %v", saved, tt.wantRoles)
+			}
+			for i, role := range tt.wantRoles {
+				if saved[i].Role != role {
+					t.Fatalf("session message %d = %+v, want role %s", i, saved[i], role)
+				}
+			}
+			if len(saved) > 0 && (saved[0].Content != "what is the answer" || saved[len(saved)-1].Content != "the answer is 42") {
+				t.Fatalf("session messages = %+v", saved)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package biz
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"jas-agent/agent/agent"
+)
+
+// SupervisorConfig 调度者 Agent 的配置
+type SupervisorConfig struct {
+	// Workers 可调度的工作 Agent，agents 表中的 Agent ID
+	Workers []int `json:"workers"`
+}
+
+// supervisorAgent 调度者 Agent：把 agents 表中的工作 Agent 作为工具，由模型分派子任务并综合回答
+type supervisorAgent struct {
+	// workers 加载工作 Agent，返回创建工作 Agent 的工厂与提供给模型的工作 Agent 列表
+	workers func(ctx context.Context, agentConfig *Agent, cfg DelegationConfig) (agent.SubAgentFactory, []agent.SubAgent, error)
+}
+
+// Validate 至少配置一个工作 Agent，不能调度自身
+func (s *supervisorAgent) Validate(agentConfig *Agent) error {
+	runtimeConfig, err := ParseAgentRuntimeConfig(agentConfig.ConfigJSON)
+	if err != nil {
+		return err
+	}
+	workers := runtimeConfig.Supervisor.Workers
+	if len(workers) == 0 {
+		return fmt.Errorf("supervisor.workers is required")
+	}
+	seen := make(map[int]bool, len(workers))
+	for _, id := range workers {
+		if id <= 0 {
+			return fmt.Errorf("invalid supervisor worker %d: must be an agent id", id)
+		}
+		if id == agentConfig.ID {
+			return fmt.Errorf("supervisor worker %d: agent cannot supervise itself", id)
+		}
+		if seen[id] {
+			return fmt.Errorf("duplicate supervisor worker %d", id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// CreateAgentExecutor 加载工作 Agent 并创建调度者执行器，工作 Agent 在运行时按需创建
+func (s *supervisorAgent) CreateAgentExecutor(ctx context.Context,
+	agentConfig *Agent,
+	agentCtx *agent.Context) (*agent.AgentExecutor, error) {
+	if err := s.Validate(agentConfig); err != nil {
+		return nil, err
+	}
+	runtimeConfig, err := ParseAgentRuntimeConfig(agentConfig.ConfigJSON)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(runtimeConfig.Supervisor.Workers))
+	for _, id := range runtimeConfig.Supervisor.Workers {
+		names = append(names, strconv.Itoa(id))
+	}
+	factory, workers, err := s.workers(ctx, agentConfig, DelegationConfig{Agents: names})
+	if err != nil {
+		return nil, err
+	}
+	agent.WithSubAgents(factory, workers)(agentCtx)
+	return agent.NewSupervisorAgentExecutor(agentCtx), nil
+}
+
+func (s *supervisorAgent) AgentType() agent.AgentType {
+	return agent.SupervisorAgentType
+}
+
+func (s *supervisorAgent) Description() string {
+	return "调度代理，把子任务分派给多个Agent并综合它们的回答"
+}
+
+func (s *supervisorAgent) Alias() string {
+	return "supervisor"
+}
@@ -0,0 +1,252 @@
+package biz
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+
+	"jas-agent/agent/agent"
+	"jas-agent/agent/llm"
+	pb "jas-agent/api/agent/service/v1"
+)
+
+// supervisorConfig 调度 workers 的 supervisor Agent 配置
+func supervisorConfig(id int, workers ...int) *Agent {
+	config, _ := json.Marshal(map[string]any{
+		"tool_call_mode": "native",
+		"summary":        map[string]string{"policy": "never"},
+		"supervisor":     map[string]any{"workers": workers},
+	})
+	return &Agent{ID: id, Name: fmt.Sprintf("supervisor-%d", id), Framework: string(agent.SupervisorAgentType), ConfigJSON: string(config), IsActive: true}
+}
+
+// toolNames 请求中提供给模型的工具名称
+func toolNames(req llm.ChatRequest) map[string]bool {
+	names := map[string]bool{}
+	for _, tool := range req.Request().Tools {
+		if tool.Function != nil {
+			names[tool.Function.Name] = true
+		}
+	}
+	return names
+}
+
+// supervisorModel 调度者同时把任务分派给 agent_2 与 agent_3，agent_3 又把任务分派给 agent_2，
+// agent_2 调用 lookup 后回答
+func supervisorModel(req llm.ChatRequest) (*llm.ChatResponse, error) {
+	names := toolNames(req)
+	switch {
+	case names["agent_3"]:
+		if !hasToolResult(req) {
+			resp := textReply("")
+			resp.Choices[0].Message.ToolCalls = []openai.ToolCall{
+				{ID: "call-2", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "agent_2", Arguments: `{"task": "find the answer"}`}},
+				{ID: "call-3", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "agent_3", Arguments: `{"task": "verify the answer"}`}},
+			}
+			return resp, nil
+		}
+		return textReply("combined: " + toolResults(req)), nil
+	case names["agent_2"]:
+		if !hasToolResult(req) {
+			return toolCallReply("call-nested", "agent_2", `{"task": "look it up again"}`), nil
+		}
+		return textReply("verified " + toolResults(req)), nil
+	}
+	return lookupThenAnswer(req)
+}
+
+func TestSupervisorDelegatesToWorkers(t *testing.T) {
+	worker := nativeAgent(2)
+	worker.IsActive = true
+	agents := &fakeAgentRepo{agents: map[int]*Agent{
+		1: supervisorConfig(1, 2, 3),
+		2: worker,
+		3: supervisorConfig(3, 2),
+	}}
+	chat := &fakeChat{respond: supervisorModel}
+	uc := newTestUsecase(chat, agents, newFakeRunRepo())
+
+	resp, err := uc.Chat(context.Background(), &pb.ChatRequest{AgentId: 1, Query: "what is the answer"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Response != "combined: the answer is 42verified the answer is 42" {
+		t.Fatalf("response = %q", resp.Response)
+	}
+	// 工作 Agent 以 agent_<ID> 工具的形式提供给调度者，说明包含名称与框架
+	var described bool
+	for _, tool := range chat.requests[0].Request().Tools {
+		if tool.Function != nil && tool.Function.Name == "agent_3" {
+			described = strings.HasPrefix(tool.Function.Description, "supervisor-3（"+string(agent.SupervisorAgentType)+"）")
+		}
+	}
+	if !described {
+		t.Fatalf("worker tools = %v, want agent_3 described by name and framework", toolNames(chat.requests[0]))
+	}
+
+	subRuns := resp.FinalResult.SubRuns
+	if len(subRuns) != 2 {
+		t.Fatalf("sub runs = %v", subRuns)
+	}
+	want := []struct {
+		agent, source, input, output string
+	}{
+		{"2", "agent_2#1", "find the answer", "the answer is 42"},
+		{"3", "agent_3#2", "verify the answer", "verified the answer is 42"},
+	}
+	for i, run := range subRuns {
+		if run.Agent != want[i].agent || run.Source != want[i].source || run.Depth != 1 || run.Status != agent.PlanStepCompleted ||
+			run.Input != want[i].input || run.Output != want[i].output {
+			t.Fatalf("sub run %d = %+v", i, run)
+		}
+	}
+	if tools := subRuns[0].ToolsCalled; len(tools) != 1 || tools[0] != "lookup" {
+		t.Fatalf("worker 2 tools called = %v", tools)
+	}
+	// agent_3 自身调度 agent_2，嵌套的运行记录在其 SubRuns 中
+	nested := subRuns[1].SubRuns
+	if len(nested) != 1 || nested[0].Agent != "2" || nested[0].Depth != 2 || nested[0].Source != "agent_3#2/agent_2#1" ||
+		nested[0].Input != "look it up again" || nested[0].Output != "the answer is 42" {
+		t.Fatalf("nested sub runs = %+v", nested)
+	}
+	if subRuns[1].AgentType != string(agent.SupervisorAgentType) || subRuns[0].AgentType != string(agent.ReactAgentType) {
+		t.Fatalf("sub run agent types = %s, %s", subRuns[0].AgentType, subRuns[1].AgentType)
+	}
+}
+
+func TestSupervisorWorkers(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *Agent
+		wantErr string
+	}{
+		{name: "no workers", config: supervisorConfig(1), wantErr: "supervisor.workers is required"},
+		{name: "invalid id", config: supervisorConfig(1, 0), wantErr: "must be an agent id"},
+		{name: "itself", config: supervisorConfig(1, 1), wantErr: "agent cannot supervise itself"},
+		{name: "duplicate", config: supervisorConfig(1, 2, 2), wantErr: "duplicate supervisor worker 2"},
+		{name: "unknown worker", config: supervisorConfig(1, 4), wantErr: "load delegation agent 4"},
+		// 停用的 Agent 不能作为工作 Agent
+		{name: "inactive worker", config: supervisorConfig(1, 3), wantErr: "delegation agent 3 (agent-3) is not active"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agents := &fakeAgentRepo{agents: map[int]*Agent{1: tt.config, 3: nativeAgent(3)}}
+			chat := &fakeChat{respond: supervisorModel}
+			uc := newTestUsecase(chat, agents, newFakeRunRepo())
+			_, err := uc.Chat(context.Background(), &pb.ChatRequest{AgentId: 1, Query: "what is the answer"})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Chat() error = %v, want %q", err, tt.wantErr)
+			}
+			if chat.calls() != 0 {
+				t.Fatal("model was called with an invalid supervisor")
+			}
+		})
+	}
+}
+
+// workerConfig 带 config_json 的工作 Agent，extra 覆盖或补充 native 工具调用与不总结的默认配置
+func workerConfig(id int, extra map[string]any) *Agent {
+	cfg := map[string]any{"tool_call_mode": "native", "summary": map[string]string{"policy": "never"}}
+	for key, value := range extra {
+		cfg[key] = value
+	}
+	config, _ := json.Marshal(cfg)
+	worker := nativeAgent(id)
+	worker.ConfigJSON = string(config)
+	worker.IsActive = true
+	return worker
+}
+
+func TestSupervisorWorkerRuntimeConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		worker map[string]any
+		// check 检查工作 Agent 的请求与调度者的回答
+		check func(t *testing.T, workerRequests []llm.ChatRequest, resp *pb.ChatResponse)
+	}{
+		{
+			// 工作 Agent 禁止的工具不因调度者的策略而被执行
+			name:   "tool policy",
+			worker: map[string]any{"tool_policy": map[string]any{"rules": []map[string]string{{"pattern": "lookup", "policy": "deny"}}}},
+			check: func(t *testing.T, workerRequests []llm.ChatRequest, resp *pb.ChatResponse) {
+				last := toolResults(workerRequests[len(workerRequests)-1])
+				if !strings.Contains(last, agent.ErrToolDenied.Error()) || strings.Contains(last, "lookup result 42") {
+					t.Fatalf("worker tool results = %q, want lookup denied", last)
+				}
+			},
+		},
+		{
+			// 工作 Agent 使用自身的各阶段模型，不沿用调度者的阶段模型
+			name:   "phase models",
+			worker: map[string]any{"models": map[string]string{"react": "worker-model"}},
+			check: func(t *testing.T, workerRequests []llm.ChatRequest, resp *pb.ChatResponse) {
+				for _, req := range workerRequests {
+					if model := req.Request().Model; model != "worker-model" {
+						t.Fatalf("worker model = %q, want worker-model", model)
+					}
+				}
+			},
+		},
+		{
+			// 工作 Agent 自身的运行预算在调用 lookup 后用尽
+			name:   "budget",
+			worker: map[string]any{"budget": map[string]int{"max_tokens": 10}},
+			check: func(t *testing.T, workerRequests []llm.ChatRequest, resp *pb.ChatResponse) {
+				if len(workerRequests) != 1 {
+					t.Fatalf("worker made %d model calls, want 1 within its budget", len(workerRequests))
+				}
+				run := resp.FinalResult.SubRuns[0]
+				if run.Status != agent.PlanStepFailed || run.State != string(agent.BudgetExceededState) {
+					t.Fatalf("worker run = %+v, want failed with %s", run, agent.BudgetExceededState)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			supervisor := supervisorConfig(1, 2)
+			config, _ := json.Marshal(map[string]any{
+				"tool_call_mode": "native",
+				"summary":        map[string]string{"policy": "never"},
+				"supervisor":     map[string]any{"workers": []int{2}},
+				"models":         map[string]string{"react": "supervisor-model"},
+			})
+			supervisor.ConfigJSON = string(config)
+			agents := &fakeAgentRepo{agents: map[int]*Agent{1: supervisor, 2: workerConfig(2, tt.worker)}}
+
+			var mu sync.Mutex
+			var workerRequests []llm.ChatRequest
+			chat := &fakeChat{respond: func(req llm.ChatRequest) (*llm.ChatResponse, error) {
+				if toolNames(req)["agent_2"] {
+					if !hasToolResult(req) {
+						return toolCallReply("call-worker", "agent_2", `{"task": "find the answer"}`), nil
+					}
+					return textReply("supervisor: " + toolResults(req)), nil
+				}
+				mu.Lock()
+				workerRequests = append(workerRequests, req)
+				mu.Unlock()
+				resp, err := lookupThenAnswer(req)
+				if resp != nil {
+					resp.Usage = openai.Usage{PromptTokens: 15, CompletionTokens: 5}
+				}
+				return resp, err
+			}}
+			uc := newTestUsecase(chat, agents, newFakeRunRepo())
+
+			resp, err := uc.Chat(context.Background(), &pb.ChatRequest{AgentId: 1, Query: "what is the answer"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(workerRequests) == 0 || len(resp.FinalResult.SubRuns) != 1 {
+				t.Fatalf("worker was not called: %+v", resp.FinalResult)
+			}
+			tt.check(t, workerRequests, resp)
+		})
+	}
+}
@@ -79,7 +79,8 @@ type MCPRepo interface {
 }
 
 type IAgent interface {
-	Validate() bool
+	// Validate 在创建或更新 Agent 时校验该类型所需的配置
+	Validate(agentConfig *Agent) error
 	CreateAgentExecutor(ctx context.Context,
 		agentConfig *Agent, agentCtx *agent.Context) (*agent.AgentExecutor, error)
 	AgentType() agent.AgentType
@@ -0,0 +1,141 @@
+package biz
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+
+	"jas-agent/agent/llm"
+	"jas-agent/internal/conf"
+)
+
+// defaultTenantBudget 作为未单独配置租户的默认预算
+const defaultTenantBudget = "*"
+
+// tenantUsageRefresh 租户当天用量从每日汇总重新加载的间隔
+const tenantUsageRefresh = 30 * time.Second
+
+// TokenUsageRepo 定义 token 用量数据访问接口
+type TokenUsageRepo interface {
+	// AddDailyUsage 将一次调用的用量累加到租户下 Agent 当天在该模型上的汇总中
+	AddDailyUsage(ctx context.Context, tenant string, agentID int, day time.Time, model string, usage llm.Usage) error
+	// GetTenantDailyUsage 汇总租户当天在所有 Agent 与模型上的用量
+	GetTenantDailyUsage(ctx context.Context, tenant string, day time.Time) (llm.Usage, error)
+}
+
+// NewLLMPricing 从配置文件读取模型单价
+func NewLLMPricing(c *conf.Bootstrap) llm.Pricing {
+	pricing := llm.Pricing{}
+	for _, price := range c.GetLlm().GetPricing() {
+		pricing[price.Model] = llm.ModelPrice{Prompt: price.Prompt, Completion: price.Completion}
+	}
+	return pricing
+}
+
+// TenantBudgets 按 llm.tenant_budgets 检查租户的每日用量预算。
+// 当天用量从每日汇总加载并定期刷新，本实例的用量在刷新之间直接累加，
+// 其他实例的用量在下次刷新后计入
+type TenantBudgets struct {
+	repo    TokenUsageRepo
+	logger  *log.Helper
+	budgets map[string]llm.Budget
+	now     func() time.Time
+
+	mu    sync.Mutex
+	usage map[string]*tenantUsage
+}
+
+type tenantUsage struct {
+	day      string
+	usage    llm.Usage
+	loadedAt time.Time
+}
+
+// NewTenantBudgets 从配置文件读取租户预算
+func NewTenantBudgets(c *conf.Bootstrap, repo TokenUsageRepo, logger log.Logger) *TenantBudgets {
+	budgets := map[string]llm.Budget{}
+	for _, budget := range c.GetLlm().GetTenantBudgets() {
+		budgets[budget.GetTenant()] = llm.Budget{
+			MaxTokens: int(budget.GetMaxDailyTokens()),
+			MaxCost:   budget.GetMaxDailyCost(),
+		}
+	}
+	return &TenantBudgets{
+		repo:    repo,
+		logger:  log.NewHelper(log.With(logger, "module", "biz/usage")),
+		budgets: budgets,
+		now:     time.Now,
+		usage:   map[string]*tenantUsage{},
+	}
+}
+
+// budget 返回租户的每日预算，未单独配置或未指定租户时使用默认预算，
+// 未指定租户的调用共用同一份默认预算
+func (b *TenantBudgets) budget(tenant string) (llm.Budget, bool) {
+	budget, ok := b.budgets[tenant]
+	if !ok {
+		budget, ok = b.budgets[defaultTenantBudget]
+	}
+	return budget, ok && (budget.MaxTokens > 0 || budget.MaxCost > 0)
+}
+
+// Limits 返回租户预算对应的用量限制，用于创建运行的 UsageMeter
+func (b *TenantBudgets) Limits(tenant string) []llm.Limit {
+	budget, ok := b.budget(tenant)
+	if !ok {
+		return nil
+	}
+	return []llm.Limit{&tenantLimit{budgets: b, tenant: tenant, budget: budget}}
+}
+
+// Observe 将本实例的一次调用计入租户当天用量
+func (b *TenantBudgets) Observe(tenant string, usage llm.Usage) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if current, ok := b.usage[tenant]; ok && current.day == b.now().Format(time.DateOnly) {
+		current.usage = current.usage.Add(usage)
+	}
+}
+
+// dailyUsage 返回租户当天用量，缓存过期或跨天时从每日汇总重新加载
+func (b *TenantBudgets) dailyUsage(tenant string) llm.Usage {
+	now := b.now()
+	day := now.Format(time.DateOnly)
+	b.mu.Lock()
+	current, ok := b.usage[tenant]
+	if ok && current.day == day && now.Sub(current.loadedAt) < tenantUsageRefresh {
+		usage := current.usage
+		b.mu.Unlock()
+		return usage
+	}
+	b.mu.Unlock()
+
+	usage, err := b.repo.GetTenantDailyUsage(context.Background(), tenant, now)
+	if err != nil {
+		// 加载失败时沿用当天已知的用量，不因统计故障阻断调用
+		b.logger.Warnf("load daily usage of tenant %s: %v", tenant, err)
+		if ok && current.day == day {
+			return current.usage
+		}
+		return llm.Usage{}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.usage[tenant] = &tenantUsage{day: day, usage: usage, loadedAt: now}
+	return usage
+}
+
+// tenantLimit 租户每日预算，实现 llm.Limit
+type tenantLimit struct {
+	budgets *TenantBudgets
+	tenant  string
+	budget  llm.Budget
+}
+
+func (l *tenantLimit) Exceeded() bool {
+	usage := l.budgets.dailyUsage(l.tenant)
+	return (l.budget.MaxTokens > 0 && usage.TotalTokens() >= l.budget.MaxTokens) ||
+		(l.budget.MaxCost > 0 && usage.Cost >= l.budget.MaxCost)
+}
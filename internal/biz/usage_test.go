@@ -0,0 +1,66 @@
+package biz
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+
+	"jas-agent/agent/llm"
+	"jas-agent/internal/conf"
+)
+
+func TestTenantBudgets(t *testing.T) {
+	repo := &fakeUsageRepo{daily: map[string]llm.Usage{"acme": {PromptTokens: 900}}}
+	budgets := NewTenantBudgets(&conf.Bootstrap{Llm: &conf.LLM{TenantBudgets: []*conf.LLM_TenantBudget{
+		{Tenant: "acme", MaxDailyTokens: 1000},
+		{Tenant: defaultTenantBudget, MaxDailyCost: 1},
+	}}}, repo, log.DefaultLogger)
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.Local)
+	budgets.now = func() time.Time { return now }
+
+	acme := llm.NewUsageMeter(llm.Budget{}, budgets.Limits("acme")...)
+	if acme.Exceeded() {
+		t.Fatal("acme exceeded before reaching its budget")
+	}
+	// 本实例的用量在刷新前直接计入
+	budgets.Observe("acme", llm.Usage{CompletionTokens: 100})
+	if !acme.Exceeded() {
+		t.Fatal("acme not exceeded after reaching its budget")
+	}
+	if repo.loads != 1 {
+		t.Fatalf("loads = %d, want 1 within the refresh interval", repo.loads)
+	}
+
+	// 未单独配置的租户使用默认预算，其他实例的用量在刷新后计入
+	other := llm.NewUsageMeter(llm.Budget{}, budgets.Limits("other")...)
+	if other.Exceeded() {
+		t.Fatal("other exceeded before any usage")
+	}
+	repo.daily["other"] = llm.Usage{Cost: 1.5}
+	if other.Exceeded() {
+		t.Fatal("other exceeded before the cached usage is refreshed")
+	}
+	now = now.Add(tenantUsageRefresh)
+	if !other.Exceeded() {
+		t.Fatal("other not exceeded after refresh")
+	}
+
+	// 未指定租户的调用同样受默认预算约束
+	anonymous := llm.NewUsageMeter(llm.Budget{}, budgets.Limits("")...)
+	if anonymous.Exceeded() {
+		t.Fatal("anonymous exceeded before any usage")
+	}
+	repo.daily[""] = llm.Usage{Cost: 1}
+	now = now.Add(tenantUsageRefresh)
+	if !anonymous.Exceeded() {
+		t.Fatal("anonymous not exceeded after reaching the default budget")
+	}
+
+	// 跨天后重新加载当天用量
+	now = now.Add(24 * time.Hour)
+	repo.daily["acme"] = llm.Usage{}
+	if acme.Exceeded() {
+		t.Fatal("acme still exceeded on the next day")
+	}
+}
@@ -3,4 +3,4 @@ package biz
 import "github.com/google/wire"
 
 // ProviderSet biz provider.
-var ProviderSet = wire.NewSet(NewAgentUsecase, NewMcpUsecase, NewAgentFactory, NewKnowledgeUsecase)
+var ProviderSet = wire.NewSet(NewAgentUsecase, NewMcpUsecase, NewAgentFactory, NewKnowledgeUsecase, NewSessionUsecase, NewLLMPricing, NewTenantBudgets, NewRunWebhook, NewRunWorkerPool)
@@ -26,6 +26,7 @@ type Bootstrap struct {
 	Server        *Server                `protobuf:"bytes,1,opt,name=server,proto3" json:"server,omitempty"`
 	Data          *Data                  `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
 	Llm           *LLM                   `protobuf:"bytes,3,opt,name=llm,proto3" json:"llm,omitempty"`
+	Rca           *RCA                   `protobuf:"bytes,4,opt,name=rca,proto3" json:"rca,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -81,10 +82,19 @@ func (x *Bootstrap) GetLlm() *LLM {
 	return nil
 }
 
+func (x *Bootstrap) GetRca() *RCA {
+	if x != nil {
+		return x.Rca
+	}
+	return nil
+}
+
 type Server struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Http          *Server_HTTP           `protobuf:"bytes,1,opt,name=http,proto3" json:"http,omitempty"`
 	Grpc          *Server_GRPC           `protobuf:"bytes,2,opt,name=grpc,proto3" json:"grpc,omitempty"`
+	Worker        *Server_Worker         `protobuf:"bytes,3,opt,name=worker,proto3" json:"worker,omitempty"`
+	Mcp           *Server_MCP            `protobuf:"bytes,4,opt,name=mcp,proto3" json:"mcp,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -133,6 +143,20 @@ func (x *Server) GetGrpc() *Server_GRPC {
 	return nil
 }
 
+func (x *Server) GetWorker() *Server_Worker {
+	if x != nil {
+		return x.Worker
+	}
+	return nil
+}
+
+func (x *Server) GetMcp() *Server_MCP {
+	if x != nil {
+		return x.Mcp
+	}
+	return nil
+}
+
 type Data struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Database      *Data_Database         `protobuf:"bytes,1,opt,name=database,proto3" json:"database,omitempty"`
@@ -202,12 +226,17 @@ func (x *Data) GetMilvus() *Data_Milvus {
 }
 
 type LLM struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ApiKey        string                 `protobuf:"bytes,1,opt,name=api_key,json=apiKey,proto3" json:"api_key,omitempty"`
-	BaseUrl       string                 `protobuf:"bytes,2,opt,name=base_url,json=baseUrl,proto3" json:"base_url,omitempty"`
-	Model         string                 `protobuf:"bytes,3,opt,name=model,proto3" json:"model,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	ApiKey           string                 `protobuf:"bytes,1,opt,name=api_key,json=apiKey,proto3" json:"api_key,omitempty"`
+	BaseUrl          string                 `protobuf:"bytes,2,opt,name=base_url,json=baseUrl,proto3" json:"base_url,omitempty"`
+	Model            string                 `protobuf:"bytes,3,opt,name=model,proto3" json:"model,omitempty"`                                               // 默认模型
+	Providers        []*LLM_Provider        `protobuf:"bytes,4,rep,name=providers,proto3" json:"providers,omitempty"`                                       // 模型服务商，模型名写作 "provider/model" 时路由到对应服务商
+	DefaultProvider  string                 `protobuf:"bytes,5,opt,name=default_provider,json=defaultProvider,proto3" json:"default_provider,omitempty"`    // 默认服务商，为空时使用 api_key/base_url 构成的 default 服务商或第一个服务商
+	Pricing          []*LLM_ModelPrice      `protobuf:"bytes,6,rep,name=pricing,proto3" json:"pricing,omitempty"`                                           // 模型单价，用于计算调用费用与费用预算
+	StructuredOutput string                 `protobuf:"bytes,7,opt,name=structured_output,json=structuredOutput,proto3" json:"structured_output,omitempty"` // default 服务商的结构化输出方式，同 Provider.structured_output
+	TenantBudgets    []*LLM_TenantBudget    `protobuf:"bytes,8,rep,name=tenant_budgets,json=tenantBudgets,proto3" json:"tenant_budgets,omitempty"`          // 租户每日预算，tenant 为 "*" 的项作为未单独配置租户的默认预算
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *LLM) Reset() {
@@ -261,6 +290,41 @@ func (x *LLM) GetModel() string {
 	return ""
 }
 
+func (x *LLM) GetProviders() []*LLM_Provider {
+	if x != nil {
+		return x.Providers
+	}
+	return nil
+}
+
+func (x *LLM) GetDefaultProvider() string {
+	if x != nil {
+		return x.DefaultProvider
+	}
+	return ""
+}
+
+func (x *LLM) GetPricing() []*LLM_ModelPrice {
+	if x != nil {
+		return x.Pricing
+	}
+	return nil
+}
+
+func (x *LLM) GetStructuredOutput() string {
+	if x != nil {
+		return x.StructuredOutput
+	}
+	return ""
+}
+
+func (x *LLM) GetTenantBudgets() []*LLM_TenantBudget {
+	if x != nil {
+		return x.TenantBudgets
+	}
+	return nil
+}
+
 type Knowledge struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UploadDir     string                 `protobuf:"bytes,1,opt,name=upload_dir,json=uploadDir,proto3" json:"upload_dir,omitempty"`
@@ -313,6 +377,74 @@ func (x *Knowledge) GetApiKey() string {
 	return ""
 }
 
+type RCA struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Server        *RCA_Server            `protobuf:"bytes,1,opt,name=server,proto3" json:"server,omitempty"`
+	Clients       *RCA_Clients           `protobuf:"bytes,2,opt,name=clients,proto3" json:"clients,omitempty"`
+	Weaviate      *RCA_Weaviate          `protobuf:"bytes,3,opt,name=weaviate,proto3" json:"weaviate,omitempty"`
+	Anomaly       *RCA_Anomaly           `protobuf:"bytes,4,opt,name=anomaly,proto3" json:"anomaly,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RCA) Reset() {
+	*x = RCA{}
+	mi := &file_conf_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RCA) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RCA) ProtoMessage() {}
+
+func (x *RCA) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RCA.ProtoReflect.Descriptor instead.
+func (*RCA) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *RCA) GetServer() *RCA_Server {
+	if x != nil {
+		return x.Server
+	}
+	return nil
+}
+
+func (x *RCA) GetClients() *RCA_Clients {
+	if x != nil {
+		return x.Clients
+	}
+	return nil
+}
+
+func (x *RCA) GetWeaviate() *RCA_Weaviate {
+	if x != nil {
+		return x.Weaviate
+	}
+	return nil
+}
+
+func (x *RCA) GetAnomaly() *RCA_Anomaly {
+	if x != nil {
+		return x.Anomaly
+	}
+	return nil
+}
+
 type Server_HTTP struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Addr          string                 `protobuf:"bytes,1,opt,name=addr,proto3" json:"addr,omitempty"`
@@ -322,7 +454,7 @@ type Server_HTTP struct {
 
 func (x *Server_HTTP) Reset() {
 	*x = Server_HTTP{}
-	mi := &file_conf_proto_msgTypes[5]
+	mi := &file_conf_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -334,7 +466,7 @@ func (x *Server_HTTP) String() string {
 func (*Server_HTTP) ProtoMessage() {}
 
 func (x *Server_HTTP) ProtoReflect() protoreflect.Message {
-	mi := &file_conf_proto_msgTypes[5]
+	mi := &file_conf_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -366,7 +498,7 @@ type Server_GRPC struct {
 
 func (x *Server_GRPC) Reset() {
 	*x = Server_GRPC{}
-	mi := &file_conf_proto_msgTypes[6]
+	mi := &file_conf_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -378,7 +510,7 @@ func (x *Server_GRPC) String() string {
 func (*Server_GRPC) ProtoMessage() {}
 
 func (x *Server_GRPC) ProtoReflect() protoreflect.Message {
-	mi := &file_conf_proto_msgTypes[6]
+	mi := &file_conf_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -401,6 +533,228 @@ func (x *Server_GRPC) GetAddr() string {
 	return ""
 }
 
+// 异步运行（SubmitRun）的后台执行配置
+type Server_Worker struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Concurrency    int32                  `protobuf:"varint,1,opt,name=concurrency,proto3" json:"concurrency,omitempty"`                            // 并发执行的运行数，默认 4，小于 0 时不启动 worker
+	PollInterval   string                 `protobuf:"bytes,2,opt,name=poll_interval,json=pollInterval,proto3" json:"poll_interval,omitempty"`       // 轮询队列的间隔，默认 2s
+	WebhookSecret  string                 `protobuf:"bytes,3,opt,name=webhook_secret,json=webhookSecret,proto3" json:"webhook_secret,omitempty"`    // 完成回调的 HMAC-SHA256 签名密钥，为空时不签名
+	WebhookTimeout string                 `protobuf:"bytes,4,opt,name=webhook_timeout,json=webhookTimeout,proto3" json:"webhook_timeout,omitempty"` // 完成回调的请求超时，默认 10s
+	WebhookHosts   []string               `protobuf:"bytes,5,rep,name=webhook_hosts,json=webhookHosts,proto3" json:"webhook_hosts,omitempty"`       // 允许的回调主机名，path.Match 模式，如 "hooks.example.com"、"*.example.com"，未配置时不允许指定回调地址
+	WebhookSchemes []string               `protobuf:"bytes,6,rep,name=webhook_schemes,json=webhookSchemes,proto3" json:"webhook_schemes,omitempty"` // 允许的回调协议，默认只允许 https
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Server_Worker) Reset() {
+	*x = Server_Worker{}
+	mi := &file_conf_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Server_Worker) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Server_Worker) ProtoMessage() {}
+
+func (x *Server_Worker) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Server_Worker.ProtoReflect.Descriptor instead.
+func (*Server_Worker) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 2}
+}
+
+func (x *Server_Worker) GetConcurrency() int32 {
+	if x != nil {
+		return x.Concurrency
+	}
+	return 0
+}
+
+func (x *Server_Worker) GetPollInterval() string {
+	if x != nil {
+		return x.PollInterval
+	}
+	return ""
+}
+
+func (x *Server_Worker) GetWebhookSecret() string {
+	if x != nil {
+		return x.WebhookSecret
+	}
+	return ""
+}
+
+func (x *Server_Worker) GetWebhookTimeout() string {
+	if x != nil {
+		return x.WebhookTimeout
+	}
+	return ""
+}
+
+func (x *Server_Worker) GetWebhookHosts() []string {
+	if x != nil {
+		return x.WebhookHosts
+	}
+	return nil
+}
+
+func (x *Server_Worker) GetWebhookSchemes() []string {
+	if x != nil {
+		return x.WebhookSchemes
+	}
+	return nil
+}
+
+// 以 MCP 服务端对外提供 Agent 与工具，挂载在 HTTP 服务上
+type Server_MCP struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Enabled       bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`     // 挂载路径，默认 /mcp：<path> 为 Streamable HTTP，<path>/sse 与 <path>/message 为 SSE
+	Tokens        []*Server_MCP_Token    `protobuf:"bytes,3,rep,name=tokens,proto3" json:"tokens,omitempty"` // 允许访问的 Bearer Token，未配置时拒绝所有请求
+	Tools         []string               `protobuf:"bytes,4,rep,name=tools,proto3" json:"tools,omitempty"`   // 对外提供的全局注册工具，path.Match 模式，如 "calculator"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Server_MCP) Reset() {
+	*x = Server_MCP{}
+	mi := &file_conf_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Server_MCP) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Server_MCP) ProtoMessage() {}
+
+func (x *Server_MCP) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Server_MCP.ProtoReflect.Descriptor instead.
+func (*Server_MCP) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 3}
+}
+
+func (x *Server_MCP) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *Server_MCP) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Server_MCP) GetTokens() []*Server_MCP_Token {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+func (x *Server_MCP) GetTools() []string {
+	if x != nil {
+		return x.Tools
+	}
+	return nil
+}
+
+type Server_MCP_Token struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // 调用方名称，用于日志
+	Token         string                 `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	Agents        []int32                `protobuf:"varint,3,rep,packed,name=agents,proto3" json:"agents,omitempty"` // 可调用的 Agent ID，为空时可调用全部对外提供的 Agent
+	Tenant        string                 `protobuf:"bytes,4,opt,name=tenant,proto3" json:"tenant,omitempty"`         // 调用方所属租户，通过该 Token 发起的对话按此租户统计用量与检查预算
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Server_MCP_Token) Reset() {
+	*x = Server_MCP_Token{}
+	mi := &file_conf_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Server_MCP_Token) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Server_MCP_Token) ProtoMessage() {}
+
+func (x *Server_MCP_Token) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Server_MCP_Token.ProtoReflect.Descriptor instead.
+func (*Server_MCP_Token) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 3, 0}
+}
+
+func (x *Server_MCP_Token) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Server_MCP_Token) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *Server_MCP_Token) GetAgents() []int32 {
+	if x != nil {
+		return x.Agents
+	}
+	return nil
+}
+
+func (x *Server_MCP_Token) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
 type Data_Database struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
 	Driver          string                 `protobuf:"bytes,1,opt,name=driver,proto3" json:"driver,omitempty"`
@@ -414,7 +768,7 @@ type Data_Database struct {
 
 func (x *Data_Database) Reset() {
 	*x = Data_Database{}
-	mi := &file_conf_proto_msgTypes[7]
+	mi := &file_conf_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -426,7 +780,7 @@ func (x *Data_Database) String() string {
 func (*Data_Database) ProtoMessage() {}
 
 func (x *Data_Database) ProtoReflect() protoreflect.Message {
-	mi := &file_conf_proto_msgTypes[7]
+	mi := &file_conf_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -489,7 +843,7 @@ type Data_Neo4J struct {
 
 func (x *Data_Neo4J) Reset() {
 	*x = Data_Neo4J{}
-	mi := &file_conf_proto_msgTypes[8]
+	mi := &file_conf_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -501,7 +855,7 @@ func (x *Data_Neo4J) String() string {
 func (*Data_Neo4J) ProtoMessage() {}
 
 func (x *Data_Neo4J) ProtoReflect() protoreflect.Message {
-	mi := &file_conf_proto_msgTypes[8]
+	mi := &file_conf_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -557,7 +911,7 @@ type Data_Milvus struct {
 
 func (x *Data_Milvus) Reset() {
 	*x = Data_Milvus{}
-	mi := &file_conf_proto_msgTypes[9]
+	mi := &file_conf_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -569,7 +923,7 @@ func (x *Data_Milvus) String() string {
 func (*Data_Milvus) ProtoMessage() {}
 
 func (x *Data_Milvus) ProtoReflect() protoreflect.Message {
-	mi := &file_conf_proto_msgTypes[9]
+	mi := &file_conf_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -613,23 +967,531 @@ func (x *Data_Milvus) GetPort() int32 {
 	return 0
 }
 
-var File_conf_proto protoreflect.FileDescriptor
+type LLM_Provider struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Name             string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Protocol         string                 `protobuf:"bytes,2,opt,name=protocol,proto3" json:"protocol,omitempty"` // openai（默认）、anthropic、ollama
+	ApiKey           string                 `protobuf:"bytes,3,opt,name=api_key,json=apiKey,proto3" json:"api_key,omitempty"`
+	BaseUrl          string                 `protobuf:"bytes,4,opt,name=base_url,json=baseUrl,proto3" json:"base_url,omitempty"`
+	StructuredOutput string                 `protobuf:"bytes,5,opt,name=structured_output,json=structuredOutput,proto3" json:"structured_output,omitempty"` // 结构化输出方式：json_schema、json_object、none，为空时按协议与接口选择
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
 
-const file_conf_proto_rawDesc = "" +
-	"\n" +
-	"\n" +
-	"conf.proto\x12\x11jas.agent.conf.v1\"\x95\x01\n" +
-	"\tBootstrap\x121\n" +
+func (x *LLM_Provider) Reset() {
+	*x = LLM_Provider{}
+	mi := &file_conf_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LLM_Provider) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LLM_Provider) ProtoMessage() {}
+
+func (x *LLM_Provider) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LLM_Provider.ProtoReflect.Descriptor instead.
+func (*LLM_Provider) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{3, 0}
+}
+
+func (x *LLM_Provider) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *LLM_Provider) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *LLM_Provider) GetApiKey() string {
+	if x != nil {
+		return x.ApiKey
+	}
+	return ""
+}
+
+func (x *LLM_Provider) GetBaseUrl() string {
+	if x != nil {
+		return x.BaseUrl
+	}
+	return ""
+}
+
+func (x *LLM_Provider) GetStructuredOutput() string {
+	if x != nil {
+		return x.StructuredOutput
+	}
+	return ""
+}
+
+type LLM_ModelPrice struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Model         string                 `protobuf:"bytes,1,opt,name=model,proto3" json:"model,omitempty"`             // 模型名，可带服务商前缀
+	Prompt        float64                `protobuf:"fixed64,2,opt,name=prompt,proto3" json:"prompt,omitempty"`         // 每百万输入 token 的费用
+	Completion    float64                `protobuf:"fixed64,3,opt,name=completion,proto3" json:"completion,omitempty"` // 每百万输出 token 的费用
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LLM_ModelPrice) Reset() {
+	*x = LLM_ModelPrice{}
+	mi := &file_conf_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LLM_ModelPrice) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LLM_ModelPrice) ProtoMessage() {}
+
+func (x *LLM_ModelPrice) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LLM_ModelPrice.ProtoReflect.Descriptor instead.
+func (*LLM_ModelPrice) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{3, 1}
+}
+
+func (x *LLM_ModelPrice) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *LLM_ModelPrice) GetPrompt() float64 {
+	if x != nil {
+		return x.Prompt
+	}
+	return 0
+}
+
+func (x *LLM_ModelPrice) GetCompletion() float64 {
+	if x != nil {
+		return x.Completion
+	}
+	return 0
+}
+
+type LLM_TenantBudget struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Tenant         string                 `protobuf:"bytes,1,opt,name=tenant,proto3" json:"tenant,omitempty"`                                          // 租户ID
+	MaxDailyTokens int64                  `protobuf:"varint,2,opt,name=max_daily_tokens,json=maxDailyTokens,proto3" json:"max_daily_tokens,omitempty"` // 每日 token 上限，0 表示不限制
+	MaxDailyCost   float64                `protobuf:"fixed64,3,opt,name=max_daily_cost,json=maxDailyCost,proto3" json:"max_daily_cost,omitempty"`      // 每日费用上限，0 表示不限制
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *LLM_TenantBudget) Reset() {
+	*x = LLM_TenantBudget{}
+	mi := &file_conf_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LLM_TenantBudget) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LLM_TenantBudget) ProtoMessage() {}
+
+func (x *LLM_TenantBudget) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LLM_TenantBudget.ProtoReflect.Descriptor instead.
+func (*LLM_TenantBudget) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{3, 2}
+}
+
+func (x *LLM_TenantBudget) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
+func (x *LLM_TenantBudget) GetMaxDailyTokens() int64 {
+	if x != nil {
+		return x.MaxDailyTokens
+	}
+	return 0
+}
+
+func (x *LLM_TenantBudget) GetMaxDailyCost() float64 {
+	if x != nil {
+		return x.MaxDailyCost
+	}
+	return 0
+}
+
+type RCA_Server struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Address         string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`                                        // gRPC 服务地址，默认 :50051
+	MetricsAddress  string                 `protobuf:"bytes,2,opt,name=metrics_address,json=metricsAddress,proto3" json:"metrics_address,omitempty"`    // 指标服务地址，默认 :2112
+	GracefulTimeout string                 `protobuf:"bytes,3,opt,name=graceful_timeout,json=gracefulTimeout,proto3" json:"graceful_timeout,omitempty"` // 优雅关闭超时，默认 10s
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RCA_Server) Reset() {
+	*x = RCA_Server{}
+	mi := &file_conf_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RCA_Server) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RCA_Server) ProtoMessage() {}
+
+func (x *RCA_Server) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RCA_Server.ProtoReflect.Descriptor instead.
+func (*RCA_Server) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{5, 0}
+}
+
+func (x *RCA_Server) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *RCA_Server) GetMetricsAddress() string {
+	if x != nil {
+		return x.MetricsAddress
+	}
+	return ""
+}
+
+func (x *RCA_Server) GetGracefulTimeout() string {
+	if x != nil {
+		return x.GracefulTimeout
+	}
+	return ""
+}
+
+type RCA_Clients struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Core          *RCA_Clients_Core      `protobuf:"bytes,1,opt,name=core,proto3" json:"core,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RCA_Clients) Reset() {
+	*x = RCA_Clients{}
+	mi := &file_conf_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RCA_Clients) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RCA_Clients) ProtoMessage() {}
+
+func (x *RCA_Clients) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RCA_Clients.ProtoReflect.Descriptor instead.
+func (*RCA_Clients) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{5, 1}
+}
+
+func (x *RCA_Clients) GetCore() *RCA_Clients_Core {
+	if x != nil {
+		return x.Core
+	}
+	return nil
+}
+
+type RCA_Weaviate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Endpoint      string                 `protobuf:"bytes,1,opt,name=endpoint,proto3" json:"endpoint,omitempty"`           // Weaviate 端点
+	ApiKey        string                 `protobuf:"bytes,2,opt,name=api_key,json=apiKey,proto3" json:"api_key,omitempty"` // API 密钥
+	Timeout       string                 `protobuf:"bytes,3,opt,name=timeout,proto3" json:"timeout,omitempty"`             // 请求超时，默认 5s
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RCA_Weaviate) Reset() {
+	*x = RCA_Weaviate{}
+	mi := &file_conf_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RCA_Weaviate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RCA_Weaviate) ProtoMessage() {}
+
+func (x *RCA_Weaviate) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RCA_Weaviate.ProtoReflect.Descriptor instead.
+func (*RCA_Weaviate) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{5, 2}
+}
+
+func (x *RCA_Weaviate) GetEndpoint() string {
+	if x != nil {
+		return x.Endpoint
+	}
+	return ""
+}
+
+func (x *RCA_Weaviate) GetApiKey() string {
+	if x != nil {
+		return x.ApiKey
+	}
+	return ""
+}
+
+func (x *RCA_Weaviate) GetTimeout() string {
+	if x != nil {
+		return x.Timeout
+	}
+	return ""
+}
+
+type RCA_Anomaly struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	DefaultThreshold float64                `protobuf:"fixed64,1,opt,name=default_threshold,json=defaultThreshold,proto3" json:"default_threshold,omitempty"` // 默认异常阈值，默认 2.5
+	MetricThreshold  float64                `protobuf:"fixed64,2,opt,name=metric_threshold,json=metricThreshold,proto3" json:"metric_threshold,omitempty"`    // 指标异常阈值，默认 2.5
+	LogThreshold     float64                `protobuf:"fixed64,3,opt,name=log_threshold,json=logThreshold,proto3" json:"log_threshold,omitempty"`             // 日志异常阈值，默认 3.0
+	TraceThreshold   float64                `protobuf:"fixed64,4,opt,name=trace_threshold,json=traceThreshold,proto3" json:"trace_threshold,omitempty"`       // 追踪异常阈值，默认 2.0
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *RCA_Anomaly) Reset() {
+	*x = RCA_Anomaly{}
+	mi := &file_conf_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RCA_Anomaly) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RCA_Anomaly) ProtoMessage() {}
+
+func (x *RCA_Anomaly) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RCA_Anomaly.ProtoReflect.Descriptor instead.
+func (*RCA_Anomaly) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{5, 3}
+}
+
+func (x *RCA_Anomaly) GetDefaultThreshold() float64 {
+	if x != nil {
+		return x.DefaultThreshold
+	}
+	return 0
+}
+
+func (x *RCA_Anomaly) GetMetricThreshold() float64 {
+	if x != nil {
+		return x.MetricThreshold
+	}
+	return 0
+}
+
+func (x *RCA_Anomaly) GetLogThreshold() float64 {
+	if x != nil {
+		return x.LogThreshold
+	}
+	return 0
+}
+
+func (x *RCA_Anomaly) GetTraceThreshold() float64 {
+	if x != nil {
+		return x.TraceThreshold
+	}
+	return 0
+}
+
+type RCA_Clients_Core struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseUrl       string                 `protobuf:"bytes,1,opt,name=base_url,json=baseUrl,proto3" json:"base_url,omitempty"` // mirador-core 基础 URL
+	ApiKey        string                 `protobuf:"bytes,2,opt,name=api_key,json=apiKey,proto3" json:"api_key,omitempty"`    // API 密钥
+	Timeout       string                 `protobuf:"bytes,3,opt,name=timeout,proto3" json:"timeout,omitempty"`                // 请求超时，默认 5s
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RCA_Clients_Core) Reset() {
+	*x = RCA_Clients_Core{}
+	mi := &file_conf_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RCA_Clients_Core) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RCA_Clients_Core) ProtoMessage() {}
+
+func (x *RCA_Clients_Core) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RCA_Clients_Core.ProtoReflect.Descriptor instead.
+func (*RCA_Clients_Core) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{5, 1, 0}
+}
+
+func (x *RCA_Clients_Core) GetBaseUrl() string {
+	if x != nil {
+		return x.BaseUrl
+	}
+	return ""
+}
+
+func (x *RCA_Clients_Core) GetApiKey() string {
+	if x != nil {
+		return x.ApiKey
+	}
+	return ""
+}
+
+func (x *RCA_Clients_Core) GetTimeout() string {
+	if x != nil {
+		return x.Timeout
+	}
+	return ""
+}
+
+var File_conf_proto protoreflect.FileDescriptor
+
+const file_conf_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"conf.proto\x12\x11jas.agent.conf.v1\"\xbf\x01\n" +
+	"\tBootstrap\x121\n" +
 	"\x06server\x18\x01 \x01(\v2\x19.jas.agent.conf.v1.ServerR\x06server\x12+\n" +
 	"\x04data\x18\x02 \x01(\v2\x17.jas.agent.conf.v1.DataR\x04data\x12(\n" +
-	"\x03llm\x18\x03 \x01(\v2\x16.jas.agent.conf.v1.LLMR\x03llm\"\xa8\x01\n" +
+	"\x03llm\x18\x03 \x01(\v2\x16.jas.agent.conf.v1.LLMR\x03llm\x12(\n" +
+	"\x03rca\x18\x04 \x01(\v2\x16.jas.agent.conf.v1.RCAR\x03rca\"\xef\x05\n" +
 	"\x06Server\x122\n" +
 	"\x04http\x18\x01 \x01(\v2\x1e.jas.agent.conf.v1.Server.HTTPR\x04http\x122\n" +
-	"\x04grpc\x18\x02 \x01(\v2\x1e.jas.agent.conf.v1.Server.GRPCR\x04grpc\x1a\x1a\n" +
+	"\x04grpc\x18\x02 \x01(\v2\x1e.jas.agent.conf.v1.Server.GRPCR\x04grpc\x128\n" +
+	"\x06worker\x18\x03 \x01(\v2 .jas.agent.conf.v1.Server.WorkerR\x06worker\x12/\n" +
+	"\x03mcp\x18\x04 \x01(\v2\x1d.jas.agent.conf.v1.Server.MCPR\x03mcp\x1a\x1a\n" +
 	"\x04HTTP\x12\x12\n" +
 	"\x04addr\x18\x01 \x01(\tR\x04addr\x1a\x1a\n" +
 	"\x04GRPC\x12\x12\n" +
-	"\x04addr\x18\x01 \x01(\tR\x04addr\"\x81\x05\n" +
+	"\x04addr\x18\x01 \x01(\tR\x04addr\x1a\xed\x01\n" +
+	"\x06Worker\x12 \n" +
+	"\vconcurrency\x18\x01 \x01(\x05R\vconcurrency\x12#\n" +
+	"\rpoll_interval\x18\x02 \x01(\tR\fpollInterval\x12%\n" +
+	"\x0ewebhook_secret\x18\x03 \x01(\tR\rwebhookSecret\x12'\n" +
+	"\x0fwebhook_timeout\x18\x04 \x01(\tR\x0ewebhookTimeout\x12#\n" +
+	"\rwebhook_hosts\x18\x05 \x03(\tR\fwebhookHosts\x12'\n" +
+	"\x0fwebhook_schemes\x18\x06 \x03(\tR\x0ewebhookSchemes\x1a\xe9\x01\n" +
+	"\x03MCP\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12;\n" +
+	"\x06tokens\x18\x03 \x03(\v2#.jas.agent.conf.v1.Server.MCP.TokenR\x06tokens\x12\x14\n" +
+	"\x05tools\x18\x04 \x03(\tR\x05tools\x1aa\n" +
+	"\x05Token\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\x12\x16\n" +
+	"\x06agents\x18\x03 \x03(\x05R\x06agents\x12\x16\n" +
+	"\x06tenant\x18\x04 \x01(\tR\x06tenant\"\x81\x05\n" +
 	"\x04Data\x12<\n" +
 	"\bdatabase\x18\x01 \x01(\v2 .jas.agent.conf.v1.Data.DatabaseR\bdatabase\x12:\n" +
 	"\tknowledge\x18\x02 \x01(\v2\x1c.jas.agent.conf.v1.KnowledgeR\tknowledge\x123\n" +
@@ -650,15 +1512,61 @@ const file_conf_proto_rawDesc = "" +
 	"\x04host\x18\x01 \x01(\tR\x04host\x12\x1a\n" +
 	"\busername\x18\x02 \x01(\tR\busername\x12\x1a\n" +
 	"\bpassword\x18\x03 \x01(\tR\bpassword\x12\x12\n" +
-	"\x04port\x18\x04 \x01(\x05R\x04port\"O\n" +
+	"\x04port\x18\x04 \x01(\x05R\x04port\"\xe1\x05\n" +
 	"\x03LLM\x12\x17\n" +
 	"\aapi_key\x18\x01 \x01(\tR\x06apiKey\x12\x19\n" +
 	"\bbase_url\x18\x02 \x01(\tR\abaseUrl\x12\x14\n" +
-	"\x05model\x18\x03 \x01(\tR\x05model\"C\n" +
+	"\x05model\x18\x03 \x01(\tR\x05model\x12=\n" +
+	"\tproviders\x18\x04 \x03(\v2\x1f.jas.agent.conf.v1.LLM.ProviderR\tproviders\x12)\n" +
+	"\x10default_provider\x18\x05 \x01(\tR\x0fdefaultProvider\x12;\n" +
+	"\apricing\x18\x06 \x03(\v2!.jas.agent.conf.v1.LLM.ModelPriceR\apricing\x12+\n" +
+	"\x11structured_output\x18\a \x01(\tR\x10structuredOutput\x12J\n" +
+	"\x0etenant_budgets\x18\b \x03(\v2#.jas.agent.conf.v1.LLM.TenantBudgetR\rtenantBudgets\x1a\x9b\x01\n" +
+	"\bProvider\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
+	"\bprotocol\x18\x02 \x01(\tR\bprotocol\x12\x17\n" +
+	"\aapi_key\x18\x03 \x01(\tR\x06apiKey\x12\x19\n" +
+	"\bbase_url\x18\x04 \x01(\tR\abaseUrl\x12+\n" +
+	"\x11structured_output\x18\x05 \x01(\tR\x10structuredOutput\x1aZ\n" +
+	"\n" +
+	"ModelPrice\x12\x14\n" +
+	"\x05model\x18\x01 \x01(\tR\x05model\x12\x16\n" +
+	"\x06prompt\x18\x02 \x01(\x01R\x06prompt\x12\x1e\n" +
+	"\n" +
+	"completion\x18\x03 \x01(\x01R\n" +
+	"completion\x1av\n" +
+	"\fTenantBudget\x12\x16\n" +
+	"\x06tenant\x18\x01 \x01(\tR\x06tenant\x12(\n" +
+	"\x10max_daily_tokens\x18\x02 \x01(\x03R\x0emaxDailyTokens\x12$\n" +
+	"\x0emax_daily_cost\x18\x03 \x01(\x01R\fmaxDailyCost\"C\n" +
 	"\tKnowledge\x12\x1d\n" +
 	"\n" +
 	"upload_dir\x18\x01 \x01(\tR\tuploadDir\x12\x17\n" +
-	"\aapi_key\x18\x02 \x01(\tR\x06apiKeyB\x1eZ\x1cjas-agent/internal/conf;confb\x06proto3"
+	"\aapi_key\x18\x02 \x01(\tR\x06apiKey\"\x8d\x06\n" +
+	"\x03RCA\x125\n" +
+	"\x06server\x18\x01 \x01(\v2\x1d.jas.agent.conf.v1.RCA.ServerR\x06server\x128\n" +
+	"\aclients\x18\x02 \x01(\v2\x1e.jas.agent.conf.v1.RCA.ClientsR\aclients\x12;\n" +
+	"\bweaviate\x18\x03 \x01(\v2\x1f.jas.agent.conf.v1.RCA.WeaviateR\bweaviate\x128\n" +
+	"\aanomaly\x18\x04 \x01(\v2\x1e.jas.agent.conf.v1.RCA.AnomalyR\aanomaly\x1av\n" +
+	"\x06Server\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\x12'\n" +
+	"\x0fmetrics_address\x18\x02 \x01(\tR\x0emetricsAddress\x12)\n" +
+	"\x10graceful_timeout\x18\x03 \x01(\tR\x0fgracefulTimeout\x1a\x98\x01\n" +
+	"\aClients\x127\n" +
+	"\x04core\x18\x01 \x01(\v2#.jas.agent.conf.v1.RCA.Clients.CoreR\x04core\x1aT\n" +
+	"\x04Core\x12\x19\n" +
+	"\bbase_url\x18\x01 \x01(\tR\abaseUrl\x12\x17\n" +
+	"\aapi_key\x18\x02 \x01(\tR\x06apiKey\x12\x18\n" +
+	"\atimeout\x18\x03 \x01(\tR\atimeout\x1aY\n" +
+	"\bWeaviate\x12\x1a\n" +
+	"\bendpoint\x18\x01 \x01(\tR\bendpoint\x12\x17\n" +
+	"\aapi_key\x18\x02 \x01(\tR\x06apiKey\x12\x18\n" +
+	"\atimeout\x18\x03 \x01(\tR\atimeout\x1a\xaf\x01\n" +
+	"\aAnomaly\x12+\n" +
+	"\x11default_threshold\x18\x01 \x01(\x01R\x10defaultThreshold\x12)\n" +
+	"\x10metric_threshold\x18\x02 \x01(\x01R\x0fmetricThreshold\x12#\n" +
+	"\rlog_threshold\x18\x03 \x01(\x01R\flogThreshold\x12'\n" +
+	"\x0ftrace_threshold\x18\x04 \x01(\x01R\x0etraceThresholdB\x1eZ\x1cjas-agent/internal/conf;confb\x06proto3"
 
 var (
 	file_conf_proto_rawDescOnce sync.Once
@@ -672,34 +1580,58 @@ func file_conf_proto_rawDescGZIP() []byte {
 	return file_conf_proto_rawDescData
 }
 
-var file_conf_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_conf_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
 var file_conf_proto_goTypes = []any{
-	(*Bootstrap)(nil),     // 0: jas.agent.conf.v1.Bootstrap
-	(*Server)(nil),        // 1: jas.agent.conf.v1.Server
-	(*Data)(nil),          // 2: jas.agent.conf.v1.Data
-	(*LLM)(nil),           // 3: jas.agent.conf.v1.LLM
-	(*Knowledge)(nil),     // 4: jas.agent.conf.v1.Knowledge
-	(*Server_HTTP)(nil),   // 5: jas.agent.conf.v1.Server.HTTP
-	(*Server_GRPC)(nil),   // 6: jas.agent.conf.v1.Server.GRPC
-	(*Data_Database)(nil), // 7: jas.agent.conf.v1.Data.Database
-	(*Data_Neo4J)(nil),    // 8: jas.agent.conf.v1.Data.Neo4j
-	(*Data_Milvus)(nil),   // 9: jas.agent.conf.v1.Data.Milvus
+	(*Bootstrap)(nil),        // 0: jas.agent.conf.v1.Bootstrap
+	(*Server)(nil),           // 1: jas.agent.conf.v1.Server
+	(*Data)(nil),             // 2: jas.agent.conf.v1.Data
+	(*LLM)(nil),              // 3: jas.agent.conf.v1.LLM
+	(*Knowledge)(nil),        // 4: jas.agent.conf.v1.Knowledge
+	(*RCA)(nil),              // 5: jas.agent.conf.v1.RCA
+	(*Server_HTTP)(nil),      // 6: jas.agent.conf.v1.Server.HTTP
+	(*Server_GRPC)(nil),      // 7: jas.agent.conf.v1.Server.GRPC
+	(*Server_Worker)(nil),    // 8: jas.agent.conf.v1.Server.Worker
+	(*Server_MCP)(nil),       // 9: jas.agent.conf.v1.Server.MCP
+	(*Server_MCP_Token)(nil), // 10: jas.agent.conf.v1.Server.MCP.Token
+	(*Data_Database)(nil),    // 11: jas.agent.conf.v1.Data.Database
+	(*Data_Neo4J)(nil),       // 12: jas.agent.conf.v1.Data.Neo4j
+	(*Data_Milvus)(nil),      // 13: jas.agent.conf.v1.Data.Milvus
+	(*LLM_Provider)(nil),     // 14: jas.agent.conf.v1.LLM.Provider
+	(*LLM_ModelPrice)(nil),   // 15: jas.agent.conf.v1.LLM.ModelPrice
+	(*LLM_TenantBudget)(nil), // 16: jas.agent.conf.v1.LLM.TenantBudget
+	(*RCA_Server)(nil),       // 17: jas.agent.conf.v1.RCA.Server
+	(*RCA_Clients)(nil),      // 18: jas.agent.conf.v1.RCA.Clients
+	(*RCA_Weaviate)(nil),     // 19: jas.agent.conf.v1.RCA.Weaviate
+	(*RCA_Anomaly)(nil),      // 20: jas.agent.conf.v1.RCA.Anomaly
+	(*RCA_Clients_Core)(nil), // 21: jas.agent.conf.v1.RCA.Clients.Core
 }
 var file_conf_proto_depIdxs = []int32{
-	1, // 0: jas.agent.conf.v1.Bootstrap.server:type_name -> jas.agent.conf.v1.Server
-	2, // 1: jas.agent.conf.v1.Bootstrap.data:type_name -> jas.agent.conf.v1.Data
-	3, // 2: jas.agent.conf.v1.Bootstrap.llm:type_name -> jas.agent.conf.v1.LLM
-	5, // 3: jas.agent.conf.v1.Server.http:type_name -> jas.agent.conf.v1.Server.HTTP
-	6, // 4: jas.agent.conf.v1.Server.grpc:type_name -> jas.agent.conf.v1.Server.GRPC
-	7, // 5: jas.agent.conf.v1.Data.database:type_name -> jas.agent.conf.v1.Data.Database
-	4, // 6: jas.agent.conf.v1.Data.knowledge:type_name -> jas.agent.conf.v1.Knowledge
-	8, // 7: jas.agent.conf.v1.Data.neo4j:type_name -> jas.agent.conf.v1.Data.Neo4j
-	9, // 8: jas.agent.conf.v1.Data.milvus:type_name -> jas.agent.conf.v1.Data.Milvus
-	9, // [9:9] is the sub-list for method output_type
-	9, // [9:9] is the sub-list for method input_type
-	9, // [9:9] is the sub-list for extension type_name
-	9, // [9:9] is the sub-list for extension extendee
-	0, // [0:9] is the sub-list for field type_name
+	1,  // 0: jas.agent.conf.v1.Bootstrap.server:type_name -> jas.agent.conf.v1.Server
+	2,  // 1: jas.agent.conf.v1.Bootstrap.data:type_name -> jas.agent.conf.v1.Data
+	3,  // 2: jas.agent.conf.v1.Bootstrap.llm:type_name -> jas.agent.conf.v1.LLM
+	5,  // 3: jas.agent.conf.v1.Bootstrap.rca:type_name -> jas.agent.conf.v1.RCA
+	6,  // 4: jas.agent.conf.v1.Server.http:type_name -> jas.agent.conf.v1.Server.HTTP
+	7,  // 5: jas.agent.conf.v1.Server.grpc:type_name -> jas.agent.conf.v1.Server.GRPC
+	8,  // 6: jas.agent.conf.v1.Server.worker:type_name -> jas.agent.conf.v1.Server.Worker
+	9,  // 7: jas.agent.conf.v1.Server.mcp:type_name -> jas.agent.conf.v1.Server.MCP
+	11, // 8: jas.agent.conf.v1.Data.database:type_name -> jas.agent.conf.v1.Data.Database
+	4,  // 9: jas.agent.conf.v1.Data.knowledge:type_name -> jas.agent.conf.v1.Knowledge
+	12, // 10: jas.agent.conf.v1.Data.neo4j:type_name -> jas.agent.conf.v1.Data.Neo4j
+	13, // 11: jas.agent.conf.v1.Data.milvus:type_name -> jas.agent.conf.v1.Data.Milvus
+	14, // 12: jas.agent.conf.v1.LLM.providers:type_name -> jas.agent.conf.v1.LLM.Provider
+	15, // 13: jas.agent.conf.v1.LLM.pricing:type_name -> jas.agent.conf.v1.LLM.ModelPrice
+	16, // 14: jas.agent.conf.v1.LLM.tenant_budgets:type_name -> jas.agent.conf.v1.LLM.TenantBudget
+	17, // 15: jas.agent.conf.v1.RCA.server:type_name -> jas.agent.conf.v1.RCA.Server
+	18, // 16: jas.agent.conf.v1.RCA.clients:type_name -> jas.agent.conf.v1.RCA.Clients
+	19, // 17: jas.agent.conf.v1.RCA.weaviate:type_name -> jas.agent.conf.v1.RCA.Weaviate
+	20, // 18: jas.agent.conf.v1.RCA.anomaly:type_name -> jas.agent.conf.v1.RCA.Anomaly
+	10, // 19: jas.agent.conf.v1.Server.MCP.tokens:type_name -> jas.agent.conf.v1.Server.MCP.Token
+	21, // 20: jas.agent.conf.v1.RCA.Clients.core:type_name -> jas.agent.conf.v1.RCA.Clients.Core
+	21, // [21:21] is the sub-list for method output_type
+	21, // [21:21] is the sub-list for method input_type
+	21, // [21:21] is the sub-list for extension type_name
+	21, // [21:21] is the sub-list for extension extendee
+	0,  // [0:21] is the sub-list for field type_name
 }
 
 func init() { file_conf_proto_init() }
@@ -713,7 +1645,7 @@ func file_conf_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_conf_proto_rawDesc), len(file_conf_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   10,
+			NumMessages:   22,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
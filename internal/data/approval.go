@@ -0,0 +1,159 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"jas-agent/agent/agent"
+	"jas-agent/internal/biz"
+
+	"gorm.io/gorm"
+)
+
+type approvalRepo struct {
+	data *Data
+}
+
+func NewApprovalRepo(data *Data) biz.ApprovalRepo {
+	return &approvalRepo{data: data}
+}
+
+// enabled 数据库未配置时不持久化审批，审批只能由执行运行的实例处理
+func (r *approvalRepo) enabled() bool {
+	return r.data != nil && r.data.DB() != nil
+}
+
+func (r *approvalRepo) CreateApproval(ctx context.Context, approval *biz.Approval) error {
+	if !r.enabled() {
+		return nil
+	}
+	model := &ApprovalModel{
+		ID:         approval.ID,
+		RunID:      approval.RunID,
+		ToolCallID: approval.ToolCallID,
+		ToolName:   approval.ToolName,
+		Arguments:  approval.Arguments,
+		Status:     approval.Status,
+	}
+	if err := r.data.DB().WithContext(ctx).Create(model).Error; err != nil {
+		return fmt.Errorf("create approval: %w", err)
+	}
+	approval.CreatedAt = model.CreatedAt
+	return nil
+}
+
+func (r *approvalRepo) GetApproval(ctx context.Context, id string) (*biz.Approval, error) {
+	if !r.enabled() {
+		return nil, fmt.Errorf("%w: %s", biz.ErrApprovalNotFound, id)
+	}
+	var model ApprovalModel
+	if err := r.data.DB().WithContext(ctx).Where("id = ?", id).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", biz.ErrApprovalNotFound, id)
+		}
+		return nil, fmt.Errorf("query approval: %w", err)
+	}
+	return model.ToBiz()
+}
+
+func (r *approvalRepo) ResolveApproval(ctx context.Context, runID, id string, decision agent.ApprovalDecision) (bool, error) {
+	if !r.enabled() {
+		return false, nil
+	}
+	data, err := json.Marshal(decision)
+	if err != nil {
+		return false, fmt.Errorf("marshal approval decision: %w", err)
+	}
+	result := r.data.DB().WithContext(ctx).Model(&ApprovalModel{}).
+		Where("id = ? AND run_id = ? AND status = ?", id, runID, biz.ApprovalPending).
+		Updates(map[string]any{
+			"status":      biz.ApprovalResolved,
+			"decision":    string(data),
+			"resolved_at": time.Now(),
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("resolve approval: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *approvalRepo) ExpireApprovals(ctx context.Context, runID string, ids ...string) error {
+	if !r.enabled() {
+		return nil
+	}
+	db := r.data.DB().WithContext(ctx).Model(&ApprovalModel{}).
+		Where("run_id = ? AND status = ?", runID, biz.ApprovalPending)
+	if len(ids) > 0 {
+		db = db.Where("id IN ?", ids)
+	}
+	if err := db.Update("status", biz.ApprovalExpired).Error; err != nil {
+		return fmt.Errorf("expire approvals: %w", err)
+	}
+	return nil
+}
+
+func (r *approvalRepo) ListApprovals(ctx context.Context, runID, status string) ([]*biz.Approval, error) {
+	if !r.enabled() {
+		return nil, nil
+	}
+	var models []ApprovalModel
+	err := r.data.DB().WithContext(ctx).
+		Where("run_id = ? AND status = ?", runID, status).
+		Order("created_at ASC").
+		Find(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("list approvals: %w", err)
+	}
+	approvals := make([]*biz.Approval, 0, len(models))
+	for _, model := range models {
+		approval, err := model.ToBiz()
+		if err != nil {
+			return nil, err
+		}
+		approvals = append(approvals, approval)
+	}
+	return approvals, nil
+}
+
+type ApprovalModel struct {
+	ID         string     `gorm:"column:id;primaryKey"`
+	RunID      string     `gorm:"column:run_id"`
+	ToolCallID string     `gorm:"column:tool_call_id"`
+	ToolName   string     `gorm:"column:tool_name"`
+	Arguments  string     `gorm:"column:arguments"`
+	Status     string     `gorm:"column:status"`
+	Decision   *string    `gorm:"column:decision"`
+	CreatedAt  time.Time  `gorm:"column:created_at"`
+	ResolvedAt *time.Time `gorm:"column:resolved_at"`
+}
+
+func (ApprovalModel) TableName() string {
+	return "agent_run_approvals"
+}
+
+func (m ApprovalModel) ToBiz() (*biz.Approval, error) {
+	approval := &biz.Approval{
+		ApprovalRequest: agent.ApprovalRequest{
+			ID:         m.ID,
+			ToolCallID: m.ToolCallID,
+			ToolName:   m.ToolName,
+			Arguments:  m.Arguments,
+		},
+		RunID:     m.RunID,
+		Status:    m.Status,
+		CreatedAt: m.CreatedAt,
+	}
+	if m.ResolvedAt != nil {
+		approval.ResolvedAt = *m.ResolvedAt
+	}
+	if m.Decision != nil {
+		approval.Decision = &agent.ApprovalDecision{}
+		if err := json.Unmarshal([]byte(*m.Decision), approval.Decision); err != nil {
+			return nil, fmt.Errorf("parse approval %s decision: %w", m.ID, err)
+		}
+	}
+	return approval, nil
+}
@@ -21,6 +21,11 @@ var ProviderSet = wire.NewSet(
 	NewMCPRepo,
 	NewKnowledgeBaseRepo,
 	NewDocumentRepo,
+	NewSessionRepo,
+	NewTokenUsageRepo,
+	NewRunRepo,
+	NewRunQueue,
+	NewApprovalRepo,
 )
 
 // Data 聚合数据访问资源。
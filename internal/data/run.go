@@ -0,0 +1,382 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"jas-agent/agent/agent"
+	"jas-agent/internal/biz"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type runRepo struct {
+	data *Data
+}
+
+func NewRunRepo(data *Data) biz.RunRepo {
+	return &runRepo{data: data}
+}
+
+// enabled 数据库未配置时不持久化运行记录，运行照常执行但无法查询与恢复
+func (r *runRepo) enabled() bool {
+	return r.data != nil && r.data.DB() != nil
+}
+
+func (r *runRepo) CreateRun(ctx context.Context, run *biz.Run) error {
+	if !r.enabled() {
+		return nil
+	}
+	return createRun(ctx, r.data.DB(), run)
+}
+
+func createRun(ctx context.Context, db *gorm.DB, run *biz.Run) error {
+	model := &RunModel{
+		ID:         run.ID,
+		AgentID:    run.AgentID,
+		SessionID:  run.SessionID,
+		Query:      run.Query,
+		Request:    string(run.Request),
+		Status:     run.Status,
+		WebhookURL: run.WebhookURL,
+		Owner:      run.Owner,
+	}
+	if !run.HeartbeatAt.IsZero() {
+		model.HeartbeatAt = &run.HeartbeatAt
+	}
+	if err := db.WithContext(ctx).Create(model).Error; err != nil {
+		return fmt.Errorf("create run: %w", err)
+	}
+	run.CreatedAt = model.CreatedAt
+	run.UpdatedAt = model.UpdatedAt
+	return nil
+}
+
+func (r *runRepo) GetRun(ctx context.Context, id string) (*biz.Run, error) {
+	if !r.enabled() {
+		return nil, errDBNotConfigured
+	}
+	var model RunModel
+	if err := r.data.DB().WithContext(ctx).Where("id = ?", id).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", biz.ErrRunNotFound, id)
+		}
+		return nil, fmt.Errorf("query run: %w", err)
+	}
+	return model.ToBiz()
+}
+
+func (r *runRepo) SaveCheckpoint(ctx context.Context, id string, step int, checkpoint []byte) error {
+	if !r.enabled() {
+		return nil
+	}
+	err := r.data.DB().WithContext(ctx).Model(&RunModel{}).Where("id = ?", id).Updates(map[string]any{
+		"current_step": step,
+		"checkpoint":   string(checkpoint),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("save run checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (r *runRepo) UpdateRun(ctx context.Context, run *biz.Run) error {
+	if !r.enabled() {
+		return nil
+	}
+	var finalResult *string
+	if run.FinalResult != nil {
+		data, err := json.Marshal(run.FinalResult)
+		if err != nil {
+			return fmt.Errorf("marshal run final result: %w", err)
+		}
+		value := string(data)
+		finalResult = &value
+	}
+	db := r.data.DB().WithContext(ctx).Model(&RunModel{}).Where("id = ?", run.ID)
+	if run.Owner != "" {
+		db = db.Where("owner = ?", run.Owner)
+	}
+	err := db.Updates(map[string]any{
+		"status":       run.Status,
+		"current_step": run.CurrentStep,
+		"result":       run.Result,
+		"final_result": finalResult,
+		"error":        run.Error,
+		"resume_count": run.ResumeCount,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("update run: %w", err)
+	}
+	return nil
+}
+
+func (r *runRepo) ListRuns(ctx context.Context, status string) ([]*biz.Run, error) {
+	if !r.enabled() {
+		return nil, nil
+	}
+	var models []RunModel
+	if err := r.data.DB().WithContext(ctx).Where("status = ?", status).Order("created_at ASC").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("list runs: %w", err)
+	}
+	runs := make([]*biz.Run, 0, len(models))
+	for _, model := range models {
+		run, err := model.ToBiz()
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+func (r *runRepo) ClaimRun(ctx context.Context, id, owner string, statuses []string, staleBefore time.Time) (bool, error) {
+	if !r.enabled() {
+		return false, errDBNotConfigured
+	}
+	result := r.staleRuns(ctx, id, statuses, staleBefore).Updates(map[string]any{
+		"status":           string(agent.RunningState),
+		"owner":            owner,
+		"heartbeat_at":     time.Now(),
+		"cancel_requested": false,
+	})
+	if result.Error != nil {
+		return false, fmt.Errorf("claim run: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *runRepo) CancelRun(ctx context.Context, id string, statuses []string, staleBefore time.Time) (bool, error) {
+	if !r.enabled() {
+		return false, errDBNotConfigured
+	}
+	result := r.staleRuns(ctx, id, statuses, staleBefore).Update("status", string(agent.CanceledState))
+	if result.Error != nil {
+		return false, fmt.Errorf("cancel run: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// staleRuns 状态在 statuses 中的运行，状态为 Running 时原执行实例的心跳需早于 staleBefore
+func (r *runRepo) staleRuns(ctx context.Context, id string, statuses []string, staleBefore time.Time) *gorm.DB {
+	return r.data.DB().WithContext(ctx).Model(&RunModel{}).
+		Where("id = ? AND status IN ?", id, statuses).
+		Where("status <> ? OR owner = '' OR heartbeat_at IS NULL OR heartbeat_at < ?", string(agent.RunningState), staleBefore)
+}
+
+func (r *runRepo) RequestCancel(ctx context.Context, id string) (bool, error) {
+	if !r.enabled() {
+		return false, errDBNotConfigured
+	}
+	result := r.data.DB().WithContext(ctx).Model(&RunModel{}).
+		Where("id = ? AND status = ?", id, string(agent.RunningState)).
+		Update("cancel_requested", true)
+	if result.Error != nil {
+		return false, fmt.Errorf("request run cancel: %w", result.Error)
+	}
+	// 已请求过取消的运行不会被更新，同样视为成功
+	if result.RowsAffected == 0 {
+		var count int64
+		err := r.data.DB().WithContext(ctx).Model(&RunModel{}).
+			Where("id = ? AND status = ? AND cancel_requested = ?", id, string(agent.RunningState), true).
+			Count(&count).Error
+		if err != nil {
+			return false, fmt.Errorf("request run cancel: %w", err)
+		}
+		return count == 1, nil
+	}
+	return true, nil
+}
+
+func (r *runRepo) Heartbeat(ctx context.Context, id, owner string) (bool, error) {
+	if !r.enabled() {
+		return false, nil
+	}
+	db := r.data.DB().WithContext(ctx)
+	result := db.Model(&RunModel{}).
+		Where("id = ? AND owner = ? AND status = ?", id, owner, string(agent.RunningState)).
+		Update("heartbeat_at", time.Now())
+	if result.Error != nil {
+		return false, fmt.Errorf("heartbeat run: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, fmt.Errorf("%w: %s", biz.ErrRunNotOwned, id)
+	}
+	var model RunModel
+	if err := db.Select("cancel_requested").Where("id = ?", id).First(&model).Error; err != nil {
+		return false, fmt.Errorf("heartbeat run: %w", err)
+	}
+	return model.CancelRequested, nil
+}
+
+func (r *runRepo) AddRunEvent(ctx context.Context, event *biz.RunEvent) error {
+	if !r.enabled() {
+		return nil
+	}
+	model := &RunEventModel{
+		RunID:   event.RunID,
+		Type:    event.Type,
+		Content: event.Content,
+		Step:    event.Step,
+	}
+	if err := r.data.DB().WithContext(ctx).Create(model).Error; err != nil {
+		return fmt.Errorf("create run event: %w", err)
+	}
+	event.Seq = model.ID
+	event.CreatedAt = model.CreatedAt
+	return nil
+}
+
+func (r *runRepo) ListRunEvents(ctx context.Context, runID string, afterSeq int64, limit int) ([]*biz.RunEvent, error) {
+	if !r.enabled() {
+		return nil, errDBNotConfigured
+	}
+	var models []RunEventModel
+	err := r.data.DB().WithContext(ctx).
+		Where("run_id = ? AND id > ?", runID, afterSeq).
+		Order("id ASC").
+		Limit(limit).
+		Find(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("list run events: %w", err)
+	}
+	events := make([]*biz.RunEvent, 0, len(models))
+	for _, model := range models {
+		events = append(events, &biz.RunEvent{
+			Seq:       model.ID,
+			RunID:     model.RunID,
+			Type:      model.Type,
+			Content:   model.Content,
+			Step:      model.Step,
+			CreatedAt: model.CreatedAt,
+		})
+	}
+	return events, nil
+}
+
+// runQueue 基于 agent_runs 表的运行队列，通过 SELECT ... FOR UPDATE SKIP LOCKED 保证多个 worker 不会领取同一运行
+type runQueue struct {
+	data *Data
+}
+
+func NewRunQueue(data *Data) biz.RunQueue {
+	return &runQueue{data: data}
+}
+
+func (q *runQueue) Enqueue(ctx context.Context, run *biz.Run) error {
+	if q.data == nil || q.data.DB() == nil {
+		return errDBNotConfigured
+	}
+	return createRun(ctx, q.data.DB(), run)
+}
+
+func (q *runQueue) Dequeue(ctx context.Context, owner string) (*biz.Run, error) {
+	// 数据库未配置时队列始终为空
+	if q.data == nil || q.data.DB() == nil {
+		return nil, nil
+	}
+	var model *RunModel
+	err := q.data.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var models []RunModel
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", biz.RunQueued).
+			Order("created_at ASC").
+			Limit(1).
+			Find(&models).Error
+		if err != nil || len(models) == 0 {
+			return err
+		}
+		now := time.Now()
+		model = &models[0]
+		model.Status = string(agent.RunningState)
+		model.Owner = owner
+		model.HeartbeatAt = &now
+		return tx.Model(&RunModel{}).Where("id = ?", model.ID).Updates(map[string]any{
+			"status":       model.Status,
+			"owner":        model.Owner,
+			"heartbeat_at": model.HeartbeatAt,
+		}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("dequeue run: %w", err)
+	}
+	if model == nil {
+		return nil, nil
+	}
+	return model.ToBiz()
+}
+
+type RunModel struct {
+	ID              string     `gorm:"column:id;primaryKey"`
+	AgentID         int        `gorm:"column:agent_id"`
+	SessionID       string     `gorm:"column:session_id"`
+	Query           string     `gorm:"column:query"`
+	Request         string     `gorm:"column:request"`
+	Status          string     `gorm:"column:status"`
+	CurrentStep     int        `gorm:"column:current_step"`
+	Checkpoint      *string    `gorm:"column:checkpoint"`
+	Result          string     `gorm:"column:result"`
+	FinalResult     *string    `gorm:"column:final_result"`
+	Error           string     `gorm:"column:error"`
+	ResumeCount     int        `gorm:"column:resume_count"`
+	WebhookURL      string     `gorm:"column:webhook_url"`
+	Owner           string     `gorm:"column:owner"`
+	HeartbeatAt     *time.Time `gorm:"column:heartbeat_at"`
+	CancelRequested bool       `gorm:"column:cancel_requested"`
+	CreatedAt       time.Time  `gorm:"column:created_at"`
+	UpdatedAt       time.Time  `gorm:"column:updated_at"`
+}
+
+func (RunModel) TableName() string {
+	return "agent_runs"
+}
+
+func (m RunModel) ToBiz() (*biz.Run, error) {
+	run := &biz.Run{
+		ID:              m.ID,
+		AgentID:         m.AgentID,
+		SessionID:       m.SessionID,
+		Query:           m.Query,
+		Request:         []byte(m.Request),
+		Status:          m.Status,
+		CurrentStep:     m.CurrentStep,
+		Result:          m.Result,
+		Error:           m.Error,
+		ResumeCount:     m.ResumeCount,
+		WebhookURL:      m.WebhookURL,
+		Owner:           m.Owner,
+		CancelRequested: m.CancelRequested,
+		CreatedAt:       m.CreatedAt,
+		UpdatedAt:       m.UpdatedAt,
+	}
+	if m.Checkpoint != nil {
+		run.Checkpoint = []byte(*m.Checkpoint)
+	}
+	if m.HeartbeatAt != nil {
+		run.HeartbeatAt = *m.HeartbeatAt
+	}
+	if m.FinalResult != nil {
+		run.FinalResult = &agent.FinalResult{}
+		if err := json.Unmarshal([]byte(*m.FinalResult), run.FinalResult); err != nil {
+			return nil, fmt.Errorf("parse run %s final result: %w", m.ID, err)
+		}
+	}
+	return run, nil
+}
+
+type RunEventModel struct {
+	ID        int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	RunID     string    `gorm:"column:run_id"`
+	Type      string    `gorm:"column:type"`
+	Content   string    `gorm:"column:content"`
+	Step      int       `gorm:"column:step"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
+
+func (RunEventModel) TableName() string {
+	return "agent_run_events"
+}
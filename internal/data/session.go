@@ -0,0 +1,369 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"jas-agent/agent/core"
+	"jas-agent/internal/biz"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+)
+
+type sessionRepo struct {
+	data *Data
+}
+
+func NewSessionRepo(data *Data) biz.SessionRepo {
+	return &sessionRepo{data: data}
+}
+
+func (r *sessionRepo) db() (*gorm.DB, error) {
+	if r.data == nil || r.data.DB() == nil {
+		return nil, errDBNotConfigured
+	}
+	return r.data.DB(), nil
+}
+
+func (r *sessionRepo) CreateSession(ctx context.Context, session *biz.Session) error {
+	db, err := r.db()
+	if err != nil {
+		return err
+	}
+	model := &SessionModel{
+		ID:      session.ID,
+		AgentID: session.AgentID,
+		Title:   session.Title,
+	}
+	if err := db.WithContext(ctx).Create(model).Error; err != nil {
+		return fmt.Errorf("create session: %w", err)
+	}
+	session.CreatedAt = model.CreatedAt
+	session.UpdatedAt = model.UpdatedAt
+	return nil
+}
+
+func (r *sessionRepo) GetSession(ctx context.Context, id string) (*biz.Session, error) {
+	db, err := r.db()
+	if err != nil {
+		return nil, err
+	}
+
+	var model SessionModel
+	if err := db.WithContext(ctx).Where("id = ?", id).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", biz.ErrSessionNotFound, id)
+		}
+		return nil, fmt.Errorf("query session: %w", err)
+	}
+
+	var count int64
+	if err := db.WithContext(ctx).Model(&SessionMessageModel{}).Where("session_id = ?", id).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("count session messages: %w", err)
+	}
+	return model.ToBiz(int(count)), nil
+}
+
+func (r *sessionRepo) ListSessions(ctx context.Context, agentID int) ([]*biz.Session, error) {
+	db, err := r.db()
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		SessionModel
+		MessageCount int `gorm:"column:message_count"`
+	}
+	query := db.WithContext(ctx).
+		Table("sessions").
+		Select("sessions.*, (SELECT COUNT(*) FROM session_messages WHERE session_messages.session_id = sessions.id) AS message_count")
+	if agentID > 0 {
+		query = query.Where("sessions.agent_id = ?", agentID)
+	}
+	if err := query.Order("sessions.updated_at DESC").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+
+	sessions := make([]*biz.Session, 0, len(rows))
+	for _, row := range rows {
+		sessions = append(sessions, row.SessionModel.ToBiz(row.MessageCount))
+	}
+	return sessions, nil
+}
+
+func (r *sessionRepo) DeleteSession(ctx context.Context, id string) error {
+	db, err := r.db()
+	if err != nil {
+		return err
+	}
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("session_id = ?", id).Delete(&SessionMessageModel{}).Error; err != nil {
+			return fmt.Errorf("delete session messages: %w", err)
+		}
+		if err := tx.Where("id = ?", id).Delete(&SessionModel{}).Error; err != nil {
+			return fmt.Errorf("delete session: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *sessionRepo) ListMessages(ctx context.Context, sessionID string) ([]*biz.SessionMessage, error) {
+	db, err := r.db()
+	if err != nil {
+		return nil, err
+	}
+
+	var models []SessionMessageModel
+	if err := db.WithContext(ctx).Where("session_id = ?", sessionID).Order("id ASC").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("list session messages: %w", err)
+	}
+
+	messages := make([]*biz.SessionMessage, 0, len(models))
+	for _, model := range models {
+		messages = append(messages, model.ToBiz())
+	}
+	return messages, nil
+}
+
+func (r *sessionRepo) Memory(ctx context.Context, sessionID string, persistAll bool) (core.Memory, error) {
+	db, err := r.db()
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := r.ListMessages(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	mem := &sessionMemory{
+		// 持久化不应随请求取消而中断，否则会丢失本轮已产生的消息
+		ctx:       context.WithoutCancel(ctx),
+		store:     gormMessageStore{db: db},
+		log:       r.data.logger(),
+		sessionID: sessionID,
+		persist:   persistAll,
+	}
+	for _, message := range history {
+		mem.messages = append(mem.messages, message.ToCore())
+	}
+	return mem, nil
+}
+
+type SessionModel struct {
+	ID        string    `gorm:"column:id;primaryKey"`
+	AgentID   int       `gorm:"column:agent_id"`
+	Title     string    `gorm:"column:title"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+}
+
+func (SessionModel) TableName() string {
+	return "sessions"
+}
+
+func (m SessionModel) ToBiz(messageCount int) *biz.Session {
+	return &biz.Session{
+		ID:           m.ID,
+		AgentID:      m.AgentID,
+		Title:        m.Title,
+		MessageCount: messageCount,
+		CreatedAt:    m.CreatedAt,
+		UpdatedAt:    m.UpdatedAt,
+	}
+}
+
+type SessionMessageModel struct {
+	ID         int64     `gorm:"column:id;primaryKey"`
+	SessionID  string    `gorm:"column:session_id"`
+	Role       string    `gorm:"column:role"`
+	Content    string    `gorm:"column:content"`
+	Name       string    `gorm:"column:name"`
+	ToolCallID string    `gorm:"column:tool_call_id"`
+	ToolCalls  ToolCalls `gorm:"column:tool_calls;type:json"`
+	CreatedAt  time.Time `gorm:"column:created_at"`
+}
+
+func (SessionMessageModel) TableName() string {
+	return "session_messages"
+}
+
+func (m SessionMessageModel) ToBiz() *biz.SessionMessage {
+	return &biz.SessionMessage{
+		ID:         m.ID,
+		SessionID:  m.SessionID,
+		Role:       m.Role,
+		Content:    m.Content,
+		Name:       m.Name,
+		ToolCallID: m.ToolCallID,
+		ToolCalls:  m.ToolCalls,
+		CreatedAt:  m.CreatedAt,
+	}
+}
+
+// ToolCalls 用于处理助手消息中 tool_calls 的 JSON 字段
+type ToolCalls []core.ToolCall
+
+func (t *ToolCalls) Scan(value interface{}) error {
+	if value == nil {
+		*t = nil
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into ToolCalls", value)
+	}
+
+	if len(bytes) == 0 {
+		*t = nil
+		return nil
+	}
+	return json.Unmarshal(bytes, t)
+}
+
+func (t ToolCalls) Value() (driver.Value, error) {
+	if len(t) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(t)
+}
+
+// AddMessages 在一个事务中追加消息并更新会话时间
+func (r *sessionRepo) AddMessages(ctx context.Context, sessionID string, messages []core.Message) error {
+	db, err := r.db()
+	if err != nil {
+		return err
+	}
+	if err := addSessionMessages(db.WithContext(ctx), sessionID, messages); err != nil {
+		return fmt.Errorf("add session %s messages: %w", sessionID, err)
+	}
+	return nil
+}
+
+func addSessionMessages(db *gorm.DB, sessionID string, messages []core.Message) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, message := range messages {
+			if err := tx.Create(&SessionMessageModel{
+				SessionID:  sessionID,
+				Role:       string(message.Role),
+				Content:    message.Content,
+				Name:       message.Name,
+				ToolCallID: message.ToolCallID,
+				ToolCalls:  message.ToolCalls,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&SessionModel{ID: sessionID}).Update("updated_at", time.Now()).Error
+	})
+}
+
+// sessionMessageStore 会话消息的存储，sessionMemory 通过它追加与清空已持久化的消息
+type sessionMessageStore interface {
+	AddMessages(ctx context.Context, sessionID string, messages []core.Message) error
+	ClearMessages(ctx context.Context, sessionID string) error
+}
+
+// gormMessageStore 基于 session_messages 表的会话消息存储
+type gormMessageStore struct {
+	db *gorm.DB
+}
+
+func (s gormMessageStore) AddMessages(ctx context.Context, sessionID string, messages []core.Message) error {
+	return addSessionMessages(s.db.WithContext(ctx), sessionID, messages)
+}
+
+func (s gormMessageStore) ClearMessages(ctx context.Context, sessionID string) error {
+	return s.db.WithContext(ctx).Where("session_id = ?", sessionID).Delete(&SessionMessageModel{}).Error
+}
+
+// sessionMemory 基于 MySQL 的会话内存。
+// 系统提示词由 Agent 在每次运行时重新生成，只保存在内存中并始终排在最前；
+// persist 为 true 时其余消息追加写入 session_messages，否则只保存在内存中，由运行完成后单独写入本轮问答。
+type sessionMemory struct {
+	mu        sync.RWMutex
+	ctx       context.Context
+	store     sessionMessageStore
+	log       *log.Helper
+	sessionID string
+	persist   bool
+	system    []core.Message
+	messages  []core.Message
+}
+
+func (m *sessionMemory) AddMessage(message core.Message) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if message.Role == core.MessageRoleSystem {
+		m.system = append(m.system, message)
+		return
+	}
+	m.messages = append(m.messages, message)
+	if m.persist {
+		m.save(message)
+	}
+}
+
+func (m *sessionMemory) AddMessages(messages []core.Message) {
+	for _, message := range messages {
+		m.AddMessage(message)
+	}
+}
+
+func (m *sessionMemory) GetLastMessage() core.Message {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if len(m.messages) > 0 {
+		return m.messages[len(m.messages)-1]
+	}
+	if len(m.system) > 0 {
+		return m.system[len(m.system)-1]
+	}
+	return core.Message{}
+}
+
+func (m *sessionMemory) GetMessages() []core.Message {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	messages := make([]core.Message, 0, len(m.system)+len(m.messages))
+	messages = append(messages, m.system...)
+	return append(messages, m.messages...)
+}
+
+func (m *sessionMemory) GetFormatMessage() string {
+	bs := bytes.NewBufferString("")
+	for _, message := range m.GetMessages() {
+		bs.WriteString(fmt.Sprintf("role:%s content:%s\n", message.Role, message.Content))
+	}
+	return bs.String()
+}
+
+// Clear 清空会话消息（包括已持久化的历史）
+func (m *sessionMemory) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.system = nil
+	m.messages = nil
+	if err := m.store.ClearMessages(m.ctx, m.sessionID); err != nil {
+		m.log.Errorf("clear session %s messages: %v", m.sessionID, err)
+	}
+}
+
+func (m *sessionMemory) save(message core.Message) {
+	if err := m.store.AddMessages(m.ctx, m.sessionID, []core.Message{message}); err != nil {
+		m.log.Errorf("persist session %s message: %v", m.sessionID, err)
+	}
+}
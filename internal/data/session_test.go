@@ -0,0 +1,93 @@
+package data
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+
+	"jas-agent/agent/core"
+)
+
+// fakeMessageStore 记录写入的会话消息
+type fakeMessageStore struct {
+	messages map[string][]core.Message
+	clears   int
+}
+
+func (s *fakeMessageStore) AddMessages(_ context.Context, sessionID string, messages []core.Message) error {
+	s.messages[sessionID] = append(s.messages[sessionID], messages...)
+	return nil
+}
+
+func (s *fakeMessageStore) ClearMessages(_ context.Context, sessionID string) error {
+	delete(s.messages, sessionID)
+	s.clears++
+	return nil
+}
+
+func TestSessionMemory(t *testing.T) {
+	history := []core.Message{
+		{Role: core.MessageRoleUser, Content: "earlier question"},
+		{Role: core.MessageRoleAssistant, Content: "earlier answer"},
+	}
+	run := []core.Message{
+		{Role: core.MessageRoleUser, Content: "question"},
+		{Role: core.MessageRoleAssistant, ToolCalls: []core.ToolCall{{ID: "call-1", Name: "lookup", Arguments: "{}"}}},
+		{Role: core.MessageRoleTool, Content: "42", Name: "lookup", ToolCallID: "call-1"},
+		{Role: core.MessageRoleAssistant, Content: "answer"},
+	}
+	tests := []struct {
+		name    string
+		persist bool
+		// wantSaved 写入存储的消息
+		wantSaved []core.Message
+	}{
+		// 保存全部消息时每条非系统消息随写入保存，系统提示词不保存
+		{name: "persist all", persist: true, wantSaved: run},
+		// 只保存每轮问答时消息只在内存中，由运行完成后单独写入
+		{name: "persist turns", persist: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeMessageStore{messages: map[string][]core.Message{}}
+			mem := &sessionMemory{
+				ctx:       context.Background(),
+				store:     store,
+				log:       log.NewHelper(log.DefaultLogger),
+				sessionID: "session-1",
+				persist:   tt.persist,
+				messages:  append([]core.Message(nil), history...),
+			}
+			mem.AddMessage(run[0])
+			// 系统提示词在历史之后写入，读取时仍排在最前
+			mem.AddMessage(core.Message{Role: core.MessageRoleSystem, Content: "system prompt"})
+			mem.AddMessages(run[1:])
+
+			saved := store.messages["session-1"]
+			if len(saved) != len(tt.wantSaved) {
+				t.Fatalf("saved = %+v, want %+v", saved, tt.wantSaved)
+			}
+			for i := range tt.wantSaved {
+				if saved[i].Role != tt.wantSaved[i].Role || saved[i].Content != tt.wantSaved[i].Content ||
+					saved[i].ToolCallID != tt.wantSaved[i].ToolCallID || len(saved[i].ToolCalls) != len(tt.wantSaved[i].ToolCalls) {
+					t.Fatalf("saved message %d = %+v, want %+v", i, saved[i], tt.wantSaved[i])
+				}
+			}
+			messages := mem.GetMessages()
+			if len(messages) != 1+len(history)+len(run) || messages[0].Content != "system prompt" ||
+				messages[1].Content != "earlier question" || messages[len(messages)-1].Content != "answer" {
+				t.Fatalf("messages = %+v", messages)
+			}
+			if last := mem.GetLastMessage(); last.Content != "answer" {
+				t.Fatalf("last message = %+v", last)
+			}
+
+			// 清空同时删除已持久化的消息
+			mem.Clear()
+			if len(mem.GetMessages()) != 0 || store.clears != 1 {
+				t.Fatalf("after clear: messages = %+v, store clears = %d", mem.GetMessages(), store.clears)
+			}
+		})
+	}
+}
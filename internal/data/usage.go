@@ -0,0 +1,91 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"jas-agent/agent/llm"
+	"jas-agent/internal/biz"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type tokenUsageRepo struct {
+	data *Data
+}
+
+func NewTokenUsageRepo(data *Data) biz.TokenUsageRepo {
+	return &tokenUsageRepo{data: data}
+}
+
+func (r *tokenUsageRepo) AddDailyUsage(ctx context.Context, tenant string, agentID int, day time.Time, model string, usage llm.Usage) error {
+	// 数据库未配置时只统计运行内用量，不做持久化
+	if r.data == nil || r.data.DB() == nil {
+		return nil
+	}
+	row := &TokenUsageDailyModel{
+		TenantID:         tenant,
+		AgentID:          agentID,
+		UsageDate:        day.Format(time.DateOnly),
+		Model:            model,
+		Calls:            1,
+		PromptTokens:     int64(usage.PromptTokens),
+		CompletionTokens: int64(usage.CompletionTokens),
+		Cost:             usage.Cost,
+	}
+	err := r.data.DB().WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			"calls":             gorm.Expr("calls + ?", row.Calls),
+			"prompt_tokens":     gorm.Expr("prompt_tokens + ?", row.PromptTokens),
+			"completion_tokens": gorm.Expr("completion_tokens + ?", row.CompletionTokens),
+			"cost":              gorm.Expr("cost + ?", row.Cost),
+		}),
+	}).Create(row).Error
+	if err != nil {
+		return fmt.Errorf("add daily token usage: %w", err)
+	}
+	return nil
+}
+
+func (r *tokenUsageRepo) GetTenantDailyUsage(ctx context.Context, tenant string, day time.Time) (llm.Usage, error) {
+	if r.data == nil || r.data.DB() == nil {
+		return llm.Usage{}, nil
+	}
+	var sum struct {
+		PromptTokens     int64
+		CompletionTokens int64
+		Cost             float64
+	}
+	err := r.data.DB().WithContext(ctx).Model(&TokenUsageDailyModel{}).
+		Select("COALESCE(SUM(prompt_tokens), 0) AS prompt_tokens, COALESCE(SUM(completion_tokens), 0) AS completion_tokens, COALESCE(SUM(cost), 0) AS cost").
+		Where("tenant_id = ? AND usage_date = ?", tenant, day.Format(time.DateOnly)).
+		Scan(&sum).Error
+	if err != nil {
+		return llm.Usage{}, fmt.Errorf("get tenant daily token usage: %w", err)
+	}
+	return llm.Usage{
+		PromptTokens:     int(sum.PromptTokens),
+		CompletionTokens: int(sum.CompletionTokens),
+		Cost:             sum.Cost,
+	}, nil
+}
+
+type TokenUsageDailyModel struct {
+	ID               int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	TenantID         string    `gorm:"column:tenant_id"`
+	AgentID          int       `gorm:"column:agent_id"`
+	UsageDate        string    `gorm:"column:usage_date"`
+	Model            string    `gorm:"column:model"`
+	Calls            int64     `gorm:"column:calls"`
+	PromptTokens     int64     `gorm:"column:prompt_tokens"`
+	CompletionTokens int64     `gorm:"column:completion_tokens"`
+	Cost             float64   `gorm:"column:cost"`
+	CreatedAt        time.Time `gorm:"column:created_at"`
+	UpdatedAt        time.Time `gorm:"column:updated_at"`
+}
+
+func (TokenUsageDailyModel) TableName() string {
+	return "agent_token_usage_daily"
+}
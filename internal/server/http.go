@@ -23,7 +23,7 @@ import (
 )
 
 // NewHTTPServer 创建 Kratos HTTP 服务。
-func NewHTTPServer(c *conf.Server, agentSvc *service.AgentService, knowledgeSvc *service.KnowledgeServiceImpl, logger log.Logger) *httptransport.Server {
+func NewHTTPServer(c *conf.Server, agentSvc *service.AgentService, knowledgeSvc *service.KnowledgeServiceImpl, mcpSvc *service.MCPServerService, logger log.Logger) *httptransport.Server {
 	addr := ":0"
 	if c != nil && c.Http != nil && c.Http.Addr != "" {
 		addr = c.Http.Addr
@@ -56,6 +56,10 @@ func NewHTTPServer(c *conf.Server, agentSvc *service.AgentService, knowledgeSvc
 	srv.Handle("/api/chat/stream", http.HandlerFunc(agentSvc.WebSocket))
 	// 文档上传端点（multipart/form-data）
 	srv.Handle("/api/knowledge-bases/{knowledge_base_id}/documents/upload", http.HandlerFunc(knowledgeSvc.UploadDocument))
+	// MCP 服务端端点（Streamable HTTP 与 SSE），开启 server.mcp 时挂载
+	if handler := mcpSvc.Handler(); handler != nil {
+		srv.HandlePrefix(mcpSvc.Path(), handler)
+	}
 	return srv
 }
 
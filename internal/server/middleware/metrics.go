@@ -1,8 +1,11 @@
 package middleware
 
 import (
+	"context"
+
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/middleware/metrics"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/metric"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
@@ -11,6 +14,8 @@ import (
 var (
 	_metricSeconds  metric.Float64Histogram
 	_metricRequests metric.Int64Counter
+	_metricTokens   metric.Int64Counter
+	_metricCost     metric.Float64Counter
 	Name            = "jasagent"
 )
 
@@ -30,7 +35,28 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+	_metricTokens, err = meter.Int64Counter("llm_tokens",
+		metric.WithDescription("Number of LLM tokens consumed"), metric.WithUnit("{token}"))
+	if err != nil {
+		panic(err)
+	}
+	_metricCost, err = meter.Float64Counter("llm_cost",
+		metric.WithDescription("Cost of LLM calls computed from the configured pricing"))
+	if err != nil {
+		panic(err)
+	}
 }
 func ServerMetrics() middleware.Middleware {
 	return metrics.Server(metrics.WithRequests(_metricRequests), metrics.WithSeconds(_metricSeconds))
 }
+
+// RecordTokenUsage 记录一次模型调用的 token 用量与费用，tenant 为租户ID（未指定时为空），
+// source 为调用来源（如 Agent 名称、graphrag）
+func RecordTokenUsage(ctx context.Context, tenant, source, model string, promptTokens, completionTokens int, cost float64) {
+	attrs := []attribute.KeyValue{attribute.String("tenant", tenant), attribute.String("source", source), attribute.String("model", model)}
+	_metricTokens.Add(ctx, int64(promptTokens), metric.WithAttributes(append(attrs, attribute.String("type", "prompt"))...))
+	_metricTokens.Add(ctx, int64(completionTokens), metric.WithAttributes(append(attrs, attribute.String("type", "completion"))...))
+	if cost > 0 {
+		_metricCost.Add(ctx, cost, metric.WithAttributes(attrs...))
+	}
+}
@@ -1,6 +1,11 @@
 package server
 
 import (
+	"context"
+
+	"jas-agent/internal/biz"
+	"jas-agent/internal/service"
+
 	"github.com/go-kratos/kratos/v2"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/transport/grpc"
@@ -11,9 +16,17 @@ import (
 // ProviderSet server provider.
 var ProviderSet = wire.NewSet(NewHTTPServer, NewGRPCServer, NewApp)
 
-// NewApp 构造 Kratos 应用。
-func NewApp(logger log.Logger, gs *grpc.Server, hs *http2.Server) *kratos.App {
-	var opts []kratos.Option
+// NewApp 构造 Kratos 应用，异步运行的 worker 随应用启停，服务启动后恢复因上次退出而中断的运行。
+func NewApp(logger log.Logger, gs *grpc.Server, hs *http2.Server, workers *biz.RunWorkerPool, agentSvc *service.AgentService) *kratos.App {
+	opts := []kratos.Option{
+		kratos.AfterStart(func(ctx context.Context) error {
+			// 恢复失败不影响服务启动
+			if err := agentSvc.ResumeInterruptedRuns(ctx); err != nil && logger != nil {
+				log.NewHelper(logger).Errorf("resume interrupted runs: %v", err)
+			}
+			return nil
+		}),
+	}
 	if logger != nil {
 		opts = append(opts, kratos.Logger(logger))
 	}
@@ -23,5 +36,8 @@ func NewApp(logger log.Logger, gs *grpc.Server, hs *http2.Server) *kratos.App {
 	if hs != nil {
 		opts = append(opts, kratos.Server(hs))
 	}
+	if workers != nil {
+		opts = append(opts, kratos.Server(workers))
+	}
 	return kratos.New(opts...)
 }
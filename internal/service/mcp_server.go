@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"jas-agent/agent/core"
+	tools "jas-agent/agent/tools"
+	"jas-agent/internal/biz"
+	"jas-agent/internal/conf"
+)
+
+const (
+	mcpServerName    = "jas-agent"
+	mcpServerVersion = "1.0.0"
+	defaultMCPPath   = "/mcp"
+)
+
+// agentToolSchema Agent 对外工具的参数
+var agentToolSchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "query": {"type": "string", "description": "交给该Agent的问题或任务"},
+    "session_id": {"type": "string", "description": "上次调用返回的会话ID，携带时复用该会话的历史消息实现多轮对话；不携带时创建新会话"}
+  },
+  "required": ["query"]
+}`)
+
+// mcpScope Bearer Token 可访问的范围
+type mcpScope struct {
+	name   string
+	tenant string
+	agents map[int]bool // 为空时可访问全部对外提供的 Agent
+}
+
+// allows 是否可以调用 agentID 对应的 Agent 及其工具，agentID 为 0 表示全局工具
+func (s *mcpScope) allows(agentID int) bool {
+	return s != nil && (agentID == 0 || len(s.agents) == 0 || s.agents[agentID])
+}
+
+type mcpScopeKey struct{}
+
+func scopeFromContext(ctx context.Context) *mcpScope {
+	scope, _ := ctx.Value(mcpScopeKey{}).(*mcpScope)
+	return scope
+}
+
+// MCPServerService 以 MCP 服务端对外提供 agents 表中开启 expose.mcp 的 Agent 与选定的工具，
+// 挂载在 HTTP 服务上，同时支持 Streamable HTTP 与 SSE 两种传输
+type MCPServerService struct {
+	uc     *biz.AgentUsecase
+	logger *log.Helper
+	path   string
+	tools  []string // 对外提供的全局注册工具模式
+	tokens []*mcpToken
+	server *server.MCPServer
+	sse    *server.SSEServer
+	stream *server.StreamableHTTPServer
+
+	mu          sync.Mutex
+	fingerprint string
+	owners      map[string]int // 工具名 -> 所属 Agent ID，全局工具为 0
+}
+
+type mcpToken struct {
+	token []byte
+	scope *mcpScope
+}
+
+// NewMCPServerService 按 server.mcp 配置创建 MCP 服务端，未开启时 Handler 返回 nil
+func NewMCPServerService(c *conf.Server, uc *biz.AgentUsecase, logger log.Logger) *MCPServerService {
+	s := &MCPServerService{
+		uc:     uc,
+		logger: log.NewHelper(log.With(logger, "module", "service/mcp_server")),
+		owners: map[string]int{},
+	}
+	cfg := c.GetMcp()
+	if !cfg.GetEnabled() {
+		return s
+	}
+	s.path = strings.TrimSuffix(cfg.GetPath(), "/")
+	if s.path == "" {
+		s.path = defaultMCPPath
+	}
+	s.tools = cfg.GetTools()
+	for _, token := range cfg.GetTokens() {
+		if token.GetToken() == "" {
+			continue
+		}
+		scope := &mcpScope{name: token.GetName(), tenant: token.GetTenant(), agents: map[int]bool{}}
+		for _, id := range token.GetAgents() {
+			scope.agents[int(id)] = true
+		}
+		s.tokens = append(s.tokens, &mcpToken{token: []byte(token.GetToken()), scope: scope})
+	}
+	if len(s.tokens) == 0 {
+		s.logger.Warn("server.mcp has no tokens configured, all MCP requests will be rejected")
+	}
+
+	hooks := &server.Hooks{}
+	// 每次列出工具前按 agents 表同步，Agent 的启用、对外配置与更新即时生效
+	hooks.AddBeforeListTools(func(ctx context.Context, id any, message *mcp.ListToolsRequest) {
+		s.sync(ctx)
+	})
+	s.server = server.NewMCPServer(mcpServerName, mcpServerVersion,
+		server.WithToolCapabilities(true),
+		server.WithToolFilter(s.filterTools),
+		server.WithHooks(hooks),
+		server.WithRecovery(),
+	)
+	s.sse = server.NewSSEServer(s.server,
+		server.WithStaticBasePath(s.path),
+		server.WithUseFullURLForMessageEndpoint(false),
+		server.WithKeepAlive(true),
+	)
+	s.stream = server.NewStreamableHTTPServer(s.server, server.WithEndpointPath(s.path))
+	s.sync(context.Background())
+	return s
+}
+
+// Path MCP 端点的挂载路径
+func (s *MCPServerService) Path() string {
+	return s.path
+}
+
+// Handler 校验 Bearer Token 后按路径分发：<path>/sse 与 <path>/message 为 SSE，其余为 Streamable HTTP。
+// 未开启时返回 nil
+func (s *MCPServerService) Handler() http.Handler {
+	if s.server == nil {
+		return nil
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scope := s.authenticate(r)
+		if scope == nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mcp"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		r = r.WithContext(context.WithValue(r.Context(), mcpScopeKey{}, scope))
+		switch r.URL.Path {
+		case s.sse.CompleteSsePath(), s.sse.CompleteMessagePath():
+			s.sse.ServeHTTP(w, r)
+		case s.path:
+			s.stream.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// authenticate 按 Authorization: Bearer <token> 查找访问范围，未匹配时返回 nil
+func (s *MCPServerService) authenticate(r *http.Request) *mcpScope {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return nil
+	}
+	for _, t := range s.tokens {
+		if subtle.ConstantTimeCompare(t.token, []byte(token)) == 1 {
+			return t.scope
+		}
+	}
+	return nil
+}
+
+// filterTools 只列出当前 Token 可访问的工具
+func (s *MCPServerService) filterTools(ctx context.Context, list []mcp.Tool) []mcp.Tool {
+	scope := scopeFromContext(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	filtered := make([]mcp.Tool, 0, len(list))
+	for _, tool := range list {
+		if scope.allows(s.owners[tool.Name]) {
+			filtered = append(filtered, tool)
+		}
+	}
+	return filtered
+}
+
+// sync 按 agents 表与全局工具重建对外的工具列表，内容未变化时不更新，避免反复通知客户端工具列表变更
+func (s *MCPServerService) sync(ctx context.Context) {
+	agents, err := s.uc.ListExposedAgents(ctx)
+	if err != nil {
+		s.logger.Errorf("sync mcp tools: %v", err)
+		return
+	}
+	var (
+		serverTools []server.ServerTool
+		fingerprint strings.Builder
+	)
+	owners := map[string]int{}
+	add := func(agentID int, tool mcp.Tool, handler server.ToolHandlerFunc) {
+		if _, ok := owners[tool.Name]; ok {
+			s.logger.Warnf("skip duplicate mcp tool %s of agent %d", tool.Name, agentID)
+			return
+		}
+		owners[tool.Name] = agentID
+		serverTools = append(serverTools, server.ServerTool{Tool: tool, Handler: handler})
+	}
+	if len(s.tools) > 0 {
+		global := tools.GetToolManager().Subset(s.tools)
+		for _, tool := range sortedTools(global) {
+			add(0, nativeTool(biz.ExposedToolName(tool.Name()), tool), s.toolHandler(0, global, tool))
+		}
+	}
+	for _, item := range agents {
+		add(item.Agent.ID, mcp.NewToolWithRawSchema(item.ToolName, agentToolDescription(item.Agent), agentToolSchema), s.agentHandler(item.Agent.ID))
+		fmt.Fprintf(&fingerprint, "%d@%d;", item.Agent.ID, item.Agent.UpdatedAt.UnixNano())
+		if item.Tools == nil {
+			continue
+		}
+		for _, tool := range sortedTools(item.Tools) {
+			name := item.ToolName + "_" + biz.ExposedToolName(tool.Name())
+			add(item.Agent.ID, nativeTool(name, tool), s.toolHandler(item.Agent.ID, item.Tools, tool))
+		}
+	}
+	for _, tool := range serverTools {
+		fingerprint.WriteString(tool.Tool.Name + ";")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if fingerprint.String() == s.fingerprint {
+		return
+	}
+	s.fingerprint = fingerprint.String()
+	s.owners = owners
+	s.server.SetTools(serverTools...)
+	s.logger.Infof("mcp server exposes %d tools of %d agents", len(serverTools), len(agents))
+}
+
+// agentHandler 调用 Agent 完成一次对话，调用时重新检查 Agent 是否仍对外提供以及会话是否属于该 Agent
+func (s *MCPServerService) agentHandler(agentID int) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		scope := scopeFromContext(ctx)
+		if !scope.allows(agentID) {
+			return mcp.NewToolResultError(fmt.Sprintf("agent %d is not allowed for this token", agentID)), nil
+		}
+		query, err := request.RequireString("query")
+		if err != nil || strings.TrimSpace(query) == "" {
+			return mcp.NewToolResultError("query is required"), nil
+		}
+		s.logger.Infof("mcp client %s calls agent %d", scope.name, agentID)
+		resp, sessionID, err := s.uc.ChatExposed(ctx, agentID, query, request.GetString("session_id", ""), scope.tenant)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return agentToolResult(resp.Response, sessionID), nil
+	}
+}
+
+// toolHandler 执行对外提供的工具，Agent 的工具在调用时重新检查 Agent 是否仍对外提供以及工具策略是否允许直接调用
+func (s *MCPServerService) toolHandler(agentID int, toolManager *tools.ToolManager, tool core.Tool) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		scope := scopeFromContext(ctx)
+		if !scope.allows(agentID) {
+			return mcp.NewToolResultError(fmt.Sprintf("tools of agent %d are not allowed for this token", agentID)), nil
+		}
+		if agentID > 0 {
+			if err := s.uc.CheckExposedTool(ctx, agentID, tool.Name()); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+		arguments, err := json.Marshal(request.GetArguments())
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		s.logger.Infof("mcp client %s calls tool %s", scope.name, tool.Name())
+		output, err := toolManager.ExecTool(ctx, &tools.ToolCall{Name: tool.Name(), Input: tools.ResolveInput(tool, string(arguments))})
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(output), nil
+	}
+}
+
+// nativeTool 以工具的参数 schema 创建对外的 MCP 工具
+func nativeTool(name string, tool core.Tool) mcp.Tool {
+	schema, err := json.Marshal(tools.ParametersSchema(tool))
+	if err != nil {
+		schema = []byte(`{"type": "object"}`)
+	}
+	return mcp.NewToolWithRawSchema(name, tool.Description(), schema)
+}
+
+// agentToolResult Agent 工具的结果，文本内容为最终回答与会话ID，结构化内容为 {"response", "session_id"}
+func agentToolResult(response, sessionID string) *mcp.CallToolResult {
+	result := mcp.NewToolResultStructured(map[string]string{"response": response, "session_id": sessionID}, response)
+	if sessionID != "" {
+		result.Content = append(result.Content, mcp.NewTextContent("session_id: "+sessionID))
+	}
+	return result
+}
+
+func agentToolDescription(agentConfig *biz.Agent) string {
+	if agentConfig.Description == "" {
+		return agentConfig.Name
+	}
+	return fmt.Sprintf("%s: %s", agentConfig.Name, agentConfig.Description)
+}
+
+func sortedTools(toolManager *tools.ToolManager) []core.Tool {
+	list := toolManager.AvailableTools()
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name() < list[j].Name()
+	})
+	return list
+}
@@ -0,0 +1,342 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"jas-agent/agent/core"
+	"jas-agent/agent/llm"
+	"jas-agent/agent/tools"
+	"jas-agent/internal/biz"
+	"jas-agent/internal/conf"
+)
+
+type fakeAgentRepo struct {
+	mu     sync.Mutex
+	agents map[int]*biz.Agent
+}
+
+func (r *fakeAgentRepo) CreateAgent(context.Context, *biz.Agent) error { return nil }
+func (r *fakeAgentRepo) UpdateAgent(context.Context, *biz.Agent) error { return nil }
+func (r *fakeAgentRepo) DeleteAgent(context.Context, int) error        { return nil }
+
+func (r *fakeAgentRepo) GetAgent(_ context.Context, id int) (*biz.Agent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	agentConfig, ok := r.agents[id]
+	if !ok {
+		return nil, fmt.Errorf("agent %d not found", id)
+	}
+	copied := *agentConfig
+	return &copied, nil
+}
+
+func (r *fakeAgentRepo) ListAgents(context.Context) ([]*biz.Agent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	agents := make([]*biz.Agent, 0, len(r.agents))
+	for _, agentConfig := range r.agents {
+		copied := *agentConfig
+		agents = append(agents, &copied)
+	}
+	slices.SortFunc(agents, func(a, b *biz.Agent) int { return a.ID - b.ID })
+	return agents, nil
+}
+
+func (r *fakeAgentRepo) expose(id int, mcp bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.agents[id].ConfigJSON = fmt.Sprintf(`{"expose": {"mcp": %t}}`, mcp)
+}
+
+// fakeSessionRepo 只保存会话归属，对话不会执行到读写消息
+type fakeSessionRepo struct {
+	mu       sync.Mutex
+	sessions map[string]int
+}
+
+func (r *fakeSessionRepo) CreateSession(_ context.Context, session *biz.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessions[session.ID] = session.AgentID
+	return nil
+}
+
+func (r *fakeSessionRepo) GetSession(_ context.Context, id string) (*biz.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	agentID, ok := r.sessions[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", biz.ErrSessionNotFound, id)
+	}
+	return &biz.Session{ID: id, AgentID: agentID}, nil
+}
+
+func (r *fakeSessionRepo) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.sessions)
+}
+
+func (r *fakeSessionRepo) ListSessions(context.Context, int) ([]*biz.Session, error) { return nil, nil }
+func (r *fakeSessionRepo) DeleteSession(context.Context, string) error               { return nil }
+func (r *fakeSessionRepo) ListMessages(context.Context, string) ([]*biz.SessionMessage, error) {
+	return nil, nil
+}
+func (r *fakeSessionRepo) Memory(context.Context, string, bool) (core.Memory, error) {
+	return nil, fmt.Errorf("unexpected memory access")
+}
+func (r *fakeSessionRepo) AddMessages(context.Context, string, []core.Message) error { return nil }
+
+// newTestMCPServer Agent 1、2 对外提供，3 未开启 expose.mcp；
+// Token all 可访问全部对外提供的 Agent，ops 只能访问 Agent 2，会话 session-2 属于 Agent 2
+func newTestMCPServer(t *testing.T) (*httptest.Server, *fakeAgentRepo, *fakeSessionRepo) {
+	t.Helper()
+	agents := &fakeAgentRepo{agents: map[int]*biz.Agent{}}
+	for id := 1; id <= 3; id++ {
+		agents.agents[id] = &biz.Agent{ID: id, Name: fmt.Sprintf("agent-%d", id), Framework: "react", IsActive: true}
+		agents.expose(id, id != 3)
+	}
+	sessions := &fakeSessionRepo{sessions: map[string]int{"session-2": 2}}
+	uc := biz.NewAgentUsecase(nil, agents, biz.NewAgentFactory(), biz.NewSessionUsecase(sessions, agents, log.DefaultLogger),
+		nil, nil, nil, nil, nil, nil, llm.Pricing{}, nil, nil, log.DefaultLogger)
+	svc := NewMCPServerService(&conf.Server{Mcp: &conf.Server_MCP{
+		Enabled: true,
+		Tokens: []*conf.Server_MCP_Token{
+			{Name: "all", Token: "token-all"},
+			{Name: "ops", Token: "token-ops", Agents: []int32{2}},
+			// 空 Token 被忽略
+			{Name: "empty"},
+		},
+	}}, uc, log.DefaultLogger)
+	ts := httptest.NewServer(svc.Handler())
+	t.Cleanup(ts.Close)
+	return ts, agents, sessions
+}
+
+// newTestMCPClient 以 token 连接 Streamable HTTP 端点并完成初始化
+func newTestMCPClient(t *testing.T, url, token string) *client.Client {
+	t.Helper()
+	c, err := client.NewStreamableHttpClient(url+defaultMCPPath, transport.WithHTTPHeaders(map[string]string{"Authorization": "Bearer " + token}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	ctx := context.Background()
+	if err := c.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Initialize(ctx, mcp.InitializeRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// callTool 调用工具，返回结果是否为错误及其文本内容
+func callTool(t *testing.T, c *client.Client, name string, arguments map[string]any) (bool, string) {
+	t.Helper()
+	result, err := c.CallTool(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: name, Arguments: arguments}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var texts []string
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return result.IsError, strings.Join(texts, "\n")
+}
+
+func TestMCPServerAuthenticate(t *testing.T) {
+	ts, _, _ := newTestMCPServer(t)
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+	}{
+		{name: "missing", wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", authorization: "Bearer token-other", wantStatus: http.StatusUnauthorized},
+		{name: "token prefix", authorization: "Bearer token-al", wantStatus: http.StatusUnauthorized},
+		{name: "empty token", authorization: "Bearer ", wantStatus: http.StatusUnauthorized},
+		{name: "not bearer", authorization: "Basic token-all", wantStatus: http.StatusUnauthorized},
+		{name: "valid", authorization: "Bearer token-ops", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {"protocolVersion": "2025-03-26", "capabilities": {}, "clientInfo": {"name": "test", "version": "1.0"}}}`
+			req, err := http.NewRequest(http.MethodPost, ts.URL+defaultMCPPath, strings.NewReader(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept", "application/json, text/event-stream")
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusUnauthorized && resp.Header.Get("WWW-Authenticate") == "" {
+				t.Fatal("missing WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestMCPServerTokenScope(t *testing.T) {
+	ts, _, _ := newTestMCPServer(t)
+	tests := []struct {
+		token string
+		want  []string
+	}{
+		// 未开启 expose.mcp 的 Agent 3 对任何 Token 都不可见
+		{token: "token-all", want: []string{"agent_1", "agent_2"}},
+		{token: "token-ops", want: []string{"agent_2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.token, func(t *testing.T) {
+			c := newTestMCPClient(t, ts.URL, tt.token)
+			result, err := c.ListTools(context.Background(), mcp.ListToolsRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, tool := range result.Tools {
+				names = append(names, tool.Name)
+			}
+			if !slices.Equal(names, tt.want) {
+				t.Fatalf("tools = %v, want %v", names, tt.want)
+			}
+		})
+	}
+
+	// 未列出的工具直接按名称调用时同样按 Token 的访问范围拒绝
+	c := newTestMCPClient(t, ts.URL, "token-ops")
+	isError, text := callTool(t, c, "agent_1", map[string]any{"query": "hello"})
+	if !isError || text != "agent 1 is not allowed for this token" {
+		t.Fatalf("call agent_1 = %v, %q", isError, text)
+	}
+}
+
+func TestMCPServerCallChecks(t *testing.T) {
+	tests := []struct {
+		name      string
+		tool      string
+		arguments map[string]any
+		// hide 调用前关闭 Agent 1 的 expose.mcp，工具列表尚未同步
+		hide    bool
+		wantErr string
+	}{
+		{name: "missing query", tool: "agent_2", arguments: map[string]any{"query": " "}, wantErr: "query is required"},
+		{name: "expose disabled after listing", tool: "agent_1", arguments: map[string]any{"query": "hello"}, hide: true, wantErr: biz.ErrAgentNotExposed.Error()},
+		// 客户端不能自行指定新的会话ID
+		{name: "unknown session", tool: "agent_2", arguments: map[string]any{"query": "hello", "session_id": "chosen-by-client"}, wantErr: biz.ErrSessionNotFound.Error()},
+		{name: "session of another agent", tool: "agent_1", arguments: map[string]any{"query": "hello", "session_id": "session-2"}, wantErr: biz.ErrSessionAgentMismatch.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts, agents, sessions := newTestMCPServer(t)
+			c := newTestMCPClient(t, ts.URL, "token-all")
+			if tt.hide {
+				agents.expose(1, false)
+			}
+			isError, text := callTool(t, c, tt.tool, tt.arguments)
+			if !isError || !strings.Contains(text, tt.wantErr) {
+				t.Fatalf("call %s = %v, %q, want error %q", tt.tool, isError, text, tt.wantErr)
+			}
+			if sessions.count() != 1 {
+				t.Fatalf("sessions = %v, want no session created", sessions.sessions)
+			}
+		})
+	}
+}
+
+func TestAgentToolResult(t *testing.T) {
+	result := agentToolResult("the answer", "session-1")
+	if len(result.Content) != 2 || result.Content[0].(mcp.TextContent).Text != "the answer" ||
+		result.Content[1].(mcp.TextContent).Text != "session_id: session-1" {
+		t.Fatalf("content = %+v", result.Content)
+	}
+	structured, err := json.Marshal(result.StructuredContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(structured) != `{"response":"the answer","session_id":"session-1"}` {
+		t.Fatalf("structured content = %s", structured)
+	}
+	// 会话创建失败时只返回回答
+	if result := agentToolResult("the answer", ""); len(result.Content) != 1 {
+		t.Fatalf("content = %+v", result.Content)
+	}
+}
+
+// countingTool 记录被执行的次数
+type countingTool struct {
+	name  string
+	calls int
+}
+
+func (t *countingTool) Name() string        { return t.name }
+func (t *countingTool) Description() string { return t.name }
+func (t *countingTool) Input() any          { return nil }
+func (t *countingTool) Type() core.ToolType { return core.Normal }
+func (t *countingTool) Handler(context.Context, string) (string, error) {
+	t.calls++
+	return "done", nil
+}
+
+func TestMCPServerToolPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		wantErr bool
+	}{
+		{name: "auto", policy: "auto"},
+		// 对外调用没有审批环节，confirm 与 deny 一样拒绝
+		{name: "confirm", policy: "confirm", wantErr: true},
+		{name: "deny", policy: "deny", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agents := &fakeAgentRepo{agents: map[int]*biz.Agent{1: {ID: 1, Name: "agent-1", Framework: "react", IsActive: true,
+				ConfigJSON: fmt.Sprintf(`{"expose": {"mcp": true, "tools": ["restart"]}, "tool_policy": {"rules": [{"pattern": "restart", "policy": %q}]}}`, tt.policy)}}}
+			uc := biz.NewAgentUsecase(nil, agents, biz.NewAgentFactory(), nil,
+				nil, nil, nil, nil, nil, nil, llm.Pricing{}, nil, nil, log.DefaultLogger)
+			svc := NewMCPServerService(&conf.Server{Mcp: &conf.Server_MCP{Enabled: true}}, uc, log.DefaultLogger)
+
+			// 工具在列出后策略才改为 confirm/deny，调用时仍按当前策略检查
+			tool := &countingTool{name: "restart"}
+			toolManager := tools.NewToolManager()
+			toolManager.RegisterTool(tool)
+			ctx := context.WithValue(context.Background(), mcpScopeKey{}, &mcpScope{name: "all"})
+			result, err := svc.toolHandler(1, toolManager, tool)(ctx, mcp.CallToolRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.IsError != tt.wantErr {
+				t.Fatalf("result = %+v, want error %v", result, tt.wantErr)
+			}
+			if wantCalls := map[bool]int{true: 0, false: 1}[tt.wantErr]; tool.calls != wantCalls {
+				t.Fatalf("tool calls = %d, want %d", tool.calls, wantCalls)
+			}
+		})
+	}
+}
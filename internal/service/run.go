@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"jas-agent/agent/agent"
+	"jas-agent/internal/biz"
+
+	pb "jas-agent/api/agent/service/v1"
+)
+
+// GetRun 获取运行状态与结果。
+func (s *AgentService) GetRun(ctx context.Context, req *pb.RunGetRequest) (*pb.RunResponse, error) {
+	result := new(pb.RunResponse)
+	run, err := s.delegate.GetRun(ctx, req.Id)
+	if err != nil {
+		return result, err
+	}
+	result.Run = runToProto(run)
+	return result, nil
+}
+
+// ResumeRun 从最近的检查点在后台继续执行运行。
+func (s *AgentService) ResumeRun(ctx context.Context, req *pb.RunResumeRequest) (*pb.RunResponse, error) {
+	result := new(pb.RunResponse)
+	run, err := s.delegate.ResumeRun(ctx, req.Id)
+	if err != nil {
+		return result, err
+	}
+	result.Run = runToProto(run)
+	return result, nil
+}
+
+// CancelRun 取消运行。
+func (s *AgentService) CancelRun(ctx context.Context, req *pb.RunCancelRequest) (*pb.RunResponse, error) {
+	result := new(pb.RunResponse)
+	run, err := s.delegate.CancelRun(ctx, req.Id)
+	if err != nil {
+		return result, err
+	}
+	result.Run = runToProto(run)
+	return result, nil
+}
+
+// SubmitRun 提交异步运行，立即返回运行ID。
+func (s *AgentService) SubmitRun(ctx context.Context, req *pb.SubmitRunRequest) (*pb.RunResponse, error) {
+	result := new(pb.RunResponse)
+	if req.Request == nil {
+		return result, errors.New("request is required")
+	}
+	fillTenant(ctx, req.Request)
+	run, err := s.delegate.SubmitRun(ctx, req.Request, req.WebhookUrl)
+	if err != nil {
+		return result, err
+	}
+	result.Run = runToProto(run)
+	return result, nil
+}
+
+// ListRunEvents 拉取运行事件。
+func (s *AgentService) ListRunEvents(ctx context.Context, req *pb.RunEventsRequest) (*pb.RunEventsResponse, error) {
+	result := new(pb.RunEventsResponse)
+	run, events, err := s.delegate.ListRunEvents(ctx, req.Id, req.AfterSeq, int(req.Limit))
+	if err != nil {
+		return result, err
+	}
+	result.Run = runToProto(run)
+	for _, event := range events {
+		result.Events = append(result.Events, runEventToProto(event))
+	}
+	return result, nil
+}
+
+// SubscribeRun 订阅运行事件，运行结束后关闭流。
+func (s *AgentService) SubscribeRun(req *pb.RunEventsRequest, stream pb.AgentService_SubscribeRunServer) error {
+	return s.delegate.SubscribeRun(stream.Context(), req.Id, req.AfterSeq, func(event *biz.RunEvent) error {
+		return stream.Send(runEventToProto(event))
+	})
+}
+
+// ResolveApproval 审批等待确认的工具调用。
+func (s *AgentService) ResolveApproval(ctx context.Context, req *pb.ResolveApprovalRequest) (*pb.ResolveApprovalResponse, error) {
+	result := new(pb.ResolveApprovalResponse)
+	if req.RunId == "" {
+		return result, errors.New("run_id is required")
+	}
+	approval, err := s.delegate.ResolveApproval(ctx, req.RunId, req.Id, agent.ApprovalDecision{
+		Action:    agent.ApprovalAction(req.Action),
+		Arguments: req.Arguments,
+		Reason:    req.Reason,
+	})
+	if err != nil {
+		return result, err
+	}
+	result.Approval = approvalToProto(approval)
+	return result, nil
+}
+
+// ResumeInterruptedRuns 恢复因服务重启而中断的运行。
+func (s *AgentService) ResumeInterruptedRuns(ctx context.Context) error {
+	return s.delegate.ResumeInterruptedRuns(ctx)
+}
+
+func runToProto(run *biz.Run) *pb.RunInfo {
+	info := &pb.RunInfo{
+		Id:          run.ID,
+		AgentId:     int32(run.AgentID),
+		SessionId:   run.SessionID,
+		Query:       run.Query,
+		Status:      run.Status,
+		CurrentStep: int32(run.CurrentStep),
+		Result:      run.Result,
+		Error:       run.Error,
+		ResumeCount: int32(run.ResumeCount),
+		WebhookUrl:  run.WebhookURL,
+		CreatedAt:   run.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:   run.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+	for _, approval := range run.PendingApprovals {
+		info.PendingApprovals = append(info.PendingApprovals, approvalToProto(approval))
+	}
+	info.FinalResult = biz.FinalResultToProto(run.FinalResult)
+	return info
+}
+
+func approvalToProto(approval *biz.Approval) *pb.ApprovalInfo {
+	return &pb.ApprovalInfo{
+		Id:         approval.ID,
+		RunId:      approval.RunID,
+		ToolCallId: approval.ToolCallID,
+		ToolName:   approval.ToolName,
+		Arguments:  approval.Arguments,
+	}
+}
+
+func runEventToProto(event *biz.RunEvent) *pb.RunEvent {
+	return &pb.RunEvent{
+		Seq:       event.Seq,
+		RunId:     event.RunID,
+		Type:      event.Type,
+		Content:   event.Content,
+		Step:      int32(event.Step),
+		CreatedAt: event.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
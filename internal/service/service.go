@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 
+	"github.com/go-kratos/kratos/v2/transport"
 	structpb "google.golang.org/protobuf/types/known/structpb"
 
 	"jas-agent/internal/biz"
@@ -16,21 +17,36 @@ type AgentService struct {
 	delegate         *biz.AgentUsecase
 	mcpService       *biz.McpUsecase
 	knowledgeService *biz.KnowledgeUsecase
+	sessionService   *biz.SessionUsecase
 }
 
 // NewAgentService 创建 AgentService。
-func NewAgentService(delegate *biz.AgentUsecase, mcpService *biz.McpUsecase, knowledgeService *biz.KnowledgeUsecase) (*AgentService, error) {
+func NewAgentService(delegate *biz.AgentUsecase, mcpService *biz.McpUsecase, knowledgeService *biz.KnowledgeUsecase, sessionService *biz.SessionUsecase) (*AgentService, error) {
 
-	return &AgentService{delegate: delegate, mcpService: mcpService, knowledgeService: knowledgeService}, nil
+	return &AgentService{delegate: delegate, mcpService: mcpService, knowledgeService: knowledgeService, sessionService: sessionService}, nil
+}
+
+// tenantHeader 认证网关写入调用方租户ID的请求头，网关需覆盖客户端自带的同名请求头
+const tenantHeader = "X-Tenant-ID"
+
+// fillTenant 以认证网关写入请求头的租户ID作为请求的租户，忽略请求体中的 tenant_id，
+// 调用方不能自行指定或省略租户以绕过租户预算
+func fillTenant(ctx context.Context, req *pb.ChatRequest) {
+	req.TenantId = ""
+	if tr, ok := transport.FromServerContext(ctx); ok {
+		req.TenantId = tr.RequestHeader().Get(tenantHeader)
+	}
 }
 
 // Chat 处理单次对话请求。
 func (s *AgentService) Chat(ctx context.Context, req *pb.ChatRequest) (*pb.ChatResponse, error) {
+	fillTenant(ctx, req)
 	return s.delegate.Chat(ctx, req)
 }
 
 // StreamChat 处理流式对话请求。
 func (s *AgentService) StreamChat(req *pb.ChatRequest, stream pb.AgentService_StreamChatServer) error {
+	fillTenant(stream.Context(), req)
 	return s.delegate.StreamChat(req, stream)
 }
 
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/transport"
+
+	pb "jas-agent/api/agent/service/v1"
+)
+
+// fakeTransport 只提供请求头的服务端传输
+type fakeTransport struct {
+	transport.Transporter
+	header map[string]string
+}
+
+func (t *fakeTransport) RequestHeader() transport.Header { return fakeHeader(t.header) }
+
+type fakeHeader map[string]string
+
+func (h fakeHeader) Get(key string) string      { return h[key] }
+func (h fakeHeader) Set(key, value string)      { h[key] = value }
+func (h fakeHeader) Add(key, value string)      { h[key] = value }
+func (h fakeHeader) Values(key string) []string { return []string{h[key]} }
+
+func (h fakeHeader) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func TestFillTenant(t *testing.T) {
+	tests := []struct {
+		name   string
+		header map[string]string
+		tenant string
+		want   string
+	}{
+		{name: "header", header: map[string]string{tenantHeader: "acme"}, want: "acme"},
+		// 请求体中的租户被网关写入的租户覆盖
+		{name: "body overridden", header: map[string]string{tenantHeader: "acme"}, tenant: "other", want: "acme"},
+		// 未经网关写入租户时不能在请求体中自行指定，按默认预算计
+		{name: "body only", header: map[string]string{}, tenant: "other", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := transport.NewServerContext(context.Background(), &fakeTransport{header: tt.header})
+			req := &pb.ChatRequest{TenantId: tt.tenant}
+			fillTenant(ctx, req)
+			if req.TenantId != tt.want {
+				t.Fatalf("tenant = %q, want %q", req.TenantId, tt.want)
+			}
+		})
+	}
+}
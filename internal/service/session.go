@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+
+	"jas-agent/internal/biz"
+
+	pb "jas-agent/api/agent/service/v1"
+)
+
+// CreateSession 创建会话。
+func (s *AgentService) CreateSession(ctx context.Context, req *pb.SessionRequest) (*pb.SessionResponse, error) {
+	result := new(pb.SessionResponse)
+	session, err := s.sessionService.CreateSession(ctx, req)
+	if err != nil {
+		return result, err
+	}
+	result.Session = sessionToProto(session)
+	return result, nil
+}
+
+// ListSessions 列出会话。
+func (s *AgentService) ListSessions(ctx context.Context, req *pb.SessionListRequest) (*pb.SessionListResponse, error) {
+	result := new(pb.SessionListResponse)
+	sessions, err := s.sessionService.ListSessions(ctx, int(req.AgentId))
+	if err != nil {
+		return result, err
+	}
+	for _, session := range sessions {
+		result.Sessions = append(result.Sessions, sessionToProto(session))
+	}
+	return result, nil
+}
+
+// GetSession 获取会话及其历史消息。
+func (s *AgentService) GetSession(ctx context.Context, req *pb.SessionGetRequest) (*pb.SessionResponse, error) {
+	result := new(pb.SessionResponse)
+	session, messages, err := s.sessionService.GetSession(ctx, req.Id)
+	if err != nil {
+		return result, err
+	}
+	result.Session = sessionToProto(session)
+	for _, message := range messages {
+		item := &pb.SessionMessage{
+			Role:       message.Role,
+			Content:    message.Content,
+			Name:       message.Name,
+			ToolCallId: message.ToolCallID,
+			CreatedAt:  message.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+		for _, toolCall := range message.ToolCalls {
+			item.ToolCalls = append(item.ToolCalls, &pb.SessionToolCall{
+				Id:        toolCall.ID,
+				Name:      toolCall.Name,
+				Arguments: toolCall.Arguments,
+			})
+		}
+		result.Messages = append(result.Messages, item)
+	}
+	return result, nil
+}
+
+// DeleteSession 删除会话。
+func (s *AgentService) DeleteSession(ctx context.Context, req *pb.SessionDeleteRequest) (*pb.SessionResponse, error) {
+	result := new(pb.SessionResponse)
+	if err := s.sessionService.DeleteSession(ctx, req.Id); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+func sessionToProto(session *biz.Session) *pb.SessionInfo {
+	return &pb.SessionInfo{
+		Id:           session.ID,
+		AgentId:      int32(session.AgentID),
+		Title:        session.Title,
+		MessageCount: int32(session.MessageCount),
+		CreatedAt:    session.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:    session.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -2,12 +2,14 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"github.com/gorilla/websocket"
 	"jas-agent/agent/core"
 	pb "jas-agent/api/agent/service/v1"
 	"net/http"
 	"strings"
+	"sync"
 )
 
 var wsUpgrader = websocket.Upgrader{
@@ -32,6 +34,7 @@ func (s *AgentService) WebSocket(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	req.TenantId = r.Header.Get(tenantHeader)
 	// 根据 agent_id 从数据库加载 Agent 配置
 	if req.AgentId == 0 {
 		_ = conn.WriteJSON(&pb.ChatStreamResponse{
@@ -40,10 +43,56 @@ func (s *AgentService) WebSocket(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
-	if err = s.delegate.StreamChatWithSender(context.TODO(), &req, func(resp *pb.ChatStreamResponse) error {
-		return conn.WriteJSON(resp)
+	// 推送与审批失败的回复来自不同协程，连接不支持并发写。
+	// 首条推送携带本连接的运行ID，连接上只能处理该运行的审批
+	var writeMu sync.Mutex
+	var runID string
+	writeJSON := func(v any) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		if resp, ok := v.(*pb.ChatStreamResponse); ok && runID == "" {
+			runID = resp.GetMetadata().GetRunId()
+		}
+		return conn.WriteJSON(v)
+	}
+	connRunID := func() string {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return runID
+	}
+	// 客户端断开连接时取消运行：持续读取连接，读失败即视为断开。
+	// 运行中客户端发送的消息为审批决定（ResolveApprovalRequest）
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	go func() {
+		defer cancel()
+		for {
+			var approval pb.ResolveApprovalRequest
+			if err := conn.ReadJSON(&approval); err != nil {
+				var syntaxErr *json.SyntaxError
+				var typeErr *json.UnmarshalTypeError
+				if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+					_ = writeJSON(&pb.ChatStreamResponse{
+						Type:    pb.ChatStreamResponse_ERROR,
+						Content: "invalid approval message",
+					})
+					continue
+				}
+				return
+			}
+			approval.RunId = connRunID()
+			if _, err := s.ResolveApproval(ctx, &approval); err != nil {
+				_ = writeJSON(&pb.ChatStreamResponse{
+					Type:    pb.ChatStreamResponse_ERROR,
+					Content: err.Error(),
+				})
+			}
+		}
+	}()
+	if err = s.delegate.StreamChatWithSender(ctx, &req, func(resp *pb.ChatStreamResponse) error {
+		return writeJSON(resp)
 	}); err != nil && !errors.Is(err, context.Canceled) {
-		_ = conn.WriteJSON(&pb.ChatStreamResponse{
+		_ = writeJSON(&pb.ChatStreamResponse{
 			Type:    pb.ChatStreamResponse_ERROR,
 			Content: err.Error(),
 		})
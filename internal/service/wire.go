@@ -3,4 +3,4 @@ package service
 import "github.com/google/wire"
 
 // ProviderSet service provider.
-var ProviderSet = wire.NewSet(NewAgentService, NewKnowledgeServiceImpl)
+var ProviderSet = wire.NewSet(NewAgentService, NewKnowledgeServiceImpl, NewMCPServerService)
@@ -0,0 +1,48 @@
+-- Agent 运行记录表，保存每一步的检查点用于中断后恢复，同时作为异步运行的队列
+CREATE TABLE IF NOT EXISTS `agent_runs` (
+  `id` VARCHAR(64) PRIMARY KEY COMMENT '运行ID',
+  `agent_id` INT NOT NULL COMMENT 'Agent ID',
+  `session_id` VARCHAR(64) COMMENT '会话ID',
+  `query` TEXT COMMENT '用户输入',
+  `request` JSON COMMENT '发起运行的请求',
+  `status` VARCHAR(32) NOT NULL COMMENT '状态: Queued, Running, Finish, Error, Timeout, Canceled, BudgetExceeded',
+  `current_step` INT NOT NULL DEFAULT 0 COMMENT '最近一次检查点的步骤数',
+  `checkpoint` LONGTEXT COMMENT '最近一次检查点（JSON）',
+  `result` LONGTEXT COMMENT '运行结果',
+  `final_result` JSON COMMENT '结构化最终结果',
+  `error` TEXT COMMENT '失败原因',
+  `resume_count` INT NOT NULL DEFAULT 0 COMMENT '已恢复次数',
+  `webhook_url` VARCHAR(1024) COMMENT '运行结束后的回调地址',
+  `owner` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '执行该运行的实例',
+  `heartbeat_at` TIMESTAMP(3) NULL COMMENT '执行实例最近一次心跳，过期后可由其他实例接管',
+  `cancel_requested` BOOLEAN NOT NULL DEFAULT FALSE COMMENT '已请求取消，执行实例在心跳时发现后中断执行',
+  `created_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  INDEX `idx_agent_id` (`agent_id`),
+  INDEX `idx_status_created` (`status`, `created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Agent 运行记录表';
+
+-- 运行事件表，用于轮询或订阅运行进度
+CREATE TABLE IF NOT EXISTS `agent_run_events` (
+  `id` BIGINT AUTO_INCREMENT PRIMARY KEY COMMENT '事件序号',
+  `run_id` VARCHAR(64) NOT NULL COMMENT '运行ID',
+  `type` VARCHAR(32) NOT NULL COMMENT '事件类型: THINKING, ACTION, OBSERVATION, SUMMARY, METADATA, STATUS, FINAL',
+  `content` LONGTEXT COMMENT '事件内容',
+  `step` INT NOT NULL DEFAULT 0 COMMENT '步骤',
+  `created_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  INDEX `idx_run_id` (`run_id`, `id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Agent 运行事件表';
+
+-- 工具调用审批表，记录等待审批的调用与审批决定，任一实例都可以处理审批
+CREATE TABLE IF NOT EXISTS `agent_run_approvals` (
+  `id` VARCHAR(64) PRIMARY KEY COMMENT '审批ID',
+  `run_id` VARCHAR(64) NOT NULL COMMENT '运行ID',
+  `tool_call_id` VARCHAR(128) COMMENT '工具调用ID',
+  `tool_name` VARCHAR(255) NOT NULL COMMENT '工具名',
+  `arguments` LONGTEXT COMMENT '模型给出的参数',
+  `status` VARCHAR(16) NOT NULL COMMENT '状态: Pending, Resolved, Expired',
+  `decision` JSON COMMENT '审批决定',
+  `created_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  `resolved_at` TIMESTAMP NULL COMMENT '作出决定的时间',
+  INDEX `idx_run_status` (`run_id`, `status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Agent 工具调用审批表';
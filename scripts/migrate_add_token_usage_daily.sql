@@ -0,0 +1,16 @@
+-- Agent 每日 token 用量汇总表
+CREATE TABLE IF NOT EXISTS `agent_token_usage_daily` (
+  `id` BIGINT AUTO_INCREMENT PRIMARY KEY,
+  `tenant_id` VARCHAR(64) NOT NULL DEFAULT '' COMMENT '租户ID，未指定租户时为空',
+  `agent_id` INT NOT NULL COMMENT 'Agent ID',
+  `usage_date` DATE NOT NULL COMMENT '统计日期',
+  `model` VARCHAR(100) NOT NULL COMMENT '模型名称',
+  `calls` BIGINT NOT NULL DEFAULT 0 COMMENT '调用次数',
+  `prompt_tokens` BIGINT NOT NULL DEFAULT 0 COMMENT '输入 token 数',
+  `completion_tokens` BIGINT NOT NULL DEFAULT 0 COMMENT '输出 token 数',
+  `cost` DECIMAL(16,6) NOT NULL DEFAULT 0 COMMENT '费用',
+  `created_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  UNIQUE KEY `uk_tenant_date_agent_model` (`tenant_id`, `usage_date`, `agent_id`, `model`),
+  INDEX `idx_usage_date` (`usage_date`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Agent 每日 token 用量汇总表';
@@ -83,6 +83,98 @@ CREATE TABLE IF NOT EXISTS `documents` (
   INDEX `idx_file_type` (`file_type`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='文档表';
 
+-- 会话表
+CREATE TABLE IF NOT EXISTS `sessions` (
+  `id` VARCHAR(64) PRIMARY KEY COMMENT '会话ID',
+  `agent_id` INT NOT NULL COMMENT '所属Agent ID',
+  `title` VARCHAR(255) COMMENT '会话标题',
+  `created_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  FOREIGN KEY (`agent_id`) REFERENCES `agents`(`id`) ON DELETE CASCADE,
+  INDEX `idx_agent_id` (`agent_id`),
+  INDEX `idx_updated_at` (`updated_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='会话表';
+
+-- 会话消息表
+CREATE TABLE IF NOT EXISTS `session_messages` (
+  `id` BIGINT AUTO_INCREMENT PRIMARY KEY,
+  `session_id` VARCHAR(64) NOT NULL COMMENT '所属会话ID',
+  `role` VARCHAR(20) NOT NULL COMMENT '消息角色: user, assistant, tool',
+  `content` LONGTEXT COMMENT '消息内容',
+  `name` VARCHAR(100) COMMENT '工具名称',
+  `tool_call_id` VARCHAR(100) COMMENT '工具调用ID',
+  `tool_calls` JSON COMMENT '助手消息发起的工具调用列表',
+  `created_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (`session_id`) REFERENCES `sessions`(`id`) ON DELETE CASCADE,
+  INDEX `idx_session_id` (`session_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='会话消息表';
+
+-- Agent 每日 token 用量汇总表
+CREATE TABLE IF NOT EXISTS `agent_token_usage_daily` (
+  `id` BIGINT AUTO_INCREMENT PRIMARY KEY,
+  `tenant_id` VARCHAR(64) NOT NULL DEFAULT '' COMMENT '租户ID，未指定租户时为空',
+  `agent_id` INT NOT NULL COMMENT 'Agent ID',
+  `usage_date` DATE NOT NULL COMMENT '统计日期',
+  `model` VARCHAR(100) NOT NULL COMMENT '模型名称',
+  `calls` BIGINT NOT NULL DEFAULT 0 COMMENT '调用次数',
+  `prompt_tokens` BIGINT NOT NULL DEFAULT 0 COMMENT '输入 token 数',
+  `completion_tokens` BIGINT NOT NULL DEFAULT 0 COMMENT '输出 token 数',
+  `cost` DECIMAL(16,6) NOT NULL DEFAULT 0 COMMENT '费用',
+  `created_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  UNIQUE KEY `uk_tenant_date_agent_model` (`tenant_id`, `usage_date`, `agent_id`, `model`),
+  INDEX `idx_usage_date` (`usage_date`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Agent 每日 token 用量汇总表';
+
+-- Agent 运行记录表，保存每一步的检查点用于中断后恢复
+CREATE TABLE IF NOT EXISTS `agent_runs` (
+  `id` VARCHAR(64) PRIMARY KEY COMMENT '运行ID',
+  `agent_id` INT NOT NULL COMMENT 'Agent ID',
+  `session_id` VARCHAR(64) COMMENT '会话ID',
+  `query` TEXT COMMENT '用户输入',
+  `request` JSON COMMENT '发起运行的请求',
+  `status` VARCHAR(32) NOT NULL COMMENT '状态: Queued, Running, Finish, Error, Timeout, Canceled, BudgetExceeded',
+  `current_step` INT NOT NULL DEFAULT 0 COMMENT '最近一次检查点的步骤数',
+  `checkpoint` LONGTEXT COMMENT '最近一次检查点（JSON）',
+  `result` LONGTEXT COMMENT '运行结果',
+  `final_result` JSON COMMENT '结构化最终结果',
+  `error` TEXT COMMENT '失败原因',
+  `resume_count` INT NOT NULL DEFAULT 0 COMMENT '已恢复次数',
+  `webhook_url` VARCHAR(1024) COMMENT '运行结束后的回调地址',
+  `owner` VARCHAR(128) NOT NULL DEFAULT '' COMMENT '执行该运行的实例',
+  `heartbeat_at` TIMESTAMP(3) NULL COMMENT '执行实例最近一次心跳，过期后可由其他实例接管',
+  `cancel_requested` BOOLEAN NOT NULL DEFAULT FALSE COMMENT '已请求取消，执行实例在心跳时发现后中断执行',
+  `created_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  INDEX `idx_agent_id` (`agent_id`),
+  INDEX `idx_status_created` (`status`, `created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Agent 运行记录表';
+
+-- 创建运行事件表
+CREATE TABLE IF NOT EXISTS `agent_run_events` (
+  `id` BIGINT AUTO_INCREMENT PRIMARY KEY COMMENT '事件序号',
+  `run_id` VARCHAR(64) NOT NULL COMMENT '运行ID',
+  `type` VARCHAR(32) NOT NULL COMMENT '事件类型: THINKING, ACTION, OBSERVATION, SUMMARY, METADATA, STATUS, FINAL',
+  `content` LONGTEXT COMMENT '事件内容',
+  `step` INT NOT NULL DEFAULT 0 COMMENT '步骤',
+  `created_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  INDEX `idx_run_id` (`run_id`, `id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Agent 运行事件表';
+
+-- 创建工具调用审批表
+CREATE TABLE IF NOT EXISTS `agent_run_approvals` (
+  `id` VARCHAR(64) PRIMARY KEY COMMENT '审批ID',
+  `run_id` VARCHAR(64) NOT NULL COMMENT '运行ID',
+  `tool_call_id` VARCHAR(128) COMMENT '工具调用ID',
+  `tool_name` VARCHAR(255) NOT NULL COMMENT '工具名',
+  `arguments` LONGTEXT COMMENT '模型给出的参数',
+  `status` VARCHAR(16) NOT NULL COMMENT '状态: Pending, Resolved, Expired',
+  `decision` JSON COMMENT '审批决定',
+  `created_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  `resolved_at` TIMESTAMP NULL COMMENT '作出决定的时间',
+  INDEX `idx_run_status` (`run_id`, `status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Agent 工具调用审批表';
+
 -- 插入一些示例数据
 INSERT INTO `agents` (`name`, `framework`, `description`, `system_prompt`, `max_steps`, `model`, `connection_config`) VALUES
 ('默认助手', 'react', '通用智能助手，适合大多数场景', NULL, 10, 'gpt-3.5-turbo', NULL),